@@ -29,9 +29,6 @@ import (
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/authenticator/bearertoken"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/authenticator/tokenfile"
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/capabilities"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
@@ -47,23 +44,56 @@ import (
 )
 
 var (
-	port                  = flag.Uint("port", 8080, "The port to listen on. Default 8080")
-	address               = util.IP(net.ParseIP("127.0.0.1"))
-	apiPrefix             = flag.String("api_prefix", "/api", "The prefix for API requests on the server. Default '/api'.")
-	storageVersion        = flag.String("storage_version", "", "The version to store resources with. Defaults to server preferred")
-	cloudProvider         = flag.String("cloud_provider", "", "The provider for cloud services.  Empty string for no provider.")
-	cloudConfigFile       = flag.String("cloud_config", "", "The path to the cloud provider configuration file.  Empty string for no configuration file.")
-	minionRegexp          = flag.String("minion_regexp", "", "If non empty, and -cloud_provider is specified, a regular expression for matching minion VMs.")
-	minionPort            = flag.Uint("minion_port", 10250, "The port at which kubelet will be listening on the minions.")
-	healthCheckMinions    = flag.Bool("health_check_minions", true, "If true, health check minions and filter unhealthy ones. Default true.")
-	minionCacheTTL        = flag.Duration("minion_cache_ttl", 30*time.Second, "Duration of time to cache minion information. Default 30 seconds.")
-	eventTTL              = flag.Duration("event_ttl", 48*time.Hour, "Amount of time to retain events. Default 2 days.")
-	tokenAuthFile         = flag.String("token_auth_file", "", "If set, the file that will be used to secure the API server via token authentication.")
-	etcdServerList        util.StringList
-	etcdConfigFile        = flag.String("etcd_config", "", "The config file for the etcd client. Mutually exclusive with -etcd_servers.")
-	machineList           util.StringList
-	corsAllowedOriginList util.StringList
-	allowPrivileged       = flag.Bool("allow_privileged", false, "If true, allow privileged containers.")
+	port                   = flag.Uint("port", 8080, "The port to listen on. Default 8080")
+	readOnlyPort           = flag.Int("read_only_port", 0, "If set, the port on 127.0.0.1 where the API is also served over HTTP, read-only and without authentication or authorization, for internal components. Secrets, proxying and exec are not served there.")
+	address                = util.IP(net.ParseIP("127.0.0.1"))
+	apiPrefix              = flag.String("api_prefix", "/api", "The prefix for API requests on the server. Default '/api'.")
+	storageVersion         = flag.String("storage_version", "", "The version to store resources with. Defaults to server preferred")
+	cloudProvider          = flag.String("cloud_provider", "", "The provider for cloud services.  Empty string for no provider.")
+	cloudConfigFile        = flag.String("cloud_config", "", "The path to the cloud provider configuration file.  Empty string for no configuration file.")
+	minionRegexp           = flag.String("minion_regexp", "", "If non empty, and -cloud_provider is specified, a regular expression for matching minion VMs.")
+	minionPort             = flag.Uint("minion_port", 10250, "The port at which kubelet will be listening on the minions.")
+	healthCheckMinions     = flag.Bool("health_check_minions", true, "If true, health check minions and filter unhealthy ones. Default true.")
+	minionFailureThreshold = flag.Int("minion_failure_threshold", 3, "How many health checks in a row a minion must fail to be filtered. Default 3.")
+	minionSuccessThreshold = flag.Int("minion_success_threshold", 1, "How many health checks in a row a filtered minion must pass to be listed again. Default 1.")
+	minionCacheTTL         = flag.Duration("minion_cache_ttl", 30*time.Second, "Duration of time to cache minion information. Default 30 seconds.")
+	minionCacheGetTTL      = flag.Duration("minion_cache_get_ttl", 1*time.Second, "Duration of time to cache minion information for single minion lookups. Default 1 second.")
+	eventTTL               = flag.Duration("event_ttl", 48*time.Hour, "Amount of time to retain events. Default 2 days.")
+	eventAggregationWindow = flag.Duration("event_aggregation_window", 10*time.Minute, "Repeats of an event within this long of its last occurrence are counted instead of stored on their own. 0 disables aggregation. Default 10 minutes.")
+	tokenAuthFile          = flag.String("token_auth_file", "", "If set, the file that will be used to secure the API server via token authentication.")
+	authorizationMode      = flag.String("authorization_mode", master.AuthorizationModeAlwaysAllow, "How requests for API resources are authorized: AlwaysAllow, or RBAC to only serve the requests the roles bound to their user allow. RBAC requires -token_auth_file.")
+	rbacSuperUser          = flag.String("authorization_rbac_super_user", "", "If set, the user who is allowed every request when -authorization_mode is RBAC, e.g. to create the first roles and bindings.")
+	auditLogPath           = flag.String("audit_log_path", "", "If set, all mutating requests to the API server are recorded in this file.")
+	tlsCertFile            = flag.String("tls_cert_file", "", "If set, the API server serves HTTPS with this certificate. Requires -tls_private_key_file.")
+	tlsPrivateKeyFile      = flag.String("tls_private_key_file", "", "The private key matching -tls_cert_file.")
+	clientCAFile           = flag.String("client_ca_file", "", "If set, HTTPS clients must present a certificate signed by one of the CAs in this file.")
+	ingressConfig          = flag.String("ingress_config", "", "If set, the routes of ingresses are written to this file as nginx configuration.")
+	ingressReloadCommand   = flag.String("ingress_reload_command", "", "The command run to reload nginx when -ingress_config changes, e.g. 'nginx -s reload'.")
+	nodeMonitorGracePeriod = flag.Duration("node_monitor_grace_period", 0, "If set, minions whose kubelet has not reported their status for this long are marked not ready, and their pods are deleted.")
+	nonGracefulShutdown    = flag.Bool("enable_non_graceful_node_shutdown", false, "If true, the pods of minions annotated node.kubernetes.io/out-of-service=nodeshutdown are deleted at once. Requires -node_monitor_grace_period.")
+	apiRate                = flag.Float64("api_rate", 0, "If set, the number of requests per second each client may make to the API server.")
+	apiBurst               = flag.Int("api_burst", 10, "The number of requests each client may make in a burst above -api_rate.")
+	enableLeaderElection   = flag.Bool("enable_leader_election", false, "If true, the controllers only run on the API server elected leader among those sharing -etcd_servers and -etcd_prefix.")
+	masterCount            = flag.Int("master_count", 1, "The number of API servers sharing -etcd_servers and -etcd_prefix. More than 1 requires -enable_leader_election.")
+	enableDaemonSets       = flag.Bool("enable_daemon_set_controller", false, "If true, the API server runs the controller which starts the pods of daemon sets on the minions they select.")
+	enableJobs             = flag.Bool("enable_job_controller", false, "If true, the API server runs the controller which starts the pods of jobs and records their completions.")
+	enableGC               = flag.Bool("enable_garbage_collector", false, "If true, the API server runs the garbage collector, which deletes the pods, replication controllers, jobs and daemon sets whose owners are all gone.")
+	enableRollingUpdates   = flag.Bool("enable_rolling_update_controller", false, "If true, the API server runs the controller which rolls the pods of replication controllers with a rolling update strategy over to a new template.")
+	leaderElectionTTL      = flag.Duration("leader_election_ttl", 15*time.Second, "How long the leader keeps leading after it last renewed its lock. Default 15 seconds.")
+	podCacheStale          = flag.Duration("pod_cache_stale_threshold", 0, "Pods whose cached container information is older than this are served with the kubernetes.io/stale-cache annotation. Defaults to 3 pod cache syncs.")
+	filterEndpoints        = flag.Bool("filter_unhealthy_endpoints", false, "If true, the endpoints served by the API server leave out pods which are not running all of their containers.")
+	etcdServerList         util.StringList
+	etcdConfigFile         = flag.String("etcd_config", "", "The config file for the etcd client. Mutually exclusive with -etcd_servers.")
+	requestTimeout         = flag.Duration("request_timeout", 60*time.Second, "Requests which are not answered within this time fail. Watches, proxied requests and log and exec streams are exempt. Default 60 seconds.")
+	watchTimeout           = flag.Duration("watch_timeout", 0, "If set, watches and log streams are ended after this long.")
+	slowWatchThreshold     = flag.Duration("watch_slow_consumer_threshold", 5*time.Second, "Watch events which wait longer than this to be received are logged and counted in watchevents_dropped_total. Default 5 seconds.")
+	etcdPrefix             = flag.String("etcd_prefix", "/registry", "The key under which every object is stored in etcd. Clusters sharing an etcd need different prefixes.")
+	etcdDialTimeout        = flag.Duration("etcd_dial_timeout", 0, "Timeout for connecting to each of -etcd_servers. Defaults to the etcd client default.")
+	machineList            util.StringList
+	corsAllowedOriginList  util.StringList
+	admissionControlList   util.StringList
+	requiredLabelList      util.StringList
+	allowPrivileged        = flag.Bool("allow_privileged", false, "If true, allow privileged containers.")
 	// TODO: Discover these by pinging the host machines, and rip out these flags.
 	nodeMilliCPU      = flag.Int("node_milli_cpu", 1000, "The amount of MilliCPU provisioned on each node")
 	nodeMemory        = flag.Int("node_memory", 3*1024*1024*1024, "The amount of memory (in bytes) provisioned on each node")
@@ -75,6 +105,23 @@ func init() {
 	flag.Var(&etcdServerList, "etcd_servers", "List of etcd servers to watch (http://ip:port), comma separated. Mutually exclusive with -etcd_config")
 	flag.Var(&machineList, "machines", "List of machines to schedule onto, comma separated.")
 	flag.Var(&corsAllowedOriginList, "cors_allowed_origins", "List of allowed origins for CORS, comma separated.  An allowed origin can be a regular expression to support subdomain matching.  If this list is empty CORS will not be enabled.")
+	flag.Var(&admissionControlList, "admission_control", "Admission control plugins which must admit every create, update and delete, in order, comma separated: NamespaceExists, ResourceQuota, ServiceAccount, LimitRanger, ConfigMapRef, RequiredLabels, NetworkPolicy and NoPreemptibleCritical.")
+	flag.Var(&requiredLabelList, "admission_control_required_labels", "The labels the RequiredLabels admission control plugin requires, comma separated.")
+}
+
+// authenticatedMux registers handlers on a ServeMux behind the authentication of
+// the master, as its own handler is.
+type authenticatedMux struct {
+	mux    *http.ServeMux
+	master *master.Master
+}
+
+func (a authenticatedMux) Handle(pattern string, handler http.Handler) {
+	a.mux.Handle(pattern, a.master.Authenticate(handler))
+}
+
+func (a authenticatedMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	a.Handle(pattern, http.HandlerFunc(handler))
 }
 
 func verifyMinionFlags() {
@@ -121,17 +168,14 @@ func initCloudProvider(name string, configFilePath string) cloudprovider.Interfa
 }
 
 func newEtcd(etcdConfigFile string, etcdServerList util.StringList) (helper tools.EtcdHelper, err error) {
-	var client tools.EtcdGetSet
-	if etcdConfigFile != "" {
-		client, err = etcd.NewClientFromFile(etcdConfigFile)
-		if err != nil {
-			return helper, err
-		}
-	} else {
-		client = etcd.NewClient(etcdServerList)
+	if etcdConfigFile == "" {
+		return master.NewEtcdHelperFromURLs(etcdServerList, *storageVersion, *etcdPrefix, *etcdDialTimeout)
 	}
-
-	return master.NewEtcdHelper(client, *storageVersion)
+	client, err := etcd.NewClientFromFile(etcdConfigFile)
+	if err != nil {
+		return helper, err
+	}
+	return master.NewEtcdHelper(client, *storageVersion, *etcdPrefix)
 }
 
 func main() {
@@ -150,6 +194,21 @@ func main() {
 		AllowPrivileged: *allowPrivileged,
 	})
 
+	if *authorizationMode != master.AuthorizationModeAlwaysAllow && *authorizationMode != master.AuthorizationModeRBAC {
+		glog.Fatalf("Unknown authorization mode %q", *authorizationMode)
+	}
+	if *authorizationMode == master.AuthorizationModeRBAC && len(*tokenAuthFile) == 0 {
+		glog.Fatalf("-authorization_mode=RBAC requires -token_auth_file")
+	}
+
+	if *masterCount > 1 && !*enableLeaderElection {
+		glog.Fatalf("-master_count greater than 1 requires -enable_leader_election, or the controllers would run on every API server")
+	}
+
+	if _, err := util.CompileRegexps(corsAllowedOriginList); err != nil {
+		glog.Fatalf("Invalid CORS allowed origin, --cors_allowed_origins flag was set to %v - %v", strings.Join(corsAllowedOriginList, ","), err)
+	}
+
 	cloud := initCloudProvider(*cloudProvider, *cloudConfigFile)
 
 	podInfoGetter := &client.HTTPPodInfoGetter{
@@ -171,53 +230,66 @@ func main() {
 	if err != nil {
 		glog.Fatalf("Invalid storage version or misconfigured etcd: %v", err)
 	}
+	helper.WatchConfig.SlowConsumerThreshold = *slowWatchThreshold
 
 	m := master.New(&master.Config{
-		Client:             client,
-		Cloud:              cloud,
-		EtcdHelper:         helper,
-		HealthCheckMinions: *healthCheckMinions,
-		Minions:            machineList,
-		MinionCacheTTL:     *minionCacheTTL,
-		EventTTL:           *eventTTL,
-		MinionRegexp:       *minionRegexp,
-		PodInfoGetter:      podInfoGetter,
+		Client:                   client,
+		Cloud:                    cloud,
+		EtcdHelper:               helper,
+		HealthCheckMinions:       *healthCheckMinions,
+		MinionFailureThreshold:   *minionFailureThreshold,
+		MinionSuccessThreshold:   *minionSuccessThreshold,
+		Minions:                  machineList,
+		MinionCacheTTL:           *minionCacheTTL,
+		MinionCacheGetTTL:        *minionCacheGetTTL,
+		EventTTL:                 *eventTTL,
+		EventAggregationWindow:   *eventAggregationWindow,
+		MinionRegexp:             *minionRegexp,
+		PodInfoGetter:            podInfoGetter,
+		APIPrefix:                *apiPrefix,
+		AuditLogPath:             *auditLogPath,
+		TokenAuthFile:            *tokenAuthFile,
+		AuthorizationMode:        *authorizationMode,
+		RBACSuperUser:            *rbacSuperUser,
+		CORSAllowedOrigins:       corsAllowedOriginList,
+		AdmissionControl:         admissionControlList,
+		RequiredLabels:           requiredLabelList,
+		IngressConfigPath:        *ingressConfig,
+		IngressReloadCommand:     strings.Fields(*ingressReloadCommand),
+		NodeMonitorGracePeriod:   *nodeMonitorGracePeriod,
+		RequestTimeout:           *requestTimeout,
+		WatchTimeout:             *watchTimeout,
+		RequestsPerSecond:        *apiRate,
+		BurstSize:                *apiBurst,
+		EnableLeaderElection:     *enableLeaderElection,
+		LeaderElectionTTL:        *leaderElectionTTL,
+		MasterCount:              *masterCount,
+		FilterUnhealthyEndpoints: *filterEndpoints,
+		PodCacheStaleThreshold:   *podCacheStale,
+		ReadOnlyPort:             *readOnlyPort,
 		NodeResources: api.NodeResources{
 			Capacity: api.ResourceList{
 				resources.CPU:    util.NewIntOrStringFromInt(*nodeMilliCPU),
 				resources.Memory: util.NewIntOrStringFromInt(*nodeMemory),
 			},
 		},
+		EnableNonGracefulNodeShutdown: *nonGracefulShutdown,
+		EnableDaemonSetController:     *enableDaemonSets,
+		EnableJobController:           *enableJobs,
+		EnableGarbageCollector:        *enableGC,
+		EnableRollingUpdateController: *enableRollingUpdates,
 	})
 
 	mux := http.NewServeMux()
-	apiserver.NewAPIGroup(m.API_v1beta1()).InstallREST(mux, *apiPrefix+"/v1beta1")
-	apiserver.NewAPIGroup(m.API_v1beta2()).InstallREST(mux, *apiPrefix+"/v1beta2")
-	apiserver.InstallSupport(mux)
+	mux.Handle("/", m.Handler())
+	support := authenticatedMux{mux, m}
 	if *enableLogsSupport {
-		apiserver.InstallLogsSupport(mux)
+		apiserver.InstallLogsSupport(support)
 	}
-	ui.InstallSupport(mux)
+	ui.InstallSupport(support)
 
 	handler := http.Handler(mux)
 
-	if len(corsAllowedOriginList) > 0 {
-		allowedOriginRegexps, err := util.CompileRegexps(corsAllowedOriginList)
-		if err != nil {
-			glog.Fatalf("Invalid CORS allowed origin, --cors_allowed_origins flag was set to %v - %v", strings.Join(corsAllowedOriginList, ","), err)
-		}
-		handler = apiserver.CORS(handler, allowedOriginRegexps, nil, nil, "true")
-	}
-
-	if len(*tokenAuthFile) != 0 {
-		auth, err := tokenfile.New(*tokenAuthFile)
-		if err != nil {
-			glog.Fatalf("Unable to load the token authentication file '%s': %v", *tokenAuthFile, err)
-		}
-		userContexts := handlers.NewUserRequestContext()
-		handler = handlers.NewRequestAuthenticator(userContexts, bearertoken.New(auth), handlers.Unauthorized, handler)
-	}
-
 	handler = apiserver.RecoverPanics(handler)
 
 	s := &http.Server{
@@ -227,5 +299,12 @@ func main() {
 		WriteTimeout:   5 * time.Minute,
 		MaxHeaderBytes: 1 << 20,
 	}
+	if len(*tlsCertFile) > 0 {
+		s.TLSConfig, err = master.NewTLSConfig(*clientCAFile)
+		if err != nil {
+			glog.Fatalf("Invalid TLS configuration: %v", err)
+		}
+		glog.Fatal(s.ListenAndServeTLS(*tlsCertFile, *tlsPrivateKeyFile))
+	}
 	glog.Fatal(s.ListenAndServe())
 }
@@ -24,12 +24,15 @@ import (
 	"flag"
 	"net"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
 	_ "github.com/GoogleCloudPlatform/kubernetes/pkg/healthz"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/leaderelection"
 	masterPkg "github.com/GoogleCloudPlatform/kubernetes/pkg/master"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/service"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
@@ -38,11 +41,15 @@ import (
 )
 
 var (
-	port         = flag.Int("port", masterPkg.ControllerManagerPort, "The port that the controller-manager's http service runs on")
-	address      = util.IP(net.ParseIP("127.0.0.1"))
-	clientConfig = &client.Config{}
+	port          = flag.Int("port", masterPkg.ControllerManagerPort, "The port that the controller-manager's http service runs on")
+	address       = util.IP(net.ParseIP("127.0.0.1"))
+	clientConfig  = &client.Config{}
+	leaseDuration = flag.Duration("lease_duration", 15*time.Second, "The controller manager renews its lease in the kube-system namespace twice per this period. A lease which was not renewed for this long is stale.")
 )
 
+// leaseName is the name of the lease the controller manager keeps renewed.
+const leaseName = "kube-controller-manager"
+
 func init() {
 	flag.Var(&address, "address", "The IP address to serve on (set to 0.0.0.0 for all interfaces)")
 	client.BindClientConfigFlags(flag.CommandLine, clientConfig)
@@ -66,6 +73,13 @@ func main() {
 
 	go http.ListenAndServe(net.JoinHostPort(address.String(), strconv.Itoa(*port)), nil)
 
+	hostname, err := os.Hostname()
+	if err != nil {
+		glog.Fatalf("Couldn't get the hostname: %v", err)
+	}
+	heartbeat := leaderelection.NewLeaseHeartbeat(kubeClient, api.NamespaceSystem, leaseName, hostname, *leaseDuration)
+	go heartbeat.Run(nil)
+
 	endpoints := service.NewEndpointController(kubeClient)
 	go util.Forever(func() { endpoints.SyncServiceEndpoints() }, time.Second*10)
 
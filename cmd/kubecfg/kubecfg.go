@@ -46,6 +46,7 @@ var (
 	preventSkew   = flag.Bool("expect_version_match", false, "Fail if server's version doesn't match own version.")
 	config        = flag.String("c", "", "Path or URL to the config file, or '-' to read from STDIN")
 	selector      = flag.String("l", "", "Selector (label query) to use for listing")
+	namespace     = flag.String("ns", "", "The namespace to operate in. If empty, the server's default namespace is used")
 	updatePeriod  = flag.Duration("u", 60*time.Second, "Update interval period")
 	portSpec      = flag.String("p", "", "The port spec, comma-separated list of <external>:<internal>,...")
 	servicePort   = flag.Int("s", -1, "If positive, create and run a corresponding service on this port, only used with 'run'")
@@ -177,8 +178,10 @@ func main() {
 		clientConfig.Host = os.Getenv("KUBERNETES_MASTER")
 	}
 
-	// TODO: get the namespace context when kubecfg ns is completed
 	ctx := api.NewContext()
+	if len(*namespace) > 0 {
+		ctx = api.WithNamespace(ctx, *namespace)
+	}
 
 	if clientConfig.Host == "" {
 		// TODO: eventually apiserver should start on 443 and be secure by default
@@ -346,7 +349,7 @@ func executeAPIRequest(ctx api.Context, method string, c *client.Client) bool {
 			glog.Fatalf("usage: kubecfg [OPTIONS] %s <%s>", method, prettyWireStorage())
 		}
 	case "update":
-		obj, err := c.Verb("GET").Path(path).Do().Get()
+		obj, err := c.Verb("GET").Namespace(*namespace).Path(path).Do().Get()
 		if err != nil {
 			glog.Fatalf("error obtaining resource version for update: %v", err)
 		}
@@ -372,7 +375,7 @@ func executeAPIRequest(ctx api.Context, method string, c *client.Client) bool {
 		return false
 	}
 
-	r := c.Verb(verb).Path(path)
+	r := c.Verb(verb).Namespace(*namespace).Path(path)
 	if len(*selector) > 0 {
 		r.ParseSelectorParam("labels", *selector)
 	}
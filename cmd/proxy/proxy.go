@@ -33,6 +33,7 @@ import (
 var (
 	etcdServerList util.StringList
 	etcdConfigFile = flag.String("etcd_config", "", "The config file for the etcd client. Mutually exclusive with -etcd_servers")
+	etcdPrefix     = flag.String("etcd_prefix", "/registry", "The key under which the apiserver stores every object in etcd, its -etcd_prefix.")
 	bindAddress    = util.IP(net.ParseIP("0.0.0.0"))
 	clientConfig   = &client.Config{}
 )
@@ -90,7 +91,7 @@ func main() {
 		if etcdClient != nil {
 			glog.Infof("Using etcd servers %v", etcdClient.GetCluster())
 
-			config.NewConfigSourceEtcd(etcdClient,
+			config.NewConfigSourceEtcd(etcdClient, *etcdPrefix,
 				serviceConfig.Channel("etcd"),
 				endpointsConfig.Channel("etcd"))
 		}
@@ -19,6 +19,8 @@ package api
 import (
 	stderrs "errors"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+
 	"code.google.com/p/go.net/context"
 )
 
@@ -33,6 +35,33 @@ type key int
 // namespaceKey is the context key for the request namespace.
 const namespaceKey key = 0
 
+// userKey is the context key for the request user.
+const userKey key = 1
+
+// resourceVersionMatchKey is the context key for how the resource version of
+// an updated object is matched.
+const resourceVersionMatchKey key = 2
+
+// deletionPropagationKey is the context key for how the deletion of an object
+// propagates to its dependents.
+const deletionPropagationKey key = 3
+
+// ResourceVersionMatch tells how the resource version of an updated object is
+// compared with that of the stored object. It only applies to objects whose
+// resource version is set.
+type ResourceVersionMatch string
+
+const (
+	// ResourceVersionMatchExact updates the object only if the stored object
+	// has exactly its resource version, that is if it has not changed since it
+	// was read. This is the default.
+	ResourceVersionMatchExact ResourceVersionMatch = "Exact"
+	// ResourceVersionMatchNotOlderThan updates the object only if the stored
+	// object was last changed at or before its resource version, such as when
+	// the version is that of a list the object was read from.
+	ResourceVersionMatchNotOlderThan ResourceVersionMatch = "NotOlderThan"
+)
+
 // NewContext instantiates a base context object for request flows.
 func NewContext() Context {
 	return context.TODO()
@@ -63,6 +92,60 @@ func NamespaceFrom(ctx Context) (string, bool) {
 	return namespace, ok
 }
 
+// WithUser returns a copy of parent in which the user value is set
+func WithUser(parent Context, u user.Info) Context {
+	return WithValue(parent, userKey, u)
+}
+
+// UserFrom returns the value of the user key on the ctx
+func UserFrom(ctx Context) (user.Info, bool) {
+	u, ok := ctx.Value(userKey).(user.Info)
+	return u, ok
+}
+
+// WithResourceVersionMatch returns a copy of parent in which the resource version match is set
+func WithResourceVersionMatch(parent Context, match ResourceVersionMatch) Context {
+	return WithValue(parent, resourceVersionMatchKey, match)
+}
+
+// ResourceVersionMatchFrom returns the resource version match on the ctx, or
+// ResourceVersionMatchExact if it is not set
+func ResourceVersionMatchFrom(ctx Context) ResourceVersionMatch {
+	if match, ok := ctx.Value(resourceVersionMatchKey).(ResourceVersionMatch); ok && match != "" {
+		return match
+	}
+	return ResourceVersionMatchExact
+}
+
+// DeletionPropagation tells what happens to the dependents of a deleted
+// object, the objects whose OwnerReferences name it.
+type DeletionPropagation string
+
+const (
+	// DeletePropagationForeground deletes the dependents before the object.
+	DeletePropagationForeground DeletionPropagation = "Foreground"
+	// DeletePropagationBackground deletes the object, and leaves its
+	// dependents to the garbage collector. This is the default.
+	DeletePropagationBackground DeletionPropagation = "Background"
+	// DeletePropagationOrphan removes the references of the dependents to the
+	// object before it is deleted, so that they are kept.
+	DeletePropagationOrphan DeletionPropagation = "Orphan"
+)
+
+// WithDeletionPropagation returns a copy of parent in which the deletion propagation is set
+func WithDeletionPropagation(parent Context, propagation DeletionPropagation) Context {
+	return WithValue(parent, deletionPropagationKey, propagation)
+}
+
+// DeletionPropagationFrom returns the deletion propagation on the ctx, or
+// DeletePropagationBackground if it is not set
+func DeletionPropagationFrom(ctx Context) DeletionPropagation {
+	if propagation, ok := ctx.Value(deletionPropagationKey).(DeletionPropagation); ok && propagation != "" {
+		return propagation
+	}
+	return DeletePropagationBackground
+}
+
 // ValidNamespace returns false if the namespace on the context differs from the resource.  If the resource has no namespace, it is set to the value in the context.
 func ValidNamespace(ctx Context, resource *TypeMeta) bool {
 	ns, ok := NamespaceFrom(ctx)
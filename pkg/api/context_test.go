@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
 )
 
 // TestNamespaceContext validates that a namespace can be get/set on a context object
@@ -40,6 +41,22 @@ func TestNamespaceContext(t *testing.T) {
 	}
 }
 
+// TestUserContext validates that a user can be get/set on a context object
+func TestUserContext(t *testing.T) {
+	ctx := api.NewContext()
+	if _, ok := api.UserFrom(ctx); ok {
+		t.Errorf("Should not be ok because there is no user on the context")
+	}
+	ctx = api.WithUser(ctx, &user.DefaultInfo{Name: "bob"})
+	result, ok := api.UserFrom(ctx)
+	if !ok {
+		t.Errorf("Error getting user")
+	}
+	if result.GetName() != "bob" {
+		t.Errorf("Expected: %v, Actual: %v", "bob", result.GetName())
+	}
+}
+
 // TestValidNamespace validates that namespace rules are enforced on a resource prior to create or update
 func TestValidNamespace(t *testing.T) {
 	ctx := api.NewDefaultContext()
@@ -91,6 +91,20 @@ func NewConflict(kind, name string, err error) error {
 	}}
 }
 
+// NewForbidden returns an error indicating the requested action was forbidden.
+func NewForbidden(kind, name string, err error) error {
+	return &statusError{api.Status{
+		Status: api.StatusFailure,
+		Code:   http.StatusForbidden,
+		Reason: api.StatusReasonForbidden,
+		Details: &api.StatusDetails{
+			Kind: kind,
+			ID:   name,
+		},
+		Message: fmt.Sprintf("%s %q is forbidden: %s", kind, name, err),
+	}}
+}
+
 // NewInvalid returns an error indicating the item is invalid and cannot be processed.
 func NewInvalid(kind, name string, errs ErrorList) error {
 	causes := make([]api.StatusCause, 0, len(errs))
@@ -116,6 +130,45 @@ func NewInvalid(kind, name string, errs ErrorList) error {
 	}}
 }
 
+// NewBadRequest returns an error indicating the request cannot be served as it is.
+func NewBadRequest(reason string) error {
+	return &statusError{api.Status{
+		Status:  api.StatusFailure,
+		Code:    400,
+		Reason:  api.StatusReasonBadRequest,
+		Message: reason,
+	}}
+}
+
+// NewTooManyRequests returns an error indicating the request cannot be served
+// now, and may be retried after retryAfterSeconds.
+func NewTooManyRequests(message string, retryAfterSeconds int) error {
+	return &statusError{api.Status{
+		Status:  api.StatusFailure,
+		Code:    429,
+		Reason:  api.StatusReasonTooManyRequests,
+		Message: message,
+		Details: &api.StatusDetails{
+			RetryAfterSeconds: retryAfterSeconds,
+		},
+	}}
+}
+
+// NewTimeout returns an error indicating the server gave up on the request for
+// the item, which may still be carried out.
+func NewTimeout(kind, name string, err error) error {
+	return &statusError{api.Status{
+		Status: api.StatusFailure,
+		Code:   http.StatusGatewayTimeout,
+		Reason: api.StatusReasonTimeout,
+		Details: &api.StatusDetails{
+			Kind: kind,
+			ID:   name,
+		},
+		Message: fmt.Sprintf("%s %q timed out: %s", kind, name, err),
+	}}
+}
+
 // IsNotFound returns true if the specified error was created by NewNotFoundErr.
 func IsNotFound(err error) bool {
 	return reasonForError(err) == api.StatusReasonNotFound
@@ -131,11 +184,31 @@ func IsConflict(err error) bool {
 	return reasonForError(err) == api.StatusReasonConflict
 }
 
+// IsForbidden determines if the err is an error which indicates that the request is forbidden.
+func IsForbidden(err error) bool {
+	return reasonForError(err) == api.StatusReasonForbidden
+}
+
 // IsInvalid determines if the err is an error which indicates the provided resource is not valid.
 func IsInvalid(err error) bool {
 	return reasonForError(err) == api.StatusReasonInvalid
 }
 
+// IsBadRequest determines if the err is an error which indicates that the request is bad.
+func IsBadRequest(err error) bool {
+	return reasonForError(err) == api.StatusReasonBadRequest
+}
+
+// IsTooManyRequests determines if the err is an error which indicates that the request should be retried later.
+func IsTooManyRequests(err error) bool {
+	return reasonForError(err) == api.StatusReasonTooManyRequests
+}
+
+// IsTimeout determines if the err is an error which indicates that the server gave up on the request.
+func IsTimeout(err error) bool {
+	return reasonForError(err) == api.StatusReasonTimeout
+}
+
 func reasonForError(err error) api.StatusReason {
 	switch t := err.(type) {
 	case *statusError:
@@ -50,6 +50,30 @@ func TestErrorNew(t *testing.T) {
 	if !IsInvalid(NewInvalid("test", "2", nil)) {
 		t.Errorf("expected to be %s", api.StatusReasonInvalid)
 	}
+	if !IsForbidden(NewForbidden("test", "4", errors.New("message"))) {
+		t.Errorf("expected to be %s", api.StatusReasonForbidden)
+	}
+	if IsForbidden(NewConflict("test", "5", errors.New("message"))) {
+		t.Errorf("expected to not be %s", api.StatusReasonForbidden)
+	}
+	if !IsBadRequest(NewBadRequest("reason")) {
+		t.Errorf("expected to be %s", api.StatusReasonBadRequest)
+	}
+	if IsBadRequest(NewInvalid("test", "6", nil)) {
+		t.Errorf("expected to not be %s", api.StatusReasonBadRequest)
+	}
+	if !IsTooManyRequests(NewTooManyRequests("message", 10)) {
+		t.Errorf("expected to be %s", api.StatusReasonTooManyRequests)
+	}
+	if IsTooManyRequests(NewBadRequest("reason")) {
+		t.Errorf("expected to not be %s", api.StatusReasonTooManyRequests)
+	}
+	if !IsTimeout(NewTimeout("test", "7", errors.New("message"))) {
+		t.Errorf("expected to be %s", api.StatusReasonTimeout)
+	}
+	if IsTimeout(NewConflict("test", "8", errors.New("message"))) {
+		t.Errorf("expected to not be %s", api.StatusReasonTimeout)
+	}
 }
 
 func TestNewInvalid(t *testing.T) {
@@ -18,6 +18,7 @@ package api
 
 import (
 	"strings"
+	"time"
 )
 
 func IsPullAlways(p PullPolicy) bool {
@@ -39,3 +40,22 @@ func IsPullIfNotPresent(p PullPolicy) bool {
 func pullPoliciesEqual(p1, p2 PullPolicy) bool {
 	return strings.ToLower(string(p1)) == strings.ToLower(string(p2))
 }
+
+// IsPodAvailable returns true if the pod is running and every container in its manifest
+// has been running for at least minReadySeconds as of now.
+func IsPodAvailable(pod *Pod, minReadySeconds int, now time.Time) bool {
+	if pod.CurrentState.Status != PodRunning {
+		return false
+	}
+	minReady := time.Duration(minReadySeconds) * time.Second
+	for _, container := range pod.DesiredState.Manifest.Containers {
+		status, ok := pod.CurrentState.Info[container.Name]
+		if !ok || status.State.Running == nil {
+			return false
+		}
+		if status.State.Running.StartedAt.Add(minReady).After(now) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsPodAvailable(t *testing.T) {
+	now := time.Now()
+	makePod := func(status PodStatus, startedAt time.Time) *Pod {
+		return &Pod{
+			DesiredState: PodState{
+				Manifest: ContainerManifest{
+					Containers: []Container{{Name: "foo"}},
+				},
+			},
+			CurrentState: PodState{
+				Status: status,
+				Info: PodInfo{
+					"foo": ContainerStatus{
+						State: ContainerState{
+							Running: &ContainerStateRunning{StartedAt: startedAt},
+						},
+					},
+				},
+			},
+		}
+	}
+	table := []struct {
+		pod             *Pod
+		minReadySeconds int
+		expected        bool
+	}{
+		{makePod(PodRunning, now), 0, true},
+		{makePod(PodRunning, now), 10, false},
+		{makePod(PodRunning, now.Add(-11*time.Second)), 10, true},
+		{makePod(PodWaiting, now.Add(-time.Hour)), 10, false},
+		{&Pod{
+			DesiredState: PodState{
+				Manifest: ContainerManifest{Containers: []Container{{Name: "foo"}}},
+			},
+			CurrentState: PodState{Status: PodRunning},
+		}, 0, false},
+	}
+	for i, item := range table {
+		if actual := IsPodAvailable(item.pod, item.minReadySeconds, now); actual != item.expected {
+			t.Errorf("%d: expected %v, got %v", i, item.expected, actual)
+		}
+	}
+}
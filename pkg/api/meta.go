@@ -0,0 +1,60 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/conversion"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// TypeMetaFor returns a pointer to the TypeMeta embedded in obj, or an error if obj
+// is not an internal API object.
+func TypeMetaFor(obj runtime.Object) (*TypeMeta, error) {
+	v, err := conversion.EnforcePtr(obj)
+	if err != nil {
+		return nil, err
+	}
+	field := v.FieldByName("TypeMeta")
+	if !field.IsValid() {
+		return nil, fmt.Errorf("%v lacks an embedded TypeMeta", v.Type())
+	}
+	meta, ok := field.Addr().Interface().(*TypeMeta)
+	if !ok {
+		return nil, fmt.Errorf("%v is not an internal API object", v.Type())
+	}
+	return meta, nil
+}
+
+// LabelsFor returns the labels of obj, or an error if obj is not an internal API
+// object that carries labels.
+func LabelsFor(obj runtime.Object) (map[string]string, error) {
+	v, err := conversion.EnforcePtr(obj)
+	if err != nil {
+		return nil, err
+	}
+	field := v.FieldByName("Labels")
+	if !field.IsValid() {
+		return nil, fmt.Errorf("%v lacks labels", v.Type())
+	}
+	labels, ok := field.Interface().(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("%v has labels of an unexpected type", v.Type())
+	}
+	return labels, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+)
+
+type externalObject struct {
+	TypeMeta struct {
+		ID string
+	}
+}
+
+func (*externalObject) IsAnAPIObject() {}
+
+func TestTypeMetaFor(t *testing.T) {
+	pod := &Pod{TypeMeta: TypeMeta{ID: "foo"}}
+	meta, err := TypeMetaFor(pod)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	meta.UID = "bar"
+	if pod.UID != "bar" {
+		t.Errorf("expected TypeMeta of the pod to be returned, got %#v", meta)
+	}
+
+	if _, err := TypeMetaFor(&FakeAPIObject{}); err == nil {
+		t.Errorf("expected error for object without TypeMeta")
+	}
+	if _, err := TypeMetaFor(&externalObject{}); err == nil {
+		t.Errorf("expected error for object with a foreign TypeMeta")
+	}
+}
+
+func TestLabelsFor(t *testing.T) {
+	pod := &Pod{Labels: map[string]string{"name": "foo"}}
+	labels, err := LabelsFor(pod)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if labels["name"] != "foo" {
+		t.Errorf("expected labels of the pod to be returned, got %#v", labels)
+	}
+
+	if _, err := LabelsFor(&Binding{}); err == nil {
+		t.Errorf("expected error for object without labels")
+	}
+}
@@ -41,12 +41,16 @@ func GetReference(obj runtime.Object) (*ObjectReference, error) {
 	if len(version) < 2 {
 		return nil, fmt.Errorf("unexpected self link format: %v", jsonBase.SelfLink())
 	}
+	// Objects created before UIDs were assigned are identified by their ID.
+	uid := jsonBase.ID()
+	if meta, err := TypeMetaFor(obj); err == nil && len(meta.UID) != 0 {
+		uid = meta.UID
+	}
 	return &ObjectReference{
-		Kind:       kind,
-		APIVersion: version[1],
-		// TODO: correct Name and UID when TypeMeta makes a distinction
+		Kind:            kind,
+		APIVersion:      version[1],
 		Name:            jsonBase.ID(),
-		UID:             jsonBase.ID(),
+		UID:             uid,
 		ResourceVersion: jsonBase.ResourceVersion(),
 	}, nil
 }
@@ -49,6 +49,23 @@ func TestGetReference(t *testing.T) {
 				ResourceVersion: "42",
 			},
 		},
+		"podWithUID": {
+			obj: &Pod{
+				TypeMeta: TypeMeta{
+					ID:              "foo",
+					UID:             "bar",
+					ResourceVersion: "42",
+					SelfLink:        "/api/v1beta1/pods/foo",
+				},
+			},
+			ref: &ObjectReference{
+				Kind:            "Pod",
+				APIVersion:      "v1beta1",
+				Name:            "foo",
+				UID:             "bar",
+				ResourceVersion: "42",
+			},
+		},
 		"serviceList": {
 			obj: &ServiceList{
 				TypeMeta: TypeMeta{
@@ -0,0 +1,25 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package strategicpatch applies strategic merge patches to api objects. A
+// strategic merge patch is a partial object in JSON. Fields of the patch
+// replace those of the object, except that nested objects are merged, null
+// removes a field, and lists whose field is tagged with patchMergeKey are
+// merged element by element, the elements being matched by that key. An
+// element of such a list with the directive "$patch": "delete" removes the
+// element with its key, and an object with the directive "$patch": "replace"
+// replaces the object it is merged with rather than being merged into it.
+package strategicpatch
@@ -0,0 +1,215 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategicpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+const (
+	// directiveKey is the key of the directive in an object of a patch.
+	directiveKey = "$patch"
+	// deleteDirective removes the element of a merged list with the same key.
+	deleteDirective = "delete"
+	// replaceDirective replaces an object instead of merging it.
+	replaceDirective = "replace"
+)
+
+// Merge applies patch, a strategic merge patch in the JSON encoding of codec,
+// to original and returns the result as a new object of the same type.
+// original is left unchanged.
+func Merge(codec runtime.Codec, original runtime.Object, patch []byte) (runtime.Object, error) {
+	data, err := codec.Encode(original)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := MergeJSON(data, patch, original)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.New(reflect.TypeOf(original).Elem()).Interface().(runtime.Object)
+	if err := codec.DecodeInto(merged, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MergeJSON applies patch, a strategic merge patch, to the JSON object
+// original. dataStruct is a value of the type original holds, whose
+// patchMergeKey struct tags tell how lists are merged.
+func MergeJSON(original, patch []byte, dataStruct interface{}) ([]byte, error) {
+	originalMap, err := decodeMap(original)
+	if err != nil {
+		return nil, err
+	}
+	patchMap, err := decodeMap(patch)
+	if err != nil {
+		return nil, fmt.Errorf("the patch is not a JSON object: %v", err)
+	}
+	result, err := mergeMap(originalMap, patchMap, reflect.TypeOf(dataStruct))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// decodeMap decodes the JSON object data. Numbers are kept as they are
+// written, so that large integers such as resource versions survive.
+func decodeMap(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	m := map[string]interface{}{}
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("null is not an object")
+	}
+	return m, nil
+}
+
+// mergeMap merges patch into original, which holds a value of type t, and
+// returns the result. t is nil where the type is not known, in which case
+// lists are replaced rather than merged.
+func mergeMap(original, patch map[string]interface{}, t reflect.Type) (map[string]interface{}, error) {
+	if directive, ok := patch[directiveKey]; ok {
+		if directive != replaceDirective {
+			return nil, fmt.Errorf("unsupported %s directive %v", directiveKey, directive)
+		}
+		original = map[string]interface{}{}
+	}
+	for key, patchValue := range patch {
+		if key == directiveKey {
+			continue
+		}
+		if patchValue == nil {
+			delete(original, key)
+			continue
+		}
+		fieldType, mergeKey := lookupField(t, key)
+		var err error
+		switch typedPatch := patchValue.(type) {
+		case map[string]interface{}:
+			originalValue, ok := original[key].(map[string]interface{})
+			if !ok {
+				originalValue = map[string]interface{}{}
+			}
+			original[key], err = mergeMap(originalValue, typedPatch, fieldType)
+		case []interface{}:
+			originalValue, _ := original[key].([]interface{})
+			var elemType reflect.Type
+			if fieldType != nil && fieldType.Kind() == reflect.Slice {
+				elemType = fieldType.Elem()
+			}
+			original[key], err = mergeList(originalValue, typedPatch, elemType, mergeKey)
+		default:
+			original[key] = patchValue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return original, nil
+}
+
+// mergeList merges patch into original, whose elements are of type elemType.
+// The elements of both are matched by mergeKey; without one, patch replaces
+// original.
+func mergeList(original, patch []interface{}, elemType reflect.Type, mergeKey string) ([]interface{}, error) {
+	if mergeKey == "" {
+		return patch, nil
+	}
+	result := append([]interface{}{}, original...)
+	for i, patchValue := range patch {
+		patchElem, ok := patchValue.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("element %d of the patch is not an object", i)
+		}
+		keyValue, ok := patchElem[mergeKey]
+		if !ok {
+			return nil, fmt.Errorf("element %d of the patch has no %q", i, mergeKey)
+		}
+		index := -1
+		for j, originalValue := range result {
+			if originalElem, ok := originalValue.(map[string]interface{}); ok && reflect.DeepEqual(originalElem[mergeKey], keyValue) {
+				index = j
+				break
+			}
+		}
+		if directive, ok := patchElem[directiveKey]; ok && directive == deleteDirective {
+			if index >= 0 {
+				result = append(result[:index], result[index+1:]...)
+			}
+			continue
+		}
+		if index < 0 {
+			merged, err := mergeMap(map[string]interface{}{}, patchElem, elemType)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			result = append(result, merged)
+			continue
+		}
+		originalElem, _ := result[index].(map[string]interface{})
+		merged, err := mergeMap(originalElem, patchElem, elemType)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %v", i, err)
+		}
+		result[index] = merged
+	}
+	return result, nil
+}
+
+// lookupField returns the type of the field of t with the JSON name
+// jsonName, and the patchMergeKey it is tagged with. The type is nil if it
+// is not known.
+func lookupField(t reflect.Type, jsonName string) (reflect.Type, string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, ""
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), ""
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.Anonymous && name == "" {
+				if fieldType, mergeKey := lookupField(field.Type, jsonName); fieldType != nil {
+					return fieldType, mergeKey
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if name == jsonName {
+				return field.Type, field.Tag.Get("patchMergeKey")
+			}
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategicpatch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/testapi"
+)
+
+func testPod() *api.Pod {
+	return &api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault, ResourceVersion: "5"},
+		Labels:   map[string]string{"name": "foo", "tier": "backend"},
+		DesiredState: api.PodState{
+			Manifest: api.ContainerManifest{
+				Version: "v1beta1",
+				ID:      "foo",
+				Containers: []api.Container{
+					{Name: "web", Image: "nginx", Ports: []api.Port{{ContainerPort: 80}, {ContainerPort: 443}}, Env: []api.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}},
+					{Name: "sidecar", Image: "logger"},
+				},
+				RestartPolicy: api.RestartPolicy{Always: &api.RestartPolicyAlways{}},
+			},
+		},
+	}
+}
+
+func TestMergePod(t *testing.T) {
+	table := map[string]struct {
+		patch  string
+		modify func(*api.Pod)
+	}{
+		"merge containers by name": {
+			patch: `{"desiredState": {"manifest": {"containers": [
+				{"name": "web", "image": "nginx:1.7", "env": [{"name": "B", "value": "3"}]},
+				{"name": "metrics", "image": "exporter"}
+			]}}}`,
+			modify: func(pod *api.Pod) {
+				web := &pod.DesiredState.Manifest.Containers[0]
+				web.Image = "nginx:1.7"
+				web.Env[1].Value = "3"
+				pod.DesiredState.Manifest.Containers = append(pod.DesiredState.Manifest.Containers, api.Container{Name: "metrics", Image: "exporter"})
+			},
+		},
+		"remove a container": {
+			patch: `{"desiredState": {"manifest": {"containers": [{"name": "sidecar", "$patch": "delete"}]}}}`,
+			modify: func(pod *api.Pod) {
+				pod.DesiredState.Manifest.Containers = pod.DesiredState.Manifest.Containers[:1]
+			},
+		},
+		"overwrite a scalar field": {
+			patch: `{"desiredState": {"manifest": {"restartPolicy": {"always": null, "never": {}}}}, "labels": {"tier": "frontend"}}`,
+			modify: func(pod *api.Pod) {
+				pod.DesiredState.Manifest.RestartPolicy = api.RestartPolicy{Never: &api.RestartPolicyNever{}}
+				pod.Labels["tier"] = "frontend"
+			},
+		},
+		"remove a label": {
+			patch: `{"labels": {"tier": null}}`,
+			modify: func(pod *api.Pod) {
+				delete(pod.Labels, "tier")
+			},
+		},
+		"replace a list without a merge key": {
+			patch: `{"desiredState": {"manifest": {"containers": [{"name": "web", "ports": [{"containerPort": 8080}]}]}}}`,
+			modify: func(pod *api.Pod) {
+				pod.DesiredState.Manifest.Containers[0].Ports = []api.Port{{ContainerPort: 8080}}
+			},
+		},
+	}
+	for name, item := range table {
+		original := testPod()
+		expected := testPod()
+		item.modify(expected)
+
+		out, err := Merge(testapi.Codec(), original, []byte(item.patch))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, expected) {
+			t.Errorf("%s: expected\n%#v\ngot\n%#v", name, expected, out)
+		}
+		if !reflect.DeepEqual(original, testPod()) {
+			t.Errorf("%s: expected the original pod to be unchanged, got %#v", name, original)
+		}
+	}
+}
+
+func TestMergeJSONErrors(t *testing.T) {
+	table := map[string]string{
+		"not an object":         `["foo"]`,
+		"null":                  `null`,
+		"element without a key": `{"desiredState": {"manifest": {"containers": [{"image": "nginx"}]}}}`,
+		"element not an object": `{"desiredState": {"manifest": {"containers": ["web"]}}}`,
+		"unknown directive":     `{"desiredState": {"$patch": "merge"}}`,
+	}
+	original := []byte(`{"id": "foo", "desiredState": {"manifest": {"containers": [{"name": "web"}]}}}`)
+	for name, patch := range table {
+		if _, err := MergeJSON(original, []byte(patch), api.Pod{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMergeJSONReplaceDirective(t *testing.T) {
+	original := []byte(`{"labels": {"name": "foo", "tier": "backend"}}`)
+	out, err := MergeJSON(original, []byte(`{"labels": {"$patch": "replace", "app": "bar"}}`), api.Pod{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := `{"labels":{"app":"bar"}}`, string(out); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+}
@@ -31,9 +31,14 @@ func init() {
 			out.Kind = in.Kind
 			out.Namespace = in.Namespace
 			out.ID = in.ID
+			out.UID = in.UID
 			out.CreationTimestamp = in.CreationTimestamp
 			out.SelfLink = in.SelfLink
+			out.Continue = in.Continue
 			out.Annotations = in.Annotations
+			if err := s.Convert(&in.OwnerReferences, &out.OwnerReferences, 0); err != nil {
+				return err
+			}
 
 			if len(in.ResourceVersion) > 0 {
 				v, err := strconv.ParseUint(in.ResourceVersion, 10, 64)
@@ -49,9 +54,14 @@ func init() {
 			out.Kind = in.Kind
 			out.Namespace = in.Namespace
 			out.ID = in.ID
+			out.UID = in.UID
 			out.CreationTimestamp = in.CreationTimestamp
 			out.SelfLink = in.SelfLink
+			out.Continue = in.Continue
 			out.Annotations = in.Annotations
+			if err := s.Convert(&in.OwnerReferences, &out.OwnerReferences, 0); err != nil {
+				return err
+			}
 
 			if in.ResourceVersion != 0 {
 				out.ResourceVersion = strconv.FormatUint(in.ResourceVersion, 10)
@@ -64,7 +74,7 @@ func init() {
 			out.Value = in.Value
 			out.Key = in.Name
 			out.Name = in.Name
-			return nil
+			return s.Convert(&in.ValueFrom, &out.ValueFrom, 0)
 		},
 		func(in *EnvVar, out *newer.EnvVar, s conversion.Scope) error {
 			out.Value = in.Value
@@ -73,7 +83,7 @@ func init() {
 			} else {
 				out.Name = in.Key
 			}
-			return nil
+			return s.Convert(&in.ValueFrom, &out.ValueFrom, 0)
 		},
 
 		// Path & MountType are deprecated.
@@ -140,6 +140,32 @@ type EnvVar struct {
 	Name string `yaml:"name" json:"name"`
 	// Optional: defaults to "".
 	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Optional: the source of the value, if Value is not set.
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty" json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource is the source of the value of an EnvVar.
+type EnvVarSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap in the pod's namespace.
+	ConfigMapKeyRef *ConfigMapKeySelector `json:"configMapKeyRef,omitempty" yaml:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects a key of a Secret in the pod's namespace.
+	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty" yaml:"secretKeyRef,omitempty"`
+}
+
+// ConfigMapKeySelector selects a key of a ConfigMap.
+type ConfigMapKeySelector struct {
+	// The name of the ConfigMap.
+	Name string `json:"name" yaml:"name"`
+	// The key whose value to select.
+	Key string `json:"key" yaml:"key"`
+}
+
+// SecretKeySelector selects a key of a Secret.
+type SecretKeySelector struct {
+	// The name of the Secret.
+	Name string `json:"name" yaml:"name"`
+	// The key whose value to select.
+	Key string `json:"key" yaml:"key"`
 }
 
 // HTTPGetAction describes an action based on HTTP Get requests.
@@ -199,12 +225,14 @@ type Container struct {
 	Name string `yaml:"name" json:"name"`
 	// Required.
 	Image string `yaml:"image" json:"image"`
+	// Optional: Pins Image to this content digest, of the form "sha256:<64 hex digits>".
+	ImageDigest string `yaml:"imageDigest,omitempty" json:"imageDigest,omitempty"`
 	// Optional: Defaults to whatever is defined in the image.
 	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
 	// Optional: Defaults to Docker's default.
 	WorkingDir string   `yaml:"workingDir,omitempty" json:"workingDir,omitempty"`
 	Ports      []Port   `yaml:"ports,omitempty" json:"ports,omitempty"`
-	Env        []EnvVar `yaml:"env,omitempty" json:"env,omitempty"`
+	Env        []EnvVar `yaml:"env,omitempty" json:"env,omitempty" patchMergeKey:"name"`
 	// Optional: Defaults to unlimited.
 	Memory int `yaml:"memory,omitempty" json:"memory,omitempty"`
 	// Optional: Defaults to unlimited.
@@ -257,6 +285,24 @@ type TypeMeta struct {
 	// external tooling. They are not queryable and should be preserved when modifying
 	// objects.
 	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+
+	// The objects this object depends on. Once all of them are deleted, this
+	// object is deleted by the garbage collector.
+	OwnerReferences []OwnerReference `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
+
+	// Continue is set on a list that holds only a page of its items. Passing it
+	// back as the continue parameter lists the items after the page.
+	Continue string `json:"continue,omitempty" yaml:"continue,omitempty"`
+}
+
+// OwnerReference identifies an object which owns the object it is set on.
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Name       string `json:"name" yaml:"name"`
+	// The UID of the owner. An object of the same kind and name with another
+	// UID is not the owner.
+	UID string `json:"uid,omitempty" yaml:"uid,omitempty"`
 }
 
 // PodStatus represents a status of a pod.
@@ -339,6 +385,14 @@ type PodState struct {
 	HostIP   string            `json:"hostIP,omitempty" yaml:"hostIP,omitempty"`
 	PodIP    string            `json:"podIP,omitempty" yaml:"podIP,omitempty"`
 
+	// ServiceAccount is the name of the ServiceAccount, in the pod's namespace,
+	// that the pod runs as.
+	ServiceAccount string `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+
+	// NodeSelector restricts the pod to minions whose labels match every
+	// key/value pair in the map.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+
 	// The key of this map is the *name* of the container within the manifest; it has one
 	// entry per container in the manifest. The value of this map is currently the output
 	// of `docker inspect`. This output format is *not* final and should not be relied
@@ -367,6 +421,28 @@ type ReplicationControllerState struct {
 	Replicas        int               `json:"replicas" yaml:"replicas"`
 	ReplicaSelector map[string]string `json:"replicaSelector,omitempty" yaml:"replicaSelector,omitempty"`
 	PodTemplate     PodTemplate       `json:"podTemplate,omitempty" yaml:"podTemplate,omitempty"`
+	// Optional: Number of seconds every container of a new pod must have been running
+	// before the pod is considered available. Defaults to 0 (available as soon as it is running).
+	MinReadySeconds int `json:"minReadySeconds,omitempty" yaml:"minReadySeconds,omitempty"`
+	// Optional: How pods made from an earlier template are replaced. If unset, they are
+	// kept until they are deleted.
+	RollingUpdateStrategy *RollingUpdateStrategy `json:"rollingUpdateStrategy,omitempty" yaml:"rollingUpdateStrategy,omitempty"`
+}
+
+// RollingUpdateStrategy describes how the pods of a replication controller are
+// gradually replaced by pods from its template once the template changes.
+type RollingUpdateStrategy struct {
+	// Optional: Number of pods which may be unavailable during the update, below the
+	// desired number of replicas.
+	MaxUnavailable int `json:"maxUnavailable,omitempty" yaml:"maxUnavailable,omitempty"`
+	// Optional: Number of pods which may be created during the update, above the desired
+	// number of replicas. At least one of MaxUnavailable and MaxSurge must be positive.
+	MaxSurge int `json:"maxSurge,omitempty" yaml:"maxSurge,omitempty"`
+	// Optional: Number of seconds every container of a new pod must have been running
+	// before the update goes on past it. Defaults to 0.
+	MinReadySeconds int `json:"minReadySeconds,omitempty" yaml:"minReadySeconds,omitempty"`
+	// Optional: Stops the update where it is until it is unset.
+	Paused bool `json:"paused,omitempty" yaml:"paused,omitempty"`
 }
 
 // ReplicationControllerList is a collection of replication controllers.
@@ -442,6 +518,64 @@ type ResourceName string
 
 type ResourceList map[ResourceName]util.IntOrString
 
+// NodeAddressType describes how an address of a minion can be reached.
+type NodeAddressType string
+
+const (
+	NodeInternalIP  NodeAddressType = "InternalIP"
+	NodeExternalIP  NodeAddressType = "ExternalIP"
+	NodeHostname    NodeAddressType = "Hostname"
+	NodeInternalDNS NodeAddressType = "InternalDNS"
+	NodeExternalDNS NodeAddressType = "ExternalDNS"
+)
+
+// NodeAddress is an address of a minion, along with its type.
+type NodeAddress struct {
+	Type    NodeAddressType `json:"type" yaml:"type"`
+	Address string          `json:"address" yaml:"address"`
+}
+
+// NodeConditionType is a kind of condition a minion reports about itself.
+type NodeConditionType string
+
+const (
+	// NodeReady means the kubelet is healthy and ready to run pods.
+	NodeReady NodeConditionType = "Ready"
+	// NodeOutOfDisk means the minion has too little free disk space to start pods.
+	NodeOutOfDisk NodeConditionType = "OutOfDisk"
+	// NodeMemoryPressure means the minion is running low on memory.
+	NodeMemoryPressure NodeConditionType = "MemoryPressure"
+)
+
+// ConditionStatus is whether a condition holds.
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// NodeCondition is a condition of a minion, as last reported by its kubelet.
+type NodeCondition struct {
+	Type   NodeConditionType `json:"type" yaml:"type"`
+	Status ConditionStatus   `json:"status" yaml:"status"`
+	// The last time the kubelet reported the condition.
+	LastHeartbeatTime util.Time `json:"lastHeartbeatTime,omitempty" yaml:"lastHeartbeatTime,omitempty"`
+	// The last time the condition went from one status to another.
+	LastTransitionTime util.Time `json:"lastTransitionTime,omitempty" yaml:"lastTransitionTime,omitempty"`
+	// A brief CamelCase reason for the last transition.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// A human readable description of the last transition.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// NodeStatus is information about the current status of a minion.
+type NodeStatus struct {
+	// Conditions the minion was last observed in, at most one of each type.
+	Conditions []NodeCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
 // Minion is a worker node in Kubernetenes.
 // The name of the minion according to etcd is in TypeMeta.ID.
 type Minion struct {
@@ -450,6 +584,13 @@ type Minion struct {
 	HostIP string `json:"hostIP,omitempty" yaml:"hostIP,omitempty"`
 	// Resources available on the node
 	NodeResources NodeResources `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// Labels for the minion
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Addresses at which the minion can be reached, at most one of each type.
+	Addresses []NodeAddress `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	// Status is reported by the kubelet of the minion, through the status
+	// subresource.
+	Status NodeStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
 // MinionList is a list of minions.
@@ -504,6 +645,9 @@ type StatusDetails struct {
 	// The Causes array includes more details associated with the StatusReason
 	// failure. Not all StatusReasons may provide detailed causes.
 	Causes []StatusCause `json:"causes,omitempty" yaml:"causes,omitempty"`
+	// If set, the number of seconds the client should wait before retrying
+	// the operation.
+	RetryAfterSeconds int `json:"retryAfterSeconds,omitempty" yaml:"retryAfterSeconds,omitempty"`
 }
 
 // Values of Status.Status
@@ -561,6 +705,14 @@ const (
 	// Status code 409
 	StatusReasonConflict StatusReason = "Conflict"
 
+	// StatusReasonForbidden means the server understood the request but refuses
+	// to perform it, for example because an admission control plugin rejected it.
+	// Details (optional):
+	//   "kind" string - the kind attribute of the forbidden resource
+	//   "id"   string - the identifier of the forbidden resource
+	// Status code 403
+	StatusReasonForbidden StatusReason = "Forbidden"
+
 	// StatusReasonInvalid means the requested create or update operation cannot be
 	// completed due to invalid data provided as part of the request. The client may
 	// need to alter the request. When set, the client may use the StatusDetails
@@ -573,6 +725,36 @@ const (
 	//                   field attributes will be set.
 	// Status code 422
 	StatusReasonInvalid StatusReason = "Invalid"
+
+	// StatusReasonBadRequest means that the request itself was invalid, because the request
+	// doesn't make any sense, for example deleting a read-only object.  This is different than
+	// StatusReasonInvalid above which indicates that the API call could possibly succeed, but the
+	// data was invalid.
+	// Status code 400
+	StatusReasonBadRequest StatusReason = "BadRequest"
+
+	// StatusReasonGone means the resource is no longer available at the server and no
+	// forwarding address is known.  The client should not retry the request.
+	// Status code 410
+	StatusReasonGone StatusReason = "Gone"
+
+	// StatusReasonTooManyRequests means the server has received more requests from the
+	// client than it is willing to handle.  The client may retry the request after a delay.
+	// Status code 429
+	StatusReasonTooManyRequests StatusReason = "TooManyRequests"
+
+	// StatusReasonInternalError indicates that an internal error occurred, it is unexpected
+	// and the outcome of the call is unknown.
+	// Details (optional):
+	//   "causes" - The original error
+	// Status code 500
+	StatusReasonInternalError StatusReason = "InternalError"
+
+	// StatusReasonTimeout means that the request could not be completed within the given time.
+	// Clients may receive this response if the server has decided to rate limit the client, or
+	// if the server is overloaded and cannot process the request at this time.
+	// Status code 504
+	StatusReasonTimeout StatusReason = "Timeout"
 )
 
 // StatusCause provides more information about an api.Status failure, including
@@ -679,6 +861,16 @@ type Event struct {
 	// Optional. The component reporting this event. Should be a short machine understandable string.
 	// TODO: provide exact specification for format.
 	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// The time at which the event was first recorded.
+	FirstTimestamp util.Time `json:"firstTimestamp,omitempty" yaml:"firstTimestamp,omitempty"`
+
+	// The time at which the most recent occurrence of the event was recorded.
+	LastTimestamp util.Time `json:"lastTimestamp,omitempty" yaml:"lastTimestamp,omitempty"`
+
+	// The number of times the event has occurred, counting repeats recorded
+	// within the aggregation window of the master.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
 }
 
 // EventList is a list of events.
@@ -687,6 +879,536 @@ type EventList struct {
 	Items    []Event `yaml:"items,omitempty" json:"items,omitempty"`
 }
 
+// Secret holds sensitive data, such as credentials, which pods may need.
+type Secret struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Data maps keys to the base64 encoded secret values.
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// SecretList is a list of secrets. The values of the secrets are not included.
+type SecretList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []Secret `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// Namespace is a scope for the names of the objects created in it. Namespaces
+// are not themselves in a namespace.
+type Namespace struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// NamespaceList is a list of namespaces.
+type NamespaceList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []Namespace `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// ResourceQuota sets limits on the total resources that may be used by the
+// objects in a namespace.
+type ResourceQuota struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Hard is the limit for each named resource. Resources that are not listed
+	// are not limited.
+	Hard ResourceList `json:"hard,omitempty" yaml:"hard,omitempty"`
+	// Status is the usage of the namespace, as observed by the system.
+	Status ResourceQuotaStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// ResourceQuotaStatus is the enforced limits and observed usage of a namespace.
+type ResourceQuotaStatus struct {
+	Hard ResourceList `json:"hard,omitempty" yaml:"hard,omitempty"`
+	Used ResourceList `json:"used,omitempty" yaml:"used,omitempty"`
+}
+
+// ResourceQuotaList is a list of resource quotas.
+type ResourceQuotaList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []ResourceQuota `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// ServiceAccount is an identity that the processes in a pod can run as. Pods
+// name the account they run as in their desired state.
+type ServiceAccount struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Secrets are the secrets, in the account's namespace, that hold the
+	// account's credentials.
+	Secrets []ObjectReference `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+}
+
+// ServiceAccountList is a list of service accounts.
+type ServiceAccountList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []ServiceAccount `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// HorizontalPodAutoscaler scales a replication controller so that the CPU
+// utilization of its pods stays near a target.
+type HorizontalPodAutoscaler struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Spec defines the controller to scale and how to scale it.
+	Spec HorizontalPodAutoscalerSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// Status is the state of the autoscaler, as observed by the system.
+	Status HorizontalPodAutoscalerStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// HorizontalPodAutoscalerSpec is the desired behavior of an autoscaler.
+type HorizontalPodAutoscalerSpec struct {
+	// ScaleTargetRef refers to the replication controller, in the autoscaler's
+	// namespace, to scale.
+	ScaleTargetRef ObjectReference `json:"scaleTargetRef" yaml:"scaleTargetRef"`
+	// MinReplicas and MaxReplicas bound the number of replicas the autoscaler
+	// may set.
+	MinReplicas int `json:"minReplicas" yaml:"minReplicas"`
+	MaxReplicas int `json:"maxReplicas" yaml:"maxReplicas"`
+	// TargetCPUUtilization is the average CPU utilization of the pods, as a
+	// percentage of the CPU they request, that the autoscaler aims for.
+	TargetCPUUtilization int `json:"targetCPUUtilization" yaml:"targetCPUUtilization"`
+}
+
+// HorizontalPodAutoscalerStatus is the most recently observed state of an autoscaler.
+type HorizontalPodAutoscalerStatus struct {
+	CurrentReplicas       int `json:"currentReplicas,omitempty" yaml:"currentReplicas,omitempty"`
+	DesiredReplicas       int `json:"desiredReplicas,omitempty" yaml:"desiredReplicas,omitempty"`
+	CurrentCPUUtilization int `json:"currentCPUUtilization,omitempty" yaml:"currentCPUUtilization,omitempty"`
+}
+
+// HorizontalPodAutoscalerList is a list of autoscalers.
+type HorizontalPodAutoscalerList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []HorizontalPodAutoscaler `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// LimitType is the kind of object a LimitRangeItem bounds.
+type LimitType string
+
+const (
+	// LimitTypePod bounds the total resources of the containers in a pod.
+	LimitTypePod LimitType = "Pod"
+	// LimitTypeContainer bounds the resources of each container.
+	LimitTypeContainer LimitType = "Container"
+)
+
+// LimitRangeItem bounds the resources of one kind of object.
+type LimitRangeItem struct {
+	Type LimitType `json:"type,omitempty" yaml:"type,omitempty"`
+	// Max and Min bound the amount of each named resource.
+	Max ResourceList `json:"max,omitempty" yaml:"max,omitempty"`
+	Min ResourceList `json:"min,omitempty" yaml:"min,omitempty"`
+	// Default is the amount of each named resource given to containers which
+	// do not specify one. Only containers have defaults.
+	Default ResourceList `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// LimitRange sets bounds on, and defaults for, the resources of the objects
+// in a namespace.
+type LimitRange struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	Limits []LimitRangeItem `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
+
+// LimitRangeList is a list of limit ranges.
+type LimitRangeList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []LimitRange `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// ConfigMap holds configuration data for pods to consume.
+type ConfigMap struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Data maps keys to configuration values.
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// ConfigMapList is a list of config maps.
+type ConfigMapList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []ConfigMap `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// DaemonSet runs a copy of a pod on every minion its node selector matches.
+type DaemonSet struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Spec defines the pods to run and where to run them.
+	Spec DaemonSetSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// Status is the state of the daemon set, as observed by the system.
+	Status DaemonSetStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// DaemonSetSpec is the desired behavior of a daemon set.
+type DaemonSetSpec struct {
+	// NodeSelector selects, by their labels, the minions to run a pod on. An
+	// empty selector selects every minion.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+	// Template describes the pods to run.
+	Template PodTemplate `json:"template" yaml:"template"`
+}
+
+// DaemonSetStatus is the most recently observed state of a daemon set.
+type DaemonSetStatus struct {
+	// CurrentNumberScheduled is the number of minions running a pod of the set.
+	CurrentNumberScheduled int `json:"currentNumberScheduled" yaml:"currentNumberScheduled"`
+	// DesiredNumberScheduled is the number of minions the set selects.
+	DesiredNumberScheduled int `json:"desiredNumberScheduled" yaml:"desiredNumberScheduled"`
+}
+
+// DaemonSetList is a list of daemon sets.
+type DaemonSetList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []DaemonSet `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// Job runs pods from a template until a number of them succeed.
+type Job struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Spec defines the pods to run and how many must succeed.
+	Spec JobSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// Status is the state of the job, as observed by the system.
+	Status JobStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// JobSpec is the desired behavior of a job.
+type JobSpec struct {
+	// Completions is the number of pods which must succeed for the job to be
+	// complete.
+	Completions int `json:"completions" yaml:"completions"`
+	// Parallelism is the largest number of pods the job runs at once.
+	Parallelism int `json:"parallelism" yaml:"parallelism"`
+	// Template describes the pods to run. Their restart policy may not be
+	// always.
+	Template PodTemplate `json:"template" yaml:"template"`
+}
+
+// JobStatus is the most recently observed state of a job.
+type JobStatus struct {
+	// Active is the number of pods of the job which have not finished.
+	Active int `json:"active" yaml:"active"`
+	// Succeeded is the number of pods of the job which exited successfully.
+	Succeeded int `json:"succeeded" yaml:"succeeded"`
+	// Failed is the number of pods of the job which failed.
+	Failed int `json:"failed" yaml:"failed"`
+	// Complete is set once Completions pods have succeeded.
+	Complete bool `json:"complete,omitempty" yaml:"complete,omitempty"`
+}
+
+// JobList is a list of jobs.
+type JobList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []Job `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// Ingress routes HTTP requests to services by their host and path.
+type Ingress struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Spec defines the rules requests are routed by.
+	Spec IngressSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// IngressSpec describes how an ingress routes requests.
+type IngressSpec struct {
+	// Backend receives the requests no rule matches. Optional.
+	Backend *IngressBackend `json:"backend,omitempty" yaml:"backend,omitempty"`
+	// Rules route requests by their host and path.
+	Rules []IngressRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// TLS terminates HTTPS for some of the hosts of the rules.
+	TLS []IngressTLS `json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// IngressTLS serves the certificate in a secret for some hosts.
+type IngressTLS struct {
+	// Hosts are the hosts the certificate is served for.
+	Hosts []string `json:"hosts" yaml:"hosts"`
+	// SecretName is the name of a secret, in the ingress's namespace, which
+	// holds the certificate under "tls.crt" and its private key under
+	// "tls.key".
+	SecretName string `json:"secretName" yaml:"secretName"`
+}
+
+// IngressRule routes the requests for a host by their path.
+type IngressRule struct {
+	// Host is the fully qualified domain name the rule matches. An empty host
+	// matches every host.
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+	// Paths route the requests for Host to backends.
+	Paths []IngressPath `json:"paths" yaml:"paths"`
+}
+
+// IngressPath routes the requests whose path starts with Path to a backend.
+type IngressPath struct {
+	// Path is the prefix of the request paths which are routed. It must start
+	// with "/".
+	Path string `json:"path" yaml:"path"`
+	// Backend receives the requests.
+	Backend IngressBackend `json:"backend" yaml:"backend"`
+}
+
+// IngressBackend is a port of a service, in the ingress's namespace, which
+// receives requests.
+type IngressBackend struct {
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	ServicePort int    `json:"servicePort" yaml:"servicePort"`
+}
+
+// IngressList is a list of ingresses.
+type IngressList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []Ingress `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// NetworkPolicy describes which pods may send traffic to a set of pods.
+type NetworkPolicy struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Spec defines the pods the policy isolates and the traffic it allows.
+	Spec NetworkPolicySpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// NetworkPolicySpec is the desired behavior of a network policy.
+type NetworkPolicySpec struct {
+	// PodSelector is a label selector for the pods in the policy's namespace
+	// that the policy applies to. An empty selector selects every pod.
+	PodSelector string `json:"podSelector,omitempty" yaml:"podSelector,omitempty"`
+	// Ingress lists the traffic the selected pods accept. Traffic which no
+	// rule allows is dropped.
+	Ingress []NetworkPolicyIngressRule `json:"ingress,omitempty" yaml:"ingress,omitempty"`
+}
+
+// NetworkPolicyIngressRule allows traffic from some sources to some ports.
+type NetworkPolicyIngressRule struct {
+	// Ports the traffic may be sent to. Empty allows every port.
+	Ports []NetworkPolicyPort `json:"ports,omitempty" yaml:"ports,omitempty"`
+	// From lists the sources the traffic may come from. Empty allows every
+	// source.
+	From []NetworkPolicyPeer `json:"from,omitempty" yaml:"from,omitempty"`
+}
+
+// NetworkPolicyPort is a port and protocol traffic may be sent to.
+type NetworkPolicyPort struct {
+	// Optional: Defaults to "TCP".
+	Protocol Protocol `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Port     int      `json:"port" yaml:"port"`
+}
+
+// NetworkPolicyPeer is a source of traffic. Exactly one of its selectors must
+// be set.
+type NetworkPolicyPeer struct {
+	// PodSelector is a label selector for pods in the policy's namespace.
+	PodSelector string `json:"podSelector,omitempty" yaml:"podSelector,omitempty"`
+	// NamespaceSelector is a label selector for namespaces, all of whose pods
+	// are selected.
+	NamespaceSelector string `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+}
+
+// NetworkPolicyList is a list of network policies.
+type NetworkPolicyList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []NetworkPolicy `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// PodDisruptionBudget limits how many of a set of pods may be evicted at once.
+type PodDisruptionBudget struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Spec defines the pods the budget covers and how many must stay available.
+	Spec PodDisruptionBudgetSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// PodDisruptionBudgetSpec is the desired behavior of a pod disruption budget.
+type PodDisruptionBudgetSpec struct {
+	// Selector is a label selector for the pods in the budget's namespace that
+	// the budget covers.
+	Selector string `json:"selector" yaml:"selector"`
+	// MinAvailable is the number of covered pods which must be bound to a
+	// minion after an eviction.
+	MinAvailable int `json:"minAvailable" yaml:"minAvailable"`
+}
+
+// PodDisruptionBudgetList is a list of pod disruption budgets.
+type PodDisruptionBudgetList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []PodDisruptionBudget `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// Eviction asks for a pod to be deleted, unless deleting it would violate a
+// disruption budget covering it. Its ID is the ID of the pod.
+type Eviction struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+}
+
+// Drain asks for the pods bound to a minion to be evicted, in the order of
+// their quality of service classes. Its ID is the ID of the minion.
+type Drain struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// IgnoreDaemonSets leaves the pods of daemon sets on the minion. Without it,
+	// a minion running such pods is not drained.
+	IgnoreDaemonSets bool `json:"ignoreDaemonSets,omitempty" yaml:"ignoreDaemonSets,omitempty"`
+}
+
+// ComponentConditionType is a kind of condition of a master component.
+type ComponentConditionType string
+
+const (
+	// ComponentHealthy means the component passed its last health check.
+	ComponentHealthy ComponentConditionType = "Healthy"
+)
+
+// ComponentCondition is a condition of a master component, as of its last
+// health check.
+type ComponentCondition struct {
+	Type   ComponentConditionType `json:"type" yaml:"type"`
+	Status ConditionStatus        `json:"status" yaml:"status"`
+	// The last time the component was checked.
+	LastProbeTime util.Time `json:"lastProbeTime,omitempty" yaml:"lastProbeTime,omitempty"`
+	// The error of the last failed health check.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ComponentStatus is the health of a component the master depends on, such as
+// etcd or the scheduler. Its ID is the name of the component.
+type ComponentStatus struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	// Conditions the component was last observed in, at most one of each type.
+	Conditions []ComponentCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// ComponentStatusList is a list of component statuses.
+type ComponentStatusList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []ComponentStatus `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// PolicyRule allows some verbs on some resources.
+type PolicyRule struct {
+	// Verbs are the operations allowed: get, list, watch, create, update,
+	// delete or proxy. "*" allows every verb.
+	Verbs []string `json:"verbs" yaml:"verbs"`
+	// Resources are the names resources are served under, e.g. "pods" or
+	// "pods/log". "*" allows every resource.
+	Resources []string `json:"resources" yaml:"resources"`
+}
+
+// Role is a set of rules which apply within the namespace of the role.
+type Role struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Rules    []PolicyRule `json:"rules" yaml:"rules"`
+}
+
+// RoleList is a list of roles.
+type RoleList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []Role `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// ClusterRole is a set of rules which apply in every namespace, and to the
+// resources which are not in a namespace.
+type ClusterRole struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Rules    []PolicyRule `json:"rules" yaml:"rules"`
+}
+
+// ClusterRoleList is a list of cluster roles.
+type ClusterRoleList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []ClusterRole `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// SubjectKindUser is the kind of the subjects which are users.
+const SubjectKindUser = "User"
+
+// Subject is who a binding grants a role to.
+type Subject struct {
+	// Kind of the subject. Only SubjectKindUser is supported.
+	Kind string `json:"kind" yaml:"kind"`
+	// Name of the subject, e.g. the name of a user.
+	Name string `json:"name" yaml:"name"`
+}
+
+const (
+	// RoleRefKindRole refers to a Role in the namespace of the binding.
+	RoleRefKindRole = "Role"
+	// RoleRefKindClusterRole refers to a ClusterRole.
+	RoleRefKindClusterRole = "ClusterRole"
+)
+
+// RoleRef names the role a binding grants.
+type RoleRef struct {
+	// Kind is RoleRefKindRole or RoleRefKindClusterRole.
+	Kind string `json:"kind" yaml:"kind"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// RoleBinding grants a role to its subjects within the namespace of the
+// binding. It may refer to a ClusterRole, whose rules then only apply in that
+// namespace.
+type RoleBinding struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Subjects []Subject `json:"subjects" yaml:"subjects"`
+	RoleRef  RoleRef   `json:"roleRef" yaml:"roleRef"`
+}
+
+// RoleBindingList is a list of role bindings.
+type RoleBindingList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []RoleBinding `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// ClusterRoleBinding grants a ClusterRole to its subjects in every namespace.
+type ClusterRoleBinding struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Subjects []Subject `json:"subjects" yaml:"subjects"`
+	// RoleRef must refer to a ClusterRole.
+	RoleRef RoleRef `json:"roleRef" yaml:"roleRef"`
+}
+
+// ClusterRoleBindingList is a list of cluster role bindings.
+type ClusterRoleBindingList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []ClusterRoleBinding `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// Lease is a lock with a holder, which the holder renews periodically so that
+// others can tell it is still alive.
+type Lease struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Spec identifies the holder of the lease and when it was last renewed.
+	Spec LeaseSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// LeaseSpec is the state of a lease.
+type LeaseSpec struct {
+	// HolderIdentity identifies the current holder of the lease.
+	HolderIdentity string `json:"holderIdentity,omitempty" yaml:"holderIdentity,omitempty"`
+	// LeaseDurationSeconds is how long after RenewTime the lease is stale.
+	LeaseDurationSeconds int `json:"leaseDurationSeconds,omitempty" yaml:"leaseDurationSeconds,omitempty"`
+	// AcquireTime is when the current holder acquired the lease.
+	AcquireTime util.Time `json:"acquireTime,omitempty" yaml:"acquireTime,omitempty"`
+	// RenewTime is when the current holder last renewed the lease.
+	RenewTime util.Time `json:"renewTime,omitempty" yaml:"renewTime,omitempty"`
+}
+
+// LeaseList is a list of leases.
+type LeaseList struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Items    []Lease `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
 // ContainerManifest corresponds to the Container Manifest format, documented at:
 // https://developers.google.com/compute/docs/containers/container_vms#container_manifest
 // This is used as the representation of Kubernetes workloads.
@@ -701,8 +1423,8 @@ type ContainerManifest struct {
 	// with the API refactoring. It is required for now to determine the instance
 	// of a Pod.
 	UUID          string        `yaml:"uuid,omitempty" json:"uuid,omitempty"`
-	Volumes       []Volume      `yaml:"volumes" json:"volumes"`
-	Containers    []Container   `yaml:"containers" json:"containers"`
+	Volumes       []Volume      `yaml:"volumes" json:"volumes" patchMergeKey:"name"`
+	Containers    []Container   `yaml:"containers" json:"containers" patchMergeKey:"name"`
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty" yaml:"restartPolicy,omitempty"`
 }
 
@@ -717,8 +1439,8 @@ type ContainerManifestList struct {
 
 // PodSpec is a description of a pod
 type PodSpec struct {
-	Volumes       []Volume      `json:"volumes" yaml:"volumes"`
-	Containers    []Container   `json:"containers" yaml:"containers"`
+	Volumes       []Volume      `json:"volumes" yaml:"volumes" patchMergeKey:"name"`
+	Containers    []Container   `json:"containers" yaml:"containers" patchMergeKey:"name"`
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty" yaml:"restartPolicy,omitempty"`
 }
 
@@ -17,6 +17,8 @@ limitations under the License.
 package validation
 
 import (
+	"encoding/base64"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -24,9 +26,44 @@ import (
 	errs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/capabilities"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resources"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 )
 
+// Validate tests obj with the validation of its kind, if it has one. Pods,
+// services, replication controllers, minions and events are validated.
+func Validate(obj runtime.Object) errs.ErrorList {
+	switch t := obj.(type) {
+	case *api.Pod:
+		return ValidatePod(t)
+	case *api.Service:
+		return ValidateService(t)
+	case *api.ReplicationController:
+		return ValidateReplicationController(t)
+	case *api.Minion:
+		return ValidateMinion(t)
+	case *api.Event:
+		return ValidateEvent(t)
+	}
+	return errs.ErrorList{}
+}
+
+// ValidateLabels tests that label keys and values, or those of a selector which
+// matches on labels, are well formed.
+func ValidateLabels(labels map[string]string) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	for k, v := range labels {
+		if !util.IsLabelKey(k) {
+			allErrs = append(allErrs, errs.NewFieldInvalid(k, k))
+		}
+		if !util.IsLabelValue(v) {
+			allErrs = append(allErrs, errs.NewFieldInvalid(k, v))
+		}
+	}
+	return allErrs
+}
+
 func validateVolumes(volumes []api.Volume) (util.StringSet, errs.ErrorList) {
 	allErrs := errs.ErrorList{}
 
@@ -148,11 +185,49 @@ func validateEnv(vars []api.EnvVar) errs.ErrorList {
 		if !util.IsCIdentifier(ev.Name) {
 			vErrs = append(vErrs, errs.NewFieldInvalid("name", ev.Name))
 		}
+		if ev.ValueFrom != nil {
+			if len(ev.Value) != 0 {
+				vErrs = append(vErrs, errs.NewFieldInvalid("value", ev.Value))
+			}
+			vErrs = append(vErrs, validateEnvVarSource(ev.ValueFrom).Prefix("valueFrom")...)
+		}
 		allErrs = append(allErrs, vErrs.PrefixIndex(i)...)
 	}
 	return allErrs
 }
 
+// validateEnvVarSource tests that source names the value of an EnvVar fully,
+// from exactly one config map or secret.
+func validateEnvVarSource(source *api.EnvVarSource) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	switch {
+	case source.ConfigMapKeyRef != nil && source.SecretKeyRef != nil:
+		allErrs = append(allErrs, errs.NewFieldInvalid("secretKeyRef", source.SecretKeyRef))
+	case source.ConfigMapKeyRef != nil:
+		allErrs = append(allErrs, validateKeyRef(source.ConfigMapKeyRef.Name, source.ConfigMapKeyRef.Key).Prefix("configMapKeyRef")...)
+	case source.SecretKeyRef != nil:
+		allErrs = append(allErrs, validateKeyRef(source.SecretKeyRef.Name, source.SecretKeyRef.Key).Prefix("secretKeyRef")...)
+	default:
+		allErrs = append(allErrs, errs.NewFieldRequired("configMapKeyRef", source.ConfigMapKeyRef))
+	}
+	return allErrs
+}
+
+// validateKeyRef tests that a reference to a key of a config map or secret
+// names both.
+func validateKeyRef(name, key string) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(name) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("name", name))
+	} else if !util.IsDNSSubdomain(name) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("name", name))
+	}
+	if len(key) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("key", key))
+	}
+	return allErrs
+}
+
 func validateVolumeMounts(mounts []api.VolumeMount, volumes util.StringSet) errs.ErrorList {
 	allErrs := errs.ErrorList{}
 
@@ -242,6 +317,19 @@ func validateLifecycle(lifecycle *api.Lifecycle) errs.ErrorList {
 	return allErrs
 }
 
+// isCleanAbsolutePath tests that p is an absolute path without ".." elements.
+func isCleanAbsolutePath(p string) bool {
+	if !strings.HasPrefix(p, "/") {
+		return false
+	}
+	for _, elem := range strings.Split(p, "/") {
+		if elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
 func validateContainers(containers []api.Container, volumes util.StringSet) errs.ErrorList {
 	allErrs := errs.ErrorList{}
 
@@ -264,6 +352,15 @@ func validateContainers(containers []api.Container, volumes util.StringSet) errs
 		if len(ctr.Image) == 0 {
 			cErrs = append(cErrs, errs.NewFieldRequired("image", ctr.Image))
 		}
+		if len(ctr.ImageDigest) != 0 {
+			// An image that is already referenced by digest can't be pinned to another one.
+			if !util.IsImageDigest(ctr.ImageDigest) || strings.Contains(ctr.Image, "@") {
+				cErrs = append(cErrs, errs.NewFieldInvalid("imageDigest", ctr.ImageDigest))
+			}
+		}
+		if len(ctr.WorkingDir) != 0 && !isCleanAbsolutePath(ctr.WorkingDir) {
+			cErrs = append(cErrs, errs.NewFieldInvalid("workingDir", ctr.WorkingDir))
+		}
 		if ctr.Lifecycle != nil {
 			cErrs = append(cErrs, validateLifecycle(ctr.Lifecycle).Prefix("lifecycle")...)
 		}
@@ -326,6 +423,9 @@ func validateRestartPolicy(restartPolicy *api.RestartPolicy) errs.ErrorList {
 
 func ValidatePodState(podState *api.PodState) errs.ErrorList {
 	allErrs := errs.ErrorList(ValidateManifest(&podState.Manifest)).Prefix("manifest")
+	if len(podState.ServiceAccount) != 0 && !util.IsDNSSubdomain(podState.ServiceAccount) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("serviceAccount", podState.ServiceAccount))
+	}
 	return allErrs
 }
 
@@ -338,6 +438,7 @@ func ValidatePod(pod *api.Pod) errs.ErrorList {
 	if !util.IsDNSSubdomain(pod.Namespace) {
 		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", pod.Namespace))
 	}
+	allErrs = append(allErrs, ValidateLabels(pod.Labels).Prefix("labels")...)
 	allErrs = append(allErrs, ValidatePodState(&pod.DesiredState).Prefix("desiredState")...)
 	return allErrs
 }
@@ -352,6 +453,7 @@ func ValidatePodUpdate(newPod, oldPod *api.Pod) errs.ErrorList {
 	}
 	pod := *newPod
 	pod.Labels = oldPod.Labels
+	pod.OwnerReferences = oldPod.OwnerReferences
 	pod.TypeMeta.ResourceVersion = oldPod.TypeMeta.ResourceVersion
 	// Tricky, we need to copy the container list so that we don't overwrite the update
 	var newContainers []api.Container
@@ -385,9 +487,29 @@ func ValidateService(service *api.Service) errs.ErrorList {
 	} else if !supportedPortProtocols.Has(strings.ToUpper(string(service.Protocol))) {
 		allErrs = append(allErrs, errs.NewFieldNotSupported("protocol", service.Protocol))
 	}
+	allErrs = append(allErrs, validateContainerPort(service.ContainerPort)...)
 	if labels.Set(service.Selector).AsSelector().Empty() {
 		allErrs = append(allErrs, errs.NewFieldRequired("selector", service.Selector))
 	}
+	allErrs = append(allErrs, ValidateLabels(service.Selector).Prefix("selector")...)
+	allErrs = append(allErrs, ValidateLabels(service.Labels).Prefix("labels")...)
+	return allErrs
+}
+
+// validateContainerPort tests that a service's container port is unset, a valid
+// port number, or a valid port name.
+func validateContainerPort(port util.IntOrString) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	switch port.Kind {
+	case util.IntstrInt:
+		if port.IntVal != 0 && !util.IsValidPortNum(port.IntVal) {
+			allErrs = append(allErrs, errs.NewFieldInvalid("containerPort", port.IntVal))
+		}
+	case util.IntstrString:
+		if len(port.StrVal) != 0 && !util.IsDNSLabel(port.StrVal) {
+			allErrs = append(allErrs, errs.NewFieldInvalid("containerPort", port.StrVal))
+		}
+	}
 	return allErrs
 }
 
@@ -400,6 +522,7 @@ func ValidateReplicationController(controller *api.ReplicationController) errs.E
 	if !util.IsDNSSubdomain(controller.Namespace) {
 		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", controller.Namespace))
 	}
+	allErrs = append(allErrs, ValidateLabels(controller.Labels).Prefix("labels")...)
 	allErrs = append(allErrs, ValidateReplicationControllerState(&controller.DesiredState).Prefix("desiredState")...)
 	return allErrs
 }
@@ -410,6 +533,8 @@ func ValidateReplicationControllerState(state *api.ReplicationControllerState) e
 	if labels.Set(state.ReplicaSelector).AsSelector().Empty() {
 		allErrs = append(allErrs, errs.NewFieldRequired("replicaSelector", state.ReplicaSelector))
 	}
+	allErrs = append(allErrs, ValidateLabels(state.ReplicaSelector).Prefix("replicaSelector")...)
+	allErrs = append(allErrs, ValidateLabels(state.PodTemplate.Labels).Prefix("podTemplate.labels")...)
 	selector := labels.Set(state.ReplicaSelector).AsSelector()
 	labels := labels.Set(state.PodTemplate.Labels)
 	if !selector.Matches(labels) {
@@ -418,10 +543,34 @@ func ValidateReplicationControllerState(state *api.ReplicationControllerState) e
 	if state.Replicas < 0 {
 		allErrs = append(allErrs, errs.NewFieldInvalid("replicas", state.Replicas))
 	}
+	if state.MinReadySeconds < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("minReadySeconds", state.MinReadySeconds))
+	}
+	if state.RollingUpdateStrategy != nil {
+		allErrs = append(allErrs, validateRollingUpdateStrategy(state.RollingUpdateStrategy).Prefix("rollingUpdateStrategy")...)
+	}
 	allErrs = append(allErrs, ValidateManifest(&state.PodTemplate.DesiredState.Manifest).Prefix("podTemplate.desiredState.manifest")...)
 	allErrs = append(allErrs, ValidateReadOnlyPersistentDisks(state.PodTemplate.DesiredState.Manifest.Volumes).Prefix("podTemplate.desiredState.manifest")...)
 	return allErrs
 }
+
+func validateRollingUpdateStrategy(strategy *api.RollingUpdateStrategy) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if strategy.MaxUnavailable < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("maxUnavailable", strategy.MaxUnavailable))
+	}
+	if strategy.MaxSurge < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("maxSurge", strategy.MaxSurge))
+	}
+	if strategy.MaxUnavailable == 0 && strategy.MaxSurge == 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("maxUnavailable", strategy.MaxUnavailable))
+	}
+	if strategy.MinReadySeconds < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("minReadySeconds", strategy.MinReadySeconds))
+	}
+	return allErrs
+}
+
 func ValidateReadOnlyPersistentDisks(volumes []api.Volume) errs.ErrorList {
 	allErrs := errs.ErrorList{}
 	for _, vol := range volumes {
@@ -433,3 +582,660 @@ func ValidateReadOnlyPersistentDisks(volumes []api.Volume) errs.ErrorList {
 	}
 	return allErrs
 }
+
+var supportedNodeAddressTypes = util.NewStringSet(
+	string(api.NodeInternalIP),
+	string(api.NodeExternalIP),
+	string(api.NodeHostname),
+	string(api.NodeInternalDNS),
+	string(api.NodeExternalDNS),
+)
+
+var supportedNodeConditionTypes = util.NewStringSet(
+	string(api.NodeReady),
+	string(api.NodeOutOfDisk),
+	string(api.NodeMemoryPressure),
+)
+
+var supportedConditionStatuses = util.NewStringSet(
+	string(api.ConditionTrue),
+	string(api.ConditionFalse),
+	string(api.ConditionUnknown),
+)
+
+// ValidateMinion tests if required fields in the minion are set.
+func ValidateMinion(minion *api.Minion) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(minion.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", minion.ID))
+	}
+	allErrs = append(allErrs, ValidateLabels(minion.Labels).Prefix("labels")...)
+	allErrs = append(allErrs, validateNodeAddresses(minion.Addresses).Prefix("addresses")...)
+	allErrs = append(allErrs, ValidateNodeStatus(&minion.Status).Prefix("status")...)
+	return allErrs
+}
+
+// validateNodeAddresses tests that each address has a known type, a value, and
+// a type no other address has.
+func validateNodeAddresses(addresses []api.NodeAddress) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	types := util.StringSet{}
+	for i, addr := range addresses {
+		aErrs := errs.ErrorList{}
+		if !supportedNodeAddressTypes.Has(string(addr.Type)) {
+			aErrs = append(aErrs, errs.NewFieldNotSupported("type", addr.Type))
+		} else if types.Has(string(addr.Type)) {
+			aErrs = append(aErrs, errs.NewFieldDuplicate("type", addr.Type))
+		} else {
+			types.Insert(string(addr.Type))
+		}
+		if len(addr.Address) == 0 {
+			aErrs = append(aErrs, errs.NewFieldRequired("address", addr.Address))
+		}
+		allErrs = append(allErrs, aErrs.PrefixIndex(i)...)
+	}
+	return allErrs
+}
+
+// ValidateNodeStatus tests that each condition of a minion status has a known
+// type, a known status, and a type no other condition has.
+func ValidateNodeStatus(status *api.NodeStatus) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	types := util.StringSet{}
+	for i, condition := range status.Conditions {
+		cErrs := errs.ErrorList{}
+		if !supportedNodeConditionTypes.Has(string(condition.Type)) {
+			cErrs = append(cErrs, errs.NewFieldNotSupported("type", condition.Type))
+		} else if types.Has(string(condition.Type)) {
+			cErrs = append(cErrs, errs.NewFieldDuplicate("type", condition.Type))
+		} else {
+			types.Insert(string(condition.Type))
+		}
+		if !supportedConditionStatuses.Has(string(condition.Status)) {
+			cErrs = append(cErrs, errs.NewFieldNotSupported("status", condition.Status))
+		}
+		allErrs = append(allErrs, cErrs.PrefixIndex(i)...)
+	}
+	return allErrs.Prefix("conditions")
+}
+
+// ValidateEvent tests if required fields in the event are set.
+func ValidateEvent(event *api.Event) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(event.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", event.ID))
+	}
+	if !util.IsDNSSubdomain(event.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", event.Namespace))
+	}
+	if len(event.InvolvedObject.Kind) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("involvedObject.kind", event.InvolvedObject.Kind))
+	}
+	if len(event.InvolvedObject.Name) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("involvedObject.name", event.InvolvedObject.Name))
+	}
+	if event.Count < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("count", event.Count))
+	}
+	return allErrs
+}
+
+// ValidateSecret tests if required fields in the secret are set, and that the
+// secret values are valid base64 under keys that are DNS subdomains, and that
+// they take at most maxSize bytes in total once decoded.
+func ValidateSecret(secret *api.Secret, maxSize int) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(secret.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", secret.ID))
+	} else if !util.IsDNSSubdomain(secret.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", secret.ID))
+	}
+	if !util.IsDNSSubdomain(secret.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", secret.Namespace))
+	}
+	size := 0
+	for key, value := range secret.Data {
+		if !util.IsDNSSubdomain(key) {
+			allErrs = append(allErrs, errs.NewFieldInvalid("data", key))
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			allErrs = append(allErrs, errs.NewFieldInvalid("data", key))
+			continue
+		}
+		size += len(decoded)
+	}
+	allErrs = append(allErrs, validateDataSize(size, maxSize)...)
+	return allErrs
+}
+
+// validateDataSize tests that size, the total size of the values of a config
+// map or secret, is at most maxSize bytes.
+func validateDataSize(size, maxSize int) errs.ErrorList {
+	if size <= maxSize {
+		return errs.ErrorList{}
+	}
+	return errs.ErrorList{errs.NewFieldInvalid("data", fmt.Sprintf("%d bytes, more than the limit of %d bytes", size, maxSize))}
+}
+
+// ValidateNamespace tests if required fields in the namespace are set. Namespaces
+// are not in a namespace, so their own namespace must be empty.
+func ValidateNamespace(namespace *api.Namespace) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(namespace.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", namespace.ID))
+	} else if !util.IsDNSSubdomain(namespace.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", namespace.ID))
+	}
+	if len(namespace.Namespace) != 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", namespace.Namespace))
+	}
+	return allErrs
+}
+
+// supportedQuotaResources are the resources a ResourceQuota can limit.
+var supportedQuotaResources = util.NewStringSet(string(resources.CPU), string(resources.Memory), string(resources.Pods))
+
+// ValidateResourceQuota tests if required fields in the resource quota are set,
+// and that it only limits supported resources to non-negative integers.
+func ValidateResourceQuota(quota *api.ResourceQuota) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(quota.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", quota.ID))
+	} else if !util.IsDNSSubdomain(quota.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", quota.ID))
+	}
+	if !util.IsDNSSubdomain(quota.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", quota.Namespace))
+	}
+	for name, value := range quota.Hard {
+		if !supportedQuotaResources.Has(string(name)) {
+			allErrs = append(allErrs, errs.NewFieldNotSupported("hard", name))
+			continue
+		}
+		if value.Kind != util.IntstrInt || value.IntVal < 0 {
+			allErrs = append(allErrs, errs.NewFieldInvalid("hard."+string(name), value))
+		}
+	}
+	return allErrs
+}
+
+// supportedLimitResources are the resources a LimitRange can bound.
+var supportedLimitResources = util.NewStringSet(string(resources.CPU), string(resources.Memory))
+
+// validateLimitResources tests that list only names resources a LimitRange can
+// bound, and gives them non-negative integer amounts.
+func validateLimitResources(list api.ResourceList, field string) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	for name, value := range list {
+		if !supportedLimitResources.Has(string(name)) {
+			allErrs = append(allErrs, errs.NewFieldNotSupported(field, name))
+			continue
+		}
+		if value.Kind != util.IntstrInt || value.IntVal < 0 {
+			allErrs = append(allErrs, errs.NewFieldInvalid(field+"."+string(name), value))
+		}
+	}
+	return allErrs
+}
+
+// ValidateLimitRange tests if required fields in the limit range are set, that
+// every limit bounds a supported kind of object and resource, and that every
+// minimum and default lies within the bounds of its limit.
+func ValidateLimitRange(limitRange *api.LimitRange) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(limitRange.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", limitRange.ID))
+	} else if !util.IsDNSSubdomain(limitRange.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", limitRange.ID))
+	}
+	if !util.IsDNSSubdomain(limitRange.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", limitRange.Namespace))
+	}
+	for i, item := range limitRange.Limits {
+		iErrs := errs.ErrorList{}
+		switch item.Type {
+		case api.LimitTypePod:
+			if len(item.Default) != 0 {
+				iErrs = append(iErrs, errs.NewFieldInvalid("default", item.Default))
+			}
+		case api.LimitTypeContainer:
+		default:
+			iErrs = append(iErrs, errs.NewFieldNotSupported("type", item.Type))
+		}
+		iErrs = append(iErrs, validateLimitResources(item.Max, "max")...)
+		iErrs = append(iErrs, validateLimitResources(item.Min, "min")...)
+		iErrs = append(iErrs, validateLimitResources(item.Default, "default")...)
+		for name, min := range item.Min {
+			if max, ok := item.Max[name]; ok && min.IntVal > max.IntVal {
+				iErrs = append(iErrs, errs.NewFieldInvalid("min."+string(name), min))
+			}
+		}
+		for name, value := range item.Default {
+			min, hasMin := item.Min[name]
+			max, hasMax := item.Max[name]
+			if (hasMin && value.IntVal < min.IntVal) || (hasMax && value.IntVal > max.IntVal) {
+				iErrs = append(iErrs, errs.NewFieldInvalid("default."+string(name), value))
+			}
+		}
+		allErrs = append(allErrs, iErrs.PrefixIndex(i).Prefix("limits")...)
+	}
+	return allErrs
+}
+
+// ValidateConfigMap tests if required fields in the config map are set, that
+// its keys are valid, and that its values take at most maxSize bytes in total.
+func ValidateConfigMap(configMap *api.ConfigMap, maxSize int) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(configMap.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", configMap.ID))
+	} else if !util.IsDNSSubdomain(configMap.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", configMap.ID))
+	}
+	if !util.IsDNSSubdomain(configMap.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", configMap.Namespace))
+	}
+	size := 0
+	for key, value := range configMap.Data {
+		if !util.IsDNSSubdomain(key) {
+			allErrs = append(allErrs, errs.NewFieldInvalid("data", key))
+		}
+		size += len(value)
+	}
+	allErrs = append(allErrs, validateDataSize(size, maxSize)...)
+	return allErrs
+}
+
+// ValidateDaemonSet tests if required fields in the daemon set are set, and
+// that its pod template is valid and leaves the choice of host to the set.
+func ValidateDaemonSet(daemonSet *api.DaemonSet) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(daemonSet.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", daemonSet.ID))
+	} else if !util.IsDNSSubdomain(daemonSet.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", daemonSet.ID))
+	}
+	if !util.IsDNSSubdomain(daemonSet.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", daemonSet.Namespace))
+	}
+	state := &daemonSet.Spec.Template.DesiredState
+	if len(state.Host) != 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.template.desiredState.host", state.Host))
+	}
+	allErrs = append(allErrs, ValidatePodState(state).Prefix("spec.template.desiredState")...)
+	allErrs = append(allErrs, ValidateReadOnlyPersistentDisks(state.Manifest.Volumes).Prefix("spec.template.desiredState.manifest")...)
+	return allErrs
+}
+
+// ValidateJob tests if required fields in the job are set, and that its pod
+// template is valid and does not restart its pods forever. A template without
+// a restart policy is defaulted to never restarting.
+func ValidateJob(job *api.Job) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(job.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", job.ID))
+	} else if !util.IsDNSSubdomain(job.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", job.ID))
+	}
+	if !util.IsDNSSubdomain(job.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", job.Namespace))
+	}
+	if job.Spec.Completions <= 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.completions", job.Spec.Completions))
+	}
+	if job.Spec.Parallelism <= 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.parallelism", job.Spec.Parallelism))
+	}
+	state := &job.Spec.Template.DesiredState
+	policy := &state.Manifest.RestartPolicy
+	if policy.Always == nil && policy.OnFailure == nil && policy.Never == nil {
+		policy.Never = &api.RestartPolicyNever{}
+	}
+	if policy.Always != nil {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.template.desiredState.manifest.restartPolicy", policy))
+	}
+	allErrs = append(allErrs, ValidatePodState(state).Prefix("spec.template.desiredState")...)
+	allErrs = append(allErrs, ValidateReadOnlyPersistentDisks(state.Manifest.Volumes).Prefix("spec.template.desiredState.manifest")...)
+	return allErrs
+}
+
+// ValidateIngress tests if required fields in the ingress are set, and that
+// its rules and backends are well formed. It does not check that the services
+// the backends refer to exist.
+func ValidateIngress(ingress *api.Ingress) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(ingress.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", ingress.ID))
+	} else if !util.IsDNSSubdomain(ingress.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", ingress.ID))
+	}
+	if !util.IsDNSSubdomain(ingress.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", ingress.Namespace))
+	}
+	if ingress.Spec.Backend == nil && len(ingress.Spec.Rules) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("spec.rules", ingress.Spec.Rules))
+	}
+	if ingress.Spec.Backend != nil {
+		allErrs = append(allErrs, validateIngressBackend(ingress.Spec.Backend).Prefix("spec.backend")...)
+	}
+	for i, rule := range ingress.Spec.Rules {
+		ruleErrs := errs.ErrorList{}
+		if len(rule.Host) != 0 && !util.IsDNSSubdomain(rule.Host) {
+			ruleErrs = append(ruleErrs, errs.NewFieldInvalid("host", rule.Host))
+		}
+		if len(rule.Paths) == 0 {
+			ruleErrs = append(ruleErrs, errs.NewFieldRequired("paths", rule.Paths))
+		}
+		for j := range rule.Paths {
+			pathErrs := errs.ErrorList{}
+			if !strings.HasPrefix(rule.Paths[j].Path, "/") {
+				pathErrs = append(pathErrs, errs.NewFieldInvalid("path", rule.Paths[j].Path))
+			}
+			pathErrs = append(pathErrs, validateIngressBackend(&rule.Paths[j].Backend).Prefix("backend")...)
+			ruleErrs = append(ruleErrs, pathErrs.PrefixIndex(j).Prefix("paths")...)
+		}
+		allErrs = append(allErrs, ruleErrs.PrefixIndex(i).Prefix("spec.rules")...)
+	}
+	for i, tls := range ingress.Spec.TLS {
+		tlsErrs := errs.ErrorList{}
+		if len(tls.Hosts) == 0 {
+			tlsErrs = append(tlsErrs, errs.NewFieldRequired("hosts", tls.Hosts))
+		}
+		for _, host := range tls.Hosts {
+			if !util.IsDNSSubdomain(host) {
+				tlsErrs = append(tlsErrs, errs.NewFieldInvalid("hosts", host))
+			}
+		}
+		if len(tls.SecretName) == 0 {
+			tlsErrs = append(tlsErrs, errs.NewFieldRequired("secretName", tls.SecretName))
+		} else if !util.IsDNSSubdomain(tls.SecretName) {
+			tlsErrs = append(tlsErrs, errs.NewFieldInvalid("secretName", tls.SecretName))
+		}
+		allErrs = append(allErrs, tlsErrs.PrefixIndex(i).Prefix("spec.tls")...)
+	}
+	return allErrs
+}
+
+func validateIngressBackend(backend *api.IngressBackend) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(backend.ServiceName) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("serviceName", backend.ServiceName))
+	} else if !util.IsDNS952Label(backend.ServiceName) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("serviceName", backend.ServiceName))
+	}
+	if !util.IsValidPortNum(backend.ServicePort) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("servicePort", backend.ServicePort))
+	}
+	return allErrs
+}
+
+// ValidateNetworkPolicy tests if required fields in the network policy are set,
+// and that its ports are valid and each of its sources sets one selector. It
+// does not parse the selectors.
+func ValidateNetworkPolicy(policy *api.NetworkPolicy) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(policy.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", policy.ID))
+	} else if !util.IsDNSSubdomain(policy.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", policy.ID))
+	}
+	if !util.IsDNSSubdomain(policy.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", policy.Namespace))
+	}
+	for i := range policy.Spec.Ingress {
+		rule := &policy.Spec.Ingress[i]
+		ruleErrs := errs.ErrorList{}
+		for j := range rule.Ports {
+			port := &rule.Ports[j]
+			portErrs := errs.ErrorList{}
+			if !util.IsValidPortNum(port.Port) {
+				portErrs = append(portErrs, errs.NewFieldInvalid("port", port.Port))
+			}
+			if len(port.Protocol) == 0 {
+				port.Protocol = "TCP"
+			} else if !supportedPortProtocols.Has(strings.ToUpper(string(port.Protocol))) {
+				portErrs = append(portErrs, errs.NewFieldNotSupported("protocol", port.Protocol))
+			}
+			ruleErrs = append(ruleErrs, portErrs.PrefixIndex(j).Prefix("ports")...)
+		}
+		for j, peer := range rule.From {
+			if (len(peer.PodSelector) == 0) == (len(peer.NamespaceSelector) == 0) {
+				ruleErrs = append(ruleErrs, errs.ErrorList{errs.NewFieldInvalid("", peer)}.PrefixIndex(j).Prefix("from")...)
+			}
+		}
+		allErrs = append(allErrs, ruleErrs.PrefixIndex(i).Prefix("spec.ingress")...)
+	}
+	return allErrs
+}
+
+// ValidatePodDisruptionBudget tests if required fields in the pod disruption
+// budget are set, and that its selector parses.
+func ValidatePodDisruptionBudget(budget *api.PodDisruptionBudget) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(budget.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", budget.ID))
+	} else if !util.IsDNSSubdomain(budget.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", budget.ID))
+	}
+	if !util.IsDNSSubdomain(budget.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", budget.Namespace))
+	}
+	if len(budget.Spec.Selector) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("spec.selector", budget.Spec.Selector))
+	} else if _, err := labels.ParseSelector(budget.Spec.Selector); err != nil {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.selector", budget.Spec.Selector))
+	}
+	if budget.Spec.MinAvailable < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.minAvailable", budget.Spec.MinAvailable))
+	}
+	return allErrs
+}
+
+// ValidateServiceAccount tests if required fields in the service account are
+// set, and that every secret it references is named.
+func ValidateServiceAccount(account *api.ServiceAccount) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(account.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", account.ID))
+	} else if !util.IsDNSSubdomain(account.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", account.ID))
+	}
+	if !util.IsDNSSubdomain(account.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", account.Namespace))
+	}
+	for i, secret := range account.Secrets {
+		sErrs := errs.ErrorList{}
+		if len(secret.Name) == 0 {
+			sErrs = append(sErrs, errs.NewFieldRequired("name", secret.Name))
+		} else if !util.IsDNSSubdomain(secret.Name) {
+			sErrs = append(sErrs, errs.NewFieldInvalid("name", secret.Name))
+		}
+		allErrs = append(allErrs, sErrs.PrefixIndex(i).Prefix("secrets")...)
+	}
+	return allErrs
+}
+
+// ValidateHorizontalPodAutoscaler tests if required fields in the autoscaler are
+// set, that it refers to a replication controller, and that its replica bounds
+// and target utilization are sensible.
+func ValidateHorizontalPodAutoscaler(autoscaler *api.HorizontalPodAutoscaler) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(autoscaler.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", autoscaler.ID))
+	} else if !util.IsDNSSubdomain(autoscaler.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", autoscaler.ID))
+	}
+	if !util.IsDNSSubdomain(autoscaler.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", autoscaler.Namespace))
+	}
+	spec := &autoscaler.Spec
+	if kind := spec.ScaleTargetRef.Kind; len(kind) != 0 && kind != "ReplicationController" {
+		allErrs = append(allErrs, errs.NewFieldNotSupported("spec.scaleTargetRef.kind", kind))
+	}
+	if len(spec.ScaleTargetRef.Name) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("spec.scaleTargetRef.name", spec.ScaleTargetRef.Name))
+	}
+	if spec.MinReplicas < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.minReplicas", spec.MinReplicas))
+	}
+	if spec.MaxReplicas < 1 || spec.MaxReplicas < spec.MinReplicas {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.maxReplicas", spec.MaxReplicas))
+	}
+	if spec.TargetCPUUtilization < 1 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.targetCPUUtilization", spec.TargetCPUUtilization))
+	}
+	return allErrs
+}
+
+// totalAnnotationSizeLimit bounds the combined size of an object's annotation keys and values.
+const totalAnnotationSizeLimit int = 256 * (1 << 10) // 256 KiB
+
+// ValidateAnnotations tests that annotation keys are valid label keys and that the
+// annotations are not too large in total.
+func ValidateAnnotations(annotations map[string]string) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	size := 0
+	for k, v := range annotations {
+		if !util.IsLabelKey(k) {
+			allErrs = append(allErrs, errs.NewFieldInvalid(k, k))
+		}
+		size += len(k) + len(v)
+	}
+	if size > totalAnnotationSizeLimit {
+		allErrs = append(allErrs, errs.NewFieldInvalid("", size))
+	}
+	return allErrs
+}
+
+// supportedPolicyVerbs are the verbs a PolicyRule may allow.
+var supportedPolicyVerbs = util.NewStringSet("*", "get", "list", "watch", "create", "update", "patch", "delete", "proxy")
+
+func validatePolicyRules(rules []api.PolicyRule) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	for i, rule := range rules {
+		ruleErrs := errs.ErrorList{}
+		if len(rule.Verbs) == 0 {
+			ruleErrs = append(ruleErrs, errs.NewFieldRequired("verbs", rule.Verbs))
+		}
+		for _, verb := range rule.Verbs {
+			if !supportedPolicyVerbs.Has(verb) {
+				ruleErrs = append(ruleErrs, errs.NewFieldNotSupported("verbs", verb))
+			}
+		}
+		if len(rule.Resources) == 0 {
+			ruleErrs = append(ruleErrs, errs.NewFieldRequired("resources", rule.Resources))
+		}
+		allErrs = append(allErrs, ruleErrs.PrefixIndex(i).Prefix("rules")...)
+	}
+	return allErrs
+}
+
+// ValidateRole tests if required fields in the role are set, and that its rules
+// only allow supported verbs.
+func ValidateRole(role *api.Role) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(role.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", role.ID))
+	} else if !util.IsDNSSubdomain(role.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", role.ID))
+	}
+	if !util.IsDNSSubdomain(role.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", role.Namespace))
+	}
+	return append(allErrs, validatePolicyRules(role.Rules)...)
+}
+
+// ValidateClusterRole tests if required fields in the cluster role are set, and
+// that its rules only allow supported verbs.
+func ValidateClusterRole(role *api.ClusterRole) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(role.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", role.ID))
+	} else if !util.IsDNSSubdomain(role.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", role.ID))
+	}
+	if len(role.Namespace) != 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", role.Namespace))
+	}
+	return append(allErrs, validatePolicyRules(role.Rules)...)
+}
+
+func validateSubjects(subjects []api.Subject) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(subjects) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("subjects", subjects))
+	}
+	for i, subject := range subjects {
+		subjectErrs := errs.ErrorList{}
+		if subject.Kind != api.SubjectKindUser {
+			subjectErrs = append(subjectErrs, errs.NewFieldNotSupported("kind", subject.Kind))
+		}
+		if len(subject.Name) == 0 {
+			subjectErrs = append(subjectErrs, errs.NewFieldRequired("name", subject.Name))
+		}
+		allErrs = append(allErrs, subjectErrs.PrefixIndex(i).Prefix("subjects")...)
+	}
+	return allErrs
+}
+
+// ValidateRoleBinding tests if required fields in the role binding are set,
+// and that it refers to a Role or a ClusterRole.
+func ValidateRoleBinding(binding *api.RoleBinding) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(binding.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", binding.ID))
+	} else if !util.IsDNSSubdomain(binding.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", binding.ID))
+	}
+	if !util.IsDNSSubdomain(binding.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", binding.Namespace))
+	}
+	allErrs = append(allErrs, validateSubjects(binding.Subjects)...)
+	if binding.RoleRef.Kind != api.RoleRefKindRole && binding.RoleRef.Kind != api.RoleRefKindClusterRole {
+		allErrs = append(allErrs, errs.NewFieldNotSupported("roleRef.kind", binding.RoleRef.Kind))
+	}
+	if len(binding.RoleRef.Name) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("roleRef.name", binding.RoleRef.Name))
+	}
+	return allErrs
+}
+
+// ValidateClusterRoleBinding tests if required fields in the cluster role
+// binding are set, and that it refers to a ClusterRole.
+func ValidateClusterRoleBinding(binding *api.ClusterRoleBinding) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(binding.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", binding.ID))
+	} else if !util.IsDNSSubdomain(binding.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", binding.ID))
+	}
+	if len(binding.Namespace) != 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", binding.Namespace))
+	}
+	allErrs = append(allErrs, validateSubjects(binding.Subjects)...)
+	if binding.RoleRef.Kind != api.RoleRefKindClusterRole {
+		allErrs = append(allErrs, errs.NewFieldNotSupported("roleRef.kind", binding.RoleRef.Kind))
+	}
+	if len(binding.RoleRef.Name) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("roleRef.name", binding.RoleRef.Name))
+	}
+	return allErrs
+}
+
+// ValidateLease tests if required fields in the lease are set, and that its
+// duration is not negative.
+func ValidateLease(lease *api.Lease) errs.ErrorList {
+	allErrs := errs.ErrorList{}
+	if len(lease.ID) == 0 {
+		allErrs = append(allErrs, errs.NewFieldRequired("id", lease.ID))
+	} else if !util.IsDNSSubdomain(lease.ID) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("id", lease.ID))
+	}
+	if !util.IsDNSSubdomain(lease.Namespace) {
+		allErrs = append(allErrs, errs.NewFieldInvalid("namespace", lease.Namespace))
+	}
+	if lease.Spec.LeaseDurationSeconds < 0 {
+		allErrs = append(allErrs, errs.NewFieldInvalid("spec.leaseDurationSeconds", lease.Spec.LeaseDurationSeconds))
+	}
+	return allErrs
+}
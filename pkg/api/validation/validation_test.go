@@ -17,12 +17,15 @@ limitations under the License.
 package validation
 
 import (
+	"encoding/base64"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/capabilities"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 )
 
@@ -139,6 +142,8 @@ func TestValidateEnv(t *testing.T) {
 		{Name: "ABC", Value: "value"},
 		{Name: "AbC_123", Value: "value"},
 		{Name: "abc", Value: ""},
+		{Name: "abc", ValueFrom: &api.EnvVarSource{ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config", Key: "abc"}}},
+		{Name: "abc", ValueFrom: &api.EnvVarSource{SecretKeyRef: &api.SecretKeySelector{Name: "secret", Key: "abc"}}},
 	}
 	if errs := validateEnv(successCase); len(errs) != 0 {
 		t.Errorf("expected success: %v", errs)
@@ -147,6 +152,18 @@ func TestValidateEnv(t *testing.T) {
 	errorCases := map[string][]api.EnvVar{
 		"zero-length name":        {{Name: ""}},
 		"name not a C identifier": {{Name: "a.b.c"}},
+		"value and valueFrom": {{
+			Name:      "abc",
+			Value:     "value",
+			ValueFrom: &api.EnvVarSource{ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config", Key: "abc"}},
+		}},
+		"empty valueFrom":        {{Name: "abc", ValueFrom: &api.EnvVarSource{}}},
+		"config map key missing": {{Name: "abc", ValueFrom: &api.EnvVarSource{ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config"}}}},
+		"secret name missing":    {{Name: "abc", ValueFrom: &api.EnvVarSource{SecretKeyRef: &api.SecretKeySelector{Key: "abc"}}}},
+		"config map and secret": {{Name: "abc", ValueFrom: &api.EnvVarSource{
+			ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config", Key: "abc"},
+			SecretKeyRef:    &api.SecretKeySelector{Name: "secret", Key: "abc"},
+		}}},
 	}
 	for k, v := range errorCases {
 		if errs := validateEnv(v); len(errs) == 0 {
@@ -199,6 +216,8 @@ func TestValidateContainers(t *testing.T) {
 			},
 		},
 		{Name: "abc-1234", Image: "image", Privileged: true},
+		{Name: "digest", Image: "image:v1", ImageDigest: "sha256:" + strings.Repeat("0a", 32)},
+		{Name: "workdir", Image: "image", WorkingDir: "/var/lib/app..data"},
 	}
 	if errs := validateContainers(successCase, volumes); len(errs) != 0 {
 		t.Errorf("expected success: %v", errs)
@@ -216,6 +235,18 @@ func TestValidateContainers(t *testing.T) {
 			{Name: "abc", Image: "image"},
 		},
 		"zero-length image": {{Name: "abc", Image: ""}},
+		"invalid image digest": {
+			{Name: "abc", Image: "image", ImageDigest: "sha256:" + strings.Repeat("0A", 32)},
+		},
+		"image already pinned": {
+			{Name: "abc", Image: "image@sha256:" + strings.Repeat("0a", 32), ImageDigest: "sha256:" + strings.Repeat("0a", 32)},
+		},
+		"relative working dir": {
+			{Name: "abc", Image: "image", WorkingDir: "var/lib"},
+		},
+		"working dir with ..": {
+			{Name: "abc", Image: "image", WorkingDir: "/var/../etc"},
+		},
 		"host port not unique": {
 			{Name: "abc", Image: "image", Ports: []api.Port{{ContainerPort: 80, HostPort: 80}}},
 			{Name: "def", Image: "image", Ports: []api.Port{{ContainerPort: 81, HostPort: 80}}},
@@ -655,6 +686,28 @@ func TestValidateService(t *testing.T) {
 			// Should fail because the protocol is invalid.
 			numErrs: 1,
 		},
+		{
+			name: "invalid container port number",
+			svc: api.Service{
+				TypeMeta:      api.TypeMeta{ID: "abc123", Namespace: api.NamespaceDefault},
+				Port:          8675,
+				Selector:      map[string]string{"foo": "bar"},
+				ContainerPort: util.NewIntOrStringFromInt(65536),
+			},
+			// Should fail because the container port is out of range.
+			numErrs: 1,
+		},
+		{
+			name: "invalid container port name",
+			svc: api.Service{
+				TypeMeta:      api.TypeMeta{ID: "abc123", Namespace: api.NamespaceDefault},
+				Port:          8675,
+				Selector:      map[string]string{"foo": "bar"},
+				ContainerPort: util.NewIntOrStringFromString("Not_A_Port"),
+			},
+			// Should fail because the container port name is not a DNS label.
+			numErrs: 1,
+		},
 		{
 			name: "missing selector",
 			svc: api.Service{
@@ -684,6 +737,26 @@ func TestValidateService(t *testing.T) {
 			},
 			numErrs: 0,
 		},
+		{
+			name: "valid container port",
+			svc: api.Service{
+				TypeMeta:      api.TypeMeta{ID: "abc123", Namespace: api.NamespaceDefault},
+				Port:          80,
+				Selector:      map[string]string{"foo": "bar"},
+				ContainerPort: util.NewIntOrStringFromInt(8080),
+			},
+			numErrs: 0,
+		},
+		{
+			name: "valid named container port",
+			svc: api.Service{
+				TypeMeta:      api.TypeMeta{ID: "abc123", Namespace: api.NamespaceDefault},
+				Port:          80,
+				Selector:      map[string]string{"foo": "bar"},
+				ContainerPort: util.NewIntOrStringFromString("http"),
+			},
+			numErrs: 0,
+		},
 		{
 			name: "valid 3",
 			svc: api.Service{
@@ -749,6 +822,14 @@ func TestValidateReplicationController(t *testing.T) {
 				PodTemplate:     validPodTemplate,
 			},
 		},
+		{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			DesiredState: api.ReplicationControllerState{
+				ReplicaSelector:       validSelector,
+				PodTemplate:           validPodTemplate,
+				RollingUpdateStrategy: &api.RollingUpdateStrategy{MaxSurge: 1},
+			},
+		},
 	}
 	for _, successCase := range successCases {
 		if errs := ValidateReplicationController(&successCase); len(errs) != 0 {
@@ -804,6 +885,30 @@ func TestValidateReplicationController(t *testing.T) {
 				ReplicaSelector: validSelector,
 			},
 		},
+		"negative_minReadySeconds": {
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			DesiredState: api.ReplicationControllerState{
+				ReplicaSelector: validSelector,
+				PodTemplate:     validPodTemplate,
+				MinReadySeconds: -1,
+			},
+		},
+		"negative_maxUnavailable": {
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			DesiredState: api.ReplicationControllerState{
+				ReplicaSelector:       validSelector,
+				PodTemplate:           validPodTemplate,
+				RollingUpdateStrategy: &api.RollingUpdateStrategy{MaxUnavailable: -1, MaxSurge: 1},
+			},
+		},
+		"no_progress": {
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			DesiredState: api.ReplicationControllerState{
+				ReplicaSelector:       validSelector,
+				PodTemplate:           validPodTemplate,
+				RollingUpdateStrategy: &api.RollingUpdateStrategy{},
+			},
+		},
 	}
 	for k, v := range errorCases {
 		errs := ValidateReplicationController(&v)
@@ -817,9 +922,1073 @@ func TestValidateReplicationController(t *testing.T) {
 				field != "namespace" &&
 				field != "desiredState.replicaSelector" &&
 				field != "GCEPersistentDisk.ReadOnly" &&
-				field != "desiredState.replicas" {
+				field != "desiredState.replicas" &&
+				field != "desiredState.minReadySeconds" &&
+				!strings.HasPrefix(field, "desiredState.rollingUpdateStrategy.") {
 				t.Errorf("%s: missing prefix for: %v", k, errs[i])
 			}
 		}
 	}
 }
+
+func TestValidateAnnotations(t *testing.T) {
+	successCases := []map[string]string{
+		nil,
+		{"a": "b"},
+		{"example.com/a-b_c.d": ""},
+		{"a": strings.Repeat("b", 1024)},
+	}
+	for i := range successCases {
+		if errs := ValidateAnnotations(successCases[i]); len(errs) != 0 {
+			t.Errorf("case[%d] expected success, got %v", i, errs)
+		}
+	}
+
+	errorCases := map[string]map[string]string{
+		"empty key":        {"": "a"},
+		"invalid key":      {"a b": "c"},
+		"invalid prefix":   {"Example.com/a": "b"},
+		"too many slashes": {"a/b/c": "d"},
+		"too large":        {"a": strings.Repeat("b", 256*1024)},
+	}
+	for k, v := range errorCases {
+		if errs := ValidateAnnotations(v); len(errs) == 0 {
+			t.Errorf("%s: expected failure", k)
+		}
+	}
+}
+
+func TestValidateSecret(t *testing.T) {
+	validSecret := func() api.Secret {
+		return api.Secret{
+			TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+			Data:     map[string]string{"data-1": base64.StdEncoding.EncodeToString([]byte("bar"))},
+		}
+	}
+	successCases := []api.Secret{
+		validSecret(),
+		{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateSecret(&successCase, api.MaxSecretSize); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	missingID, invalidID, invalidNamespace, invalidKey, invalidValue, tooLarge := validSecret(), validSecret(), validSecret(), validSecret(), validSecret(), validSecret()
+	missingID.ID = ""
+	invalidID.ID = "a..b"
+	invalidNamespace.Namespace = "a b"
+	invalidKey.Data["a b"] = "d2hvb3Bz"
+	invalidValue.Data["data-2"] = "not base64"
+	tooLarge.Data["large"] = base64.StdEncoding.EncodeToString(make([]byte, api.MaxSecretSize))
+
+	errorCases := map[string]struct {
+		secret api.Secret
+		field  string
+	}{
+		"missing id":        {missingID, "id"},
+		"invalid id":        {invalidID, "id"},
+		"invalid namespace": {invalidNamespace, "namespace"},
+		"invalid data key":  {invalidKey, "data"},
+		"invalid data":      {invalidValue, "data"},
+		"too large":         {tooLarge, "data"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateSecret(&v.secret, api.MaxSecretSize)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateNamespace(t *testing.T) {
+	successCases := []api.Namespace{
+		{TypeMeta: api.TypeMeta{ID: "abc"}},
+		{TypeMeta: api.TypeMeta{ID: "abc.example.com"}, Labels: map[string]string{"team": "a"}},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateNamespace(&successCase); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		namespace api.Namespace
+		field     string
+	}{
+		"missing id":    {api.Namespace{}, "id"},
+		"invalid id":    {api.Namespace{TypeMeta: api.TypeMeta{ID: "a b"}}, "id"},
+		"has namespace": {api.Namespace{TypeMeta: api.TypeMeta{ID: "abc", Namespace: "default"}}, "namespace"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateNamespace(&v.namespace)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateResourceQuota(t *testing.T) {
+	successCases := []api.ResourceQuota{
+		{TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault}},
+		{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Hard: api.ResourceList{
+				"cpu":    util.NewIntOrStringFromInt(1000),
+				"memory": util.NewIntOrStringFromInt(1 << 30),
+				"pods":   util.NewIntOrStringFromInt(0),
+			},
+		},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateResourceQuota(&successCase); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		quota api.ResourceQuota
+		field string
+	}{
+		"missing id":        {api.ResourceQuota{TypeMeta: api.TypeMeta{Namespace: api.NamespaceDefault}}, "id"},
+		"invalid namespace": {api.ResourceQuota{TypeMeta: api.TypeMeta{ID: "abc", Namespace: "a b"}}, "namespace"},
+		"unsupported resource": {api.ResourceQuota{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Hard:     api.ResourceList{"disk": util.NewIntOrStringFromInt(1)},
+		}, "hard"},
+		"negative limit": {api.ResourceQuota{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Hard:     api.ResourceList{"pods": util.NewIntOrStringFromInt(-1)},
+		}, "hard.pods"},
+		"string limit": {api.ResourceQuota{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Hard:     api.ResourceList{"memory": util.NewIntOrStringFromString("1Gi")},
+		}, "hard.memory"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateResourceQuota(&v.quota)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateLimitRange(t *testing.T) {
+	successCases := []api.LimitRange{
+		{TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault}},
+		{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Limits: []api.LimitRangeItem{
+				{
+					Type:    api.LimitTypeContainer,
+					Max:     api.ResourceList{"cpu": util.NewIntOrStringFromInt(1000), "memory": util.NewIntOrStringFromInt(1 << 30)},
+					Min:     api.ResourceList{"cpu": util.NewIntOrStringFromInt(100)},
+					Default: api.ResourceList{"cpu": util.NewIntOrStringFromInt(500), "memory": util.NewIntOrStringFromInt(1 << 28)},
+				},
+				{
+					Type: api.LimitTypePod,
+					Max:  api.ResourceList{"cpu": util.NewIntOrStringFromInt(2000)},
+				},
+			},
+		},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateLimitRange(&successCase); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	limitRange := func(item api.LimitRangeItem) api.LimitRange {
+		return api.LimitRange{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Limits:   []api.LimitRangeItem{item},
+		}
+	}
+	errorCases := map[string]struct {
+		limitRange api.LimitRange
+		field      string
+	}{
+		"missing id":        {api.LimitRange{TypeMeta: api.TypeMeta{Namespace: api.NamespaceDefault}}, "id"},
+		"invalid namespace": {api.LimitRange{TypeMeta: api.TypeMeta{ID: "abc", Namespace: "a b"}}, "namespace"},
+		"unsupported type":  {limitRange(api.LimitRangeItem{Type: "Service"}), "limits[0].type"},
+		"unsupported resource": {limitRange(api.LimitRangeItem{
+			Type: api.LimitTypeContainer,
+			Max:  api.ResourceList{"pods": util.NewIntOrStringFromInt(1)},
+		}), "limits[0].max"},
+		"negative max": {limitRange(api.LimitRangeItem{
+			Type: api.LimitTypeContainer,
+			Max:  api.ResourceList{"cpu": util.NewIntOrStringFromInt(-1)},
+		}), "limits[0].max.cpu"},
+		"min above max": {limitRange(api.LimitRangeItem{
+			Type: api.LimitTypeContainer,
+			Max:  api.ResourceList{"cpu": util.NewIntOrStringFromInt(100)},
+			Min:  api.ResourceList{"cpu": util.NewIntOrStringFromInt(200)},
+		}), "limits[0].min.cpu"},
+		"default out of bounds": {limitRange(api.LimitRangeItem{
+			Type:    api.LimitTypeContainer,
+			Max:     api.ResourceList{"memory": util.NewIntOrStringFromInt(100)},
+			Default: api.ResourceList{"memory": util.NewIntOrStringFromInt(200)},
+		}), "limits[0].default.memory"},
+		"pod default": {limitRange(api.LimitRangeItem{
+			Type:    api.LimitTypePod,
+			Default: api.ResourceList{"cpu": util.NewIntOrStringFromInt(100)},
+		}), "limits[0].default"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateLimitRange(&v.limitRange)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateConfigMap(t *testing.T) {
+	successCases := []api.ConfigMap{
+		{TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault}},
+		{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Data:     map[string]string{"game.properties": "lives=3", "ui": "color=blue"},
+		},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateConfigMap(&successCase, api.MaxConfigMapSize); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		configMap api.ConfigMap
+		field     string
+	}{
+		"missing id":        {api.ConfigMap{TypeMeta: api.TypeMeta{Namespace: api.NamespaceDefault}}, "id"},
+		"invalid namespace": {api.ConfigMap{TypeMeta: api.TypeMeta{ID: "abc", Namespace: "a b"}}, "namespace"},
+		"invalid key": {api.ConfigMap{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Data:     map[string]string{"a b": "c"},
+		}, "data"},
+		"too large": {api.ConfigMap{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Data:     map[string]string{"a": strings.Repeat("x", api.MaxConfigMapSize), "b": "x"},
+		}, "data"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateConfigMap(&v.configMap, api.MaxConfigMapSize)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateDaemonSet(t *testing.T) {
+	validDaemonSet := func() api.DaemonSet {
+		return api.DaemonSet{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec: api.DaemonSetSpec{
+				NodeSelector: map[string]string{"disk": "ssd"},
+				Template: api.PodTemplate{
+					DesiredState: api.PodState{
+						Manifest: api.ContainerManifest{
+							Version:       "v1beta1",
+							RestartPolicy: api.RestartPolicy{Always: &api.RestartPolicyAlways{}},
+						},
+					},
+					Labels: map[string]string{"name": "logger"},
+				},
+			},
+		}
+	}
+	if daemonSet := validDaemonSet(); len(ValidateDaemonSet(&daemonSet)) != 0 {
+		t.Errorf("expected success: %v", ValidateDaemonSet(&daemonSet))
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.DaemonSet)
+		field  string
+	}{
+		"missing id":        {func(d *api.DaemonSet) { d.ID = "" }, "id"},
+		"invalid namespace": {func(d *api.DaemonSet) { d.Namespace = "a b" }, "namespace"},
+		"host set":          {func(d *api.DaemonSet) { d.Spec.Template.DesiredState.Host = "machine" }, "spec.template.desiredState.host"},
+		"invalid manifest":  {func(d *api.DaemonSet) { d.Spec.Template.DesiredState.Manifest.Version = "" }, "spec.template.desiredState.manifest.version"},
+	}
+	for k, v := range errorCases {
+		daemonSet := validDaemonSet()
+		v.mutate(&daemonSet)
+		errs := ValidateDaemonSet(&daemonSet)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateJob(t *testing.T) {
+	validJob := func() api.Job {
+		return api.Job{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec: api.JobSpec{
+				Completions: 5,
+				Parallelism: 2,
+				Template: api.PodTemplate{
+					DesiredState: api.PodState{
+						Manifest: api.ContainerManifest{Version: "v1beta1"},
+					},
+				},
+			},
+		}
+	}
+	job := validJob()
+	if errs := ValidateJob(&job); len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+	if job.Spec.Template.DesiredState.Manifest.RestartPolicy.Never == nil {
+		t.Errorf("expected the restart policy to default to never, got %#v", job.Spec.Template.DesiredState.Manifest.RestartPolicy)
+	}
+	job = validJob()
+	job.Spec.Template.DesiredState.Manifest.RestartPolicy.OnFailure = &api.RestartPolicyOnFailure{}
+	if errs := ValidateJob(&job); len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.Job)
+		field  string
+	}{
+		"missing id":           {func(j *api.Job) { j.ID = "" }, "id"},
+		"invalid namespace":    {func(j *api.Job) { j.Namespace = "a b" }, "namespace"},
+		"no completions":       {func(j *api.Job) { j.Spec.Completions = 0 }, "spec.completions"},
+		"negative parallelism": {func(j *api.Job) { j.Spec.Parallelism = -1 }, "spec.parallelism"},
+		"restart always": {
+			func(j *api.Job) {
+				j.Spec.Template.DesiredState.Manifest.RestartPolicy.Always = &api.RestartPolicyAlways{}
+			},
+			"spec.template.desiredState.manifest.restartPolicy",
+		},
+		"invalid manifest": {func(j *api.Job) { j.Spec.Template.DesiredState.Manifest.Version = "" }, "spec.template.desiredState.manifest.version"},
+	}
+	for k, v := range errorCases {
+		job := validJob()
+		v.mutate(&job)
+		errs := ValidateJob(&job)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateIngress(t *testing.T) {
+	validIngress := func() api.Ingress {
+		return api.Ingress{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec: api.IngressSpec{
+				Backend: &api.IngressBackend{ServiceName: "default-http", ServicePort: 80},
+				Rules: []api.IngressRule{{
+					Host: "foo.example.com",
+					Paths: []api.IngressPath{
+						{Path: "/", Backend: api.IngressBackend{ServiceName: "frontend", ServicePort: 80}},
+						{Path: "/api", Backend: api.IngressBackend{ServiceName: "api", ServicePort: 8080}},
+					},
+				}},
+				TLS: []api.IngressTLS{{Hosts: []string{"foo.example.com"}, SecretName: "foo-tls"}},
+			},
+		}
+	}
+	successCases := map[string]func(*api.Ingress){
+		"valid":         func(*api.Ingress) {},
+		"only backend":  func(i *api.Ingress) { i.Spec.Rules = nil },
+		"only rules":    func(i *api.Ingress) { i.Spec.Backend = nil },
+		"any host rule": func(i *api.Ingress) { i.Spec.Rules[0].Host = "" },
+	}
+	for k, mutate := range successCases {
+		ingress := validIngress()
+		mutate(&ingress)
+		if errs := ValidateIngress(&ingress); len(errs) != 0 {
+			t.Errorf("%s: expected success: %v", k, errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.Ingress)
+		field  string
+	}{
+		"missing id":        {func(i *api.Ingress) { i.ID = "" }, "id"},
+		"invalid namespace": {func(i *api.Ingress) { i.Namespace = "a b" }, "namespace"},
+		"no routes": {
+			func(i *api.Ingress) { i.Spec.Backend, i.Spec.Rules = nil, nil },
+			"spec.rules",
+		},
+		"backend without service": {
+			func(i *api.Ingress) { i.Spec.Backend.ServiceName = "" },
+			"spec.backend.serviceName",
+		},
+		"backend with invalid port": {
+			func(i *api.Ingress) { i.Spec.Backend.ServicePort = 0 },
+			"spec.backend.servicePort",
+		},
+		"invalid host": {
+			func(i *api.Ingress) { i.Spec.Rules[0].Host = "foo_bar" },
+			"spec.rules[0].host",
+		},
+		"no paths": {
+			func(i *api.Ingress) { i.Spec.Rules[0].Paths = nil },
+			"spec.rules[0].paths",
+		},
+		"relative path": {
+			func(i *api.Ingress) { i.Spec.Rules[0].Paths[1].Path = "api" },
+			"spec.rules[0].paths[1].path",
+		},
+		"invalid path service": {
+			func(i *api.Ingress) { i.Spec.Rules[0].Paths[1].Backend.ServiceName = "Api" },
+			"spec.rules[0].paths[1].backend.serviceName",
+		},
+		"tls without hosts": {
+			func(i *api.Ingress) { i.Spec.TLS[0].Hosts = nil },
+			"spec.tls[0].hosts",
+		},
+		"tls with invalid host": {
+			func(i *api.Ingress) { i.Spec.TLS[0].Hosts[0] = "foo_bar" },
+			"spec.tls[0].hosts",
+		},
+		"tls without secret": {
+			func(i *api.Ingress) { i.Spec.TLS[0].SecretName = "" },
+			"spec.tls[0].secretName",
+		},
+	}
+	for k, v := range errorCases {
+		ingress := validIngress()
+		v.mutate(&ingress)
+		errs := ValidateIngress(&ingress)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	validPolicy := func() api.NetworkPolicy {
+		return api.NetworkPolicy{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec: api.NetworkPolicySpec{
+				PodSelector: "name=db",
+				Ingress: []api.NetworkPolicyIngressRule{{
+					Ports: []api.NetworkPolicyPort{{Protocol: "TCP", Port: 5432}},
+					From: []api.NetworkPolicyPeer{
+						{PodSelector: "name=frontend"},
+						{NamespaceSelector: "team=ops"},
+					},
+				}},
+			},
+		}
+	}
+	successCases := map[string]func(*api.NetworkPolicy){
+		"valid":              func(*api.NetworkPolicy) {},
+		"all pods":           func(p *api.NetworkPolicy) { p.Spec.PodSelector = "" },
+		"deny all":           func(p *api.NetworkPolicy) { p.Spec.Ingress = nil },
+		"any port":           func(p *api.NetworkPolicy) { p.Spec.Ingress[0].Ports = nil },
+		"any source":         func(p *api.NetworkPolicy) { p.Spec.Ingress[0].From = nil },
+		"default protocol":   func(p *api.NetworkPolicy) { p.Spec.Ingress[0].Ports[0].Protocol = "" },
+		"lowercase protocol": func(p *api.NetworkPolicy) { p.Spec.Ingress[0].Ports[0].Protocol = "udp" },
+	}
+	for k, mutate := range successCases {
+		policy := validPolicy()
+		mutate(&policy)
+		if errs := ValidateNetworkPolicy(&policy); len(errs) != 0 {
+			t.Errorf("%s: expected success: %v", k, errs)
+		}
+	}
+
+	policy := validPolicy()
+	policy.Spec.Ingress[0].Ports[0].Protocol = ""
+	ValidateNetworkPolicy(&policy)
+	if policy.Spec.Ingress[0].Ports[0].Protocol != "TCP" {
+		t.Errorf("expected default protocol of 'TCP', got %q", policy.Spec.Ingress[0].Ports[0].Protocol)
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.NetworkPolicy)
+		field  string
+	}{
+		"missing id":        {func(p *api.NetworkPolicy) { p.ID = "" }, "id"},
+		"invalid namespace": {func(p *api.NetworkPolicy) { p.Namespace = "a b" }, "namespace"},
+		"invalid port": {
+			func(p *api.NetworkPolicy) { p.Spec.Ingress[0].Ports[0].Port = 65536 },
+			"spec.ingress[0].ports[0].port",
+		},
+		"invalid protocol": {
+			func(p *api.NetworkPolicy) { p.Spec.Ingress[0].Ports[0].Protocol = "ICMP" },
+			"spec.ingress[0].ports[0].protocol",
+		},
+		"source without selector": {
+			func(p *api.NetworkPolicy) { p.Spec.Ingress[0].From[1].NamespaceSelector = "" },
+			"spec.ingress[0].from[1]",
+		},
+		"source with both selectors": {
+			func(p *api.NetworkPolicy) { p.Spec.Ingress[0].From[0].NamespaceSelector = "team=web" },
+			"spec.ingress[0].from[0]",
+		},
+	}
+	for k, v := range errorCases {
+		policy := validPolicy()
+		v.mutate(&policy)
+		errs := ValidateNetworkPolicy(&policy)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidatePodDisruptionBudget(t *testing.T) {
+	validBudget := func() api.PodDisruptionBudget {
+		return api.PodDisruptionBudget{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec:     api.PodDisruptionBudgetSpec{Selector: "name=db", MinAvailable: 2},
+		}
+	}
+	successCases := map[string]func(*api.PodDisruptionBudget){
+		"valid":         func(*api.PodDisruptionBudget) {},
+		"no minimum":    func(b *api.PodDisruptionBudget) { b.Spec.MinAvailable = 0 },
+		"many criteria": func(b *api.PodDisruptionBudget) { b.Spec.Selector = "name=db,tier!=test" },
+	}
+	for k, mutate := range successCases {
+		budget := validBudget()
+		mutate(&budget)
+		if errs := ValidatePodDisruptionBudget(&budget); len(errs) != 0 {
+			t.Errorf("%s: expected success: %v", k, errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.PodDisruptionBudget)
+		field  string
+	}{
+		"missing id":        {func(b *api.PodDisruptionBudget) { b.ID = "" }, "id"},
+		"invalid namespace": {func(b *api.PodDisruptionBudget) { b.Namespace = "a b" }, "namespace"},
+		"missing selector":  {func(b *api.PodDisruptionBudget) { b.Spec.Selector = "" }, "spec.selector"},
+		"invalid selector":  {func(b *api.PodDisruptionBudget) { b.Spec.Selector = "name" }, "spec.selector"},
+		"negative minimum":  {func(b *api.PodDisruptionBudget) { b.Spec.MinAvailable = -1 }, "spec.minAvailable"},
+	}
+	for k, v := range errorCases {
+		budget := validBudget()
+		v.mutate(&budget)
+		errs := ValidatePodDisruptionBudget(&budget)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateServiceAccount(t *testing.T) {
+	successCases := []api.ServiceAccount{
+		{TypeMeta: api.TypeMeta{ID: "default", Namespace: api.NamespaceDefault}},
+		{
+			TypeMeta: api.TypeMeta{ID: "build-robot", Namespace: api.NamespaceDefault},
+			Secrets:  []api.ObjectReference{{Name: "build-robot-token"}},
+		},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateServiceAccount(&successCase); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		account api.ServiceAccount
+		field   string
+	}{
+		"missing id":        {api.ServiceAccount{TypeMeta: api.TypeMeta{Namespace: api.NamespaceDefault}}, "id"},
+		"invalid id":        {api.ServiceAccount{TypeMeta: api.TypeMeta{ID: "a b", Namespace: api.NamespaceDefault}}, "id"},
+		"invalid namespace": {api.ServiceAccount{TypeMeta: api.TypeMeta{ID: "abc", Namespace: "a b"}}, "namespace"},
+		"unnamed secret": {api.ServiceAccount{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Secrets:  []api.ObjectReference{{Name: "token"}, {}},
+		}, "secrets[1].name"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateServiceAccount(&v.account)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateHorizontalPodAutoscaler(t *testing.T) {
+	validAutoscaler := func() api.HorizontalPodAutoscaler {
+		return api.HorizontalPodAutoscaler{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec: api.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef:       api.ObjectReference{Kind: "ReplicationController", Name: "frontend"},
+				MinReplicas:          1,
+				MaxReplicas:          5,
+				TargetCPUUtilization: 80,
+			},
+		}
+	}
+	successCases := []api.HorizontalPodAutoscaler{
+		validAutoscaler(),
+		{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			Spec: api.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef:       api.ObjectReference{Name: "frontend"},
+				MaxReplicas:          1,
+				TargetCPUUtilization: 50,
+			},
+		},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateHorizontalPodAutoscaler(&successCase); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.HorizontalPodAutoscaler)
+		field  string
+	}{
+		"missing id":        {func(a *api.HorizontalPodAutoscaler) { a.ID = "" }, "id"},
+		"invalid namespace": {func(a *api.HorizontalPodAutoscaler) { a.Namespace = "a b" }, "namespace"},
+		"wrong kind":        {func(a *api.HorizontalPodAutoscaler) { a.Spec.ScaleTargetRef.Kind = "Pod" }, "spec.scaleTargetRef.kind"},
+		"missing target":    {func(a *api.HorizontalPodAutoscaler) { a.Spec.ScaleTargetRef.Name = "" }, "spec.scaleTargetRef.name"},
+		"negative min":      {func(a *api.HorizontalPodAutoscaler) { a.Spec.MinReplicas = -1 }, "spec.minReplicas"},
+		"max below min":     {func(a *api.HorizontalPodAutoscaler) { a.Spec.MaxReplicas = 0 }, "spec.maxReplicas"},
+		"zero target":       {func(a *api.HorizontalPodAutoscaler) { a.Spec.TargetCPUUtilization = 0 }, "spec.targetCPUUtilization"},
+	}
+	for k, v := range errorCases {
+		autoscaler := validAutoscaler()
+		v.mutate(&autoscaler)
+		errs := ValidateHorizontalPodAutoscaler(&autoscaler)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
+
+func TestValidateMinion(t *testing.T) {
+	successCases := []api.Minion{
+		{TypeMeta: api.TypeMeta{ID: "abc"}},
+		{
+			TypeMeta: api.TypeMeta{ID: "abc"},
+			Addresses: []api.NodeAddress{
+				{Type: api.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: api.NodeExternalIP, Address: "1.2.3.4"},
+				{Type: api.NodeHostname, Address: "abc"},
+			},
+		},
+		{
+			TypeMeta: api.TypeMeta{ID: "abc"},
+			Status: api.NodeStatus{
+				Conditions: []api.NodeCondition{
+					{Type: api.NodeReady, Status: api.ConditionTrue},
+					{Type: api.NodeOutOfDisk, Status: api.ConditionFalse},
+					{Type: api.NodeMemoryPressure, Status: api.ConditionUnknown},
+				},
+			},
+		},
+	}
+	for _, successCase := range successCases {
+		if errs := ValidateMinion(&successCase); len(errs) != 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := map[string]api.Minion{
+		"missing id": {},
+		"unknown address type": {
+			TypeMeta:  api.TypeMeta{ID: "abc"},
+			Addresses: []api.NodeAddress{{Type: "PublicIP", Address: "1.2.3.4"}},
+		},
+		"duplicate address type": {
+			TypeMeta: api.TypeMeta{ID: "abc"},
+			Addresses: []api.NodeAddress{
+				{Type: api.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: api.NodeInternalIP, Address: "10.0.0.2"},
+			},
+		},
+		"missing address": {
+			TypeMeta:  api.TypeMeta{ID: "abc"},
+			Addresses: []api.NodeAddress{{Type: api.NodeInternalIP}},
+		},
+		"unknown condition type": {
+			TypeMeta: api.TypeMeta{ID: "abc"},
+			Status:   api.NodeStatus{Conditions: []api.NodeCondition{{Type: "Reachable", Status: api.ConditionTrue}}},
+		},
+		"duplicate condition type": {
+			TypeMeta: api.TypeMeta{ID: "abc"},
+			Status: api.NodeStatus{
+				Conditions: []api.NodeCondition{
+					{Type: api.NodeReady, Status: api.ConditionTrue},
+					{Type: api.NodeReady, Status: api.ConditionFalse},
+				},
+			},
+		},
+		"unknown condition status": {
+			TypeMeta: api.TypeMeta{ID: "abc"},
+			Status:   api.NodeStatus{Conditions: []api.NodeCondition{{Type: api.NodeReady, Status: "Full"}}},
+		},
+	}
+	for k, v := range errorCases {
+		errs := ValidateMinion(&v)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+		}
+		for i := range errs {
+			field := errs[i].(errors.ValidationError).Field
+			if field != "id" && !strings.HasPrefix(field, "addresses[") && !strings.HasPrefix(field, "status.conditions[") {
+				t.Errorf("%s: missing prefix for: %v", k, errs[i])
+			}
+		}
+	}
+}
+
+func TestValidateRole(t *testing.T) {
+	validRole := func() api.Role {
+		return api.Role{
+			TypeMeta: api.TypeMeta{ID: "reader", Namespace: api.NamespaceDefault},
+			Rules:    []api.PolicyRule{{Verbs: []string{"get", "list"}, Resources: []string{"pods", "pods/log"}}},
+		}
+	}
+	successCases := map[string]func(*api.Role){
+		"valid":     func(*api.Role) {},
+		"wildcards": func(r *api.Role) { r.Rules = []api.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"*"}}} },
+		"no rules":  func(r *api.Role) { r.Rules = nil },
+	}
+	for k, mutate := range successCases {
+		role := validRole()
+		mutate(&role)
+		if errs := ValidateRole(&role); len(errs) != 0 {
+			t.Errorf("%s: expected success: %v", k, errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.Role)
+		field  string
+	}{
+		"missing id":        {func(r *api.Role) { r.ID = "" }, "id"},
+		"invalid namespace": {func(r *api.Role) { r.Namespace = "a b" }, "namespace"},
+		"missing verbs":     {func(r *api.Role) { r.Rules[0].Verbs = nil }, "rules[0].verbs"},
+		"unsupported verb":  {func(r *api.Role) { r.Rules[0].Verbs = []string{"escalate"} }, "rules[0].verbs"},
+		"missing resources": {func(r *api.Role) { r.Rules[0].Resources = nil }, "rules[0].resources"},
+	}
+	for k, v := range errorCases {
+		role := validRole()
+		v.mutate(&role)
+		errs := ValidateRole(&role)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+
+	clusterRole := api.ClusterRole{TypeMeta: api.TypeMeta{ID: "admin", Namespace: api.NamespaceDefault}}
+	if errs := ValidateClusterRole(&clusterRole); len(errs) != 1 || errs[0].(errors.ValidationError).Field != "namespace" {
+		t.Errorf("expected a cluster role in a namespace to be invalid, got %v", errs)
+	}
+}
+
+func TestValidateRoleBinding(t *testing.T) {
+	validBinding := func() api.RoleBinding {
+		return api.RoleBinding{
+			TypeMeta: api.TypeMeta{ID: "read-pods", Namespace: api.NamespaceDefault},
+			Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "alice"}},
+			RoleRef:  api.RoleRef{Kind: api.RoleRefKindRole, Name: "reader"},
+		}
+	}
+	successCases := map[string]func(*api.RoleBinding){
+		"valid":        func(*api.RoleBinding) {},
+		"cluster role": func(b *api.RoleBinding) { b.RoleRef.Kind = api.RoleRefKindClusterRole },
+	}
+	for k, mutate := range successCases {
+		binding := validBinding()
+		mutate(&binding)
+		if errs := ValidateRoleBinding(&binding); len(errs) != 0 {
+			t.Errorf("%s: expected success: %v", k, errs)
+		}
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.RoleBinding)
+		field  string
+	}{
+		"missing id":        {func(b *api.RoleBinding) { b.ID = "" }, "id"},
+		"missing subjects":  {func(b *api.RoleBinding) { b.Subjects = nil }, "subjects"},
+		"unsupported kind":  {func(b *api.RoleBinding) { b.Subjects[0].Kind = "Group" }, "subjects[0].kind"},
+		"missing name":      {func(b *api.RoleBinding) { b.Subjects[0].Name = "" }, "subjects[0].name"},
+		"unsupported role":  {func(b *api.RoleBinding) { b.RoleRef.Kind = "Policy" }, "roleRef.kind"},
+		"missing role name": {func(b *api.RoleBinding) { b.RoleRef.Name = "" }, "roleRef.name"},
+	}
+	for k, v := range errorCases {
+		binding := validBinding()
+		v.mutate(&binding)
+		errs := ValidateRoleBinding(&binding)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+
+	clusterBinding := api.ClusterRoleBinding{
+		TypeMeta: api.TypeMeta{ID: "admins"},
+		Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "alice"}},
+		RoleRef:  api.RoleRef{Kind: api.RoleRefKindRole, Name: "reader"},
+	}
+	if errs := ValidateClusterRoleBinding(&clusterBinding); len(errs) != 1 || errs[0].(errors.ValidationError).Field != "roleRef.kind" {
+		t.Errorf("expected a cluster role binding to a role to be invalid, got %v", errs)
+	}
+}
+
+func TestValidateEvent(t *testing.T) {
+	validEvent := func() api.Event {
+		return api.Event{
+			TypeMeta:       api.TypeMeta{ID: "abc", Namespace: api.NamespaceDefault},
+			InvolvedObject: api.ObjectReference{Kind: "Pod", Name: "foo"},
+		}
+	}
+	event := validEvent()
+	if errs := ValidateEvent(&event); len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+
+	errorCases := map[string]struct {
+		mutate func(*api.Event)
+		field  string
+	}{
+		"missing id":        {func(e *api.Event) { e.ID = "" }, "id"},
+		"invalid namespace": {func(e *api.Event) { e.Namespace = "a b" }, "namespace"},
+		"missing kind":      {func(e *api.Event) { e.InvolvedObject.Kind = "" }, "involvedObject.kind"},
+		"missing name":      {func(e *api.Event) { e.InvolvedObject.Name = "" }, "involvedObject.name"},
+		"negative count":    {func(e *api.Event) { e.Count = -1 }, "count"},
+	}
+	for k, v := range errorCases {
+		event := validEvent()
+		v.mutate(&event)
+		errs := ValidateEvent(&event)
+		if len(errs) != 1 {
+			t.Errorf("%s: expected one error, got %v", k, errs)
+			continue
+		}
+		if field := errs[0].(errors.ValidationError).Field; field != v.field {
+			t.Errorf("%s: expected an error for %s, got %s", k, v.field, field)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	successCases := []map[string]string{
+		nil,
+		{"a": ""},
+		{"example.com/name": "value-1.2_3"},
+	}
+	for i := range successCases {
+		if errs := ValidateLabels(successCases[i]); len(errs) != 0 {
+			t.Errorf("case[%d] expected success, got %v", i, errs)
+		}
+	}
+
+	errorCases := []map[string]string{
+		{"a b": "c"},
+		{"a": "b c"},
+		{"a": "-b"},
+		{"a=b": "c"},
+		{"a": strings.Repeat("b", 64)},
+	}
+	for i := range errorCases {
+		if errs := ValidateLabels(errorCases[i]); len(errs) != 1 {
+			t.Errorf("case[%d] expected one error, got %v", i, errs)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	manifest := api.ContainerManifest{Version: "v1beta1"}
+	errorCases := map[string]struct {
+		obj    runtime.Object
+		fields []string
+	}{
+		"pod missing id": {
+			obj:    &api.Pod{TypeMeta: api.TypeMeta{Namespace: api.NamespaceDefault}, DesiredState: api.PodState{Manifest: manifest}},
+			fields: []string{"id"},
+		},
+		"pod invalid label": {
+			obj: &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+				Labels:       map[string]string{"name": "foo bar"},
+				DesiredState: api.PodState{Manifest: manifest},
+			},
+			fields: []string{"labels.name"},
+		},
+		"service port out of range": {
+			obj: &api.Service{
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+				Port:     65536,
+				Selector: map[string]string{"name": "foo"},
+			},
+			fields: []string{"port"},
+		},
+		"service invalid selector": {
+			obj: &api.Service{
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+				Port:     80,
+				Selector: map[string]string{"name in (foo)": "foo"},
+			},
+			fields: []string{"selector.name in (foo)"},
+		},
+		"controller negative replicas": {
+			obj: &api.ReplicationController{
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+				DesiredState: api.ReplicationControllerState{
+					Replicas:        -1,
+					ReplicaSelector: map[string]string{"name": "foo"},
+					PodTemplate:     api.PodTemplate{DesiredState: api.PodState{Manifest: manifest}, Labels: map[string]string{"name": "foo"}},
+				},
+			},
+			fields: []string{"desiredState.replicas"},
+		},
+		"controller invalid selector": {
+			obj: &api.ReplicationController{
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+				DesiredState: api.ReplicationControllerState{
+					ReplicaSelector: map[string]string{"name": "!foo"},
+					PodTemplate:     api.PodTemplate{DesiredState: api.PodState{Manifest: manifest}, Labels: map[string]string{"name": "!foo"}},
+				},
+			},
+			fields: []string{"desiredState.replicaSelector.name", "desiredState.podTemplate.labels.name"},
+		},
+		"minion missing id": {
+			obj:    &api.Minion{},
+			fields: []string{"id"},
+		},
+		"event missing involved object": {
+			obj:    &api.Event{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}},
+			fields: []string{"involvedObject.kind", "involvedObject.name"},
+		},
+	}
+	for k, v := range errorCases {
+		errs := Validate(v.obj)
+		fields := []string{}
+		for i := range errs {
+			fields = append(fields, errs[i].(errors.ValidationError).Field)
+		}
+		if !reflect.DeepEqual(fields, v.fields) {
+			t.Errorf("%s: expected errors for %v, got %v", k, v.fields, errs)
+		}
+	}
+
+	if errs := Validate(&api.Namespace{}); len(errs) != 0 {
+		t.Errorf("expected kinds without validation to pass, got %v", errs)
+	}
+}
+
+func TestValidateLease(t *testing.T) {
+	lease := api.Lease{
+		TypeMeta: api.TypeMeta{ID: "kube-controller-manager", Namespace: api.NamespaceSystem},
+		Spec:     api.LeaseSpec{HolderIdentity: "host-1", LeaseDurationSeconds: 15},
+	}
+	if errs := ValidateLease(&lease); len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+
+	errorCases := map[string]struct {
+		lease api.Lease
+		field string
+	}{
+		"missing id":        {api.Lease{TypeMeta: api.TypeMeta{Namespace: api.NamespaceSystem}}, "id"},
+		"invalid namespace": {api.Lease{TypeMeta: api.TypeMeta{ID: "abc", Namespace: "a b"}}, "namespace"},
+		"negative duration": {api.Lease{
+			TypeMeta: api.TypeMeta{ID: "abc", Namespace: api.NamespaceSystem},
+			Spec:     api.LeaseSpec{LeaseDurationSeconds: -1},
+		}, "spec.leaseDurationSeconds"},
+	}
+	for k, v := range errorCases {
+		errs := ValidateLease(&v.lease)
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %s", k)
+			continue
+		}
+		for i := range errs {
+			if field := errs[i].(errors.ValidationError).Field; field != v.field {
+				t.Errorf("%s: expected field %q, got %q", k, v.field, field)
+			}
+		}
+	}
+}
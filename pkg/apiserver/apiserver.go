@@ -18,13 +18,19 @@ package apiserver
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/healthz"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/version"
 	"github.com/golang/glog"
 )
@@ -43,6 +49,7 @@ type defaultAPIServer struct {
 
 const (
 	StatusUnprocessableEntity = 422
+	StatusTooManyRequests     = 429
 )
 
 // Handle returns a Handler function that exposes the provided storage interfaces
@@ -84,9 +91,9 @@ func NewAPIGroup(storage map[string]RESTStorage, codec runtime.Codec, canonicalP
 	}}
 }
 
-// InstallREST registers the REST handlers (storage, watch, and operations) into a mux.
-// It is expected that the provided prefix will serve all operations. Path MUST NOT end
-// in a slash.
+// InstallREST registers the REST handlers (storage, watch, and operations) into a mux,
+// along with a list of the resources at each prefix itself. It is expected that the
+// provided prefix will serve all operations. Path MUST NOT end in a slash.
 func (g *APIGroup) InstallREST(mux mux, paths ...string) {
 	restHandler := &g.handler
 	watchHandler := &WatchHandler{g.handler.storage, g.handler.codec}
@@ -106,6 +113,10 @@ func (g *APIGroup) InstallREST(mux mux, paths ...string) {
 	for _, prefix := range paths {
 		prefix = strings.TrimRight(prefix, "/")
 		proxyHandler := &ProxyHandler{prefix + "/proxy/", g.handler.storage, g.handler.codec}
+		resources := resourceList(path.Base(prefix), g.handler.storage)
+		mux.HandleFunc(prefix, func(w http.ResponseWriter, req *http.Request) {
+			writeRawJSON(http.StatusOK, resources, w)
+		})
 		mux.Handle(prefix+"/", http.StripPrefix(prefix, restHandler))
 		mux.Handle(prefix+"/watch/", http.StripPrefix(prefix+"/watch/", watchHandler))
 		mux.Handle(prefix+"/proxy/", http.StripPrefix(prefix+"/proxy/", proxyHandler))
@@ -143,6 +154,9 @@ func writeJSON(statusCode int, codec runtime.Codec, object runtime.Object, w htt
 		errorJSON(err, codec, w)
 		return
 	}
+	if status, ok := object.(*api.Status); ok && status.Details != nil && status.Details.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(status.Details.RetryAfterSeconds))
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	w.Write(output)
@@ -158,7 +172,7 @@ func errorJSON(err error, codec runtime.Codec, w http.ResponseWriter) {
 func writeRawJSON(statusCode int, object interface{}, w http.ResponseWriter) {
 	output, err := json.Marshal(object)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteErrorResponse(w, api.StatusReasonInternalError, err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -177,6 +191,36 @@ func parseTimeout(str string) time.Duration {
 	return 30 * time.Second
 }
 
+// requestNamespace returns the namespace a request is made in: its namespace
+// query parameter, api.NamespaceAll if that is api.NamespaceAllParam, or the
+// default namespace if it has none.
+func requestNamespace(req *http.Request) string {
+	switch namespace := req.URL.Query().Get("namespace"); namespace {
+	case "":
+		return api.NamespaceDefault
+	case api.NamespaceAllParam:
+		return api.NamespaceAll
+	default:
+		return namespace
+	}
+}
+
+// requestContext returns the context of a request, in the namespace it is
+// made in. It returns an error if that namespace is not a valid name, or if
+// the request spans all namespaces and list is false, since only lists and
+// watches may do so.
+func requestContext(req *http.Request, list bool) (api.Context, error) {
+	namespace := requestNamespace(req)
+	if namespace == api.NamespaceAll {
+		if !list {
+			return nil, apierrs.NewBadRequest("all namespaces may only be listed or watched")
+		}
+	} else if !util.IsDNSLabel(namespace) {
+		return nil, apierrs.NewBadRequest(fmt.Sprintf("invalid namespace %q", namespace))
+	}
+	return api.WithNamespace(api.NewContext(), namespace), nil
+}
+
 func readBody(req *http.Request) ([]byte, error) {
 	defer req.Body.Close()
 	return ioutil.ReadAll(req.Body)
@@ -17,13 +17,17 @@ limitations under the License.
 package apiserver
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"sync"
@@ -34,6 +38,7 @@ import (
 	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/version"
@@ -74,6 +79,12 @@ type SimpleRESTStorage struct {
 	updated *Simple
 	created *Simple
 
+	// The resource version match of the last update
+	updatedMatch api.ResourceVersionMatch
+
+	// The namespace of the last get
+	requestedNamespace string
+
 	// These are set when Watch is called
 	fakeWatch                *watch.FakeWatcher
 	requestedLabelSelector   labels.Selector
@@ -97,6 +108,7 @@ func (storage *SimpleRESTStorage) List(ctx api.Context, label, field labels.Sele
 }
 
 func (storage *SimpleRESTStorage) Get(ctx api.Context, id string) (runtime.Object, error) {
+	storage.requestedNamespace, _ = api.NamespaceFrom(ctx)
 	return api.Scheme.CopyOrDie(&storage.item), storage.errors["get"]
 }
 
@@ -132,6 +144,7 @@ func (storage *SimpleRESTStorage) Create(ctx api.Context, obj runtime.Object) (<
 
 func (storage *SimpleRESTStorage) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
 	storage.updated = obj.(*Simple)
+	storage.updatedMatch = api.ResourceVersionMatchFrom(ctx)
 	if err := storage.errors["update"]; err != nil {
 		return nil, err
 	}
@@ -191,9 +204,13 @@ func TestNotFound(t *testing.T) {
 		"PUT with extra segment":       {"PUT", "/prefix/version/foo/bar/baz"},
 		"watch missing storage":        {"GET", "/prefix/version/watch/"},
 		"watch with bad method":        {"POST", "/prefix/version/watch/foo/bar"},
+		"POST to subresource":          {"POST", "/prefix/version/foo/bar/status"},
+		"DELETE subresource":           {"DELETE", "/prefix/version/foo/bar/status"},
+		"GET missing subresource":      {"GET", "/prefix/version/foo/bar/spec"},
 	}
 	handler := Handle(map[string]RESTStorage{
-		"foo": &SimpleRESTStorage{},
+		"foo":        &SimpleRESTStorage{},
+		"foo/status": &SimpleRESTStorage{},
 	}, codec, "/prefix/version", selfLinker)
 	server := httptest.NewServer(handler)
 	client := http.Client{}
@@ -283,6 +300,35 @@ func TestErrorList(t *testing.T) {
 	}
 }
 
+func TestRequestMetrics(t *testing.T) {
+	storage := map[string]RESTStorage{
+		"metered": &SimpleRESTStorage{
+			errors: map[string]error{"get": apierrs.NewNotFound("simple", "id")},
+		},
+	}
+	handler := Handle(storage, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for _, path := range []string{"/prefix/version/metered", "/prefix/version/metered", "/prefix/version/metered/id"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if e, a := 2.0, metrics.RequestCounter.Value("LIST", "metered", "200"); e != a {
+		t.Errorf("expected %v lists, got %v", e, a)
+	}
+	if e, a := 1.0, metrics.RequestCounter.Value("GET", "metered", "404"); e != a {
+		t.Errorf("expected %v failed gets, got %v", e, a)
+	}
+	if e, a := uint64(2), metrics.RequestLatencies.Count("LIST", "metered"); e != a {
+		t.Errorf("expected %v observed lists, got %v", e, a)
+	}
+}
+
 func TestNonEmptyList(t *testing.T) {
 	storage := map[string]RESTStorage{}
 	simpleStorage := SimpleRESTStorage{
@@ -321,6 +367,140 @@ func TestNonEmptyList(t *testing.T) {
 	}
 }
 
+func TestPagedList(t *testing.T) {
+	simpleStorage := SimpleRESTStorage{}
+	for i := 0; i < 200; i++ {
+		simpleStorage.list = append(simpleStorage.list, Simple{
+			TypeMeta: api.TypeMeta{Kind: "Simple", Namespace: "ns" + fmt.Sprint(i%3), ID: fmt.Sprintf("item%03d", (i*7)%200)},
+		})
+	}
+	handler := Handle(map[string]RESTStorage{"simple": &simpleStorage}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+
+	seen := map[string]bool{}
+	token := ""
+	for pages := 1; ; pages++ {
+		resp, err := http.Get(server.URL + "/prefix/version/simple?limit=50&continue=" + url.QueryEscape(token))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected status: %d, Expected: %d, %#v", resp.StatusCode, http.StatusOK, resp)
+		}
+		var listOut SimpleList
+		if _, err := extractBody(resp, &listOut); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(listOut.Items) > 50 {
+			t.Errorf("expected at most 50 items, got %d", len(listOut.Items))
+		}
+		for _, item := range listOut.Items {
+			key := item.Namespace + "/" + item.ID
+			if seen[key] {
+				t.Errorf("item %s listed twice", key)
+			}
+			seen[key] = true
+		}
+		token = listOut.Continue
+		if len(token) == 0 {
+			if pages != 4 {
+				t.Errorf("expected 4 pages, got %d", pages)
+			}
+			break
+		}
+		if pages > 4 {
+			t.Fatalf("list did not end after %d pages", pages)
+		}
+	}
+	if len(seen) != 200 {
+		t.Errorf("expected 200 items, got %d", len(seen))
+	}
+}
+
+// pagingRESTStorage lists pages itself, as storage backed by etcd does.
+type pagingRESTStorage struct {
+	SimpleRESTStorage
+	page ListPage
+}
+
+func (storage *pagingRESTStorage) ListPage(ctx api.Context, label, field labels.Selector, page ListPage) (runtime.Object, bool, error) {
+	storage.page = page
+	return &SimpleList{Items: storage.list}, true, nil
+}
+
+func TestPagedListPager(t *testing.T) {
+	storage := &pagingRESTStorage{}
+	storage.list = []Simple{
+		{TypeMeta: api.TypeMeta{Namespace: "ns1", ID: "b"}},
+		{TypeMeta: api.TypeMeta{Namespace: "ns1", ID: "c"}},
+	}
+	handler := Handle(map[string]RESTStorage{"simple": storage}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple?limit=2&continue=" + url.QueryEscape(continueToken("ns1", "a")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var listOut SimpleList
+	if _, err := extractBody(resp, &listOut); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := (ListPage{Limit: 2, Namespace: "ns1", ID: "a"}), storage.page; e != a {
+		t.Errorf("expected the page %#v to be listed, got %#v", e, a)
+	}
+	if len(listOut.Items) != 2 || listOut.Continue != continueToken("ns1", "c") {
+		t.Errorf("expected the page with a token after its last item, got %#v", listOut)
+	}
+
+	// Lists which are not paged are served by List.
+	storage.page = ListPage{}
+	resp, err = http.Get(server.URL + "/prefix/version/simple")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if storage.page != (ListPage{}) {
+		t.Errorf("expected an unpaged list not to be paged, got %#v", storage.page)
+	}
+}
+
+func TestListPageCursor(t *testing.T) {
+	table := []struct {
+		page      ListPage
+		namespace string
+		cursor    string
+		done      bool
+	}{
+		{ListPage{}, "default", "", false},
+		{ListPage{Namespace: "default", ID: "foo"}, api.NamespaceAll, "default/foo", false},
+		{ListPage{ID: "minion1"}, api.NamespaceAll, "minion1", false},
+		{ListPage{Namespace: "default", ID: "foo"}, "default", "foo", false},
+		{ListPage{Namespace: "a", ID: "foo"}, "default", "", false},
+		{ListPage{Namespace: "other", ID: "foo"}, "default", "", true},
+	}
+	for _, item := range table {
+		cursor, done := item.page.Cursor(item.namespace)
+		if cursor != item.cursor || done != item.done {
+			t.Errorf("%#v in %q: expected %q, %v, got %q, %v", item.page, item.namespace, item.cursor, item.done, cursor, done)
+		}
+	}
+}
+
+func TestPagedListInvalid(t *testing.T) {
+	handler := Handle(map[string]RESTStorage{"simple": &SimpleRESTStorage{}}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+
+	for _, query := range []string{"limit=-1", "limit=ten", "continue=%21%21", "continue=" + url.QueryEscape(continueToken("default", ""))} {
+		resp, err := http.Get(server.URL + "/prefix/version/simple?" + query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: unexpected status: %d, Expected: %d", query, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
+
 func TestGet(t *testing.T) {
 	storage := map[string]RESTStorage{}
 	simpleStorage := SimpleRESTStorage{
@@ -351,6 +531,61 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetNamespace(t *testing.T) {
+	storage := map[string]RESTStorage{}
+	simpleStorage := SimpleRESTStorage{
+		item: Simple{
+			Name: "foo",
+		},
+	}
+	namespaceLinker := &setTestSelfLinker{
+		t:           t,
+		expectedSet: "/prefix/version/simple/id?namespace=other",
+	}
+	storage["simple"] = &simpleStorage
+	handler := Handle(storage, codec, "/prefix/version", namespaceLinker)
+	server := httptest.NewServer(handler)
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple/id?namespace=other")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected response %#v", resp)
+	}
+	if simpleStorage.requestedNamespace != "other" {
+		t.Errorf("Unexpected namespace: %q", simpleStorage.requestedNamespace)
+	}
+	if !namespaceLinker.called {
+		t.Errorf("Never set self link")
+	}
+
+	server = httptest.NewServer(Handle(storage, codec, "/prefix/version", selfLinker))
+	resp, err = http.Get(server.URL + "/prefix/version/simple/id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if simpleStorage.requestedNamespace != api.NamespaceDefault {
+		t.Errorf("Unexpected namespace: %q", simpleStorage.requestedNamespace)
+	}
+}
+
+func TestGetInvalidNamespace(t *testing.T) {
+	storage := map[string]RESTStorage{}
+	simpleStorage := SimpleRESTStorage{}
+	storage["simple"] = &simpleStorage
+	handler := Handle(storage, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple/id?namespace=Not_Valid")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected response %#v", resp)
+	}
+}
+
 func TestGetMissing(t *testing.T) {
 	storage := map[string]RESTStorage{}
 	simpleStorage := SimpleRESTStorage{
@@ -447,6 +682,424 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateResourceVersionMatch(t *testing.T) {
+	simpleStorage := SimpleRESTStorage{}
+	handler := Handle(map[string]RESTStorage{"simple": &simpleStorage}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	body, err := codec.Encode(&Simple{TypeMeta: api.TypeMeta{ID: "id", ResourceVersion: "3"}, Name: "bar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	table := []struct {
+		query    string
+		code     int
+		expected api.ResourceVersionMatch
+	}{
+		{"", http.StatusOK, api.ResourceVersionMatchExact},
+		{"&resourceVersionMatch=Exact", http.StatusOK, api.ResourceVersionMatchExact},
+		{"&resourceVersionMatch=NotOlderThan", http.StatusOK, api.ResourceVersionMatchNotOlderThan},
+		{"&resourceVersionMatch=Newer", http.StatusBadRequest, ""},
+	}
+	for _, item := range table {
+		simpleStorage.updatedMatch = ""
+		request, err := http.NewRequest("PUT", server.URL+"/prefix/version/simple/id?sync=true"+item.query, bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response.Body.Close()
+		if response.StatusCode != item.code {
+			t.Errorf("%q: expected status %d, got %d", item.query, item.code, response.StatusCode)
+		}
+		if simpleStorage.updatedMatch != item.expected {
+			t.Errorf("%q: expected the match %q, got %q", item.query, item.expected, simpleStorage.updatedMatch)
+		}
+	}
+
+	simpleStorage.errors = map[string]error{"update": apierrs.NewConflict("simple", "id", errors.New("the object has been modified"))}
+	request, err := http.NewRequest("PUT", server.URL+"/prefix/version/simple/id", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusConflict {
+		t.Errorf("expected a conflict, got %d", response.StatusCode)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	simpleStorage := SimpleRESTStorage{
+		item: Simple{TypeMeta: api.TypeMeta{ID: "id", ResourceVersion: "7"}, Name: "foo"},
+	}
+	selfLinker := &setTestSelfLinker{
+		t:           t,
+		expectedSet: "/prefix/version/simple/id",
+	}
+	handler := Handle(map[string]RESTStorage{"simple": &simpleStorage}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	patch := func(contentType, body string) *http.Response {
+		request, err := http.NewRequest("PATCH", server.URL+"/prefix/version/simple/id", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		request.Header.Set("Content-Type", contentType)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return response
+	}
+
+	response := patch(StrategicMergePatchType, `{"name": "bar"}`)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status %d", response.StatusCode)
+	}
+	if simpleStorage.updated == nil || simpleStorage.updated.Name != "bar" || simpleStorage.updated.ResourceVersion != "7" {
+		t.Errorf("unexpected update %#v", simpleStorage.updated)
+	}
+	if !selfLinker.called {
+		t.Errorf("Never set self link")
+	}
+
+	simpleStorage.updated = nil
+	for _, item := range []struct {
+		contentType, body string
+	}{
+		{"application/json", `{"name": "bar"}`},
+		{StrategicMergePatchType, `["bar"]`},
+	} {
+		response := patch(item.contentType, item.body)
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s %s: expected a bad request, got %d", item.contentType, item.body, response.StatusCode)
+		}
+	}
+	if simpleStorage.updated != nil {
+		t.Errorf("unexpected update %#v", simpleStorage.updated)
+	}
+
+	simpleStorage.errors = map[string]error{"get": apierrs.NewNotFound("simple", "id")}
+	response = patch(StrategicMergePatchType, `{"name": "bar"}`)
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected not found, got %d", response.StatusCode)
+	}
+}
+
+func TestSubresource(t *testing.T) {
+	storage := map[string]RESTStorage{}
+	simpleStorage := SimpleRESTStorage{}
+	statusStorage := SimpleRESTStorage{
+		item: Simple{
+			Name: "foo",
+		},
+	}
+	storage["simple"] = &simpleStorage
+	storage["simple/status"] = &statusStorage
+	selfLinker := &setTestSelfLinker{
+		t:           t,
+		expectedSet: "/prefix/version/simple/id/status",
+	}
+	handler := Handle(storage, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple/id/status")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var itemOut Simple
+	body, err := extractBody(resp, &itemOut)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if itemOut.Name != statusStorage.item.Name {
+		t.Errorf("Unexpected data: %#v, expected %#v (%s)", itemOut, statusStorage.item, string(body))
+	}
+
+	item := &Simple{
+		Name: "bar",
+	}
+	data, err := codec.Encode(item)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	client := http.Client{}
+	request, err := http.NewRequest("PUT", server.URL+"/prefix/version/simple/id/status", bytes.NewReader(data))
+	_, err = client.Do(request)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if statusStorage.updated == nil || statusStorage.updated.Name != item.Name {
+		t.Errorf("Unexpected update value %#v, expected %#v.", statusStorage.updated, item)
+	}
+	if simpleStorage.updated != nil {
+		t.Errorf("Unexpected update of the object itself: %#v", simpleStorage.updated)
+	}
+	if !selfLinker.called {
+		t.Errorf("Never set self link")
+	}
+}
+
+// NamedCreatingRESTStorage records the objects created through CreateNamed.
+type NamedCreatingRESTStorage struct {
+	SimpleRESTStorage
+	err error
+
+	// Set when CreateNamed is called.
+	createdID string
+	created   *Simple
+}
+
+func (storage *NamedCreatingRESTStorage) CreateNamed(ctx api.Context, id string, obj runtime.Object) (<-chan runtime.Object, error) {
+	if storage.err != nil {
+		return nil, storage.err
+	}
+	storage.createdID = id
+	storage.created = obj.(*Simple)
+	return MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, nil
+	}), nil
+}
+
+func TestNamedCreate(t *testing.T) {
+	evictions := &NamedCreatingRESTStorage{}
+	handler := Handle(map[string]RESTStorage{
+		"simple":          &SimpleRESTStorage{},
+		"simple/eviction": evictions,
+	}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	data, err := codec.Encode(&Simple{Name: "foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := http.Post(server.URL+"/prefix/version/simple/id/eviction?sync=true", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var status api.Status
+	body, err := extractBody(resp, &status)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || status.Status != api.StatusSuccess {
+		t.Errorf("Unexpected response %d: %s", resp.StatusCode, body)
+	}
+	if evictions.createdID != "id" || evictions.created == nil || evictions.created.Name != "foo" {
+		t.Errorf("Unexpected create of %q: %#v", evictions.createdID, evictions.created)
+	}
+
+	evictions.err = apierrs.NewTooManyRequests("not now", 10)
+	resp, err = http.Post(server.URL+"/prefix/version/simple/id/eviction", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != StatusTooManyRequests {
+		t.Errorf("Expected %d, Got %d", StatusTooManyRequests, resp.StatusCode)
+	}
+	if e, a := "10", resp.Header.Get("Retry-After"); e != a {
+		t.Errorf("Expected Retry-After %q, Got %q", e, a)
+	}
+}
+
+// StreamingRESTStorage serves the contents of a resource as a stream.
+type StreamingRESTStorage struct {
+	SimpleRESTStorage
+	contents string
+	flush    bool
+	err      error
+	// If set, streamed instead of contents.
+	stream io.ReadCloser
+
+	// Set when ResourceStream is called.
+	requestedID    string
+	requestedQuery url.Values
+}
+
+func (storage *StreamingRESTStorage) ResourceStream(ctx api.Context, id string, query url.Values) (io.ReadCloser, string, bool, error) {
+	storage.requestedID = id
+	storage.requestedQuery = query
+	if storage.err != nil {
+		return nil, "", false, storage.err
+	}
+	if storage.stream != nil {
+		return storage.stream, "text/plain", storage.flush, nil
+	}
+	return ioutil.NopCloser(strings.NewReader(storage.contents)), "text/plain", storage.flush, nil
+}
+
+func TestStream(t *testing.T) {
+	for _, flush := range []bool{false, true} {
+		storage := map[string]RESTStorage{}
+		streamStorage := StreamingRESTStorage{contents: "line 1\nline 2\n", flush: flush}
+		storage["simple/log"] = &streamStorage
+		handler := Handle(storage, codec, "/prefix/version", selfLinker)
+		server := httptest.NewServer(handler)
+
+		resp, err := http.Get(server.URL + "/prefix/version/simple/id/log?tail=5")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != streamStorage.contents {
+			t.Errorf("Unexpected response: %d %q", resp.StatusCode, body)
+		}
+		if e, a := "text/plain", resp.Header.Get("Content-Type"); e != a {
+			t.Errorf("Expected content type %q, got %q", e, a)
+		}
+		chunked := len(resp.TransferEncoding) == 1 && resp.TransferEncoding[0] == "chunked"
+		if flush != chunked {
+			t.Errorf("Expected chunked %v, got %v", flush, resp.TransferEncoding)
+		}
+		if streamStorage.requestedID != "id" || streamStorage.requestedQuery.Get("tail") != "5" {
+			t.Errorf("Unexpected request: %s %v", streamStorage.requestedID, streamStorage.requestedQuery)
+		}
+		server.Close()
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	storage := map[string]RESTStorage{}
+	storage["simple/log"] = &StreamingRESTStorage{err: apierrs.NewNotFound("simple", "id")}
+	handler := Handle(storage, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple/id/log")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected status: %d, Expected: %d, %#v", resp.StatusCode, http.StatusNotFound, resp)
+	}
+}
+
+// ConnectingRESTStorage connects to a resource at a fixed location.
+type ConnectingRESTStorage struct {
+	SimpleRESTStorage
+	location string
+	err      error
+
+	// Set when ConnectLocation is called.
+	requestedID    string
+	requestedQuery url.Values
+}
+
+func (storage *ConnectingRESTStorage) ConnectLocation(ctx api.Context, id string, query url.Values) (*url.URL, error) {
+	storage.requestedID = id
+	storage.requestedQuery = query
+	if storage.err != nil {
+		return nil, storage.err
+	}
+	return url.Parse(storage.location)
+}
+
+// upgradeRequest writes a request to upgrade the connection to path, and returns
+// the response.
+func upgradeRequest(t *testing.T, conn net.Conn, path string) *http.Response {
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n", path)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+func TestConnect(t *testing.T) {
+	var backendPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		backendPath = req.URL.RequestURI()
+		if req.Header.Get("Upgrade") != "echo" {
+			t.Errorf("Expected the upgrade to be forwarded, got %#v", req.Header)
+		}
+		conn, buffered, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		line, _ := buffered.ReadString('\n')
+		fmt.Fprintf(conn, "echo: %s", line)
+	}))
+	defer backend.Close()
+
+	storage := map[string]RESTStorage{}
+	connectStorage := ConnectingRESTStorage{location: backend.URL + "/exec/id?command=ls"}
+	storage["simple/exec"] = &connectStorage
+	handler := Handle(storage, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	resp := upgradeRequest(t, conn, "/prefix/version/simple/id/exec?command=ls")
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Unexpected status: %d", resp.StatusCode)
+	}
+	fmt.Fprintf(conn, "hello\n")
+	data, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if string(data) != "echo: hello\n" {
+		t.Errorf("Unexpected data: %q", data)
+	}
+	if connectStorage.requestedID != "id" || connectStorage.requestedQuery.Get("command") != "ls" {
+		t.Errorf("Unexpected request: %s %v", connectStorage.requestedID, connectStorage.requestedQuery)
+	}
+	if backendPath != "/exec/id?command=ls" {
+		t.Errorf("Unexpected path at the backend: %s", backendPath)
+	}
+}
+
+func TestConnectErrors(t *testing.T) {
+	storage := map[string]RESTStorage{}
+	storage["simple/exec"] = &ConnectingRESTStorage{err: apierrs.NewNotFound("simple", "id")}
+	handler := Handle(storage, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple/id/exec")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status: %d, Expected: %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	resp = upgradeRequest(t, conn, "/prefix/version/simple/id/exec")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected status: %d, Expected: %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
 func TestUpdateMissing(t *testing.T) {
 	storage := map[string]RESTStorage{}
 	ID := "id"
@@ -839,8 +1492,8 @@ func TestCORSAllowedOrigins(t *testing.T) {
 				t.Errorf("Expected Access-Control-Allow-Headers header to be set")
 			}
 
-			if response.Header.Get("Access-Control-Allow-Methods") == "" {
-				t.Errorf("Expected Access-Control-Allow-Methods header to be set")
+			if methods := response.Header.Get("Access-Control-Allow-Methods"); !strings.Contains(methods, "PATCH") {
+				t.Errorf("Expected Access-Control-Allow-Methods header to allow PATCH, got %q", methods)
 			}
 		} else {
 			if response.Header.Get("Access-Control-Allow-Origin") != "" {
@@ -861,3 +1514,132 @@ func TestCORSAllowedOrigins(t *testing.T) {
 		}
 	}
 }
+
+// SlowRESTStorage blocks List until unblock is closed.
+type SlowRESTStorage struct {
+	SimpleRESTStorage
+	unblock chan struct{}
+}
+
+func (storage *SlowRESTStorage) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	<-storage.unblock
+	return storage.SimpleRESTStorage.List(ctx, label, field)
+}
+
+func TestTimeoutHandler(t *testing.T) {
+	slow := &SlowRESTStorage{unblock: make(chan struct{})}
+	defer close(slow.unblock)
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close()
+	isLongRunning := func(req *http.Request) bool {
+		return strings.Contains(req.URL.Path, "/watch/") || strings.HasSuffix(req.URL.Path, "/log")
+	}
+	handler := TimeoutHandler(Handle(map[string]RESTStorage{
+		"simple":     slow,
+		"simple/log": &StreamingRESTStorage{stream: pipeReader, flush: true},
+	}, codec, "/prefix/version", selfLinker), 50*time.Millisecond, 200*time.Millisecond, isLongRunning)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/prefix/version/simple")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("Expected %d, Got %d", http.StatusGatewayTimeout, resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/prefix/version/simple/id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected %d, Got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	for _, path := range []string{"/prefix/version/watch/simple", "/prefix/version/simple/id/log"} {
+		start := time.Now()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: Expected %d, Got %d", path, http.StatusOK, resp.StatusCode)
+		}
+		// The response streams until the long running timeout ends it.
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("%s: Expected the long running timeout to apply, ended after %v", path, elapsed)
+		}
+	}
+}
+
+// ClusterRESTStorage is a SimpleRESTStorage whose resources are not in a
+// namespace.
+type ClusterRESTStorage struct {
+	SimpleRESTStorage
+}
+
+func (*ClusterRESTStorage) NamespaceScoped() bool {
+	return false
+}
+
+func TestResourceList(t *testing.T) {
+	handler := Handle(map[string]RESTStorage{
+		"simple":          &SimpleRESTStorage{},
+		"simple/status":   &SimpleRESTStorage{},
+		"simple/log":      &StreamingRESTStorage{},
+		"simple/exec":     &ConnectingRESTStorage{},
+		"simple/eviction": &NamedCreatingRESTStorage{},
+		"cluster":         &ClusterRESTStorage{},
+	}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/prefix/version")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var list api.APIResourceList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	expected := api.APIResourceList{
+		GroupVersion: "version",
+		Resources: []api.APIResource{
+			{Name: "cluster", Namespaced: false, Verbs: []string{"get", "list", "watch", "create", "update", "delete"}},
+			{Name: "simple", Namespaced: true, Verbs: []string{"get", "list", "watch", "create", "update", "delete"}},
+			{Name: "simple/eviction", Namespaced: true, Verbs: []string{"create"}},
+			{Name: "simple/exec", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "simple/log", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "simple/status", Namespaced: true, Verbs: []string{"get", "update"}},
+		},
+	}
+	if !reflect.DeepEqual(expected, list) {
+		t.Errorf("Expected %#v, Got %#v", expected, list)
+	}
+}
+
+func TestInstallVersions(t *testing.T) {
+	mux := http.NewServeMux()
+	InstallVersions(mux, "/prefix/", "v1", "v2")
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/prefix")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var versions api.APIVersions
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if !reflect.DeepEqual([]string{"v1", "v2"}, versions.Versions) {
+		t.Errorf("Unexpected versions %v", versions.Versions)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
+	"github.com/golang/glog"
+)
+
+// AuditLog records the requests served by the apiserver.
+type AuditLog interface {
+	// Log records that req was answered with statusCode on behalf of username.
+	// originalUser is the user who made req if it was served as username
+	// through impersonation, and empty otherwise.
+	Log(req *http.Request, statusCode int, username, originalUser string)
+}
+
+// NullAuditLog discards every record.
+type NullAuditLog struct{}
+
+// Log does nothing.
+func (NullAuditLog) Log(req *http.Request, statusCode int, username, originalUser string) {}
+
+// auditRecord is a single line of a FileAuditLog.
+type auditRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Verb      string    `json:"verb"`
+	Path      string    `json:"path"`
+	SourceIP  string    `json:"sourceIP"`
+	Code      int       `json:"code"`
+	User      string    `json:"user,omitempty"`
+	// OriginalUser and ImpersonatedUser are set when the request was made by
+	// OriginalUser as ImpersonatedUser, who is also User.
+	OriginalUser     string `json:"originalUser,omitempty"`
+	ImpersonatedUser string `json:"impersonatedUser,omitempty"`
+}
+
+// FileAuditLog appends one JSON object per request to a file.
+type FileAuditLog struct {
+	lock sync.Mutex
+	file *os.File
+	now  func() time.Time
+}
+
+// NewFileAuditLog opens path for appending, creating it if needed.
+func NewFileAuditLog(path string) (*FileAuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditLog{file: file, now: time.Now}, nil
+}
+
+// Log appends a record of req to the file.
+func (l *FileAuditLog) Log(req *http.Request, statusCode int, username, originalUser string) {
+	sourceIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		sourceIP = req.RemoteAddr
+	}
+	record := auditRecord{
+		Timestamp: l.now().UTC(),
+		Verb:      req.Method,
+		Path:      req.URL.Path,
+		SourceIP:  sourceIP,
+		Code:      statusCode,
+		User:      username,
+	}
+	if len(originalUser) > 0 {
+		record.OriginalUser = originalUser
+		record.ImpersonatedUser = username
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		glog.Errorf("Unable to encode audit record: %v", err)
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		glog.Errorf("Unable to write audit record: %v", err)
+	}
+}
+
+// Close closes the underlying file.
+func (l *FileAuditLog) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.Close()
+}
+
+// RequestUsers returns the user a request is made by, if it is known.
+type RequestUsers interface {
+	Get(req *http.Request) (user.Info, bool)
+}
+
+// isMutating returns true for the methods which change stored state.
+func isMutating(method string) bool {
+	switch method {
+	case "POST", "PUT", "DELETE", "PATCH":
+		return true
+	}
+	return false
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush, CloseNotify and Hijack pass through to the underlying ResponseWriter,
+// so that watches, streams and connections can be served through a statusRecorder.
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := httplog.Unlogged(r.ResponseWriter).(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) CloseNotify() <-chan bool {
+	if cn, ok := httplog.Unlogged(r.ResponseWriter).(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := httplog.Unlogged(r.ResponseWriter).(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("the underlying ResponseWriter cannot be hijacked")
+}
+
+// Audit wraps an http Handler so that every mutating request it serves is
+// recorded in log, along with the user who made it if it is served as another
+// user; see Impersonate. users may be nil if requests are not authenticated.
+func Audit(handler http.Handler, log AuditLog, users RequestUsers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !isMutating(req.Method) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		username := ""
+		if users != nil {
+			if u, ok := users.Get(req); ok {
+				username = u.GetName()
+			}
+		}
+		originalUser := ""
+		if u, ok := ImpersonatorFrom(req.Context()); ok {
+			originalUser = u.GetName()
+		}
+		recorder := &statusRecorder{w, http.StatusOK}
+		handler.ServeHTTP(recorder, req)
+		log.Log(req, recorder.status, username, originalUser)
+	})
+}
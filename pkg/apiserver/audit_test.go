@@ -0,0 +1,154 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+)
+
+// recordingAuditLog remembers the requests it is asked to log.
+type recordingAuditLog struct {
+	records []string
+}
+
+func (l *recordingAuditLog) Log(req *http.Request, statusCode int, username, originalUser string) {
+	record := strings.Join([]string{req.Method, req.URL.Path, http.StatusText(statusCode), username}, " ")
+	if len(originalUser) > 0 {
+		record += " by " + originalUser
+	}
+	l.records = append(l.records, record)
+}
+
+// fakeRequestUsers authenticates every request as the same user.
+type fakeRequestUsers struct {
+	name string
+}
+
+func (f fakeRequestUsers) Get(req *http.Request) (user.Info, bool) {
+	return &user.DefaultInfo{Name: f.name}, true
+}
+
+func TestAudit(t *testing.T) {
+	simpleStorage := &SimpleRESTStorage{}
+	log := &recordingAuditLog{}
+	handler := Audit(Handle(map[string]RESTStorage{
+		"foo": simpleStorage,
+	}, codec, "/prefix/version", selfLinker), log, fakeRequestUsers{"bob"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	body, _ := codec.Encode(&Simple{Name: "bar"})
+	for _, request := range []struct{ method, path string }{
+		{"GET", "/prefix/version/foo"},
+		{"POST", "/prefix/version/foo?sync=true"},
+		{"GET", "/prefix/version/foo/bar"},
+		{"DELETE", "/prefix/version/foo/bar?sync=true"},
+		{"PUT", "/prefix/version/missing/bar"},
+	} {
+		req, _ := http.NewRequest(request.method, server.URL+request.path, bytes.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	expected := []string{
+		"POST /prefix/version/foo OK bob",
+		"DELETE /prefix/version/foo/bar OK bob",
+		"PUT /prefix/version/missing/bar Not Found bob",
+	}
+	if !reflect.DeepEqual(log.records, expected) {
+		t.Errorf("expected %v, got %v", expected, log.records)
+	}
+}
+
+func TestNullAuditLog(t *testing.T) {
+	called := false
+	handler := Audit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		called = true
+	}), NullAuditLog{}, nil)
+	req, _ := http.NewRequest("POST", "/foo", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !called {
+		t.Errorf("expected the request to be served")
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	log, err := NewFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest("POST", "/api/v1beta1/pods", nil)
+	req.RemoteAddr = "10.0.0.1:4321"
+	log.Log(req, http.StatusAccepted, "bob", "")
+	req, _ = http.NewRequest("DELETE", "/api/v1beta1/pods/foo", nil)
+	req.RemoteAddr = "10.0.0.2:4321"
+	log.Log(req, http.StatusNotFound, "", "")
+	req, _ = http.NewRequest("PUT", "/api/v1beta1/pods/foo", nil)
+	req.RemoteAddr = "10.0.0.3:4321"
+	log.Log(req, http.StatusOK, "bob", "admin")
+	if err := log.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	expected := []auditRecord{
+		{Timestamp: now, Verb: "POST", Path: "/api/v1beta1/pods", SourceIP: "10.0.0.1", Code: http.StatusAccepted, User: "bob"},
+		{Timestamp: now, Verb: "DELETE", Path: "/api/v1beta1/pods/foo", SourceIP: "10.0.0.2", Code: http.StatusNotFound},
+		{Timestamp: now, Verb: "PUT", Path: "/api/v1beta1/pods/foo", SourceIP: "10.0.0.3", Code: http.StatusOK, User: "bob", OriginalUser: "admin", ImpersonatedUser: "bob"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d records, got %q", len(expected), lines)
+	}
+	for i, line := range lines {
+		var record auditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(record, expected[i]) {
+			t.Errorf("expected %#v, got %#v", expected[i], record)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"net/http"
+	"strings"
+
+	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// AuthorizationAttributes describes a request to the API.
+type AuthorizationAttributes struct {
+	// The user making the request, or nil if it is not known.
+	User user.Info
+	// One of get, list, watch, create, update, patch, delete or proxy.
+	// Requests which connect to a resource are creates.
+	Verb string
+	// The name the storage is served under, e.g. "pods" or "pods/log".
+	Resource string
+	// The namespace of the request, or "" for resources which are not in a
+	// namespace.
+	Namespace string
+	// The id of the object, if the request is for a single object.
+	Name string
+	// The path of the request, if it is not for a resource, e.g. "/logs/";
+	// Resource, Namespace and Name are empty then.
+	Path string
+}
+
+// Authorizer decides whether a request to the API may be served.
+type Authorizer interface {
+	// Authorize returns an error if the request described by a must be denied.
+	Authorize(a AuthorizationAttributes) error
+}
+
+// AuthorizerFunc adapts an ordinary function to the Authorizer interface.
+type AuthorizerFunc func(a AuthorizationAttributes) error
+
+// Authorize calls f(a).
+func (f AuthorizerFunc) Authorize(a AuthorizationAttributes) error {
+	return f(a)
+}
+
+// requestAttributes returns the attributes of a request for the API served at
+// prefix by storage, or false if req is not for a resource, e.g. when it asks
+// for the versions or resources served.
+func requestAttributes(req *http.Request, prefix string, storage map[string]RESTStorage) (AuthorizationAttributes, bool) {
+	a := AuthorizationAttributes{}
+	parts, ok := resourcePath(req, prefix)
+	if !ok {
+		return a, false
+	}
+	a.Verb = methodVerb(req.Method)
+	if req.Method == "GET" {
+		if len(parts) == 1 {
+			a.Verb = "list"
+		}
+		if req.URL.Query().Get("watch") == "true" {
+			a.Verb = "watch"
+		}
+	}
+	switch parts[0] {
+	case "watch":
+		a.Verb = "watch"
+		parts = parts[1:]
+	case "proxy":
+		a.Verb = "proxy"
+		parts = parts[1:]
+	case "redirect":
+		a.Verb = "get"
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return a, false
+	}
+	a.Resource = parts[0]
+	if len(parts) > 1 {
+		a.Name = parts[1]
+	}
+	if len(parts) == 3 && a.Verb != "proxy" {
+		a.Resource = parts[0] + "/" + parts[2]
+	}
+	// Connecting to a resource, e.g. running a command in a pod, is as
+	// powerful as creating one, whatever the method of the request.
+	if _, ok := storage[a.Resource].(ResourceConnector); ok {
+		a.Verb = "create"
+	}
+	a.Namespace = requestNamespace(req)
+	if scoper, ok := storage[parts[0]].(Scoper); ok && !scoper.NamespaceScoped() {
+		a.Namespace = ""
+	}
+	return a, true
+}
+
+// methodVerb returns the verb of a request with the given method.
+func methodVerb(method string) string {
+	switch method {
+	case "GET":
+		return "get"
+	case "POST":
+		return "create"
+	case "PUT":
+		return "update"
+	case "DELETE":
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
+
+// nonResourceAttributes returns the attributes of a request which is not for
+// the API. Requests proxied to a minion are proxies of the minion, as they are
+// through the API; others are described by their path.
+func nonResourceAttributes(req *http.Request) AuthorizationAttributes {
+	if strings.HasPrefix(req.URL.Path, "/proxy/minion/") {
+		parts := splitPath(strings.TrimPrefix(req.URL.Path, "/proxy/minion/"))
+		if len(parts) > 0 {
+			return AuthorizationAttributes{Verb: "proxy", Resource: "minions", Name: parts[0]}
+		}
+	}
+	return AuthorizationAttributes{Verb: methodVerb(req.Method), Path: req.URL.Path}
+}
+
+// resourcePath returns the parts of the path of req which follow the version of
+// the API served at prefix, or false if req is not for a resource.
+func resourcePath(req *http.Request, prefix string) ([]string, bool) {
+	if !strings.HasPrefix(req.URL.Path, prefix+"/") {
+		return nil, false
+	}
+	parts := splitPath(strings.TrimPrefix(req.URL.Path, prefix+"/"))
+	if len(parts) < 2 {
+		return nil, false
+	}
+	return parts[1:], true
+}
+
+// Authorize wraps an http Handler so that requests are only served if authorizer
+// allows them. The requests for the versions and resources served at prefix, and
+// for the paths in public, are served as they are. Other requests outside the API,
+// such as those proxied to minions or for logs, are authorized by their path; see
+// nonResourceAttributes. Requests which are denied fail with 403 Forbidden. users
+// may be nil if requests are not authenticated.
+func Authorize(handler http.Handler, authorizer Authorizer, users RequestUsers, prefix string, storage map[string]RESTStorage, public util.StringSet, codec runtime.Codec) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		a, ok := requestAttributes(req, prefix, storage)
+		if !ok {
+			if req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/") || public.Has(req.URL.Path) {
+				handler.ServeHTTP(w, req)
+				return
+			}
+			a = nonResourceAttributes(req)
+		}
+		if users != nil {
+			a.User, _ = users.Get(req)
+		}
+		if err := authorizer.Authorize(a); err != nil {
+			if len(a.Path) > 0 {
+				errorJSON(apierrs.NewForbidden("path", a.Path, err), codec, w)
+				return
+			}
+			errorJSON(apierrs.NewForbidden(a.Resource, a.Name, err), codec, w)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
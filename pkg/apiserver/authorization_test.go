@@ -0,0 +1,159 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// clusterScopedStorage is storage for resources which are not in a namespace.
+type clusterScopedStorage struct {
+	SimpleRESTStorage
+}
+
+func (*clusterScopedStorage) NamespaceScoped() bool {
+	return false
+}
+
+func TestRequestAttributes(t *testing.T) {
+	storage := map[string]RESTStorage{
+		"foo":      &SimpleRESTStorage{},
+		"foo/exec": &ConnectingRESTStorage{},
+		"minions":  &clusterScopedStorage{},
+	}
+	table := []struct {
+		method, path string
+		expected     AuthorizationAttributes
+		ok           bool
+	}{
+		{"GET", "/api/v1beta1/foo", AuthorizationAttributes{Verb: "list", Resource: "foo", Namespace: api.NamespaceDefault}, true},
+		{"GET", "/api/v1beta1/foo?watch=true", AuthorizationAttributes{Verb: "watch", Resource: "foo", Namespace: api.NamespaceDefault}, true},
+		{"GET", "/api/v1beta1/watch/foo", AuthorizationAttributes{Verb: "watch", Resource: "foo", Namespace: api.NamespaceDefault}, true},
+		{"GET", "/api/v1beta1/foo/bar", AuthorizationAttributes{Verb: "get", Resource: "foo", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"GET", "/api/v1beta1/foo/bar/log", AuthorizationAttributes{Verb: "get", Resource: "foo/log", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"POST", "/api/v1beta1/foo", AuthorizationAttributes{Verb: "create", Resource: "foo", Namespace: api.NamespaceDefault}, true},
+		{"PUT", "/api/v1beta1/foo/bar", AuthorizationAttributes{Verb: "update", Resource: "foo", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"DELETE", "/api/v1beta1/foo/bar", AuthorizationAttributes{Verb: "delete", Resource: "foo", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"PATCH", "/api/v1beta1/foo/bar", AuthorizationAttributes{Verb: "patch", Resource: "foo", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"GET", "/api/v1beta1/foo/bar?namespace=other", AuthorizationAttributes{Verb: "get", Resource: "foo", Namespace: "other", Name: "bar"}, true},
+		{"GET", "/api/v1beta1/foo/bar/exec", AuthorizationAttributes{Verb: "create", Resource: "foo/exec", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"POST", "/api/v1beta1/foo/bar/exec", AuthorizationAttributes{Verb: "create", Resource: "foo/exec", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"GET", "/api/v1beta1/proxy/foo/bar/a/b", AuthorizationAttributes{Verb: "proxy", Resource: "foo", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"GET", "/api/v1beta1/redirect/foo/bar", AuthorizationAttributes{Verb: "get", Resource: "foo", Namespace: api.NamespaceDefault, Name: "bar"}, true},
+		{"DELETE", "/api/v1beta1/minions/m1", AuthorizationAttributes{Verb: "delete", Resource: "minions", Name: "m1"}, true},
+		{"GET", "/api/v1beta1", AuthorizationAttributes{}, false},
+		{"GET", "/api", AuthorizationAttributes{}, false},
+		{"GET", "/healthz", AuthorizationAttributes{}, false},
+	}
+	for _, item := range table {
+		req, err := http.NewRequest(item.method, item.path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		a, ok := requestAttributes(req, "/api", storage)
+		if ok != item.ok || a != item.expected {
+			t.Errorf("%s %s: expected %#v %v, got %#v %v", item.method, item.path, item.expected, item.ok, a, ok)
+		}
+	}
+}
+
+func TestNonResourceAttributes(t *testing.T) {
+	table := []struct {
+		method, path string
+		expected     AuthorizationAttributes
+	}{
+		{"GET", "/logs/", AuthorizationAttributes{Verb: "get", Path: "/logs/"}},
+		{"POST", "/metrics", AuthorizationAttributes{Verb: "create", Path: "/metrics"}},
+		{"GET", "/proxy/minion/m1/healthz", AuthorizationAttributes{Verb: "proxy", Resource: "minions", Name: "m1"}},
+		{"GET", "/proxy/minion/", AuthorizationAttributes{Verb: "get", Path: "/proxy/minion/"}},
+	}
+	for _, item := range table {
+		req, err := http.NewRequest(item.method, item.path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if a := nonResourceAttributes(req); a != item.expected {
+			t.Errorf("%s %s: expected %#v, got %#v", item.method, item.path, item.expected, a)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	storage := map[string]RESTStorage{"foo": &SimpleRESTStorage{}}
+	// alice may do anything, bob may only list.
+	authorizer := AuthorizerFunc(func(a AuthorizationAttributes) error {
+		if a.User == nil {
+			return errors.New("not authenticated")
+		}
+		if a.User.GetName() == "alice" || (a.User.GetName() == "bob" && a.Verb == "list") {
+			return nil
+		}
+		return errors.New("not allowed")
+	})
+	handler := Authorize(Handle(storage, codec, "/prefix/version", selfLinker), authorizer, headerRequestUsers{}, "/prefix", storage, util.NewStringSet("/version"), codec)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	table := []struct {
+		username, method, path string
+		expectedCode           int
+	}{
+		{"alice", "GET", "/prefix/version/foo", http.StatusOK},
+		{"alice", "GET", "/prefix/version/foo/bar", http.StatusOK},
+		{"bob", "GET", "/prefix/version/foo", http.StatusOK},
+		{"bob", "GET", "/prefix/version/foo/bar", http.StatusForbidden},
+		{"bob", "DELETE", "/prefix/version/foo/bar", http.StatusForbidden},
+		{"", "GET", "/prefix/version/foo", http.StatusForbidden},
+		{"", "GET", "/version", http.StatusOK},
+		{"alice", "GET", "/logs/", http.StatusNotFound},
+		{"bob", "GET", "/logs/", http.StatusForbidden},
+		{"", "GET", "/proxy/minion/m1/healthz", http.StatusForbidden},
+	}
+	for _, item := range table {
+		req, err := http.NewRequest(item.method, server.URL+item.path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.username != "" {
+			req.Header.Set("X-User", item.username)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.expectedCode == http.StatusForbidden {
+			var status api.Status
+			if _, err := extractBody(resp, &status); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status.Reason != api.StatusReasonForbidden {
+				t.Errorf("%s %s %s: unexpected status %#v", item.username, item.method, item.path, status)
+			}
+		} else {
+			resp.Body.Close()
+		}
+		if resp.StatusCode != item.expectedCode {
+			t.Errorf("%s %s %s: expected %d, got %d", item.username, item.method, item.path, item.expectedCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// serveConnection forwards a request to upgrade the connection to the resource
+// id, such as to a websocket or SPDY, to the location the connector returns, and
+// joins the two connections until either of them is closed.
+func serveConnection(ctx api.Context, connector ResourceConnector, id string, codec runtime.Codec, w http.ResponseWriter, req *http.Request) {
+	if len(req.Header.Get("Upgrade")) == 0 {
+		WriteErrorResponse(w, api.StatusReasonBadRequest, "the connection must be upgraded, for example to a websocket")
+		return
+	}
+	location, err := connector.ConnectLocation(ctx, id, req.URL.Query())
+	if err != nil {
+		errorJSON(err, codec, w)
+		return
+	}
+	loggedW := httplog.LogOf(req, w)
+	w = httplog.Unlogged(w)
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		loggedW.Addf("unable to get Hijacker")
+		notFound(w, req)
+		return
+	}
+	backend, err := net.Dial("tcp", location.Host)
+	if err != nil {
+		loggedW.Addf("unable to connect to %s: %v", location.Host, err)
+		badGatewayError(w, req)
+		return
+	}
+	defer backend.Close()
+
+	forwarded := *req
+	forwarded.URL = location
+	forwarded.Host = location.Host
+	forwarded.Body = nil
+	forwarded.ContentLength = 0
+	if err := forwarded.Write(backend); err != nil {
+		loggedW.Addf("unable to forward the request to %s: %v", location.Host, err)
+		badGatewayError(w, req)
+		return
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		loggedW.Addf("unable to hijack the connection: %v", err)
+		return
+	}
+	defer conn.Close()
+	// The joined connections may stay open for longer than the timeouts of the server.
+	conn.SetDeadline(time.Time{})
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backend, buffered)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, backend)
+		done <- struct{}{}
+	}()
+	<-done
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+// InstallVersions registers a handler at prefix which lists the versions of
+// the API served under it.
+func InstallVersions(mux mux, prefix string, versions ...string) {
+	list := api.APIVersions{Versions: versions}
+	mux.HandleFunc(strings.TrimRight(prefix, "/"), func(w http.ResponseWriter, req *http.Request) {
+		writeRawJSON(http.StatusOK, list, w)
+	})
+}
+
+// resourceList lists the resources of storage, served in version.
+func resourceList(version string, storage map[string]RESTStorage) *api.APIResourceList {
+	list := &api.APIResourceList{GroupVersion: version, Resources: []api.APIResource{}}
+	for name, s := range storage {
+		namespaced := true
+		if scoper, ok := s.(Scoper); ok {
+			namespaced = scoper.NamespaceScoped()
+		}
+		list.Resources = append(list.Resources, api.APIResource{
+			Name:       name,
+			Namespaced: namespaced,
+			Verbs:      resourceVerbs(name, s),
+		})
+	}
+	sort.Sort(byResourceName(list.Resources))
+	return list
+}
+
+// resourceVerbs returns the verbs RESTHandler serves for the storage registered
+// as name. Subresources can only be read and updated, unless their storage
+// serves them differently.
+func resourceVerbs(name string, storage RESTStorage) []string {
+	if strings.Contains(name, "/") {
+		switch storage.(type) {
+		case NamedCreater:
+			return []string{"create"}
+		case ResourceConnector, ResourceStreamer:
+			return []string{"get"}
+		}
+		return []string{"get", "update"}
+	}
+	verbs := []string{"get", "list"}
+	if _, ok := storage.(ResourceWatcher); ok {
+		verbs = append(verbs, "watch")
+	}
+	return append(verbs, "create", "update", "delete")
+}
+
+type byResourceName []api.APIResource
+
+func (s byResourceName) Len() int           { return len(s) }
+func (s byResourceName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byResourceName) Less(i, j int) bool { return s[i].Name < s[j].Name }
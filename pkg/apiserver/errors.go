@@ -17,7 +17,9 @@ limitations under the License.
 package apiserver
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
@@ -36,14 +38,21 @@ func errToAPIStatus(err error) *api.Status {
 	case statusError:
 		status := t.Status()
 		status.Status = api.StatusFailure
-		//TODO: check for invalid responses
+		if status.Code == 0 {
+			status.Code = statusCodeForReason(status.Reason)
+		}
 		return &status
 	default:
 		status := http.StatusInternalServerError
+		reason := api.StatusReasonUnknown
 		switch {
 		//TODO: replace me with NewConflictErr
 		case tools.IsEtcdTestFailed(err):
 			status = http.StatusConflict
+			reason = api.StatusReasonConflict
+		case isTimeout(err):
+			status = http.StatusGatewayTimeout
+			reason = api.StatusReasonTimeout
 		}
 		// Log errors that were not converted to an error status
 		// by REST storage - these typically indicate programmer
@@ -53,20 +62,77 @@ func errToAPIStatus(err error) *api.Status {
 		return &api.Status{
 			Status:  api.StatusFailure,
 			Code:    status,
-			Reason:  api.StatusReasonUnknown,
+			Reason:  reason,
 			Message: err.Error(),
 		}
 	}
 }
 
+// isTimeout returns true if err is a network operation which timed out, such
+// as a request to etcd or to a minion.
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
 // notFound renders a simple not found error.
 func notFound(w http.ResponseWriter, req *http.Request) {
-	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, "Not Found: %#v", req.RequestURI)
+	WriteErrorResponse(w, api.StatusReasonNotFound, fmt.Sprintf("Not Found: %#v", req.RequestURI))
 }
 
 // badGatewayError renders a simple bad gateway error.
 func badGatewayError(w http.ResponseWriter, req *http.Request) {
-	w.WriteHeader(http.StatusBadGateway)
-	fmt.Fprintf(w, "Bad Gateway: %#v", req.RequestURI)
+	writeErrorStatus(w, http.StatusBadGateway, api.StatusReasonUnknown, fmt.Sprintf("Bad Gateway: %#v", req.RequestURI))
+}
+
+// statusCodes is the HTTP status code each api.StatusReason is served with.
+var statusCodes = map[api.StatusReason]int{
+	api.StatusReasonUnknown:          http.StatusInternalServerError,
+	api.StatusReasonWorking:          http.StatusAccepted,
+	api.StatusReasonNotFound:         http.StatusNotFound,
+	api.StatusReasonAlreadyExists:    http.StatusConflict,
+	api.StatusReasonConflict:         http.StatusConflict,
+	api.StatusReasonInvalid:          StatusUnprocessableEntity,
+	api.StatusReasonBadRequest:       http.StatusBadRequest,
+	api.StatusReasonForbidden:        http.StatusForbidden,
+	api.StatusReasonGone:             http.StatusGone,
+	api.StatusReasonMethodNotAllowed: http.StatusMethodNotAllowed,
+	api.StatusReasonTooManyRequests:  StatusTooManyRequests,
+	api.StatusReasonInternalError:    http.StatusInternalServerError,
+	api.StatusReasonTimeout:          http.StatusGatewayTimeout,
+}
+
+// statusCodeForReason returns the HTTP status code for reason, or 500 if the
+// reason is not known.
+func statusCodeForReason(reason api.StatusReason) int {
+	if code, ok := statusCodes[reason]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteErrorResponse renders a failure api.Status with the given reason and message,
+// using the HTTP status code that corresponds to reason.
+func WriteErrorResponse(w http.ResponseWriter, reason api.StatusReason, message string) {
+	writeErrorStatus(w, statusCodeForReason(reason), reason, message)
+}
+
+// writeErrorStatus renders a failure api.Status with the given HTTP status code,
+// reason and message.
+func writeErrorStatus(w http.ResponseWriter, code int, reason api.StatusReason, message string) {
+	output, err := json.Marshal(&api.Status{
+		TypeMeta: api.TypeMeta{Kind: "Status"},
+		Status:   api.StatusFailure,
+		Code:     code,
+		Reason:   reason,
+		Message:  message,
+	})
+	if err != nil {
+		w.WriteHeader(code)
+		fmt.Fprint(w, message)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(output)
 }
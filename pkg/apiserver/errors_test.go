@@ -17,13 +17,16 @@ limitations under the License.
 package apiserver
 
 import (
+	"encoding/json"
 	stderrs "errors"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
 )
 
 func Test_errToAPIStatus(t *testing.T) {
@@ -56,6 +59,36 @@ func TestErrorsToAPIStatus(t *testing.T) {
 				ID:   "bar",
 			},
 		},
+		errors.NewNotFound("foo", "bar"): {
+			Status:  api.StatusFailure,
+			Code:    http.StatusNotFound,
+			Reason:  "NotFound",
+			Message: "foo \"bar\" not found",
+			Details: &api.StatusDetails{
+				Kind: "foo",
+				ID:   "bar",
+			},
+		},
+		errors.NewForbidden("foo", "bar", stderrs.New("failure")): {
+			Status:  api.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  "Forbidden",
+			Message: "foo \"bar\" is forbidden: failure",
+			Details: &api.StatusDetails{
+				Kind: "foo",
+				ID:   "bar",
+			},
+		},
+		errors.NewTimeout("foo", "bar", stderrs.New("failure")): {
+			Status:  api.StatusFailure,
+			Code:    http.StatusGatewayTimeout,
+			Reason:  "Timeout",
+			Message: "foo \"bar\" timed out: failure",
+			Details: &api.StatusDetails{
+				Kind: "foo",
+				ID:   "bar",
+			},
+		},
 	}
 	for k, v := range cases {
 		actual := errToAPIStatus(k)
@@ -64,3 +97,96 @@ func TestErrorsToAPIStatus(t *testing.T) {
 		}
 	}
 }
+
+// timeoutError is a net.Error which timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestUncheckedErrorsToAPIStatus(t *testing.T) {
+	table := []struct {
+		err    error
+		code   int
+		reason api.StatusReason
+	}{
+		{stderrs.New("failure"), http.StatusInternalServerError, api.StatusReasonUnknown},
+		{tools.EtcdErrorTestFailed, http.StatusConflict, api.StatusReasonConflict},
+		{timeoutError{}, http.StatusGatewayTimeout, api.StatusReasonTimeout},
+	}
+	for _, item := range table {
+		status := errToAPIStatus(item.err)
+		if status.Status != api.StatusFailure || status.Code != item.code || status.Reason != item.reason || status.Message != item.err.Error() {
+			t.Errorf("%v: unexpected status %#v", item.err, status)
+		}
+	}
+}
+
+// expectStatusBody checks that w holds a failure api.Status with the given code and reason.
+func expectStatusBody(t *testing.T, w *httptest.ResponseRecorder, code int, reason api.StatusReason) {
+	if w.Code != code {
+		t.Errorf("expected code %d, got %d", code, w.Code)
+	}
+	var status api.Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Status != api.StatusFailure || status.Code != code || status.Reason != reason || status.Message == "" {
+		t.Errorf("unexpected status %#v", status)
+	}
+}
+
+func TestNotFoundStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	notFound(w, &http.Request{RequestURI: "/foo"})
+	expectStatusBody(t, w, http.StatusNotFound, api.StatusReasonNotFound)
+}
+
+func TestBadGatewayError(t *testing.T) {
+	w := httptest.NewRecorder()
+	badGatewayError(w, &http.Request{RequestURI: "/foo"})
+	expectStatusBody(t, w, http.StatusBadGateway, api.StatusReasonUnknown)
+}
+
+func TestStatusCodeForReason(t *testing.T) {
+	table := map[api.StatusReason]int{
+		api.StatusReasonUnknown:          http.StatusInternalServerError,
+		api.StatusReasonNotFound:         http.StatusNotFound,
+		api.StatusReasonAlreadyExists:    http.StatusConflict,
+		api.StatusReasonInvalid:          StatusUnprocessableEntity,
+		api.StatusReasonBadRequest:       http.StatusBadRequest,
+		api.StatusReasonGone:             http.StatusGone,
+		api.StatusReasonMethodNotAllowed: http.StatusMethodNotAllowed,
+		api.StatusReasonTooManyRequests:  StatusTooManyRequests,
+		api.StatusReasonTimeout:          http.StatusGatewayTimeout,
+		"NoSuchReason":                   http.StatusInternalServerError,
+	}
+	for reason, expected := range table {
+		if actual := statusCodeForReason(reason); actual != expected {
+			t.Errorf("%q: expected %d, got %d", reason, expected, actual)
+		}
+	}
+}
+
+func TestWriteErrorResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteErrorResponse(w, api.StatusReasonBadRequest, "bad things")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unexpected code: %d", w.Code)
+	}
+	var status api.Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := api.Status{
+		TypeMeta: api.TypeMeta{Kind: "Status"},
+		Status:   api.StatusFailure,
+		Code:     http.StatusBadRequest,
+		Reason:   api.StatusReasonBadRequest,
+		Message:  "bad things",
+	}
+	if !reflect.DeepEqual(expected, status) {
+		t.Errorf("Expected %#v, Got %#v", expected, status)
+	}
+}
@@ -17,13 +17,17 @@ limitations under the License.
 package apiserver
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
 	"runtime/debug"
 	"strings"
+	"time"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/golang/glog"
 )
 
@@ -32,8 +36,7 @@ func RecoverPanics(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		defer func() {
 			if x := recover(); x != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				fmt.Fprint(w, "apis panic. Look in log for details.")
+				WriteErrorResponse(w, api.StatusReasonInternalError, "apis panic. Look in log for details.")
 				glog.Infof("APIServer panic'd on %v %v: %#v\n%s\n", req.Method, req.RequestURI, x, debug.Stack())
 			}
 		}()
@@ -54,6 +57,83 @@ func RecoverPanics(handler http.Handler) http.Handler {
 	})
 }
 
+// ReadOnly wraps an http Handler so that it only serves requests which read the API
+// served at prefix by storage, or the other paths of handler listed in paths. Requests
+// with a method other than GET fail with 405 Method Not Allowed. Requests which could
+// do more than read, because they upgrade their connection, connect to a resource, or
+// proxy or redirect to another server, fail with 403 Forbidden, as do requests for the
+// resources in hidden and their subresources, and for paths outside the API which are
+// not listed.
+func ReadOnly(handler http.Handler, prefix string, storage map[string]RESTStorage, hidden, paths util.StringSet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" {
+			w.Header().Set("Allow", "GET")
+			WriteErrorResponse(w, api.StatusReasonMethodNotAllowed, fmt.Sprintf("%s is not allowed on the read-only port", req.Method))
+			return
+		}
+		if req.Header.Get("Upgrade") != "" {
+			WriteErrorResponse(w, api.StatusReasonForbidden, "upgrading the connection is not allowed on the read-only port")
+			return
+		}
+		if req.URL.Path != prefix && !strings.HasPrefix(req.URL.Path, prefix+"/") && !paths.Has(req.URL.Path) {
+			WriteErrorResponse(w, api.StatusReasonForbidden, fmt.Sprintf("%s is not served on the read-only port", req.URL.Path))
+			return
+		}
+		if parts, ok := resourcePath(req, prefix); ok && (parts[0] == "proxy" || parts[0] == "redirect") {
+			WriteErrorResponse(w, api.StatusReasonForbidden, fmt.Sprintf("%s is not allowed on the read-only port", parts[0]))
+			return
+		}
+		if a, ok := requestAttributes(req, prefix, storage); ok {
+			if _, ok := storage[a.Resource].(ResourceConnector); ok {
+				WriteErrorResponse(w, api.StatusReasonForbidden, fmt.Sprintf("connecting to %s is not allowed on the read-only port", a.Resource))
+				return
+			}
+			if hidden.Has(strings.SplitN(a.Resource, "/", 2)[0]) {
+				WriteErrorResponse(w, api.StatusReasonForbidden, fmt.Sprintf("%s are not served on the read-only port", a.Resource))
+				return
+			}
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// TimeoutHandler wraps an http Handler so that requests which are not answered within timeout fail
+// with 504 Gateway Timeout, and their context is cancelled. Requests for which isLongRunning returns
+// true, such as watches, stream their response and are not limited by timeout; their context is
+// cancelled after longRunningTimeout instead, which ends watches and streams. If longRunningTimeout
+// is zero they are not limited at all.
+func TimeoutHandler(handler http.Handler, timeout, longRunningTimeout time.Duration, isLongRunning func(*http.Request) bool) http.Handler {
+	timeoutHandler := http.TimeoutHandler(handler, timeout, "The request timed out")
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !isLongRunning(req) {
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			timeoutHandler.ServeHTTP(&gatewayTimeoutWriter{w, ctx}, req.WithContext(ctx))
+			return
+		}
+		if longRunningTimeout > 0 {
+			ctx, cancel := context.WithTimeout(req.Context(), longRunningTimeout)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// gatewayTimeoutWriter answers the requests http.TimeoutHandler gives up on with
+// 504 Gateway Timeout rather than 503 Service Unavailable.
+type gatewayTimeoutWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+}
+
+func (w *gatewayTimeoutWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && w.ctx.Err() == context.DeadlineExceeded {
+		code = http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
 // Simple CORS implementation that wraps an http Handler
 // For a more detailed implementation use https://github.com/martini-contrib/cors
 // or implement CORS at your proxy layer
@@ -72,7 +152,7 @@ func CORS(handler http.Handler, allowedOriginPatterns []*regexp.Regexp, allowedM
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				// Set defaults for methods and headers if nothing was passed
 				if allowedMethods == nil {
-					allowedMethods = []string{"POST", "GET", "OPTIONS", "PUT", "DELETE"}
+					allowedMethods = []string{"POST", "GET", "OPTIONS", "PUT", "PATCH", "DELETE"}
 				}
 				if allowedHeaders == nil {
 					allowedHeaders = []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "X-Requested-With", "If-Modified-Since"}
@@ -83,7 +163,7 @@ func CORS(handler http.Handler, allowedOriginPatterns []*regexp.Regexp, allowedM
 
 				// Stop here if its a preflight OPTIONS request
 				if req.Method == "OPTIONS" {
-					w.WriteHeader(http.StatusNoContent)
+					w.WriteHeader(http.StatusOK)
 					return
 				}
 			}
@@ -0,0 +1,99 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	handler := RecoverPanics(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("failure")
+	}))
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler.ServeHTTP(w, req)
+	expectStatusBody(t, w, http.StatusInternalServerError, api.StatusReasonInternalError)
+}
+
+func TestReadOnly(t *testing.T) {
+	storage := map[string]RESTStorage{
+		"foo":         &SimpleRESTStorage{},
+		"foo/exec":    &ConnectingRESTStorage{},
+		"secrets":     &SimpleRESTStorage{},
+		"secrets/log": &SimpleRESTStorage{},
+	}
+	handler := ReadOnly(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "/api", storage, util.NewStringSet("secrets"), util.NewStringSet("/healthz"))
+	table := []struct {
+		method, path string
+		upgrade      bool
+		expected     int
+		reason       api.StatusReason
+	}{
+		{"GET", "/api/v1beta1/foo", false, http.StatusOK, ""},
+		{"GET", "/api/v1beta1/foo/bar", false, http.StatusOK, ""},
+		{"GET", "/api/v1beta1/watch/foo", false, http.StatusOK, ""},
+		{"GET", "/healthz", false, http.StatusOK, ""},
+		{"POST", "/api/v1beta1/foo", false, http.StatusMethodNotAllowed, api.StatusReasonMethodNotAllowed},
+		{"PUT", "/api/v1beta1/foo/bar", false, http.StatusMethodNotAllowed, api.StatusReasonMethodNotAllowed},
+		{"PATCH", "/api/v1beta1/foo/bar", false, http.StatusMethodNotAllowed, api.StatusReasonMethodNotAllowed},
+		{"DELETE", "/api/v1beta1/foo/bar", false, http.StatusMethodNotAllowed, api.StatusReasonMethodNotAllowed},
+		{"GET", "/api/v1beta1/foo/bar/exec", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/foo/bar", true, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/proxy/foo/bar/a", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/redirect/foo/bar", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/secrets", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/secrets/bar", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/watch/secrets", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/api/v1beta1/secrets/bar/log", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/proxy/minion/m1/", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/logs/", false, http.StatusForbidden, api.StatusReasonForbidden},
+		{"GET", "/healthz/ping", false, http.StatusForbidden, api.StatusReasonForbidden},
+	}
+	for _, item := range table {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(item.method, item.path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.upgrade {
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "SPDY/3.1")
+		}
+		handler.ServeHTTP(w, req)
+		if item.expected == http.StatusOK {
+			if w.Code != item.expected {
+				t.Errorf("%s %s: expected %d, got %d", item.method, item.path, item.expected, w.Code)
+			}
+			continue
+		}
+		expectStatusBody(t, w, item.expected, item.reason)
+		if allow := w.Header().Get("Allow"); item.expected == http.StatusMethodNotAllowed && allow != "GET" {
+			t.Errorf("%s %s: unexpected Allow header %q", item.method, item.path, allow)
+		}
+	}
+}
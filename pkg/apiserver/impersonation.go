@@ -0,0 +1,99 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// ImpersonateUserHeader names the user a request is to be served as, instead
+// of the user who made it.
+const ImpersonateUserHeader = "Impersonate-User"
+
+// impersonatorKey is the key of the user who made an impersonating request in
+// its context.
+type impersonatorKey struct{}
+
+// ImpersonatorFrom returns the user who made a request with the context ctx,
+// if the request is served as another user.
+func ImpersonatorFrom(ctx context.Context) (user.Info, bool) {
+	u, ok := ctx.Value(impersonatorKey{}).(user.Info)
+	return u, ok
+}
+
+// Impersonate wraps an http Handler so that a request which names a user in
+// ImpersonateUserHeader is served as that user, if authorizer allows the user
+// who made it to impersonate them: to "impersonate" the "users" of that name.
+// A nil authorizer allows every user to impersonate any other. Requests which
+// are denied, or whose user is not known to users, fail with 403 Forbidden.
+// The handler finds the impersonated user in the context of the request, with
+// handlers.UserFrom, and in its handlers.RemoteUserHeader.
+func Impersonate(handler http.Handler, authorizer Authorizer, users RequestUsers, codec runtime.Codec) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := req.Header.Get(ImpersonateUserHeader)
+		if len(name) == 0 {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		original, ok := users.Get(req)
+		if !ok {
+			errorJSON(apierrs.NewForbidden("users", name, fmt.Errorf("anonymous requests may not impersonate")), codec, w)
+			return
+		}
+		if authorizer != nil {
+			a := AuthorizationAttributes{User: original, Verb: "impersonate", Resource: "users", Name: name}
+			if err := authorizer.Authorize(a); err != nil {
+				errorJSON(apierrs.NewForbidden("users", name, err), codec, w)
+				return
+			}
+		}
+
+		impersonated := &user.DefaultInfo{Name: name}
+		req.Header.Del(ImpersonateUserHeader)
+		req.Header.Set(handlers.RemoteUserHeader, name)
+		ctx := context.WithValue(handlers.WithUser(req.Context(), impersonated), impersonatorKey{}, original)
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// impersonatedUsers finds the user of a request served by Impersonate in its
+// context, and the users of other requests in users.
+type impersonatedUsers struct {
+	users RequestUsers
+}
+
+func (u impersonatedUsers) Get(req *http.Request) (user.Info, bool) {
+	if _, ok := ImpersonatorFrom(req.Context()); ok {
+		return handlers.UserFrom(req.Context())
+	}
+	return u.users.Get(req)
+}
+
+// ImpersonatedUsers returns RequestUsers which look up the users of requests
+// served by Impersonate as the users they impersonate, and the users of other
+// requests in users, so that the handlers Impersonate wraps see the impersonated
+// users whichever way users finds them.
+func ImpersonatedUsers(users RequestUsers) RequestUsers {
+	return impersonatedUsers{users}
+}
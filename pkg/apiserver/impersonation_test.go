@@ -0,0 +1,114 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+)
+
+// noRequestUsers knows the user of no request.
+type noRequestUsers struct{}
+
+func (noRequestUsers) Get(req *http.Request) (user.Info, bool) {
+	return nil, false
+}
+
+func TestImpersonate(t *testing.T) {
+	// Only admin may impersonate, and only alice.
+	authorizer := AuthorizerFunc(func(a AuthorizationAttributes) error {
+		if a.User.GetName() == "admin" && a.Verb == "impersonate" && a.Resource == "users" && a.Name == "alice" {
+			return nil
+		}
+		return fmt.Errorf("denied")
+	})
+	table := []struct {
+		users        RequestUsers
+		impersonate  string
+		code         int
+		user         string
+		impersonator string
+	}{
+		{users: fakeRequestUsers{"bob"}, code: http.StatusOK, user: "bob"},
+		{users: fakeRequestUsers{"admin"}, impersonate: "alice", code: http.StatusOK, user: "alice", impersonator: "admin"},
+		{users: fakeRequestUsers{"admin"}, impersonate: "carol", code: http.StatusForbidden},
+		{users: fakeRequestUsers{"bob"}, impersonate: "alice", code: http.StatusForbidden},
+		{users: noRequestUsers{}, impersonate: "alice", code: http.StatusForbidden},
+	}
+	for i, item := range table {
+		var served *http.Request
+		handler := Impersonate(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			served = req
+		}), authorizer, item.users, codec)
+		req, _ := http.NewRequest("POST", "/api/v1beta1/pods", nil)
+		if len(item.impersonate) > 0 {
+			req.Header.Set(ImpersonateUserHeader, item.impersonate)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != item.code {
+			t.Errorf("%d: expected %d, got %d", i, item.code, w.Code)
+		}
+		if item.code != http.StatusOK {
+			if served != nil {
+				t.Errorf("%d: expected the request not to be served", i)
+			}
+			continue
+		}
+
+		u, ok := ImpersonatedUsers(item.users).Get(served)
+		if !ok || u.GetName() != item.user {
+			t.Errorf("%d: expected the request to be served as %q, got %#v", i, item.user, u)
+		}
+		impersonator, ok := ImpersonatorFrom(served.Context())
+		if len(item.impersonator) == 0 {
+			if ok {
+				t.Errorf("%d: unexpected impersonator %#v", i, impersonator)
+			}
+			continue
+		}
+		if !ok || impersonator.GetName() != item.impersonator {
+			t.Errorf("%d: expected the request to be made by %q, got %#v", i, item.impersonator, impersonator)
+		}
+		if u, ok := handlers.UserFrom(served.Context()); !ok || u.GetName() != item.user {
+			t.Errorf("%d: expected %q in the context of the request, got %#v", i, item.user, u)
+		}
+		if e, a := item.user, served.Header.Get(handlers.RemoteUserHeader); e != a {
+			t.Errorf("%d: expected %q in %s, got %q", i, e, handlers.RemoteUserHeader, a)
+		}
+		if served.Header.Get(ImpersonateUserHeader) != "" {
+			t.Errorf("%d: expected %s to be removed", i, ImpersonateUserHeader)
+		}
+	}
+}
+
+func TestAuditImpersonation(t *testing.T) {
+	log := &recordingAuditLog{}
+	users := fakeRequestUsers{"admin"}
+	handler := Impersonate(Audit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}), log, ImpersonatedUsers(users)), nil, users, codec)
+	req, _ := http.NewRequest("POST", "/api/v1beta1/pods", nil)
+	req.Header.Set(ImpersonateUserHeader, "alice")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if e, a := []string{"POST /api/v1beta1/pods OK alice by admin"}, log.records; len(a) != 1 || a[0] != e[0] {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
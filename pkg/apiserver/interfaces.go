@@ -17,6 +17,9 @@ limitations under the License.
 package apiserver
 
 import (
+	"io"
+	"net/url"
+
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
@@ -57,8 +60,64 @@ type ResourceWatcher interface {
 	Watch(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error)
 }
 
+// ResourcePager is implemented by RESTStorage objects which can list a page of
+// their resources without reading every resource, as List does.
+type ResourcePager interface {
+	// ListPage returns the items of the list List would return which fall in
+	// page, ordered by namespace and id, and whether more items follow them.
+	ListPage(ctx api.Context, label, field labels.Selector, page ListPage) (runtime.Object, bool, error)
+}
+
 // Redirector know how to return a remote resource's location.
 type Redirector interface {
 	// ResourceLocation should return the remote location of the given resource, or an error.
 	ResourceLocation(ctx api.Context, id string) (remoteLocation string, err error)
 }
+
+// ResourceStreamer should be implemented by RESTStorage objects whose resources
+// are served as a stream of bytes, such as the logs of a pod, rather than as an
+// object.
+type ResourceStreamer interface {
+	// ResourceStream returns the contents of the given resource, as selected by the
+	// query parameters of the request, and their content type. If flush is true the
+	// stream is sent to the client as it is read, until it ends or the client goes
+	// away. The caller closes the stream.
+	ResourceStream(ctx api.Context, id string, query url.Values) (stream io.ReadCloser, contentType string, flush bool, err error)
+}
+
+// ResourceConnector should be implemented by RESTStorage objects whose resources
+// are served as a connection to another server, such as a command running in
+// the container of a pod, rather than as an object.
+type ResourceConnector interface {
+	// ConnectLocation returns the location a request for the given resource, with
+	// the given query parameters, is forwarded to. The upgraded connection of the
+	// request is then joined to the connection to that location.
+	ConnectLocation(ctx api.Context, id string, query url.Values) (location *url.URL, err error)
+}
+
+// NamedCreater should be implemented by the RESTStorage objects of subresources
+// which are created by posting to /${resource}/${id}/${subresource}, such as the
+// eviction of a pod. Other subresources can only be read and updated.
+type NamedCreater interface {
+	// CreateNamed is Create for the object with the given id. Although it can
+	// return an arbitrary error value, IsNotFound(err) is true for the returned
+	// error value err when the object does not exist.
+	CreateNamed(ctx api.Context, id string, obj runtime.Object) (<-chan runtime.Object, error)
+}
+
+// Patcher may be implemented by RESTStorage objects which apply patches to
+// their resources themselves. A patch to the resources of other storage is
+// applied to the object returned by Get, and the result passed to Update.
+type Patcher interface {
+	// Patch applies patch, a strategic merge patch, to the given resource.
+	// Although it can return an arbitrary error value, IsNotFound(err) is true
+	// for the returned error value err when the resource is not found.
+	Patch(ctx api.Context, id string, patch []byte) (<-chan runtime.Object, error)
+}
+
+// Scoper may be implemented by RESTStorage objects to tell whether their
+// resources are in a namespace. The resources of storage which does not
+// implement it are taken to be.
+type Scoper interface {
+	NamespaceScoped() bool
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// ListPage selects a page of a list: at most Limit items, in the order of their
+// namespaces and ids, starting after the item with the given Namespace and ID. A
+// zero Limit selects every item; an empty ID starts at the first item.
+type ListPage struct {
+	Limit     int
+	Namespace string
+	ID        string
+}
+
+// parseListPage reads the limit and continue parameters of a list request.
+func parseListPage(limit, token string) (ListPage, error) {
+	page := ListPage{}
+	if len(limit) > 0 {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return page, apierrs.NewBadRequest(fmt.Sprintf("invalid limit %q", limit))
+		}
+		page.Limit = n
+	}
+	if len(token) > 0 {
+		cursor, err := base64.URLEncoding.DecodeString(token)
+		parts := strings.SplitN(string(cursor), "/", 2)
+		if err != nil || len(parts) != 2 || len(parts[1]) == 0 {
+			return page, apierrs.NewBadRequest(fmt.Sprintf("invalid continue token %q", token))
+		}
+		page.Namespace, page.ID = parts[0], parts[1]
+	}
+	return page, nil
+}
+
+// continueToken returns the token that lists the items after the item with the
+// given namespace and id.
+func continueToken(namespace, id string) string {
+	return base64.URLEncoding.EncodeToString([]byte(namespace + "/" + id))
+}
+
+// selects returns true if the page selects less than the whole list.
+func (page ListPage) selects() bool {
+	return page.Limit > 0 || len(page.ID) > 0
+}
+
+// Cursor returns the key the page starts after, relative to the keys of the
+// items listed in namespace: "namespace/id" when namespace is api.NamespaceAll
+// and the items are in namespaces, and the id otherwise. done is true if the
+// page starts after every item of namespace.
+func (page ListPage) Cursor(namespace string) (cursor string, done bool) {
+	switch {
+	case len(page.ID) == 0:
+		return "", false
+	case namespace == api.NamespaceAll && len(page.Namespace) > 0:
+		return page.Namespace + "/" + page.ID, false
+	case namespace == api.NamespaceAll || namespace == page.Namespace:
+		return page.ID, false
+	case page.Namespace < namespace:
+		return "", false
+	}
+	return "", true
+}
+
+// pageItem is an item of a list with the key it is ordered by.
+type pageItem struct {
+	namespace, id string
+	obj           runtime.Object
+}
+
+type byNamespaceAndID []pageItem
+
+func (s byNamespaceAndID) Len() int      { return len(s) }
+func (s byNamespaceAndID) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byNamespaceAndID) Less(i, j int) bool {
+	if s[i].namespace != s[j].namespace {
+		return s[i].namespace < s[j].namespace
+	}
+	return s[i].id < s[j].id
+}
+
+// after returns true if the item sorts after the cursor of page.
+func (page ListPage) after(item pageItem) bool {
+	if item.namespace != page.Namespace {
+		return item.namespace > page.Namespace
+	}
+	return item.id > page.ID
+}
+
+// Apply cuts the whole list down to the page, ordering its items by namespace
+// and id so that the cursor stays valid while items are added and removed, and
+// returns true if items remain after the page. It is how the pages of storage
+// which is not a ResourcePager are served.
+func (page ListPage) Apply(list runtime.Object) (bool, error) {
+	if !page.selects() {
+		return false, nil
+	}
+	objs, err := runtime.ExtractList(list)
+	if err != nil {
+		return false, err
+	}
+	items := make([]pageItem, 0, len(objs))
+	for _, obj := range objs {
+		meta, err := api.TypeMetaFor(obj)
+		if err != nil {
+			return false, err
+		}
+		items = append(items, pageItem{meta.Namespace, meta.ID, obj})
+	}
+	sort.Sort(byNamespaceAndID(items))
+	if len(page.ID) > 0 {
+		start := sort.Search(len(items), func(i int) bool { return page.after(items[i]) })
+		items = items[start:]
+	}
+	more := page.Limit > 0 && len(items) > page.Limit
+	if more {
+		items = items[:page.Limit]
+	}
+	objs = make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		objs = append(objs, item.obj)
+	}
+	return more, runtime.SetList(list, objs)
+}
+
+// setContinue sets the Continue of a page of a list to fetch the items after
+// its last item if more is true, and clears it otherwise.
+func setContinue(list runtime.Object, more bool) error {
+	listMeta, err := api.TypeMetaFor(list)
+	if err != nil {
+		return err
+	}
+	listMeta.Continue = ""
+	if !more {
+		return nil
+	}
+	objs, err := runtime.ExtractList(list)
+	if err != nil || len(objs) == 0 {
+		return err
+	}
+	last, err := api.TypeMetaFor(objs[len(objs)-1])
+	if err != nil {
+		return err
+	}
+	listMeta.Continue = continueToken(last.Namespace, last.ID)
+	return nil
+}
+
+// listPage lists the page of the resources of storage, through ListPage if
+// storage is a ResourcePager, so that only the items of the page are read.
+func listPage(ctx api.Context, storage RESTStorage, label, field labels.Selector, page ListPage) (runtime.Object, error) {
+	if pager, ok := storage.(ResourcePager); ok && page.selects() {
+		list, more, err := pager.ListPage(ctx, label, field, page)
+		if err != nil {
+			return nil, err
+		}
+		return list, setContinue(list, more)
+	}
+	list, err := storage.List(ctx, label, field)
+	if err != nil {
+		return nil, err
+	}
+	more, err := page.Apply(list)
+	if err != nil {
+		return nil, err
+	}
+	return list, setContinue(list, more)
+}
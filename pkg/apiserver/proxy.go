@@ -26,7 +26,7 @@ import (
 	"path"
 	"strings"
 
-	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
@@ -77,7 +77,11 @@ type ProxyHandler struct {
 }
 
 func (r *ProxyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	ctx := api.NewContext()
+	ctx, err := requestContext(req, false)
+	if err != nil {
+		errorJSON(err, r.codec, w)
+		return
+	}
 	parts := strings.SplitN(req.URL.Path, "/", 3)
 	if len(parts) < 2 {
 		notFound(w, req)
@@ -122,7 +126,7 @@ func (r *ProxyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		glog.Errorf("Failed to create request: %s", err)
 	}
-	newReq.Header = req.Header
+	newReq.Header = proxyHeader(req)
 
 	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: destURL.Host})
 	proxy.Transport = &proxyTransport{
@@ -133,6 +137,23 @@ func (r *ProxyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	proxy.ServeHTTP(w, newReq)
 }
 
+// proxyHeader returns the headers of req to send on to the server it is proxied
+// to. The client's credentials are removed, so that the server cannot reuse
+// them, and handlers.RemoteUserHeader names the user req was authenticated as,
+// if any, rather than whatever the client sent.
+func proxyHeader(req *http.Request) http.Header {
+	header := http.Header{}
+	for key, values := range req.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	header.Del("Authorization")
+	header.Del(handlers.RemoteUserHeader)
+	if user, ok := handlers.UserFrom(req.Context()); ok {
+		header.Set(handlers.RemoteUserHeader, user.GetName())
+	}
+	return header
+}
+
 type proxyTransport struct {
 	proxyScheme      string
 	proxyHost        string
@@ -26,6 +26,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/authenticator"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+
 	"code.google.com/p/go.net/html"
 )
 
@@ -188,3 +192,47 @@ func TestProxy(t *testing.T) {
 		}
 	}
 }
+
+func TestProxyHeader(t *testing.T) {
+	var header http.Header
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header = proxyHeader(req)
+	})
+	authenticated := handlers.NewRequestAuthenticator(
+		handlers.NewUserRequestContext(),
+		authenticator.RequestFunc(func(req *http.Request) (user.Info, bool, error) {
+			return &user.DefaultInfo{Name: "alice"}, true, nil
+		}),
+		handlers.Unauthorized,
+		handler,
+	)
+	table := []struct {
+		handler  http.Handler
+		expected string
+	}{
+		{handler, ""},
+		{authenticated, "alice"},
+	}
+	for _, item := range table {
+		req, err := http.NewRequest("GET", "/prefix/version/proxy/foo/id", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set(handlers.RemoteUserHeader, "mallory")
+		req.Header.Set("Accept", "text/html")
+		item.handler.ServeHTTP(httptest.NewRecorder(), req)
+		if auth := header.Get("Authorization"); auth != "" {
+			t.Errorf("expected the credentials of the client to be removed, got %q", auth)
+		}
+		if name := header.Get(handlers.RemoteUserHeader); name != item.expected {
+			t.Errorf("expected %s to be %q, got %q", handlers.RemoteUserHeader, item.expected, name)
+		}
+		if accept := header.Get("Accept"); accept != "text/html" {
+			t.Errorf("expected other headers to be kept, got %#v", header)
+		}
+		if req.Header.Get("Authorization") == "" {
+			t.Errorf("expected the headers of the request to be left alone")
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// rateLimitRetryAfterSeconds is how long throttled clients are asked to wait
+// before they retry.
+const rateLimitRetryAfterSeconds = 1
+
+// RateLimiter decides whether the requests of a client are served.
+type RateLimiter interface {
+	// Accept returns true if a request of the client clientID may be served now.
+	Accept(clientID string) bool
+}
+
+// tokenBucket holds the tokens of a client as of the last time it was used.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type tokenBucketRateLimiter struct {
+	qps   float64
+	burst float64
+	now   func() time.Time
+
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+	// lastPrune is when buckets were last rid of the buckets which are full.
+	lastPrune time.Time
+}
+
+// NewTokenBucketRateLimiter returns a RateLimiter which gives every client a bucket of burst
+// tokens, refilled at qps tokens per second. Every accepted request takes a token, so a client
+// may exceed qps in bursts of up to burst requests while its rate is held to qps over time.
+func NewTokenBucketRateLimiter(qps float64, burst int) RateLimiter {
+	if burst < 1 {
+		panic("burst must be a positive integer")
+	}
+	return &tokenBucketRateLimiter{
+		qps:     qps,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+func (r *tokenBucketRateLimiter) Accept(clientID string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	r.prune(now)
+	bucket, ok := r.buckets[clientID]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[clientID] = bucket
+	}
+	bucket.tokens = r.refill(bucket, now)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// refill returns the tokens in bucket at now.
+func (r *tokenBucketRateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	tokens := bucket.tokens + now.Sub(bucket.last).Seconds()*r.qps
+	if tokens > r.burst {
+		return r.burst
+	}
+	return tokens
+}
+
+// prune forgets the clients whose bucket has filled up again, which are no different
+// from clients which were never seen. It does so at most once per time the bucket
+// takes to fill.
+func (r *tokenBucketRateLimiter) prune(now time.Time) {
+	if now.Sub(r.lastPrune).Seconds()*r.qps < r.burst {
+		return
+	}
+	for clientID, bucket := range r.buckets {
+		if r.refill(bucket, now) == r.burst {
+			delete(r.buckets, clientID)
+		}
+	}
+	r.lastPrune = now
+}
+
+// RateLimit wraps an http Handler so that the requests of each client are only served while
+// limiter accepts them. Clients are told apart by the user they are authenticated as, or else by
+// their address. Requests which are not accepted fail with 429 Too Many Requests.
+func RateLimit(handler http.Handler, limiter RateLimiter, users RequestUsers, codec runtime.Codec) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		clientID := ""
+		if users != nil {
+			if u, ok := users.Get(req); ok {
+				clientID = "user:" + u.GetName()
+			}
+		}
+		if clientID == "" {
+			ip, _, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil {
+				ip = req.RemoteAddr
+			}
+			clientID = "ip:" + ip
+		}
+		if !limiter.Accept(clientID) {
+			errorJSON(apierrs.NewTooManyRequests("Too many requests, please try again later.", rateLimitRetryAfterSeconds), codec, w)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
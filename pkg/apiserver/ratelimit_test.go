@@ -0,0 +1,130 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+)
+
+// headerRequestUsers authenticates requests as the user named by their X-User header.
+type headerRequestUsers struct{}
+
+func (headerRequestUsers) Get(req *http.Request) (user.Info, bool) {
+	name := req.Header.Get("X-User")
+	if name == "" {
+		return nil, false
+	}
+	return &user.DefaultInfo{Name: name}, true
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := NewTokenBucketRateLimiter(2, 3).(*tokenBucketRateLimiter)
+	limiter.now = func() time.Time { return now }
+
+	accepted := func(clientID string, requests int) int {
+		count := 0
+		for i := 0; i < requests; i++ {
+			if limiter.Accept(clientID) {
+				count++
+			}
+		}
+		return count
+	}
+	if n := accepted("a", 5); n != 3 {
+		t.Errorf("expected a burst of 3 to be accepted, got %d", n)
+	}
+	if n := accepted("b", 1); n != 1 {
+		t.Errorf("expected another client not to be limited, got %d", n)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if n := accepted("a", 5); n != 1 {
+		t.Errorf("expected 1 token to be refilled, got %d", n)
+	}
+	now = now.Add(time.Minute)
+	if n := accepted("a", 5); n != 3 {
+		t.Errorf("expected the bucket to be refilled up to the burst, got %d", n)
+	}
+	if _, ok := limiter.buckets["b"]; ok {
+		t.Errorf("expected the full bucket of b to be forgotten")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(Handle(map[string]RESTStorage{
+		"foo": &SimpleRESTStorage{},
+	}, codec, "/prefix/version", selfLinker), NewTokenBucketRateLimiter(0.001, 10), headerRequestUsers{}, codec)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(username string) *http.Response {
+		req, err := http.NewRequest("GET", server.URL+"/prefix/version/foo", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if username != "" {
+			req.Header.Set("X-User", username)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp
+	}
+
+	served, throttled := 0, 0
+	for i := 0; i < 100; i++ {
+		resp := get("alice")
+		switch resp.StatusCode {
+		case http.StatusOK:
+			served++
+			resp.Body.Close()
+		case StatusTooManyRequests:
+			throttled++
+			var status api.Status
+			if _, err := extractBody(resp, &status); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status.Reason != api.StatusReasonTooManyRequests {
+				t.Errorf("unexpected status %#v", status)
+			}
+			if e, a := "1", resp.Header.Get("Retry-After"); e != a {
+				t.Errorf("expected Retry-After %q, got %q", e, a)
+			}
+		default:
+			resp.Body.Close()
+			t.Fatalf("unexpected status %d", resp.StatusCode)
+		}
+	}
+	if served != 10 || throttled != 90 {
+		t.Errorf("expected 10 requests to be served and 90 throttled, got %d and %d", served, throttled)
+	}
+
+	for _, username := range []string{"bob", ""} {
+		resp := get(username)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%q: expected other clients not to be throttled, got %d", username, resp.StatusCode)
+		}
+	}
+}
@@ -17,18 +17,28 @@ limitations under the License.
 package apiserver
 
 import (
+	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 	"path"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrs "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/strategicpatch"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 
 	"github.com/golang/glog"
 )
 
+// StrategicMergePatchType is the content type of the strategic merge patches
+// which PATCH requests apply.
+const StrategicMergePatchType = "application/strategic-merge-patch+json"
+
 type RESTHandler struct {
 	storage         map[string]RESTStorage
 	codec           runtime.Codec
@@ -45,6 +55,17 @@ func (h *RESTHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		notFound(w, req)
 		return
 	}
+	if len(parts) == 3 {
+		// A subresource of an object is served at /${resource}/${id}/${subresource}
+		// by the storage registered as "${resource}/${subresource}", which can
+		// only be asked to get or update the object, or to create it if the
+		// storage is a NamedCreater.
+		if req.Method != "GET" && req.Method != "PUT" && req.Method != "POST" {
+			notFound(w, req)
+			return
+		}
+		parts = []string{parts[0] + "/" + parts[2], parts[1]}
+	}
 	storage := h.storage[parts[0]]
 	if storage == nil {
 		httplog.LogOf(req, w).Addf("'%v' has no storage object", parts[0])
@@ -52,14 +73,29 @@ func (h *RESTHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	h.handleRESTStorage(parts, req, w, storage)
+	start := time.Now()
+	recorder := &statusRecorder{w, http.StatusOK}
+	h.handleRESTStorage(parts, req, recorder, storage)
+	metrics.Monitor(requestVerb(req, parts), parts[0], recorder.status, time.Since(start))
+}
+
+// requestVerb returns the verb a request for parts is counted under in metrics,
+// which tells lists and watches apart from gets.
+func requestVerb(req *http.Request, parts []string) string {
+	if req.Method == "GET" && len(parts) == 1 {
+		if req.URL.Query().Get("watch") == "true" {
+			return "WATCH"
+		}
+		return "LIST"
+	}
+	return req.Method
 }
 
 // Sets the SelfLink field of the object.
 func (h *RESTHandler) setSelfLink(obj runtime.Object, req *http.Request) error {
 	newURL := *req.URL
 	newURL.Path = path.Join(h.canonicalPrefix, req.URL.Path)
-	newURL.RawQuery = ""
+	newURL.RawQuery = selfLinkQuery(req)
 	newURL.Fragment = ""
 	return h.selfLinker.SetSelfLink(obj, newURL.String())
 }
@@ -72,11 +108,21 @@ func (h *RESTHandler) setSelfLinkAddID(obj runtime.Object, req *http.Request) er
 	}
 	newURL := *req.URL
 	newURL.Path = path.Join(h.canonicalPrefix, req.URL.Path, id)
-	newURL.RawQuery = ""
+	newURL.RawQuery = selfLinkQuery(req)
 	newURL.Fragment = ""
 	return h.selfLinker.SetSelfLink(obj, newURL.String())
 }
 
+// selfLinkQuery returns the query of a self link for req, which keeps only the
+// namespace the request was made in.
+func selfLinkQuery(req *http.Request) string {
+	namespace := req.URL.Query().Get("namespace")
+	if len(namespace) == 0 {
+		return ""
+	}
+	return url.Values{"namespace": []string{namespace}}.Encode()
+}
+
 // curry adapts either of the self link setting functions into a function appropriate for operation's hook.
 func curry(f func(runtime.Object, *http.Request) error, req *http.Request) func(runtime.Object) {
 	return func(obj runtime.Object) {
@@ -90,24 +136,66 @@ func curry(f func(runtime.Object, *http.Request) error, req *http.Request) func(
 // on path length, according to the following table:
 //   Method     Path          Action
 //   GET        /foo          list
-//   GET        /foo/bar      get 'bar'
+//   GET        /foo/bar      get 'bar', or stream it if the storage is a ResourceStreamer, or
+//                            connect to it if the storage is a ResourceConnector
 //   POST       /foo          create
 //   PUT        /foo/bar      update 'bar'
+//   PATCH      /foo/bar      apply a strategic merge patch to 'bar'
 //   DELETE     /foo/bar      delete 'bar'
+//   GET        /foo/bar/baz  get 'bar' from the storage registered as "foo/baz"
+//   PUT        /foo/bar/baz  update 'bar' through the storage registered as "foo/baz"
+//   POST       /foo/bar/baz  create 'bar' through the storage registered as "foo/baz", if it
+//                            is a NamedCreater
 // Returns 404 if the method/pattern doesn't match one of these entries
 // The s accepts several query parameters:
 //    sync=[false|true] Synchronous request (only applies to create, update, delete operations)
 //    timeout=<duration> Timeout for synchronous requests, only applies if sync=true
 //    labels=<label-selector> Used for filtering list operations
+//    fields=<field-selector> Used for filtering list operations
+//    watch=[false|true] Watch the list instead of returning it, if the storage is a ResourceWatcher
+//    limit=<count> The most items a list operation returns; the list's continue is set if there are more
+//    continue=<token> The continue of a previous list operation, to list the items after it
+//    resourceVersionMatch=[Exact|NotOlderThan] How the resource version of an updated object is
+//                         matched with that of the stored object (only applies to update, patch operations)
+//    propagationPolicy=[Foreground|Background|Orphan] What happens to the dependents of a deleted object
+//                         (only applies to delete operations)
+//    namespace=<namespace> The namespace of the objects, the default namespace if not given, or
+//                         all namespaces if "*" (only applies to list and watch operations)
 func (h *RESTHandler) handleRESTStorage(parts []string, req *http.Request, w http.ResponseWriter, storage RESTStorage) {
-	// TODO for now, we perform all operations in the default namespace
-	ctx := api.NewDefaultContext()
+	ctx, err := requestContext(req, req.Method == "GET" && len(parts) == 1)
+	if err != nil {
+		errorJSON(err, h.codec, w)
+		return
+	}
 	sync := req.URL.Query().Get("sync") == "true"
 	timeout := parseTimeout(req.URL.Query().Get("timeout"))
+	if match := api.ResourceVersionMatch(req.URL.Query().Get("resourceVersionMatch")); match != "" {
+		if match != api.ResourceVersionMatchExact && match != api.ResourceVersionMatchNotOlderThan {
+			errorJSON(apierrs.NewBadRequest(fmt.Sprintf("unsupported resourceVersionMatch %q", match)), h.codec, w)
+			return
+		}
+		ctx = api.WithResourceVersionMatch(ctx, match)
+	}
+	if propagation := api.DeletionPropagation(req.URL.Query().Get("propagationPolicy")); propagation != "" {
+		if propagation != api.DeletePropagationForeground && propagation != api.DeletePropagationBackground && propagation != api.DeletePropagationOrphan {
+			errorJSON(apierrs.NewBadRequest(fmt.Sprintf("unsupported propagationPolicy %q", propagation)), h.codec, w)
+			return
+		}
+		ctx = api.WithDeletionPropagation(ctx, propagation)
+	}
 	switch req.Method {
 	case "GET":
 		switch len(parts) {
 		case 1:
+			if req.URL.Query().Get("watch") == "true" {
+				watcher, ok := storage.(ResourceWatcher)
+				if !ok {
+					notFound(w, req)
+					return
+				}
+				serveWatch(ctx, watcher, h.codec, w, req)
+				return
+			}
 			label, err := labels.ParseSelector(req.URL.Query().Get("labels"))
 			if err != nil {
 				errorJSON(err, h.codec, w)
@@ -118,7 +206,12 @@ func (h *RESTHandler) handleRESTStorage(parts []string, req *http.Request, w htt
 				errorJSON(err, h.codec, w)
 				return
 			}
-			list, err := storage.List(ctx, label, field)
+			page, err := parseListPage(req.URL.Query().Get("limit"), req.URL.Query().Get("continue"))
+			if err != nil {
+				errorJSON(err, h.codec, w)
+				return
+			}
+			list, err := listPage(ctx, storage, label, field, page)
 			if err != nil {
 				errorJSON(err, h.codec, w)
 				return
@@ -129,6 +222,14 @@ func (h *RESTHandler) handleRESTStorage(parts []string, req *http.Request, w htt
 			}
 			writeJSON(http.StatusOK, h.codec, list, w)
 		case 2:
+			if connector, ok := storage.(ResourceConnector); ok {
+				serveConnection(ctx, connector, parts[1], h.codec, w, req)
+				return
+			}
+			if streamer, ok := storage.(ResourceStreamer); ok {
+				serveStream(ctx, streamer, parts[1], h.codec, w, req)
+				return
+			}
 			item, err := storage.Get(ctx, parts[1])
 			if err != nil {
 				errorJSON(err, h.codec, w)
@@ -144,7 +245,16 @@ func (h *RESTHandler) handleRESTStorage(parts []string, req *http.Request, w htt
 		}
 
 	case "POST":
-		if len(parts) != 1 {
+		var creater NamedCreater
+		switch len(parts) {
+		case 1:
+		case 2:
+			var ok bool
+			if creater, ok = storage.(NamedCreater); !ok {
+				notFound(w, req)
+				return
+			}
+		default:
 			notFound(w, req)
 			return
 		}
@@ -159,6 +269,16 @@ func (h *RESTHandler) handleRESTStorage(parts []string, req *http.Request, w htt
 			errorJSON(err, h.codec, w)
 			return
 		}
+		if creater != nil {
+			out, err := creater.CreateNamed(ctx, parts[1], obj)
+			if err != nil {
+				errorJSON(err, h.codec, w)
+				return
+			}
+			op := h.createOperation(out, sync, timeout, curry(h.setSelfLink, req))
+			h.finishReq(op, req, w)
+			return
+		}
 		out, err := storage.Create(ctx, obj)
 		if err != nil {
 			errorJSON(err, h.codec, w)
@@ -204,6 +324,32 @@ func (h *RESTHandler) handleRESTStorage(parts []string, req *http.Request, w htt
 		op := h.createOperation(out, sync, timeout, curry(h.setSelfLink, req))
 		h.finishReq(op, req, w)
 
+	case "PATCH":
+		if len(parts) != 2 {
+			notFound(w, req)
+			return
+		}
+		if contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); contentType != StrategicMergePatchType {
+			errorJSON(apierrs.NewBadRequest(fmt.Sprintf("the body of a patch must be of type %s", StrategicMergePatchType)), h.codec, w)
+			return
+		}
+		body, err := readBody(req)
+		if err != nil {
+			errorJSON(err, h.codec, w)
+			return
+		}
+		patcher, ok := storage.(Patcher)
+		if !ok {
+			patcher = &updatePatcher{storage, h.codec}
+		}
+		out, err := patcher.Patch(ctx, parts[1], body)
+		if err != nil {
+			errorJSON(err, h.codec, w)
+			return
+		}
+		op := h.createOperation(out, sync, timeout, curry(h.setSelfLink, req))
+		h.finishReq(op, req, w)
+
 	default:
 		notFound(w, req)
 	}
@@ -237,3 +383,24 @@ func (h *RESTHandler) finishReq(op *Operation, req *http.Request, w http.Respons
 		writeJSON(http.StatusAccepted, h.codec, obj, w)
 	}
 }
+
+// updatePatcher patches the resources of storage which is not a Patcher by
+// updating them with the patch applied to their current state. The update
+// carries the resource version of that state, so it fails with a conflict
+// rather than undoing a concurrent change if the storage checks versions.
+type updatePatcher struct {
+	storage RESTStorage
+	codec   runtime.Codec
+}
+
+func (p *updatePatcher) Patch(ctx api.Context, id string, patch []byte) (<-chan runtime.Object, error) {
+	original, err := p.storage.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := strategicpatch.Merge(p.codec, original, patch)
+	if err != nil {
+		return nil, apierrs.NewBadRequest(err.Error())
+	}
+	return p.storage.Update(ctx, obj)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// serveStream copies the stream of the resource id to the client. A stream which
+// should be flushed is sent with Transfer-Encoding: chunked, a chunk per read.
+func serveStream(ctx api.Context, streamer ResourceStreamer, id string, codec runtime.Codec, w http.ResponseWriter, req *http.Request) {
+	stream, contentType, flush, err := streamer.ResourceStream(ctx, id, req.URL.Query())
+	if err != nil {
+		errorJSON(err, codec, w)
+		return
+	}
+	defer stream.Close()
+
+	loggedW := httplog.LogOf(req, w)
+	w = httplog.Unlogged(w)
+	w.Header().Set("Content-Type", contentType)
+	flusher, ok := w.(http.Flusher)
+	if !flush || !ok {
+		if flush {
+			loggedW.Addf("unable to get Flusher")
+		}
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, stream)
+		return
+	}
+
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	var closed <-chan bool
+	if cn, ok := w.(http.CloseNotifier); ok {
+		closed = cn.CloseNotify()
+	}
+	// Unblock the read below when the client goes away or the request times out.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-closed:
+			stream.Close()
+		case <-req.Context().Done():
+			stream.Close()
+		case <-done:
+		}
+	}()
+	buf := make([]byte, 4096)
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
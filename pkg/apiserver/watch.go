@@ -59,7 +59,11 @@ func isWebsocketRequest(req *http.Request) bool {
 
 // ServeHTTP processes watch requests.
 func (h *WatchHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	ctx := api.NewContext()
+	ctx, err := requestContext(req, true)
+	if err != nil {
+		errorJSON(err, h.codec, w)
+		return
+	}
 	parts := splitPath(req.URL.Path)
 	if len(parts) < 1 || req.Method != "GET" {
 		notFound(w, req)
@@ -71,27 +75,33 @@ func (h *WatchHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	if watcher, ok := storage.(ResourceWatcher); ok {
-		label, field, resourceVersion := getWatchParams(req.URL.Query())
-		watching, err := watcher.Watch(ctx, label, field, resourceVersion)
-		if err != nil {
-			errorJSON(err, h.codec, w)
-			return
-		}
-
-		// TODO: This is one watch per connection. We want to multiplex, so that
-		// multiple watches of the same thing don't create two watches downstream.
-		watchServer := &WatchServer{watching, h.codec}
-		if isWebsocketRequest(req) {
-			websocket.Handler(watchServer.HandleWS).ServeHTTP(httplog.Unlogged(w), req)
-		} else {
-			watchServer.ServeHTTP(w, req)
-		}
+		serveWatch(ctx, watcher, h.codec, w, req)
 		return
 	}
 
 	notFound(w, req)
 }
 
+// serveWatch starts a watch on watcher with the parameters of req, and streams
+// its events over a websocket or vanilla HTTP.
+func serveWatch(ctx api.Context, watcher ResourceWatcher, codec runtime.Codec, w http.ResponseWriter, req *http.Request) {
+	label, field, resourceVersion := getWatchParams(req.URL.Query())
+	watching, err := watcher.Watch(ctx, label, field, resourceVersion)
+	if err != nil {
+		errorJSON(err, codec, w)
+		return
+	}
+
+	// TODO: This is one watch per connection. We want to multiplex, so that
+	// multiple watches of the same thing don't create two watches downstream.
+	watchServer := &WatchServer{watching, codec}
+	if isWebsocketRequest(req) {
+		websocket.Handler(watchServer.HandleWS).ServeHTTP(httplog.Unlogged(w), req)
+	} else {
+		watchServer.ServeHTTP(w, req)
+	}
+}
+
 // WatchServer serves a watch.Interface over a websocket or vanilla HTTP.
 type WatchServer struct {
 	watching watch.Interface
@@ -113,6 +123,9 @@ func (w *WatchServer) HandleWS(ws *websocket.Conn) {
 		case <-done:
 			w.watching.Stop()
 			return
+		case <-ws.Request().Context().Done():
+			w.watching.Stop()
+			return
 		case event, ok := <-w.watching.ResultChan():
 			if !ok {
 				// End of results.
@@ -162,6 +175,9 @@ func (self *WatchServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		case <-cn.CloseNotify():
 			self.watching.Stop()
 			return
+		case <-req.Context().Done():
+			self.watching.Stop()
+			return
 		case event, ok := <-self.watching.ResultChan():
 			if !ok {
 				// End of results.
@@ -144,6 +144,77 @@ func TestWatchHTTP(t *testing.T) {
 	}
 }
 
+func TestWatchHTTPQueryParam(t *testing.T) {
+	simpleStorage := &SimpleRESTStorage{}
+	handler := Handle(map[string]RESTStorage{
+		"foo": simpleStorage,
+	}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	dest, _ := url.Parse(server.URL)
+	dest.Path = "/prefix/version/foo"
+	dest.RawQuery = "watch=true&labels=name%3Dfoo&resourceVersion=1234"
+
+	response, err := http.Get(dest.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected response %#v", response)
+	}
+	if e, a := "name=foo", simpleStorage.requestedLabelSelector.String(); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := "1234", simpleStorage.requestedResourceVersion; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	for i, item := range watchTestTable {
+		simpleStorage.fakeWatch.Action(item.t, item.obj)
+		var got watchJSON
+		if err := decoder.Decode(&got); err != nil {
+			t.Fatalf("%d: Unexpected error: %v", i, err)
+		}
+		if got.Type != item.t {
+			t.Errorf("%d: Unexpected type: %v", i, got.Type)
+		}
+		if e, a := runtime.EncodeOrDie(codec, item.obj), string(got.Object); !reflect.DeepEqual(e, a) {
+			t.Errorf("Expected %#v, got %#v", e, a)
+		}
+	}
+	simpleStorage.fakeWatch.Stop()
+
+	var got watchJSON
+	if err := decoder.Decode(&got); err == nil {
+		t.Errorf("Unexpected non-error")
+	}
+}
+
+// nonWatchableRESTStorage hides the Watch method of the storage it wraps.
+type nonWatchableRESTStorage struct {
+	RESTStorage
+}
+
+func TestWatchQueryParamNotWatchable(t *testing.T) {
+	handler := Handle(map[string]RESTStorage{
+		"bar": nonWatchableRESTStorage{&SimpleRESTStorage{}},
+	}, codec, "/prefix/version", selfLinker)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/prefix/version/bar?watch=true")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, response.StatusCode)
+	}
+}
+
 func TestWatchParamParsing(t *testing.T) {
 	simpleStorage := &SimpleRESTStorage{}
 	handler := Handle(map[string]RESTStorage{
@@ -21,10 +21,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
 )
 
+// TokenAuthenticator authenticates the tokens listed in a CSV file, one per
+// line, as "token,user name,user uid" followed by an optional column holding
+// the comma separated groups of the user, e.g. token1,user1,uid1,"group1,group2".
 type TokenAuthenticator struct {
 	tokens map[string]*user.DefaultInfo
 }
@@ -38,6 +42,7 @@ func New(path string) (*TokenAuthenticator, error) {
 
 	tokens := make(map[string]*user.DefaultInfo)
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
@@ -53,6 +58,9 @@ func New(path string) (*TokenAuthenticator, error) {
 			Name: record[1],
 			UID:  record[2],
 		}
+		if len(record) > 3 && len(record[3]) > 0 {
+			obj.Groups = strings.Split(record[3], ",")
+		}
 		tokens[record[0]] = obj
 	}
 
@@ -29,6 +29,8 @@ func TestTokenFile(t *testing.T) {
 	auth, err := newWithContents(t, `
 token1,user1,uid1
 token2,user2,uid2
+token3,user3,uid3,"group1,group2"
+token4,user4,uid4,
 `)
 	if err != nil {
 		t.Fatalf("unable to read tokenfile: %v", err)
@@ -52,9 +54,16 @@ token2,user2,uid2
 		},
 		{
 			Token: "token3",
+			User:  &user.DefaultInfo{Name: "user3", UID: "uid3", Groups: []string{"group1", "group2"}},
+			Ok:    true,
 		},
 		{
 			Token: "token4",
+			User:  &user.DefaultInfo{Name: "user4", UID: "uid4"},
+			Ok:    true,
+		},
+		{
+			Token: "token5",
 		},
 	}
 	for i, testCase := range testCases {
@@ -17,6 +17,7 @@ limitations under the License.
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"sync"
 
@@ -25,6 +26,13 @@ import (
 	"github.com/golang/glog"
 )
 
+// RemoteUserHeader is set on authenticated requests to the name of their user,
+// for the handlers they are passed on to.
+const RemoteUserHeader = "X-Remote-User"
+
+// userKey is the key of the user of an authenticated request in its context.
+type userKey struct{}
+
 // RequestContext is the interface used to associate a user with an http Request.
 type RequestContext interface {
 	Set(*http.Request, user.Info)
@@ -32,9 +40,9 @@ type RequestContext interface {
 }
 
 // NewRequestAuthenticator creates an http handler that tries to authenticate the given request as a user, and then
-// stores any such user found onto the provided context for the request. If authentication fails or returns an error
-// the failed handler is used. On success, handler is invoked to serve the request.
-func NewRequestAuthenticator(context RequestContext, auth authenticator.Request, failed http.Handler, handler http.Handler) http.Handler {
+// stores any such user found onto the provided context for the request, and in its RemoteUserHeader. If authentication
+// fails or returns an error the failed handler is used. On success, handler is invoked to serve the request.
+func NewRequestAuthenticator(requestContext RequestContext, auth authenticator.Request, failed http.Handler, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		user, ok, err := auth.AuthenticateRequest(req)
 		if err != nil || !ok {
@@ -45,8 +53,10 @@ func NewRequestAuthenticator(context RequestContext, auth authenticator.Request,
 			return
 		}
 
-		context.Set(req, user)
-		defer context.Remove(req)
+		req.Header.Set(RemoteUserHeader, user.GetName())
+		req = req.WithContext(WithUser(req.Context(), user))
+		requestContext.Set(req, user)
+		defer requestContext.Remove(req)
 
 		handler.ServeHTTP(w, req)
 	})
@@ -75,11 +85,27 @@ func NewUserRequestContext() *UserRequestContext {
 	}
 }
 
+// Get returns the user stored for req. Handlers which replace the request, for
+// example to give it a deadline, pass on its context, which the user is found in.
 func (c *UserRequestContext) Get(req *http.Request) (user.Info, bool) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
-	user, ok := c.requests[req]
-	return user, ok
+	u, ok := c.requests[req]
+	c.lock.Unlock()
+	if ok {
+		return u, true
+	}
+	return UserFrom(req.Context())
+}
+
+// WithUser returns a copy of ctx which carries u as the user of its request.
+func WithUser(ctx context.Context, u user.Info) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// UserFrom returns the user a request with the context ctx was authenticated as.
+func UserFrom(ctx context.Context) (user.Info, bool) {
+	u, ok := ctx.Value(userKey{}).(user.Info)
+	return u, ok
 }
 
 func (c *UserRequestContext) Set(req *http.Request, user user.Info) {
@@ -41,11 +41,17 @@ func TestAuthenticateRequest(t *testing.T) {
 			if user, ok := context.Get(req); user == nil || !ok {
 				t.Errorf("no user stored on context: %#v", context)
 			}
+			if name := req.Header.Get(RemoteUserHeader); name != "user" {
+				t.Errorf("expected %s to be user, got %q", RemoteUserHeader, name)
+			}
+			if user, ok := context.Get(req.WithContext(req.Context())); user == nil || !ok {
+				t.Errorf("no user found for a request replaced by a handler: %#v", context)
+			}
 			close(success)
 		}),
 	)
 
-	auth.ServeHTTP(httptest.NewRecorder(), &http.Request{})
+	auth.ServeHTTP(httptest.NewRecorder(), &http.Request{Header: http.Header{}})
 
 	<-success
 	if len(context.requests) > 0 {
@@ -69,7 +75,7 @@ func TestAuthenticateRequestFailed(t *testing.T) {
 		}),
 	)
 
-	auth.ServeHTTP(httptest.NewRecorder(), &http.Request{})
+	auth.ServeHTTP(httptest.NewRecorder(), &http.Request{Header: http.Header{}})
 
 	<-failed
 	if len(context.requests) > 0 {
@@ -93,7 +99,7 @@ func TestAuthenticateRequestError(t *testing.T) {
 		}),
 	)
 
-	auth.ServeHTTP(httptest.NewRecorder(), &http.Request{})
+	auth.ServeHTTP(httptest.NewRecorder(), &http.Request{Header: http.Header{}})
 
 	<-failed
 	if len(context.requests) > 0 {
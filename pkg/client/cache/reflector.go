@@ -116,7 +116,7 @@ func (r *Reflector) syncWith(items []runtime.Object) error {
 		if err != nil {
 			return fmt.Errorf("unexpected item in list: %v", err)
 		}
-		found[jsonBase.ID()] = item
+		found[MetaKey(jsonBase)] = item
 	}
 
 	r.store.Replace(found)
@@ -146,14 +146,14 @@ func (r *Reflector) watchHandler(w watch.Interface, resourceVersion *string) err
 		}
 		switch event.Type {
 		case watch.Added:
-			r.store.Add(jsonBase.ID(), event.Object)
+			r.store.Add(MetaKey(jsonBase), event.Object)
 		case watch.Modified:
-			r.store.Update(jsonBase.ID(), event.Object)
+			r.store.Update(MetaKey(jsonBase), event.Object)
 		case watch.Deleted:
 			// TODO: Will any consumers need access to the "last known
 			// state", which is passed in event.Object? If so, may need
 			// to change this.
-			r.store.Delete(jsonBase.ID())
+			r.store.Delete(MetaKey(jsonBase))
 		default:
 			glog.Errorf("unable to understand watch event %#v", event)
 		}
@@ -169,3 +169,13 @@ func (r *Reflector) watchHandler(w watch.Interface, resourceVersion *string) err
 	glog.V(4).Infof("watch close - %v total items received", eventCount)
 	return nil
 }
+
+// MetaKey returns the key under which a Reflector stores the object with the
+// given TypeMeta: its namespace and ID, or only its ID if it has no namespace,
+// so that objects of the same name in different namespaces do not collide.
+func MetaKey(meta runtime.TypeMetaInterface) string {
+	if len(meta.Namespace()) == 0 {
+		return meta.ID()
+	}
+	return meta.Namespace() + "/" + meta.ID()
+}
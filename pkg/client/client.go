@@ -73,6 +73,13 @@ type EndpointsInterface interface {
 	WatchEndpoints(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error)
 }
 
+// LeaseInterface has methods to work with Lease resources.
+type LeaseInterface interface {
+	GetLease(ctx api.Context, id string) (*api.Lease, error)
+	CreateLease(ctx api.Context, lease *api.Lease) (*api.Lease, error)
+	UpdateLease(ctx api.Context, lease *api.Lease) (*api.Lease, error)
+}
+
 // VersionInterface has a method to retrieve the server version.
 type VersionInterface interface {
 	ServerVersion() (*version.Info, error)
@@ -96,26 +103,26 @@ type Client struct {
 // ListPods takes a selector, and returns the list of pods that match that selector.
 func (c *Client) ListPods(ctx api.Context, selector labels.Selector) (result *api.PodList, err error) {
 	result = &api.PodList{}
-	err = c.Get().Path("pods").SelectorParam("labels", selector).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("pods").SelectorParam("labels", selector).Do().Into(result)
 	return
 }
 
 // GetPod takes the id of the pod, and returns the corresponding Pod object, and an error if it occurs
 func (c *Client) GetPod(ctx api.Context, id string) (result *api.Pod, err error) {
 	result = &api.Pod{}
-	err = c.Get().Path("pods").Path(id).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("pods").Path(id).Do().Into(result)
 	return
 }
 
 // DeletePod takes the id of the pod, and returns an error if one occurs
 func (c *Client) DeletePod(ctx api.Context, id string) error {
-	return c.Delete().Path("pods").Path(id).Do().Error()
+	return c.Delete().Namespace(namespaceOf(ctx)).Path("pods").Path(id).Do().Error()
 }
 
 // CreatePod takes the representation of a pod.  Returns the server's representation of the pod, and an error, if it occurs.
 func (c *Client) CreatePod(ctx api.Context, pod *api.Pod) (result *api.Pod, err error) {
 	result = &api.Pod{}
-	err = c.Post().Path("pods").Body(pod).Do().Into(result)
+	err = c.Post().Namespace(namespaceOf(ctx)).Path("pods").Body(pod).Do().Into(result)
 	return
 }
 
@@ -126,28 +133,28 @@ func (c *Client) UpdatePod(ctx api.Context, pod *api.Pod) (result *api.Pod, err
 		err = fmt.Errorf("invalid update object, missing resource version: %v", pod)
 		return
 	}
-	err = c.Put().Path("pods").Path(pod.ID).Body(pod).Do().Into(result)
+	err = c.Put().Namespace(namespaceOf(ctx)).Path("pods").Path(pod.ID).Body(pod).Do().Into(result)
 	return
 }
 
 // ListReplicationControllers takes a selector, and returns the list of replication controllers that match that selector.
 func (c *Client) ListReplicationControllers(ctx api.Context, selector labels.Selector) (result *api.ReplicationControllerList, err error) {
 	result = &api.ReplicationControllerList{}
-	err = c.Get().Path("replicationControllers").SelectorParam("labels", selector).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("replicationControllers").SelectorParam("labels", selector).Do().Into(result)
 	return
 }
 
 // GetReplicationController returns information about a particular replication controller.
 func (c *Client) GetReplicationController(ctx api.Context, id string) (result *api.ReplicationController, err error) {
 	result = &api.ReplicationController{}
-	err = c.Get().Path("replicationControllers").Path(id).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("replicationControllers").Path(id).Do().Into(result)
 	return
 }
 
 // CreateReplicationController creates a new replication controller.
 func (c *Client) CreateReplicationController(ctx api.Context, controller *api.ReplicationController) (result *api.ReplicationController, err error) {
 	result = &api.ReplicationController{}
-	err = c.Post().Path("replicationControllers").Body(controller).Do().Into(result)
+	err = c.Post().Namespace(namespaceOf(ctx)).Path("replicationControllers").Body(controller).Do().Into(result)
 	return
 }
 
@@ -158,18 +165,19 @@ func (c *Client) UpdateReplicationController(ctx api.Context, controller *api.Re
 		err = fmt.Errorf("invalid update object, missing resource version: %v", controller)
 		return
 	}
-	err = c.Put().Path("replicationControllers").Path(controller.ID).Body(controller).Do().Into(result)
+	err = c.Put().Namespace(namespaceOf(ctx)).Path("replicationControllers").Path(controller.ID).Body(controller).Do().Into(result)
 	return
 }
 
 // DeleteReplicationController deletes an existing replication controller.
 func (c *Client) DeleteReplicationController(ctx api.Context, id string) error {
-	return c.Delete().Path("replicationControllers").Path(id).Do().Error()
+	return c.Delete().Namespace(namespaceOf(ctx)).Path("replicationControllers").Path(id).Do().Error()
 }
 
 // WatchReplicationControllers returns a watch.Interface that watches the requested controllers.
 func (c *Client) WatchReplicationControllers(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
 	return c.Get().
+		Namespace(namespaceOf(ctx)).
 		Path("watch").
 		Path("replicationControllers").
 		Param("resourceVersion", resourceVersion).
@@ -181,21 +189,21 @@ func (c *Client) WatchReplicationControllers(ctx api.Context, label, field label
 // ListServices takes a selector, and returns the list of services that match that selector
 func (c *Client) ListServices(ctx api.Context, selector labels.Selector) (result *api.ServiceList, err error) {
 	result = &api.ServiceList{}
-	err = c.Get().Path("services").SelectorParam("labels", selector).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("services").SelectorParam("labels", selector).Do().Into(result)
 	return
 }
 
 // GetService returns information about a particular service.
 func (c *Client) GetService(ctx api.Context, id string) (result *api.Service, err error) {
 	result = &api.Service{}
-	err = c.Get().Path("services").Path(id).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("services").Path(id).Do().Into(result)
 	return
 }
 
 // CreateService creates a new service.
 func (c *Client) CreateService(ctx api.Context, svc *api.Service) (result *api.Service, err error) {
 	result = &api.Service{}
-	err = c.Post().Path("services").Body(svc).Do().Into(result)
+	err = c.Post().Namespace(namespaceOf(ctx)).Path("services").Body(svc).Do().Into(result)
 	return
 }
 
@@ -206,18 +214,19 @@ func (c *Client) UpdateService(ctx api.Context, svc *api.Service) (result *api.S
 		err = fmt.Errorf("invalid update object, missing resource version: %v", svc)
 		return
 	}
-	err = c.Put().Path("services").Path(svc.ID).Body(svc).Do().Into(result)
+	err = c.Put().Namespace(namespaceOf(ctx)).Path("services").Path(svc.ID).Body(svc).Do().Into(result)
 	return
 }
 
 // DeleteService deletes an existing service.
 func (c *Client) DeleteService(ctx api.Context, id string) error {
-	return c.Delete().Path("services").Path(id).Do().Error()
+	return c.Delete().Namespace(namespaceOf(ctx)).Path("services").Path(id).Do().Error()
 }
 
 // WatchServices returns a watch.Interface that watches the requested services.
 func (c *Client) WatchServices(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
 	return c.Get().
+		Namespace(namespaceOf(ctx)).
 		Path("watch").
 		Path("services").
 		Param("resourceVersion", resourceVersion).
@@ -229,20 +238,21 @@ func (c *Client) WatchServices(ctx api.Context, label, field labels.Selector, re
 // ListEndpoints takes a selector, and returns the list of endpoints that match that selector
 func (c *Client) ListEndpoints(ctx api.Context, selector labels.Selector) (result *api.EndpointsList, err error) {
 	result = &api.EndpointsList{}
-	err = c.Get().Path("endpoints").SelectorParam("labels", selector).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("endpoints").SelectorParam("labels", selector).Do().Into(result)
 	return
 }
 
 // GetEndpoints returns information about the endpoints for a particular service.
 func (c *Client) GetEndpoints(ctx api.Context, id string) (result *api.Endpoints, err error) {
 	result = &api.Endpoints{}
-	err = c.Get().Path("endpoints").Path(id).Do().Into(result)
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("endpoints").Path(id).Do().Into(result)
 	return
 }
 
 // WatchEndpoints returns a watch.Interface that watches the requested endpoints for a service.
 func (c *Client) WatchEndpoints(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
 	return c.Get().
+		Namespace(namespaceOf(ctx)).
 		Path("watch").
 		Path("endpoints").
 		Param("resourceVersion", resourceVersion).
@@ -253,7 +263,7 @@ func (c *Client) WatchEndpoints(ctx api.Context, label, field labels.Selector, r
 
 func (c *Client) CreateEndpoints(ctx api.Context, endpoints *api.Endpoints) (*api.Endpoints, error) {
 	result := &api.Endpoints{}
-	err := c.Post().Path("endpoints").Body(endpoints).Do().Into(result)
+	err := c.Post().Namespace(namespaceOf(ctx)).Path("endpoints").Body(endpoints).Do().Into(result)
 	return result, err
 }
 
@@ -263,6 +273,7 @@ func (c *Client) UpdateEndpoints(ctx api.Context, endpoints *api.Endpoints) (*ap
 		return nil, fmt.Errorf("invalid update object, missing resource version: %v", endpoints)
 	}
 	err := c.Put().
+		Namespace(namespaceOf(ctx)).
 		Path("endpoints").
 		Path(endpoints.ID).
 		Body(endpoints).
@@ -271,6 +282,41 @@ func (c *Client) UpdateEndpoints(ctx api.Context, endpoints *api.Endpoints) (*ap
 	return result, err
 }
 
+// GetLease returns the lease with the given id.
+func (c *Client) GetLease(ctx api.Context, id string) (result *api.Lease, err error) {
+	result = &api.Lease{}
+	err = c.Get().Namespace(namespaceOf(ctx)).Path("leases").Path(id).Do().Into(result)
+	return
+}
+
+// CreateLease creates a new lease.
+func (c *Client) CreateLease(ctx api.Context, lease *api.Lease) (result *api.Lease, err error) {
+	result = &api.Lease{}
+	err = c.Post().Namespace(namespaceOf(ctx)).Path("leases").Body(lease).Do().Into(result)
+	return
+}
+
+// UpdateLease updates an existing lease.
+func (c *Client) UpdateLease(ctx api.Context, lease *api.Lease) (result *api.Lease, err error) {
+	result = &api.Lease{}
+	if len(lease.ResourceVersion) == 0 {
+		err = fmt.Errorf("invalid update object, missing resource version: %v", lease)
+		return
+	}
+	err = c.Put().Namespace(namespaceOf(ctx)).Path("leases").Path(lease.ID).Body(lease).Do().Into(result)
+	return
+}
+
+// namespaceOf returns the namespace parameter for ctx: its namespace,
+// api.NamespaceAllParam if it is set to api.NamespaceAll, or "" if it has none.
+func namespaceOf(ctx api.Context) string {
+	namespace, ok := api.NamespaceFrom(ctx)
+	if ok && namespace == api.NamespaceAll {
+		return api.NamespaceAllParam
+	}
+	return namespace
+}
+
 // ServerVersion retrieves and parses the server's version.
 func (c *Client) ServerVersion() (*version.Info, error) {
 	body, err := c.Get().AbsPath("/version").Do().Raw()
@@ -297,3 +343,11 @@ func (c *Client) GetMinion(id string) (result *api.Minion, err error) {
 	err = c.Get().Path("minions").Path(id).Do().Into(result)
 	return
 }
+
+// UpdateMinionStatus replaces the status of a minion with minion.Status. This
+// is how kubelets report the conditions of their minion.
+func (c *Client) UpdateMinionStatus(minion *api.Minion) (result *api.Minion, err error) {
+	result = &api.Minion{}
+	err = c.Put().Path("minions").Path(minion.ID).Path("status").Body(minion).Do().Into(result)
+	return
+}
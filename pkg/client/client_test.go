@@ -178,6 +178,16 @@ func TestListPods(t *testing.T) {
 	c.Validate(t, receivedPodList, err)
 }
 
+func TestListPodsAllNamespaces(t *testing.T) {
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceAll)
+	c := &testClient{
+		Request:  testRequest{Method: "GET", Path: "/pods", Query: url.Values{"namespace": []string{api.NamespaceAllParam}}},
+		Response: Response{StatusCode: 200, Body: &api.PodList{}},
+	}
+	receivedPodList, err := c.Setup().ListPods(ctx, labels.Everything())
+	c.Validate(t, receivedPodList, err)
+}
+
 func validateLabels(a, b string) bool {
 	sA, _ := labels.ParseSelector(a)
 	sB, _ := labels.ParseSelector(b)
@@ -233,6 +243,16 @@ func TestGetPod(t *testing.T) {
 	c.Validate(t, receivedPod, err)
 }
 
+func TestGetPodNamespace(t *testing.T) {
+	ctx := api.WithNamespace(api.NewContext(), "other")
+	c := &testClient{
+		Request:  testRequest{Method: "GET", Path: "/pods/foo", Query: url.Values{"namespace": []string{"other"}}},
+		Response: Response{StatusCode: 200, Body: &api.Pod{}},
+	}
+	receivedPod, err := c.Setup().GetPod(ctx, "foo")
+	c.Validate(t, receivedPod, err)
+}
+
 func TestDeletePod(t *testing.T) {
 	c := &testClient{
 		Request:  testRequest{Method: "DELETE", Path: "/pods/foo"},
@@ -482,6 +502,26 @@ func TestDeleteService(t *testing.T) {
 	c.Validate(t, nil, err)
 }
 
+func TestGetLease(t *testing.T) {
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceSystem)
+	c := &testClient{
+		Request:  testRequest{Method: "GET", Path: "/leases/foo", Query: url.Values{"namespace": []string{api.NamespaceSystem}}},
+		Response: Response{StatusCode: 200, Body: &api.Lease{TypeMeta: api.TypeMeta{ID: "foo"}}},
+	}
+	response, err := c.Setup().GetLease(ctx, "foo")
+	c.Validate(t, response, err)
+}
+
+func TestUpdateLease(t *testing.T) {
+	lease := &api.Lease{TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: "1"}}
+	c := &testClient{
+		Request:  testRequest{Method: "PUT", Path: "/leases/foo", Body: lease},
+		Response: Response{StatusCode: 200, Body: lease},
+	}
+	response, err := c.Setup().UpdateLease(api.NewDefaultContext(), lease)
+	c.Validate(t, response, err)
+}
+
 func TestListEndpooints(t *testing.T) {
 	c := &testClient{
 		Request: testRequest{Method: "GET", Path: "/endpoints"},
@@ -544,3 +584,18 @@ func TestListMinions(t *testing.T) {
 	response, err := c.Setup().ListMinions()
 	c.Validate(t, response, err)
 }
+
+func TestUpdateMinionStatus(t *testing.T) {
+	minion := &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "minion-1"},
+		Status: api.NodeStatus{
+			Conditions: []api.NodeCondition{{Type: api.NodeReady, Status: api.ConditionTrue}},
+		},
+	}
+	c := &testClient{
+		Request:  testRequest{Method: "PUT", Path: "/minions/minion-1/status", Body: minion},
+		Response: Response{StatusCode: 200, Body: minion},
+	}
+	response, err := c.Setup().UpdateMinionStatus(minion)
+	c.Validate(t, response, err)
+}
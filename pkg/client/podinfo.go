@@ -20,9 +20,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
@@ -39,6 +42,26 @@ type PodInfoGetter interface {
 	GetPodInfo(host, podNamespace, podID string) (api.PodInfo, error)
 }
 
+// PodLogOptions selects the logs of a pod's container which GetPodLogs returns.
+type PodLogOptions struct {
+	// Container is the name of the container whose logs are returned.
+	Container string
+	// Follow keeps the logs streaming as the container writes them.
+	Follow bool
+	// TailLines, if positive, limits the logs to that many lines from their end.
+	TailLines int
+	// SinceSeconds, if positive, limits the logs to those written in that many
+	// seconds before now.
+	SinceSeconds int
+}
+
+// PodLogGetter is an interface for things that can get the logs of a pod's containers.
+type PodLogGetter interface {
+	// GetPodLogs returns a stream of the logs selected by opts, which the caller
+	// must close.
+	GetPodLogs(host, podNamespace, podID string, opts PodLogOptions) (io.ReadCloser, error)
+}
+
 // HTTPPodInfoGetter is the default implementation of PodInfoGetter, accesses the kubelet over HTTP.
 type HTTPPodInfoGetter struct {
 	Client *http.Client
@@ -79,6 +102,37 @@ func (c *HTTPPodInfoGetter) GetPodInfo(host, podNamespace, podID string) (api.Po
 	return info, nil
 }
 
+// GetPodLogs gets the logs of a container of the specified pod.
+func (c *HTTPPodInfoGetter) GetPodLogs(host, podNamespace, podID string, opts PodLogOptions) (io.ReadCloser, error) {
+	query := url.Values{}
+	query.Set("podNamespace", podNamespace)
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	if opts.TailLines > 0 {
+		query.Set("tail", strconv.Itoa(opts.TailLines))
+	}
+	if opts.SinceSeconds > 0 {
+		query.Set("sinceSeconds", strconv.Itoa(opts.SinceSeconds))
+	}
+	location := url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(host, strconv.FormatUint(uint64(c.Port), 10)),
+		Path:     path.Join("/containerLogs", podID, opts.Container),
+		RawQuery: query.Encode(),
+	}
+	response, err := c.Client.Get(location.String())
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		return nil, fmt.Errorf("couldn't get the logs of container %s of pod %s: %s: %s", opts.Container, podID, response.Status, body)
+	}
+	return response.Body, nil
+}
+
 // FakePodInfoGetter is a fake implementation of PodInfoGetter. It is useful for testing.
 type FakePodInfoGetter struct {
 	data api.PodInfo
@@ -18,6 +18,7 @@ package client
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -107,3 +108,76 @@ func TestHTTPPodInfoGetterNotFound(t *testing.T) {
 		t.Errorf("Expected %#v, Got %#v", ErrPodInfoNotAvailable, err)
 	}
 }
+
+func TestHTTPPodInfoGetterLogs(t *testing.T) {
+	var requestURI string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestURI = req.RequestURI
+		w.Write([]byte("line 1\nline 2\n"))
+	}))
+	defer testServer.Close()
+
+	hostURL, err := url.Parse(testServer.URL)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	parts := strings.Split(hostURL.Host, ":")
+	port, err := strconv.Atoi(parts[1])
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	podInfoGetter := &HTTPPodInfoGetter{
+		Client: http.DefaultClient,
+		Port:   uint(port),
+	}
+	stream, err := podInfoGetter.GetPodLogs(parts[0], api.NamespaceDefault, "foo", PodLogOptions{
+		Container:    "bar",
+		Follow:       true,
+		TailLines:    5,
+		SinceSeconds: 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+	logs, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if string(logs) != "line 1\nline 2\n" {
+		t.Errorf("Unexpected logs: %q", logs)
+	}
+	expectURI := "/containerLogs/foo/bar?follow=true&podNamespace=default&sinceSeconds=60&tail=5"
+	if requestURI != expectURI {
+		t.Errorf("Expected %s, Got %s", expectURI, requestURI)
+	}
+}
+
+func TestHTTPPodInfoGetterLogsError(t *testing.T) {
+	fakeHandler := util.FakeHandler{
+		StatusCode:   500,
+		ResponseBody: "Pod not found",
+	}
+	testServer := httptest.NewServer(&fakeHandler)
+	defer testServer.Close()
+
+	hostURL, err := url.Parse(testServer.URL)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	parts := strings.Split(hostURL.Host, ":")
+	port, err := strconv.Atoi(parts[1])
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	podInfoGetter := &HTTPPodInfoGetter{
+		Client: http.DefaultClient,
+		Port:   uint(port),
+	}
+	_, err = podInfoGetter.GetPodLogs(parts[0], api.NamespaceDefault, "foo", PodLogOptions{Container: "bar"})
+	if err == nil || !strings.Contains(err.Error(), "Pod not found") {
+		t.Errorf("Expected the error of the kubelet, Got %v", err)
+	}
+}
@@ -65,6 +65,15 @@ func (r *Request) Path(item string) *Request {
 	return r
 }
 
+// Namespace sets the "namespace" parameter, which selects the namespace the
+// request is made in. An empty namespace leaves it to the server's default.
+func (r *Request) Namespace(namespace string) *Request {
+	if r.err != nil || len(namespace) == 0 {
+		return r
+	}
+	return r.setParam("namespace", namespace)
+}
+
 // Sync sets sync/async call status by setting the "sync" parameter to "true"/"false".
 func (r *Request) Sync(sync bool) *Request {
 	if r.err != nil {
@@ -116,8 +116,8 @@ func (aws *AWSCloud) Zones() (cloudprovider.Zones, bool) {
 	return nil, false
 }
 
-// IPAddress is an implementation of Instances.IPAddress.
-func (aws *AWSCloud) IPAddress(name string) (net.IP, error) {
+// getInstanceByDNSName returns the instance whose private DNS name is name.
+func (aws *AWSCloud) getInstanceByDNSName(name string) (*ec2.Instance, error) {
 	f := ec2.NewFilter()
 	f.Add("private-dns-name", name)
 
@@ -137,8 +137,17 @@ func (aws *AWSCloud) IPAddress(name string) (net.IP, error) {
 	if len(resp.Reservations[0].Instances) > 1 {
 		return nil, fmt.Errorf("Multiple instances found for host: %s", name)
 	}
+	return &resp.Reservations[0].Instances[0], nil
+}
+
+// IPAddress is an implementation of Instances.IPAddress.
+func (aws *AWSCloud) IPAddress(name string) (net.IP, error) {
+	instance, err := aws.getInstanceByDNSName(name)
+	if err != nil {
+		return nil, err
+	}
 
-	ipAddress := resp.Reservations[0].Instances[0].PrivateIpAddress
+	ipAddress := instance.PrivateIpAddress
 	ip := net.ParseIP(ipAddress)
 	if ip == nil {
 		return nil, fmt.Errorf("Invalid network IP: %s", ipAddress)
@@ -181,6 +190,30 @@ func (aws *AWSCloud) List(filter string) ([]string, error) {
 	return aws.getInstancesByRegex(filter)
 }
 
-func (v *AWSCloud) GetNodeResources(name string) (*api.NodeResources, error) {
+func (aws *AWSCloud) GetNodeResources(name string) (*api.NodeResources, error) {
 	return nil, nil
 }
+
+// IsPreemptible is an implementation of Instances.IsPreemptible.
+// TODO: report spot instances once the vendored goamz exposes the instance lifecycle
+// and is updated through Godeps.
+func (aws *AWSCloud) IsPreemptible(name string) (bool, error) {
+	return false, nil
+}
+
+// InstanceZone is an implementation of Instances.InstanceZone.
+func (aws *AWSCloud) InstanceZone(name string) (cloudprovider.Zone, error) {
+	instance, err := aws.getInstanceByDNSName(name)
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+	return cloudprovider.Zone{
+		FailureDomain: instance.AvailZone,
+		Region:        aws.cfg.Global.Region,
+	}, nil
+}
+
+// PreemptibleAnnotation is an implementation of Instances.PreemptibleAnnotation.
+func (aws *AWSCloud) PreemptibleAnnotation() (key, value string) {
+	return cloudprovider.AWSCapacityTypeAnnotation, "SPOT"
+}
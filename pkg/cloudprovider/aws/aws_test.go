@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
 	"github.com/mitchellh/goamz/aws"
 	"github.com/mitchellh/goamz/ec2"
 )
@@ -155,3 +156,24 @@ func TestIPAddress(t *testing.T) {
 		t.Errorf("Expected %v, got %v", e, a)
 	}
 }
+
+func TestInstanceZone(t *testing.T) {
+	instances := make([]ec2.Instance, 1)
+	instances[0].PrivateDNSName = "instance1"
+	instances[0].AvailZone = "us-east-1a"
+
+	aws := mockInstancesResp(instances)
+	aws.cfg = &AWSCloudConfig{}
+	aws.cfg.Global.Region = "us-east-1"
+	zone, err := aws.InstanceZone("instance1")
+	if err != nil {
+		t.Errorf("Should not error when instance found")
+	}
+	if e, a := (cloudprovider.Zone{FailureDomain: "us-east-1a", Region: "us-east-1"}), zone; e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+
+	if _, err := mockInstancesResp([]ec2.Instance{}).InstanceZone("instance1"); err == nil {
+		t.Errorf("Should error when no instance found")
+	}
+}
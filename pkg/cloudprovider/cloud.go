@@ -53,8 +53,24 @@ type Instances interface {
 	List(filter string) ([]string, error)
 	// GetNodeResources gets the resources for a particular node
 	GetNodeResources(name string) (*api.NodeResources, error)
+	// IsPreemptible returns true if the specified instance may be reclaimed by the cloud at any time
+	IsPreemptible(name string) (bool, error)
+	// InstanceZone returns the Zone containing the specified instance
+	InstanceZone(name string) (Zone, error)
+	// PreemptibleAnnotation returns the annotation to set on minions backed by preemptible instances,
+	// or an empty key if the cloud has no preemptible instances
+	PreemptibleAnnotation() (key, value string)
 }
 
+const (
+	// GCEPreemptibleAnnotation is set to "true" on minions backed by preemptible
+	// Google Compute Engine instances.
+	GCEPreemptibleAnnotation = "cloud.google.com/gke-preemptible"
+	// AWSCapacityTypeAnnotation is set to "SPOT" on minions backed by Amazon Web
+	// Services spot instances.
+	AWSCapacityTypeAnnotation = "eks.amazonaws.com/capacityType"
+)
+
 // Zone represents the location of a particular machine.
 type Zone struct {
 	FailureDomain string
@@ -32,6 +32,11 @@ type FakeCloud struct {
 	IP            net.IP
 	Machines      []string
 	NodeResources *api.NodeResources
+	Preemptible   map[string]bool
+	InstanceZones map[string]cloudprovider.Zone
+	// PreemptibleKey and PreemptibleValue are returned by PreemptibleAnnotation.
+	PreemptibleKey   string
+	PreemptibleValue string
 
 	cloudprovider.Zone
 }
@@ -118,3 +123,17 @@ func (f *FakeCloud) GetNodeResources(name string) (*api.NodeResources, error) {
 	f.addCall("get-node-resources")
 	return f.NodeResources, f.Err
 }
+
+func (f *FakeCloud) IsPreemptible(name string) (bool, error) {
+	f.addCall("is-preemptible")
+	return f.Preemptible[name], f.Err
+}
+
+func (f *FakeCloud) InstanceZone(name string) (cloudprovider.Zone, error) {
+	f.addCall("instance-zone")
+	return f.InstanceZones[name], f.Err
+}
+
+func (f *FakeCloud) PreemptibleAnnotation() (key, value string) {
+	return f.PreemptibleKey, f.PreemptibleValue
+}
@@ -329,6 +329,24 @@ func (gce *GCECloud) GetNodeResources(name string) (*api.NodeResources, error) {
 	}
 }
 
+// IsPreemptible is an implementation of Instances.IsPreemptible.
+// TODO: the vendored compute/v1 client does not expose scheduling.preemptible yet,
+// so every instance is reported as a regular instance until it is updated through Godeps.
+func (gce *GCECloud) IsPreemptible(name string) (bool, error) {
+	return false, nil
+}
+
+// InstanceZone is an implementation of Instances.InstanceZone. List only returns
+// the instances in the zone of the cloud, so every instance is in that zone.
+func (gce *GCECloud) InstanceZone(name string) (cloudprovider.Zone, error) {
+	return gce.GetZone()
+}
+
+// PreemptibleAnnotation is an implementation of Instances.PreemptibleAnnotation.
+func (gce *GCECloud) PreemptibleAnnotation() (key, value string) {
+	return cloudprovider.GCEPreemptibleAnnotation, "true"
+}
+
 func (gce *GCECloud) GetZone() (cloudprovider.Zone, error) {
 	region, err := getGceRegion(gce.zone)
 	if err != nil {
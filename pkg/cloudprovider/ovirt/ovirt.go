@@ -159,3 +159,15 @@ func (v *OVirtCloud) List(filter string) ([]string, error) {
 func (v *OVirtCloud) GetNodeResources(name string) (*api.NodeResources, error) {
 	return nil, nil
 }
+
+func (v *OVirtCloud) IsPreemptible(name string) (bool, error) {
+	return false, nil
+}
+
+func (v *OVirtCloud) InstanceZone(name string) (cloudprovider.Zone, error) {
+	return cloudprovider.Zone{}, nil
+}
+
+func (v *OVirtCloud) PreemptibleAnnotation() (key, value string) {
+	return "", ""
+}
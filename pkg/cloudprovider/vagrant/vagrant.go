@@ -214,3 +214,15 @@ func (v *VagrantCloud) List(filter string) ([]string, error) {
 func (v *VagrantCloud) GetNodeResources(name string) (*api.NodeResources, error) {
 	return nil, nil
 }
+
+func (v *VagrantCloud) IsPreemptible(name string) (bool, error) {
+	return false, nil
+}
+
+func (v *VagrantCloud) InstanceZone(name string) (cloudprovider.Zone, error) {
+	return cloudprovider.Zone{}, nil
+}
+
+func (v *VagrantCloud) PreemptibleAnnotation() (key, value string) {
+	return "", ""
+}
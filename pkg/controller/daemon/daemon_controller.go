@@ -0,0 +1,179 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/binding"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/minion"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// DaemonSetController keeps exactly one pod of every daemon set on each of
+// the minions the set selects.
+type DaemonSetController struct {
+	daemonSets generic.Registry
+	pods       pod.Registry
+	podCreater controller.PodCreater
+	bindings   binding.Registry
+	minions    minion.Registry
+}
+
+// NewDaemonSetController returns a controller which creates the pods of the
+// daemon sets in daemonSets through podCreater, binds them to the minions in
+// minions through bindings, and deletes them from pods when their minion goes
+// away.
+func NewDaemonSetController(daemonSets generic.Registry, pods pod.Registry, podCreater controller.PodCreater, bindings binding.Registry, minions minion.Registry) *DaemonSetController {
+	return &DaemonSetController{
+		daemonSets: daemonSets,
+		pods:       pods,
+		podCreater: podCreater,
+		bindings:   bindings,
+		minions:    minions,
+	}
+}
+
+// Run syncs every daemon set once per period until stopCh is closed.
+func (c *DaemonSetController) Run(period time.Duration, stopCh <-chan struct{}) {
+	util.Until(c.syncDaemonSets, period, stopCh)
+}
+
+func (c *DaemonSetController) syncDaemonSets() {
+	minions, err := c.minions.ListMinions(api.NewContext(), labels.Everything())
+	if err != nil {
+		glog.Errorf("Couldn't list minions: %v", err)
+		return
+	}
+	obj, err := c.daemonSets.List(api.NewContext(), generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }))
+	if err != nil {
+		glog.Errorf("Couldn't list daemon sets: %v", err)
+		return
+	}
+	list, ok := obj.(*api.DaemonSetList)
+	if !ok {
+		glog.Errorf("Unexpected daemon set list: %#v", obj)
+		return
+	}
+	for i := range list.Items {
+		if err := c.syncDaemonSet(&list.Items[i], minions.Items); err != nil {
+			glog.Errorf("Couldn't sync daemon set %s: %v", list.Items[i].ID, err)
+		}
+	}
+}
+
+// syncDaemonSet creates a pod of daemonSet on every minion it selects that
+// lacks one, deletes its pods from every other minion, and records what it
+// observed in the status of daemonSet.
+func (c *DaemonSetController) syncDaemonSet(daemonSet *api.DaemonSet, minions []api.Minion) error {
+	ctx := api.WithNamespace(api.NewContext(), daemonSet.Namespace)
+	pods, err := c.pods.ListPodsPredicate(ctx, func(pod *api.Pod) bool {
+		return ownedBy(pod, daemonSet)
+	})
+	if err != nil {
+		return err
+	}
+	podsByHost := map[string][]api.Pod{}
+	for _, pod := range pods.Items {
+		podsByHost[pod.DesiredState.Host] = append(podsByHost[pod.DesiredState.Host], pod)
+	}
+
+	selector := labels.Set(daemonSet.Spec.NodeSelector).AsSelector()
+	selected := util.StringSet{}
+	status := api.DaemonSetStatus{}
+	for i := range minions {
+		if !selector.Matches(labels.Set(minions[i].Labels)) {
+			continue
+		}
+		selected.Insert(minions[i].ID)
+		status.DesiredNumberScheduled++
+		if len(podsByHost[minions[i].ID]) > 0 {
+			status.CurrentNumberScheduled++
+			continue
+		}
+		if err := c.createPod(ctx, daemonSet, minions[i].ID); err != nil {
+			glog.Errorf("Couldn't create a pod of daemon set %s on %s: %v", daemonSet.ID, minions[i].ID, err)
+		}
+	}
+
+	for host, hostPods := range podsByHost {
+		// Pods not yet bound have no host; they are retried under their minion's
+		// name on the next sync.
+		if host == "" {
+			continue
+		}
+		keep := 0
+		if selected.Has(host) {
+			keep = 1
+		}
+		for _, pod := range hostPods[keep:] {
+			glog.Infof("Daemon set %s deleting pod %s from %s", daemonSet.ID, pod.ID, host)
+			if err := c.pods.DeletePod(ctx, pod.ID); err != nil {
+				glog.Errorf("Couldn't delete pod %s of daemon set %s: %v", pod.ID, daemonSet.ID, err)
+			}
+		}
+	}
+
+	if status == daemonSet.Status {
+		return nil
+	}
+	daemonSet.Status = status
+	return c.daemonSets.Update(ctx, daemonSet.ID, daemonSet)
+}
+
+// createPod creates a pod from the template of daemonSet and binds it to host.
+// The pod is named after the set and the minion, so a pod whose binding failed
+// on an earlier sync is bound again rather than duplicated.
+func (c *DaemonSetController) createPod(ctx api.Context, daemonSet *api.DaemonSet, host string) error {
+	podID := fmt.Sprintf("%s-%s", daemonSet.ID, host)
+	pod := &api.Pod{
+		TypeMeta:     api.TypeMeta{ID: podID, Namespace: daemonSet.Namespace},
+		DesiredState: daemonSet.Spec.Template.DesiredState,
+		Labels:       daemonSet.Spec.Template.Labels,
+	}
+	if err := controller.SetCreatedBy(pod, controller.CreatedByReference("DaemonSet", &daemonSet.TypeMeta)); err != nil {
+		return err
+	}
+	glog.Infof("Daemon set %s creating pod %s on %s", daemonSet.ID, podID, host)
+	if err := controller.CreatePod(ctx, c.podCreater, pod); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return c.bindings.ApplyBinding(ctx, &api.Binding{PodID: podID, Host: host})
+}
+
+// ownedBy returns whether pod was created for daemonSet.
+func ownedBy(pod *api.Pod, daemonSet *api.DaemonSet) bool {
+	value, ok := pod.Annotations[controller.CreatedByAnnotation]
+	if !ok {
+		return false
+	}
+	var ref api.ObjectReference
+	if err := json.Unmarshal([]byte(value), &ref); err != nil {
+		return false
+	}
+	return ref.Kind == "DaemonSet" && ref.Namespace == daemonSet.Namespace && ref.Name == daemonSet.ID
+}
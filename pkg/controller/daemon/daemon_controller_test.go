@@ -0,0 +1,236 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemon
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+)
+
+// fakePodRegistry records the pods created and deleted through it.
+type fakePodRegistry struct {
+	*registrytest.PodRegistry
+	created   []api.Pod
+	createErr error
+	deleted   []string
+}
+
+func (r *fakePodRegistry) CreatePod(ctx api.Context, pod *api.Pod) error {
+	r.created = append(r.created, *pod)
+	return r.createErr
+}
+
+func (r *fakePodRegistry) DeletePod(ctx api.Context, podID string) error {
+	r.deleted = append(r.deleted, podID)
+	return r.Err
+}
+
+type fakeBindingRegistry struct {
+	bindings []api.Binding
+	err      error
+}
+
+func (r *fakeBindingRegistry) ApplyBinding(ctx api.Context, binding *api.Binding) error {
+	r.bindings = append(r.bindings, *binding)
+	return r.err
+}
+
+func newTestDaemonSet() *api.DaemonSet {
+	return &api.DaemonSet{
+		TypeMeta: api.TypeMeta{ID: "logger", Namespace: api.NamespaceDefault, UID: "1234"},
+		Spec: api.DaemonSetSpec{
+			NodeSelector: map[string]string{"logging": "true"},
+			Template: api.PodTemplate{
+				DesiredState: api.PodState{
+					Manifest: api.ContainerManifest{
+						Version:       "v1beta1",
+						Containers:    []api.Container{{Name: "logger", Image: "logger"}},
+						RestartPolicy: api.RestartPolicy{Always: &api.RestartPolicyAlways{}},
+					},
+				},
+				Labels: map[string]string{"name": "logger"},
+			},
+		},
+	}
+}
+
+// newTestPod returns a pod of daemonSet bound to host.
+func newTestPod(t *testing.T, daemonSet *api.DaemonSet, host string) api.Pod {
+	pod := api.Pod{
+		TypeMeta:     api.TypeMeta{ID: fmt.Sprintf("%s-%s", daemonSet.ID, host), Namespace: daemonSet.Namespace},
+		DesiredState: api.PodState{Host: host},
+	}
+	if err := controller.SetCreatedBy(&pod, controller.CreatedByReference("DaemonSet", &daemonSet.TypeMeta)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return pod
+}
+
+func newTestMinions(labelled, unlabelled []string) *registrytest.MinionRegistry {
+	minions := registrytest.NewMinionRegistry(append(labelled, unlabelled...), api.NodeResources{})
+	for i := range labelled {
+		minions.Minions.Items[i].Labels = map[string]string{"logging": "true"}
+	}
+	return minions
+}
+
+func newTestController(daemonSet *api.DaemonSet, pods []api.Pod, minions *registrytest.MinionRegistry) (*registrytest.GenericRegistry, *fakePodRegistry, *fakeBindingRegistry, *DaemonSetController) {
+	daemonSets := registrytest.NewGeneric(&api.DaemonSetList{Items: []api.DaemonSet{*daemonSet}})
+	podRegistry := &fakePodRegistry{PodRegistry: registrytest.NewPodRegistry(&api.PodList{Items: pods})}
+	bindings := &fakeBindingRegistry{}
+	podREST := pod.NewREST(&pod.RESTConfig{Registry: podRegistry})
+	return daemonSets, podRegistry, bindings, NewDaemonSetController(daemonSets, podRegistry, podREST, bindings, minions)
+}
+
+func TestDaemonSetControllerCreatesPods(t *testing.T) {
+	daemonSet := newTestDaemonSet()
+	minions := newTestMinions([]string{"m1", "m2"}, []string{"m3"})
+	daemonSets, pods, bindings, c := newTestController(daemonSet, nil, minions)
+	c.syncDaemonSets()
+
+	if len(pods.created) != 2 {
+		t.Fatalf("expected 2 pods, got %#v", pods.created)
+	}
+	for i, host := range []string{"m1", "m2"} {
+		pod := pods.created[i]
+		if e, a := "logger-"+host, pod.ID; e != a {
+			t.Errorf("expected pod %s, got %s", e, a)
+		}
+		// The pods storage names the manifest after the pod.
+		expected := daemonSet.Spec.Template.DesiredState
+		expected.Manifest.ID, expected.Manifest.UUID = pod.ID, pod.DesiredState.Manifest.UUID
+		if !reflect.DeepEqual(expected, pod.DesiredState) {
+			t.Errorf("expected the template state, got %#v", pod.DesiredState)
+		}
+		if !reflect.DeepEqual(daemonSet.Spec.Template.Labels, pod.Labels) {
+			t.Errorf("expected the template labels, got %#v", pod.Labels)
+		}
+		if !ownedBy(&pod, daemonSet) {
+			t.Errorf("expected pod %s to refer to the daemon set, got %#v", pod.ID, pod.Annotations)
+		}
+	}
+	expectBindings := []api.Binding{{PodID: "logger-m1", Host: "m1"}, {PodID: "logger-m2", Host: "m2"}}
+	if !reflect.DeepEqual(expectBindings, bindings.bindings) {
+		t.Errorf("expected %#v, got %#v", expectBindings, bindings.bindings)
+	}
+	if len(pods.deleted) != 0 {
+		t.Errorf("unexpected deletions %v", pods.deleted)
+	}
+	updated, ok := daemonSets.Object.(*api.DaemonSet)
+	if !ok {
+		t.Fatalf("expected the daemon set status to be updated")
+	}
+	expectStatus := api.DaemonSetStatus{CurrentNumberScheduled: 0, DesiredNumberScheduled: 2}
+	if updated.Status != expectStatus {
+		t.Errorf("expected status %#v, got %#v", expectStatus, updated.Status)
+	}
+}
+
+func TestDaemonSetControllerDeletesPods(t *testing.T) {
+	daemonSet := newTestDaemonSet()
+	minions := newTestMinions([]string{"m1"}, []string{"m2"})
+	existing := []api.Pod{
+		newTestPod(t, daemonSet, "m1"),
+		newTestPod(t, daemonSet, "m2"),
+		newTestPod(t, daemonSet, "gone"),
+	}
+	duplicate := newTestPod(t, daemonSet, "m1")
+	duplicate.ID = "logger-m1-duplicate"
+	existing = append(existing, duplicate)
+	daemonSets, pods, bindings, c := newTestController(daemonSet, existing, minions)
+	c.syncDaemonSets()
+
+	if len(pods.created) != 0 || len(bindings.bindings) != 0 {
+		t.Errorf("unexpected creations %#v %#v", pods.created, bindings.bindings)
+	}
+	sort.Strings(pods.deleted)
+	expectDeleted := []string{"logger-gone", "logger-m1-duplicate", "logger-m2"}
+	if !reflect.DeepEqual(expectDeleted, pods.deleted) {
+		t.Errorf("expected %v to be deleted, got %v", expectDeleted, pods.deleted)
+	}
+	updated, ok := daemonSets.Object.(*api.DaemonSet)
+	if !ok {
+		t.Fatalf("expected the daemon set status to be updated")
+	}
+	expectStatus := api.DaemonSetStatus{CurrentNumberScheduled: 1, DesiredNumberScheduled: 1}
+	if updated.Status != expectStatus {
+		t.Errorf("expected status %#v, got %#v", expectStatus, updated.Status)
+	}
+}
+
+func TestDaemonSetControllerIgnoresOtherPods(t *testing.T) {
+	daemonSet := newTestDaemonSet()
+	other := newTestDaemonSet()
+	other.ID = "other"
+	minions := newTestMinions(nil, []string{"m1"})
+	unowned := api.Pod{TypeMeta: api.TypeMeta{ID: "unowned"}, DesiredState: api.PodState{Host: "m1"}}
+	_, pods, _, c := newTestController(daemonSet, []api.Pod{unowned, newTestPod(t, other, "m1")}, minions)
+	c.syncDaemonSets()
+
+	if len(pods.created) != 0 || len(pods.deleted) != 0 {
+		t.Errorf("unexpected changes %#v %v", pods.created, pods.deleted)
+	}
+}
+
+func TestDaemonSetControllerRebindsUnboundPods(t *testing.T) {
+	daemonSet := newTestDaemonSet()
+	minions := newTestMinions([]string{"m1"}, nil)
+	unbound := newTestPod(t, daemonSet, "m1")
+	unbound.DesiredState.Host = ""
+	_, pods, bindings, c := newTestController(daemonSet, []api.Pod{unbound}, minions)
+	pods.createErr = errors.NewAlreadyExists("pod", unbound.ID)
+	c.syncDaemonSets()
+
+	expectBindings := []api.Binding{{PodID: "logger-m1", Host: "m1"}}
+	if !reflect.DeepEqual(expectBindings, bindings.bindings) {
+		t.Errorf("expected %#v, got %#v", expectBindings, bindings.bindings)
+	}
+	if len(pods.deleted) != 0 {
+		t.Errorf("unexpected deletions %v", pods.deleted)
+	}
+}
+
+func TestDaemonSetControllerUnchangedStatus(t *testing.T) {
+	daemonSet := newTestDaemonSet()
+	daemonSet.Status = api.DaemonSetStatus{CurrentNumberScheduled: 1, DesiredNumberScheduled: 1}
+	minions := newTestMinions([]string{"m1"}, nil)
+	daemonSets, pods, _, c := newTestController(daemonSet, []api.Pod{newTestPod(t, daemonSet, "m1")}, minions)
+	c.syncDaemonSets()
+
+	if len(pods.created) != 0 || len(pods.deleted) != 0 || daemonSets.Object != nil {
+		t.Errorf("expected no changes")
+	}
+}
+
+func TestDaemonSetControllerMinionError(t *testing.T) {
+	minions := newTestMinions([]string{"m1"}, nil)
+	minions.Err = fmt.Errorf("no minions")
+	daemonSets, pods, _, c := newTestController(newTestDaemonSet(), nil, minions)
+	c.syncDaemonSets()
+
+	if len(pods.created) != 0 || daemonSets.Object != nil {
+		t.Errorf("expected no changes")
+	}
+}
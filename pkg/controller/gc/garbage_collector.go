@@ -0,0 +1,272 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// objectKey identifies an object of a tracked kind.
+type objectKey struct {
+	kind, namespace, name string
+}
+
+// node is an object in the graph of owners and their dependents.
+type node struct {
+	key        objectKey
+	obj        runtime.Object
+	meta       *api.TypeMeta
+	dependents []*node
+}
+
+// graph holds the objects of every tracked kind, linked to the objects they own.
+type graph map[objectKey]*node
+
+// GarbageCollector deletes the objects whose owners, as named by their
+// OwnerReferences, have all been deleted. Only the objects of the kinds it is
+// given registries for are tracked; an object with an owner of another kind
+// is never collected. Owners are looked up in the namespace of their
+// dependents.
+type GarbageCollector struct {
+	registries map[string]generic.Registry
+}
+
+// NewGarbageCollector returns a GarbageCollector which tracks the objects in
+// registries, which are keyed by the kind of their objects.
+func NewGarbageCollector(registries map[string]generic.Registry) *GarbageCollector {
+	return &GarbageCollector{registries: registries}
+}
+
+// Run collects garbage once per period until stopCh is closed.
+func (gc *GarbageCollector) Run(period time.Duration, stopCh <-chan struct{}) {
+	util.Until(gc.collect, period, stopCh)
+}
+
+// collect deletes every object which has owners, none of which exists. An
+// object whose owners are missing from the graph is only deleted once they
+// are found missing from their registries too, since an owner created while
+// the graph was built may have been left out of it.
+func (gc *GarbageCollector) collect() {
+	g, err := gc.buildGraph()
+	if err != nil {
+		glog.Errorf("Couldn't build the graph of owners: %v", err)
+		return
+	}
+	for _, n := range g {
+		if len(n.meta.OwnerReferences) == 0 || gc.ownerInGraph(g, n, nil) {
+			continue
+		}
+		if owned, err := gc.ownerExists(n); err != nil || owned {
+			if err != nil {
+				glog.Errorf("Couldn't look up the owners of %s %s: %v", n.key.kind, n.key.name, err)
+			}
+			continue
+		}
+		glog.Infof("Deleting %s %s in namespace %s, whose owners are gone", n.key.kind, n.key.name, n.key.namespace)
+		if err := gc.delete(n); err != nil {
+			glog.Errorf("Couldn't delete %s %s: %v", n.key.kind, n.key.name, err)
+		}
+	}
+}
+
+// PropagateDeletion prepares the deletion of the object of kind named id in
+// the namespace of ctx, as propagation tells. DeletePropagationForeground
+// deletes its dependents, and theirs, unless they have other owners, whose
+// references to it are removed instead. DeletePropagationOrphan removes the
+// references of its dependents to it. DeletePropagationBackground does
+// nothing, since its dependents are collected once it is gone.
+func (gc *GarbageCollector) PropagateDeletion(ctx api.Context, kind, id string, propagation api.DeletionPropagation) error {
+	if propagation == api.DeletePropagationBackground {
+		return nil
+	}
+	if propagation != api.DeletePropagationForeground && propagation != api.DeletePropagationOrphan {
+		return fmt.Errorf("unsupported deletion propagation %q", propagation)
+	}
+	g, err := gc.buildGraph()
+	if err != nil {
+		return err
+	}
+	namespace, _ := api.NamespaceFrom(ctx)
+	owner, ok := g[objectKey{kind, namespace, id}]
+	if !ok {
+		// Deleting the owner fails.
+		return nil
+	}
+	if propagation == api.DeletePropagationOrphan {
+		for _, dependent := range owner.dependents {
+			if err := gc.removeOwner(dependent, owner); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return gc.deleteDependents(g, owner, map[*node]bool{owner: true})
+}
+
+// deleteDependents deletes the dependents of owner which have no other owner,
+// after their own dependents, and removes the references to owner of the
+// others. visited holds the objects being deleted.
+func (gc *GarbageCollector) deleteDependents(g graph, owner *node, visited map[*node]bool) error {
+	for _, dependent := range owner.dependents {
+		if visited[dependent] {
+			continue
+		}
+		if gc.ownerInGraph(g, dependent, visited) {
+			if err := gc.removeOwner(dependent, owner); err != nil {
+				return err
+			}
+			continue
+		}
+		visited[dependent] = true
+		if err := gc.deleteDependents(g, dependent, visited); err != nil {
+			return err
+		}
+		glog.Infof("Deleting %s %s in namespace %s before its owner", dependent.key.kind, dependent.key.name, dependent.key.namespace)
+		if err := gc.delete(dependent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildGraph lists the objects of every tracked kind, and links them to the
+// objects they own.
+func (gc *GarbageCollector) buildGraph() (graph, error) {
+	g := graph{}
+	everything := generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil })
+	for kind, registry := range gc.registries {
+		list, err := registry.List(api.NewContext(), everything)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list %s: %v", kind, err)
+		}
+		items, err := runtime.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range items {
+			meta, err := api.TypeMetaFor(obj)
+			if err != nil {
+				return nil, err
+			}
+			key := objectKey{kind, meta.Namespace, meta.ID}
+			g[key] = &node{key: key, obj: obj, meta: meta}
+		}
+	}
+	for _, n := range g {
+		for _, ref := range n.meta.OwnerReferences {
+			if owner := g.owner(n, ref); owner != nil {
+				owner.dependents = append(owner.dependents, n)
+			}
+		}
+	}
+	return g, nil
+}
+
+// owner returns the owner of n that ref refers to, or nil if it is not in g.
+func (g graph) owner(n *node, ref api.OwnerReference) *node {
+	owner, ok := g[objectKey{ref.Kind, n.key.namespace, ref.Name}]
+	if !ok || !refersTo(ref, owner) {
+		return nil
+	}
+	return owner
+}
+
+// ownerInGraph returns whether any owner of n is in g and not in deleted, or
+// is of a kind which is not tracked.
+func (gc *GarbageCollector) ownerInGraph(g graph, n *node, deleted map[*node]bool) bool {
+	for _, ref := range n.meta.OwnerReferences {
+		if _, tracked := gc.registries[ref.Kind]; !tracked {
+			return true
+		}
+		if owner := g.owner(n, ref); owner != nil && !deleted[owner] {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerExists returns whether any owner of n exists in its registry, or is of
+// a kind which is not tracked.
+func (gc *GarbageCollector) ownerExists(n *node) (bool, error) {
+	ctx := api.WithNamespace(api.NewContext(), n.key.namespace)
+	for _, ref := range n.meta.OwnerReferences {
+		registry, ok := gc.registries[ref.Kind]
+		if !ok {
+			return true, nil
+		}
+		obj, err := registry.Get(ctx, ref.Name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		meta, err := api.TypeMetaFor(obj)
+		if err != nil {
+			return false, err
+		}
+		if uidMatches(ref, meta) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// removeOwner removes the references of dependent to owner.
+func (gc *GarbageCollector) removeOwner(dependent, owner *node) error {
+	refs := []api.OwnerReference{}
+	for _, ref := range dependent.meta.OwnerReferences {
+		if ref.Kind != owner.key.kind || !refersTo(ref, owner) {
+			refs = append(refs, ref)
+		}
+	}
+	dependent.meta.OwnerReferences = refs
+	ctx := api.WithNamespace(api.NewContext(), dependent.key.namespace)
+	return gc.registries[dependent.key.kind].Update(ctx, dependent.key.name, dependent.obj)
+}
+
+// delete deletes n. An object which is already gone is not an error.
+func (gc *GarbageCollector) delete(n *node) error {
+	ctx := api.WithNamespace(api.NewContext(), n.key.namespace)
+	err := gc.registries[n.key.kind].Delete(ctx, n.key.name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// refersTo returns whether ref refers to n, whose kind it is known to name.
+func refersTo(ref api.OwnerReference, n *node) bool {
+	return ref.Name == n.key.name && uidMatches(ref, n.meta)
+}
+
+// uidMatches returns whether ref may refer to the object meta describes, which
+// it names. Only an owner which has a UID other than the one ref holds is a
+// different object, created after the owner ref refers to was deleted; an
+// owner without a UID is taken to be the one ref refers to, so that dependents
+// are never collected because their owner lost its UID.
+func uidMatches(ref api.OwnerReference, meta *api.TypeMeta) bool {
+	return len(ref.UID) == 0 || len(meta.UID) == 0 || ref.UID == meta.UID
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// fakeRegistry holds the objects of a kind by namespace and id, and records
+// the deletes made through it in a log shared by every registry.
+type fakeRegistry struct {
+	kind    string
+	list    func() runtime.Object
+	objects map[string]runtime.Object
+	log     *[]string
+}
+
+func objectPath(namespace, id string) string {
+	return namespace + "/" + id
+}
+
+func (r *fakeRegistry) List(ctx api.Context, m generic.Matcher) (runtime.Object, error) {
+	paths := []string{}
+	for path := range r.objects {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	items := []runtime.Object{}
+	for _, path := range paths {
+		items = append(items, r.objects[path])
+	}
+	list := r.list()
+	if err := runtime.SetList(list, items); err != nil {
+		return nil, err
+	}
+	return generic.FilterList(list, m)
+}
+
+func (r *fakeRegistry) Get(ctx api.Context, id string) (runtime.Object, error) {
+	namespace, _ := api.NamespaceFrom(ctx)
+	obj, ok := r.objects[objectPath(namespace, id)]
+	if !ok {
+		return nil, errors.NewNotFound(r.kind, id)
+	}
+	return obj, nil
+}
+
+func (r *fakeRegistry) Create(ctx api.Context, id string, obj runtime.Object) error {
+	namespace, _ := api.NamespaceFrom(ctx)
+	r.objects[objectPath(namespace, id)] = obj
+	return nil
+}
+
+func (r *fakeRegistry) Update(ctx api.Context, id string, obj runtime.Object) error {
+	namespace, _ := api.NamespaceFrom(ctx)
+	if _, ok := r.objects[objectPath(namespace, id)]; !ok {
+		return errors.NewNotFound(r.kind, id)
+	}
+	r.objects[objectPath(namespace, id)] = obj
+	return nil
+}
+
+func (r *fakeRegistry) Delete(ctx api.Context, id string) error {
+	namespace, _ := api.NamespaceFrom(ctx)
+	if _, ok := r.objects[objectPath(namespace, id)]; !ok {
+		return errors.NewNotFound(r.kind, id)
+	}
+	delete(r.objects, objectPath(namespace, id))
+	*r.log = append(*r.log, fmt.Sprintf("%s %s", r.kind, id))
+	return nil
+}
+
+func (r *fakeRegistry) Watch(ctx api.Context, m generic.Matcher, resourceVersion uint64) (watch.Interface, error) {
+	return nil, fmt.Errorf("watch is not supported")
+}
+
+type testCollector struct {
+	*GarbageCollector
+	jobs, controllers, pods *fakeRegistry
+	deleted                 []string
+}
+
+func newTestCollector() *testCollector {
+	c := &testCollector{}
+	newRegistry := func(kind string, list func() runtime.Object) *fakeRegistry {
+		return &fakeRegistry{kind: kind, list: list, objects: map[string]runtime.Object{}, log: &c.deleted}
+	}
+	c.jobs = newRegistry("Job", func() runtime.Object { return &api.JobList{} })
+	c.controllers = newRegistry("ReplicationController", func() runtime.Object { return &api.ReplicationControllerList{} })
+	c.pods = newRegistry("Pod", func() runtime.Object { return &api.PodList{} })
+	c.GarbageCollector = NewGarbageCollector(map[string]generic.Registry{
+		"Job":                   c.jobs,
+		"ReplicationController": c.controllers,
+		"Pod":                   c.pods,
+	})
+	return c
+}
+
+func testMeta(id, uid string, owners ...api.OwnerReference) api.TypeMeta {
+	return api.TypeMeta{ID: id, Namespace: api.NamespaceDefault, UID: uid, OwnerReferences: owners}
+}
+
+func ownedBy(kind, name, uid string) api.OwnerReference {
+	return api.OwnerReference{Kind: kind, Name: name, UID: uid}
+}
+
+func (c *testCollector) addController(id, uid string, owners ...api.OwnerReference) {
+	c.controllers.objects[objectPath(api.NamespaceDefault, id)] = &api.ReplicationController{TypeMeta: testMeta(id, uid, owners...)}
+}
+
+func (c *testCollector) addPod(id string, owners ...api.OwnerReference) {
+	c.pods.objects[objectPath(api.NamespaceDefault, id)] = &api.Pod{TypeMeta: testMeta(id, id, owners...)}
+}
+
+func (c *testCollector) podIDs() []string {
+	ids := []string{}
+	for _, obj := range c.pods.objects {
+		ids = append(ids, obj.(*api.Pod).ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestCollect(t *testing.T) {
+	c := newTestCollector()
+	c.addController("frontend", "1")
+	c.addController("backend", "2")
+	c.addController("no-uid", "")
+	c.addPod("owned", ownedBy("ReplicationController", "frontend", "1"))
+	c.addPod("owner-without-uid", ownedBy("ReplicationController", "no-uid", "5"))
+	c.addPod("owned-by-name", ownedBy("ReplicationController", "frontend", ""))
+	c.addPod("orphan", ownedBy("ReplicationController", "deleted", "3"))
+	c.addPod("stale-uid", ownedBy("ReplicationController", "frontend", "0"))
+	c.addPod("one-owner-left", ownedBy("ReplicationController", "deleted", "3"), ownedBy("ReplicationController", "backend", "2"))
+	c.addPod("untracked-owner", ownedBy("Deployment", "web", "4"))
+	c.addPod("unowned")
+
+	c.collect()
+	if e, a := []string{"one-owner-left", "owned", "owned-by-name", "owner-without-uid", "unowned", "untracked-owner"}, c.podIDs(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the pods %v to be left, got %v", e, a)
+	}
+}
+
+func TestCollectOwnerCreatedDuringList(t *testing.T) {
+	c := newTestCollector()
+	c.addPod("owned", ownedBy("ReplicationController", "frontend", "1"))
+	// The controller is created once the controllers have been listed.
+	c.controllers.list = func() runtime.Object {
+		c.addController("frontend", "1")
+		return &api.ReplicationControllerList{}
+	}
+	c.collect()
+	if e, a := []string{"owned"}, c.podIDs(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the pods %v to be left, got %v", e, a)
+	}
+}
+
+func TestPropagateDeletionBackground(t *testing.T) {
+	c := newTestCollector()
+	c.addController("frontend", "1")
+	c.addPod("a", ownedBy("ReplicationController", "frontend", "1"))
+	c.addPod("b", ownedBy("ReplicationController", "frontend", "1"))
+
+	ctx := api.NewDefaultContext()
+	if err := c.PropagateDeletion(ctx, "ReplicationController", "frontend", api.DeletePropagationBackground); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.deleted) != 0 {
+		t.Errorf("expected nothing to be deleted before the owner, got %v", c.deleted)
+	}
+	if err := c.controllers.Delete(ctx, "frontend"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.collect()
+	if a := c.podIDs(); len(a) != 0 {
+		t.Errorf("expected the dependents to be collected, got %v", a)
+	}
+}
+
+func TestPropagateDeletionForeground(t *testing.T) {
+	c := newTestCollector()
+	c.jobs.objects[objectPath(api.NamespaceDefault, "batch")] = &api.Job{TypeMeta: testMeta("batch", "1")}
+	c.addController("frontend", "2", ownedBy("Job", "batch", "1"))
+	c.addController("backend", "3")
+	c.addPod("a", ownedBy("ReplicationController", "frontend", "2"))
+	c.addPod("shared", ownedBy("ReplicationController", "frontend", "2"), ownedBy("ReplicationController", "backend", "3"))
+	c.addPod("other", ownedBy("ReplicationController", "backend", "3"))
+
+	if err := c.PropagateDeletion(api.NewDefaultContext(), "Job", "batch", api.DeletePropagationForeground); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := []string{"Pod a", "ReplicationController frontend"}, c.deleted; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the dependents to be deleted in the order %v, got %v", e, a)
+	}
+	if e, a := []string{"other", "shared"}, c.podIDs(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the pods %v to be left, got %v", e, a)
+	}
+	shared := c.pods.objects[objectPath(api.NamespaceDefault, "shared")].(*api.Pod)
+	if e, a := []api.OwnerReference{ownedBy("ReplicationController", "backend", "3")}, shared.OwnerReferences; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the owners of a pod with another owner to be %v, got %v", e, a)
+	}
+	if _, ok := c.jobs.objects[objectPath(api.NamespaceDefault, "batch")]; !ok {
+		t.Errorf("expected the owner itself to be left to its storage")
+	}
+}
+
+func TestPropagateDeletionOrphan(t *testing.T) {
+	c := newTestCollector()
+	c.addController("frontend", "1")
+	c.addController("backend", "2")
+	c.addPod("a", ownedBy("ReplicationController", "frontend", "1"))
+	c.addPod("shared", ownedBy("ReplicationController", "frontend", "1"), ownedBy("ReplicationController", "backend", "2"))
+
+	ctx := api.NewDefaultContext()
+	if err := c.PropagateDeletion(ctx, "ReplicationController", "frontend", api.DeletePropagationOrphan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.controllers.Delete(ctx, "frontend"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.collect()
+	if e, a := []string{"a", "shared"}, c.podIDs(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the pods %v to be kept, got %v", e, a)
+	}
+	a := c.pods.objects[objectPath(api.NamespaceDefault, "a")].(*api.Pod)
+	if len(a.OwnerReferences) != 0 {
+		t.Errorf("expected the references to the owner to be removed, got %v", a.OwnerReferences)
+	}
+	shared := c.pods.objects[objectPath(api.NamespaceDefault, "shared")].(*api.Pod)
+	if e, a := []api.OwnerReference{ownedBy("ReplicationController", "backend", "2")}, shared.OwnerReferences; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected the owners %v, got %v", e, a)
+	}
+}
+
+func TestPropagateDeletionUnsupported(t *testing.T) {
+	c := newTestCollector()
+	if err := c.PropagateDeletion(api.NewDefaultContext(), "Pod", "a", "Eventually"); err == nil {
+		t.Errorf("expected an unsupported propagation to fail")
+	}
+}
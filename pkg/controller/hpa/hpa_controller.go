@@ -0,0 +1,134 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// MetricsSource reports the resource usage of pods.
+type MetricsSource interface {
+	// CPUUtilization returns the average CPU utilization of the pods in namespace
+	// that selector matches, as a percentage of the CPU they request.
+	CPUUtilization(namespace string, selector labels.Selector) (int, error)
+}
+
+// tolerance is how far, as a fraction of the target, the observed utilization
+// may stray from the target before an autoscaler changes the number of replicas.
+const tolerance = 0.1
+
+// HPAController resizes the replication controllers that autoscalers refer to.
+type HPAController struct {
+	autoscalers generic.Registry
+	controllers controller.Registry
+	metrics     MetricsSource
+}
+
+// NewHPAController returns a controller which scales the replication
+// controllers in controllers according to the autoscalers in autoscalers.
+func NewHPAController(autoscalers generic.Registry, controllers controller.Registry, metrics MetricsSource) *HPAController {
+	return &HPAController{
+		autoscalers: autoscalers,
+		controllers: controllers,
+		metrics:     metrics,
+	}
+}
+
+// Run reconciles every autoscaler once per period until stopCh is closed.
+func (c *HPAController) Run(period time.Duration, stopCh <-chan struct{}) {
+	util.Until(c.reconcileAutoscalers, period, stopCh)
+}
+
+func (c *HPAController) reconcileAutoscalers() {
+	obj, err := c.autoscalers.List(api.NewContext(), generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }))
+	if err != nil {
+		glog.Errorf("Couldn't list autoscalers: %v", err)
+		return
+	}
+	list, ok := obj.(*api.HorizontalPodAutoscalerList)
+	if !ok {
+		glog.Errorf("Unexpected autoscaler list: %#v", obj)
+		return
+	}
+	for i := range list.Items {
+		if err := c.reconcileAutoscaler(&list.Items[i]); err != nil {
+			glog.Errorf("Couldn't reconcile autoscaler %s: %v", list.Items[i].ID, err)
+		}
+	}
+}
+
+// reconcileAutoscaler sets the replicas of the controller that autoscaler
+// refers to, and records what it observed in the status of autoscaler.
+func (c *HPAController) reconcileAutoscaler(autoscaler *api.HorizontalPodAutoscaler) error {
+	ctx := api.WithNamespace(api.NewContext(), autoscaler.Namespace)
+	rc, err := c.controllers.GetController(ctx, autoscaler.Spec.ScaleTargetRef.Name)
+	if err != nil {
+		return err
+	}
+	selector := labels.Set(rc.DesiredState.ReplicaSelector).AsSelector()
+	utilization, err := c.metrics.CPUUtilization(autoscaler.Namespace, selector)
+	if err != nil {
+		return fmt.Errorf("couldn't get the CPU utilization of %s: %v", rc.ID, err)
+	}
+	current := rc.DesiredState.Replicas
+	desired := desiredReplicas(current, utilization, &autoscaler.Spec)
+	if desired != current {
+		glog.Infof("Autoscaler %s resizing %s from %d to %d replicas at %d%% CPU utilization", autoscaler.ID, rc.ID, current, desired, utilization)
+		rc.DesiredState.Replicas = desired
+		if err := c.controllers.UpdateController(ctx, rc); err != nil {
+			return err
+		}
+	}
+	status := api.HorizontalPodAutoscalerStatus{
+		CurrentReplicas:       current,
+		DesiredReplicas:       desired,
+		CurrentCPUUtilization: utilization,
+	}
+	if status == autoscaler.Status {
+		return nil
+	}
+	autoscaler.Status = status
+	return c.autoscalers.Update(ctx, autoscaler.ID, autoscaler)
+}
+
+// desiredReplicas returns the number of replicas which would bring the CPU
+// utilization of current replicas, observed at utilization, to the target of
+// spec, bounded by the minimum and maximum of spec.
+func desiredReplicas(current, utilization int, spec *api.HorizontalPodAutoscalerSpec) int {
+	desired := current
+	ratio := float64(utilization) / float64(spec.TargetCPUUtilization)
+	if current > 0 && math.Abs(ratio-1) > tolerance {
+		desired = int(math.Ceil(ratio * float64(current)))
+	}
+	if desired < spec.MinReplicas {
+		desired = spec.MinReplicas
+	}
+	if desired > spec.MaxReplicas {
+		desired = spec.MaxReplicas
+	}
+	return desired
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+)
+
+type fakeMetricsSource struct {
+	utilization int
+	err         error
+	namespace   string
+	selector    labels.Selector
+}
+
+func (f *fakeMetricsSource) CPUUtilization(namespace string, selector labels.Selector) (int, error) {
+	f.namespace = namespace
+	f.selector = selector
+	return f.utilization, f.err
+}
+
+// fakeControllerRegistry holds a single replication controller.
+type fakeControllerRegistry struct {
+	registrytest.ControllerRegistry
+	controller *api.ReplicationController
+	updated    bool
+}
+
+func (r *fakeControllerRegistry) GetController(ctx api.Context, id string) (*api.ReplicationController, error) {
+	copied := *r.controller
+	return &copied, r.Err
+}
+
+func (r *fakeControllerRegistry) UpdateController(ctx api.Context, controller *api.ReplicationController) error {
+	r.controller = controller
+	r.updated = true
+	return r.Err
+}
+
+func newTestController(replicas, utilization int) (*registrytest.GenericRegistry, *fakeControllerRegistry, *fakeMetricsSource, *HPAController) {
+	autoscalers := registrytest.NewGeneric(&api.HorizontalPodAutoscalerList{
+		Items: []api.HorizontalPodAutoscaler{{
+			TypeMeta: api.TypeMeta{ID: "frontend-scaler", Namespace: api.NamespaceDefault},
+			Spec: api.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef:       api.ObjectReference{Kind: "ReplicationController", Name: "frontend"},
+				MinReplicas:          1,
+				MaxReplicas:          10,
+				TargetCPUUtilization: 50,
+			},
+		}},
+	})
+	controllers := &fakeControllerRegistry{
+		controller: &api.ReplicationController{
+			TypeMeta: api.TypeMeta{ID: "frontend", Namespace: api.NamespaceDefault},
+			DesiredState: api.ReplicationControllerState{
+				Replicas:        replicas,
+				ReplicaSelector: map[string]string{"name": "frontend"},
+			},
+		},
+	}
+	metrics := &fakeMetricsSource{utilization: utilization}
+	return autoscalers, controllers, metrics, NewHPAController(autoscalers, controllers, metrics)
+}
+
+func TestHPAControllerScales(t *testing.T) {
+	table := map[string]struct {
+		replicas    int
+		utilization int
+		expected    int
+	}{
+		"scale up":         {replicas: 2, utilization: 100, expected: 4},
+		"scale down":       {replicas: 4, utilization: 25, expected: 2},
+		"round up":         {replicas: 3, utilization: 60, expected: 4},
+		"within tolerance": {replicas: 3, utilization: 54, expected: 3},
+		"bounded by max":   {replicas: 6, utilization: 100, expected: 10},
+		"bounded by min":   {replicas: 2, utilization: 0, expected: 1},
+		"no replicas":      {replicas: 0, utilization: 0, expected: 1},
+	}
+	for name, item := range table {
+		autoscalers, controllers, metrics, c := newTestController(item.replicas, item.utilization)
+		c.reconcileAutoscalers()
+		if e, a := item.expected, controllers.controller.DesiredState.Replicas; e != a {
+			t.Errorf("%s: expected %d replicas, got %d", name, e, a)
+		}
+		if e, a := (item.expected != item.replicas), controllers.updated; e != a {
+			t.Errorf("%s: expected update %v, got %v", name, e, a)
+		}
+		if metrics.namespace != api.NamespaceDefault || metrics.selector.String() != "name=frontend" {
+			t.Errorf("%s: unexpected metrics query %q %v", name, metrics.namespace, metrics.selector)
+		}
+		updated, ok := autoscalers.Object.(*api.HorizontalPodAutoscaler)
+		if !ok {
+			t.Errorf("%s: expected the autoscaler status to be updated", name)
+			continue
+		}
+		expectStatus := api.HorizontalPodAutoscalerStatus{
+			CurrentReplicas:       item.replicas,
+			DesiredReplicas:       item.expected,
+			CurrentCPUUtilization: item.utilization,
+		}
+		if updated.Status != expectStatus {
+			t.Errorf("%s: expected status %#v, got %#v", name, expectStatus, updated.Status)
+		}
+	}
+}
+
+func TestHPAControllerMetricsError(t *testing.T) {
+	autoscalers, controllers, metrics, c := newTestController(2, 100)
+	metrics.err = fmt.Errorf("no metrics")
+	c.reconcileAutoscalers()
+	if controllers.updated {
+		t.Errorf("unexpected update of %#v", controllers.controller)
+	}
+	if autoscalers.Object != nil {
+		t.Errorf("unexpected update of %#v", autoscalers.Object)
+	}
+}
+
+func TestHPAControllerUnchangedStatus(t *testing.T) {
+	autoscalers, controllers, _, c := newTestController(2, 50)
+	list := autoscalers.ObjectList.(*api.HorizontalPodAutoscalerList)
+	list.Items[0].Status = api.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 2, CurrentCPUUtilization: 50}
+	c.reconcileAutoscalers()
+	if controllers.updated || autoscalers.Object != nil {
+		t.Errorf("expected no updates")
+	}
+}
@@ -0,0 +1,403 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"code.google.com/p/go-uuid/uuid"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/service"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// eventSource is the source of the events the controller records.
+const eventSource = "ingress-controller"
+
+// nginxConfig renders the routes of all ingresses as nginx upstream and server
+// blocks, to be included in the http block of the nginx configuration.
+var nginxConfig = template.Must(template.New("nginx").Parse(`# Generated by the ingress controller. Changes will be overwritten.
+{{range .Upstreams}}
+upstream {{.Name}} {
+{{range .Servers}}	server {{.}};
+{{end}}}
+{{end}}{{range .Servers}}
+server {
+	listen 80{{if not .Host}} default_server{{end}};
+{{with .TLS}}	listen 443 ssl;
+	ssl_certificate {{.Certificate}};
+	ssl_certificate_key {{.Key}};
+{{end}}	server_name {{if .Host}}{{.Host}}{{else}}_{{end}};
+{{range .Locations}}
+	location {{.Path}} {
+		proxy_pass http://{{.Upstream}};
+	}
+{{end}}}
+{{end}}`))
+
+type upstream struct {
+	Name    string
+	Servers []string
+}
+
+type location struct {
+	Path     string
+	Upstream string
+}
+
+// certificate holds the paths of a certificate and its private key.
+type certificate struct {
+	Certificate string
+	Key         string
+}
+
+type server struct {
+	Host      string
+	TLS       *certificate
+	Locations []location
+}
+
+// IngressController writes the routes of every ingress to an nginx
+// configuration file, and reloads nginx when they change. The certificates of
+// hosts which serve HTTPS are written next to the configuration file.
+type IngressController struct {
+	ingresses  generic.Registry
+	services   service.Registry
+	secrets    generic.Registry
+	events     generic.Registry
+	configPath string
+	reload     func() error
+
+	lock sync.Mutex
+	// config is the configuration last written to configPath.
+	config string
+	// files holds the certificate files last written, by path.
+	files map[string][]byte
+	// unresolved holds the backends which could not be resolved on the last
+	// sync, so that an event is only recorded when a backend stops resolving.
+	unresolved util.StringSet
+}
+
+// NewIngressController returns a controller which routes requests to the
+// services in services according to the ingresses in ingresses, serving HTTPS
+// with the certificates in secrets. It writes the routes to configPath and
+// then runs reloadCommand, if any. Events about backends and secrets which
+// cannot be resolved are recorded in events.
+func NewIngressController(ingresses generic.Registry, services service.Registry, secrets generic.Registry, events generic.Registry, configPath string, reloadCommand []string) *IngressController {
+	return &IngressController{
+		ingresses:  ingresses,
+		services:   services,
+		secrets:    secrets,
+		events:     events,
+		configPath: configPath,
+		reload: func() error {
+			if len(reloadCommand) == 0 {
+				return nil
+			}
+			out, err := exec.Command(reloadCommand[0], reloadCommand[1:]...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%v: %s", err, out)
+			}
+			return nil
+		},
+		unresolved: util.StringSet{},
+	}
+}
+
+// Run syncs the routes whenever an ingress changes, and once per period so
+// that changes to services and their endpoints are picked up, until stopCh
+// is closed.
+func (c *IngressController) Run(period time.Duration, stopCh <-chan struct{}) {
+	go util.Until(c.sync, period, stopCh)
+	util.Until(func() { c.watchIngresses(stopCh) }, time.Second, stopCh)
+}
+
+// watchIngresses syncs the routes on every change to an ingress, until the
+// watch ends or stopCh is closed.
+func (c *IngressController) watchIngresses(stopCh <-chan struct{}) {
+	w, err := c.ingresses.Watch(api.NewContext(), generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }), 0)
+	if err != nil {
+		glog.Errorf("Couldn't watch ingresses: %v", err)
+		return
+	}
+	defer w.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case _, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			c.sync()
+		}
+	}
+}
+
+func (c *IngressController) sync() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	obj, err := c.ingresses.List(api.NewContext(), generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }))
+	if err != nil {
+		glog.Errorf("Couldn't list ingresses: %v", err)
+		return
+	}
+	list, ok := obj.(*api.IngressList)
+	if !ok {
+		glog.Errorf("Unexpected ingress list: %#v", obj)
+		return
+	}
+	config, files, err := c.render(list.Items)
+	if err != nil {
+		glog.Errorf("Couldn't render the nginx configuration: %v", err)
+		return
+	}
+	if config == c.config && reflect.DeepEqual(files, c.files) {
+		return
+	}
+	for path, data := range files {
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			glog.Errorf("Couldn't write %s: %v", path, err)
+			return
+		}
+	}
+	if err := ioutil.WriteFile(c.configPath, []byte(config), 0644); err != nil {
+		glog.Errorf("Couldn't write the nginx configuration to %s: %v", c.configPath, err)
+		return
+	}
+	if err := c.reload(); err != nil {
+		glog.Errorf("Couldn't reload nginx: %v", err)
+		return
+	}
+	c.config = config
+	c.files = files
+}
+
+// render returns the nginx configuration for ingresses, and the certificate
+// files it refers to by path. Routes whose backend cannot be resolved, and
+// certificates whose secret cannot be resolved, are left out, and an event is
+// recorded for them. When several ingresses route the same host and path, or
+// serve a certificate for the same host, the first of them by namespace and
+// name wins.
+func (c *IngressController) render(ingresses []api.Ingress) (string, map[string][]byte, error) {
+	sort.Sort(byName(ingresses))
+	upstreams := map[string]upstream{}
+	servers := map[string]map[string]string{}
+	unresolved := util.StringSet{}
+	route := func(ingress *api.Ingress, host, path string, backend *api.IngressBackend) {
+		if _, ok := servers[host][path]; ok {
+			return
+		}
+		up, reason, err := c.resolve(ingress.Namespace, backend)
+		if err != nil {
+			key := fmt.Sprintf("%s/%s/%s:%d", ingress.Namespace, ingress.ID, backend.ServiceName, backend.ServicePort)
+			if !unresolved.Has(key) && !c.unresolved.Has(key) {
+				c.recordEvent(ingress, "unresolvedBackend", reason, err.Error())
+			}
+			unresolved.Insert(key)
+			return
+		}
+		upstreams[up.Name] = up
+		if servers[host] == nil {
+			servers[host] = map[string]string{}
+		}
+		servers[host][path] = up.Name
+	}
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		hosts := []string{}
+		for _, rule := range ingress.Spec.Rules {
+			hosts = append(hosts, rule.Host)
+			for j := range rule.Paths {
+				route(ingress, rule.Host, rule.Paths[j].Path, &rule.Paths[j].Backend)
+			}
+		}
+		if ingress.Spec.Backend != nil {
+			for _, host := range append(hosts, "") {
+				route(ingress, host, "/", ingress.Spec.Backend)
+			}
+		}
+	}
+	certs := map[string]*certificate{}
+	files := map[string][]byte{}
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		for _, tls := range ingress.Spec.TLS {
+			cert, reason, err := c.certificate(ingress.Namespace, tls.SecretName, files)
+			if err != nil {
+				key := fmt.Sprintf("%s/%s/secret/%s", ingress.Namespace, ingress.ID, tls.SecretName)
+				if !unresolved.Has(key) && !c.unresolved.Has(key) {
+					c.recordEvent(ingress, "unresolvedSecret", reason, err.Error())
+				}
+				unresolved.Insert(key)
+				continue
+			}
+			for _, host := range tls.Hosts {
+				if _, ok := certs[host]; !ok {
+					certs[host] = cert
+				}
+			}
+		}
+	}
+	c.unresolved = unresolved
+
+	data := struct {
+		Upstreams []upstream
+		Servers   []server
+	}{}
+	names := util.StringSet{}
+	for name := range upstreams {
+		names.Insert(name)
+	}
+	for _, name := range names.List() {
+		data.Upstreams = append(data.Upstreams, upstreams[name])
+	}
+	hosts := util.StringSet{}
+	for host := range servers {
+		hosts.Insert(host)
+	}
+	for _, host := range hosts.List() {
+		paths := util.StringSet{}
+		for path := range servers[host] {
+			paths.Insert(path)
+		}
+		s := server{Host: host, TLS: certs[host]}
+		for _, path := range paths.List() {
+			s.Locations = append(s.Locations, location{Path: path, Upstream: servers[host][path]})
+		}
+		data.Servers = append(data.Servers, s)
+	}
+	var buf bytes.Buffer
+	if err := nginxConfig.Execute(&buf, data); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), files, nil
+}
+
+// certificate adds the certificate and private key held by the secret name in
+// namespace to files, and returns their paths, or the reason they cannot be
+// served and an error describing why.
+func (c *IngressController) certificate(namespace, name string, files map[string][]byte) (*certificate, string, error) {
+	ctx := api.WithNamespace(api.NewContext(), namespace)
+	obj, err := c.secrets.Get(ctx, name)
+	if errors.IsNotFound(err) {
+		return nil, "secretNotFound", fmt.Errorf("secret %q not found", name)
+	}
+	if err != nil {
+		return nil, "secretNotFound", fmt.Errorf("couldn't get secret %q: %v", name, err)
+	}
+	secret, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, "secretNotFound", fmt.Errorf("unexpected secret %#v", obj)
+	}
+	prefix := filepath.Join(filepath.Dir(c.configPath), fmt.Sprintf("%s-%s", namespace, name))
+	cert := &certificate{Certificate: prefix + ".crt", Key: prefix + ".key"}
+	certData, err := base64.StdEncoding.DecodeString(secret.Data[api.TLSCertKey])
+	if err != nil || len(certData) == 0 {
+		return nil, "invalidSecret", fmt.Errorf("secret %q has no valid %q", name, api.TLSCertKey)
+	}
+	keyData, err := base64.StdEncoding.DecodeString(secret.Data[api.TLSPrivateKeyKey])
+	if err != nil || len(keyData) == 0 {
+		return nil, "invalidSecret", fmt.Errorf("secret %q has no valid %q", name, api.TLSPrivateKeyKey)
+	}
+	files[cert.Certificate] = certData
+	files[cert.Key] = keyData
+	return cert, "", nil
+}
+
+// resolve returns the upstream which serves backend in namespace, or the
+// reason it cannot be served and an error describing why.
+func (c *IngressController) resolve(namespace string, backend *api.IngressBackend) (upstream, string, error) {
+	ctx := api.WithNamespace(api.NewContext(), namespace)
+	svc, err := c.services.GetService(ctx, backend.ServiceName)
+	if errors.IsNotFound(err) {
+		return upstream{}, "serviceNotFound", fmt.Errorf("service %q not found", backend.ServiceName)
+	}
+	if err != nil {
+		return upstream{}, "serviceNotFound", fmt.Errorf("couldn't get service %q: %v", backend.ServiceName, err)
+	}
+	if svc.Port != backend.ServicePort {
+		return upstream{}, "portNotFound", fmt.Errorf("service %q does not serve port %d", backend.ServiceName, backend.ServicePort)
+	}
+	endpoints, err := c.services.GetEndpoints(ctx, backend.ServiceName)
+	if err != nil && !errors.IsNotFound(err) {
+		return upstream{}, "noEndpoints", fmt.Errorf("couldn't get the endpoints of service %q: %v", backend.ServiceName, err)
+	}
+	if endpoints == nil || len(endpoints.Endpoints) == 0 {
+		return upstream{}, "noEndpoints", fmt.Errorf("service %q has no endpoints", backend.ServiceName)
+	}
+	servers := append([]string{}, endpoints.Endpoints...)
+	sort.Strings(servers)
+	return upstream{
+		Name:    fmt.Sprintf("%s-%s-%d", namespace, backend.ServiceName, backend.ServicePort),
+		Servers: servers,
+	}, "", nil
+}
+
+// recordEvent records that a backend or secret of ingress could not be
+// resolved.
+func (c *IngressController) recordEvent(ingress *api.Ingress, status, reason, message string) {
+	glog.Infof("Ingress %s: %s", ingress.ID, message)
+	event := &api.Event{
+		TypeMeta: api.TypeMeta{
+			ID:                uuid.NewUUID().String(),
+			Namespace:         ingress.Namespace,
+			CreationTimestamp: util.Now(),
+		},
+		InvolvedObject: api.ObjectReference{
+			Kind:       "Ingress",
+			Namespace:  ingress.Namespace,
+			Name:       ingress.ID,
+			UID:        ingress.UID,
+			APIVersion: latest.Version,
+		},
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+		Source:  eventSource,
+	}
+	ctx := api.WithNamespace(api.NewContext(), ingress.Namespace)
+	if err := c.events.Create(ctx, event.ID, event); err != nil {
+		glog.Errorf("Couldn't record an event for ingress %s: %v", ingress.ID, err)
+	}
+}
+
+type byName []api.Ingress
+
+func (s byName) Len() int      { return len(s) }
+func (s byName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byName) Less(i, j int) bool {
+	if s[i].Namespace != s[j].Namespace {
+		return s[i].Namespace < s[j].Namespace
+	}
+	return s[i].ID < s[j].ID
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// fakeServiceRegistry holds services and their endpoints by name.
+type fakeServiceRegistry struct {
+	registrytest.ServiceRegistry
+	services  map[string]*api.Service
+	endpoints map[string]*api.Endpoints
+}
+
+func (r *fakeServiceRegistry) GetService(ctx api.Context, id string) (*api.Service, error) {
+	svc, ok := r.services[id]
+	if !ok {
+		return nil, errors.NewNotFound("service", id)
+	}
+	return svc, nil
+}
+
+func (r *fakeServiceRegistry) GetEndpoints(ctx api.Context, id string) (*api.Endpoints, error) {
+	endpoints, ok := r.endpoints[id]
+	if !ok {
+		return nil, errors.NewNotFound("endpoints", id)
+	}
+	return endpoints, nil
+}
+
+// fakeSecretRegistry holds secrets by name.
+type fakeSecretRegistry struct {
+	*registrytest.GenericRegistry
+	secrets map[string]*api.Secret
+}
+
+func (r *fakeSecretRegistry) Get(ctx api.Context, id string) (runtime.Object, error) {
+	secret, ok := r.secrets[id]
+	if !ok {
+		return nil, errors.NewNotFound("secret", id)
+	}
+	return secret, nil
+}
+
+// fakeEventRegistry records the events created through it.
+type fakeEventRegistry struct {
+	*registrytest.GenericRegistry
+	events []*api.Event
+}
+
+func (r *fakeEventRegistry) Create(ctx api.Context, id string, obj runtime.Object) error {
+	r.events = append(r.events, obj.(*api.Event))
+	return nil
+}
+
+type testController struct {
+	*IngressController
+	ingresses *registrytest.GenericRegistry
+	services  *fakeServiceRegistry
+	secrets   *fakeSecretRegistry
+	events    *fakeEventRegistry
+	reloads   int
+}
+
+func newTestController(t *testing.T, ingresses ...api.Ingress) (*testController, func()) {
+	dir, err := ioutil.TempDir("", "ingress")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	services := &fakeServiceRegistry{
+		services: map[string]*api.Service{
+			"frontend": {TypeMeta: api.TypeMeta{ID: "frontend"}, Port: 80},
+			"api":      {TypeMeta: api.TypeMeta{ID: "api"}, Port: 8080},
+			"idle":     {TypeMeta: api.TypeMeta{ID: "idle"}, Port: 80},
+		},
+		endpoints: map[string]*api.Endpoints{
+			"frontend": {Endpoints: []string{"10.0.0.2:80", "10.0.0.1:80"}},
+			"api":      {Endpoints: []string{"10.0.0.3:8080"}},
+			"idle":     {},
+		},
+	}
+	c := &testController{
+		ingresses: registrytest.NewGeneric(&api.IngressList{Items: ingresses}),
+		services:  services,
+		secrets: &fakeSecretRegistry{
+			GenericRegistry: registrytest.NewGeneric(nil),
+			secrets: map[string]*api.Secret{
+				"foo-tls": {
+					TypeMeta: api.TypeMeta{ID: "foo-tls"},
+					Data: map[string]string{
+						api.TLSCertKey:       base64.StdEncoding.EncodeToString([]byte("cert")),
+						api.TLSPrivateKeyKey: base64.StdEncoding.EncodeToString([]byte("key")),
+					},
+				},
+				"empty": {TypeMeta: api.TypeMeta{ID: "empty"}},
+			},
+		},
+		events: &fakeEventRegistry{GenericRegistry: registrytest.NewGeneric(nil)},
+	}
+	c.IngressController = NewIngressController(c.ingresses, services, c.secrets, c.events, filepath.Join(dir, "ingress.conf"), nil)
+	c.reload = func() error {
+		c.reloads++
+		return nil
+	}
+	return c, func() { os.RemoveAll(dir) }
+}
+
+func (c *testController) readConfig(t *testing.T) string {
+	data, err := ioutil.ReadFile(c.configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(data)
+}
+
+func testIngress(id string, spec api.IngressSpec) api.Ingress {
+	return api.Ingress{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Spec:     spec,
+	}
+}
+
+func TestIngressControllerWritesConfig(t *testing.T) {
+	c, cleanup := newTestController(t,
+		testIngress("site", api.IngressSpec{
+			Backend: &api.IngressBackend{ServiceName: "frontend", ServicePort: 80},
+			Rules: []api.IngressRule{{
+				Host:  "foo.example.com",
+				Paths: []api.IngressPath{{Path: "/api", Backend: api.IngressBackend{ServiceName: "api", ServicePort: 8080}}},
+			}},
+		}),
+		// The site ingress already routes this host and path.
+		testIngress("takeover", api.IngressSpec{
+			Rules: []api.IngressRule{{
+				Host:  "foo.example.com",
+				Paths: []api.IngressPath{{Path: "/api", Backend: api.IngressBackend{ServiceName: "frontend", ServicePort: 80}}},
+			}},
+		}),
+	)
+	defer cleanup()
+	c.sync()
+
+	expected := `# Generated by the ingress controller. Changes will be overwritten.
+
+upstream default-api-8080 {
+	server 10.0.0.3:8080;
+}
+
+upstream default-frontend-80 {
+	server 10.0.0.1:80;
+	server 10.0.0.2:80;
+}
+
+server {
+	listen 80 default_server;
+	server_name _;
+
+	location / {
+		proxy_pass http://default-frontend-80;
+	}
+}
+
+server {
+	listen 80;
+	server_name foo.example.com;
+
+	location / {
+		proxy_pass http://default-frontend-80;
+	}
+
+	location /api {
+		proxy_pass http://default-api-8080;
+	}
+}
+`
+	if actual := c.readConfig(t); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+	if c.reloads != 1 {
+		t.Errorf("expected 1 reload, got %d", c.reloads)
+	}
+	if len(c.events.events) != 0 {
+		t.Errorf("unexpected events %#v", c.events.events)
+	}
+
+	c.sync()
+	if c.reloads != 1 {
+		t.Errorf("expected no reload when nothing changed, got %d", c.reloads)
+	}
+}
+
+func TestIngressControllerUnresolvedBackends(t *testing.T) {
+	c, cleanup := newTestController(t, testIngress("broken", api.IngressSpec{
+		Rules: []api.IngressRule{{
+			Paths: []api.IngressPath{
+				{Path: "/missing", Backend: api.IngressBackend{ServiceName: "missing", ServicePort: 80}},
+				{Path: "/port", Backend: api.IngressBackend{ServiceName: "api", ServicePort: 80}},
+				{Path: "/idle", Backend: api.IngressBackend{ServiceName: "idle", ServicePort: 80}},
+			},
+		}},
+	}))
+	defer cleanup()
+	c.sync()
+
+	expected := "# Generated by the ingress controller. Changes will be overwritten.\n"
+	if actual := c.readConfig(t); actual != expected {
+		t.Errorf("expected no routes, got:\n%s", actual)
+	}
+	reasons := []string{}
+	for _, event := range c.events.events {
+		reasons = append(reasons, event.Reason)
+		if event.InvolvedObject.Kind != "Ingress" || event.InvolvedObject.Name != "broken" || event.Namespace != api.NamespaceDefault {
+			t.Errorf("unexpected event %#v", event)
+		}
+		if event.Status != "unresolvedBackend" || event.Source != eventSource || event.Message == "" {
+			t.Errorf("unexpected event %#v", event)
+		}
+	}
+	if e, a := []string{"serviceNotFound", "portNotFound", "noEndpoints"}, reasons; len(a) != len(e) || a[0] != e[0] || a[1] != e[1] || a[2] != e[2] {
+		t.Errorf("expected events for %v, got %v", e, a)
+	}
+
+	c.sync()
+	if len(c.events.events) != 3 {
+		t.Errorf("expected no more events for the same backends, got %d", len(c.events.events))
+	}
+
+	c.services.endpoints["idle"] = &api.Endpoints{Endpoints: []string{"10.0.0.4:80"}}
+	c.sync()
+	delete(c.services.endpoints, "idle")
+	c.sync()
+	if len(c.events.events) != 4 {
+		t.Errorf("expected an event when a backend stops resolving again, got %d", len(c.events.events))
+	}
+}
+
+func TestIngressControllerTLS(t *testing.T) {
+	c, cleanup := newTestController(t, testIngress("site", api.IngressSpec{
+		Rules: []api.IngressRule{
+			{Host: "foo.example.com", Paths: []api.IngressPath{{Path: "/", Backend: api.IngressBackend{ServiceName: "frontend", ServicePort: 80}}}},
+			{Host: "bar.example.com", Paths: []api.IngressPath{{Path: "/", Backend: api.IngressBackend{ServiceName: "frontend", ServicePort: 80}}}},
+		},
+		TLS: []api.IngressTLS{
+			{Hosts: []string{"foo.example.com"}, SecretName: "foo-tls"},
+			{Hosts: []string{"bar.example.com"}, SecretName: "missing"},
+			{Hosts: []string{"bar.example.com"}, SecretName: "empty"},
+		},
+	}))
+	defer cleanup()
+	c.sync()
+
+	dir := filepath.Dir(c.configPath)
+	expected := `# Generated by the ingress controller. Changes will be overwritten.
+
+upstream default-frontend-80 {
+	server 10.0.0.1:80;
+	server 10.0.0.2:80;
+}
+
+server {
+	listen 80;
+	server_name bar.example.com;
+
+	location / {
+		proxy_pass http://default-frontend-80;
+	}
+}
+
+server {
+	listen 80;
+	listen 443 ssl;
+	ssl_certificate ` + dir + `/default-foo-tls.crt;
+	ssl_certificate_key ` + dir + `/default-foo-tls.key;
+	server_name foo.example.com;
+
+	location / {
+		proxy_pass http://default-frontend-80;
+	}
+}
+`
+	if actual := c.readConfig(t); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+	for file, content := range map[string]string{"default-foo-tls.crt": "cert", "default-foo-tls.key": "key"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("expected %s to hold %q, got %q", file, content, data)
+		}
+	}
+	reasons := []string{}
+	for _, event := range c.events.events {
+		if event.Status != "unresolvedSecret" {
+			t.Errorf("unexpected event %#v", event)
+		}
+		reasons = append(reasons, event.Reason)
+	}
+	if e, a := []string{"secretNotFound", "invalidSecret"}, reasons; len(a) != len(e) || a[0] != e[0] || a[1] != e[1] {
+		t.Errorf("expected events for %v, got %v", e, a)
+	}
+
+	c.secrets.secrets["foo-tls"].Data[api.TLSCertKey] = base64.StdEncoding.EncodeToString([]byte("renewed"))
+	c.sync()
+	if c.reloads != 2 {
+		t.Errorf("expected a reload when a certificate changes, got %d", c.reloads)
+	}
+}
+
+func TestIngressControllerWatch(t *testing.T) {
+	c, cleanup := newTestController(t)
+	defer cleanup()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.watchIngresses(stopCh)
+
+	ingress := testIngress("site", api.IngressSpec{
+		Backend: &api.IngressBackend{ServiceName: "frontend", ServicePort: 80},
+	})
+	for i := 0; i < 50; i++ {
+		c.ingresses.Lock()
+		c.ingresses.ObjectList = &api.IngressList{Items: []api.Ingress{ingress}}
+		c.ingresses.Unlock()
+		c.ingresses.Mux.Action(watch.Added, &ingress)
+		c.lock.Lock()
+		synced := c.config != ""
+		c.lock.Unlock()
+		if synced {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected a change to an ingress to be synced")
+}
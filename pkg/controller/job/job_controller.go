@@ -0,0 +1,231 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"code.google.com/p/go-uuid/uuid"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+const (
+	// failureBackoff is how long a job waits after a pod fails before it
+	// replaces the pod. The wait doubles with every further failed pod, up
+	// to maxFailureBackoff.
+	failureBackoff    = 10 * time.Second
+	maxFailureBackoff = 5 * time.Minute
+)
+
+type clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// JobController runs the pods of every job until enough of them succeed.
+type JobController struct {
+	jobs       generic.Registry
+	pods       pod.Registry
+	podCreater controller.PodCreater
+	podInfo    client.PodInfoGetter
+	clock      clock
+}
+
+// NewJobController returns a controller which creates the pods of the jobs
+// in jobs through podCreater, finds them in pods, and learns whether they
+// succeeded from podInfo.
+func NewJobController(jobs generic.Registry, pods pod.Registry, podCreater controller.PodCreater, podInfo client.PodInfoGetter) *JobController {
+	return &JobController{
+		jobs:       jobs,
+		pods:       pods,
+		podCreater: podCreater,
+		podInfo:    podInfo,
+		clock:      realClock{},
+	}
+}
+
+// Run syncs every job once per period until stopCh is closed.
+func (c *JobController) Run(period time.Duration, stopCh <-chan struct{}) {
+	util.Until(c.syncJobs, period, stopCh)
+}
+
+func (c *JobController) syncJobs() {
+	obj, err := c.jobs.List(api.NewContext(), generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }))
+	if err != nil {
+		glog.Errorf("Couldn't list jobs: %v", err)
+		return
+	}
+	list, ok := obj.(*api.JobList)
+	if !ok {
+		glog.Errorf("Unexpected job list: %#v", obj)
+		return
+	}
+	for i := range list.Items {
+		if err := c.syncJob(&list.Items[i]); err != nil {
+			glog.Errorf("Couldn't sync job %s: %v", list.Items[i].ID, err)
+		}
+	}
+}
+
+// syncJob counts the pods of job by outcome, creates pods until as many run
+// as its parallelism allows and its remaining completions need, and records
+// what it observed in the status of job. Failed pods are replaced only once
+// the backoff for the number of failures has passed since the last one.
+func (c *JobController) syncJob(job *api.Job) error {
+	if job.Status.Complete {
+		return nil
+	}
+	ctx := api.WithNamespace(api.NewContext(), job.Namespace)
+	pods, err := c.pods.ListPodsPredicate(ctx, func(pod *api.Pod) bool {
+		return ownedBy(pod, job)
+	})
+	if err != nil {
+		return err
+	}
+	status := api.JobStatus{}
+	var lastFailure time.Time
+	for i := range pods.Items {
+		finished, succeeded, finishedAt := c.podOutcome(&pods.Items[i])
+		switch {
+		case !finished:
+			status.Active++
+		case succeeded:
+			status.Succeeded++
+		default:
+			status.Failed++
+			if finishedAt.After(lastFailure) {
+				lastFailure = finishedAt
+			}
+		}
+	}
+
+	if status.Succeeded >= job.Spec.Completions {
+		status.Complete = true
+	} else {
+		wanted := job.Spec.Completions - status.Succeeded
+		if wanted > job.Spec.Parallelism {
+			wanted = job.Spec.Parallelism
+		}
+		missing := wanted - status.Active
+		if missing > 0 && status.Failed > 0 {
+			if retryAt := lastFailure.Add(backoff(status.Failed)); c.clock.Now().Before(retryAt) {
+				glog.V(4).Infof("Job %s backing off until %v after %d failed pods", job.ID, retryAt, status.Failed)
+				missing = 0
+			}
+		}
+		for i := 0; i < missing; i++ {
+			if err := c.createPod(ctx, job); err != nil {
+				glog.Errorf("Couldn't create a pod of job %s: %v", job.ID, err)
+				break
+			}
+			status.Active++
+		}
+	}
+
+	if status == job.Status {
+		return nil
+	}
+	job.Status = status
+	return c.jobs.Update(ctx, job.ID, job)
+}
+
+// podOutcome reports whether every container of pod has terminated, whether
+// they all exited successfully, and when the last of them finished. Pods
+// which restart their failed containers only finish by succeeding.
+func (c *JobController) podOutcome(pod *api.Pod) (finished, succeeded bool, finishedAt time.Time) {
+	if pod.DesiredState.Host == "" {
+		return false, false, finishedAt
+	}
+	info, err := c.podInfo.GetPodInfo(pod.DesiredState.Host, pod.Namespace, pod.ID)
+	if err != nil {
+		if err != client.ErrPodInfoNotAvailable {
+			glog.Errorf("Couldn't get the info of pod %s: %v", pod.ID, err)
+		}
+		return false, false, finishedAt
+	}
+	succeeded = true
+	for _, container := range pod.DesiredState.Manifest.Containers {
+		termination := info[container.Name].State.Termination
+		if termination == nil {
+			return false, false, finishedAt
+		}
+		if termination.ExitCode != 0 {
+			succeeded = false
+		}
+		if termination.FinishedAt.After(finishedAt) {
+			finishedAt = termination.FinishedAt
+		}
+	}
+	if !succeeded && pod.DesiredState.Manifest.RestartPolicy.OnFailure != nil {
+		return false, false, finishedAt
+	}
+	return true, succeeded, finishedAt
+}
+
+// backoff returns how long to wait before replacing a pod once failed pods
+// have failed.
+func backoff(failed int) time.Duration {
+	wait := failureBackoff
+	for i := 1; i < failed && wait < maxFailureBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxFailureBackoff {
+		wait = maxFailureBackoff
+	}
+	return wait
+}
+
+// createPod creates a pod from the template of job.
+func (c *JobController) createPod(ctx api.Context, job *api.Job) error {
+	pod := &api.Pod{
+		TypeMeta:     api.TypeMeta{ID: fmt.Sprintf("%s-%s", job.ID, uuid.NewUUID().String()), Namespace: job.Namespace},
+		DesiredState: job.Spec.Template.DesiredState,
+		Labels:       job.Spec.Template.Labels,
+	}
+	if err := controller.SetCreatedBy(pod, controller.CreatedByReference("Job", &job.TypeMeta)); err != nil {
+		return err
+	}
+	glog.Infof("Job %s creating pod %s", job.ID, pod.ID)
+	return controller.CreatePod(ctx, c.podCreater, pod)
+}
+
+// ownedBy returns whether pod was created for job.
+func ownedBy(pod *api.Pod, job *api.Job) bool {
+	value, ok := pod.Annotations[controller.CreatedByAnnotation]
+	if !ok {
+		return false
+	}
+	var ref api.ObjectReference
+	if err := json.Unmarshal([]byte(value), &ref); err != nil {
+		return false
+	}
+	return ref.Kind == "Job" && ref.Namespace == job.Namespace && ref.Name == job.ID
+}
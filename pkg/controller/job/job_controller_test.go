@@ -0,0 +1,257 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+)
+
+// fakePodRegistry adds the pods created through it to the pods it lists, as
+// if they had been scheduled on a minion.
+type fakePodRegistry struct {
+	*registrytest.PodRegistry
+	created int
+}
+
+func (r *fakePodRegistry) CreatePod(ctx api.Context, pod *api.Pod) error {
+	r.created++
+	pod.DesiredState.Host = "machine"
+	r.Pods.Items = append(r.Pods.Items, *pod)
+	return r.Err
+}
+
+// fakePodInfoGetter returns the info of pods by their ID.
+type fakePodInfoGetter map[string]api.PodInfo
+
+func (f fakePodInfoGetter) GetPodInfo(host, namespace, id string) (api.PodInfo, error) {
+	info, ok := f[id]
+	if !ok {
+		return nil, client.ErrPodInfoNotAvailable
+	}
+	return info, nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func newTestJob(completions, parallelism int) *api.Job {
+	return &api.Job{
+		TypeMeta: api.TypeMeta{ID: "batch", Namespace: api.NamespaceDefault, UID: "1234"},
+		Spec: api.JobSpec{
+			Completions: completions,
+			Parallelism: parallelism,
+			Template: api.PodTemplate{
+				DesiredState: api.PodState{
+					Manifest: api.ContainerManifest{
+						Version:       "v1beta1",
+						Containers:    []api.Container{{Name: "worker", Image: "worker"}},
+						RestartPolicy: api.RestartPolicy{Never: &api.RestartPolicyNever{}},
+					},
+				},
+				Labels: map[string]string{"name": "batch"},
+			},
+		},
+	}
+}
+
+type testController struct {
+	*JobController
+	jobs    *registrytest.GenericRegistry
+	pods    *fakePodRegistry
+	podInfo fakePodInfoGetter
+	clock   *fakeClock
+}
+
+func newTestController(job *api.Job) *testController {
+	jobs := registrytest.NewGeneric(&api.JobList{Items: []api.Job{*job}})
+	pods := &fakePodRegistry{PodRegistry: registrytest.NewPodRegistry(&api.PodList{})}
+	podInfo := fakePodInfoGetter{}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	c := NewJobController(jobs, pods, pod.NewREST(&pod.RESTConfig{Registry: pods}), podInfo)
+	c.clock = clock
+	return &testController{c, jobs, pods, podInfo, clock}
+}
+
+// sync runs the controller once, and feeds the status it records back into
+// the job it lists next time.
+func (c *testController) sync() api.JobStatus {
+	c.jobs.Object = nil
+	c.syncJobs()
+	list := c.jobs.ObjectList.(*api.JobList)
+	if updated, ok := c.jobs.Object.(*api.Job); ok {
+		list.Items[0] = *updated
+	}
+	return list.Items[0].Status
+}
+
+// finish terminates the active pods of the job, the first failures of them
+// with a non-zero exit code, the rest successfully.
+func (c *testController) finish(failures int) {
+	for _, pod := range c.pods.Pods.Items {
+		if _, ok := c.podInfo[pod.ID]; ok {
+			continue
+		}
+		exitCode := 0
+		if failures > 0 {
+			exitCode = 1
+			failures--
+		}
+		c.podInfo[pod.ID] = api.PodInfo{
+			"worker": api.ContainerStatus{
+				State: api.ContainerState{
+					Termination: &api.ContainerStateTerminated{ExitCode: exitCode, FinishedAt: c.clock.now},
+				},
+			},
+		}
+	}
+}
+
+func TestJobControllerCompletes(t *testing.T) {
+	c := newTestController(newTestJob(3, 2))
+
+	status := c.sync()
+	if e := (api.JobStatus{Active: 2}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+	if c.pods.created != 2 {
+		t.Errorf("expected 2 pods, created %d", c.pods.created)
+	}
+	for _, pod := range c.pods.Pods.Items {
+		if !strings.HasPrefix(pod.ID, "batch-") || pod.Labels["name"] != "batch" {
+			t.Errorf("unexpected pod %#v", pod)
+		}
+		if pod.DesiredState.Manifest.UUID == "" || pod.CreationTimestamp.IsZero() {
+			t.Errorf("expected the pod to be created through the pods storage, got %#v", pod)
+		}
+		if len(pod.OwnerReferences) != 1 || pod.OwnerReferences[0].Kind != "Job" || pod.OwnerReferences[0].Name != "batch" {
+			t.Errorf("expected the pod to be owned by the job, got %#v", pod.OwnerReferences)
+		}
+	}
+	if c.pods.Pods.Items[0].ID == c.pods.Pods.Items[1].ID {
+		t.Errorf("expected the pods to have different names, got %s", c.pods.Pods.Items[0].ID)
+	}
+
+	c.finish(0)
+	status = c.sync()
+	if e := (api.JobStatus{Active: 1, Succeeded: 2}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+	if c.pods.created != 3 {
+		t.Errorf("expected only the last completion to be started, created %d", c.pods.created)
+	}
+
+	c.finish(0)
+	status = c.sync()
+	if e := (api.JobStatus{Succeeded: 3, Complete: true}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+
+	c.sync()
+	if c.jobs.Object != nil || c.pods.created != 3 {
+		t.Errorf("expected a complete job to be left alone")
+	}
+}
+
+func TestJobControllerBacksOffFailures(t *testing.T) {
+	c := newTestController(newTestJob(1, 1))
+	c.sync()
+
+	c.finish(1)
+	c.clock.now = c.clock.now.Add(failureBackoff - time.Second)
+	status := c.sync()
+	if e := (api.JobStatus{Failed: 1}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+	if c.pods.created != 1 {
+		t.Errorf("expected the failed pod not to be replaced yet, created %d", c.pods.created)
+	}
+
+	c.clock.now = c.clock.now.Add(time.Second)
+	status = c.sync()
+	if e := (api.JobStatus{Active: 1, Failed: 1}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+
+	c.finish(1)
+	c.clock.now = c.clock.now.Add(2*failureBackoff - time.Second)
+	c.sync()
+	if c.pods.created != 2 {
+		t.Errorf("expected the backoff to double, created %d", c.pods.created)
+	}
+	c.clock.now = c.clock.now.Add(time.Second)
+	c.sync()
+	if c.pods.created != 3 {
+		t.Errorf("expected the second failed pod to be replaced, created %d", c.pods.created)
+	}
+
+	c.finish(0)
+	status = c.sync()
+	if e := (api.JobStatus{Succeeded: 1, Failed: 2, Complete: true}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+}
+
+func TestJobControllerRestartsOnFailure(t *testing.T) {
+	job := newTestJob(1, 1)
+	job.Spec.Template.DesiredState.Manifest.RestartPolicy = api.RestartPolicy{OnFailure: &api.RestartPolicyOnFailure{}}
+	c := newTestController(job)
+	c.sync()
+
+	c.finish(1)
+	status := c.sync()
+	if e := (api.JobStatus{Active: 1}); status != e {
+		t.Errorf("expected a pod restarting on failure to stay active, got %#v", status)
+	}
+	if c.pods.created != 1 {
+		t.Errorf("unexpected replacement, created %d", c.pods.created)
+	}
+}
+
+func TestJobControllerIgnoresOtherPods(t *testing.T) {
+	c := newTestController(newTestJob(1, 1))
+	c.pods.Pods.Items = []api.Pod{{TypeMeta: api.TypeMeta{ID: "unowned", Namespace: api.NamespaceDefault}}}
+	status := c.sync()
+	if e := (api.JobStatus{Active: 1}); status != e {
+		t.Errorf("expected %#v, got %#v", e, status)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	table := map[int]time.Duration{
+		1:  failureBackoff,
+		2:  2 * failureBackoff,
+		3:  4 * failureBackoff,
+		20: maxFailureBackoff,
+	}
+	for failed, expected := range table {
+		if actual := backoff(failed); actual != expected {
+			t.Errorf("%d failures: expected %v, got %v", failed, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/minion"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// OutOfServiceAnnotation is set on a minion, with the value
+// NodeShutdownOutOfService, once an operator has made sure the minion is shut
+// down and its pods are no longer running.
+const OutOfServiceAnnotation = "node.kubernetes.io/out-of-service"
+
+// NodeShutdownOutOfService is the value of OutOfServiceAnnotation for minions
+// which were shut down without draining them first.
+const NodeShutdownOutOfService = "nodeshutdown"
+
+type clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NodeLifecycleController marks minions whose kubelet has not reported their
+// Ready condition for a while as not ready, and deletes the pods bound to
+// minions which are not ready.
+type NodeLifecycleController struct {
+	minions     minion.Registry
+	pods        pod.Registry
+	gracePeriod time.Duration
+	clock       clock
+
+	// EnableNonGracefulNodeShutdown makes the controller delete the pods of
+	// minions marked with OutOfServiceAnnotation straight away, without waiting
+	// for the minion to miss its heartbeats.
+	EnableNonGracefulNodeShutdown bool
+}
+
+// NewNodeLifecycleController returns a controller which watches over the
+// minions in minions, and deletes their pods through pods. A minion whose
+// Ready condition was last reported more than gracePeriod ago is taken not to
+// be ready.
+func NewNodeLifecycleController(minions minion.Registry, pods pod.Registry, gracePeriod time.Duration) *NodeLifecycleController {
+	return &NodeLifecycleController{
+		minions:     minions,
+		pods:        pods,
+		gracePeriod: gracePeriod,
+		clock:       realClock{},
+	}
+}
+
+// Run checks every minion once per period until stopCh is closed.
+func (c *NodeLifecycleController) Run(period time.Duration, stopCh <-chan struct{}) {
+	util.Until(c.monitorNodes, period, stopCh)
+}
+
+func (c *NodeLifecycleController) monitorNodes() {
+	minions, err := c.minions.ListMinions(api.NewContext(), labels.Everything())
+	if err != nil {
+		glog.Errorf("Couldn't list minions: %v", err)
+		return
+	}
+	for i := range minions.Items {
+		if err := c.monitorNode(&minions.Items[i]); err != nil {
+			glog.Errorf("Couldn't check minion %s: %v", minions.Items[i].ID, err)
+		}
+	}
+}
+
+// monitorNode sets the Ready condition of minion to Unknown once it has not
+// been reported for the grace period, and deletes the pods of minion if it is
+// not ready. Minions which have never reported their Ready condition are left
+// alone, since their kubelet may not report status at all.
+func (c *NodeLifecycleController) monitorNode(minion *api.Minion) error {
+	if c.EnableNonGracefulNodeShutdown && minion.Annotations[OutOfServiceAnnotation] == NodeShutdownOutOfService {
+		return c.evictPods(minion.ID)
+	}
+	ready := readyCondition(&minion.Status)
+	if ready == nil {
+		return nil
+	}
+	now := c.clock.Now()
+	if ready.Status != api.ConditionUnknown && now.Sub(ready.LastHeartbeatTime.Time) > c.gracePeriod {
+		glog.Infof("Minion %s has not reported its status since %v, marking it not ready", minion.ID, ready.LastHeartbeatTime)
+		ready.Status = api.ConditionUnknown
+		ready.LastTransitionTime = util.Time{Time: now}
+		ready.Reason = "NodeStatusUnknown"
+		ready.Message = fmt.Sprintf("Kubelet stopped reporting status for more than %v", c.gracePeriod)
+		if err := c.minions.UpdateMinion(api.NewContext(), minion); err != nil {
+			return err
+		}
+	}
+	if ready.Status == api.ConditionTrue {
+		return nil
+	}
+	return c.evictPods(minion.ID)
+}
+
+// evictPods deletes the pods bound to host, so that the controllers which own
+// them replace them on other minions.
+func (c *NodeLifecycleController) evictPods(host string) error {
+	pods, err := c.pods.ListPodsPredicate(api.NewContext(), func(pod *api.Pod) bool {
+		return pod.DesiredState.Host == host
+	})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		glog.Infof("Deleting pod %s from minion %s, which is not ready", pod.ID, host)
+		ctx := api.WithNamespace(api.NewContext(), pod.Namespace)
+		if err := c.pods.DeletePod(ctx, pod.ID); err != nil {
+			glog.Errorf("Couldn't delete pod %s: %v", pod.ID, err)
+		}
+	}
+	return nil
+}
+
+// readyCondition returns the Ready condition in status, or nil if it has none.
+func readyCondition(status *api.NodeStatus) *api.NodeCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == api.NodeReady {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
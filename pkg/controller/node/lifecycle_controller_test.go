@@ -0,0 +1,162 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// fakePodRegistry records the pods deleted through it.
+type fakePodRegistry struct {
+	*registrytest.PodRegistry
+	deleted []string
+}
+
+func (r *fakePodRegistry) DeletePod(ctx api.Context, podID string) error {
+	r.deleted = append(r.deleted, podID)
+	return r.Err
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+const testGracePeriod = 40 * time.Second
+
+// newTestMinion returns a minion whose Ready condition has status and was
+// last reported heartbeat ago.
+func newTestMinion(id string, status api.ConditionStatus, heartbeat time.Duration, now time.Time) api.Minion {
+	return api.Minion{
+		TypeMeta: api.TypeMeta{ID: id},
+		Status: api.NodeStatus{
+			Conditions: []api.NodeCondition{{
+				Type:              api.NodeReady,
+				Status:            status,
+				LastHeartbeatTime: util.Time{Time: now.Add(-heartbeat)},
+			}},
+		},
+	}
+}
+
+func newTestController(minions []api.Minion, pods []api.Pod) (*registrytest.MinionRegistry, *fakePodRegistry, *NodeLifecycleController) {
+	minionRegistry := registrytest.NewMinionRegistry(nil, api.NodeResources{})
+	minionRegistry.Minions.Items = minions
+	podRegistry := &fakePodRegistry{PodRegistry: registrytest.NewPodRegistry(&api.PodList{Items: pods})}
+	c := NewNodeLifecycleController(minionRegistry, podRegistry, testGracePeriod)
+	return minionRegistry, podRegistry, c
+}
+
+func testPod(id, host string) api.Pod {
+	return api.Pod{
+		TypeMeta:     api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		DesiredState: api.PodState{Host: host},
+	}
+}
+
+func TestNodeLifecycleController(t *testing.T) {
+	now := time.Unix(1000, 0)
+	minions := []api.Minion{
+		newTestMinion("healthy", api.ConditionTrue, testGracePeriod/2, now),
+		newTestMinion("silent", api.ConditionTrue, 2*testGracePeriod, now),
+		newTestMinion("failing", api.ConditionFalse, time.Second, now),
+		{TypeMeta: api.TypeMeta{ID: "unreported"}},
+	}
+	pods := []api.Pod{
+		testPod("a", "healthy"),
+		testPod("b", "silent"),
+		testPod("c", "failing"),
+		testPod("d", "unreported"),
+		testPod("e", ""),
+	}
+	minionRegistry, podRegistry, c := newTestController(minions, pods)
+	c.clock = &fakeClock{now: now}
+	c.monitorNodes()
+
+	sort.Strings(podRegistry.deleted)
+	if e, a := []string{"b", "c"}, podRegistry.deleted; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v to be deleted, got %v", e, a)
+	}
+	statuses := map[string]api.ConditionStatus{}
+	for _, minion := range minionRegistry.Minions.Items {
+		if ready := readyCondition(&minion.Status); ready != nil {
+			statuses[minion.ID] = ready.Status
+		}
+	}
+	expected := map[string]api.ConditionStatus{
+		"healthy": api.ConditionTrue,
+		"silent":  api.ConditionUnknown,
+		"failing": api.ConditionFalse,
+	}
+	if !reflect.DeepEqual(expected, statuses) {
+		t.Errorf("expected %v, got %v", expected, statuses)
+	}
+	ready := readyCondition(&minionRegistry.Minions.Items[1].Status)
+	if !ready.LastTransitionTime.Time.Equal(now) || ready.Reason != "NodeStatusUnknown" || ready.Message == "" {
+		t.Errorf("unexpected condition %#v", ready)
+	}
+}
+
+func TestNodeLifecycleControllerLeavesUnknownAlone(t *testing.T) {
+	now := time.Unix(1000, 0)
+	minion := newTestMinion("silent", api.ConditionUnknown, 2*testGracePeriod, now)
+	minion.Status.Conditions[0].LastTransitionTime = util.Time{Time: now.Add(-testGracePeriod)}
+	minionRegistry, podRegistry, c := newTestController([]api.Minion{minion}, []api.Pod{testPod("a", "silent")})
+	c.clock = &fakeClock{now: now}
+	c.monitorNodes()
+
+	if !reflect.DeepEqual(minion, minionRegistry.Minions.Items[0]) {
+		t.Errorf("expected the minion not to be updated again, got %#v", minionRegistry.Minions.Items[0])
+	}
+	if e, a := []string{"a"}, podRegistry.deleted; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v to be deleted, got %v", e, a)
+	}
+}
+
+func TestNodeLifecycleControllerOutOfService(t *testing.T) {
+	now := time.Unix(1000, 0)
+	minion := newTestMinion("shutdown", api.ConditionTrue, time.Second, now)
+	minion.Annotations = map[string]string{OutOfServiceAnnotation: NodeShutdownOutOfService}
+	unreported := api.Minion{TypeMeta: api.TypeMeta{ID: "unreported", Annotations: minion.Annotations}}
+	pods := []api.Pod{testPod("a", "shutdown"), testPod("b", "unreported")}
+
+	_, podRegistry, c := newTestController([]api.Minion{minion, unreported}, pods)
+	c.clock = &fakeClock{now: now}
+	c.monitorNodes()
+	if len(podRegistry.deleted) != 0 {
+		t.Errorf("expected no pods to be deleted, got %v", podRegistry.deleted)
+	}
+
+	_, podRegistry, c = newTestController([]api.Minion{minion, unreported}, pods)
+	c.clock = &fakeClock{now: now}
+	c.EnableNonGracefulNodeShutdown = true
+	c.monitorNodes()
+	sort.Strings(podRegistry.deleted)
+	if e, a := []string{"a", "b"}, podRegistry.deleted; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v to be deleted, got %v", e, a)
+	}
+}
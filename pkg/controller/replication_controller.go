@@ -17,12 +17,18 @@ limitations under the License.
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
+	"hash/adler32"
 	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/golang/glog"
 )
@@ -52,6 +58,100 @@ type RealPodControl struct {
 	kubeClient client.Interface
 }
 
+// CreatedByAnnotation is set on pods created by a replication controller. Its
+// value is a JSON encoded api.ObjectReference to that controller. It only
+// informs; pods are garbage collected by their OwnerReferences.
+const CreatedByAnnotation = "kubernetes.io/created-by"
+
+// PodTemplateHashAnnotation is set on pods created by a replication controller.
+// Its value is the PodTemplateHash of the template the pod was made from, by
+// which pods made from an earlier template are told apart during a rolling
+// update.
+const PodTemplateHashAnnotation = "kubernetes.io/pod-template-hash"
+
+// PodTemplateHash returns a hash of the desired state of template.
+func PodTemplateHash(template *api.PodTemplate) string {
+	data, err := json.Marshal(template.DesiredState)
+	if err != nil {
+		glog.Errorf("Unable to encode pod template: %v", err)
+	}
+	return fmt.Sprintf("%08x", adler32.Checksum(data))
+}
+
+// IsRollingUpdate returns true if pods holds pods which were not made from the
+// current template of controller, and controller has a rolling update
+// strategy to replace them with.
+func IsRollingUpdate(controller *api.ReplicationController, pods []api.Pod) bool {
+	if controller.DesiredState.RollingUpdateStrategy == nil {
+		return false
+	}
+	hash := PodTemplateHash(&controller.DesiredState.PodTemplate)
+	for i := range pods {
+		if pods[i].Annotations[PodTemplateHashAnnotation] != hash {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatedByReference returns the reference the pods created for the object of
+// kind described by meta carry.
+func CreatedByReference(kind string, meta *api.TypeMeta) api.ObjectReference {
+	return api.ObjectReference{
+		Kind:       kind,
+		Namespace:  meta.Namespace,
+		Name:       meta.ID,
+		UID:        meta.UID,
+		APIVersion: latest.Version,
+	}
+}
+
+// OwnerReference returns the owner reference to the object ref refers to,
+// which is set on the pods it creates so that they are garbage collected
+// once it is gone.
+func OwnerReference(ref api.ObjectReference) api.OwnerReference {
+	return api.OwnerReference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Name:       ref.Name,
+		UID:        ref.UID,
+	}
+}
+
+// SetCreatedBy records on pod that it is created for the object owner refers
+// to, in CreatedByAnnotation and in an owner reference.
+func SetCreatedBy(pod *api.Pod, owner api.ObjectReference) error {
+	ref, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[CreatedByAnnotation] = string(ref)
+	pod.OwnerReferences = append(pod.OwnerReferences, OwnerReference(owner))
+	return nil
+}
+
+// PodCreater creates pods the way the API does, e.g. the "pods" storage of the
+// apiserver, so that the pods controllers create are validated and admitted
+// like those of users.
+type PodCreater interface {
+	Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error)
+}
+
+// CreatePod creates pod through creater and waits until it is created.
+func CreatePod(ctx api.Context, creater PodCreater, pod *api.Pod) error {
+	out, err := creater.Create(ctx, pod)
+	if err != nil {
+		return err
+	}
+	if status, ok := (<-out).(*api.Status); ok && status.Status != api.StatusSuccess {
+		return errors.FromObject(status)
+	}
+	return nil
+}
+
 func (r RealPodControl) createReplica(ctx api.Context, controllerSpec api.ReplicationController) {
 	labels := controllerSpec.DesiredState.PodTemplate.Labels
 	// TODO: don't fail to set this label just because the map isn't created.
@@ -59,9 +159,18 @@ func (r RealPodControl) createReplica(ctx api.Context, controllerSpec api.Replic
 		labels["replicationController"] = controllerSpec.ID
 	}
 	pod := &api.Pod{
+		TypeMeta: api.TypeMeta{
+			Annotations: map[string]string{
+				PodTemplateHashAnnotation: PodTemplateHash(&controllerSpec.DesiredState.PodTemplate),
+			},
+		},
 		DesiredState: controllerSpec.DesiredState.PodTemplate.DesiredState,
 		Labels:       controllerSpec.DesiredState.PodTemplate.Labels,
 	}
+	if err := SetCreatedBy(pod, CreatedByReference("ReplicationController", &controllerSpec.TypeMeta)); err != nil {
+		glog.Errorf("Unable to encode reference to controller %s: %v", controllerSpec.ID, err)
+		return
+	}
 	_, err := r.kubeClient.CreatePod(ctx, pod)
 	if err != nil {
 		glog.Errorf("%#v\n", err)
@@ -93,7 +202,7 @@ func (rm *ReplicationManager) Run(period time.Duration) {
 
 // resourceVersion is a pointer to the resource version to use/update.
 func (rm *ReplicationManager) watchControllers(resourceVersion *string) {
-	ctx := api.NewContext()
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceAll)
 	watching, err := rm.kubeClient.WatchReplicationControllers(
 		ctx,
 		labels.Everything(),
@@ -143,6 +252,20 @@ func (rm *ReplicationManager) filterActivePods(pods []api.Pod) []api.Pod {
 	return result
 }
 
+// unavailableFirst returns pods reordered so that pods which have not been running for
+// minReadySeconds come before the available ones.
+func unavailableFirst(pods []api.Pod, minReadySeconds int, now time.Time) []api.Pod {
+	var available, unavailable []api.Pod
+	for _, pod := range pods {
+		if api.IsPodAvailable(&pod, minReadySeconds, now) {
+			available = append(available, pod)
+		} else {
+			unavailable = append(unavailable, pod)
+		}
+	}
+	return append(unavailable, available...)
+}
+
 func (rm *ReplicationManager) syncReplicationController(controllerSpec api.ReplicationController) error {
 	s := labels.Set(controllerSpec.DesiredState.ReplicaSelector).AsSelector()
 	ctx := api.WithNamespace(api.NewContext(), controllerSpec.Namespace)
@@ -151,6 +274,11 @@ func (rm *ReplicationManager) syncReplicationController(controllerSpec api.Repli
 		return err
 	}
 	filteredList := rm.filterActivePods(podList.Items)
+	if IsRollingUpdate(&controllerSpec, filteredList) {
+		// The rolling update controller replaces the pods until the update is done.
+		glog.V(4).Infof("Replication controller %s is being updated, not syncing it", controllerSpec.ID)
+		return nil
+	}
 	diff := len(filteredList) - controllerSpec.DesiredState.Replicas
 	if diff < 0 {
 		diff *= -1
@@ -166,6 +294,8 @@ func (rm *ReplicationManager) syncReplicationController(controllerSpec api.Repli
 		wait.Wait()
 	} else if diff > 0 {
 		glog.V(2).Infof("Too many replicas, deleting %d\n", diff)
+		// Prefer deleting pods that have not become available yet.
+		filteredList = unavailableFirst(filteredList, controllerSpec.DesiredState.MinReadySeconds, time.Now())
 		wait := sync.WaitGroup{}
 		wait.Add(diff)
 		for i := 0; i < diff; i++ {
@@ -183,7 +313,7 @@ func (rm *ReplicationManager) synchronize() {
 	// TODO: remove this method completely and rely on the watch.
 	// Add resource version tracking to watch to make this work.
 	var controllerSpecs []api.ReplicationController
-	ctx := api.NewContext()
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceAll)
 	list, err := rm.kubeClient.ListReplicationControllers(ctx, labels.Everything())
 	if err != nil {
 		glog.Errorf("Synchronization error: %v (%#v)", err, err)
@@ -168,6 +168,41 @@ func TestSyncReplicationControllerCreates(t *testing.T) {
 	validateSyncReplication(t, &fakePodControl, 2, 0)
 }
 
+func TestSyncReplicationControllerRollingUpdate(t *testing.T) {
+	body, _ := latest.Codec.Encode(newPodList(1))
+	fakeHandler := util.FakeHandler{
+		StatusCode:   200,
+		ResponseBody: string(body),
+	}
+	testServer := httptest.NewServer(&fakeHandler)
+	client := client.NewOrDie(&client.Config{Host: testServer.URL, Version: testapi.Version()})
+
+	fakePodControl := FakePodControl{}
+
+	manager := NewReplicationManager(client)
+	manager.podControl = &fakePodControl
+
+	// The pod carries no template hash, so it is left to the rolling update.
+	controllerSpec := newReplicationController(2)
+	controllerSpec.DesiredState.RollingUpdateStrategy = &api.RollingUpdateStrategy{MaxSurge: 1}
+
+	manager.syncReplicationController(controllerSpec)
+	validateSyncReplication(t, &fakePodControl, 0, 0)
+}
+
+func TestPodTemplateHash(t *testing.T) {
+	controllerSpec := newReplicationController(1)
+	template := &controllerSpec.DesiredState.PodTemplate
+	hash := PodTemplateHash(template)
+	if hash != PodTemplateHash(template) {
+		t.Errorf("expected the hash of a template not to change")
+	}
+	template.DesiredState.Manifest.Containers[0].Image = "foo/baz"
+	if hash == PodTemplateHash(template) {
+		t.Errorf("expected a changed template to hash differently")
+	}
+}
+
 func TestCreateReplica(t *testing.T) {
 	ctx := api.NewDefaultContext()
 	body := runtime.EncodeOrDie(testapi.Codec(), &api.Pod{})
@@ -211,11 +246,16 @@ func TestCreateReplica(t *testing.T) {
 		TypeMeta: api.TypeMeta{
 			Kind:       "Pod",
 			APIVersion: testapi.Version(),
+			Annotations: map[string]string{
+				CreatedByAnnotation:       fmt.Sprintf(`{"kind":"ReplicationController","apiVersion":"%s"}`, latest.Version),
+				PodTemplateHashAnnotation: PodTemplateHash(&controllerSpec.DesiredState.PodTemplate),
+			},
+			OwnerReferences: []api.OwnerReference{{Kind: "ReplicationController", APIVersion: latest.Version}},
 		},
 		Labels:       controllerSpec.DesiredState.PodTemplate.Labels,
 		DesiredState: controllerSpec.DesiredState.PodTemplate.DesiredState,
 	}
-	fakeHandler.ValidateRequest(t, makeURL("/pods"), "POST", nil)
+	fakeHandler.ValidateRequest(t, makeURL("/pods?namespace=default"), "POST", nil)
 	actualPod := api.Pod{}
 	if err := json.Unmarshal([]byte(fakeHandler.RequestBody), &actualPod); err != nil {
 		t.Errorf("Unexpected error: %#v", err)
@@ -303,6 +343,7 @@ func TestSynchonize(t *testing.T) {
 	}
 	mux := http.NewServeMux()
 	mux.Handle("/api/"+testapi.Version()+"/pods/", &fakePodHandler)
+	mux.Handle("/api/"+testapi.Version()+"/replicationControllers", &fakeControllerHandler)
 	mux.Handle("/api/"+testapi.Version()+"/replicationControllers/", &fakeControllerHandler)
 	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -316,6 +357,8 @@ func TestSynchonize(t *testing.T) {
 
 	manager.synchronize()
 
+	// Controllers are listed in every namespace.
+	fakeControllerHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/replicationControllers?labels=&namespace=*", "GET", nil)
 	validateSyncReplication(t, &fakePodControl, 7, 0)
 }
 
@@ -354,3 +397,40 @@ func TestWatchControllers(t *testing.T) {
 		t.Errorf("Expected 1 call but got 0")
 	}
 }
+
+func TestUnavailableFirst(t *testing.T) {
+	now := time.Now()
+	running := func(id string, startedAt time.Time) api.Pod {
+		return api.Pod{
+			TypeMeta: api.TypeMeta{ID: id},
+			DesiredState: api.PodState{
+				Manifest: api.ContainerManifest{
+					Containers: []api.Container{{Name: "foo"}},
+				},
+			},
+			CurrentState: api.PodState{
+				Status: api.PodRunning,
+				Info: api.PodInfo{
+					"foo": api.ContainerStatus{
+						State: api.ContainerState{
+							Running: &api.ContainerStateRunning{StartedAt: startedAt},
+						},
+					},
+				},
+			},
+		}
+	}
+	pods := []api.Pod{
+		running("old", now.Add(-time.Minute)),
+		running("new", now.Add(-time.Second)),
+		{TypeMeta: api.TypeMeta{ID: "waiting"}},
+	}
+	ordered := unavailableFirst(pods, 10, now)
+	ids := []string{}
+	for _, pod := range ordered {
+		ids = append(ids, pod.ID)
+	}
+	if expected := []string{"new", "waiting", "old"}; !reflect.DeepEqual(expected, ids) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+}
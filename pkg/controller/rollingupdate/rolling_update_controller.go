@@ -0,0 +1,205 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollingupdate
+
+import (
+	"fmt"
+	"time"
+
+	"code.google.com/p/go-uuid/uuid"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	controllerregistry "github.com/GoogleCloudPlatform/kubernetes/pkg/registry/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+type clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// RollingUpdateController replaces the pods of replication controllers which
+// were made from an earlier template with pods from the current one, as fast
+// as the rolling update strategy of each controller allows. While it does,
+// the replication manager leaves those controllers alone.
+type RollingUpdateController struct {
+	controllers controllerregistry.Registry
+	pods        pod.Registry
+	podCreater  controller.PodCreater
+	podInfo     client.PodInfoGetter
+	clock       clock
+}
+
+// NewRollingUpdateController returns a controller which updates the
+// replication controllers in controllers by creating pods through podCreater
+// and deleting them from pods, and learns whether pods are available from
+// podInfo.
+func NewRollingUpdateController(controllers controllerregistry.Registry, pods pod.Registry, podCreater controller.PodCreater, podInfo client.PodInfoGetter) *RollingUpdateController {
+	return &RollingUpdateController{
+		controllers: controllers,
+		pods:        pods,
+		podCreater:  podCreater,
+		podInfo:     podInfo,
+		clock:       realClock{},
+	}
+}
+
+// Run takes every rolling update a step further once per period until stopCh
+// is closed.
+func (c *RollingUpdateController) Run(period time.Duration, stopCh <-chan struct{}) {
+	util.Until(c.syncControllers, period, stopCh)
+}
+
+func (c *RollingUpdateController) syncControllers() {
+	list, err := c.controllers.ListControllers(api.NewContext())
+	if err != nil {
+		glog.Errorf("Couldn't list replication controllers: %v", err)
+		return
+	}
+	for i := range list.Items {
+		if err := c.syncController(&list.Items[i]); err != nil {
+			glog.Errorf("Couldn't update replication controller %s: %v", list.Items[i].ID, err)
+		}
+	}
+}
+
+// syncController deletes pods of rc made from an earlier template as long as
+// no more than MaxUnavailable available pods are missing, and then creates
+// pods from its current template as long as no more than MaxSurge pods are
+// above the desired number of replicas. Old pods which are not available are
+// deleted right away. Nothing is done while the update is paused.
+func (c *RollingUpdateController) syncController(rc *api.ReplicationController) error {
+	strategy := rc.DesiredState.RollingUpdateStrategy
+	if strategy == nil || strategy.Paused {
+		return nil
+	}
+	ctx := api.WithNamespace(api.NewContext(), rc.Namespace)
+	list, err := c.pods.ListPods(ctx, labels.Set(rc.DesiredState.ReplicaSelector).AsSelector())
+	if err != nil {
+		return err
+	}
+	var pods []api.Pod
+	for _, pod := range list.Items {
+		if pod.CurrentState.Status != api.PodTerminated {
+			pods = append(pods, pod)
+		}
+	}
+	if !controller.IsRollingUpdate(rc, pods) {
+		return nil
+	}
+
+	hash := controller.PodTemplateHash(&rc.DesiredState.PodTemplate)
+	now := c.clock.Now()
+	current, available := 0, 0
+	var oldAvailable, oldUnavailable []api.Pod
+	for i := range pods {
+		isAvailable := c.isAvailable(&pods[i], strategy.MinReadySeconds, now)
+		if isAvailable {
+			available++
+		}
+		switch {
+		case pods[i].Annotations[controller.PodTemplateHashAnnotation] == hash:
+			current++
+		case isAvailable:
+			oldAvailable = append(oldAvailable, pods[i])
+		default:
+			oldUnavailable = append(oldUnavailable, pods[i])
+		}
+	}
+
+	replicas := rc.DesiredState.Replicas
+	remaining := len(pods)
+	for _, pod := range oldUnavailable {
+		glog.Infof("Replication controller %s deleting unavailable old pod %s", rc.ID, pod.ID)
+		if err := c.pods.DeletePod(ctx, pod.ID); err != nil {
+			return err
+		}
+		remaining--
+	}
+	deletable := available - (replicas - strategy.MaxUnavailable)
+	for i := 0; i < deletable && i < len(oldAvailable); i++ {
+		glog.Infof("Replication controller %s deleting old pod %s", rc.ID, oldAvailable[i].ID)
+		if err := c.pods.DeletePod(ctx, oldAvailable[i].ID); err != nil {
+			return err
+		}
+		remaining--
+	}
+
+	create := replicas + strategy.MaxSurge - remaining
+	if missing := replicas - current; create > missing {
+		create = missing
+	}
+	for i := 0; i < create; i++ {
+		if err := c.createPod(ctx, rc, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isAvailable returns true if every container of pod has been running for
+// minReadySeconds as of now, according to podInfo.
+func (c *RollingUpdateController) isAvailable(pod *api.Pod, minReadySeconds int, now time.Time) bool {
+	if pod.DesiredState.Host == "" {
+		return false
+	}
+	info, err := c.podInfo.GetPodInfo(pod.DesiredState.Host, pod.Namespace, pod.ID)
+	if err != nil {
+		if err != client.ErrPodInfoNotAvailable {
+			glog.Errorf("Couldn't get the info of pod %s: %v", pod.ID, err)
+		}
+		return false
+	}
+	running := *pod
+	running.CurrentState.Status = api.PodRunning
+	running.CurrentState.Info = info
+	return api.IsPodAvailable(&running, minReadySeconds, now)
+}
+
+// createPod creates a pod from the template of rc, whose hash is hash.
+func (c *RollingUpdateController) createPod(ctx api.Context, rc *api.ReplicationController, hash string) error {
+	podLabels := map[string]string{}
+	for k, v := range rc.DesiredState.PodTemplate.Labels {
+		podLabels[k] = v
+	}
+	podLabels["replicationController"] = rc.ID
+	pod := &api.Pod{
+		TypeMeta: api.TypeMeta{
+			ID:        fmt.Sprintf("%s-%s", rc.ID, uuid.NewUUID().String()),
+			Namespace: rc.Namespace,
+			Annotations: map[string]string{
+				controller.PodTemplateHashAnnotation: hash,
+			},
+		},
+		DesiredState: rc.DesiredState.PodTemplate.DesiredState,
+		Labels:       podLabels,
+	}
+	if err := controller.SetCreatedBy(pod, controller.CreatedByReference("ReplicationController", &rc.TypeMeta)); err != nil {
+		return err
+	}
+	glog.Infof("Replication controller %s creating pod %s", rc.ID, pod.ID)
+	return controller.CreatePod(ctx, c.podCreater, pod)
+}
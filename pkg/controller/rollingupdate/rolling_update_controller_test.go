@@ -0,0 +1,291 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollingupdate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+)
+
+// fakePodRegistry adds the pods created through it to the pods it lists, as
+// if they had been scheduled on a minion, and removes the deleted ones.
+type fakePodRegistry struct {
+	*registrytest.PodRegistry
+}
+
+func (r *fakePodRegistry) CreatePod(ctx api.Context, pod *api.Pod) error {
+	r.Lock()
+	defer r.Unlock()
+	pod.DesiredState.Host = "machine"
+	r.Pods.Items = append(r.Pods.Items, *pod)
+	return r.Err
+}
+
+func (r *fakePodRegistry) DeletePod(ctx api.Context, id string) error {
+	r.Lock()
+	defer r.Unlock()
+	for i := range r.Pods.Items {
+		if r.Pods.Items[i].ID == id {
+			r.Pods.Items = append(r.Pods.Items[:i], r.Pods.Items[i+1:]...)
+			break
+		}
+	}
+	return r.Err
+}
+
+// fakePodInfoGetter returns the info of pods by their ID.
+type fakePodInfoGetter map[string]api.PodInfo
+
+func (f fakePodInfoGetter) GetPodInfo(host, namespace, id string) (api.PodInfo, error) {
+	info, ok := f[id]
+	if !ok {
+		return nil, client.ErrPodInfoNotAvailable
+	}
+	return info, nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func newTestController(replicas int, strategy *api.RollingUpdateStrategy) *api.ReplicationController {
+	return &api.ReplicationController{
+		TypeMeta: api.TypeMeta{ID: "frontend", Namespace: api.NamespaceDefault, UID: "1234"},
+		DesiredState: api.ReplicationControllerState{
+			Replicas:        replicas,
+			ReplicaSelector: map[string]string{"name": "frontend"},
+			PodTemplate: api.PodTemplate{
+				DesiredState: api.PodState{
+					Manifest: api.ContainerManifest{
+						Version:    "v1beta1",
+						Containers: []api.Container{{Name: "web", Image: "web:1"}},
+					},
+				},
+				Labels: map[string]string{"name": "frontend"},
+			},
+			RollingUpdateStrategy: strategy,
+		},
+	}
+}
+
+type testController struct {
+	*RollingUpdateController
+	rc      *api.ReplicationController
+	pods    *fakePodRegistry
+	podInfo fakePodInfoGetter
+	clock   *fakeClock
+}
+
+// newTestRollingUpdateController returns a controller for rc, whose pods were
+// made from its current template and are all available.
+func newTestRollingUpdateController(t *testing.T, rc *api.ReplicationController) *testController {
+	controllers := &registrytest.ControllerRegistry{Controllers: &api.ReplicationControllerList{Items: []api.ReplicationController{*rc}}}
+	pods := &fakePodRegistry{registrytest.NewPodRegistry(&api.PodList{})}
+	podInfo := fakePodInfoGetter{}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	c := NewRollingUpdateController(controllers, pods, pod.NewREST(&pod.RESTConfig{Registry: pods}), podInfo)
+	c.clock = clock
+	tc := &testController{c, rc, pods, podInfo, clock}
+	hash := controller.PodTemplateHash(&rc.DesiredState.PodTemplate)
+	for i := 0; i < rc.DesiredState.Replicas; i++ {
+		if err := c.createPod(api.NewDefaultContext(), rc, hash); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	tc.start()
+	return tc
+}
+
+// setImage changes the template of the controller.
+func (c *testController) setImage(image string) {
+	c.rc.DesiredState.PodTemplate.DesiredState.Manifest.Containers = []api.Container{{Name: "web", Image: image}}
+	c.controllers.(*registrytest.ControllerRegistry).Controllers.Items[0] = *c.rc
+}
+
+// start starts the containers of every pod which has not started yet.
+func (c *testController) start() {
+	for _, pod := range c.pods.Pods.Items {
+		if _, ok := c.podInfo[pod.ID]; ok {
+			continue
+		}
+		c.podInfo[pod.ID] = api.PodInfo{
+			"web": api.ContainerStatus{
+				State: api.ContainerState{Running: &api.ContainerStateRunning{StartedAt: c.clock.now}},
+			},
+		}
+	}
+}
+
+// images returns the number of pods of each image, and of them those which
+// are available.
+func (c *testController) images() (total, available map[string]int) {
+	total, available = map[string]int{}, map[string]int{}
+	for i := range c.pods.Pods.Items {
+		pod := &c.pods.Pods.Items[i]
+		image := pod.DesiredState.Manifest.Containers[0].Image
+		total[image]++
+		if c.isAvailable(pod, c.rc.DesiredState.RollingUpdateStrategy.MinReadySeconds, c.clock.now) {
+			available[image]++
+		}
+	}
+	return total, available
+}
+
+// rollOut syncs the controller and starts the pods it creates until nothing
+// changes any more, and checks the pods respect the strategy at every step.
+func (c *testController) rollOut(t *testing.T) {
+	strategy := c.rc.DesiredState.RollingUpdateStrategy
+	replicas := c.rc.DesiredState.Replicas
+	for i := 0; i < 20; i++ {
+		before := len(c.pods.Pods.Items)
+		c.syncControllers()
+		total, available := 0, 0
+		for _, pod := range c.pods.Pods.Items {
+			total++
+			if c.isAvailable(&pod, strategy.MinReadySeconds, c.clock.now) {
+				available++
+			}
+		}
+		if total > replicas+strategy.MaxSurge {
+			t.Fatalf("expected at most %d pods, got %d", replicas+strategy.MaxSurge, total)
+		}
+		if available < replicas-strategy.MaxUnavailable {
+			t.Fatalf("expected at least %d available pods, got %d", replicas-strategy.MaxUnavailable, available)
+		}
+		c.clock.now = c.clock.now.Add(time.Second)
+		c.start()
+		if total == before && !controller.IsRollingUpdate(c.rc, c.pods.Pods.Items) {
+			return
+		}
+	}
+	t.Fatalf("expected the rolling update to finish")
+}
+
+func TestRollingUpdate(t *testing.T) {
+	table := map[string]api.RollingUpdateStrategy{
+		"surge":       {MaxSurge: 1},
+		"unavailable": {MaxUnavailable: 1},
+		"both":        {MaxSurge: 2, MaxUnavailable: 1},
+	}
+	for name, strategy := range table {
+		strategy := strategy
+		c := newTestRollingUpdateController(t, newTestController(3, &strategy))
+
+		c.syncControllers()
+		if total, _ := c.images(); total["web:1"] != 3 || len(total) != 1 {
+			t.Errorf("%s: expected the pods of an unchanged template to be kept, got %v", name, total)
+		}
+
+		c.setImage("web:2")
+		c.rollOut(t)
+		if total, available := c.images(); total["web:2"] != 3 || available["web:2"] != 3 || len(total) != 1 {
+			t.Errorf("%s: expected 3 available pods of the new template, got %v, %v available", name, total, available)
+		}
+	}
+}
+
+func TestRollingUpdateMinReadySeconds(t *testing.T) {
+	c := newTestRollingUpdateController(t, newTestController(2, &api.RollingUpdateStrategy{MaxSurge: 1, MinReadySeconds: 30}))
+	c.clock.now = c.clock.now.Add(time.Minute)
+	c.setImage("web:2")
+
+	c.syncControllers()
+	c.start()
+	c.clock.now = c.clock.now.Add(10 * time.Second)
+	c.syncControllers()
+	if total, _ := c.images(); total["web:1"] != 2 || total["web:2"] != 1 {
+		t.Errorf("expected no old pod to be deleted before the new one is ready, got %v", total)
+	}
+	c.clock.now = c.clock.now.Add(30 * time.Second)
+	c.syncControllers()
+	if total, _ := c.images(); total["web:1"] != 1 || total["web:2"] != 2 {
+		t.Errorf("expected an old pod to be replaced once the new one is ready, got %v", total)
+	}
+}
+
+func TestRollingUpdatePauseResume(t *testing.T) {
+	strategy := &api.RollingUpdateStrategy{MaxSurge: 1}
+	c := newTestRollingUpdateController(t, newTestController(3, strategy))
+	c.setImage("web:2")
+
+	c.syncControllers()
+	c.start()
+	c.syncControllers()
+	c.start()
+	paused, _ := c.images()
+	if paused["web:1"] == 0 || paused["web:2"] == 0 {
+		t.Fatalf("expected the update to be under way, got %v", paused)
+	}
+
+	strategy.Paused = true
+	c.setImage("web:2")
+	for i := 0; i < 3; i++ {
+		c.syncControllers()
+		c.start()
+	}
+	if total, _ := c.images(); total["web:1"] != paused["web:1"] || total["web:2"] != paused["web:2"] {
+		t.Errorf("expected a paused update to stay at %v, got %v", paused, total)
+	}
+
+	strategy.Paused = false
+	c.setImage("web:2")
+	c.rollOut(t)
+	if total, _ := c.images(); total["web:2"] != 3 || len(total) != 1 {
+		t.Errorf("expected a resumed update to finish, got %v", total)
+	}
+}
+
+func TestRollingUpdateAbort(t *testing.T) {
+	c := newTestRollingUpdateController(t, newTestController(3, &api.RollingUpdateStrategy{MaxUnavailable: 1}))
+	c.setImage("web:2")
+	c.syncControllers()
+	c.start()
+	c.syncControllers()
+	c.start()
+	if total, _ := c.images(); total["web:2"] == 0 {
+		t.Fatalf("expected the update to be under way, got %v", total)
+	}
+
+	// Restoring the previous template rolls the new pods back.
+	c.setImage("web:1")
+	c.rollOut(t)
+	if total, available := c.images(); total["web:1"] != 3 || available["web:1"] != 3 || len(total) != 1 {
+		t.Errorf("expected 3 available pods of the restored template, got %v, %v available", total, available)
+	}
+}
+
+func TestRollingUpdateDeletesUnavailableOldPods(t *testing.T) {
+	c := newTestRollingUpdateController(t, newTestController(2, &api.RollingUpdateStrategy{MaxSurge: 1}))
+	// The containers of the first pod stopped running.
+	delete(c.podInfo, c.pods.Pods.Items[0].ID)
+	c.setImage("web:2")
+
+	c.syncControllers()
+	if total, _ := c.images(); total["web:1"] != 1 || total["web:2"] != 2 {
+		t.Errorf("expected the unavailable old pod to be replaced right away, got %v", total)
+	}
+}
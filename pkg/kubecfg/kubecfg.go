@@ -124,6 +124,19 @@ func Update(ctx api.Context, name string, client client.Interface, updatePeriod
 			return err
 		}
 		time.Sleep(updatePeriod)
+		if minReady := controller.DesiredState.MinReadySeconds; minReady > 0 {
+			// Don't move on to the next pod until the replacement has been running for minReady.
+			err = wait.Poll(time.Second, time.Second*300+time.Duration(minReady)*time.Second, func() (bool, error) {
+				podList, err := client.ListPods(ctx, s)
+				if err != nil {
+					return false, err
+				}
+				return countAvailable(podList.Items, minReady) >= expected, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return wait.Poll(time.Second*5, time.Second*300, func() (bool, error) {
 		podList, err := client.ListPods(ctx, s)
@@ -134,6 +147,18 @@ func Update(ctx api.Context, name string, client client.Interface, updatePeriod
 	})
 }
 
+// countAvailable returns the number of pods that have been running for at least minReadySeconds.
+func countAvailable(pods []api.Pod, minReadySeconds int) int {
+	now := time.Now()
+	count := 0
+	for i := range pods {
+		if api.IsPodAvailable(&pods[i], minReadySeconds, now) {
+			count++
+		}
+	}
+	return count
+}
+
 // StopController stops a controller named 'name' by setting replicas to zero.
 func StopController(ctx api.Context, name string, client client.Interface) error {
 	return ResizeController(ctx, name, 0, client)
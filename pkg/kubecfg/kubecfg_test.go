@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
@@ -55,6 +56,43 @@ func TestUpdateWithPods(t *testing.T) {
 	validateAction(client.FakeAction{Action: "list-pods"}, fakeClient.Actions[4], t)
 }
 
+func TestUpdateWithMinReadySeconds(t *testing.T) {
+	fakeClient := client.Fake{
+		Ctrl: api.ReplicationController{
+			DesiredState: api.ReplicationControllerState{MinReadySeconds: 10},
+		},
+		Pods: api.PodList{
+			Items: []api.Pod{
+				{
+					TypeMeta: api.TypeMeta{ID: "pod-1"},
+					CurrentState: api.PodState{
+						Status: api.PodRunning,
+						Info: api.PodInfo{
+							"foo": api.ContainerStatus{
+								State: api.ContainerState{
+									Running: &api.ContainerStateRunning{StartedAt: time.Now().Add(-time.Minute)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := Update(api.NewDefaultContext(), "foo", &fakeClient, 0, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fakeClient.Actions) != 5 {
+		t.Fatalf("Unexpected action list %#v", fakeClient.Actions)
+	}
+	validateAction(client.FakeAction{Action: "get-controller", Value: "foo"}, fakeClient.Actions[0], t)
+	validateAction(client.FakeAction{Action: "list-pods"}, fakeClient.Actions[1], t)
+	validateAction(client.FakeAction{Action: "delete-pod", Value: "pod-1"}, fakeClient.Actions[2], t)
+	// Update waits for the replacement to become available before moving on.
+	validateAction(client.FakeAction{Action: "list-pods"}, fakeClient.Actions[3], t)
+	validateAction(client.FakeAction{Action: "list-pods"}, fakeClient.Actions[4], t)
+}
+
 func TestUpdateNoPods(t *testing.T) {
 	fakeClient := client.Fake{}
 	Update(api.NewDefaultContext(), "foo", &fakeClient, 0, "")
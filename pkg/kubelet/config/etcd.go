@@ -32,8 +32,11 @@ import (
 	"github.com/golang/glog"
 )
 
-func EtcdKeyForHost(hostname string) string {
-	return path.Join("/", "registry", "hosts", hostname, "kubelet")
+// EtcdKeyForHost returns the key the pods bound to hostname are stored under
+// in etcd, where prefix is the key under which the apiserver keeps every
+// object (its -etcd_prefix).
+func EtcdKeyForHost(prefix, hostname string) string {
+	return path.Join("/", prefix, "hosts", hostname, "kubelet")
 }
 
 type SourceEtcd struct {
@@ -45,9 +48,9 @@ type SourceEtcd struct {
 // NewSourceEtcd creates a config source that watches and pulls from a key in etcd
 func NewSourceEtcd(key string, client tools.EtcdClient, updates chan<- interface{}) *SourceEtcd {
 	helper := tools.EtcdHelper{
-		client,
-		latest.Codec,
-		tools.RuntimeVersionAdapter{latest.ResourceVersioner},
+		Client:            client,
+		Codec:             latest.Codec,
+		ResourceVersioner: tools.RuntimeVersionAdapter{latest.ResourceVersioner},
 	}
 	source := &SourceEtcd{
 		key:     key,
@@ -105,7 +108,3 @@ func eventToPods(ev watch.Event) ([]kubelet.Pod, error) {
 
 	return pods, nil
 }
-
-func makeContainerKey(machine string) string {
-	return "/registry/hosts/" + machine + "/kubelet"
-}
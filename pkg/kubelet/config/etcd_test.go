@@ -83,3 +83,16 @@ func TestEventToPods(t *testing.T) {
 		}
 	}
 }
+
+func TestEtcdKeyForHost(t *testing.T) {
+	tests := map[string]string{
+		"/registry": "/registry/hosts/machine/kubelet",
+		"/other/":   "/other/hosts/machine/kubelet",
+		"":          "/hosts/machine/kubelet",
+	}
+	for prefix, expected := range tests {
+		if got := EtcdKeyForHost(prefix, "machine"); got != expected {
+			t.Errorf("prefix %q: expected %s, got %s", prefix, expected, got)
+		}
+	}
+}
@@ -116,6 +116,30 @@ func (d *dockerContainerCommandRunner) RunInContainer(containerID string, cmd []
 	return c.CombinedOutput()
 }
 
+// ExecInContainer uses nsinit to run the command inside the container identified by containerID,
+// attached to stdin, stdout and stderr. Any of them may be nil.
+func (d *dockerContainerCommandRunner) ExecInContainer(containerID string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	c, err := d.getRunInContainerCommand(containerID, cmd)
+	if err != nil {
+		return err
+	}
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if stdin != nil {
+		// Copy stdin ourselves, as Run would otherwise wait for stdin to be
+		// closed even once the command has exited.
+		w, err := c.StdinPipe()
+		if err != nil {
+			return err
+		}
+		go func() {
+			io.Copy(w, stdin)
+			w.Close()
+		}()
+	}
+	return c.Run()
+}
+
 // NewDockerContainerCommandRunner creates a ContainerCommandRunner which uses nsinit to run a command
 // inside a container.
 func NewDockerContainerCommandRunner() ContainerCommandRunner {
@@ -488,6 +512,7 @@ func parseImageName(image string) (string, string) {
 
 type ContainerCommandRunner interface {
 	RunInContainer(containerID string, cmd []string) ([]byte, error)
+	ExecInContainer(containerID string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error
 }
 
 // dockerKeyring tracks a set of docker registry credentials, maintaining a
@@ -17,11 +17,13 @@ limitations under the License.
 package kubelet
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
@@ -115,3 +117,54 @@ func (fw *FlushWriter) Write(p []byte) (n int, err error) {
 	}
 	return
 }
+
+// SinceWriter drops the lines of container logs written to it until the first
+// line timestamped at or after Since, and passes on everything from there. Docker
+// prefixes every line with its timestamp when asked for timestamps, after the
+// header of the frame if the output of the container is multiplexed.
+type SinceWriter struct {
+	Since  time.Time
+	Writer io.Writer
+
+	passing bool
+	line    []byte
+}
+
+// Write is a SinceWriter implementation of the io.Writer.
+func (sw *SinceWriter) Write(p []byte) (int, error) {
+	if sw.passing {
+		return sw.Writer.Write(p)
+	}
+	sw.line = append(sw.line, p...)
+	for !sw.passing {
+		end := bytes.IndexByte(sw.line, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		if stamp, ok := logLineTime(sw.line[:end]); ok && !stamp.Before(sw.Since) {
+			sw.passing = true
+		} else {
+			sw.line = sw.line[end+1:]
+		}
+	}
+	rest := sw.line
+	sw.line = nil
+	if _, err := sw.Writer.Write(rest); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// logLineTime returns the timestamp of a line of container logs.
+func logLineTime(line []byte) (time.Time, bool) {
+	// Skip the header of a multiplexed frame: the stream, three zero bytes
+	// and the size of the frame.
+	if len(line) >= 8 && line[0] <= 2 && line[1] == 0 && line[2] == 0 && line[3] == 0 {
+		line = line[8:]
+	}
+	if i := bytes.IndexByte(line, ' '); i >= 0 {
+		line = line[:i]
+	}
+	stamp, err := time.Parse(time.RFC3339Nano, string(line))
+	return stamp, err == nil
+}
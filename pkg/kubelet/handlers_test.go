@@ -17,7 +17,9 @@ limitations under the License.
 package kubelet
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
@@ -67,3 +69,29 @@ func TestResolvePortStringUnknown(t *testing.T) {
 		t.Error("unexpected non-error")
 	}
 }
+
+func TestSinceWriter(t *testing.T) {
+	since := time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	w := &SinceWriter{Since: since, Writer: &out}
+	writes := []string{
+		"2015-01-01T11:59:59.5Z too old\n",
+		"not a log line\n2015-01-01T12:00",
+		":00Z first\n",
+		"\x01\x00\x00\x00\x00\x00\x00\x202015-01-01T12:00:01.25Z multiplexed\n",
+		"no timestamp, but after the first line\n",
+	}
+	for _, data := range writes {
+		n, err := w.Write([]byte(data))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if n != len(data) {
+			t.Errorf("expected %d bytes written, saw: %d", len(data), n)
+		}
+	}
+	expected := "2015-01-01T12:00:00Z first\n\x01\x00\x00\x00\x00\x00\x00\x202015-01-01T12:00:01.25Z multiplexed\nno timestamp, but after the first line\n"
+	if out.String() != expected {
+		t.Errorf("expected: %q, saw: %q", expected, out.String())
+	}
+}
@@ -853,13 +853,34 @@ func (kl *Kubelet) RunInContainer(podFullName, uuid, container string, cmd []str
 	if kl.runner == nil {
 		return nil, fmt.Errorf("no runner specified.")
 	}
-	dockerContainers, err := dockertools.GetKubeletDockerContainers(kl.dockerClient, false)
+	containerID, err := kl.findContainerID(podFullName, uuid, container)
 	if err != nil {
 		return nil, err
 	}
+	return kl.runner.RunInContainer(containerID, cmd)
+}
+
+// ExecInContainer runs a command in a container, attached to stdin, stdout and stderr.
+func (kl *Kubelet) ExecInContainer(podFullName, uuid, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if kl.runner == nil {
+		return fmt.Errorf("no runner specified.")
+	}
+	containerID, err := kl.findContainerID(podFullName, uuid, container)
+	if err != nil {
+		return err
+	}
+	return kl.runner.ExecInContainer(containerID, cmd, stdin, stdout, stderr)
+}
+
+// findContainerID returns the ID of the docker container of a container of a pod.
+func (kl *Kubelet) findContainerID(podFullName, uuid, container string) (string, error) {
+	dockerContainers, err := dockertools.GetKubeletDockerContainers(kl.dockerClient, false)
+	if err != nil {
+		return "", err
+	}
 	dockerContainer, found, _ := dockerContainers.FindPodContainer(podFullName, uuid, container)
 	if !found {
-		return nil, fmt.Errorf("container not found (%s)", container)
+		return "", fmt.Errorf("container not found (%s)", container)
 	}
-	return kl.runner.RunInContainer(dockerContainer.ID, cmd)
+	return dockerContainer.ID, nil
 }
@@ -17,7 +17,9 @@ limitations under the License.
 package kubelet
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"regexp"
@@ -944,9 +946,12 @@ func TestGetContainerInfoOnNonExistContainer(t *testing.T) {
 }
 
 type fakeContainerCommandRunner struct {
-	Cmd []string
-	ID  string
-	E   error
+	Cmd    []string
+	ID     string
+	E      error
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 func (f *fakeContainerCommandRunner) RunInContainer(id string, cmd []string) ([]byte, error) {
@@ -955,6 +960,13 @@ func (f *fakeContainerCommandRunner) RunInContainer(id string, cmd []string) ([]
 	return []byte{}, f.E
 }
 
+func (f *fakeContainerCommandRunner) ExecInContainer(id string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	f.Cmd = cmd
+	f.ID = id
+	f.Stdin, f.Stdout, f.Stderr = stdin, stdout, stderr
+	return f.E
+}
+
 func TestRunInContainerNoSuchPod(t *testing.T) {
 	fakeCommandRunner := fakeContainerCommandRunner{}
 	kubelet, _, fakeDocker := newTestKubelet(t)
@@ -1162,3 +1174,63 @@ func TestSyncPodEventHandlerFails(t *testing.T) {
 		t.Errorf("Wrong containers were stopped: %v", fakeDocker.Stopped)
 	}
 }
+
+func TestExecInContainerNoSuchPod(t *testing.T) {
+	fakeCommandRunner := fakeContainerCommandRunner{}
+	kubelet, _, fakeDocker := newTestKubelet(t)
+	fakeDocker.ContainerList = []docker.APIContainers{}
+	kubelet.runner = &fakeCommandRunner
+
+	err := kubelet.ExecInContainer(
+		GetPodFullName(&Pod{Name: "podFoo", Namespace: "etcd"}),
+		"",
+		"containerFoo",
+		[]string{"ls"},
+		nil, nil, nil)
+	if err == nil {
+		t.Error("unexpected non-error")
+	}
+	if fakeCommandRunner.ID != "" {
+		t.Errorf("unexpected command run in %s", fakeCommandRunner.ID)
+	}
+}
+
+func TestExecInContainer(t *testing.T) {
+	fakeCommandRunner := fakeContainerCommandRunner{}
+	kubelet, _, fakeDocker := newTestKubelet(t)
+	kubelet.runner = &fakeCommandRunner
+
+	containerID := "abc1234"
+	podName := "podFoo"
+	podNamespace := "etcd"
+	containerName := "containerFoo"
+
+	fakeDocker.ContainerList = []docker.APIContainers{
+		{
+			ID:    containerID,
+			Names: []string{"/k8s_" + containerName + "_" + podName + "." + podNamespace + "_1234"},
+		},
+	}
+
+	cmd := []string{"ls"}
+	stdin := &bytes.Buffer{}
+	stdout := &bytes.Buffer{}
+	err := kubelet.ExecInContainer(
+		GetPodFullName(&Pod{Name: podName, Namespace: podNamespace}),
+		"",
+		containerName,
+		cmd,
+		stdin, stdout, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if fakeCommandRunner.ID != containerID {
+		t.Errorf("unexpected ID: %s", fakeCommandRunner.ID)
+	}
+	if !reflect.DeepEqual(fakeCommandRunner.Cmd, cmd) {
+		t.Errorf("unexpected command: %s", fakeCommandRunner.Cmd)
+	}
+	if fakeCommandRunner.Stdin != stdin || fakeCommandRunner.Stdout != stdout || fakeCommandRunner.Stderr != nil {
+		t.Errorf("unexpected streams: %#v", fakeCommandRunner)
+	}
+}
@@ -28,8 +28,10 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"code.google.com/p/go.net/websocket"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/healthz"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/httplog"
@@ -68,6 +70,7 @@ type HostInterface interface {
 	GetMachineInfo() (*info.MachineInfo, error)
 	GetPodInfo(name, uuid string) (api.PodInfo, error)
 	RunInContainer(name, uuid, container string, cmd []string) ([]byte, error)
+	ExecInContainer(name, uuid, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error
 	GetKubeletContainerLogs(podFullName, containerName, tail string, follow bool, stdout, stderr io.Writer) error
 	ServeLogs(w http.ResponseWriter, req *http.Request)
 }
@@ -100,6 +103,7 @@ func (s *Server) InstallDebuggingHandlers() {
 	s.mux.HandleFunc("/container", s.handleContainer)
 	s.mux.HandleFunc("/containers", s.handleContainers)
 	s.mux.HandleFunc("/run/", s.handleRun)
+	s.mux.HandleFunc("/exec/", s.handleExec)
 
 	s.mux.HandleFunc("/logs/", s.handleLogs)
 	s.mux.HandleFunc("/containerLogs/", s.handleContainerLogs)
@@ -185,6 +189,14 @@ func (s *Server) handleContainerLogs(w http.ResponseWriter, req *http.Request) {
 	uriValues := u.Query()
 	follow, _ := strconv.ParseBool(uriValues.Get("follow"))
 	tail := uriValues.Get("tail")
+	var sinceSeconds int
+	if value := uriValues.Get("sinceSeconds"); len(value) > 0 {
+		sinceSeconds, err = strconv.Atoi(value)
+		if err != nil || sinceSeconds < 0 {
+			http.Error(w, `{"message": "Invalid sinceSeconds."}`, http.StatusBadRequest)
+			return
+		}
+	}
 
 	podFullName := GetPodFullName(&Pod{Name: podID, Namespace: "etcd"})
 
@@ -192,9 +204,13 @@ func (s *Server) handleContainerLogs(w http.ResponseWriter, req *http.Request) {
 	if flusher, ok := w.(http.Flusher); ok {
 		fw.flusher = flusher
 	}
+	var out io.Writer = &fw
+	if sinceSeconds > 0 {
+		out = &SinceWriter{Since: time.Now().Add(-time.Duration(sinceSeconds) * time.Second), Writer: &fw}
+	}
 	w.Header().Set("Transfer-Encoding", "chunked")
 	w.WriteHeader(http.StatusOK)
-	err = s.host.GetKubeletContainerLogs(podFullName, containerName, tail, follow, &fw, &fw)
+	err = s.host.GetKubeletContainerLogs(podFullName, containerName, tail, follow, out, out)
 	if err != nil {
 		s.error(w, err)
 		return
@@ -294,6 +310,119 @@ func (s *Server) handleRun(w http.ResponseWriter, req *http.Request) {
 	w.Write(data)
 }
 
+// The streams of a command run by handleExec. Every message on the websocket
+// starts with the stream it belongs to.
+const (
+	// ExecStdin messages are sent by the client. An empty one closes stdin.
+	ExecStdin byte = iota
+	ExecStdout
+	ExecStderr
+	// An ExecError message holds the error the command failed with, and is the
+	// last message the kubelet sends.
+	ExecError
+)
+
+// handleExec handles requests to run a command inside a container, attached to
+// a websocket. The command is given by the command query parameters, and the
+// stdin, stdout and stderr parameters choose the streams to attach.
+func (s *Server) handleExec(w http.ResponseWriter, req *http.Request) {
+	u, err := url.ParseRequestURI(req.RequestURI)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	parts := strings.Split(u.Path, "/")
+	var podID, uuid, container string
+	if len(parts) == 4 {
+		podID = parts[2]
+		container = parts[3]
+	} else if len(parts) == 5 {
+		podID = parts[2]
+		uuid = parts[3]
+		container = parts[4]
+	} else {
+		http.Error(w, "Unexpected path for command running", http.StatusBadRequest)
+		return
+	}
+	query := u.Query()
+	command := query["command"]
+	if len(command) == 0 {
+		http.Error(w, `{"message": "Missing command."}`, http.StatusBadRequest)
+		return
+	}
+	if tty, _ := strconv.ParseBool(query.Get("tty")); tty {
+		http.Error(w, `{"message": "Running a command with a TTY is not supported."}`, http.StatusBadRequest)
+		return
+	}
+	attachStdin, _ := strconv.ParseBool(query.Get("stdin"))
+	attachStdout, _ := strconv.ParseBool(query.Get("stdout"))
+	attachStderr, _ := strconv.ParseBool(query.Get("stderr"))
+	podFullName := GetPodFullName(&Pod{Name: podID, Namespace: "etcd"})
+
+	websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+		// Commands may run for longer than the timeouts of the server.
+		ws.SetDeadline(time.Time{})
+		var stdin io.Reader
+		if attachStdin {
+			r, w := io.Pipe()
+			defer r.Close()
+			go receiveStdin(ws, w)
+			stdin = r
+		}
+		lock := &sync.Mutex{}
+		var stdout, stderr io.Writer
+		if attachStdout {
+			stdout = &execWriter{ws, ExecStdout, lock}
+		}
+		if attachStderr {
+			stderr = &execWriter{ws, ExecStderr, lock}
+		}
+		err := s.host.ExecInContainer(podFullName, uuid, container, command, stdin, stdout, stderr)
+		if err != nil {
+			(&execWriter{ws, ExecError, lock}).Write([]byte(err.Error()))
+		}
+	}).ServeHTTP(httplog.Unlogged(w), req)
+}
+
+// receiveStdin copies the stdin messages of ws to w, until an empty one is
+// received or ws is closed.
+func receiveStdin(ws *websocket.Conn, w *io.PipeWriter) {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			w.CloseWithError(err)
+			return
+		}
+		if len(data) == 0 || data[0] != ExecStdin {
+			continue
+		}
+		if len(data) == 1 {
+			w.Close()
+			return
+		}
+		if _, err := w.Write(data[1:]); err != nil {
+			return
+		}
+	}
+}
+
+// execWriter sends what is written to it to a websocket as messages of a stream.
+type execWriter struct {
+	ws     *websocket.Conn
+	stream byte
+	lock   *sync.Mutex
+}
+
+func (e *execWriter) Write(p []byte) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if err := websocket.Message.Send(e.ws, append([]byte{e.stream}, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // ServeHTTP responds to HTTP requests on the Kubelet.
 func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	defer httplog.NewLogged(req, &w).StacktraceWhen(
@@ -28,7 +28,9 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"code.google.com/p/go.net/websocket"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/google/cadvisor/info"
@@ -42,6 +44,7 @@ type fakeKubelet struct {
 	logFunc           func(w http.ResponseWriter, req *http.Request)
 	runFunc           func(podFullName, uuid, containerName string, cmd []string) ([]byte, error)
 	containerLogsFunc func(podFullName, containerName, tail string, follow bool, stdout, stderr io.Writer) error
+	execFunc          func(podFullName, uuid, containerName string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error
 }
 
 func (fk *fakeKubelet) GetPodInfo(name, uuid string) (api.PodInfo, error) {
@@ -72,6 +75,10 @@ func (fk *fakeKubelet) RunInContainer(podFullName, uuid, containerName string, c
 	return fk.runFunc(podFullName, uuid, containerName, cmd)
 }
 
+func (fk *fakeKubelet) ExecInContainer(podFullName, uuid, containerName string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return fk.execFunc(podFullName, uuid, containerName, cmd, stdin, stdout, stderr)
+}
+
 type serverTestFramework struct {
 	updateChan      chan interface{}
 	updateReader    *channelReader
@@ -472,3 +479,110 @@ func TestContainerLogsWithFollow(t *testing.T) {
 		t.Errorf("Expected: '%v', got: '%v'", output, result)
 	}
 }
+
+func TestContainerLogsWithSinceSeconds(t *testing.T) {
+	fw := newServerTest()
+	podName := "foo"
+	expectedContainerName := "baz"
+	now := time.Now()
+	fw.fakeKubelet.containerLogsFunc = func(podFullName, containerName, tail string, follow bool, stdout, stderr io.Writer) error {
+		fmt.Fprintf(stdout, "%s old\n", now.Add(-time.Hour).Format(time.RFC3339Nano))
+		fmt.Fprintf(stdout, "%s new\n", now.Add(time.Minute).Format(time.RFC3339Nano))
+		return nil
+	}
+	resp, err := http.Get(fw.testHTTPServer.URL + "/containerLogs/" + podName + "/" + expectedContainerName + "?sinceSeconds=60")
+	if err != nil {
+		t.Fatalf("Got error GETing: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Errorf("Error reading container logs: %v", err)
+	}
+	expected := now.Add(time.Minute).Format(time.RFC3339Nano) + " new\n"
+	if string(body) != expected {
+		t.Errorf("Expected: %q, got: %q", expected, string(body))
+	}
+
+	resp, err = http.Get(fw.testHTTPServer.URL + "/containerLogs/" + podName + "/" + expectedContainerName + "?sinceSeconds=-1")
+	if err != nil {
+		t.Fatalf("Got error GETing: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestServeExecInContainer(t *testing.T) {
+	fw := newServerTest()
+	expectedPodName := "foo.etcd"
+	expectedContainerName := "baz"
+	expectedCommand := []string{"cat", "-"}
+	fw.fakeKubelet.execFunc = func(podFullName, uuid, containerName string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		if podFullName != expectedPodName {
+			t.Errorf("expected %s, got %s", expectedPodName, podFullName)
+		}
+		if containerName != expectedContainerName {
+			t.Errorf("expected %s, got %s", expectedContainerName, containerName)
+		}
+		if !reflect.DeepEqual(cmd, expectedCommand) {
+			t.Errorf("expected %v, got %v", expectedCommand, cmd)
+		}
+		input, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		fmt.Fprintf(stdout, "out: %s", input)
+		fmt.Fprintf(stderr, "err: %s", input)
+		return fmt.Errorf("exited with 1")
+	}
+
+	url := strings.Replace(fw.testHTTPServer.URL, "http://", "ws://", 1) + "/exec/foo/baz?command=cat&command=-&stdin=1&stdout=1&stderr=1"
+	ws, err := websocket.Dial(url, "", "http://127.0.0.1/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ws.Close()
+	if err := websocket.Message.Send(ws, []byte{ExecStdin, 'h', 'i'}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := websocket.Message.Send(ws, []byte{ExecStdin}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	received := map[byte]string{}
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			break
+		}
+		received[data[0]] += string(data[1:])
+	}
+	expected := map[byte]string{
+		ExecStdout: "out: hi",
+		ExecStderr: "err: hi",
+		ExecError:  "exited with 1",
+	}
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("expected %#v, got %#v", expected, received)
+	}
+}
+
+func TestServeExecInContainerBadRequest(t *testing.T) {
+	fw := newServerTest()
+	fw.fakeKubelet.execFunc = func(podFullName, uuid, containerName string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		t.Errorf("unexpected command run")
+		return nil
+	}
+	for _, path := range []string{"/exec/foo/baz", "/exec/foo/baz?command=sh&tty=1", "/exec/foo?command=sh"} {
+		resp, err := http.Get(fw.testHTTPServer.URL + path)
+		if err != nil {
+			t.Fatalf("Got error GETing: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"math"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/golang/glog"
+)
+
+// LeaderElector campaigns for a lock key in etcd on behalf of one candidate.
+// The key holds the identity of the leader and expires after a TTL, so the
+// leader renews it several times per TTL. If the leader stops renewing it,
+// another candidate acquires the key once it expires.
+type LeaderElector struct {
+	client tools.EtcdGetSet
+	key    string
+	id     string
+	ttl    uint64
+	// How often the lock is renewed, and how often a candidate tries to
+	// acquire it.
+	period time.Duration
+}
+
+// NewLeaderElector returns a LeaderElector which campaigns for key as the
+// candidate id. The lock expires ttl after it was last renewed, rounded up to
+// whole seconds.
+func NewLeaderElector(client tools.EtcdGetSet, key, id string, ttl time.Duration) *LeaderElector {
+	seconds := uint64(math.Ceil(ttl.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &LeaderElector{
+		client: client,
+		key:    key,
+		id:     id,
+		ttl:    seconds,
+		period: ttl / 3,
+	}
+}
+
+// Run campaigns for the lock until stop is closed. Whenever the lock is
+// acquired, lead is called with a channel which is closed when the lock is
+// lost again or stop is closed. lead must return once that happens, after
+// which Run campaigns again. The lock is not released when stop is closed; it
+// expires after the TTL.
+func (e *LeaderElector) Run(lead func(lost <-chan struct{}), stop <-chan struct{}) {
+	for {
+		if e.tryAcquireOrRenew() {
+			glog.Infof("%s acquired the lock %s", e.id, e.key)
+			lost := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				lead(lost)
+			}()
+			e.hold(stop)
+			close(lost)
+			<-done
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(e.period):
+		}
+	}
+}
+
+// hold renews the lock until it fails to, or stop is closed.
+func (e *LeaderElector) hold(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(e.period):
+		}
+		if !e.tryAcquireOrRenew() {
+			glog.Infof("%s lost the lock %s", e.id, e.key)
+			return
+		}
+	}
+}
+
+// tryAcquireOrRenew returns true if the lock is held by this candidate for
+// another TTL, either because it was free or because it was held already.
+func (e *LeaderElector) tryAcquireOrRenew() bool {
+	_, err := e.client.Create(e.key, e.id, e.ttl)
+	if err == nil {
+		return true
+	}
+	if !tools.IsEtcdNodeExist(err) {
+		glog.Errorf("Couldn't acquire the lock %s: %v", e.key, err)
+		return false
+	}
+	_, err = e.client.CompareAndSwap(e.key, e.id, e.ttl, e.id, 0)
+	if err == nil {
+		return true
+	}
+	if !tools.IsEtcdTestFailed(err) && !tools.IsEtcdNotFound(err) {
+		glog.Errorf("Couldn't renew the lock %s: %v", e.key, err)
+	}
+	return false
+}
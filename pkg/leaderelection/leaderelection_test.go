@@ -0,0 +1,157 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/coreos/go-etcd/etcd"
+)
+
+const lockKey = "/registry/leaderelection/test"
+
+func newFakeClient(t *testing.T) *tools.FakeEtcdClient {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	return fakeClient
+}
+
+// setHolder stores holder as the holder of the lock, or frees the lock if
+// holder is empty, as another candidate or an expiring TTL would.
+func setHolder(fakeClient *tools.FakeEtcdClient, holder string) {
+	fakeClient.Mutex.Lock()
+	defer fakeClient.Mutex.Unlock()
+	if holder == "" {
+		delete(fakeClient.Data, lockKey)
+		return
+	}
+	fakeClient.Data[lockKey] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Value: holder, ModifiedIndex: 1}},
+	}
+}
+
+func holder(fakeClient *tools.FakeEtcdClient) string {
+	fakeClient.Mutex.Lock()
+	defer fakeClient.Mutex.Unlock()
+	result := fakeClient.Data[lockKey]
+	if result.R == nil || result.R.Node == nil {
+		return ""
+	}
+	return result.R.Node.Value
+}
+
+func TestNewLeaderElectorTTL(t *testing.T) {
+	table := map[time.Duration]uint64{
+		0:                       1,
+		300 * time.Millisecond:  1,
+		15 * time.Second:        15,
+		1500 * time.Millisecond: 2,
+	}
+	for ttl, expected := range table {
+		if e := NewLeaderElector(nil, lockKey, "a", ttl); e.ttl != expected {
+			t.Errorf("%v: expected a TTL of %d seconds, got %d", ttl, expected, e.ttl)
+		}
+	}
+}
+
+func TestTryAcquireOrRenew(t *testing.T) {
+	fakeClient := newFakeClient(t)
+	e := NewLeaderElector(fakeClient, lockKey, "a", 15*time.Second)
+
+	if !e.tryAcquireOrRenew() {
+		t.Errorf("expected a free lock to be acquired")
+	}
+	if h := holder(fakeClient); h != "a" {
+		t.Errorf("expected the lock to be held by a, got %q", h)
+	}
+	if fakeClient.LastSetTTL != 15 {
+		t.Errorf("expected a TTL of 15 seconds, got %d", fakeClient.LastSetTTL)
+	}
+	if !e.tryAcquireOrRenew() {
+		t.Errorf("expected a held lock to be renewed")
+	}
+
+	setHolder(fakeClient, "b")
+	if e.tryAcquireOrRenew() {
+		t.Errorf("expected a lock held by another candidate not to be acquired")
+	}
+	if h := holder(fakeClient); h != "b" {
+		t.Errorf("expected the lock to stay with b, got %q", h)
+	}
+}
+
+func TestRun(t *testing.T) {
+	fakeClient := newFakeClient(t)
+	setHolder(fakeClient, "b")
+	e := NewLeaderElector(fakeClient, lockKey, "a", 15*time.Second)
+	e.period = 5 * time.Millisecond
+
+	leading := make(chan (<-chan struct{}))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Run(func(lost <-chan struct{}) {
+			leading <- lost
+			<-lost
+		}, stop)
+	}()
+
+	select {
+	case <-leading:
+		t.Fatalf("unexpected leadership while the lock is held by b")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The lock of b expires.
+	setHolder(fakeClient, "")
+	var lost <-chan struct{}
+	select {
+	case lost = <-leading:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the lock to be acquired once it is free")
+	}
+
+	// b takes the lock over, as if a had failed to renew it in time.
+	setHolder(fakeClient, "b")
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatalf("expected leadership to be lost")
+	}
+
+	setHolder(fakeClient, "")
+	select {
+	case lost = <-leading:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the lock to be acquired again")
+	}
+
+	close(stop)
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatalf("expected leadership to end when stopped")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Run to return when stopped")
+	}
+}
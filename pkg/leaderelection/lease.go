@@ -0,0 +1,106 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// LeaseHeartbeat holds a Lease through the API on behalf of one holder, and
+// renews it twice per lease duration, so that operators can tell from the
+// renew time of the lease whether the holder is still running. A lease held by
+// another holder is only taken over once it has not been renewed for its
+// duration.
+type LeaseHeartbeat struct {
+	client   client.LeaseInterface
+	ctx      api.Context
+	name     string
+	id       string
+	duration time.Duration
+	now      func() time.Time
+}
+
+// NewLeaseHeartbeat returns a LeaseHeartbeat which holds the lease name in
+// namespace as id. The lease is stale duration after it was last renewed,
+// rounded up to whole seconds.
+func NewLeaseHeartbeat(c client.LeaseInterface, namespace, name, id string, duration time.Duration) *LeaseHeartbeat {
+	seconds := math.Ceil(duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &LeaseHeartbeat{
+		client:   c,
+		ctx:      api.WithNamespace(api.NewContext(), namespace),
+		name:     name,
+		id:       id,
+		duration: time.Duration(seconds) * time.Second,
+		now:      time.Now,
+	}
+}
+
+// Run acquires or renews the lease every half lease duration until stop is
+// closed.
+func (h *LeaseHeartbeat) Run(stop <-chan struct{}) {
+	util.Until(func() {
+		if err := h.tryAcquireOrRenew(); err != nil {
+			glog.Errorf("Couldn't renew the lease %s: %v", h.name, err)
+		}
+	}, h.duration/2, stop)
+}
+
+// tryAcquireOrRenew creates the lease if it does not exist, renews it if it
+// is held by this holder, and takes it over if it is stale.
+func (h *LeaseHeartbeat) tryAcquireOrRenew() error {
+	now := util.Time{Time: h.now()}
+	lease, err := h.client.GetLease(h.ctx, h.name)
+	if errors.IsNotFound(err) {
+		_, err = h.client.CreateLease(h.ctx, &api.Lease{
+			TypeMeta: api.TypeMeta{ID: h.name},
+			Spec: api.LeaseSpec{
+				HolderIdentity:       h.id,
+				LeaseDurationSeconds: int(h.duration / time.Second),
+				AcquireTime:          now,
+				RenewTime:            now,
+			},
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if lease.Spec.HolderIdentity != h.id {
+		expiry := lease.Spec.RenewTime.Add(time.Duration(lease.Spec.LeaseDurationSeconds) * time.Second)
+		if now.Before(expiry) {
+			return fmt.Errorf("the lease is held by %s until %v", lease.Spec.HolderIdentity, expiry)
+		}
+		glog.Infof("%s took over the stale lease %s from %s", h.id, h.name, lease.Spec.HolderIdentity)
+		lease.Spec.HolderIdentity = h.id
+		lease.Spec.AcquireTime = now
+	}
+	lease.Spec.LeaseDurationSeconds = int(h.duration / time.Second)
+	lease.Spec.RenewTime = now
+	_, err = h.client.UpdateLease(h.ctx, lease)
+	return err
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// fakeLeaseClient stores at most one lease.
+type fakeLeaseClient struct {
+	lease *api.Lease
+}
+
+func (f *fakeLeaseClient) GetLease(ctx api.Context, id string) (*api.Lease, error) {
+	if f.lease == nil {
+		return nil, errors.NewNotFound("lease", id)
+	}
+	lease := *f.lease
+	return &lease, nil
+}
+
+func (f *fakeLeaseClient) CreateLease(ctx api.Context, lease *api.Lease) (*api.Lease, error) {
+	if f.lease != nil {
+		return nil, errors.NewAlreadyExists("lease", lease.ID)
+	}
+	if namespace, _ := api.NamespaceFrom(ctx); namespace != api.NamespaceSystem {
+		return nil, errors.NewBadRequest("unexpected namespace " + namespace)
+	}
+	f.lease = lease
+	return lease, nil
+}
+
+func (f *fakeLeaseClient) UpdateLease(ctx api.Context, lease *api.Lease) (*api.Lease, error) {
+	f.lease = lease
+	return lease, nil
+}
+
+func TestLeaseHeartbeat(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fake := &fakeLeaseClient{}
+	h := NewLeaseHeartbeat(fake, api.NamespaceSystem, "kube-controller-manager", "a", 15*time.Second)
+	h.now = func() time.Time { return start }
+
+	if err := h.tryAcquireOrRenew(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec := fake.lease.Spec
+	if spec.HolderIdentity != "a" || spec.LeaseDurationSeconds != 15 || !spec.AcquireTime.Equal(start) || !spec.RenewTime.Equal(start) {
+		t.Errorf("unexpected lease %#v", spec)
+	}
+
+	renewed := start.Add(7 * time.Second)
+	h.now = func() time.Time { return renewed }
+	if err := h.tryAcquireOrRenew(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec := fake.lease.Spec; !spec.AcquireTime.Equal(start) || !spec.RenewTime.Equal(renewed) {
+		t.Errorf("expected the lease to be renewed, got %#v", spec)
+	}
+}
+
+func TestLeaseHeartbeatHeldByOther(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fake := &fakeLeaseClient{lease: &api.Lease{
+		TypeMeta: api.TypeMeta{ID: "kube-controller-manager", ResourceVersion: "1"},
+		Spec: api.LeaseSpec{
+			HolderIdentity:       "b",
+			LeaseDurationSeconds: 15,
+			AcquireTime:          util.Time{Time: start},
+			RenewTime:            util.Time{Time: start},
+		},
+	}}
+	h := NewLeaseHeartbeat(fake, api.NamespaceSystem, "kube-controller-manager", "a", 15*time.Second)
+
+	h.now = func() time.Time { return start.Add(10 * time.Second) }
+	if err := h.tryAcquireOrRenew(); err == nil {
+		t.Errorf("expected an error while the lease is held by another holder")
+	}
+	if fake.lease.Spec.HolderIdentity != "b" {
+		t.Errorf("expected the lease to be left alone, got %#v", fake.lease.Spec)
+	}
+
+	stale := start.Add(20 * time.Second)
+	h.now = func() time.Time { return stale }
+	if err := h.tryAcquireOrRenew(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec := fake.lease.Spec; spec.HolderIdentity != "a" || !spec.AcquireTime.Equal(stale) || !spec.RenewTime.Equal(stale) {
+		t.Errorf("expected the stale lease to be taken over, got %#v", spec)
+	}
+}
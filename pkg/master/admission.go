@@ -0,0 +1,610 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/minion"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resources"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// AdmissionOperation is the kind of write an AdmissionController is asked to admit.
+type AdmissionOperation string
+
+const (
+	AdmissionCreate AdmissionOperation = "CREATE"
+	AdmissionUpdate AdmissionOperation = "UPDATE"
+	AdmissionDelete AdmissionOperation = "DELETE"
+)
+
+// AdmissionAttributes describes a write that is about to be passed to storage.
+type AdmissionAttributes struct {
+	// The name the storage is served under, e.g. "pods".
+	Resource string
+	// The namespace of the request.
+	Namespace string
+	// The id of the object. Only set for deletes.
+	Name      string
+	Operation AdmissionOperation
+	// The object being created or updated. Nil for deletes.
+	Object runtime.Object
+	// The user making the request, if it is known.
+	User user.Info
+}
+
+// AdmissionController decides whether a write may proceed.
+type AdmissionController interface {
+	// Admit returns an error if the write described by a must be rejected.
+	Admit(a AdmissionAttributes) error
+}
+
+// AdmissionControllerFunc adapts an ordinary function to the AdmissionController interface.
+type AdmissionControllerFunc func(a AdmissionAttributes) error
+
+// Admit calls f(a).
+func (f AdmissionControllerFunc) Admit(a AdmissionAttributes) error {
+	return f(a)
+}
+
+// AdmittingStorage passes every create, update and delete through a chain of
+// AdmissionControllers before delegating it to the wrapped storage. Writes that
+// are rejected fail with a Forbidden error, or with the Invalid error the
+// plugin returned.
+type AdmittingStorage struct {
+	apiserver.RESTStorage
+	resource string
+	plugins  []AdmissionController
+}
+
+// NewAdmittingStorage returns storage wrapped in an AdmittingStorage. The result
+// still implements apiserver.ResourceWatcher and apiserver.Redirector if storage
+// does. If storage is an apiserver.NamedCreater, so is the result, and its
+// creates are admitted too.
+func NewAdmittingStorage(resource string, storage apiserver.RESTStorage, plugins []AdmissionController) apiserver.RESTStorage {
+	s := &AdmittingStorage{storage, resource, plugins}
+	watcher, isWatcher := storage.(apiserver.ResourceWatcher)
+	redirector, isRedirector := storage.(apiserver.Redirector)
+	creater, isNamedCreater := storage.(apiserver.NamedCreater)
+	switch {
+	case isNamedCreater:
+		return &admittingNamedCreater{s, creater}
+	case isWatcher && isRedirector:
+		return struct {
+			*AdmittingStorage
+			apiserver.ResourceWatcher
+			apiserver.Redirector
+		}{s, watcher, redirector}
+	case isWatcher:
+		return struct {
+			*AdmittingStorage
+			apiserver.ResourceWatcher
+		}{s, watcher}
+	case isRedirector:
+		return struct {
+			*AdmittingStorage
+			apiserver.Redirector
+		}{s, redirector}
+	}
+	return s
+}
+
+// admit runs the admission chain, stopping at the first plugin that rejects.
+func (s *AdmittingStorage) admit(ctx api.Context, op AdmissionOperation, name string, obj runtime.Object) error {
+	a := AdmissionAttributes{
+		Resource:  s.resource,
+		Name:      name,
+		Operation: op,
+		Object:    obj,
+	}
+	a.Namespace, _ = api.NamespaceFrom(ctx)
+	a.User, _ = api.UserFrom(ctx)
+	for _, plugin := range s.plugins {
+		if err := plugin.Admit(a); err != nil {
+			if errors.IsInvalid(err) {
+				// The causes of invalid objects are reported as they are.
+				return err
+			}
+			if len(name) == 0 && obj != nil {
+				if meta, err := api.TypeMetaFor(obj); err == nil {
+					name = meta.ID
+				}
+			}
+			return errors.NewForbidden(s.resource, name, err)
+		}
+	}
+	return nil
+}
+
+// NamespaceScoped returns whether the resources of the wrapped storage are in a
+// namespace, as apiserver.Scoper.
+func (s *AdmittingStorage) NamespaceScoped() bool {
+	if scoper, ok := s.RESTStorage.(apiserver.Scoper); ok {
+		return scoper.NamespaceScoped()
+	}
+	return true
+}
+
+func (s *AdmittingStorage) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	if err := s.admit(ctx, AdmissionCreate, "", obj); err != nil {
+		return nil, err
+	}
+	return s.RESTStorage.Create(ctx, obj)
+}
+
+func (s *AdmittingStorage) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	if err := s.admit(ctx, AdmissionUpdate, "", obj); err != nil {
+		return nil, err
+	}
+	return s.RESTStorage.Update(ctx, obj)
+}
+
+func (s *AdmittingStorage) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	if err := s.admit(ctx, AdmissionDelete, id, nil); err != nil {
+		return nil, err
+	}
+	return s.RESTStorage.Delete(ctx, id)
+}
+
+// admittingNamedCreater is an AdmittingStorage which also admits the creates
+// made through apiserver.NamedCreater.
+type admittingNamedCreater struct {
+	*AdmittingStorage
+	creater apiserver.NamedCreater
+}
+
+func (s *admittingNamedCreater) CreateNamed(ctx api.Context, id string, obj runtime.Object) (<-chan runtime.Object, error) {
+	if err := s.admit(ctx, AdmissionCreate, id, obj); err != nil {
+		return nil, err
+	}
+	return s.creater.CreateNamed(ctx, id, obj)
+}
+
+// quotaCache holds the resource quotas of every namespace. They are reloaded
+// from the registry once they are older than period.
+type quotaCache struct {
+	lock      sync.Mutex
+	registry  generic.Registry
+	period    time.Duration
+	now       func() time.Time
+	refreshed time.Time
+	quotas    map[string][]api.ResourceQuota
+}
+
+// get returns the quotas of namespace.
+func (c *quotaCache) get(namespace string) ([]api.ResourceQuota, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.quotas == nil || c.now().Sub(c.refreshed) >= c.period {
+		obj, err := c.registry.List(api.NewContext(), generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }))
+		if err != nil {
+			return nil, err
+		}
+		list, ok := obj.(*api.ResourceQuotaList)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object: %#v", obj)
+		}
+		quotas := map[string][]api.ResourceQuota{}
+		for _, quota := range list.Items {
+			quotas[quota.Namespace] = append(quotas[quota.Namespace], quota)
+		}
+		c.quotas = quotas
+		c.refreshed = c.now()
+	}
+	return c.quotas[namespace], nil
+}
+
+// podUsage returns the resources used by pods: their number, and the sum of
+// the CPU (in millicores) and memory (in bytes) of their containers.
+func podUsage(pods []api.Pod) map[api.ResourceName]int {
+	usage := map[api.ResourceName]int{resources.Pods: len(pods)}
+	for i := range pods {
+		for _, container := range pods[i].DesiredState.Manifest.Containers {
+			usage[resources.CPU] += container.CPU
+			usage[resources.Memory] += container.Memory
+		}
+	}
+	return usage
+}
+
+// quotaResources are the resources NewResourceQuotaAdmission enforces, in the
+// order they are checked.
+var quotaResources = []api.ResourceName{resources.Pods, resources.CPU, resources.Memory}
+
+// NewResourceQuotaAdmission returns a plugin that rejects the creation or update
+// of a pod if the pods of its namespace would then use more than a quota of that
+// namespace allows. Quotas are read from quotas at most once every refreshPeriod;
+// usage is computed from pods on every request.
+func NewResourceQuotaAdmission(quotas generic.Registry, pods pod.Registry, refreshPeriod time.Duration) AdmissionController {
+	return newResourceQuotaAdmission(&quotaCache{registry: quotas, period: refreshPeriod, now: time.Now}, pods)
+}
+
+func newResourceQuotaAdmission(cache *quotaCache, pods pod.Registry) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Resource != "pods" || a.Operation == AdmissionDelete {
+			return nil
+		}
+		newPod, ok := a.Object.(*api.Pod)
+		if !ok {
+			return nil
+		}
+		quotas, err := cache.get(a.Namespace)
+		if err != nil || len(quotas) == 0 {
+			return err
+		}
+		list, err := pods.ListPods(api.WithNamespace(api.NewContext(), a.Namespace), labels.Everything())
+		if err != nil {
+			return err
+		}
+		// An update replaces the stored pod of the same name.
+		counted := []api.Pod{*newPod}
+		for _, existing := range list.Items {
+			if existing.ID != newPod.ID {
+				counted = append(counted, existing)
+			}
+		}
+		usage := podUsage(counted)
+		for _, quota := range quotas {
+			for _, name := range quotaResources {
+				limit, found := quota.Hard[name]
+				if found && usage[name] > limit.IntVal {
+					return fmt.Errorf("resource quota %q limits %s to %d; this would use %d", quota.ID, name, limit.IntVal, usage[name])
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// NewValidationAdmission returns a plugin that rejects creates and updates of
+// objects which fail validation.Validate with an Invalid error, whose causes
+// name the invalid fields. Storages fill in the namespace of objects which lack
+// one, and the ID of some new objects, before they validate them again, so a
+// missing namespace or ID is left to them.
+func NewValidationAdmission() AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation == AdmissionDelete {
+			return nil
+		}
+		meta, err := api.TypeMetaFor(a.Object)
+		if err != nil {
+			return nil
+		}
+		allErrs := errors.ErrorList{}
+		for _, err := range validation.Validate(a.Object) {
+			if v, ok := err.(errors.ValidationError); ok {
+				if v.Field == "namespace" && len(meta.Namespace) == 0 {
+					continue
+				}
+				if v.Field == "id" && len(meta.ID) == 0 && a.Operation == AdmissionCreate {
+					continue
+				}
+			}
+			allErrs = append(allErrs, err)
+		}
+		if len(allErrs) == 0 {
+			return nil
+		}
+		_, kind, _ := api.Scheme.ObjectVersionAndKind(a.Object)
+		return errors.NewInvalid(kind, meta.ID, allErrs)
+	})
+}
+
+// NewRequiredLabelsAdmission returns a plugin that rejects creates and updates
+// of labeled objects which lack any of the given label keys.
+func NewRequiredLabelsAdmission(keys ...string) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation == AdmissionDelete {
+			return nil
+		}
+		labels, err := api.LabelsFor(a.Object)
+		if err != nil {
+			// Objects without labels have nothing to enforce.
+			return nil
+		}
+		missing := []string{}
+		for _, key := range keys {
+			if _, ok := labels[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("missing required labels %v", missing)
+		}
+		return nil
+	})
+}
+
+// NewNamespaceExistsAdmission returns a plugin that rejects the creation of objects
+// in a namespace which is not stored in namespaces. The default and system
+// namespaces are always taken to exist, and namespaces themselves are not in a
+// namespace.
+func NewNamespaceExistsAdmission(namespaces generic.Registry) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation != AdmissionCreate || a.Resource == "namespaces" || a.Namespace == api.NamespaceDefault || a.Namespace == api.NamespaceSystem {
+			return nil
+		}
+		if _, err := namespaces.Get(api.NewContext(), a.Namespace); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("namespace %q does not exist", a.Namespace)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// DefaultServiceAccount is the service account that pods which name none run as.
+const DefaultServiceAccount = "default"
+
+// NewServiceAccountAdmission returns a plugin that sets the service account of
+// new pods which name none to DefaultServiceAccount, and rejects new pods whose
+// service account is not stored in accounts.
+func NewServiceAccountAdmission(accounts generic.Registry) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation != AdmissionCreate || a.Resource != "pods" {
+			return nil
+		}
+		newPod, ok := a.Object.(*api.Pod)
+		if !ok {
+			return nil
+		}
+		if len(newPod.DesiredState.ServiceAccount) == 0 {
+			newPod.DesiredState.ServiceAccount = DefaultServiceAccount
+		}
+		ctx := api.WithNamespace(api.NewContext(), a.Namespace)
+		if _, err := accounts.Get(ctx, newPod.DesiredState.ServiceAccount); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("service account %q does not exist in namespace %q", newPod.DesiredState.ServiceAccount, a.Namespace)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// limitedResources are the container resources NewLimitRangerAdmission defaults
+// and bounds, in the order they are checked.
+var limitedResources = []api.ResourceName{resources.CPU, resources.Memory}
+
+// containerResource returns a pointer to the amount of the named resource which
+// container uses, or nil if containers do not specify it.
+func containerResource(container *api.Container, name api.ResourceName) *int {
+	switch name {
+	case resources.CPU:
+		return &container.CPU
+	case resources.Memory:
+		return &container.Memory
+	}
+	return nil
+}
+
+// checkLimit returns an error if what uses an amount of the named resource
+// outside the bounds of item.
+func checkLimit(limitRange, what string, name api.ResourceName, used int, item *api.LimitRangeItem) error {
+	if min, ok := item.Min[name]; ok && used < min.IntVal {
+		return fmt.Errorf("limit range %q requires %s to use at least %d %s; it uses %d", limitRange, what, min.IntVal, name, used)
+	}
+	if max, ok := item.Max[name]; ok && used > max.IntVal {
+		return fmt.Errorf("limit range %q allows %s to use at most %d %s; it uses %d", limitRange, what, max.IntVal, name, used)
+	}
+	return nil
+}
+
+// NewLimitRangerAdmission returns a plugin that, on the creation of a pod, gives
+// its containers the default resources of the limit ranges in its namespace
+// wherever they specify none, and then rejects the pod if it or any of its
+// containers uses resources outside the bounds of those limit ranges.
+func NewLimitRangerAdmission(limitRanges generic.Registry) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation != AdmissionCreate || a.Resource != "pods" {
+			return nil
+		}
+		newPod, ok := a.Object.(*api.Pod)
+		if !ok {
+			return nil
+		}
+		ctx := api.WithNamespace(api.NewContext(), a.Namespace)
+		obj, err := limitRanges.List(ctx, generic.MatcherFunc(func(obj runtime.Object) (bool, error) {
+			limitRange, ok := obj.(*api.LimitRange)
+			return ok && limitRange.Namespace == a.Namespace, nil
+		}))
+		if err != nil {
+			return err
+		}
+		list, ok := obj.(*api.LimitRangeList)
+		if !ok {
+			return fmt.Errorf("unexpected limit range list: %#v", obj)
+		}
+		containers := newPod.DesiredState.Manifest.Containers
+		for _, limitRange := range list.Items {
+			for _, item := range limitRange.Limits {
+				if item.Type != api.LimitTypeContainer {
+					continue
+				}
+				for i := range containers {
+					for _, name := range limitedResources {
+						value, ok := item.Default[name]
+						if used := containerResource(&containers[i], name); ok && *used == 0 {
+							*used = value.IntVal
+						}
+					}
+				}
+			}
+		}
+		for _, limitRange := range list.Items {
+			for j := range limitRange.Limits {
+				item := &limitRange.Limits[j]
+				for _, name := range limitedResources {
+					switch item.Type {
+					case api.LimitTypeContainer:
+						for i := range containers {
+							what := fmt.Sprintf("container %q", containers[i].Name)
+							if err := checkLimit(limitRange.ID, what, name, *containerResource(&containers[i], name), item); err != nil {
+								return err
+							}
+						}
+					case api.LimitTypePod:
+						total := 0
+						for i := range containers {
+							total += *containerResource(&containers[i], name)
+						}
+						if err := checkLimit(limitRange.ID, "the pod", name, total, item); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// NewConfigMapRefAdmission returns a plugin that rejects the creation of pods
+// with environment variables taken from a config map in configMaps which does
+// not exist in the pod's namespace, or which lacks the selected key.
+func NewConfigMapRefAdmission(configMaps generic.Registry) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation != AdmissionCreate || a.Resource != "pods" {
+			return nil
+		}
+		newPod, ok := a.Object.(*api.Pod)
+		if !ok {
+			return nil
+		}
+		ctx := api.WithNamespace(api.NewContext(), a.Namespace)
+		for _, container := range newPod.DesiredState.Manifest.Containers {
+			for _, env := range container.Env {
+				if env.ValueFrom == nil || env.ValueFrom.ConfigMapKeyRef == nil {
+					continue
+				}
+				ref := env.ValueFrom.ConfigMapKeyRef
+				obj, err := configMaps.Get(ctx, ref.Name)
+				if err != nil {
+					if errors.IsNotFound(err) {
+						return fmt.Errorf("config map %q used by container %q does not exist in namespace %q", ref.Name, container.Name, a.Namespace)
+					}
+					return err
+				}
+				configMap, ok := obj.(*api.ConfigMap)
+				if !ok {
+					return fmt.Errorf("unexpected config map: %#v", obj)
+				}
+				if _, ok := configMap.Data[ref.Key]; !ok {
+					return fmt.Errorf("config map %q used by container %q has no key %q", ref.Name, container.Name, ref.Key)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// NewNetworkPolicyAdmission returns a plugin that rejects creates and updates of
+// network policies whose pod or namespace selectors cannot be parsed.
+func NewNetworkPolicyAdmission() AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		if a.Operation == AdmissionDelete || a.Resource != "networkPolicies" {
+			return nil
+		}
+		policy, ok := a.Object.(*api.NetworkPolicy)
+		if !ok {
+			return nil
+		}
+		if _, err := labels.ParseSelector(policy.Spec.PodSelector); err != nil {
+			return fmt.Errorf("invalid pod selector %q: %v", policy.Spec.PodSelector, err)
+		}
+		for i, rule := range policy.Spec.Ingress {
+			for j, peer := range rule.From {
+				if _, err := labels.ParseSelector(peer.PodSelector); err != nil {
+					return fmt.Errorf("invalid pod selector %q in ingress[%d].from[%d]: %v", peer.PodSelector, i, j, err)
+				}
+				if _, err := labels.ParseSelector(peer.NamespaceSelector); err != nil {
+					return fmt.Errorf("invalid namespace selector %q in ingress[%d].from[%d]: %v", peer.NamespaceSelector, i, j, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+const (
+	// PriorityClassAnnotation names the priority class of a pod.
+	PriorityClassAnnotation = "priorityClass"
+	// SystemCriticalPriorityClass is the priority class of pods the cluster
+	// cannot run without.
+	SystemCriticalPriorityClass = "system-critical"
+)
+
+// NewNoPreemptibleCriticalAdmission returns a plugin that rejects placing pods of
+// the system-critical priority class on minions in minions which are backed by
+// preemptible instances, whether the pod names its host itself or is bound to
+// one. The pods of bindings are looked up in pods.
+func NewNoPreemptibleCriticalAdmission(minions minion.Registry, pods pod.Registry) AdmissionController {
+	return AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		var (
+			newPod *api.Pod
+			host   string
+		)
+		switch {
+		case a.Resource == "pods" && a.Operation != AdmissionDelete:
+			p, ok := a.Object.(*api.Pod)
+			if !ok {
+				return nil
+			}
+			newPod, host = p, p.DesiredState.Host
+		case a.Resource == "bindings" && a.Operation == AdmissionCreate:
+			binding, ok := a.Object.(*api.Binding)
+			if !ok {
+				return nil
+			}
+			p, err := pods.GetPod(api.WithNamespace(api.NewContext(), a.Namespace), binding.PodID)
+			if err != nil {
+				return err
+			}
+			newPod, host = p, binding.Host
+		default:
+			return nil
+		}
+		if len(host) == 0 || newPod.Annotations[PriorityClassAnnotation] != SystemCriticalPriorityClass {
+			return nil
+		}
+		node, err := minions.GetMinion(api.NewContext(), host)
+		if err != nil {
+			if err == minion.ErrDoesNotExist || errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if node != nil && minion.IsPreemptible(node) {
+			return fmt.Errorf("pod %q is %s and cannot run on preemptible minion %q", newPod.ID, SystemCriticalPriorityClass, host)
+		}
+		return nil
+	})
+}
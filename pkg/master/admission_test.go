@@ -0,0 +1,746 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	apierrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/resources"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// fakePodStorage records the writes that reach it.
+type fakePodStorage struct {
+	created, deleted bool
+}
+
+func (s *fakePodStorage) New() runtime.Object { return &api.Pod{} }
+
+func (s *fakePodStorage) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return &api.PodList{}, nil
+}
+
+func (s *fakePodStorage) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return &api.Pod{TypeMeta: api.TypeMeta{ID: id}}, nil
+}
+
+func (s *fakePodStorage) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	s.created = true
+	return apiserver.MakeAsync(func() (runtime.Object, error) { return obj, nil }), nil
+}
+
+func (s *fakePodStorage) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return apiserver.MakeAsync(func() (runtime.Object, error) { return obj, nil }), nil
+}
+
+func (s *fakePodStorage) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	s.deleted = true
+	return apiserver.MakeAsync(func() (runtime.Object, error) { return &api.Status{Status: api.StatusSuccess}, nil }), nil
+}
+
+// fakeWatchablePodStorage is a fakePodStorage that can be watched.
+type fakeWatchablePodStorage struct {
+	fakePodStorage
+}
+
+func (s *fakeWatchablePodStorage) Watch(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
+	return watch.NewFake(), nil
+}
+
+// fakeEvictionStorage is a fakePodStorage whose objects are created by name.
+type fakeEvictionStorage struct {
+	fakePodStorage
+}
+
+func (s *fakeEvictionStorage) CreateNamed(ctx api.Context, id string, obj runtime.Object) (<-chan runtime.Object, error) {
+	return s.Create(ctx, obj)
+}
+
+func TestAdmittingStorage(t *testing.T) {
+	reject := AdmissionControllerFunc(func(a AdmissionAttributes) error { return errors.New("rejected") })
+	admit := AdmissionControllerFunc(func(a AdmissionAttributes) error { return nil })
+	pod := runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}})
+
+	table := map[string]struct {
+		plugins      []AdmissionController
+		method, path string
+		body         []byte
+		expectedCode int
+		expectWrite  bool
+	}{
+		"create admitted": {
+			plugins:      []AdmissionController{admit, admit},
+			method:       "POST",
+			path:         "/api/v1beta1/pods?sync=true",
+			body:         []byte(pod),
+			expectedCode: http.StatusOK,
+			expectWrite:  true,
+		},
+		"create rejected": {
+			plugins:      []AdmissionController{admit, reject},
+			method:       "POST",
+			path:         "/api/v1beta1/pods?sync=true",
+			body:         []byte(pod),
+			expectedCode: http.StatusForbidden,
+		},
+		"delete admitted": {
+			plugins:      []AdmissionController{admit},
+			method:       "DELETE",
+			path:         "/api/v1beta1/pods/foo?sync=true",
+			expectedCode: http.StatusOK,
+			expectWrite:  true,
+		},
+		"delete rejected": {
+			plugins:      []AdmissionController{reject},
+			method:       "DELETE",
+			path:         "/api/v1beta1/pods/foo?sync=true",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+	for name, item := range table {
+		storage := &fakePodStorage{}
+		handler := apiserver.Handle(map[string]apiserver.RESTStorage{
+			"pods": NewAdmittingStorage("pods", storage, item.plugins),
+		}, latest.Codec, "/api/v1beta1", latest.SelfLinker)
+		server := httptest.NewServer(handler)
+
+		req, _ := http.NewRequest(item.method, server.URL+item.path, bytes.NewReader(item.body))
+		resp, err := http.DefaultClient.Do(req)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != item.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", name, item.expectedCode, resp.StatusCode)
+		}
+		if wrote := storage.created || storage.deleted; wrote != item.expectWrite {
+			t.Errorf("%s: expected write %v, got %v", name, item.expectWrite, wrote)
+		}
+	}
+}
+
+func TestAdmittingStorageAttributes(t *testing.T) {
+	var got AdmissionAttributes
+	record := AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		got = a
+		return nil
+	})
+	storage := NewAdmittingStorage("pods", &fakePodStorage{}, []AdmissionController{record})
+	ctx := api.WithNamespace(api.NewContext(), "other")
+
+	pod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
+	storage.Update(ctx, pod)
+	if got.Resource != "pods" || got.Namespace != "other" || got.Operation != AdmissionUpdate || got.Object != pod {
+		t.Errorf("unexpected attributes for update: %#v", got)
+	}
+	storage.Delete(ctx, "foo")
+	if got.Operation != AdmissionDelete || got.Name != "foo" || got.Object != nil {
+		t.Errorf("unexpected attributes for delete: %#v", got)
+	}
+}
+
+func TestNewAdmittingStorageKeepsInterfaces(t *testing.T) {
+	if _, ok := NewAdmittingStorage("pods", &fakePodStorage{}, nil).(apiserver.ResourceWatcher); ok {
+		t.Errorf("expected storage that cannot be watched to stay that way")
+	}
+	if _, ok := NewAdmittingStorage("pods", &fakeWatchablePodStorage{}, nil).(apiserver.ResourceWatcher); !ok {
+		t.Errorf("expected watchable storage to stay watchable")
+	}
+}
+
+func TestAdmittingStorageNamedCreate(t *testing.T) {
+	var got AdmissionAttributes
+	reject := AdmissionControllerFunc(func(a AdmissionAttributes) error {
+		got = a
+		return errors.New("no evictions")
+	})
+	fake := &fakeEvictionStorage{}
+	storage, ok := NewAdmittingStorage("pods/eviction", fake, []AdmissionController{reject}).(apiserver.NamedCreater)
+	if !ok {
+		t.Fatalf("expected storage created by name to stay that way")
+	}
+	eviction := &api.Eviction{}
+	if _, err := storage.CreateNamed(api.NewDefaultContext(), "foo", eviction); !apierrors.IsForbidden(err) {
+		t.Errorf("expected a forbidden error, got %v", err)
+	}
+	if fake.created {
+		t.Errorf("expected the rejected create not to reach the storage")
+	}
+	if got.Resource != "pods/eviction" || got.Name != "foo" || got.Operation != AdmissionCreate || got.Object != eviction {
+		t.Errorf("unexpected attributes for create: %#v", got)
+	}
+}
+
+func TestRequiredLabelsAdmission(t *testing.T) {
+	plugin := NewRequiredLabelsAdmission("name", "tier")
+	table := map[string]struct {
+		attributes AdmissionAttributes
+		admit      bool
+	}{
+		"all labels": {
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: &api.Pod{Labels: map[string]string{"name": "a", "tier": "b"}}},
+			admit:      true,
+		},
+		"missing label": {
+			attributes: AdmissionAttributes{Operation: AdmissionUpdate, Object: &api.Pod{Labels: map[string]string{"name": "a"}}},
+		},
+		"no labels": {
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: &api.Pod{}},
+		},
+		"unlabeled kind": {
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: &api.Binding{}},
+			admit:      true,
+		},
+		"delete": {
+			attributes: AdmissionAttributes{Operation: AdmissionDelete},
+			admit:      true,
+		},
+	}
+	for name, item := range table {
+		if err := plugin.Admit(item.attributes); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+}
+
+func TestValidationAdmission(t *testing.T) {
+	manifest := api.ContainerManifest{Version: "v1beta1"}
+	table := map[string]struct {
+		pod            *api.Pod
+		expectedCode   int
+		expectedFields []string
+	}{
+		"valid": {
+			pod:          &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}, DesiredState: api.PodState{Manifest: manifest}},
+			expectedCode: http.StatusOK,
+		},
+		"defaulted by storage": {
+			pod:          &api.Pod{DesiredState: api.PodState{Manifest: manifest}},
+			expectedCode: http.StatusOK,
+		},
+		"invalid": {
+			pod: &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+				Labels:       map[string]string{"name": "foo bar"},
+				DesiredState: api.PodState{Manifest: api.ContainerManifest{Version: "v0"}},
+			},
+			expectedCode:   422,
+			expectedFields: []string{"desiredState.manifest.version", "labels.name"},
+		},
+	}
+	for name, item := range table {
+		storage := &fakePodStorage{}
+		handler := apiserver.Handle(map[string]apiserver.RESTStorage{
+			"pods": NewAdmittingStorage("pods", storage, []AdmissionController{NewValidationAdmission()}),
+		}, latest.Codec, "/api/v1beta1", latest.SelfLinker)
+		server := httptest.NewServer(handler)
+
+		body := runtime.EncodeOrDie(latest.Codec, item.pod)
+		resp, err := http.Post(server.URL+"/api/v1beta1/pods?sync=true", "application/json", bytes.NewReader([]byte(body)))
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		var status api.Status
+		if item.expectedCode != http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+		}
+		resp.Body.Close()
+		if resp.StatusCode != item.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", name, item.expectedCode, resp.StatusCode)
+		}
+		if storage.created != (item.expectedCode == http.StatusOK) {
+			t.Errorf("%s: expected create %v, got %v", name, item.expectedCode == http.StatusOK, storage.created)
+		}
+		if item.expectedFields == nil {
+			continue
+		}
+		if status.Reason != api.StatusReasonInvalid || status.Details == nil {
+			t.Errorf("%s: unexpected status %#v", name, status)
+			continue
+		}
+		fields := []string{}
+		for _, cause := range status.Details.Causes {
+			fields = append(fields, cause.Field)
+		}
+		sort.Strings(fields)
+		if !reflect.DeepEqual(fields, item.expectedFields) {
+			t.Errorf("%s: expected causes for %v, got %#v", name, item.expectedFields, status.Details.Causes)
+		}
+	}
+}
+
+func TestNamespaceExistsAdmission(t *testing.T) {
+	namespaces := registrytest.NewGeneric(nil)
+	namespaces.Err = apierrors.NewNotFound("namespace", "missing")
+	plugin := NewNamespaceExistsAdmission(namespaces)
+	table := map[string]struct {
+		attributes AdmissionAttributes
+		admit      bool
+	}{
+		"default namespace": {
+			attributes: AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate},
+			admit:      true,
+		},
+		"system namespace": {
+			attributes: AdmissionAttributes{Resource: "leases", Namespace: api.NamespaceSystem, Operation: AdmissionCreate},
+			admit:      true,
+		},
+		"missing namespace": {
+			attributes: AdmissionAttributes{Resource: "pods", Namespace: "missing", Operation: AdmissionCreate},
+		},
+		"update in missing namespace": {
+			attributes: AdmissionAttributes{Resource: "pods", Namespace: "missing", Operation: AdmissionUpdate},
+			admit:      true,
+		},
+		"namespace itself": {
+			attributes: AdmissionAttributes{Resource: "namespaces", Namespace: "missing", Operation: AdmissionCreate},
+			admit:      true,
+		},
+	}
+	for name, item := range table {
+		if err := plugin.Admit(item.attributes); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+
+	namespaces.Err = nil
+	namespaces.Object = &api.Namespace{TypeMeta: api.TypeMeta{ID: "other"}}
+	if err := plugin.Admit(AdmissionAttributes{Resource: "pods", Namespace: "other", Operation: AdmissionCreate}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNamespaceExistsAdmissionRequest(t *testing.T) {
+	namespaces := registrytest.NewGeneric(nil)
+	namespaces.Err = apierrors.NewNotFound("namespace", "missing")
+	table := map[string]struct {
+		path         string
+		expectedCode int
+		expectWrite  bool
+	}{
+		"default namespace": {
+			path:         "/api/v1beta1/pods?sync=true",
+			expectedCode: http.StatusOK,
+			expectWrite:  true,
+		},
+		"missing namespace": {
+			path:         "/api/v1beta1/pods?sync=true&namespace=missing",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+	for name, item := range table {
+		storage := &fakePodStorage{}
+		handler := apiserver.Handle(map[string]apiserver.RESTStorage{
+			"pods": NewAdmittingStorage("pods", storage, []AdmissionController{NewNamespaceExistsAdmission(namespaces)}),
+		}, latest.Codec, "/api/v1beta1", latest.SelfLinker)
+		server := httptest.NewServer(handler)
+
+		body := runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}})
+		resp, err := http.Post(server.URL+item.path, "application/json", bytes.NewReader([]byte(body)))
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != item.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", name, item.expectedCode, resp.StatusCode)
+		}
+		if storage.created != item.expectWrite {
+			t.Errorf("%s: expected write %v, got %v", name, item.expectWrite, storage.created)
+		}
+	}
+}
+
+func TestServiceAccountAdmission(t *testing.T) {
+	accounts := registrytest.NewGeneric(nil)
+	accounts.Object = &api.ServiceAccount{TypeMeta: api.TypeMeta{ID: DefaultServiceAccount, Namespace: api.NamespaceDefault}}
+	plugin := NewServiceAccountAdmission(accounts)
+
+	newPod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}}
+	if err := plugin.Admit(AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: newPod}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := DefaultServiceAccount, newPod.DesiredState.ServiceAccount; e != a {
+		t.Errorf("expected service account %q, got %q", e, a)
+	}
+
+	newPod = &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}}
+	newPod.DesiredState.ServiceAccount = "build-robot"
+	if err := plugin.Admit(AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: newPod}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := "build-robot", newPod.DesiredState.ServiceAccount; e != a {
+		t.Errorf("expected service account %q, got %q", e, a)
+	}
+
+	accounts.Err = apierrors.NewNotFound("serviceAccount", DefaultServiceAccount)
+	table := map[string]struct {
+		attributes AdmissionAttributes
+		admit      bool
+	}{
+		"missing service account": {
+			attributes: AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: &api.Pod{}},
+		},
+		"pod update": {
+			attributes: AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionUpdate, Object: &api.Pod{}},
+			admit:      true,
+		},
+		"other resource": {
+			attributes: AdmissionAttributes{Resource: "services", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: &api.Service{}},
+			admit:      true,
+		},
+	}
+	for name, item := range table {
+		if err := plugin.Admit(item.attributes); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+}
+
+func TestLimitRangerAdmission(t *testing.T) {
+	limitRanges := registrytest.NewGeneric(&api.LimitRangeList{
+		Items: []api.LimitRange{
+			{
+				TypeMeta: api.TypeMeta{ID: "limits", Namespace: api.NamespaceDefault},
+				Limits: []api.LimitRangeItem{
+					{
+						Type:    api.LimitTypeContainer,
+						Min:     api.ResourceList{resources.CPU: util.NewIntOrStringFromInt(100)},
+						Max:     api.ResourceList{resources.Memory: util.NewIntOrStringFromInt(1000)},
+						Default: api.ResourceList{resources.CPU: util.NewIntOrStringFromInt(200), resources.Memory: util.NewIntOrStringFromInt(500)},
+					},
+					{
+						Type: api.LimitTypePod,
+						Max:  api.ResourceList{resources.CPU: util.NewIntOrStringFromInt(1000)},
+					},
+				},
+			},
+			{
+				TypeMeta: api.TypeMeta{ID: "elsewhere", Namespace: "other"},
+				Limits: []api.LimitRangeItem{{
+					Type: api.LimitTypeContainer,
+					Max:  api.ResourceList{resources.CPU: util.NewIntOrStringFromInt(1)},
+				}},
+			},
+		},
+	})
+	plugin := NewLimitRangerAdmission(limitRanges)
+	newPod := func(containers ...api.Container) *api.Pod {
+		p := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}}
+		p.DesiredState.Manifest.Containers = containers
+		return p
+	}
+	admit := func(p *api.Pod) error {
+		return plugin.Admit(AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: p})
+	}
+
+	defaulted := newPod(api.Container{Name: "a"}, api.Container{Name: "b", CPU: 300, Memory: 100})
+	if err := admit(defaulted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []api.Container{{Name: "a", CPU: 200, Memory: 500}, {Name: "b", CPU: 300, Memory: 100}}
+	if e, a := expected, defaulted.DesiredState.Manifest.Containers; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected containers %#v, got %#v", e, a)
+	}
+
+	table := map[string]struct {
+		pod   *api.Pod
+		admit bool
+	}{
+		"container below min": {pod: newPod(api.Container{Name: "a", CPU: 50})},
+		"container above max": {pod: newPod(api.Container{Name: "a", Memory: 2000})},
+		"pod above max":       {pod: newPod(api.Container{Name: "a", CPU: 600}, api.Container{Name: "b", CPU: 600})},
+		"within bounds":       {pod: newPod(api.Container{Name: "a", CPU: 500}, api.Container{Name: "b", CPU: 500}), admit: true},
+	}
+	for name, item := range table {
+		if err := admit(item.pod); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+
+	if err := plugin.Admit(AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionUpdate, Object: newPod(api.Container{Name: "a", CPU: 50})}); err != nil {
+		t.Errorf("unexpected error for an update: %v", err)
+	}
+}
+
+func TestConfigMapRefAdmission(t *testing.T) {
+	configMaps := registrytest.NewGeneric(nil)
+	configMaps.Object = &api.ConfigMap{
+		TypeMeta: api.TypeMeta{ID: "config", Namespace: api.NamespaceDefault},
+		Data:     map[string]string{"color": "blue"},
+	}
+	plugin := NewConfigMapRefAdmission(configMaps)
+	podWithRef := func(key string) *api.Pod {
+		p := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}}
+		p.DesiredState.Manifest.Containers = []api.Container{{
+			Name: "a",
+			Env: []api.EnvVar{
+				{Name: "PLAIN", Value: "value"},
+				{Name: "COLOR", ValueFrom: &api.EnvVarSource{ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config", Key: key}}},
+			},
+		}}
+		return p
+	}
+	admit := func(p *api.Pod) error {
+		return plugin.Admit(AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: p})
+	}
+
+	if err := admit(podWithRef("color")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := admit(podWithRef("size")); err == nil {
+		t.Errorf("expected a missing key to be rejected")
+	}
+	configMaps.Err = apierrors.NewNotFound("configMap", "config")
+	if err := admit(podWithRef("color")); err == nil {
+		t.Errorf("expected a missing config map to be rejected")
+	}
+	if err := admit(&api.Pod{TypeMeta: api.TypeMeta{ID: "bar", Namespace: api.NamespaceDefault}}); err != nil {
+		t.Errorf("unexpected error for a pod without references: %v", err)
+	}
+}
+
+func TestNetworkPolicyAdmission(t *testing.T) {
+	plugin := NewNetworkPolicyAdmission()
+	policyWith := func(podSelector, fromPods, fromNamespaces string) *api.NetworkPolicy {
+		return &api.NetworkPolicy{
+			TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+			Spec: api.NetworkPolicySpec{
+				PodSelector: podSelector,
+				Ingress: []api.NetworkPolicyIngressRule{{
+					From: []api.NetworkPolicyPeer{{PodSelector: fromPods, NamespaceSelector: fromNamespaces}},
+				}},
+			},
+		}
+	}
+	table := map[string]struct {
+		attributes AdmissionAttributes
+		admit      bool
+	}{
+		"valid selectors": {
+			attributes: AdmissionAttributes{Resource: "networkPolicies", Operation: AdmissionCreate, Object: policyWith("name=db,tier!=cache", "name=frontend", "")},
+			admit:      true,
+		},
+		"empty selectors": {
+			attributes: AdmissionAttributes{Resource: "networkPolicies", Operation: AdmissionUpdate, Object: policyWith("", "", "")},
+			admit:      true,
+		},
+		"invalid pod selector": {
+			attributes: AdmissionAttributes{Resource: "networkPolicies", Operation: AdmissionCreate, Object: policyWith("name=db=x", "", "team=ops")},
+		},
+		"invalid source pod selector": {
+			attributes: AdmissionAttributes{Resource: "networkPolicies", Operation: AdmissionUpdate, Object: policyWith("name=db", "name", "")},
+		},
+		"invalid source namespace selector": {
+			attributes: AdmissionAttributes{Resource: "networkPolicies", Operation: AdmissionCreate, Object: policyWith("name=db", "", "team:ops")},
+		},
+		"other resource": {
+			attributes: AdmissionAttributes{Resource: "pods", Operation: AdmissionCreate, Object: &api.Pod{}},
+			admit:      true,
+		},
+		"delete": {
+			attributes: AdmissionAttributes{Resource: "networkPolicies", Operation: AdmissionDelete},
+			admit:      true,
+		},
+	}
+	for name, item := range table {
+		if err := plugin.Admit(item.attributes); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+}
+
+func TestForbiddenError(t *testing.T) {
+	storage := NewAdmittingStorage("pods", &fakePodStorage{}, []AdmissionController{
+		NewRequiredLabelsAdmission("name"),
+	})
+	_, err := storage.Create(api.NewDefaultContext(), &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}})
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("expected a forbidden error, got %v", err)
+	}
+}
+
+// quotaPod returns a pod with a single container using cpu and memory.
+func quotaPod(id string, cpu, memory int) *api.Pod {
+	return &api.Pod{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		DesiredState: api.PodState{
+			Manifest: api.ContainerManifest{
+				Containers: []api.Container{{Name: "c", CPU: cpu, Memory: memory}},
+			},
+		},
+	}
+}
+
+func TestResourceQuotaAdmission(t *testing.T) {
+	existing := &api.PodList{Items: []api.Pod{*quotaPod("a", 500, 1000), *quotaPod("b", 250, 1000)}}
+	table := map[string]struct {
+		hard       api.ResourceList
+		attributes AdmissionAttributes
+		admit      bool
+	}{
+		"within all limits": {
+			hard: api.ResourceList{
+				"pods":   util.NewIntOrStringFromInt(3),
+				"cpu":    util.NewIntOrStringFromInt(1000),
+				"memory": util.NewIntOrStringFromInt(3000),
+			},
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: quotaPod("c", 250, 1000)},
+			admit:      true,
+		},
+		"too many pods": {
+			hard:       api.ResourceList{"pods": util.NewIntOrStringFromInt(2)},
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: quotaPod("c", 0, 0)},
+		},
+		"too much cpu": {
+			hard:       api.ResourceList{"cpu": util.NewIntOrStringFromInt(1000)},
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: quotaPod("c", 251, 0)},
+		},
+		"too much memory": {
+			hard:       api.ResourceList{"memory": util.NewIntOrStringFromInt(2500)},
+			attributes: AdmissionAttributes{Operation: AdmissionCreate, Object: quotaPod("c", 0, 501)},
+		},
+		"update replaces the stored pod": {
+			hard:       api.ResourceList{"pods": util.NewIntOrStringFromInt(2), "cpu": util.NewIntOrStringFromInt(1000)},
+			attributes: AdmissionAttributes{Operation: AdmissionUpdate, Object: quotaPod("a", 750, 0)},
+			admit:      true,
+		},
+		"other resources": {
+			hard:       api.ResourceList{"pods": util.NewIntOrStringFromInt(0)},
+			attributes: AdmissionAttributes{Resource: "services", Operation: AdmissionCreate, Object: &api.Service{}},
+			admit:      true,
+		},
+		"delete": {
+			hard:       api.ResourceList{"pods": util.NewIntOrStringFromInt(0)},
+			attributes: AdmissionAttributes{Operation: AdmissionDelete, Name: "a"},
+			admit:      true,
+		},
+	}
+	for name, item := range table {
+		quotas := registrytest.NewGeneric(&api.ResourceQuotaList{
+			Items: []api.ResourceQuota{{TypeMeta: api.TypeMeta{ID: "quota", Namespace: api.NamespaceDefault}, Hard: item.hard}},
+		})
+		plugin := NewResourceQuotaAdmission(quotas, registrytest.NewPodRegistry(existing), time.Minute)
+		attributes := item.attributes
+		attributes.Namespace = api.NamespaceDefault
+		if len(attributes.Resource) == 0 {
+			attributes.Resource = "pods"
+		}
+		if err := plugin.Admit(attributes); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+}
+
+func TestResourceQuotaAdmissionRefresh(t *testing.T) {
+	quotas := registrytest.NewGeneric(&api.ResourceQuotaList{})
+	now := time.Unix(0, 0)
+	cache := &quotaCache{registry: quotas, period: time.Minute, now: func() time.Time { return now }}
+	plugin := newResourceQuotaAdmission(cache, registrytest.NewPodRegistry(&api.PodList{}))
+	create := AdmissionAttributes{Resource: "pods", Namespace: api.NamespaceDefault, Operation: AdmissionCreate, Object: quotaPod("a", 0, 0)}
+
+	if err := plugin.Admit(create); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	quotas.ObjectList = &api.ResourceQuotaList{
+		Items: []api.ResourceQuota{{TypeMeta: api.TypeMeta{ID: "quota", Namespace: api.NamespaceDefault}, Hard: api.ResourceList{"pods": util.NewIntOrStringFromInt(0)}}},
+	}
+	now = now.Add(30 * time.Second)
+	if err := plugin.Admit(create); err != nil {
+		t.Errorf("expected the cached quotas to be used, got %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if err := plugin.Admit(create); err == nil {
+		t.Errorf("expected the refreshed quota to be enforced")
+	}
+}
+
+func TestNoPreemptibleCriticalAdmission(t *testing.T) {
+	minions := registrytest.NewMinionRegistry([]string{"regular", "spot"}, api.NodeResources{})
+	minions.Minions.Items[1].Annotations = map[string]string{cloudprovider.AWSCapacityTypeAnnotation: "SPOT"}
+	critical := &api.Pod{
+		TypeMeta: api.TypeMeta{
+			ID:          "foo",
+			Annotations: map[string]string{PriorityClassAnnotation: SystemCriticalPriorityClass},
+		},
+	}
+	pods := registrytest.NewPodRegistry(nil)
+	pods.Pod = critical
+	plugin := NewNoPreemptibleCriticalAdmission(minions, pods)
+
+	onHost := func(pod *api.Pod, host string) *api.Pod {
+		copied := *pod
+		copied.DesiredState.Host = host
+		return &copied
+	}
+	table := map[string]struct {
+		attributes AdmissionAttributes
+		admit      bool
+	}{
+		"critical pod without a host": {
+			attributes: AdmissionAttributes{Resource: "pods", Operation: AdmissionCreate, Object: critical},
+			admit:      true,
+		},
+		"critical pod on a regular minion": {
+			attributes: AdmissionAttributes{Resource: "pods", Operation: AdmissionCreate, Object: onHost(critical, "regular")},
+			admit:      true,
+		},
+		"critical pod on a preemptible minion": {
+			attributes: AdmissionAttributes{Resource: "pods", Operation: AdmissionUpdate, Object: onHost(critical, "spot")},
+		},
+		"ordinary pod on a preemptible minion": {
+			attributes: AdmissionAttributes{Resource: "pods", Operation: AdmissionCreate, Object: onHost(&api.Pod{}, "spot")},
+			admit:      true,
+		},
+		"critical pod bound to a regular minion": {
+			attributes: AdmissionAttributes{Resource: "bindings", Operation: AdmissionCreate, Object: &api.Binding{PodID: "foo", Host: "regular"}},
+			admit:      true,
+		},
+		"critical pod bound to a preemptible minion": {
+			attributes: AdmissionAttributes{Resource: "bindings", Operation: AdmissionCreate, Object: &api.Binding{PodID: "foo", Host: "spot"}},
+		},
+	}
+	for name, item := range table {
+		if err := plugin.Admit(item.attributes); (err == nil) != item.admit {
+			t.Errorf("%s: expected admit %v, got error %v", name, item.admit, err)
+		}
+	}
+}
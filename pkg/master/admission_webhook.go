@@ -0,0 +1,203 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/golang/glog"
+)
+
+const (
+	// WebhookFailurePolicyIgnore admits a write when its webhook cannot be reached
+	// or does not answer in time.
+	WebhookFailurePolicyIgnore = "Ignore"
+	// WebhookFailurePolicyFail rejects a write when its webhook cannot be reached
+	// or does not answer in time.
+	WebhookFailurePolicyFail = "Fail"
+
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+// WebhookConfig describes an admission webhook.
+type WebhookConfig struct {
+	// The https URL that AdmissionReviews are posted to.
+	URL string
+	// PEM encoded certificates which the certificate of the webhook must be
+	// signed by. Optional: defaults to the system roots.
+	CABundle []byte
+	// What happens to a write when the webhook fails: WebhookFailurePolicyFail,
+	// the default, or WebhookFailurePolicyIgnore.
+	FailurePolicy string
+	// How long the webhook has to answer. Optional: defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// AdmissionReview is posted to an admission webhook with its Request set. The
+// webhook answers with an AdmissionReview with its Response set.
+type AdmissionReview struct {
+	Request  *AdmissionReviewRequest  `json:"request,omitempty"`
+	Response *AdmissionReviewResponse `json:"response,omitempty"`
+}
+
+// AdmissionReviewRequest describes the write to admit, as AdmissionAttributes.
+type AdmissionReviewRequest struct {
+	Resource  string             `json:"resource"`
+	Namespace string             `json:"namespace,omitempty"`
+	Name      string             `json:"name,omitempty"`
+	Operation AdmissionOperation `json:"operation"`
+	// The object being created or updated, in the latest version of the API.
+	// Unset for deletes.
+	Object   json.RawMessage `json:"object,omitempty"`
+	UserName string          `json:"userName,omitempty"`
+	UserUID  string          `json:"userUID,omitempty"`
+}
+
+// AdmissionReviewResponse tells whether a webhook admits a write.
+type AdmissionReviewResponse struct {
+	Allowed bool `json:"allowed"`
+	// Why the write is rejected. Optional.
+	Reason string `json:"reason,omitempty"`
+}
+
+// webhook is an admission webhook with the client it is reached with.
+type webhook struct {
+	url           string
+	failurePolicy string
+	client        *http.Client
+}
+
+// WebhookAdmissionController is an AdmissionController which asks admission
+// webhooks, in order, whether to admit a write. Every webhook must admit it.
+type WebhookAdmissionController struct {
+	webhooks []webhook
+}
+
+// NewWebhookAdmissionController returns a WebhookAdmissionController which asks
+// the given webhooks. It returns an error if any of them is misconfigured.
+func NewWebhookAdmissionController(configs []WebhookConfig) (*WebhookAdmissionController, error) {
+	c := &WebhookAdmissionController{}
+	for _, config := range configs {
+		u, err := url.Parse(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admission webhook URL %q: %v", config.URL, err)
+		}
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("admission webhook URL %q must use https", config.URL)
+		}
+		failurePolicy := config.FailurePolicy
+		switch failurePolicy {
+		case "":
+			failurePolicy = WebhookFailurePolicyFail
+		case WebhookFailurePolicyFail, WebhookFailurePolicyIgnore:
+		default:
+			return nil, fmt.Errorf("admission webhook %q has unknown failure policy %q", config.URL, failurePolicy)
+		}
+		tlsConfig := &tls.Config{}
+		if len(config.CABundle) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(config.CABundle) {
+				return nil, fmt.Errorf("admission webhook %q has no certificates in its CA bundle", config.URL)
+			}
+		}
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = defaultWebhookTimeout
+		}
+		c.webhooks = append(c.webhooks, webhook{
+			url:           config.URL,
+			failurePolicy: failurePolicy,
+			client: &http.Client{
+				Transport: &http.Transport{TLSClientConfig: tlsConfig},
+				Timeout:   timeout,
+			},
+		})
+	}
+	return c, nil
+}
+
+// Admit posts an AdmissionReview of a to every webhook, and rejects the write
+// as soon as one of them does. A webhook which fails rejects the write unless
+// its failure policy is WebhookFailurePolicyIgnore.
+func (c *WebhookAdmissionController) Admit(a AdmissionAttributes) error {
+	request := &AdmissionReviewRequest{
+		Resource:  a.Resource,
+		Namespace: a.Namespace,
+		Name:      a.Name,
+		Operation: a.Operation,
+	}
+	if a.Object != nil {
+		data, err := latest.Codec.Encode(a.Object)
+		if err != nil {
+			return err
+		}
+		request.Object = data
+	}
+	if a.User != nil {
+		request.UserName = a.User.GetName()
+		request.UserUID = a.User.GetUID()
+	}
+	body, err := json.Marshal(&AdmissionReview{Request: request})
+	if err != nil {
+		return err
+	}
+	for _, w := range c.webhooks {
+		response, err := w.review(body)
+		if err != nil {
+			if w.failurePolicy == WebhookFailurePolicyIgnore {
+				glog.Warningf("Ignoring the failure of admission webhook %s: %v", w.url, err)
+				continue
+			}
+			return fmt.Errorf("admission webhook %s failed: %v", w.url, err)
+		}
+		if !response.Allowed {
+			if len(response.Reason) == 0 {
+				return fmt.Errorf("admission webhook %s rejected the request", w.url)
+			}
+			return fmt.Errorf("admission webhook %s rejected the request: %s", w.url, response.Reason)
+		}
+	}
+	return nil
+}
+
+// review posts body, an AdmissionReview, to w and returns its response.
+func (w *webhook) review(body []byte) (*AdmissionReviewResponse, error) {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	var review AdmissionReview
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		return nil, err
+	}
+	if review.Response == nil {
+		return nil, fmt.Errorf("no response in the review")
+	}
+	return review.Response, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+)
+
+// caBundle returns the certificate of server in PEM.
+func caBundle(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
+}
+
+func TestWebhookAdmission(t *testing.T) {
+	var reviewed *AdmissionReviewRequest
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var review AdmissionReview
+		if err := json.NewDecoder(req.Body).Decode(&review); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		reviewed = review.Request
+		var pod api.Pod
+		if err := latest.Codec.DecodeInto(review.Request.Object, &pod); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		review.Response = &AdmissionReviewResponse{Allowed: true}
+		if pod.Labels["privileged"] == "true" {
+			review.Response = &AdmissionReviewResponse{Reason: "privileged pods are not allowed"}
+		}
+		json.NewEncoder(w).Encode(&review)
+	}))
+	defer server.Close()
+
+	controller, err := NewWebhookAdmissionController([]WebhookConfig{{URL: server.URL, CABundle: caBundle(server)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a := AdmissionAttributes{
+		Resource:  "pods",
+		Namespace: api.NamespaceDefault,
+		Operation: AdmissionCreate,
+		Object:    &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}},
+		User:      &user.DefaultInfo{Name: "alice", UID: "1"},
+	}
+	if err := controller.Admit(a); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if reviewed == nil || reviewed.Resource != "pods" || reviewed.Namespace != api.NamespaceDefault || reviewed.Operation != AdmissionCreate || reviewed.UserName != "alice" || reviewed.UserUID != "1" {
+		t.Errorf("unexpected review %#v", reviewed)
+	}
+
+	a.Object = &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}, Labels: map[string]string{"privileged": "true"}}
+	if err := controller.Admit(a); err == nil || !strings.Contains(err.Error(), "privileged pods are not allowed") {
+		t.Errorf("expected the pod to be rejected with the reason of the webhook, got %v", err)
+	}
+}
+
+func TestWebhookAdmissionFailurePolicy(t *testing.T) {
+	unblock := make(chan struct{})
+	slow := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-unblock
+	}))
+	defer slow.Close()
+	defer close(unblock)
+	broken := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	table := map[string]WebhookConfig{
+		"timeout":       {URL: slow.URL, CABundle: caBundle(slow), Timeout: 50 * time.Millisecond},
+		"error status":  {URL: broken.URL, CABundle: caBundle(broken)},
+		"untrusted":     {URL: broken.URL},
+		"not listening": {URL: "https://127.0.0.1:1", CABundle: caBundle(broken)},
+	}
+	a := AdmissionAttributes{Resource: "pods", Operation: AdmissionDelete, Name: "foo"}
+	for name, config := range table {
+		config.FailurePolicy = WebhookFailurePolicyIgnore
+		controller, err := NewWebhookAdmissionController([]WebhookConfig{config})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if err := controller.Admit(a); err != nil {
+			t.Errorf("%s: expected the failure to be ignored, got %v", name, err)
+		}
+
+		config.FailurePolicy = WebhookFailurePolicyFail
+		controller, err = NewWebhookAdmissionController([]WebhookConfig{config})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if err := controller.Admit(a); err == nil {
+			t.Errorf("%s: expected the failure to reject the request", name)
+		}
+	}
+}
+
+func TestNewWebhookAdmissionControllerErrors(t *testing.T) {
+	table := map[string]WebhookConfig{
+		"insecure URL":   {URL: "http://example.com/admit"},
+		"invalid URL":    {URL: "https://[::1"},
+		"unknown policy": {URL: "https://example.com/admit", FailurePolicy: "Retry"},
+		"bad CA bundle":  {URL: "https://example.com/admit", CABundle: []byte("not a certificate")},
+	}
+	for name, config := range table {
+		if _, err := NewWebhookAdmissionController([]WebhookConfig{config}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller/gc"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// garbageCollectedResources are the kinds of objects, by the name of their
+// storage, which the garbage collector tracks.
+var garbageCollectedResources = map[string]string{
+	"pods":                   "Pod",
+	"replicationControllers": "ReplicationController",
+	"jobs":                   "Job",
+	"daemonSets":             "DaemonSet",
+}
+
+// podGenericRegistry adapts a pod.Registry to generic.Registry.
+type podGenericRegistry struct {
+	pods pod.Registry
+}
+
+func (r *podGenericRegistry) List(ctx api.Context, m generic.Matcher) (runtime.Object, error) {
+	list, err := r.pods.ListPods(ctx, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	return generic.FilterList(list, m)
+}
+
+func (r *podGenericRegistry) Watch(ctx api.Context, m generic.Matcher, resourceVersion uint64) (watch.Interface, error) {
+	return r.pods.WatchPods(ctx, strconv.FormatUint(resourceVersion, 10), func(pod *api.Pod) bool {
+		matches, err := m.Matches(pod)
+		return err == nil && matches
+	})
+}
+
+func (r *podGenericRegistry) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return r.pods.GetPod(ctx, id)
+}
+
+func (r *podGenericRegistry) Create(ctx api.Context, id string, obj runtime.Object) error {
+	return r.pods.CreatePod(ctx, obj.(*api.Pod))
+}
+
+func (r *podGenericRegistry) Update(ctx api.Context, id string, obj runtime.Object) error {
+	return r.pods.UpdatePod(ctx, obj.(*api.Pod))
+}
+
+func (r *podGenericRegistry) Delete(ctx api.Context, id string) error {
+	return r.pods.DeletePod(ctx, id)
+}
+
+// controllerGenericRegistry adapts a controller.Registry to generic.Registry.
+type controllerGenericRegistry struct {
+	controllers controller.Registry
+}
+
+func (r *controllerGenericRegistry) List(ctx api.Context, m generic.Matcher) (runtime.Object, error) {
+	list, err := r.controllers.ListControllers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return generic.FilterList(list, m)
+}
+
+func (r *controllerGenericRegistry) Watch(ctx api.Context, m generic.Matcher, resourceVersion uint64) (watch.Interface, error) {
+	w, err := r.controllers.WatchControllers(ctx, strconv.FormatUint(resourceVersion, 10))
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		matches, err := m.Matches(in.Object)
+		return in, err == nil && matches
+	}), nil
+}
+
+func (r *controllerGenericRegistry) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return r.controllers.GetController(ctx, id)
+}
+
+func (r *controllerGenericRegistry) Create(ctx api.Context, id string, obj runtime.Object) error {
+	return r.controllers.CreateController(ctx, obj.(*api.ReplicationController))
+}
+
+func (r *controllerGenericRegistry) Update(ctx api.Context, id string, obj runtime.Object) error {
+	return r.controllers.UpdateController(ctx, obj.(*api.ReplicationController))
+}
+
+func (r *controllerGenericRegistry) Delete(ctx api.Context, id string) error {
+	return r.controllers.DeleteController(ctx, id)
+}
+
+// PropagatingStorage propagates the deletion of an object to its dependents
+// through a garbage collector, as the deletion propagation of the request
+// tells, before it deletes the object through the wrapped storage.
+type PropagatingStorage struct {
+	apiserver.RESTStorage
+	kind      string
+	collector *gc.GarbageCollector
+}
+
+// NewPropagatingStorage returns storage, whose objects are of kind, wrapped in
+// a PropagatingStorage. The result still implements apiserver.ResourceWatcher
+// and apiserver.Redirector if storage does.
+func NewPropagatingStorage(kind string, storage apiserver.RESTStorage, collector *gc.GarbageCollector) apiserver.RESTStorage {
+	s := &PropagatingStorage{storage, kind, collector}
+	watcher, isWatcher := storage.(apiserver.ResourceWatcher)
+	redirector, isRedirector := storage.(apiserver.Redirector)
+	switch {
+	case isWatcher && isRedirector:
+		return struct {
+			*PropagatingStorage
+			apiserver.ResourceWatcher
+			apiserver.Redirector
+		}{s, watcher, redirector}
+	case isWatcher:
+		return struct {
+			*PropagatingStorage
+			apiserver.ResourceWatcher
+		}{s, watcher}
+	case isRedirector:
+		return struct {
+			*PropagatingStorage
+			apiserver.Redirector
+		}{s, redirector}
+	}
+	return s
+}
+
+// NamespaceScoped returns whether the resources of the wrapped storage are in a
+// namespace, as apiserver.Scoper.
+func (s *PropagatingStorage) NamespaceScoped() bool {
+	if scoper, ok := s.RESTStorage.(apiserver.Scoper); ok {
+		return scoper.NamespaceScoped()
+	}
+	return true
+}
+
+func (s *PropagatingStorage) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	if err := s.collector.PropagateDeletion(ctx, s.kind, id, api.DeletionPropagationFrom(ctx)); err != nil {
+		return nil, err
+	}
+	return s.RESTStorage.Delete(ctx, id)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller/gc"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+)
+
+// deletingRegistry records the objects deleted through it.
+type deletingRegistry struct {
+	*registrytest.GenericRegistry
+	deleted []string
+}
+
+func (r *deletingRegistry) Delete(ctx api.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestPropagatingStorage(t *testing.T) {
+	table := map[string]struct {
+		query          string
+		expectedCode   int
+		expectDeleted  bool
+		expectOrphaned bool
+	}{
+		"background": {
+			expectedCode: http.StatusOK,
+		},
+		"foreground": {
+			query:         "&propagationPolicy=Foreground",
+			expectedCode:  http.StatusOK,
+			expectDeleted: true,
+		},
+		"orphan": {
+			query:          "&propagationPolicy=Orphan",
+			expectedCode:   http.StatusOK,
+			expectOrphaned: true,
+		},
+		"unsupported": {
+			query:        "&propagationPolicy=Never",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for name, item := range table {
+		job := &api.Job{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault, UID: "1"}}
+		pod := api.Pod{TypeMeta: api.TypeMeta{
+			ID:              "foo-a",
+			Namespace:       api.NamespaceDefault,
+			OwnerReferences: []api.OwnerReference{{Kind: "Job", Name: "foo", UID: "1"}},
+		}}
+		jobs := &deletingRegistry{GenericRegistry: registrytest.NewGeneric(&api.JobList{Items: []api.Job{*job}})}
+		jobs.Object = job
+		pods := &deletingRegistry{GenericRegistry: registrytest.NewGeneric(&api.PodList{Items: []api.Pod{pod}})}
+		collector := gc.NewGarbageCollector(map[string]generic.Registry{"Job": jobs, "Pod": pods})
+		storage := &fakePodStorage{}
+		handler := apiserver.Handle(map[string]apiserver.RESTStorage{
+			"jobs": NewPropagatingStorage("Job", storage, collector),
+		}, latest.Codec, "/api/v1beta1", latest.SelfLinker)
+		server := httptest.NewServer(handler)
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/api/v1beta1/jobs/foo?sync=true"+item.query, nil)
+		resp, err := http.DefaultClient.Do(req)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != item.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", name, item.expectedCode, resp.StatusCode)
+		}
+		if e, a := item.expectedCode == http.StatusOK, storage.deleted; e != a {
+			t.Errorf("%s: expected the job to be deleted %v, got %v", name, e, a)
+		}
+		if deleted := len(pods.deleted) == 1 && pods.deleted[0] == "foo-a"; deleted != item.expectDeleted {
+			t.Errorf("%s: expected the pod to be deleted %v, got %v", name, item.expectDeleted, pods.deleted)
+		}
+		orphaned := false
+		if updated, ok := pods.Object.(*api.Pod); ok {
+			orphaned = updated.ID == "foo-a" && len(updated.OwnerReferences) == 0
+		}
+		if orphaned != item.expectOrphaned {
+			t.Errorf("%s: expected the pod to be orphaned %v, got %#v", name, item.expectOrphaned, pods.Object)
+		}
+	}
+}
+
+func TestNewPropagatingStorageKeepsInterfaces(t *testing.T) {
+	collector := gc.NewGarbageCollector(nil)
+	if _, ok := NewPropagatingStorage("Pod", &fakePodStorage{}, collector).(apiserver.ResourceWatcher); ok {
+		t.Errorf("expected storage that cannot be watched to stay that way")
+	}
+	if _, ok := NewPropagatingStorage("Pod", &fakeWatchablePodStorage{}, collector).(apiserver.ResourceWatcher); !ok {
+		t.Errorf("expected watchable storage to stay watchable")
+	}
+}
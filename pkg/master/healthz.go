@@ -0,0 +1,173 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/minion"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// HealthChecker reports whether some component the master depends on is healthy.
+type HealthChecker interface {
+	// Check returns an error if the component is unhealthy.
+	Check() error
+}
+
+// HealthCheckerFunc adapts an ordinary function to the HealthChecker interface.
+type HealthCheckerFunc func() error
+
+// Check calls f().
+func (f HealthCheckerFunc) Check() error {
+	return f()
+}
+
+// namedHealthChecker is a HealthChecker reported under name by /healthz.
+type namedHealthChecker struct {
+	name string
+	HealthChecker
+}
+
+// healthCheckFailure describes a failed check in the /healthz response.
+type healthCheckFailure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// podCacheStalenessFactor is how many sync periods may pass without a successful
+// pod cache sync before the pod cache is reported unhealthy.
+const podCacheStalenessFactor = 3
+
+// componentCheckTimeout bounds each HTTP health check of a component.
+const componentCheckTimeout = 5 * time.Second
+
+// etcdHealthCheck verifies that etcd can be read.
+func etcdHealthCheck(client tools.EtcdGetSet) HealthChecker {
+	return HealthCheckerFunc(func() error {
+		if _, err := client.Get("/", false, false); err != nil && !tools.IsEtcdNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// minionRegistryHealthCheck verifies that minions can be listed.
+func minionRegistryHealthCheck(registry minion.Registry) HealthChecker {
+	return HealthCheckerFunc(func() error {
+		_, err := registry.ListMinions(api.NewContext(), labels.Everything())
+		return err
+	})
+}
+
+// httpHealthCheck verifies that GET url succeeds, as it does for the /healthz
+// of a component.
+func httpHealthCheck(url string) HealthChecker {
+	client := &http.Client{Timeout: componentCheckTimeout}
+	return HealthCheckerFunc(func() error {
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s returned %s", url, resp.Status)
+		}
+		return nil
+	})
+}
+
+// podCacheHealthCheck verifies that the pod cache has synced recently. Before
+// the first sync, the time the check was created is used instead.
+func podCacheHealthCheck(podCache *PodCache, syncPeriod time.Duration) HealthChecker {
+	created := time.Now()
+	return HealthCheckerFunc(func() error {
+		lastSync := podCache.LastSync()
+		if lastSync.IsZero() {
+			lastSync = created
+		}
+		if since := time.Since(lastSync); since > podCacheStalenessFactor*syncPeriod {
+			return fmt.Errorf("pod cache has not synced for %v", since)
+		}
+		return nil
+	})
+}
+
+// checkComponents registers each of components with the component status
+// registry, and records its health every period until the master stops.
+func (m *Master) checkComponents(components map[string]HealthChecker, period time.Duration) {
+	for name, checker := range components {
+		m.componentStatuses.Add(name)
+		m.running.Add(1)
+		go func(name string, checker HealthChecker) {
+			defer m.running.Done()
+			util.Until(func() { m.componentStatuses.SetHealth(name, checker.Check()) }, period, m.stop)
+		}(name, checker)
+	}
+}
+
+// handleHealthz runs every health check, and responds with "ok" if all of them
+// pass or with the list of failed checks otherwise.
+func (m *Master) handleHealthz(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	failures := []healthCheckFailure{}
+	for _, checker := range m.healthChecks {
+		if err := checker.Check(); err != nil {
+			failures = append(failures, healthCheckFailure{Name: checker.name, Error: err.Error()})
+		}
+	}
+	if len(failures) == 0 {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		return
+	}
+	output, err := json.Marshal(map[string][]healthCheckFailure{"failed": failures})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write(output)
+}
+
+// handlePodCacheStatus responds with the PodCacheStats of the pod cache, so
+// that minions which are failing to report the status of their pods can be
+// told apart.
+func (m *Master) handlePodCacheStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	output, err := json.Marshal(m.podCache.Stats())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+)
+
+func TestHealthz(t *testing.T) {
+	healthy := HealthCheckerFunc(func() error { return nil })
+	unhealthy := HealthCheckerFunc(func() error { return errors.New("broken") })
+	table := map[string]struct {
+		checks       []namedHealthChecker
+		expectedCode int
+		expectedBody string
+	}{
+		"none": {
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		"healthy": {
+			checks:       []namedHealthChecker{{"a", healthy}, {"b", healthy}},
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		"unhealthy": {
+			checks:       []namedHealthChecker{{"a", healthy}, {"b", unhealthy}, {"c", unhealthy}},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"failed":[{"name":"b","error":"broken"},{"name":"c","error":"broken"}]}`,
+		},
+	}
+	for name, item := range table {
+		m := &Master{healthChecks: item.checks}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/healthz", nil)
+		m.handleHealthz(w, req)
+		if w.Code != item.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", name, item.expectedCode, w.Code)
+		}
+		if body := w.Body.String(); body != item.expectedBody {
+			t.Errorf("%s: expected body %q, got %q", name, item.expectedBody, body)
+		}
+	}
+}
+
+func TestPodCacheHealthCheck(t *testing.T) {
+	podCache := NewPodCache(&countingPodInfoGetter{}, registrytest.NewPodRegistry(&api.PodList{}))
+	checker := podCacheHealthCheck(podCache, 10*time.Millisecond)
+	if err := checker.Check(); err != nil {
+		t.Errorf("expected a new pod cache to be healthy, got %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err := checker.Check(); err == nil {
+		t.Errorf("expected a pod cache that never synced to become unhealthy")
+	}
+
+	podCache.UpdateAllContainers()
+	if err := checker.Check(); err != nil {
+		t.Errorf("expected a freshly synced pod cache to be healthy, got %v", err)
+	}
+}
+
+func TestHandlePodCacheStatus(t *testing.T) {
+	podCache := NewPodCache(&FakePodInfoGetter{err: errors.New("unreachable")}, registrytest.NewPodRegistry(&api.PodList{
+		Items: []api.Pod{{TypeMeta: api.TypeMeta{ID: "foo"}, CurrentState: api.PodState{Host: "machine"}}},
+	}))
+	podCache.UpdateAllContainers()
+	m := &Master{podCache: podCache}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/podCacheStatus", nil)
+	m.handlePodCacheStatus(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d", http.StatusOK, w.Code)
+	}
+	var stats PodCacheStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.ErrorCount != 1 || len(stats.MinionLastUpdate) != 0 {
+		t.Errorf("unexpected stats %#v", stats)
+	}
+}
+
+func TestHandlerHealthz(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/pods/default")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+		HealthChecks: []HealthChecker{
+			HealthCheckerFunc(func() error { return errors.New("broken") }),
+		},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected code %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	var body map[string][]healthCheckFailure
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []healthCheckFailure{{Name: "healthChecks[0]", Error: "broken"}}
+	if len(body["failed"]) != 1 || body["failed"][0] != expected[0] {
+		t.Errorf("expected failures %#v, got %#v", expected, body["failed"])
+	}
+
+	resp, err = http.Get(server.URL + "/api/v1beta1/pods")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the API to be served, got code %d", resp.StatusCode)
+	}
+}
+
+// toggledHealthChecker fails while broken is set.
+type toggledHealthChecker struct {
+	lock   sync.Mutex
+	broken bool
+}
+
+func (c *toggledHealthChecker) Check() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.broken {
+		return errors.New("broken")
+	}
+	return nil
+}
+
+func (c *toggledHealthChecker) set(broken bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.broken = broken
+}
+
+func TestHandlerComponentStatuses(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	checker := &toggledHealthChecker{}
+	m := New(&Config{
+		EtcdHelper:            tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:         &countingPodInfoGetter{},
+		ComponentHealthChecks: map[string]HealthChecker{"fake": checker},
+		ComponentCheckPeriod:  5 * time.Millisecond,
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	get := func(path string, obj interface{}) {
+		resp, err := http.Get(server.URL + "/api/v1beta1/componentStatuses" + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected code %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(obj); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// waitFor polls the status of the fake component until it matches expected.
+	waitFor := func(expected api.ConditionStatus) {
+		var status api.ComponentStatus
+		for i := 0; i < 100; i++ {
+			get("/fake", &status)
+			if len(status.Conditions) == 1 && status.Conditions[0].Type == api.ComponentHealthy && status.Conditions[0].Status == expected {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("expected the fake component to be %s, got %#v", expected, status.Conditions)
+	}
+
+	for _, broken := range []bool{false, true, false, true} {
+		checker.set(broken)
+		if broken {
+			waitFor(api.ConditionFalse)
+		} else {
+			waitFor(api.ConditionTrue)
+		}
+	}
+
+	var list api.ComponentStatusList
+	get("", &list)
+	names := []string{}
+	for _, item := range list.Items {
+		names = append(names, item.ID)
+	}
+	if e, a := []string{"controller-manager", "etcd", "fake", "scheduler"}, names; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected components %v, got %v", e, a)
+	}
+}
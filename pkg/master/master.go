@@ -17,29 +17,67 @@ limitations under the License.
 package master
 
 import (
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 
+	"code.google.com/p/go-uuid/uuid"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/v1beta1"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/v1beta2"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/authenticator"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/authenticator/bearertoken"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/authenticator/tokenfile"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller/daemon"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller/gc"
+	hpacontroller "github.com/GoogleCloudPlatform/kubernetes/pkg/controller/hpa"
+	ingresscontroller "github.com/GoogleCloudPlatform/kubernetes/pkg/controller/ingress"
+	jobcontroller "github.com/GoogleCloudPlatform/kubernetes/pkg/controller/job"
+	nodecontroller "github.com/GoogleCloudPlatform/kubernetes/pkg/controller/node"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller/rollingupdate"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/leaderelection"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/binding"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/componentstatus"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/configmap"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/daemonset"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/endpoint"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/etcd"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/event"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/hpa"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/ingress"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/job"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/lease"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/limitrange"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/minion"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/namespace"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/networkpolicy"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/poddisruptionbudget"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/rbac"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/resourcequota"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/secret"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/service"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/serviceaccount"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 
+	etcdclient "github.com/coreos/go-etcd/etcd"
 	"github.com/golang/glog"
 )
 
@@ -51,28 +89,316 @@ type Config struct {
 	HealthCheckMinions bool
 	Minions            []string
 	MinionCacheTTL     time.Duration
+	MinionCacheGetTTL  time.Duration
 	EventTTL           time.Duration
 	MinionRegexp       string
 	PodInfoGetter      client.PodInfoGetter
 	NodeResources      api.NodeResources
+	// With HealthCheckMinions, how many health checks in a row a minion must
+	// fail to be left out, and pass to be listed again. Both default to 1.
+	MinionFailureThreshold int
+	MinionSuccessThreshold int
+	// If set, an event which repeats an event created within this window before
+	// it increments the count of that event instead of being stored on its own.
+	EventAggregationWindow time.Duration
+	// How often the pod cache refreshes container information. Defaults to 30 seconds.
+	PodCacheSyncPeriod time.Duration
+	// Pods whose cached container information is older than this are served
+	// with the stale cache annotation. Defaults to 3 pod cache sync periods.
+	PodCacheStaleThreshold time.Duration
+	// If set, the master is stopped when this channel is closed.
+	StopCh <-chan struct{}
+	// The prefix under which Handler serves the API. Defaults to "/api".
+	APIPrefix string
+	// The key under which the master stores every object in etcd, so that
+	// several clusters can share one etcd. Overrides the PathPrefix of EtcdHelper.
+	// If neither is set, defaults to "/registry".
+	EtcdPrefix string
+	// Additional checks run by /healthz, alongside the checks of etcd, the minion
+	// registry and the pod cache.
+	HealthChecks []HealthChecker
+	// Additional components, by name, whose health is served as componentStatuses
+	// alongside that of etcd, the scheduler and the controller manager. Every
+	// component is checked each ComponentCheckPeriod, which defaults to 10 seconds.
+	ComponentHealthChecks map[string]HealthChecker
+	ComponentCheckPeriod  time.Duration
+	// If set, the endpoints served by the master leave out the pods which are not
+	// running all of their containers according to the pod cache.
+	FilterUnhealthyEndpoints bool
+	// The names of the plugins built from the master's registries which must
+	// admit every create, update and delete, in order; see AdmissionControl*.
+	// They run after the objects are validated. AdmissionControlRequiredLabels
+	// requires the labels listed in RequiredLabels.
+	AdmissionControl []string
+	RequiredLabels   []string
+	// Plugins which must admit every create, update and delete after those
+	// named by AdmissionControl, in order.
+	AdmissionPlugins []AdmissionController
+	// Webhooks which must admit every create, update and delete after
+	// AdmissionPlugins, in order.
+	AdmissionWebhooks []WebhookConfig
+	// If set, every mutating request served by Handler is recorded in this file.
+	AuditLogPath string
+	// Looks up the users that requests are made by, for auditing, rate limiting
+	// and authorization. Optional; defaults to the users which requests are
+	// authenticated as with TokenAuthFile.
+	RequestUsers apiserver.RequestUsers
+	// If set, Handler only serves requests which carry a bearer token listed in
+	// this CSV file, of token, user name, user uid and optionally the user's
+	// comma separated groups, one token per line. Other requests fail with 401
+	// Unauthorized.
+	TokenAuthFile string
+	// How requests are authorized: AuthorizationModeAlwaysAllow, the default, or
+	// AuthorizationModeRBAC. Under RBAC, paths outside the API other than /healthz
+	// and /version are only served to cluster admins. If RBACSuperUser is set,
+	// that user is allowed every request, so that the first roles and bindings
+	// can be created.
+	AuthorizationMode string
+	RBACSuperUser     string
+	// Requests which are not answered within RequestTimeout fail with 504 Gateway
+	// Timeout. Defaults to 60 seconds. Watches, proxied requests and the log and
+	// exec subresources hold their connection open and are exempt; watches and
+	// log streams end after WatchTimeout instead, if it is set.
+	RequestTimeout time.Duration
+	WatchTimeout   time.Duration
+	// If set, each client may make RequestsPerSecond requests per second, in
+	// bursts of up to BurstSize requests, before its requests fail with 429 Too
+	// Many Requests. Clients are told apart by their user, or else their address.
+	// BurstSize defaults to 10.
+	RequestsPerSecond float64
+	BurstSize         int
+	// If set, the controllers of the master, which change the state of the
+	// cluster, only run while the master holds a lock in etcd, so that several
+	// masters can share one etcd. The lock expires LeaderElectionTTL after it was
+	// last renewed, which defaults to 15 seconds.
+	EnableLeaderElection bool
+	LeaderElectionTTL    time.Duration
+	// The number of masters sharing EtcdHelper, which defaults to 1. Leader
+	// election is used whenever it is more than 1, whether or not
+	// EnableLeaderElection is set, so that the controllers never run on several
+	// masters at once.
+	MasterCount int
+	// If set, Handler answers cross-origin requests from origins matching any of
+	// these regular expressions.
+	CORSAllowedOrigins []string
+	// The certificate and key ListenAndServeTLS serves with.
+	TLSCertFile string
+	TLSKeyFile  string
+	// If set, ListenAndServeTLS requires clients to present a certificate signed
+	// by one of the CAs in this file.
+	ClientCAFile string
+	// If set, the replication controllers that horizontal pod autoscalers refer
+	// to are resized once per HPASyncPeriod, according to the CPU utilization
+	// this reports. HPASyncPeriod defaults to 30 seconds.
+	HPAMetrics    hpacontroller.MetricsSource
+	HPASyncPeriod time.Duration
+	// If set, the master runs the daemon set controller, which reconciles the
+	// pods of daemon sets with the minions they select once per
+	// DaemonSetSyncPeriod. DaemonSetSyncPeriod defaults to 30 seconds.
+	EnableDaemonSetController bool
+	DaemonSetSyncPeriod       time.Duration
+	// If set, the master runs the job controller, which starts the pods of jobs
+	// and counts those which finished once per JobSyncPeriod. JobSyncPeriod
+	// defaults to 10 seconds.
+	EnableJobController bool
+	JobSyncPeriod       time.Duration
+	// If set, the master runs the rolling update controller, which replaces the
+	// pods of replication controllers whose rolling update strategy is set when
+	// their pod template changes.
+	EnableRollingUpdateController bool
+	// If set, the master runs the garbage collector, which deletes the objects
+	// whose owners are all gone. Deleting an object with the foreground or
+	// orphan propagation policy updates its dependents either way; background
+	// deletion relies on the collector.
+	EnableGarbageCollector bool
+	// If set, the routes of ingresses are written to this file as nginx
+	// configuration, and IngressReloadCommand is run whenever they change.
+	IngressConfigPath    string
+	IngressReloadCommand []string
+	// If set, minions whose kubelet has not reported their Ready condition for
+	// this long are marked not ready, and their pods are deleted. Minions which
+	// fail health checks are not listed when HealthCheckMinions is set, so they
+	// are not noticed.
+	NodeMonitorGracePeriod time.Duration
+	// If set along with NodeMonitorGracePeriod, the pods of minions annotated
+	// as out of service after a non-graceful shutdown are deleted at once,
+	// rather than after the minion misses its heartbeats.
+	EnableNonGracefulNodeShutdown bool
+	// If set, the master also serves its API over plain HTTP on this port of
+	// ReadOnlyAddress, which defaults to 127.0.0.1, for internal components such
+	// as the scheduler which have no client certificate. Requests there are not
+	// authenticated or authorized; only reads are served, and secrets are not
+	// (see ReadOnlyHandler).
+	ReadOnlyPort    int
+	ReadOnlyAddress string
+	// The largest total size, in bytes, of the values of a config map and of
+	// the decoded values of a secret. Both default to 1 MiB.
+	MaxConfigMapSize int
+	MaxSecretSize    int
 }
 
+const (
+	// AuthorizationModeAlwaysAllow serves every request.
+	AuthorizationModeAlwaysAllow = "AlwaysAllow"
+	// AuthorizationModeRBAC only serves the requests which the roles bound to
+	// their user allow.
+	AuthorizationModeRBAC = "RBAC"
+)
+
+// The plugins which Config.AdmissionControl may name.
+const (
+	AdmissionControlNamespaceExists       = "NamespaceExists"
+	AdmissionControlResourceQuota         = "ResourceQuota"
+	AdmissionControlServiceAccount        = "ServiceAccount"
+	AdmissionControlLimitRanger           = "LimitRanger"
+	AdmissionControlConfigMapRef          = "ConfigMapRef"
+	AdmissionControlRequiredLabels        = "RequiredLabels"
+	AdmissionControlNetworkPolicy         = "NetworkPolicy"
+	AdmissionControlNoPreemptibleCritical = "NoPreemptibleCritical"
+)
+
+// quotaRefreshPeriod is how often the ResourceQuota admission plugin rereads
+// the quotas of a namespace.
+const quotaRefreshPeriod = 10 * time.Second
+
+// defaultPodCacheSyncPeriod is used when Config.PodCacheSyncPeriod is not set.
+const defaultPodCacheSyncPeriod = 30 * time.Second
+
+// defaultComponentCheckPeriod is used when Config.ComponentCheckPeriod is not set.
+const defaultComponentCheckPeriod = 10 * time.Second
+
+// defaultHPASyncPeriod is used when Config.HPASyncPeriod is not set.
+const defaultHPASyncPeriod = 30 * time.Second
+
+// defaultDaemonSetSyncPeriod is used when Config.DaemonSetSyncPeriod is not set.
+const defaultDaemonSetSyncPeriod = 30 * time.Second
+
+// defaultJobSyncPeriod is used when Config.JobSyncPeriod is not set.
+const defaultJobSyncPeriod = 10 * time.Second
+
+// ingressSyncPeriod is how often the ingress controller picks up changes to
+// the services and endpoints ingresses route to.
+const ingressSyncPeriod = 30 * time.Second
+
+// rollingUpdatePeriod is how often the rolling update controller takes the
+// rolling updates of replication controllers a step further.
+const rollingUpdatePeriod = 5 * time.Second
+
+// garbageCollectionPeriod is how often the garbage collector deletes the
+// objects whose owners are gone.
+const garbageCollectionPeriod = 30 * time.Second
+
+// objectCountPeriod is how often the objects stored in etcd are counted for
+// apiserver_etcd_object_counts.
+const objectCountPeriod = time.Minute
+
+// nodeMonitorPeriod is how often the node lifecycle controller checks on
+// minions, when Config.NodeMonitorGracePeriod is set.
+const nodeMonitorPeriod = 5 * time.Second
+
+// defaultEtcdPrefix is the key objects are stored under when neither
+// Config.EtcdPrefix nor the PathPrefix of Config.EtcdHelper is set.
+const defaultEtcdPrefix = "/registry"
+
+// defaultLeaderElectionTTL is used when Config.LeaderElectionTTL is not set.
+const defaultLeaderElectionTTL = 15 * time.Second
+
+// leaderElectionKey is the key of the lock the elected master holds, beneath
+// the etcd prefix of the master.
+const leaderElectionKey = "/leaderelection/master"
+
+// defaultReadOnlyAddress is used when Config.ReadOnlyAddress is not set.
+const defaultReadOnlyAddress = "127.0.0.1"
+
+// defaultAPIPrefix is used when Config.APIPrefix is not set.
+const defaultAPIPrefix = "/api"
+
+// publicPaths are the paths outside the API which are served without
+// authorization: the health checks of load balancers and the version.
+var publicPaths = util.NewStringSet("/healthz", "/version")
+
+// defaultRequestTimeout is used when Config.RequestTimeout is not set.
+const defaultRequestTimeout = 60 * time.Second
+
+// defaultBurstSize is used when Config.RequestsPerSecond is set and
+// Config.BurstSize is not.
+const defaultBurstSize = 10
+
+// longRunningRequestRE matches the paths of the requests which hold their
+// connection open: watches, proxied requests, and the log and exec
+// subresources.
+var longRunningRequestRE = regexp.MustCompile(`/(watch|proxy)/|/(log|exec)$`)
+
 // Master contains state for a Kubernetes cluster master/api server.
 type Master struct {
-	podRegistry        pod.Registry
-	controllerRegistry controller.Registry
-	serviceRegistry    service.Registry
-	endpointRegistry   endpoint.Registry
-	minionRegistry     minion.Registry
-	bindingRegistry    binding.Registry
-	eventRegistry      generic.Registry
-	storage            map[string]apiserver.RESTStorage
-	client             *client.Client
+	podRegistry           pod.Registry
+	controllerRegistry    controller.Registry
+	serviceRegistry       service.Registry
+	endpointRegistry      endpoint.Registry
+	minionRegistry        minion.Registry
+	bindingRegistry       binding.Registry
+	eventRegistry         generic.Registry
+	secretRegistry        generic.Registry
+	namespaceRegistry     generic.Registry
+	quotaRegistry         generic.Registry
+	accountRegistry       generic.Registry
+	autoscalerRegistry    generic.Registry
+	limitRangeRegistry    generic.Registry
+	configMapRegistry     generic.Registry
+	daemonSetRegistry     generic.Registry
+	jobRegistry           generic.Registry
+	ingressRegistry       generic.Registry
+	networkPolicyRegistry generic.Registry
+	budgetRegistry        generic.Registry
+	roleRegistry          generic.Registry
+	roleBindingRegistry   generic.Registry
+	clusterRoleRegistry   generic.Registry
+	clusterRoleBindings   generic.Registry
+	leaseRegistry         generic.Registry
+	garbageCollector      *gc.GarbageCollector
+	componentStatuses     *componentstatus.Registry
+	filterEndpoints       bool
+	podCache              *PodCache
+	podCacheStale         time.Duration
+	maxConfigMapSize      int
+	maxSecretSize         int
+	storage               map[string]apiserver.RESTStorage
+	client                *client.Client
+	apiPrefix             string
+	healthChecks          []namedHealthChecker
+	admissionPlugins      []AdmissionController
+	auditLog              *apiserver.FileAuditLog
+	requestUsers          apiserver.RequestUsers
+	corsAllowedOrigins    []*regexp.Regexp
+	requestTimeout        time.Duration
+	watchTimeout          time.Duration
+	rateLimiter           apiserver.RateLimiter
+	authorizer            apiserver.Authorizer
+	tlsCertFile           string
+	tlsKeyFile            string
+	clientCAFile          string
+
+	// If set, requests must authenticate with authenticator, and their users
+	// are stored in userContext.
+	authenticator authenticator.Request
+	userContext   *handlers.UserRequestContext
+
+	// controllers change the state of the cluster, so they only run while the
+	// master leads.
+	controllers       []func(stop <-chan struct{})
+	leaderElected     chan struct{}
+	leaderElectedOnce sync.Once
+
+	// stop is closed to signal the background goroutines to exit.
+	stop     chan struct{}
+	stopOnce sync.Once
+	// running tracks the background goroutines started by init.
+	running sync.WaitGroup
 }
 
 // NewEtcdHelper returns an EtcdHelper for the provided arguments or an error if the version
-// is incorrect.
-func NewEtcdHelper(client tools.EtcdGetSet, version string) (helper tools.EtcdHelper, err error) {
+// is incorrect. The helper keeps its keys under prefix, which defaults to "/registry".
+func NewEtcdHelper(client tools.EtcdGetSet, version, prefix string) (helper tools.EtcdHelper, err error) {
 	if version == "" {
 		version = latest.Version
 	}
@@ -80,31 +406,460 @@ func NewEtcdHelper(client tools.EtcdGetSet, version string) (helper tools.EtcdHe
 	if err != nil {
 		return helper, err
 	}
-	return tools.EtcdHelper{client, versionInterfaces.Codec, tools.RuntimeVersionAdapter{versionInterfaces.ResourceVersioner}}, nil
+	if prefix == "" {
+		prefix = defaultEtcdPrefix
+	}
+	return tools.EtcdHelper{client, versionInterfaces.Codec, tools.RuntimeVersionAdapter{versionInterfaces.ResourceVersioner}, prefix, tools.WatchConfig{}}, nil
+}
+
+// NewEtcdHelperFromURLs returns an EtcdHelper backed by an etcd client for the given
+// cluster endpoints, or an error if an endpoint is not an http(s) URL or the version is
+// incorrect. The client fails over between endpoints. The helper keeps its keys under
+// prefix, as with NewEtcdHelper. A dialTimeout of zero keeps the client default.
+func NewEtcdHelperFromURLs(endpoints []string, version, prefix string, dialTimeout time.Duration) (helper tools.EtcdHelper, err error) {
+	if len(endpoints) == 0 {
+		return helper, fmt.Errorf("at least one etcd endpoint is required")
+	}
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return helper, fmt.Errorf("invalid etcd endpoint %q: %v", endpoint, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return helper, fmt.Errorf("invalid etcd endpoint %q: expected http(s)://host:port", endpoint)
+		}
+	}
+	client := etcdclient.NewClient(endpoints)
+	if dialTimeout > 0 {
+		client.SetDialTimeout(dialTimeout)
+	}
+	return NewEtcdHelper(client, version, prefix)
 }
 
 // New returns a new instance of Master connected to the given etcd server.
 func New(c *Config) *Master {
-	minionRegistry := makeMinionRegistry(c)
-	serviceRegistry := etcd.NewRegistry(c.EtcdHelper, nil)
+	helper := c.EtcdHelper
+	if c.EtcdPrefix != "" {
+		helper.PathPrefix = c.EtcdPrefix
+	}
+	if helper.PathPrefix == "" {
+		helper.PathPrefix = defaultEtcdPrefix
+	}
+	minionRegistry := makeMinionRegistry(c, helper)
+	serviceRegistry := etcd.NewRegistry(helper, nil)
+	if err := serviceRegistry.MigrateLegacyKeys(); err != nil {
+		glog.Errorf("Unable to move objects to their namespaced keys: %v", err)
+	}
 	manifestFactory := &pod.BasicManifestFactory{
 		ServiceRegistry: serviceRegistry,
 	}
 	m := &Master{
-		podRegistry:        etcd.NewRegistry(c.EtcdHelper, manifestFactory),
-		controllerRegistry: etcd.NewRegistry(c.EtcdHelper, nil),
-		serviceRegistry:    serviceRegistry,
-		endpointRegistry:   etcd.NewRegistry(c.EtcdHelper, nil),
-		bindingRegistry:    etcd.NewRegistry(c.EtcdHelper, manifestFactory),
-		eventRegistry:      event.NewEtcdRegistry(c.EtcdHelper, uint64(c.EventTTL.Seconds())),
-		minionRegistry:     minionRegistry,
-		client:             c.Client,
-	}
-	m.init(c.Cloud, c.PodInfoGetter)
+		podRegistry:           etcd.NewRegistry(helper, manifestFactory),
+		controllerRegistry:    etcd.NewRegistry(helper, nil),
+		serviceRegistry:       serviceRegistry,
+		endpointRegistry:      etcd.NewRegistry(helper, nil),
+		bindingRegistry:       etcd.NewRegistry(helper, manifestFactory),
+		eventRegistry:         event.NewEtcdRegistry(helper, uint64(c.EventTTL.Seconds()), c.EventAggregationWindow),
+		secretRegistry:        secret.NewEtcdRegistry(helper),
+		namespaceRegistry:     namespace.NewEtcdRegistry(helper),
+		quotaRegistry:         resourcequota.NewEtcdRegistry(helper),
+		accountRegistry:       serviceaccount.NewEtcdRegistry(helper),
+		autoscalerRegistry:    hpa.NewEtcdRegistry(helper),
+		limitRangeRegistry:    limitrange.NewEtcdRegistry(helper),
+		configMapRegistry:     configmap.NewEtcdRegistry(helper),
+		daemonSetRegistry:     daemonset.NewEtcdRegistry(helper),
+		jobRegistry:           job.NewEtcdRegistry(helper),
+		ingressRegistry:       ingress.NewEtcdRegistry(helper),
+		networkPolicyRegistry: networkpolicy.NewEtcdRegistry(helper),
+		budgetRegistry:        poddisruptionbudget.NewEtcdRegistry(helper),
+		roleRegistry:          rbac.NewRoleEtcdRegistry(helper),
+		roleBindingRegistry:   rbac.NewRoleBindingEtcdRegistry(helper),
+		clusterRoleRegistry:   rbac.NewClusterRoleEtcdRegistry(helper),
+		clusterRoleBindings:   rbac.NewClusterRoleBindingEtcdRegistry(helper),
+		leaseRegistry:         lease.NewEtcdRegistry(helper),
+		componentStatuses:     componentstatus.NewRegistry(),
+		filterEndpoints:       c.FilterUnhealthyEndpoints,
+		minionRegistry:        minionRegistry,
+		client:                c.Client,
+		admissionPlugins:      []AdmissionController{NewValidationAdmission()},
+		requestUsers:          c.RequestUsers,
+		tlsCertFile:           c.TLSCertFile,
+		tlsKeyFile:            c.TLSKeyFile,
+		clientCAFile:          c.ClientCAFile,
+		maxConfigMapSize:      c.MaxConfigMapSize,
+		maxSecretSize:         c.MaxSecretSize,
+		stop:                  make(chan struct{}),
+		leaderElected:         make(chan struct{}),
+	}
+	podCacheSyncPeriod := c.PodCacheSyncPeriod
+	if podCacheSyncPeriod == 0 {
+		podCacheSyncPeriod = defaultPodCacheSyncPeriod
+	}
+	m.podCacheStale = c.PodCacheStaleThreshold
+	if m.podCacheStale == 0 {
+		m.podCacheStale = podCacheStalenessFactor * podCacheSyncPeriod
+	}
+	if len(c.AuditLogPath) > 0 {
+		auditLog, err := apiserver.NewFileAuditLog(c.AuditLogPath)
+		if err != nil {
+			glog.Errorf("Failed to open audit log %s, requests will not be audited: %v", c.AuditLogPath, err)
+		} else {
+			m.auditLog = auditLog
+		}
+	}
+	if len(c.CORSAllowedOrigins) > 0 {
+		allowedOrigins, err := util.CompileRegexps(c.CORSAllowedOrigins)
+		if err != nil {
+			glog.Errorf("Invalid CORS allowed origins %v, cross-origin requests will not be allowed: %v", c.CORSAllowedOrigins, err)
+		} else {
+			m.corsAllowedOrigins = allowedOrigins
+		}
+	}
+	m.requestTimeout = c.RequestTimeout
+	if m.requestTimeout == 0 {
+		m.requestTimeout = defaultRequestTimeout
+	}
+	m.watchTimeout = c.WatchTimeout
+	if c.RequestsPerSecond > 0 {
+		burst := c.BurstSize
+		if burst <= 0 {
+			burst = defaultBurstSize
+		}
+		m.rateLimiter = apiserver.NewTokenBucketRateLimiter(c.RequestsPerSecond, burst)
+	}
+	if len(c.TokenAuthFile) > 0 {
+		tokens, err := tokenfile.New(c.TokenAuthFile)
+		if err != nil {
+			glog.Errorf("Failed to load the token file %s, every request will be unauthorized: %v", c.TokenAuthFile, err)
+			m.authenticator = authenticator.RequestFunc(func(*http.Request) (user.Info, bool, error) {
+				return nil, false, fmt.Errorf("invalid token file %s: %v", c.TokenAuthFile, err)
+			})
+		} else {
+			m.authenticator = bearertoken.New(tokens)
+		}
+		m.userContext = handlers.NewUserRequestContext()
+		if m.requestUsers == nil {
+			m.requestUsers = m.userContext
+		}
+	}
+	switch c.AuthorizationMode {
+	case "", AuthorizationModeAlwaysAllow:
+	case AuthorizationModeRBAC:
+		m.authorizer = rbac.NewAuthorizer(m.roleRegistry, m.roleBindingRegistry, m.clusterRoleRegistry, m.clusterRoleBindings, c.RBACSuperUser)
+	default:
+		glog.Errorf("Unknown authorization mode %q, requests for API resources will be denied", c.AuthorizationMode)
+		m.authorizer = apiserver.AuthorizerFunc(func(apiserver.AuthorizationAttributes) error {
+			return fmt.Errorf("unknown authorization mode %q", c.AuthorizationMode)
+		})
+	}
+	for _, name := range c.AdmissionControl {
+		plugin, err := m.admissionPlugin(name, c.RequiredLabels)
+		if err != nil {
+			glog.Errorf("%v, writes will be rejected", err)
+			plugin = AdmissionControllerFunc(func(AdmissionAttributes) error {
+				return err
+			})
+		}
+		m.admissionPlugins = append(m.admissionPlugins, plugin)
+	}
+	m.admissionPlugins = append(m.admissionPlugins, c.AdmissionPlugins...)
+	if len(c.AdmissionWebhooks) > 0 {
+		var plugin AdmissionController
+		webhooks, err := NewWebhookAdmissionController(c.AdmissionWebhooks)
+		if err != nil {
+			glog.Errorf("Invalid admission webhooks, writes will be rejected: %v", err)
+			plugin = AdmissionControllerFunc(func(AdmissionAttributes) error {
+				return fmt.Errorf("invalid admission webhooks: %v", err)
+			})
+		} else {
+			plugin = webhooks
+		}
+		m.admissionPlugins = append(m.admissionPlugins, plugin)
+	}
+	m.apiPrefix = c.APIPrefix
+	if m.apiPrefix == "" {
+		m.apiPrefix = defaultAPIPrefix
+	}
+	m.init(c.Cloud, c.PodInfoGetter, podCacheSyncPeriod)
+	if c.HPAMetrics != nil {
+		hpaSyncPeriod := c.HPASyncPeriod
+		if hpaSyncPeriod == 0 {
+			hpaSyncPeriod = defaultHPASyncPeriod
+		}
+		autoscaling := hpacontroller.NewHPAController(m.autoscalerRegistry, m.controllerRegistry, c.HPAMetrics)
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			autoscaling.Run(hpaSyncPeriod, stop)
+		})
+	}
+	// Controllers create pods through the pods storage, so that they are
+	// validated and admitted like the pods of users.
+	podCreater := m.storage["pods"]
+	if c.EnableDaemonSetController {
+		daemonSetSyncPeriod := c.DaemonSetSyncPeriod
+		if daemonSetSyncPeriod == 0 {
+			daemonSetSyncPeriod = defaultDaemonSetSyncPeriod
+		}
+		daemons := daemon.NewDaemonSetController(m.daemonSetRegistry, m.podRegistry, podCreater, m.bindingRegistry, m.minionRegistry)
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			daemons.Run(daemonSetSyncPeriod, stop)
+		})
+	}
+	if c.EnableJobController {
+		jobSyncPeriod := c.JobSyncPeriod
+		if jobSyncPeriod == 0 {
+			jobSyncPeriod = defaultJobSyncPeriod
+		}
+		jobs := jobcontroller.NewJobController(m.jobRegistry, m.podRegistry, podCreater, m.podCache)
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			jobs.Run(jobSyncPeriod, stop)
+		})
+	}
+	if c.EnableRollingUpdateController {
+		rollingUpdates := rollingupdate.NewRollingUpdateController(m.controllerRegistry, m.podRegistry, podCreater, m.podCache)
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			rollingUpdates.Run(rollingUpdatePeriod, stop)
+		})
+	}
+	if len(c.IngressConfigPath) > 0 {
+		ingresses := ingresscontroller.NewIngressController(m.ingressRegistry, m.serviceRegistry, m.secretRegistry, m.eventRegistry, c.IngressConfigPath, c.IngressReloadCommand)
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			ingresses.Run(ingressSyncPeriod, stop)
+		})
+	}
+	if c.EnableGarbageCollector {
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			m.garbageCollector.Run(garbageCollectionPeriod, stop)
+		})
+	}
+	if c.NodeMonitorGracePeriod > 0 {
+		nodes := nodecontroller.NewNodeLifecycleController(m.minionRegistry, m.podRegistry, c.NodeMonitorGracePeriod)
+		nodes.EnableNonGracefulNodeShutdown = c.EnableNonGracefulNodeShutdown
+		m.controllers = append(m.controllers, func(stop <-chan struct{}) {
+			nodes.Run(nodeMonitorPeriod, stop)
+		})
+	} else if c.EnableNonGracefulNodeShutdown {
+		glog.Warningf("Non-graceful node shutdown is enabled, but has no effect without a node monitor grace period.")
+	}
+	m.running.Add(1)
+	if c.EnableLeaderElection || c.MasterCount > 1 {
+		ttl := c.LeaderElectionTTL
+		if ttl == 0 {
+			ttl = defaultLeaderElectionTTL
+		}
+		elector := leaderelection.NewLeaderElector(helper.Client, helper.PathPrefix+leaderElectionKey, uuid.NewUUID().String(), ttl)
+		go func() {
+			defer m.running.Done()
+			elector.Run(m.lead, m.stop)
+		}()
+	} else {
+		go func() {
+			defer m.running.Done()
+			m.lead(m.stop)
+		}()
+	}
+	if c.EtcdHelper.Client != nil {
+		m.healthChecks = append(m.healthChecks, namedHealthChecker{"etcd", etcdHealthCheck(c.EtcdHelper.Client)})
+	}
+	m.healthChecks = append(m.healthChecks, namedHealthChecker{"minions", minionRegistryHealthCheck(m.minionRegistry)})
+	for i, checker := range c.HealthChecks {
+		m.healthChecks = append(m.healthChecks, namedHealthChecker{fmt.Sprintf("healthChecks[%d]", i), checker})
+	}
+	components := map[string]HealthChecker{
+		"scheduler":          httpHealthCheck(fmt.Sprintf("http://127.0.0.1:%d/healthz", SchedulerPort)),
+		"controller-manager": httpHealthCheck(fmt.Sprintf("http://127.0.0.1:%d/healthz", ControllerManagerPort)),
+	}
+	if c.EtcdHelper.Client != nil {
+		components["etcd"] = etcdHealthCheck(c.EtcdHelper.Client)
+	}
+	for name, checker := range c.ComponentHealthChecks {
+		components[name] = checker
+	}
+	componentCheckPeriod := c.ComponentCheckPeriod
+	if componentCheckPeriod == 0 {
+		componentCheckPeriod = defaultComponentCheckPeriod
+	}
+	m.checkComponents(components, componentCheckPeriod)
+	m.countObjects(m.objectCounters(serviceRegistry), objectCountPeriod)
+	if c.ReadOnlyPort != 0 {
+		address := c.ReadOnlyAddress
+		if address == "" {
+			address = defaultReadOnlyAddress
+		}
+		m.serveReadOnly(net.JoinHostPort(address, strconv.Itoa(c.ReadOnlyPort)))
+	}
+	if c.StopCh != nil {
+		go func() {
+			select {
+			case <-c.StopCh:
+				m.Stop()
+			case <-m.stop:
+			}
+		}()
+	}
 	return m
 }
 
-func makeMinionRegistry(c *Config) minion.Registry {
+// objectCounters returns the registries of the master which can count the
+// objects they store. registry is the registry of pods, replication
+// controllers, services and minions.
+func (m *Master) objectCounters(registry *etcd.Registry) []generic.Counter {
+	counters := []generic.Counter{registry}
+	for _, r := range []generic.Registry{
+		m.eventRegistry, m.secretRegistry, m.namespaceRegistry, m.quotaRegistry,
+		m.accountRegistry, m.autoscalerRegistry, m.limitRangeRegistry,
+		m.configMapRegistry, m.daemonSetRegistry, m.jobRegistry, m.ingressRegistry,
+		m.networkPolicyRegistry, m.budgetRegistry, m.roleRegistry,
+		m.roleBindingRegistry, m.clusterRoleRegistry, m.clusterRoleBindings,
+		m.leaseRegistry,
+	} {
+		if counter, ok := r.(generic.Counter); ok {
+			counters = append(counters, counter)
+		}
+	}
+	return counters
+}
+
+// countObjects sets apiserver_etcd_object_counts to the number of objects each
+// of counters stores, every period until the master stops. The first count is
+// taken a period after the master starts, which keeps it off the reads made
+// while the master comes up.
+func (m *Master) countObjects(counters []generic.Counter, period time.Duration) {
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		select {
+		case <-time.After(period):
+		case <-m.stop:
+			return
+		}
+		util.Until(func() {
+			for _, counter := range counters {
+				counts, err := counter.CountObjects()
+				if err != nil {
+					glog.Errorf("Unable to count stored objects: %v", err)
+					continue
+				}
+				for resource, count := range counts {
+					metrics.ObjectCounts.Set(float64(count), resource)
+				}
+			}
+		}, period, m.stop)
+	}()
+}
+
+// lead runs the controllers of the master until stop is closed.
+func (m *Master) lead(stop <-chan struct{}) {
+	m.leaderElectedOnce.Do(func() { close(m.leaderElected) })
+	var wg sync.WaitGroup
+	for _, run := range m.controllers {
+		wg.Add(1)
+		go func(run func(<-chan struct{})) {
+			defer wg.Done()
+			run(stop)
+		}(run)
+	}
+	wg.Wait()
+}
+
+// LeaderElected returns a channel which is closed once the master first leads,
+// that is runs its controllers. Without leader election the master always
+// leads.
+func (m *Master) LeaderElected() <-chan struct{} {
+	return m.leaderElected
+}
+
+// Stop signals the background goroutines of the master to exit and waits until
+// they have. It is safe to call Stop more than once.
+func (m *Master) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		if m.auditLog != nil {
+			m.auditLog.Close()
+		}
+	})
+	m.running.Wait()
+}
+
+// Handler returns an http.Handler serving every API version of the master
+// under its API prefix, which lists the versions, along with the apiserver
+// support functions, a /healthz that runs the health checks of the master, and
+// the apiserver metrics at /metrics. Mutating requests are audited if an audit
+// log is configured, requests which take longer than the request timeout fail,
+// clients which exceed the rate limit are throttled, and cross-origin requests
+// are allowed from the configured CORS origins. If a token file is configured,
+// requests must authenticate with a bearer token first. Authenticated requests
+// may impersonate another user with the Impersonate-User header, if they are
+// authorized to.
+func (m *Master) Handler() http.Handler {
+	handler := m.Authenticate(m.unauthenticatedHandler())
+	if len(m.corsAllowedOrigins) > 0 {
+		handler = apiserver.CORS(handler, m.corsAllowedOrigins, nil, nil, "true")
+	}
+	return handler
+}
+
+// Authenticate wraps an http Handler so that it only serves requests which
+// authenticate with a token from the token file of the master, if one is
+// configured, and so that their users are known to the RequestUsers of the master.
+// Without a token file, the handlers.RemoteUserHeader clients send is removed,
+// since no user was authenticated to name.
+func (m *Master) Authenticate(handler http.Handler) http.Handler {
+	if m.authenticator == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.Header.Del(handlers.RemoteUserHeader)
+			handler.ServeHTTP(w, req)
+		})
+	}
+	return handlers.NewRequestAuthenticator(m.userContext, m.authenticator, handlers.Unauthorized, handler)
+}
+
+// unauthenticatedHandler returns the handler chain of Handler which is served
+// once a request is authenticated.
+func (m *Master) unauthenticatedHandler() http.Handler {
+	return m.handlerChain(true)
+}
+
+// handlerChain returns the handlers of the master, which authorize requests if
+// authorize is true and the master has an authorizer.
+func (m *Master) handlerChain(authorize bool) http.Handler {
+	apiMux := http.NewServeMux()
+	apiserver.NewAPIGroup(m.API_v1beta1()).InstallREST(apiMux, m.apiPrefix+"/v1beta1")
+	apiserver.NewAPIGroup(m.API_v1beta2()).InstallREST(apiMux, m.apiPrefix+"/v1beta2")
+	apiserver.InstallVersions(apiMux, m.apiPrefix, "v1beta1", "v1beta2")
+	apiserver.InstallSupport(apiMux)
+
+	// InstallSupport registers a /healthz of its own, so the master's is served
+	// in front of it.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", m.handleHealthz)
+	mux.HandleFunc("/podCacheStatus", m.handlePodCacheStatus)
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/", apiMux)
+	handler := http.Handler(mux)
+	// Requests may impersonate other users once they are known, and are then
+	// authorized and audited as the users they impersonate.
+	users := m.requestUsers
+	impersonate := authorize && users != nil
+	if impersonate {
+		users = apiserver.ImpersonatedUsers(users)
+	}
+	if authorize && m.authorizer != nil {
+		handler = apiserver.Authorize(handler, m.authorizer, users, m.apiPrefix, m.storage, publicPaths, latest.Codec)
+	}
+	if m.auditLog != nil {
+		handler = apiserver.Audit(handler, m.auditLog, users)
+	}
+	if impersonate {
+		handler = apiserver.Impersonate(handler, m.authorizer, m.requestUsers, latest.Codec)
+	}
+	handler = apiserver.TimeoutHandler(handler, m.requestTimeout, m.watchTimeout, isLongRunningRequest)
+	if m.rateLimiter != nil {
+		handler = apiserver.RateLimit(handler, m.rateLimiter, m.requestUsers, latest.Codec)
+	}
+	return handler
+}
+
+func makeMinionRegistry(c *Config, helper tools.EtcdHelper) minion.Registry {
 	var minionRegistry minion.Registry
 	if c.Cloud != nil && len(c.MinionRegexp) > 0 {
 		var err error
@@ -114,7 +869,7 @@ func makeMinionRegistry(c *Config) minion.Registry {
 		}
 	}
 	if minionRegistry == nil {
-		minionRegistry = etcd.NewRegistry(c.EtcdHelper, nil)
+		minionRegistry = etcd.NewRegistry(helper, nil)
 		for _, minionID := range c.Minions {
 			minionRegistry.CreateMinion(nil, &api.Minion{
 				TypeMeta:      api.TypeMeta{ID: minionID},
@@ -123,10 +878,16 @@ func makeMinionRegistry(c *Config) minion.Registry {
 		}
 	}
 	if c.HealthCheckMinions {
-		minionRegistry = minion.NewHealthyRegistry(minionRegistry, &http.Client{})
+		minionRegistry = minion.NewHealthyRegistry(minionRegistry, &http.Client{}, minion.HealthyConfig{
+			FailureThreshold: c.MinionFailureThreshold,
+			SuccessThreshold: c.MinionSuccessThreshold,
+		})
 	}
 	if c.MinionCacheTTL > 0 {
-		cachingMinionRegistry, err := minion.NewCachingRegistry(minionRegistry, c.MinionCacheTTL)
+		cachingMinionRegistry, err := minion.NewCachingRegistry(minionRegistry, minion.CachingConfig{
+			GetTTL:  c.MinionCacheGetTTL,
+			ListTTL: c.MinionCacheTTL,
+		})
 		if err != nil {
 			glog.Errorf("Failed to initialize caching layer, ignoring cache.")
 		} else {
@@ -136,27 +897,140 @@ func makeMinionRegistry(c *Config) minion.Registry {
 	return minionRegistry
 }
 
-func (m *Master) init(cloud cloudprovider.Interface, podInfoGetter client.PodInfoGetter) {
+// admissionPlugin returns the admission plugin called name, built from the
+// registries of m.
+func (m *Master) admissionPlugin(name string, requiredLabels []string) (AdmissionController, error) {
+	switch name {
+	case AdmissionControlNamespaceExists:
+		return NewNamespaceExistsAdmission(m.namespaceRegistry), nil
+	case AdmissionControlResourceQuota:
+		return NewResourceQuotaAdmission(m.quotaRegistry, m.podRegistry, quotaRefreshPeriod), nil
+	case AdmissionControlServiceAccount:
+		return NewServiceAccountAdmission(m.accountRegistry), nil
+	case AdmissionControlLimitRanger:
+		return NewLimitRangerAdmission(m.limitRangeRegistry), nil
+	case AdmissionControlConfigMapRef:
+		return NewConfigMapRefAdmission(m.configMapRegistry), nil
+	case AdmissionControlRequiredLabels:
+		return NewRequiredLabelsAdmission(requiredLabels...), nil
+	case AdmissionControlNetworkPolicy:
+		return NewNetworkPolicyAdmission(), nil
+	case AdmissionControlNoPreemptibleCritical:
+		return NewNoPreemptibleCriticalAdmission(m.minionRegistry, m.podRegistry), nil
+	}
+	return nil, fmt.Errorf("unknown admission control plugin %q", name)
+}
+
+func (m *Master) init(cloud cloudprovider.Interface, podInfoGetter client.PodInfoGetter, podCacheSyncPeriod time.Duration) {
 	podCache := NewPodCache(podInfoGetter, m.podRegistry)
-	go util.Forever(func() { podCache.UpdateAllContainers() }, time.Second*30)
+	m.podCache = podCache
+	m.healthChecks = append(m.healthChecks, namedHealthChecker{"podCache", podCacheHealthCheck(podCache, podCacheSyncPeriod)})
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		util.Until(func() { podCache.UpdateAllContainers() }, podCacheSyncPeriod, m.stop)
+	}()
+	if m.filterEndpoints {
+		m.endpointRegistry = endpoint.NewFilteredEndpointRegistry(m.endpointRegistry, m.podRegistry, podCache)
+	}
+
+	m.garbageCollector = gc.NewGarbageCollector(map[string]generic.Registry{
+		"Pod":                   &podGenericRegistry{m.podRegistry},
+		"ReplicationController": &controllerGenericRegistry{m.controllerRegistry},
+		"Job":                   m.jobRegistry,
+		"DaemonSet":             m.daemonSetRegistry,
+	})
 
 	m.storage = map[string]apiserver.RESTStorage{
 		"pods": pod.NewREST(&pod.RESTConfig{
-			CloudProvider: cloud,
-			PodCache:      podCache,
-			PodInfoGetter: podInfoGetter,
-			Registry:      m.podRegistry,
-			Minions:       m.client,
+			CloudProvider:       cloud,
+			PodCache:            podCache,
+			PodInfoGetter:       podInfoGetter,
+			Registry:            m.podRegistry,
+			Minions:             m.client,
+			StaleCacheThreshold: m.podCacheStale,
+			ConfigMaps:          m.configMapRegistry,
+			Secrets:             m.secretRegistry,
+			Events:              m.eventRegistry,
 		}),
-		"replicationControllers": controller.NewREST(m.controllerRegistry, m.podRegistry),
-		"services":               service.NewREST(m.serviceRegistry, cloud, m.minionRegistry),
-		"endpoints":              endpoint.NewREST(m.endpointRegistry),
-		"minions":                minion.NewREST(m.minionRegistry),
-		"events":                 event.NewREST(m.eventRegistry),
+		"replicationControllers":   controller.NewREST(m.controllerRegistry, m.podRegistry),
+		"services":                 service.NewREST(m.serviceRegistry, cloud, m.minionRegistry),
+		"endpoints":                endpoint.NewREST(m.endpointRegistry),
+		"minions":                  minion.NewREST(m.minionRegistry),
+		"minions/status":           minion.NewStatusREST(m.minionRegistry),
+		"events":                   event.NewREST(m.eventRegistry, m.involvedObjectLabels),
+		"secrets":                  secret.NewREST(m.secretRegistry, m.maxSecretSize),
+		"namespaces":               namespace.NewREST(m.namespaceRegistry),
+		"resourceQuotas":           resourcequota.NewREST(m.quotaRegistry),
+		"serviceAccounts":          serviceaccount.NewREST(m.accountRegistry),
+		"horizontalPodAutoscalers": hpa.NewREST(m.autoscalerRegistry, m.controllerRegistry),
+		"limitRanges":              limitrange.NewREST(m.limitRangeRegistry),
+		"configMaps":               configmap.NewREST(m.configMapRegistry, m.maxConfigMapSize),
+		"daemonSets":               daemonset.NewREST(m.daemonSetRegistry),
+		"jobs":                     job.NewREST(m.jobRegistry),
+		"ingresses":                ingress.NewREST(m.ingressRegistry, m.serviceRegistry),
+		"networkPolicies":          networkpolicy.NewREST(m.networkPolicyRegistry),
+		"podDisruptionBudgets":     poddisruptionbudget.NewREST(m.budgetRegistry),
+		"pods/eviction":            pod.NewEvictionREST(m.podRegistry, m.budgetRegistry),
+		"minions/drain":            pod.NewDrainREST(m.podRegistry, m.budgetRegistry, m.eventRegistry),
+		"componentStatuses":        componentstatus.NewREST(m.componentStatuses),
+		"roles":                    rbac.NewRoleREST(m.roleRegistry),
+		"roleBindings":             rbac.NewRoleBindingREST(m.roleBindingRegistry),
+		"clusterRoles":             rbac.NewClusterRoleREST(m.clusterRoleRegistry),
+		"clusterRoleBindings":      rbac.NewClusterRoleBindingREST(m.clusterRoleBindings),
+		"leases":                   lease.NewREST(m.leaseRegistry),
 
 		// TODO: should appear only in scheduler API group.
 		"bindings": binding.NewREST(m.bindingRegistry),
 	}
+	if len(m.admissionPlugins) > 0 {
+		for resource, storage := range m.storage {
+			m.storage[resource] = NewAdmittingStorage(resource, storage, m.admissionPlugins)
+		}
+	}
+	for resource, kind := range garbageCollectedResources {
+		m.storage[resource] = NewPropagatingStorage(kind, m.storage[resource], m.garbageCollector)
+	}
+	// Logs are only read and exec connects to a pod which already exists, so
+	// neither is subject to admission control.
+	if logs, ok := podInfoGetter.(client.PodLogGetter); ok {
+		m.storage["pods/log"] = pod.NewLogREST(m.podRegistry, logs)
+	}
+	if kubelets, ok := podInfoGetter.(*client.HTTPPodInfoGetter); ok {
+		m.storage["pods/exec"] = pod.NewExecREST(m.podRegistry, podInfoGetter, m.minionRegistry, kubelets.Port)
+	}
+}
+
+// involvedObjectLabels returns the labels of the object an event refers to.
+func (m *Master) involvedObjectLabels(ctx api.Context, ref *api.ObjectReference) (labels.Set, error) {
+	ctx = api.WithNamespace(ctx, ref.Namespace)
+	switch ref.Kind {
+	case "Pod":
+		p, err := m.podRegistry.GetPod(ctx, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		return labels.Set(p.Labels), nil
+	case "ReplicationController":
+		rc, err := m.controllerRegistry.GetController(ctx, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		return labels.Set(rc.Labels), nil
+	case "Service":
+		svc, err := m.serviceRegistry.GetService(ctx, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		return labels.Set(svc.Labels), nil
+	case "Minion":
+		node, err := m.minionRegistry.GetMinion(ctx, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		return labels.Set(node.Labels), nil
+	}
+	return nil, fmt.Errorf("unsupported kind %q", ref.Kind)
 }
 
 // API_v1beta1 returns the resources and codec for API version v1beta1.
@@ -174,5 +1048,11 @@ func (m *Master) API_v1beta2() (map[string]apiserver.RESTStorage, runtime.Codec,
 	for k, v := range m.storage {
 		storage[k] = v
 	}
-	return storage, v1beta2.Codec, "/api/v1beta1", latest.SelfLinker
+	return storage, v1beta2.Codec, "/api/v1beta2", latest.SelfLinker
+}
+
+// isLongRunningRequest returns whether req holds its connection open, so that
+// it must not be cut off by the request timeout.
+func isLongRunningRequest(req *http.Request) bool {
+	return longRunningRequestRE.MatchString(req.URL.Path) || req.URL.Query().Get("watch") == "true"
 }
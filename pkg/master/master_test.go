@@ -0,0 +1,1118 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/v1beta1"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/v1beta2"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/handlers"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/coreos/go-etcd/etcd"
+)
+
+func TestAPIVersionPrefixes(t *testing.T) {
+	m := &Master{}
+	if _, codec, prefix, _ := m.API_v1beta1(); codec != v1beta1.Codec || prefix != "/api/v1beta1" {
+		t.Errorf("unexpected v1beta1 codec or prefix: %v %s", codec, prefix)
+	}
+	if _, codec, prefix, _ := m.API_v1beta2(); codec != v1beta2.Codec || prefix != "/api/v1beta2" {
+		t.Errorf("unexpected v1beta2 codec or prefix: %v %s", codec, prefix)
+	}
+}
+
+// countingPodInfoGetter counts the calls made to it.
+type countingPodInfoGetter struct {
+	lock  sync.Mutex
+	calls int
+}
+
+func (c *countingPodInfoGetter) GetPodInfo(host, namespace, id string) (api.PodInfo, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls++
+	return api.PodInfo{}, nil
+}
+
+func (c *countingPodInfoGetter) Calls() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.calls
+}
+
+func TestPodCacheSyncPeriod(t *testing.T) {
+	getter := &countingPodInfoGetter{}
+	m := &Master{
+		podRegistry: registrytest.NewPodRegistry(&api.PodList{
+			Items: []api.Pod{
+				{
+					TypeMeta:     api.TypeMeta{ID: "foo"},
+					CurrentState: api.PodState{Host: "machine"},
+				},
+			},
+		}),
+		stop: make(chan struct{}),
+	}
+	m.init(nil, getter, 100*time.Millisecond)
+	defer m.Stop()
+
+	time.Sleep(500 * time.Millisecond)
+	if calls := getter.Calls(); calls < 2 {
+		t.Errorf("expected the pod cache to sync at least twice, synced %d times", calls)
+	}
+}
+
+func TestStop(t *testing.T) {
+	getter := &countingPodInfoGetter{}
+	m := &Master{
+		podRegistry: registrytest.NewPodRegistry(&api.PodList{
+			Items: []api.Pod{
+				{
+					TypeMeta:     api.TypeMeta{ID: "foo"},
+					CurrentState: api.PodState{Host: "machine"},
+				},
+			},
+		}),
+		stop: make(chan struct{}),
+	}
+	m.init(nil, getter, 10*time.Millisecond)
+	m.Stop()
+	m.Stop()
+
+	calls := getter.Calls()
+	time.Sleep(50 * time.Millisecond)
+	if after := getter.Calls(); after != calls {
+		t.Errorf("expected the pod cache to stop syncing, went from %d to %d calls", calls, after)
+	}
+}
+
+// fakeCounter counts the objects of a single resource.
+type fakeCounter struct {
+	resource string
+	count    int
+	err      error
+}
+
+func (c fakeCounter) CountObjects() (map[string]int, error) {
+	return map[string]int{c.resource: c.count}, c.err
+}
+
+func TestCountObjects(t *testing.T) {
+	m := &Master{stop: make(chan struct{})}
+	m.countObjects([]generic.Counter{
+		fakeCounter{resource: "counted", count: 3},
+		fakeCounter{resource: "failed", count: 5, err: fmt.Errorf("etcd is down")},
+	}, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	m.Stop()
+	if e, a := 3.0, metrics.ObjectCounts.Value("counted"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := 0.0, metrics.ObjectCounts.Value("failed"); e != a {
+		t.Errorf("expected counts which failed not to be recorded, got %v", a)
+	}
+}
+
+// newFakeEtcdClient returns a fake etcd client which expects the reads New
+// makes when it moves objects stored at legacy keys.
+func newFakeEtcdClient(t *testing.T) *tools.FakeEtcdClient {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	for _, key := range []string{"/registry/pods", "/registry/controllers", "/registry/services/specs", "/registry/services/endpoints"} {
+		fakeClient.ExpectNotFoundGet(key)
+	}
+	return fakeClient
+}
+
+func TestStopCh(t *testing.T) {
+	stopCh := make(chan struct{})
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/pods/default")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:         tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:      &countingPodInfoGetter{},
+		PodCacheSyncPeriod: 10 * time.Millisecond,
+		StopCh:             stopCh,
+	})
+	close(stopCh)
+
+	select {
+	case <-m.stop:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the master to stop when its stop channel was closed")
+	}
+	m.Stop()
+}
+
+func TestNewEtcdHelperFromURLs(t *testing.T) {
+	invalid := [][]string{
+		{},
+		{"localhost:4001"},
+		{"http://"},
+		{"http://127.0.0.1:4001", "ftp://127.0.0.1:4001"},
+	}
+	for _, endpoints := range invalid {
+		if _, err := NewEtcdHelperFromURLs(endpoints, "", "", 0); err == nil {
+			t.Errorf("expected an error for %v", endpoints)
+		}
+	}
+
+	helper, err := NewEtcdHelperFromURLs([]string{"http://127.0.0.1:4001", "https://10.0.0.1:4001"}, "", "", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if helper.Client == nil || helper.Codec != latest.Codec {
+		t.Errorf("unexpected helper: %#v", helper)
+	}
+
+	if _, err := NewEtcdHelperFromURLs([]string{"http://127.0.0.1:4001"}, "v0", "", 0); err == nil {
+		t.Errorf("expected an error for an unknown version")
+	}
+}
+
+func TestHandlerAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{"", filepath.Join(dir, "audit.log")} {
+		fakeClient := newFakeEtcdClient(t)
+		fakeClient.ExpectNotFoundGet("/")
+		fakeClient.ExpectNotFoundGet("/registry/pods")
+		fakeClient.ExpectNotFoundGet("/registry/pods/default")
+		fakeClient.ExpectNotFoundGet("/registry/minions")
+		fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+		fakeClient.ExpectNotFoundGet("/registry/jobs")
+		fakeClient.ExpectNotFoundGet("/registry/controllers")
+		m := New(&Config{
+			EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+			PodInfoGetter: &countingPodInfoGetter{},
+			AuditLogPath:  path,
+		})
+		server := httptest.NewServer(m.Handler())
+		resp, err := http.Get(server.URL + "/api/v1beta1/pods")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		resp, err = http.Post(server.URL+"/api/v1beta1/pods", "application/json", strings.NewReader("{"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		server.Close()
+		m.Stop()
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"verb":"POST"`) {
+		t.Errorf("expected only the create to be audited, got %q", lines)
+	}
+}
+
+func TestHandlerDiscovery(t *testing.T) {
+	allow := AdmissionControllerFunc(func(AdmissionAttributes) error { return nil })
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:       tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:    &countingPodInfoGetter{},
+		AdmissionPlugins: []AdmissionController{allow},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	var versions api.APIVersions
+	getJSON(t, server.URL+"/api", &versions)
+	if !reflect.DeepEqual([]string{"v1beta1", "v1beta2"}, versions.Versions) {
+		t.Errorf("unexpected versions %v", versions.Versions)
+	}
+
+	for _, version := range versions.Versions {
+		var list api.APIResourceList
+		getJSON(t, server.URL+"/api/"+version, &list)
+		if list.GroupVersion != version {
+			t.Errorf("expected version %s, got %s", version, list.GroupVersion)
+		}
+		resources := map[string]api.APIResource{}
+		for _, resource := range list.Resources {
+			resources[resource.Name] = resource
+		}
+		for name := range m.storage {
+			if _, ok := resources[name]; !ok {
+				t.Errorf("%s: expected resource %s to be listed", version, name)
+			}
+		}
+		if len(resources) != len(m.storage) {
+			t.Errorf("%s: expected %d resources, got %d", version, len(m.storage), len(resources))
+		}
+		if resources["minions"].Namespaced || resources["namespaces"].Namespaced || !resources["pods"].Namespaced {
+			t.Errorf("%s: unexpected scopes %#v", version, list.Resources)
+		}
+		if e, a := []string{"get", "list", "watch", "create", "update", "delete"}, resources["pods"].Verbs; !reflect.DeepEqual(e, a) {
+			t.Errorf("%s: expected the verbs of pods to be %v, got %v", version, e, a)
+		}
+		if e, a := []string{"create"}, resources["pods/eviction"].Verbs; !reflect.DeepEqual(e, a) {
+			t.Errorf("%s: expected the verbs of pods/eviction to be %v, got %v", version, e, a)
+		}
+	}
+}
+
+func TestAdmissionControl(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:       tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:    &countingPodInfoGetter{},
+		AdmissionControl: []string{AdmissionControlRequiredLabels, "Unknown"},
+		RequiredLabels:   []string{"team"},
+	})
+	defer m.Stop()
+
+	// The validation plugin always runs first.
+	if len(m.admissionPlugins) != 3 {
+		t.Fatalf("expected 3 admission plugins, got %d", len(m.admissionPlugins))
+	}
+	unlabelled := AdmissionAttributes{Resource: "pods", Operation: AdmissionCreate, Object: &api.Pod{}}
+	if err := m.admissionPlugins[1].Admit(unlabelled); err == nil {
+		t.Errorf("expected a pod without the required labels to be rejected")
+	}
+	labelled := AdmissionAttributes{Resource: "pods", Operation: AdmissionCreate, Object: &api.Pod{Labels: map[string]string{"team": "a"}}}
+	if err := m.admissionPlugins[1].Admit(labelled); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := m.admissionPlugins[2].Admit(labelled); err == nil {
+		t.Errorf("expected an unknown plugin to reject every write")
+	}
+
+	for _, name := range []string{
+		AdmissionControlNamespaceExists,
+		AdmissionControlResourceQuota,
+		AdmissionControlServiceAccount,
+		AdmissionControlLimitRanger,
+		AdmissionControlConfigMapRef,
+		AdmissionControlRequiredLabels,
+		AdmissionControlNetworkPolicy,
+		AdmissionControlNoPreemptibleCritical,
+	} {
+		if plugin, err := m.admissionPlugin(name, nil); err != nil || plugin == nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
+
+func getJSON(t *testing.T, url string, into interface{}) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d for %s", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHandlerCORS(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:         tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:      &countingPodInfoGetter{},
+		CORSAllowedOrigins: []string{`^https://.*\.example\.com$`},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	table := []struct {
+		method  string
+		origin  string
+		allowed bool
+	}{
+		{"GET", "https://ui.example.com", true},
+		{"OPTIONS", "https://ui.example.com", true},
+		{"GET", "https://example.org", false},
+		{"GET", "http://ui.example.com", false},
+		{"GET", "", false},
+	}
+	for _, item := range table {
+		req, err := http.NewRequest(item.method, server.URL+"/api", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.origin != "" {
+			req.Header.Set("Origin", item.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s %q: unexpected status %d", item.method, item.origin, resp.StatusCode)
+		}
+		headers := []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Allow-Headers"}
+		for _, header := range headers {
+			value := resp.Header.Get(header)
+			if item.allowed && value == "" {
+				t.Errorf("%s %q: expected %s to be set", item.method, item.origin, header)
+			}
+			if !item.allowed && value != "" {
+				t.Errorf("%s %q: expected %s not to be set, got %q", item.method, item.origin, header, value)
+			}
+		}
+		if item.allowed && resp.Header.Get("Access-Control-Allow-Origin") != item.origin {
+			t.Errorf("%s %q: unexpected allowed origin %q", item.method, item.origin, resp.Header.Get("Access-Control-Allow-Origin"))
+		}
+	}
+}
+
+func TestHandlerNoCORS(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/api", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("Origin", "https://ui.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if value := resp.Header.Get("Access-Control-Allow-Origin"); value != "" {
+		t.Errorf("expected CORS to be disabled, got %q", value)
+	}
+}
+
+func TestIsLongRunningRequest(t *testing.T) {
+	table := map[string]bool{
+		"/api/v1beta1/pods":                  false,
+		"/api/v1beta1/pods/foo":              false,
+		"/api/v1beta1/pods/foo/eviction":     false,
+		"/api/v1beta1/pods/logger":           false,
+		"/api/v1beta1/pods?watch=true":       true,
+		"/api/v1beta1/watch/pods":            true,
+		"/api/v1beta1/proxy/services/foo/":   true,
+		"/api/v1beta1/pods/foo/log":          true,
+		"/api/v1beta1/pods/foo/exec?cmd=sh":  true,
+		"/api/v1beta1/redirect/services/foo": false,
+	}
+	for path, expected := range table {
+		req, err := http.NewRequest("GET", "http://localhost"+path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual := isLongRunningRequest(req); actual != expected {
+			t.Errorf("%s: expected %v, got %v", path, expected, actual)
+		}
+	}
+}
+
+func TestHandlerRateLimit(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:        tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:     &countingPodInfoGetter{},
+		RequestsPerSecond: 0.001,
+		BurstSize:         2,
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	for i, expected := range []int{http.StatusOK, http.StatusOK, 429} {
+		resp, err := http.Get(server.URL + "/api")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("%d: expected %d, got %d", i, expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandlerMetrics(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1beta1/minions")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	expected := `apiserver_request_total{verb="LIST",resource="minions",code="200"}`
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("expected %s in:\n%s", expected, body)
+	}
+}
+
+func TestHandlerTokenAuth(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "tokens")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(tokenFile.Name())
+	fmt.Fprintln(tokenFile, "token1,alice,uid1,\"group1,group2\"")
+	tokenFile.Close()
+	auditPath := filepath.Join(os.TempDir(), fmt.Sprintf("audit-%d.log", time.Now().UnixNano()))
+	defer os.Remove(auditPath)
+
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+		TokenAuthFile: tokenFile.Name(),
+		AuditLogPath:  auditPath,
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	for token, expected := range map[string]int{
+		"":       http.StatusUnauthorized,
+		"token2": http.StatusUnauthorized,
+		"token1": http.StatusOK,
+	} {
+		req, err := http.NewRequest("GET", server.URL+"/api/v1beta1/minions", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("%q: expected %d, got %d", token, expected, resp.StatusCode)
+		}
+	}
+
+	// The audit log records the user the request authenticated as.
+	fakeClient.ExpectNotFoundGet("/registry/minions/missing")
+	req, err := http.NewRequest("DELETE", server.URL+"/api/v1beta1/minions/missing", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer token1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	data, err := ioutil.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"user":"alice"`) {
+		t.Errorf("expected the request of alice to be audited, got %s", data)
+	}
+}
+
+func TestHandlerImpersonation(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "tokens")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(tokenFile.Name())
+	fmt.Fprintln(tokenFile, "token1,alice,uid1,")
+	tokenFile.Close()
+	auditPath := filepath.Join(os.TempDir(), fmt.Sprintf("audit-%d.log", time.Now().UnixNano()))
+	defer os.Remove(auditPath)
+
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+		TokenAuthFile: tokenFile.Name(),
+		AuditLogPath:  auditPath,
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	pod := &api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		DesiredState: api.PodState{
+			Manifest: api.ContainerManifest{
+				Version:    "v1beta1",
+				Containers: []api.Container{{Name: "web", Image: "nginx"}},
+			},
+		},
+	}
+	req, err := http.NewRequest("POST", server.URL+"/api/v1beta1/pods?sync=true", strings.NewReader(runtime.EncodeOrDie(latest.Codec, pod)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer token1")
+	req.Header.Set("Impersonate-User", "bob")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the pod to be created, got %d: %s", resp.StatusCode, body)
+	}
+
+	data, err := ioutil.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{`"user":"bob"`, `"originalUser":"alice"`, `"impersonatedUser":"bob"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %s in the audit log, got %s", expected, data)
+		}
+	}
+}
+
+func TestAuthenticateWithoutTokenFile(t *testing.T) {
+	m := &Master{}
+	var name string
+	handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name = req.Header.Get(handlers.RemoteUserHeader)
+	}))
+	req, err := http.NewRequest("GET", "/api/v1beta1/proxy/minions/m1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set(handlers.RemoteUserHeader, "mallory")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if name != "" {
+		t.Errorf("expected the user sent by the client to be removed, got %q", name)
+	}
+}
+
+func TestHandlerInvalidTokenFile(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+		TokenAuthFile: filepath.Join(os.TempDir(), "no-such-token-file"),
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected every request to be unauthorized, got %d", resp.StatusCode)
+	}
+}
+
+// headerRequestUsers authenticates requests as the user named by their X-User header.
+type headerRequestUsers struct{}
+
+func (headerRequestUsers) Get(req *http.Request) (user.Info, bool) {
+	name := req.Header.Get("X-User")
+	if name == "" {
+		return nil, false
+	}
+	return &user.DefaultInfo{Name: name}, true
+}
+
+func TestHandlerRBAC(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	fakeClient.ExpectNotFoundGet("/registry/namespaces")
+	fakeClient.Data["/registry/clusterrolebindings"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{}},
+	}
+	m := New(&Config{
+		EtcdHelper:        tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:     &countingPodInfoGetter{},
+		RequestUsers:      headerRequestUsers{},
+		AuthorizationMode: AuthorizationModeRBAC,
+		RBACSuperUser:     "admin",
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	do := func(username, method, path, body string) int {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if username != "" {
+			req.Header.Set("X-User", username)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := do("alice", "GET", "/api/v1beta1/namespaces", ""); code != http.StatusForbidden {
+		t.Errorf("expected alice to be denied before any role is bound, got %d", code)
+	}
+	if code := do("", "GET", "/version", ""); code != http.StatusOK {
+		t.Errorf("expected requests outside the API to be served, got %d", code)
+	}
+	if code := do("admin", "GET", "/api/v1beta1/namespaces", ""); code != http.StatusOK {
+		t.Errorf("expected the super user to be allowed, got %d", code)
+	}
+
+	if code := do("admin", "POST", "/api/v1beta1/clusterRoles?sync=true", `{"id": "reader", "rules": [{"verbs": ["get", "list"], "resources": ["*"]}]}`); code != http.StatusOK {
+		t.Fatalf("unexpected status creating a cluster role: %d", code)
+	}
+	if code := do("admin", "POST", "/api/v1beta1/clusterRoleBindings?sync=true", `{"id": "alice-reader", "subjects": [{"kind": "User", "name": "alice"}], "roleRef": {"kind": "ClusterRole", "name": "reader"}}`); code != http.StatusOK {
+		t.Fatalf("unexpected status creating a cluster role binding: %d", code)
+	}
+	// The fake etcd client doesn't list the keys created under a directory.
+	fakeClient.Mutex.Lock()
+	binding := fakeClient.Data["/registry/clusterrolebindings/alice-reader"].R.Node
+	fakeClient.Data["/registry/clusterrolebindings"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Nodes: []*etcd.Node{binding}}},
+	}
+	fakeClient.Mutex.Unlock()
+	if code := do("alice", "GET", "/api/v1beta1/namespaces", ""); code != http.StatusOK {
+		t.Errorf("expected alice to be allowed to list, got %d", code)
+	}
+	if code := do("alice", "DELETE", "/api/v1beta1/namespaces/foo", ""); code != http.StatusForbidden {
+		t.Errorf("expected alice to be denied a delete, got %d", code)
+	}
+}
+
+func TestHandlerConcurrentUpdates(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	fakeClient.ExpectNotFoundGet("/registry/namespaces")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	do := func(method, path, body string) (int, []byte) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp.StatusCode, data
+	}
+
+	if code, _ := do("POST", "/api/v1beta1/clusterRoles?sync=true", `{"id": "reader", "rules": [{"verbs": ["get"], "resources": ["*"]}]}`); code != http.StatusOK {
+		t.Fatalf("unexpected status creating a cluster role: %d", code)
+	}
+	code, data := do("GET", "/api/v1beta1/clusterRoles/reader", "")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status getting a cluster role: %d", code)
+	}
+	var role v1beta1.ClusterRole
+	if err := json.Unmarshal(data, &role); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	update := func(verb string, version uint64) string {
+		return fmt.Sprintf(`{"id": "reader", "resourceVersion": %d, "rules": [{"verbs": [%q], "resources": ["*"]}]}`, version, verb)
+	}
+
+	// Two writers update the role they both read.
+	if code, _ := do("PUT", "/api/v1beta1/clusterRoles/reader?sync=true", update("list", role.ResourceVersion)); code != http.StatusOK {
+		t.Errorf("expected the first writer to succeed, got %d", code)
+	}
+	if code, _ := do("PUT", "/api/v1beta1/clusterRoles/reader?sync=true", update("watch", role.ResourceVersion)); code != http.StatusConflict {
+		t.Errorf("expected the second writer to conflict, got %d", code)
+	}
+	if code, _ := do("PUT", "/api/v1beta1/clusterRoles/reader?sync=true&resourceVersionMatch=NotOlderThan", update("watch", role.ResourceVersion)); code != http.StatusConflict {
+		t.Errorf("expected a write older than the role to conflict, got %d", code)
+	}
+	if code, _ := do("PUT", "/api/v1beta1/clusterRoles/reader?sync=true&resourceVersionMatch=NotOlderThan", update("watch", role.ResourceVersion+100)); code != http.StatusOK {
+		t.Errorf("expected a write newer than the role to succeed, got %d", code)
+	}
+}
+
+func TestHandlerAllNamespaces(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	fakeClient.ExpectNotFoundGet("/registry/namespaces")
+	fakeClient.ExpectNotFoundGet("/registry/services/specs/other")
+	fakeClient.ExpectNotFoundGet("/registry/hosts/machine/kubelet")
+	podNode := func(namespace, id string, index uint64) *etcd.Node {
+		return &etcd.Node{
+			Key:           "/registry/pods/" + namespace + "/" + id,
+			Value:         runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: id, Namespace: namespace}}),
+			ModifiedIndex: index,
+		}
+	}
+	fakeClient.Data["/registry/pods"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Dir: true,
+				Nodes: []*etcd.Node{
+					{Key: "/registry/pods/default", Dir: true, Nodes: []*etcd.Node{podNode("default", "bar", 1)}},
+					{Key: "/registry/pods/other", Dir: true, Nodes: []*etcd.Node{podNode("other", "foo", 2)}},
+				},
+			},
+		},
+	}
+	fakeClient.Data["/registry/pods/default"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{Dir: true, Nodes: []*etcd.Node{podNode("default", "bar", 1)}},
+		},
+	}
+	fakeClient.Data["/registry/pods/other/foo"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: podNode("other", "foo", 2)},
+	}
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+	})
+	defer m.Stop()
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+	c := client.NewOrDie(&client.Config{Host: server.URL, Version: "v1beta1"})
+
+	// A client without a namespace only sees the default namespace.
+	pods, err := c.ListPods(api.NewContext(), labels.Everything())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].ID != "bar" {
+		t.Errorf("expected only the default pod, got %#v", pods.Items)
+	}
+
+	// The scheduler and controllers list every namespace.
+	pods, err = c.ListPods(api.WithNamespace(api.NewContext(), api.NamespaceAll), labels.Everything())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected the pods of both namespaces, got %#v", pods.Items)
+	}
+	var other *api.Pod
+	for i := range pods.Items {
+		if pods.Items[i].Namespace == "other" {
+			other = &pods.Items[i]
+		}
+	}
+	if other == nil || other.ID != "foo" {
+		t.Fatalf("expected pod foo in namespace other, got %#v", pods.Items)
+	}
+
+	// Binding the pod in its own namespace, as the scheduler does, assigns it.
+	binding := &api.Binding{TypeMeta: api.TypeMeta{Namespace: other.Namespace}, PodID: other.ID, Host: "machine"}
+	if err := c.Post().Namespace(binding.Namespace).Path("bindings").Sync(true).Body(binding).Do().Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var bound api.Pod
+	if err := latest.Codec.DecodeInto([]byte(fakeClient.Data["/registry/pods/other/foo"].R.Node.Value), &bound); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bound.DesiredState.Host != "machine" {
+		t.Errorf("expected pod foo to be bound to machine, got %#v", bound)
+	}
+
+	// Only lists and watches may span all namespaces.
+	if err := c.Get().Namespace(api.NamespaceAllParam).Path("pods").Path("foo").Do().Error(); !errors.IsBadRequest(err) {
+		t.Errorf("expected a bad request getting a pod in all namespaces, got %v", err)
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	lockKey := "/registry" + leaderElectionKey
+	fakeClient.Data[lockKey] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Value: "other", ModifiedIndex: 1}},
+	}
+	m := New(&Config{
+		EtcdHelper:           tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:        &countingPodInfoGetter{},
+		EnableLeaderElection: true,
+		LeaderElectionTTL:    300 * time.Millisecond,
+	})
+	defer m.Stop()
+
+	select {
+	case <-m.LeaderElected():
+		t.Fatalf("unexpected leadership while another master holds the lock")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// The lock of the other master expires.
+	fakeClient.Mutex.Lock()
+	delete(fakeClient.Data, lockKey)
+	fakeClient.Mutex.Unlock()
+	select {
+	case <-m.LeaderElected():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the master to lead once the lock is free")
+	}
+}
+
+// newMasterWithoutLeadership returns a master which never leads, since another
+// master holds the leader election lock.
+func newMasterWithoutLeadership(t *testing.T, c Config) *Master {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.Data["/registry"+leaderElectionKey] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Value: "other", ModifiedIndex: 1}},
+	}
+	c.EtcdHelper = tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}}
+	c.PodInfoGetter = &countingPodInfoGetter{}
+	c.LeaderElectionTTL = time.Minute
+	return New(&c)
+}
+
+func TestLeaderElectionWithSeveralMasters(t *testing.T) {
+	m := newMasterWithoutLeadership(t, Config{MasterCount: 2})
+	defer m.Stop()
+
+	select {
+	case <-m.LeaderElected():
+		t.Fatalf("expected several masters to elect a leader, even without EnableLeaderElection")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEnableControllers(t *testing.T) {
+	countControllers := func(c Config) int {
+		c.EnableLeaderElection = true
+		m := newMasterWithoutLeadership(t, c)
+		defer m.Stop()
+		return len(m.controllers)
+	}
+	base := countControllers(Config{})
+	for name, c := range map[string]Config{
+		"daemon sets":     {EnableDaemonSetController: true},
+		"jobs":            {EnableJobController: true},
+		"gc":              {EnableGarbageCollector: true},
+		"rolling updates": {EnableRollingUpdateController: true},
+	} {
+		if e, a := base+1, countControllers(c); e != a {
+			t.Errorf("%s: expected %d controllers, got %d", name, e, a)
+		}
+	}
+}
+
+func TestLeaderElectionDisabled(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+	})
+	defer m.Stop()
+
+	select {
+	case <-m.LeaderElected():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the master to lead without leader election")
+	}
+}
+
+func TestFilterUnhealthyEndpoints(t *testing.T) {
+	for _, filter := range []bool{false, true} {
+		endpoints := registrytest.NewServiceRegistry()
+		endpoints.Endpoints = api.Endpoints{
+			TypeMeta:  api.TypeMeta{ID: "foo"},
+			Endpoints: []string{"10.0.0.1:80"},
+		}
+		m := &Master{
+			podRegistry: registrytest.NewPodRegistry(&api.PodList{
+				Items: []api.Pod{
+					{
+						TypeMeta:     api.TypeMeta{ID: "foo"},
+						DesiredState: api.PodState{Manifest: api.ContainerManifest{Containers: []api.Container{{Name: "web"}}}},
+						CurrentState: api.PodState{Host: "machine", PodIP: "10.0.0.1"},
+					},
+				},
+			}),
+			endpointRegistry: endpoints,
+			filterEndpoints:  filter,
+			stop:             make(chan struct{}),
+		}
+		// The pod cache never learns of a running container.
+		m.init(nil, &countingPodInfoGetter{}, time.Hour)
+		obj, err := m.storage["endpoints"].Get(api.NewDefaultContext(), "foo")
+		m.Stop()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if filtered := len(obj.(*api.Endpoints).Endpoints) == 0; filtered != filter {
+			t.Errorf("filter %v: unexpected endpoints %v", filter, obj.(*api.Endpoints).Endpoints)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package master
 
 import (
 	"sync"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
@@ -34,7 +35,30 @@ type PodCache struct {
 	pods          pod.Registry
 	// This is a map of pod id to a map of container name to the
 	podInfo map[string]api.PodInfo
-	podLock sync.Mutex
+	// The time at which each entry of podInfo was last updated, by the same key.
+	updated map[string]time.Time
+	// The time at which pod information was last fetched successfully from
+	// each minion.
+	minionUpdated map[string]time.Time
+	// The number of times fetching pod information failed.
+	errorCount int
+	// The time at which the last full sync finished.
+	lastSync time.Time
+	// Guards all of the above. It is only held for single map accesses, never
+	// while pod information is fetched, and readers do not block each other.
+	podLock sync.RWMutex
+	now     func() time.Time
+}
+
+// PodCacheStats describes how fresh the information in a PodCache is.
+type PodCacheStats struct {
+	// The time at which pod information was last fetched successfully from
+	// each minion.
+	MinionLastUpdate map[string]time.Time `json:"minionLastUpdate"`
+	// The number of times fetching pod information failed.
+	ErrorCount int `json:"errorCount"`
+	// How long ago the least recently updated entry was updated.
+	OldestEntryAge time.Duration `json:"oldestEntryAge"`
 }
 
 // NewPodCache returns a new PodCache which watches container information registered in the given PodRegistry.
@@ -43,6 +67,9 @@ func NewPodCache(info client.PodInfoGetter, pods pod.Registry) *PodCache {
 		containerInfo: info,
 		pods:          pods,
 		podInfo:       map[string]api.PodInfo{},
+		updated:       map[string]time.Time{},
+		minionUpdated: map[string]time.Time{},
+		now:           time.Now,
 	}
 }
 
@@ -55,8 +82,8 @@ func makePodCacheKey(podNamespace, podID string) string {
 // The returned value should be treated as read-only.
 // TODO: Remove the host from this call, it's totally unnecessary.
 func (p *PodCache) GetPodInfo(host, podNamespace, podID string) (api.PodInfo, error) {
-	p.podLock.Lock()
-	defer p.podLock.Unlock()
+	p.podLock.RLock()
+	defer p.podLock.RUnlock()
 	value, ok := p.podInfo[makePodCacheKey(podNamespace, podID)]
 	if !ok {
 		return nil, client.ErrPodInfoNotAvailable
@@ -64,14 +91,62 @@ func (p *PodCache) GetPodInfo(host, podNamespace, podID string) (api.PodInfo, er
 	return value, nil
 }
 
+// LastSync returns the time at which UpdateAllContainers last finished, or the
+// zero time if it never has.
+func (p *PodCache) LastSync() time.Time {
+	p.podLock.RLock()
+	defer p.podLock.RUnlock()
+	return p.lastSync
+}
+
+// PodInfoAge returns how long ago the cached information of a pod was
+// updated, or false if there is none.
+func (p *PodCache) PodInfoAge(podNamespace, podID string) (time.Duration, bool) {
+	p.podLock.RLock()
+	defer p.podLock.RUnlock()
+	updated, ok := p.updated[makePodCacheKey(podNamespace, podID)]
+	if !ok {
+		return 0, false
+	}
+	return p.now().Sub(updated), true
+}
+
+// Stats returns how fresh the information in the cache is.
+func (p *PodCache) Stats() PodCacheStats {
+	p.podLock.RLock()
+	defer p.podLock.RUnlock()
+	now := p.now()
+	stats := PodCacheStats{
+		MinionLastUpdate: map[string]time.Time{},
+		ErrorCount:       p.errorCount,
+	}
+	for host, updated := range p.minionUpdated {
+		stats.MinionLastUpdate[host] = updated
+	}
+	for _, updated := range p.updated {
+		if age := now.Sub(updated); age > stats.OldestEntryAge {
+			stats.OldestEntryAge = age
+		}
+	}
+	return stats
+}
+
 func (p *PodCache) updatePodInfo(host, podNamespace, podID string) error {
 	info, err := p.containerInfo.GetPodInfo(host, podNamespace, podID)
-	if err != nil {
+	if err == client.ErrPodInfoNotAvailable {
 		return err
 	}
 	p.podLock.Lock()
 	defer p.podLock.Unlock()
-	p.podInfo[makePodCacheKey(podNamespace, podID)] = info
+	if err != nil {
+		p.errorCount++
+		return err
+	}
+	now := p.now()
+	key := makePodCacheKey(podNamespace, podID)
+	p.podInfo[key] = info
+	p.updated[key] = now
+	p.minionUpdated[host] = now
 	return nil
 }
 
@@ -83,7 +158,9 @@ func (p *PodCache) UpdateAllContainers() {
 		glog.Errorf("Error synchronizing container list: %v", err)
 		return
 	}
+	listed := map[string]bool{}
 	for _, pod := range pods.Items {
+		listed[makePodCacheKey(pod.Namespace, pod.ID)] = true
 		if pod.CurrentState.Host == "" {
 			continue
 		}
@@ -92,4 +169,15 @@ func (p *PodCache) UpdateAllContainers() {
 			glog.Errorf("Error synchronizing container: %v", err)
 		}
 	}
+	p.podLock.Lock()
+	defer p.podLock.Unlock()
+	// Forget the pods which are gone, so that their entries don't count as
+	// stale forever.
+	for key := range p.podInfo {
+		if !listed[key] {
+			delete(p.podInfo, key)
+			delete(p.updated, key)
+		}
+	}
+	p.lastSync = p.now()
 }
@@ -17,8 +17,12 @@ limitations under the License.
 package master
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
@@ -155,3 +159,117 @@ func TestPodUpdateAllContainers(t *testing.T) {
 		t.Errorf("Unexpected mismatch. Expected: %#v, Got: #%v", &expected, info)
 	}
 }
+
+// unreachablePodInfoGetter fails to get the info of the pods on its hosts.
+type unreachablePodInfoGetter struct {
+	hosts map[string]bool
+}
+
+func (u unreachablePodInfoGetter) GetPodInfo(host, namespace, id string) (api.PodInfo, error) {
+	if u.hosts[host] {
+		return nil, errors.New("unreachable")
+	}
+	return api.PodInfo{"foo": api.ContainerStatus{}}, nil
+}
+
+func TestPodCacheStats(t *testing.T) {
+	pods := []api.Pod{
+		{
+			TypeMeta:     api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+			CurrentState: api.PodState{Host: "machine1"},
+		},
+		{
+			TypeMeta:     api.TypeMeta{ID: "bar", Namespace: api.NamespaceDefault},
+			CurrentState: api.PodState{Host: "machine2"},
+		},
+	}
+	mockRegistry := registrytest.NewPodRegistry(&api.PodList{Items: pods})
+	getter := unreachablePodInfoGetter{hosts: map[string]bool{}}
+	cache := NewPodCache(getter, mockRegistry)
+	start := time.Unix(1000, 0)
+	now := start
+	cache.now = func() time.Time { return now }
+
+	cache.UpdateAllContainers()
+	stats := cache.Stats()
+	expected := map[string]time.Time{"machine1": start, "machine2": start}
+	if !reflect.DeepEqual(stats.MinionLastUpdate, expected) || stats.ErrorCount != 0 || stats.OldestEntryAge != 0 {
+		t.Errorf("unexpected stats %#v", stats)
+	}
+
+	// machine2 stops answering, so its pod is no longer updated.
+	getter.hosts["machine2"] = true
+	now = start.Add(time.Minute)
+	cache.UpdateAllContainers()
+	cache.UpdateAllContainers()
+	stats = cache.Stats()
+	expected = map[string]time.Time{"machine1": now, "machine2": start}
+	if !reflect.DeepEqual(stats.MinionLastUpdate, expected) {
+		t.Errorf("expected minion updates %v, got %v", expected, stats.MinionLastUpdate)
+	}
+	if stats.ErrorCount != 2 {
+		t.Errorf("expected 2 errors, got %d", stats.ErrorCount)
+	}
+	if stats.OldestEntryAge != time.Minute {
+		t.Errorf("expected the oldest entry to be a minute old, got %v", stats.OldestEntryAge)
+	}
+	if age, ok := cache.PodInfoAge(api.NamespaceDefault, "bar"); !ok || age != time.Minute {
+		t.Errorf("expected bar to be a minute old, got %v %v", age, ok)
+	}
+	if age, ok := cache.PodInfoAge(api.NamespaceDefault, "foo"); !ok || age != 0 {
+		t.Errorf("expected foo to be fresh, got %v %v", age, ok)
+	}
+
+	// Once bar is deleted, its entry no longer counts as stale.
+	mockRegistry.Pods.Items = pods[:1]
+	cache.UpdateAllContainers()
+	if _, ok := cache.PodInfoAge(api.NamespaceDefault, "bar"); ok {
+		t.Errorf("expected the entry of a deleted pod to be forgotten")
+	}
+	if stats := cache.Stats(); stats.OldestEntryAge != 0 {
+		t.Errorf("expected no stale entries, got %v", stats.OldestEntryAge)
+	}
+}
+
+type staticPodInfoGetter struct {
+	data api.PodInfo
+}
+
+func (s staticPodInfoGetter) GetPodInfo(host, namespace, id string) (api.PodInfo, error) {
+	return s.data, nil
+}
+
+// BenchmarkPodCacheContention measures 100 concurrent reads racing 10 concurrent
+// full cache updates.
+func BenchmarkPodCacheContention(b *testing.B) {
+	pods := []api.Pod{}
+	for i := 0; i < 100; i++ {
+		pods = append(pods, api.Pod{
+			TypeMeta:     api.TypeMeta{ID: fmt.Sprintf("pod%d", i), Namespace: api.NamespaceDefault},
+			CurrentState: api.PodState{Host: "machine"},
+		})
+	}
+	mockRegistry := registrytest.NewPodRegistry(&api.PodList{Items: pods})
+	cache := NewPodCache(staticPodInfoGetter{api.PodInfo{"foo": api.ContainerStatus{}}}, mockRegistry)
+	cache.UpdateAllContainers()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg := sync.WaitGroup{}
+		for u := 0; u < 10; u++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cache.UpdateAllContainers()
+			}()
+		}
+		for r := 0; r < 100; r++ {
+			wg.Add(1)
+			go func(r int) {
+				defer wg.Done()
+				cache.GetPodInfo("machine", api.NamespaceDefault, fmt.Sprintf("pod%d", r))
+			}(r)
+		}
+		wg.Wait()
+	}
+}
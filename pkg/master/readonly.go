@@ -0,0 +1,76 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/golang/glog"
+)
+
+// readOnlyHiddenResources are the resources which are not served on the
+// read-only port, because reading them gives away credentials.
+var readOnlyHiddenResources = util.NewStringSet("secrets")
+
+// readOnlyPaths are the paths outside the API which are served on the
+// read-only port. Others, such as /proxy/minion/ and /logs/, are refused.
+var readOnlyPaths = util.NewStringSet("/healthz", "/version")
+
+// ReadOnlyHandler returns the handlers of the master restricted to reading,
+// as served on the read-only port; see apiserver.ReadOnly. Requests are
+// neither authenticated nor authorized, since they have no user which an
+// authorizer could allow. Instead, the port only serves what internal
+// components such as the scheduler need to read, and callers rely on it being
+// bound to a local address.
+func (m *Master) ReadOnlyHandler() http.Handler {
+	return apiserver.ReadOnly(m.handlerChain(false), m.apiPrefix, m.storage, readOnlyHiddenResources, readOnlyPaths)
+}
+
+// serveReadOnly serves ReadOnlyHandler over HTTP on addr until the master is
+// stopped.
+func (m *Master) serveReadOnly(addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		glog.Errorf("Unable to listen on the read-only address %s: %v", addr, err)
+		return
+	}
+	server := &http.Server{
+		Handler:        apiserver.RecoverPanics(m.ReadOnlyHandler()),
+		ReadTimeout:    5 * time.Minute,
+		WriteTimeout:   5 * time.Minute,
+		MaxHeaderBytes: 1 << 20,
+	}
+	m.running.Add(2)
+	go func() {
+		defer m.running.Done()
+		<-m.stop
+		listener.Close()
+	}()
+	go func() {
+		defer m.running.Done()
+		err := server.Serve(listener)
+		select {
+		case <-m.stop:
+		default:
+			glog.Errorf("Stopped serving the read-only address %s: %v", addr, err)
+		}
+	}()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+)
+
+// freePort returns a port on 127.0.0.1 which nothing listens on.
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestReadOnlyPort(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	port := freePort(t)
+	m := New(&Config{
+		EtcdHelper:    tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter: &countingPodInfoGetter{},
+		ReadOnlyPort:  port,
+	})
+	defer m.Stop()
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/v1beta1/minions", port)
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get(url); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected GET to succeed, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(url, "application/json", bytes.NewBufferString(`{"id":"minion1"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected POST to be rejected with 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadOnlyHandler(t *testing.T) {
+	fakeClient := newFakeEtcdClient(t)
+	fakeClient.ExpectNotFoundGet("/")
+	fakeClient.ExpectNotFoundGet("/registry/pods")
+	fakeClient.ExpectNotFoundGet("/registry/minions")
+	fakeClient.ExpectNotFoundGet("/registry/daemonsets")
+	fakeClient.ExpectNotFoundGet("/registry/jobs")
+	fakeClient.ExpectNotFoundGet("/registry/controllers")
+	// Nobody is bound to a role, so RBAC would deny every request.
+	m := New(&Config{
+		EtcdHelper:        tools.EtcdHelper{fakeClient, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "", tools.WatchConfig{}},
+		PodInfoGetter:     &client.HTTPPodInfoGetter{Client: http.DefaultClient, Port: 10250},
+		AuthorizationMode: AuthorizationModeRBAC,
+	})
+	defer m.Stop()
+	handler := m.ReadOnlyHandler()
+
+	table := map[string]int{
+		"/api/v1beta1/minions":          http.StatusOK,
+		"/api/v1beta1/secrets":          http.StatusForbidden,
+		"/api/v1beta1/pods/foo/exec":    http.StatusForbidden,
+		"/api/v1beta1/proxy/minions/m1": http.StatusForbidden,
+		"/healthz":                      http.StatusOK,
+		"/version":                      http.StatusOK,
+		"/proxy/minion/m1/healthz":      http.StatusForbidden,
+		"/logs/":                        http.StatusForbidden,
+		"/podCacheStatus":               http.StatusForbidden,
+	}
+	for path, expected := range table {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Errorf("%s: expected %d, got %d: %s", path, expected, w.Code, w.Body.String())
+		}
+	}
+}
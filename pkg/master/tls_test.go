@@ -0,0 +1,167 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package master
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a certificate and key issued for tests.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert issues a certificate for name, signed by parent or self-signed if
+// parent is nil.
+func newTestCert(t *testing.T, name string, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &testCert{cert, key, der}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func (c *testCert) writePEM(t *testing.T, path string) {
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewTLSConfigClientCerts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", 1, nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	ca.writePEM(t, caFile)
+	serverCert := newTestCert(t, "server", 2, ca)
+	clientCert := newTestCert(t, "client", 3, ca)
+	otherClientCert := newTestCert(t, "other", 4, newTestCert(t, "other-ca", 5, nil))
+
+	config, err := NewTLSConfig(caFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.MinVersion != tls.VersionTLS12 || config.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("unexpected TLS config: %#v", config)
+	}
+	config.Certificates = []tls.Certificate{serverCert.tlsCertificate()}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = config
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+		}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(clientCert.tlsCertificate()); err != nil {
+		t.Errorf("expected a client with a valid certificate to be accepted, got %v", err)
+	}
+	if err := get(); err == nil {
+		t.Errorf("expected a client without a certificate to be rejected")
+	}
+	if err := get(otherClientCert.tlsCertificate()); err == nil {
+		t.Errorf("expected a client with a certificate from another CA to be rejected")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	config, err := NewTLSConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.ClientAuth != tls.NoClientCert || config.ClientCAs != nil {
+		t.Errorf("expected no client certificates to be required: %#v", config)
+	}
+	for _, suite := range config.CipherSuites {
+		if suite == tls.TLS_RSA_WITH_RC4_128_SHA || suite == tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA || suite == tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA {
+			t.Errorf("unexpected RC4 cipher suite %x", suite)
+		}
+	}
+
+	if _, err := NewTLSConfig("/does/not/exist"); err == nil {
+		t.Errorf("expected an error for a missing CA file")
+	}
+}
+
+func TestListenAndServeTLSRequiresCert(t *testing.T) {
+	m := &Master{}
+	if err := m.ListenAndServeTLS("127.0.0.1:0"); err == nil {
+		t.Errorf("expected an error without a certificate and key")
+	}
+}
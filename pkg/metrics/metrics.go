@@ -0,0 +1,327 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of a
+// histogram of request latencies.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	// RequestCounter counts the requests served by the apiserver.
+	RequestCounter = NewCounterVec("apiserver_request_total",
+		"Counter of apiserver requests broken out by verb, resource and HTTP response code.",
+		"verb", "resource", "code")
+	// RequestLatencies observes how long the apiserver takes to serve requests.
+	RequestLatencies = NewHistogramVec("apiserver_request_duration_seconds",
+		"Response latency distribution in seconds for each verb and resource.",
+		DefaultBuckets, "verb", "resource")
+	// ObjectCounts holds the number of objects stored in etcd the last time
+	// they were counted.
+	ObjectCounts = NewGaugeVec("apiserver_etcd_object_counts",
+		"Number of stored objects at the time of last check split by resource.",
+		"resource")
+	// NoopUpdates counts the updates which were not written to etcd because
+	// they would not have changed the stored object.
+	NoopUpdates = NewCounterVec("apiserver_noop_updates_total",
+		"Counter of updates skipped because they would not change the stored object, split by resource.",
+		"resource")
+	// WatchEventsDropped counts the watch events which waited longer than the
+	// slow consumer threshold of their watch to be received.
+	WatchEventsDropped = NewCounterVec("watchevents_dropped_total",
+		"Counter of watch events whose consumer fell behind, split by resource.",
+		"resource")
+)
+
+var (
+	registryLock sync.Mutex
+	registry     = []metric{}
+)
+
+func init() {
+	Register(RequestCounter)
+	Register(RequestLatencies)
+	Register(ObjectCounts)
+	Register(NoopUpdates)
+	Register(WatchEventsDropped)
+}
+
+// metric is a family of samples which can be exposed.
+type metric interface {
+	// write writes the samples of the metric in the text exposition format.
+	write(w io.Writer)
+}
+
+// Register adds m to the metrics served by Handler.
+func Register(m metric) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry = append(registry, m)
+}
+
+// Handler returns an http Handler which serves every registered metric in the
+// Prometheus text exposition format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		for _, m := range registry {
+			m.write(w)
+		}
+	})
+}
+
+// knownVerbs are the verbs requests are recorded under. Requests with any other
+// method are recorded under otherVerb, so that clients cannot add series by
+// sending made up methods.
+var knownVerbs = map[string]bool{
+	"LIST": true, "WATCH": true, "GET": true, "HEAD": true, "POST": true,
+	"PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+const otherVerb = "OTHER"
+
+// Monitor records that a request to verb resource was answered with code
+// after elapsed.
+func Monitor(verb, resource string, code int, elapsed time.Duration) {
+	if !knownVerbs[verb] {
+		verb = otherVerb
+	}
+	RequestCounter.Inc(verb, resource, strconv.Itoa(code))
+	RequestLatencies.Observe(elapsed.Seconds(), verb, resource)
+}
+
+// vec holds the samples of a metric for each combination of label values.
+type vec struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	lock   sync.Mutex
+	values map[string][]string
+}
+
+func newVec(name, help, kind string, labels []string) vec {
+	return vec{name: name, help: help, kind: kind, labels: labels, values: map[string][]string{}}
+}
+
+// key returns the key of labelValues, remembering them for write. The caller
+// must hold the lock.
+func (v *vec) key(labelValues []string) string {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("%s: expected %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := v.values[key]; !ok {
+		v.values[key] = append([]string{}, labelValues...)
+	}
+	return key
+}
+
+// sortedKeys returns the keys of every combination of label values seen,
+// in order. The caller must hold the lock.
+func (v *vec) sortedKeys() []string {
+	keys := []string{}
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *vec) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.kind)
+}
+
+// formatLabels renders the labels of key, followed by extra which is a
+// rendered label itself, in braces.
+func (v *vec) formatLabels(key string, extra string) string {
+	pairs := []string{}
+	for i, value := range v.values[key] {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", v.labels[i], strconv.Quote(value)))
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// CounterVec is a metric whose values only go up.
+type CounterVec struct {
+	vec
+	counts map[string]float64
+}
+
+// NewCounterVec returns a counter named name which is partitioned by labels.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, "counter", labels), map[string]float64{}}
+}
+
+// Inc adds one to the counter of labelValues.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter of labelValues.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("%s: counters cannot decrease", c.name))
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts[c.key(labelValues)] += delta
+}
+
+// Value returns the counter of labelValues.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.counts[strings.Join(labelValues, "\xff")]
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writeHeader(w)
+	for _, key := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.formatLabels(key, ""), formatValue(c.counts[key]))
+	}
+}
+
+// GaugeVec is a metric whose values are set to the current state.
+type GaugeVec struct {
+	vec
+	gauges map[string]float64
+}
+
+// NewGaugeVec returns a gauge named name which is partitioned by labels.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{newVec(name, help, "gauge", labels), map[string]float64{}}
+}
+
+// Set sets the gauge of labelValues to value.
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.gauges[g.key(labelValues)] = value
+}
+
+// Value returns the gauge of labelValues.
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.gauges[strings.Join(labelValues, "\xff")]
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.writeHeader(w)
+	for _, key := range g.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.formatLabels(key, ""), formatValue(g.gauges[key]))
+	}
+}
+
+// histogram counts the observations of one combination of label values.
+type histogram struct {
+	// buckets holds the number of observations at most each upper bound.
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// HistogramVec is a metric which counts observations in buckets.
+type HistogramVec struct {
+	vec
+	upperBounds []float64
+	histograms  map[string]*histogram
+}
+
+// NewHistogramVec returns a histogram named name which is partitioned by
+// labels and counts observations in buckets with the given upper bounds, in
+// increasing order.
+func NewHistogramVec(name, help string, upperBounds []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{newVec(name, help, "histogram", labels), upperBounds, map[string]*histogram{}}
+}
+
+// Observe adds value to the histogram of labelValues.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	key := h.key(labelValues)
+	hist, ok := h.histograms[key]
+	if !ok {
+		hist = &histogram{buckets: make([]uint64, len(h.upperBounds))}
+		h.histograms[key] = hist
+	}
+	for i, bound := range h.upperBounds {
+		if value <= bound {
+			hist.buckets[i]++
+		}
+	}
+	hist.count++
+	hist.sum += value
+}
+
+// Count returns the number of observations in the histogram of labelValues.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if hist, ok := h.histograms[strings.Join(labelValues, "\xff")]; ok {
+		return hist.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.writeHeader(w)
+	for _, key := range h.sortedKeys() {
+		hist := h.histograms[key]
+		for i, bound := range h.upperBounds {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.formatLabels(key, fmt.Sprintf("le=%q", formatValue(bound))), hist.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.formatLabels(key, `le="+Inf"`), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.formatLabels(key, ""), formatValue(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.formatLabels(key, ""), hist.count)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounterVec(t *testing.T) {
+	c := NewCounterVec("test_total", "A test counter.", "verb")
+	c.Inc("GET")
+	c.Add(2, "GET")
+	c.Inc("PUT")
+	if e, a := 3.0, c.Value("GET"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := 0.0, c.Value("DELETE"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	h := NewHistogramVec("test_seconds", "A test histogram.", []float64{1, 2}, "verb")
+	h.Observe(0.5, "GET")
+	h.Observe(1.5, "GET")
+	h.Observe(3, "GET")
+	if e, a := uint64(3), h.Count("GET"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	hist := h.histograms["GET"]
+	if hist.buckets[0] != 1 || hist.buckets[1] != 2 || hist.sum != 5 {
+		t.Errorf("unexpected histogram %#v", hist)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	Monitor("GET", "monitored", 200, time.Second)
+	Monitor("GET", "monitored", 404, time.Second)
+	if e, a := 1.0, RequestCounter.Value("GET", "monitored", "404"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := uint64(2), RequestLatencies.Count("GET", "monitored"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	Monitor("FROBNICATE", "monitored", 405, time.Second)
+	if e, a := 0.0, RequestCounter.Value("FROBNICATE", "monitored", "405"); e != a {
+		t.Errorf("expected unknown verbs not to be recorded, got %v", a)
+	}
+	if e, a := 1.0, RequestCounter.Value("OTHER", "monitored", "405"); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	RequestCounter.Inc("LIST", "handled", "200")
+	RequestLatencies.Observe(0.2, "LIST", "handled")
+	ObjectCounts.Set(4, "handled")
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, &http.Request{})
+	if w.Code != http.StatusOK {
+		t.Errorf("unexpected code %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, expected := range []string{
+		"# TYPE apiserver_request_total counter\n",
+		`apiserver_request_total{verb="LIST",resource="handled",code="200"} 1` + "\n",
+		"# TYPE apiserver_request_duration_seconds histogram\n",
+		`apiserver_request_duration_seconds_bucket{verb="LIST",resource="handled",le="0.1"} 0` + "\n",
+		`apiserver_request_duration_seconds_bucket{verb="LIST",resource="handled",le="0.25"} 1` + "\n",
+		`apiserver_request_duration_seconds_bucket{verb="LIST",resource="handled",le="+Inf"} 1` + "\n",
+		`apiserver_request_duration_seconds_sum{verb="LIST",resource="handled"} 0.2` + "\n",
+		`apiserver_request_duration_seconds_count{verb="LIST",resource="handled"} 1` + "\n",
+		"# TYPE apiserver_etcd_object_counts gauge\n",
+		`apiserver_etcd_object_counts{resource="handled"} 4` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in:\n%s", expected, body)
+		}
+	}
+}
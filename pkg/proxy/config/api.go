@@ -72,7 +72,7 @@ func NewSourceAPI(client Watcher, period time.Duration, services chan<- ServiceU
 
 // runServices loops forever looking for changes to services.
 func (s *SourceAPI) runServices(resourceVersion *string) {
-	ctx := api.NewContext()
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceAll)
 	if len(*resourceVersion) == 0 {
 		services, err := s.client.ListServices(ctx, labels.Everything())
 		if err != nil {
@@ -122,7 +122,7 @@ func handleServicesWatch(resourceVersion *string, ch <-chan watch.Event, updates
 
 // runEndpoints loops forever looking for changes to endpoints.
 func (s *SourceAPI) runEndpoints(resourceVersion *string) {
-	ctx := api.NewContext()
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceAll)
 	if len(*resourceVersion) == 0 {
 		endpoints, err := s.client.ListEndpoints(ctx, labels.Everything())
 		if err != nil {
@@ -16,16 +16,17 @@ limitations under the License.
 
 // Watches etcd and gets the full configuration on preset intervals.
 // It expects the list of exposed services to live under:
-// registry/services
+// <prefix>/services
+// where <prefix> is the -etcd_prefix of the apiserver, /registry by default,
 // which in etcd is exposed like so:
 // http://<etcd server>/v2/keys/registry/services
 //
 // The port that proxy needs to listen in for each service is a value in:
-// registry/services/<service>
+// <prefix>/services/specs/<namespace>/<service>
 //
 // The endpoints for each of the services found is a json string
 // representing that service at:
-// /registry/services/<service>/endpoint
+// <prefix>/services/endpoints/<namespace>/<service>
 // and the format is:
 // '[ { "machine": <host>, "name": <name", "port": <port> },
 //    { "machine": <host2>, "name": <name2", "port": <port2> }
@@ -35,6 +36,7 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
@@ -46,21 +48,21 @@ import (
 	"github.com/golang/glog"
 )
 
-// registryRoot is the key prefix for service configs in etcd.
-const registryRoot = "registry/services"
-
 // ConfigSourceEtcd communicates with a etcd via the client, and sends the change notification of services and endpoints to the specified channels.
 type ConfigSourceEtcd struct {
 	client           *etcd.Client
+	prefix           string
 	serviceChannel   chan ServiceUpdate
 	endpointsChannel chan EndpointsUpdate
 	interval         time.Duration
 }
 
 // NewConfigSourceEtcd creates a new ConfigSourceEtcd and immediately runs the created ConfigSourceEtcd in a goroutine.
-func NewConfigSourceEtcd(client *etcd.Client, serviceChannel chan ServiceUpdate, endpointsChannel chan EndpointsUpdate) ConfigSourceEtcd {
+// prefix is the key under which the apiserver stores every object in etcd.
+func NewConfigSourceEtcd(client *etcd.Client, prefix string, serviceChannel chan ServiceUpdate, endpointsChannel chan EndpointsUpdate) ConfigSourceEtcd {
 	config := ConfigSourceEtcd{
 		client:           client,
+		prefix:           prefix,
 		serviceChannel:   serviceChannel,
 		endpointsChannel: endpointsChannel,
 		interval:         2 * time.Second,
@@ -115,25 +117,36 @@ func (s ConfigSourceEtcd) Run() {
 	}
 }
 
+// servicesKey returns the key services and their endpoints are stored under.
+func (s ConfigSourceEtcd) servicesKey() string {
+	return path.Join("/", s.prefix, "services")
+}
+
 // GetServices finds the list of services and their endpoints from etcd.
 // This operation is akin to a set a known good at regular intervals.
 func (s ConfigSourceEtcd) GetServices() ([]api.Service, []api.Endpoints, error) {
-	response, err := s.client.Get(registryRoot+"/specs", true, false)
+	key := path.Join(s.servicesKey(), "specs")
+	response, err := s.client.Get(key, true, true)
 	if err != nil {
 		if tools.IsEtcdNotFound(err) {
-			glog.V(4).Infof("Failed to get the key %s: %v", registryRoot, err)
+			glog.V(4).Infof("Failed to get the key %s: %v", key, err)
 		} else {
-			glog.Errorf("Failed to contact etcd for key %s: %v", registryRoot, err)
+			glog.Errorf("Failed to contact etcd for key %s: %v", key, err)
 		}
 		return []api.Service{}, []api.Endpoints{}, err
 	}
 	if response.Node.Dir == true {
-		retServices := make([]api.Service, len(response.Node.Nodes))
-		retEndpoints := make([]api.Endpoints, len(response.Node.Nodes))
+		// Services are stored in a directory per namespace.
+		nodes := []*etcd.Node{}
+		for _, namespace := range response.Node.Nodes {
+			nodes = append(nodes, namespace.Nodes...)
+		}
+		retServices := make([]api.Service, len(nodes))
+		retEndpoints := make([]api.Endpoints, len(nodes))
 		// Ok, so we have directories, this list should be the list
 		// of services. Find the local port to listen on and remote endpoints
 		// and create a Service entry for it.
-		for i, node := range response.Node.Nodes {
+		for i, node := range nodes {
 			var svc api.Service
 			err = latest.Codec.DecodeInto([]byte(node.Value), &svc)
 			if err != nil {
@@ -141,7 +154,7 @@ func (s ConfigSourceEtcd) GetServices() ([]api.Service, []api.Endpoints, error)
 				continue
 			}
 			retServices[i] = svc
-			endpoints, err := s.GetEndpoints(svc.ID)
+			endpoints, err := s.GetEndpoints(svc.Namespace, svc.ID)
 			if err != nil {
 				if tools.IsEtcdNotFound(err) {
 					glog.V(4).Infof("Unable to get endpoints for %s : %v", svc.ID, err)
@@ -155,12 +168,12 @@ func (s ConfigSourceEtcd) GetServices() ([]api.Service, []api.Endpoints, error)
 		}
 		return retServices, retEndpoints, err
 	}
-	return nil, nil, fmt.Errorf("did not get the root of the registry %s", registryRoot)
+	return nil, nil, fmt.Errorf("did not get the root of the registry %s", key)
 }
 
-// GetEndpoints finds the list of endpoints of the service from etcd.
-func (s ConfigSourceEtcd) GetEndpoints(service string) (api.Endpoints, error) {
-	key := fmt.Sprintf(registryRoot + "/endpoints/" + service)
+// GetEndpoints finds the list of endpoints of the service in namespace from etcd.
+func (s ConfigSourceEtcd) GetEndpoints(namespace, service string) (api.Endpoints, error) {
+	key := path.Join(s.servicesKey(), "endpoints", namespace, service)
 	response, err := s.client.Get(key, true, false)
 	if err != nil {
 		glog.Errorf("Failed to get the key: %s %v", key, err)
@@ -188,7 +201,7 @@ func etcdResponseToService(response *etcd.Response) (*api.Service, error) {
 func (s ConfigSourceEtcd) WatchForChanges() {
 	glog.V(4).Info("Setting up a watch for new services")
 	watchChannel := make(chan *etcd.Response)
-	go s.client.Watch("/registry/services/", 0, true, watchChannel, nil)
+	go s.client.Watch(s.servicesKey()+"/", 0, true, watchChannel, nil)
 	for {
 		watchResponse, ok := <-watchChannel
 		if !ok {
@@ -218,10 +231,10 @@ func (s ConfigSourceEtcd) ProcessChange(response *etcd.Response) {
 		return
 	}
 	if response.Action == "delete" {
-		parts := strings.Split(response.Node.Key[1:], "/")
-		if len(parts) == 4 {
-			glog.V(4).Infof("Deleting service: %s", parts[3])
-			serviceUpdate := ServiceUpdate{Op: REMOVE, Services: []api.Service{{TypeMeta: api.TypeMeta{ID: parts[3]}}}}
+		parts := strings.Split(strings.TrimPrefix(response.Node.Key, path.Join(s.servicesKey(), "specs")+"/"), "/")
+		if len(parts) == 2 {
+			glog.V(4).Infof("Deleting service: %s/%s", parts[0], parts[1])
+			serviceUpdate := ServiceUpdate{Op: REMOVE, Services: []api.Service{{TypeMeta: api.TypeMeta{ID: parts[1], Namespace: parts[0]}}}}
 			s.serviceChannel <- serviceUpdate
 			return
 		}
@@ -0,0 +1,103 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentstatus
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// Registry holds the status of each component as of its last health check.
+// Statuses are not persisted; they are rebuilt by the checks of every master.
+type Registry struct {
+	lock     sync.Mutex
+	statuses map[string]api.ComponentStatus
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		statuses: map[string]api.ComponentStatus{},
+	}
+}
+
+// Add registers the component name, whose health is unknown until it is
+// first set.
+func (r *Registry) Add(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.statuses[name]; ok {
+		return
+	}
+	r.statuses[name] = api.ComponentStatus{
+		TypeMeta: api.TypeMeta{ID: name},
+		Conditions: []api.ComponentCondition{
+			{Type: api.ComponentHealthy, Status: api.ConditionUnknown},
+		},
+	}
+}
+
+// SetHealth records the result of a health check of the component name, which
+// passed if err is nil.
+func (r *Registry) SetHealth(name string, err error) {
+	condition := api.ComponentCondition{
+		Type:          api.ComponentHealthy,
+		Status:        api.ConditionTrue,
+		LastProbeTime: util.Now(),
+	}
+	if err != nil {
+		condition.Status = api.ConditionFalse
+		condition.Error = err.Error()
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.statuses[name] = api.ComponentStatus{
+		TypeMeta:   api.TypeMeta{ID: name},
+		Conditions: []api.ComponentCondition{condition},
+	}
+}
+
+// GetComponentStatus returns the status of the component name.
+func (r *Registry) GetComponentStatus(name string) (*api.ComponentStatus, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	status, ok := r.statuses[name]
+	if !ok {
+		return nil, errors.NewNotFound("componentStatus", name)
+	}
+	return &status, nil
+}
+
+// ListComponentStatuses returns the status of every component, by name.
+func (r *Registry) ListComponentStatuses() *api.ComponentStatusList {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	names := make([]string, 0, len(r.statuses))
+	for name := range r.statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := &api.ComponentStatusList{Items: make([]api.ComponentStatus, 0, len(names))}
+	for _, name := range names {
+		list.Items = append(list.Items, r.statuses[name])
+	}
+	return list
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentstatus
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// REST serves the statuses of a Registry. They are only set by health checks,
+// so they cannot be changed through it.
+type REST struct {
+	registry *Registry
+}
+
+// NewREST returns a new REST.
+func NewREST(registry *Registry) *REST {
+	return &REST{
+		registry: registry,
+	}
+}
+
+var ErrReadOnly = fmt.Errorf("Component statuses can only be read.")
+
+func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrReadOnly
+}
+
+func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrReadOnly
+}
+
+func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	return nil, ErrReadOnly
+}
+
+func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return rs.registry.GetComponentStatus(id)
+}
+
+func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	if !label.Empty() || !field.Empty() {
+		return nil, fmt.Errorf("label and field selectors are not supported on component statuses")
+	}
+	return rs.registry.ListComponentStatuses(), nil
+}
+
+func (rs *REST) New() runtime.Object {
+	return &api.ComponentStatus{}
+}
+
+// NamespaceScoped returns false, as components are not in a namespace.
+func (rs *REST) NamespaceScoped() bool {
+	return false
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentstatus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
+
+func healthyStatus(t *testing.T, rs *REST, name string) api.ConditionStatus {
+	obj, err := rs.Get(api.NewContext(), name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conditions := obj.(*api.ComponentStatus).Conditions
+	if len(conditions) != 1 || conditions[0].Type != api.ComponentHealthy {
+		t.Fatalf("unexpected conditions %#v", conditions)
+	}
+	return conditions[0].Status
+}
+
+func TestRESTGet(t *testing.T) {
+	registry := NewRegistry()
+	rs := NewREST(registry)
+
+	registry.Add("etcd")
+	if status := healthyStatus(t, rs, "etcd"); status != api.ConditionUnknown {
+		t.Errorf("expected an unchecked component to be of unknown health, got %s", status)
+	}
+	registry.SetHealth("etcd", nil)
+	if status := healthyStatus(t, rs, "etcd"); status != api.ConditionTrue {
+		t.Errorf("expected a healthy component, got %s", status)
+	}
+	registry.SetHealth("etcd", fmt.Errorf("connection refused"))
+	obj, err := rs.Get(api.NewContext(), "etcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	condition := obj.(*api.ComponentStatus).Conditions[0]
+	if condition.Status != api.ConditionFalse || condition.Error != "connection refused" || condition.LastProbeTime.IsZero() {
+		t.Errorf("unexpected condition %#v", condition)
+	}
+	registry.Add("etcd")
+	if status := healthyStatus(t, rs, "etcd"); status != api.ConditionFalse {
+		t.Errorf("expected adding a component again to keep its health, got %s", status)
+	}
+
+	if _, err := rs.Get(api.NewContext(), "scheduler"); !errors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	registry := NewRegistry()
+	rs := NewREST(registry)
+	registry.Add("scheduler")
+	registry.SetHealth("etcd", nil)
+
+	obj, err := rs.List(api.NewContext(), labels.Everything(), labels.Everything())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := obj.(*api.ComponentStatusList).Items
+	if len(items) != 2 || items[0].ID != "etcd" || items[1].ID != "scheduler" {
+		t.Errorf("unexpected items %#v", items)
+	}
+
+	if _, err := rs.List(api.NewContext(), labels.SelectorFromSet(labels.Set{"a": "b"}), labels.Everything()); err == nil {
+		t.Errorf("expected label selectors to be rejected")
+	}
+}
+
+func TestRESTReadOnly(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add("etcd")
+	rs := NewREST(registry)
+	status := &api.ComponentStatus{TypeMeta: api.TypeMeta{ID: "etcd"}}
+	if _, err := rs.Create(api.NewContext(), status); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if _, err := rs.Update(api.NewContext(), status); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if _, err := rs.Delete(api.NewContext(), "etcd"); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+}
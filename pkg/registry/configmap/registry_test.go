@@ -0,0 +1,38 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+func TestEtcdRegistry(t *testing.T) {
+	tester := &registrytest.EtcdTester{
+		T:           t,
+		NewRegistry: NewEtcdRegistry,
+		Prefix:      "/configmaps",
+		New: func(id, namespace string) runtime.Object {
+			configMap := testConfigMap(id)
+			configMap.Namespace = namespace
+			return configMap
+		},
+	}
+	tester.Test()
+}
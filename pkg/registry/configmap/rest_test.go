@@ -0,0 +1,56 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg, 0)
+}
+
+func testConfigMap(id string) *api.ConfigMap {
+	return &api.ConfigMap{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Data:     map[string]string{"key": id},
+	}
+}
+
+func TestREST(t *testing.T) {
+	reg, rest := NewTestREST()
+	tester := &registrytest.RESTTester{
+		T:        t,
+		Storage:  rest,
+		Registry: reg.GenericRegistry,
+		New:      func(id string) runtime.Object { return testConfigMap(id) },
+		NewList:  func() runtime.Object { return &api.ConfigMapList{} },
+	}
+	invalid := testConfigMap("foo")
+	invalid.Data["a b"] = "c"
+	tester.Test(invalid)
+	tester.TestWatch()
+}
@@ -66,6 +66,7 @@ func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Obje
 	if len(controller.ID) == 0 {
 		controller.ID = uuid.NewUUID().String()
 	}
+	controller.UID = uuid.NewUUID().String()
 	// Pod Manifest ID should be assigned by the pod API
 	controller.DesiredState.PodTemplate.DesiredState.Manifest.ID = ""
 	if errs := validation.ValidateReplicationController(controller); len(errs) > 0 {
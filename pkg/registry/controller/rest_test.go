@@ -251,6 +251,9 @@ func TestCreateController(t *testing.T) {
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if len(controller.UID) == 0 {
+		t.Errorf("expected a UID to be assigned")
+	}
 
 	select {
 	case <-channel:
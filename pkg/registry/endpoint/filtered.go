@@ -0,0 +1,161 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"net"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+
+	"github.com/golang/glog"
+)
+
+// PodLister lists pods. It is satisfied by pod.Registry, which this package
+// cannot refer to.
+type PodLister interface {
+	ListPods(ctx api.Context, selector labels.Selector) (*api.PodList, error)
+}
+
+// FilteredEndpointRegistry is a Registry which hides the endpoints of pods that
+// are not ready to serve, that is pods which are not running every one of their
+// containers according to podInfo. Endpoints which do not belong to any pod are
+// kept, as they point outside the cluster. Updates are stored unfiltered.
+type FilteredEndpointRegistry struct {
+	Registry
+	pods    PodLister
+	podInfo client.PodInfoGetter
+}
+
+// NewFilteredEndpointRegistry returns a FilteredEndpointRegistry of registry.
+func NewFilteredEndpointRegistry(registry Registry, pods PodLister, podInfo client.PodInfoGetter) *FilteredEndpointRegistry {
+	return &FilteredEndpointRegistry{
+		Registry: registry,
+		pods:     pods,
+		podInfo:  podInfo,
+	}
+}
+
+func (r *FilteredEndpointRegistry) ListEndpoints(ctx api.Context) (*api.EndpointsList, error) {
+	list, err := r.Registry.ListEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := r.podsByIP(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := *list
+	filtered.Items = make([]api.Endpoints, len(list.Items))
+	for i := range list.Items {
+		filtered.Items[i] = *filterEndpoints(&list.Items[i], ready)
+	}
+	return &filtered, nil
+}
+
+func (r *FilteredEndpointRegistry) GetEndpoints(ctx api.Context, name string) (*api.Endpoints, error) {
+	endpoints, err := r.Registry.GetEndpoints(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := r.podsByIP(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterEndpoints(endpoints, ready), nil
+}
+
+// WatchEndpoints filters the endpoints of every event by the readiness of their
+// pods at the time of the event.
+func (r *FilteredEndpointRegistry) WatchEndpoints(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
+	w, err := r.Registry.WatchEndpoints(ctx, label, field, resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		endpoints, ok := in.Object.(*api.Endpoints)
+		if !ok || in.Type == watch.Deleted {
+			return in, true
+		}
+		ready, err := r.podsByIP(ctx)
+		if err != nil {
+			glog.Errorf("Couldn't list pods to filter endpoints %s: %v", endpoints.ID, err)
+			return in, true
+		}
+		in.Object = filterEndpoints(endpoints, ready)
+		return in, true
+	}), nil
+}
+
+// podsByIP returns whether each pod in the namespace of ctx is ready, by the IP
+// of the pod.
+func (r *FilteredEndpointRegistry) podsByIP(ctx api.Context) (map[string]bool, error) {
+	pods, err := r.pods.ListPods(ctx, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	ready := map[string]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.CurrentState.PodIP == "" {
+			continue
+		}
+		ip := pod.CurrentState.PodIP
+		ready[ip] = ready[ip] || r.isReady(pod)
+	}
+	return ready, nil
+}
+
+// isReady returns true if every container of pod is running.
+func (r *FilteredEndpointRegistry) isReady(pod *api.Pod) bool {
+	if pod.CurrentState.Host == "" {
+		return false
+	}
+	info, err := r.podInfo.GetPodInfo(pod.CurrentState.Host, pod.Namespace, pod.ID)
+	if err != nil {
+		return false
+	}
+	for _, container := range pod.DesiredState.Manifest.Containers {
+		if status, ok := info[container.Name]; !ok || status.State.Running == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// filterEndpoints returns a copy of endpoints without the endpoints of pods
+// which are not ready.
+func filterEndpoints(endpoints *api.Endpoints, ready map[string]bool) *api.Endpoints {
+	filtered := *endpoints
+	if len(endpoints.Endpoints) == 0 {
+		return &filtered
+	}
+	filtered.Endpoints = []string{}
+	for _, endpoint := range endpoints.Endpoints {
+		host, _, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			host = endpoint
+		}
+		if isReady, ok := ready[host]; ok && !isReady {
+			continue
+		}
+		filtered.Endpoints = append(filtered.Endpoints, endpoint)
+	}
+	return &filtered
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// fakePodCache holds the info of pods by ID.
+type fakePodCache map[string]api.PodInfo
+
+func (c fakePodCache) GetPodInfo(host, namespace, id string) (api.PodInfo, error) {
+	info, ok := c[id]
+	if !ok {
+		return nil, client.ErrPodInfoNotAvailable
+	}
+	return info, nil
+}
+
+func testPod(id, ip string) api.Pod {
+	return api.Pod{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		DesiredState: api.PodState{
+			Manifest: api.ContainerManifest{
+				Containers: []api.Container{{Name: "web"}, {Name: "log"}},
+			},
+		},
+		CurrentState: api.PodState{Host: "machine", PodIP: ip},
+	}
+}
+
+var (
+	running    = api.ContainerStatus{State: api.ContainerState{Running: &api.ContainerStateRunning{}}}
+	terminated = api.ContainerStatus{State: api.ContainerState{Termination: &api.ContainerStateTerminated{}}}
+)
+
+func newFilteredRegistry() (*FilteredEndpointRegistry, *registrytest.ServiceRegistry) {
+	pods := registrytest.NewPodRegistry(&api.PodList{
+		Items: []api.Pod{
+			testPod("ready", "10.0.0.1"),
+			testPod("partial", "10.0.0.2"),
+			testPod("uncached", "10.0.0.3"),
+			testPod("unscheduled", "10.0.0.4"),
+		},
+	})
+	pods.Pods.Items[3].CurrentState.Host = ""
+	podCache := fakePodCache{
+		"ready":       {"web": running, "log": running},
+		"partial":     {"web": running, "log": terminated},
+		"unscheduled": {"web": running, "log": running},
+	}
+	services := registrytest.NewServiceRegistry()
+	return NewFilteredEndpointRegistry(services, pods, podCache), services
+}
+
+var allEndpoints = []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80", "10.0.0.4:80", "192.168.0.1:80"}
+
+// Only the ready pod and the address outside the cluster remain.
+var filteredEndpoints = []string{"10.0.0.1:80", "192.168.0.1:80"}
+
+func TestFilteredEndpointRegistryGet(t *testing.T) {
+	registry, services := newFilteredRegistry()
+	services.Endpoints = api.Endpoints{
+		TypeMeta:  api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+		Endpoints: allEndpoints,
+	}
+
+	endpoints, err := registry.GetEndpoints(api.NewDefaultContext(), "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(filteredEndpoints, endpoints.Endpoints) {
+		t.Errorf("expected %v, got %v", filteredEndpoints, endpoints.Endpoints)
+	}
+	if !reflect.DeepEqual(allEndpoints, services.Endpoints.Endpoints) {
+		t.Errorf("expected the stored endpoints not to change, got %v", services.Endpoints.Endpoints)
+	}
+}
+
+func TestFilteredEndpointRegistryList(t *testing.T) {
+	registry, services := newFilteredRegistry()
+	services.EndpointsList = api.EndpointsList{
+		Items: []api.Endpoints{
+			{TypeMeta: api.TypeMeta{ID: "foo"}, Endpoints: allEndpoints},
+			{TypeMeta: api.TypeMeta{ID: "bar"}},
+		},
+	}
+
+	list, err := registry.ListEndpoints(api.NewContext())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Items) != 2 || !reflect.DeepEqual(filteredEndpoints, list.Items[0].Endpoints) || len(list.Items[1].Endpoints) != 0 {
+		t.Errorf("unexpected endpoints %#v", list.Items)
+	}
+}
+
+func TestFilteredEndpointRegistryUpdate(t *testing.T) {
+	registry, services := newFilteredRegistry()
+	err := registry.UpdateEndpoints(api.NewDefaultContext(), &api.Endpoints{
+		TypeMeta:  api.TypeMeta{ID: "foo"},
+		Endpoints: allEndpoints,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(allEndpoints, services.Endpoints.Endpoints) {
+		t.Errorf("expected the endpoints to be stored unfiltered, got %v", services.Endpoints.Endpoints)
+	}
+}
+
+// watchingServiceRegistry returns a fake watch of endpoints.
+type watchingServiceRegistry struct {
+	*registrytest.ServiceRegistry
+	watcher *watch.FakeWatcher
+}
+
+func (r *watchingServiceRegistry) WatchEndpoints(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
+	return r.watcher, nil
+}
+
+func TestFilteredEndpointRegistryWatch(t *testing.T) {
+	registry, services := newFilteredRegistry()
+	watcher := watch.NewFake()
+	registry.Registry = &watchingServiceRegistry{services, watcher}
+
+	w, err := registry.WatchEndpoints(api.NewDefaultContext(), labels.Everything(), labels.Everything(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Stop()
+	go watcher.Modify(&api.Endpoints{TypeMeta: api.TypeMeta{ID: "foo"}, Endpoints: allEndpoints})
+	event := <-w.ResultChan()
+	if event.Type != watch.Modified || !reflect.DeepEqual(filteredEndpoints, event.Object.(*api.Endpoints).Endpoints) {
+		t.Errorf("unexpected event %#v", event)
+	}
+}
@@ -18,6 +18,8 @@ package etcd
 
 import (
 	"fmt"
+	"path"
+	"reflect"
 	"strconv"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
@@ -26,6 +28,8 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/constraint"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
@@ -52,15 +56,105 @@ func NewRegistry(helper tools.EtcdHelper, manifestFactory pod.ManifestFactory) *
 	return registry
 }
 
-func makePodKey(podID string) string {
-	return "/registry/pods/" + podID
+const (
+	// podPrefix is the key prefix under which pods are stored.
+	podPrefix = "/pods"
+	// controllerPrefix is the key prefix under which replication controllers are stored.
+	controllerPrefix = "/controllers"
+	// servicePrefix is the key prefix under which services are stored.
+	servicePrefix = "/services/specs"
+	// serviceEndpointPrefix is the key prefix under which endpoints are stored.
+	serviceEndpointPrefix = "/services/endpoints"
+)
+
+// makeListKey returns the key under which the objects stored at prefix are kept
+// for the namespace of ctx. Without a namespace, the key spans all namespaces.
+func makeListKey(ctx api.Context, prefix string) string {
+	if ns, ok := api.NamespaceFrom(ctx); ok && len(ns) > 0 {
+		return prefix + "/" + ns
+	}
+	return prefix
+}
+
+// objectCountKeys are the keys the objects counted by CountObjects are stored
+// under, by resource.
+var objectCountKeys = map[string]string{
+	"pods":                   podPrefix,
+	"replicationControllers": controllerPrefix,
+	"services":               servicePrefix,
+	"minions":                "/minions",
+}
+
+// CountObjects returns the number of pods, replication controllers, services
+// and minions stored in every namespace; see generic.Counter.
+func (r *Registry) CountObjects() (map[string]int, error) {
+	counts := map[string]int{}
+	for resource, key := range objectCountKeys {
+		count, err := r.CountList(key)
+		if err != nil {
+			return nil, err
+		}
+		counts[resource] = count
+	}
+	return counts, nil
+}
+
+// makeItemKey returns the key of the object id stored at prefix in the namespace
+// of ctx. A namespace is required.
+func makeItemKey(ctx api.Context, prefix, id string) (string, error) {
+	ns, ok := api.NamespaceFrom(ctx)
+	if !ok || len(ns) == 0 {
+		return "", fmt.Errorf("a namespace is required to access %s/%s", prefix, id)
+	}
+	return prefix + "/" + ns + "/" + id, nil
+}
+
+// legacyPrefixes lists the key prefixes whose objects were stored directly
+// beneath them, as prefix/id, before they were kept per namespace.
+var legacyPrefixes = []string{podPrefix, controllerPrefix, servicePrefix, serviceEndpointPrefix}
+
+// MigrateLegacyKeys moves the objects stored at the keys used before objects
+// were kept per namespace, prefix/id, into the default namespace at
+// prefix/default/id. It is safe to run on every start: when an object exists
+// at both keys the namespaced copy is kept.
+func (r *Registry) MigrateLegacyKeys() error {
+	for _, prefix := range legacyPrefixes {
+		response, err := r.Client.Get(r.PathPrefix+prefix, false, false)
+		if tools.IsEtcdNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if response.Node == nil {
+			continue
+		}
+		for _, node := range response.Node.Nodes {
+			if node.Dir {
+				continue
+			}
+			key := r.PathPrefix + prefix + "/" + api.NamespaceDefault + "/" + path.Base(node.Key)
+			if _, err := r.Client.Create(key, node.Value, 0); err != nil && !tools.IsEtcdNodeExist(err) {
+				return err
+			}
+			if _, err := r.Client.Delete(node.Key, false); err != nil && !tools.IsEtcdNotFound(err) {
+				return err
+			}
+			glog.Infof("Moved %s to %s", node.Key, key)
+		}
+	}
+	return nil
+}
+
+func makePodKey(ctx api.Context, podID string) (string, error) {
+	return makeItemKey(ctx, podPrefix, podID)
 }
 
-// parseWatchResourceVersion takes a resource version argument and converts it to
+// ParseWatchResourceVersion takes a resource version argument and converts it to
 // the etcd version we should pass to helper.Watch(). Because resourceVersion is
 // an opaque value, the default watch behavior for non-zero watch is to watch
 // the next value (if you pass "1", you will see updates from "2" onwards).
-func parseWatchResourceVersion(resourceVersion, kind string) (uint64, error) {
+func ParseWatchResourceVersion(resourceVersion, kind string) (uint64, error) {
 	if resourceVersion == "" || resourceVersion == "0" {
 		return 0, nil
 	}
@@ -81,7 +175,7 @@ func (r *Registry) ListPods(ctx api.Context, selector labels.Selector) (*api.Pod
 // ListPodsPredicate obtains a list of pods that match filter.
 func (r *Registry) ListPodsPredicate(ctx api.Context, filter func(*api.Pod) bool) (*api.PodList, error) {
 	allPods := api.PodList{}
-	err := r.ExtractToList("/registry/pods", &allPods)
+	err := r.ExtractToList(makeListKey(ctx, podPrefix), &allPods)
 	if err != nil {
 		return nil, err
 	}
@@ -99,13 +193,30 @@ func (r *Registry) ListPodsPredicate(ctx api.Context, filter func(*api.Pod) bool
 	return &allPods, nil
 }
 
+// ListPodsPage obtains a page of at most limit pods that match filter, in the
+// order of their keys, starting after the key after, which is relative to the
+// pods listed for ctx. Only the pods of the page are decoded. It returns true
+// if more matching pods follow.
+func (r *Registry) ListPodsPage(ctx api.Context, filter func(*api.Pod) (bool, error), after string, limit int) (*api.PodList, bool, error) {
+	pods := &api.PodList{}
+	more, err := r.ExtractToListPage(makeListKey(ctx, podPrefix), pods, after, limit, func(obj runtime.Object) (bool, error) {
+		pod := obj.(*api.Pod)
+		pod.CurrentState.Host = pod.DesiredState.Host
+		return filter(pod)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return pods, more, nil
+}
+
 // WatchPods begins watching for new, changed, or deleted pods.
 func (r *Registry) WatchPods(ctx api.Context, resourceVersion string, filter func(*api.Pod) bool) (watch.Interface, error) {
-	version, err := parseWatchResourceVersion(resourceVersion, "pod")
+	version, err := ParseWatchResourceVersion(resourceVersion, "pod")
 	if err != nil {
 		return nil, err
 	}
-	return r.WatchList("/registry/pods", version, func(obj runtime.Object) bool {
+	return r.WatchList(makeListKey(ctx, podPrefix), version, func(obj runtime.Object) bool {
 		switch t := obj.(type) {
 		case *api.Pod:
 			return filter(t)
@@ -118,8 +229,12 @@ func (r *Registry) WatchPods(ctx api.Context, resourceVersion string, filter fun
 
 // GetPod gets a specific pod specified by its ID.
 func (r *Registry) GetPod(ctx api.Context, podID string) (*api.Pod, error) {
+	key, err := makePodKey(ctx, podID)
+	if err != nil {
+		return nil, err
+	}
 	var pod api.Pod
-	if err := r.ExtractObj(makePodKey(podID), &pod, false); err != nil {
+	if err := r.ExtractObj(key, &pod, false); err != nil {
 		return nil, etcderr.InterpretGetError(err, "pod", podID)
 	}
 	// TODO: Currently nothing sets CurrentState.Host. We need a feedback loop that sets
@@ -130,7 +245,7 @@ func (r *Registry) GetPod(ctx api.Context, podID string) (*api.Pod, error) {
 }
 
 func makeContainerKey(machine string) string {
-	return "/registry/hosts/" + machine + "/kubelet"
+	return "/hosts/" + machine + "/kubelet"
 }
 
 // CreatePod creates a pod based on a specification.
@@ -141,19 +256,26 @@ func (r *Registry) CreatePod(ctx api.Context, pod *api.Pod) error {
 	// DesiredState.Host == "" is a signal to the scheduler that this pod needs scheduling.
 	pod.DesiredState.Status = api.PodRunning
 	pod.DesiredState.Host = ""
-	err := r.CreateObj(makePodKey(pod.ID), pod, 0)
+	key, err := makePodKey(ctx, pod.ID)
+	if err != nil {
+		return err
+	}
+	err = r.CreateObj(key, pod, 0)
 	return etcderr.InterpretCreateError(err, "pod", pod.ID)
 }
 
 // ApplyBinding implements binding's registry
 func (r *Registry) ApplyBinding(ctx api.Context, binding *api.Binding) error {
-	return etcderr.InterpretCreateError(r.assignPod(binding.PodID, binding.Host), "binding", "")
+	return etcderr.InterpretCreateError(r.assignPod(ctx, binding.PodID, binding.Host), "binding", "")
 }
 
 // setPodHostTo sets the given pod's host to 'machine' iff it was previously 'oldMachine'.
 // Returns the current state of the pod, or an error.
-func (r *Registry) setPodHostTo(podID, oldMachine, machine string) (finalPod *api.Pod, err error) {
-	podKey := makePodKey(podID)
+func (r *Registry) setPodHostTo(ctx api.Context, podID, oldMachine, machine string) (finalPod *api.Pod, err error) {
+	podKey, err := makePodKey(ctx, podID)
+	if err != nil {
+		return nil, err
+	}
 	err = r.AtomicUpdate(podKey, &api.Pod{}, func(obj runtime.Object) (runtime.Object, error) {
 		pod, ok := obj.(*api.Pod)
 		if !ok {
@@ -170,8 +292,8 @@ func (r *Registry) setPodHostTo(podID, oldMachine, machine string) (finalPod *ap
 }
 
 // assignPod assigns the given pod to the given machine.
-func (r *Registry) assignPod(podID string, machine string) error {
-	finalPod, err := r.setPodHostTo(podID, "", machine)
+func (r *Registry) assignPod(ctx api.Context, podID string, machine string) error {
+	finalPod, err := r.setPodHostTo(ctx, podID, "", machine)
 	if err != nil {
 		return err
 	}
@@ -192,17 +314,31 @@ func (r *Registry) assignPod(podID string, machine string) error {
 	if err != nil {
 		// Put the pod's host back the way it was. This is a terrible hack that
 		// won't be needed if we convert this to a rectification loop.
-		if _, err2 := r.setPodHostTo(podID, machine, ""); err2 != nil {
+		if _, err2 := r.setPodHostTo(ctx, podID, machine, ""); err2 != nil {
 			glog.Errorf("Stranding pod %v; couldn't clear host after previous error: %v", podID, err2)
 		}
 	}
 	return err
 }
 
+// isNoopPodUpdate returns true if writing pod would leave the stored pod unchanged.
+// An update based on an older version is never a no-op, so that it still conflicts.
+func isNoopPodUpdate(pod, stored *api.Pod) bool {
+	if len(pod.ResourceVersion) != 0 && pod.ResourceVersion != stored.ResourceVersion {
+		return false
+	}
+	updated := *pod
+	updated.ResourceVersion = stored.ResourceVersion
+	return reflect.DeepEqual(&updated, stored)
+}
+
 func (r *Registry) UpdatePod(ctx api.Context, pod *api.Pod) error {
 	var podOut api.Pod
-	podKey := makePodKey(pod.ID)
-	err := r.EtcdHelper.ExtractObj(podKey, &podOut, false)
+	podKey, err := makePodKey(ctx, pod.ID)
+	if err != nil {
+		return err
+	}
+	err = r.EtcdHelper.ExtractObj(podKey, &podOut, false)
 	if err != nil {
 		return err
 	}
@@ -215,11 +351,16 @@ func (r *Registry) UpdatePod(ctx api.Context, pod *api.Pod) error {
 			return errors.NewInvalid("Pod", pod.ID, errs)
 		}
 	}
+	if isNoopPodUpdate(pod, &podOut) {
+		glog.V(4).Infof("Skipping update of pod %s, nothing changed", pod.ID)
+		metrics.NoopUpdates.Inc("pods")
+		return nil
+	}
 	// There's no race with the scheduler, because either this write will fail because the host
 	// has been updated, or the host update will fail because this pod has been updated.
-	err = r.EtcdHelper.SetObj(podKey, pod)
+	err = r.EtcdHelper.SetObjWithMatch(podKey, pod, api.ResourceVersionMatchFrom(ctx))
 	if err != nil {
-		return err
+		return etcderr.InterpretUpdateError(err, "pod", pod.ID)
 	}
 	if !scheduled {
 		// never scheduled, just update.
@@ -243,8 +384,11 @@ func (r *Registry) UpdatePod(ctx api.Context, pod *api.Pod) error {
 // DeletePod deletes an existing pod specified by its ID.
 func (r *Registry) DeletePod(ctx api.Context, podID string) error {
 	var pod api.Pod
-	podKey := makePodKey(podID)
-	err := r.ExtractObj(podKey, &pod, false)
+	podKey, err := makePodKey(ctx, podID)
+	if err != nil {
+		return err
+	}
+	err = r.ExtractObj(podKey, &pod, false)
 	if err != nil {
 		return etcderr.InterpretDeleteError(err, "pod", podID)
 	}
@@ -286,28 +430,31 @@ func (r *Registry) DeletePod(ctx api.Context, podID string) error {
 // ListControllers obtains a list of ReplicationControllers.
 func (r *Registry) ListControllers(ctx api.Context) (*api.ReplicationControllerList, error) {
 	controllers := &api.ReplicationControllerList{}
-	err := r.ExtractToList("/registry/controllers", controllers)
+	err := r.ExtractToList(makeListKey(ctx, controllerPrefix), controllers)
 	return controllers, err
 }
 
 // WatchControllers begins watching for new, changed, or deleted controllers.
 func (r *Registry) WatchControllers(ctx api.Context, resourceVersion string) (watch.Interface, error) {
-	version, err := parseWatchResourceVersion(resourceVersion, "replicationControllers")
+	version, err := ParseWatchResourceVersion(resourceVersion, "replicationControllers")
 	if err != nil {
 		return nil, err
 	}
-	return r.WatchList("/registry/controllers", version, tools.Everything)
+	return r.WatchList(makeListKey(ctx, controllerPrefix), version, tools.Everything)
 }
 
-func makeControllerKey(id string) string {
-	return "/registry/controllers/" + id
+func makeControllerKey(ctx api.Context, id string) (string, error) {
+	return makeItemKey(ctx, controllerPrefix, id)
 }
 
 // GetController gets a specific ReplicationController specified by its ID.
 func (r *Registry) GetController(ctx api.Context, controllerID string) (*api.ReplicationController, error) {
 	var controller api.ReplicationController
-	key := makeControllerKey(controllerID)
-	err := r.ExtractObj(key, &controller, false)
+	key, err := makeControllerKey(ctx, controllerID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.ExtractObj(key, &controller, false)
 	if err != nil {
 		return nil, etcderr.InterpretGetError(err, "replicationController", controllerID)
 	}
@@ -316,45 +463,72 @@ func (r *Registry) GetController(ctx api.Context, controllerID string) (*api.Rep
 
 // CreateController creates a new ReplicationController.
 func (r *Registry) CreateController(ctx api.Context, controller *api.ReplicationController) error {
-	err := r.CreateObj(makeControllerKey(controller.ID), controller, 0)
+	key, err := makeControllerKey(ctx, controller.ID)
+	if err != nil {
+		return err
+	}
+	err = r.CreateObj(key, controller, 0)
 	return etcderr.InterpretCreateError(err, "replicationController", controller.ID)
 }
 
-// UpdateController replaces an existing ReplicationController.
+// UpdateController replaces an existing ReplicationController. The UID and
+// creation timestamp of the stored controller are kept if the update omits
+// them, and may not be changed; pods are matched to their controller by UID.
 func (r *Registry) UpdateController(ctx api.Context, controller *api.ReplicationController) error {
-	err := r.SetObj(makeControllerKey(controller.ID), controller)
+	key, err := makeControllerKey(ctx, controller.ID)
+	if err != nil {
+		return err
+	}
+	var existing api.ReplicationController
+	if err := r.ExtractObj(key, &existing, true); err != nil {
+		return etcderr.InterpretGetError(err, "replicationController", controller.ID)
+	}
+	if errs := generic.PreserveImmutableFields(&controller.TypeMeta, &existing.TypeMeta); len(errs) > 0 {
+		return errors.NewInvalid("replicationController", controller.ID, errs)
+	}
+	err = r.SetObjWithMatch(key, controller, api.ResourceVersionMatchFrom(ctx))
 	return etcderr.InterpretUpdateError(err, "replicationController", controller.ID)
 }
 
 // DeleteController deletes a ReplicationController specified by its ID.
 func (r *Registry) DeleteController(ctx api.Context, controllerID string) error {
-	key := makeControllerKey(controllerID)
-	err := r.Delete(key, false)
+	key, err := makeControllerKey(ctx, controllerID)
+	if err != nil {
+		return err
+	}
+	err = r.Delete(key, false)
 	return etcderr.InterpretDeleteError(err, "replicationController", controllerID)
 }
 
-func makeServiceKey(name string) string {
-	return "/registry/services/specs/" + name
+func makeServiceKey(ctx api.Context, name string) (string, error) {
+	return makeItemKey(ctx, servicePrefix, name)
 }
 
 // ListServices obtains a list of Services.
 func (r *Registry) ListServices(ctx api.Context) (*api.ServiceList, error) {
 	list := &api.ServiceList{}
-	err := r.ExtractToList("/registry/services/specs", list)
+	err := r.ExtractToList(makeListKey(ctx, servicePrefix), list)
 	return list, err
 }
 
 // CreateService creates a new Service.
 func (r *Registry) CreateService(ctx api.Context, svc *api.Service) error {
-	err := r.CreateObj(makeServiceKey(svc.ID), svc, 0)
+	key, err := makeServiceKey(ctx, svc.ID)
+	if err != nil {
+		return err
+	}
+	err = r.CreateObj(key, svc, 0)
 	return etcderr.InterpretCreateError(err, "service", svc.ID)
 }
 
 // GetService obtains a Service specified by its name.
 func (r *Registry) GetService(ctx api.Context, name string) (*api.Service, error) {
-	key := makeServiceKey(name)
+	key, err := makeServiceKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 	var svc api.Service
-	err := r.ExtractObj(key, &svc, false)
+	err = r.ExtractObj(key, &svc, false)
 	if err != nil {
 		return nil, etcderr.InterpretGetError(err, "service", name)
 	}
@@ -363,30 +537,39 @@ func (r *Registry) GetService(ctx api.Context, name string) (*api.Service, error
 
 // GetEndpoints obtains the endpoints for the service identified by 'name'.
 func (r *Registry) GetEndpoints(ctx api.Context, name string) (*api.Endpoints, error) {
-	key := makeServiceEndpointsKey(name)
+	key, err := makeServiceEndpointsKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 	var endpoints api.Endpoints
-	err := r.ExtractObj(key, &endpoints, false)
+	err = r.ExtractObj(key, &endpoints, false)
 	if err != nil {
 		return nil, etcderr.InterpretGetError(err, "endpoints", name)
 	}
 	return &endpoints, nil
 }
 
-func makeServiceEndpointsKey(name string) string {
-	return "/registry/services/endpoints/" + name
+func makeServiceEndpointsKey(ctx api.Context, name string) (string, error) {
+	return makeItemKey(ctx, serviceEndpointPrefix, name)
 }
 
 // DeleteService deletes a Service specified by its name.
 func (r *Registry) DeleteService(ctx api.Context, name string) error {
-	key := makeServiceKey(name)
-	err := r.Delete(key, true)
+	key, err := makeServiceKey(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = r.Delete(key, true)
 	if err != nil {
 		return etcderr.InterpretDeleteError(err, "service", name)
 	}
 
 	// TODO: can leave dangling endpoints, and potentially return incorrect
 	// endpoints if a new service is created with the same name
-	key = makeServiceEndpointsKey(name)
+	key, err = makeServiceEndpointsKey(ctx, name)
+	if err != nil {
+		return err
+	}
 	if err := r.Delete(key, true); err != nil && !tools.IsEtcdNotFound(err) {
 		return etcderr.InterpretDeleteError(err, "endpoints", name)
 	}
@@ -395,13 +578,17 @@ func (r *Registry) DeleteService(ctx api.Context, name string) error {
 
 // UpdateService replaces an existing Service.
 func (r *Registry) UpdateService(ctx api.Context, svc *api.Service) error {
-	err := r.SetObj(makeServiceKey(svc.ID), svc)
+	key, err := makeServiceKey(ctx, svc.ID)
+	if err != nil {
+		return err
+	}
+	err = r.SetObjWithMatch(key, svc, api.ResourceVersionMatchFrom(ctx))
 	return etcderr.InterpretUpdateError(err, "service", svc.ID)
 }
 
 // WatchServices begins watching for new, changed, or deleted service configurations.
 func (r *Registry) WatchServices(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
-	version, err := parseWatchResourceVersion(resourceVersion, "service")
+	version, err := ParseWatchResourceVersion(resourceVersion, "service")
 	if err != nil {
 		return nil, err
 	}
@@ -409,25 +596,40 @@ func (r *Registry) WatchServices(ctx api.Context, label, field labels.Selector,
 		return nil, fmt.Errorf("label selectors are not supported on services")
 	}
 	if value, found := field.RequiresExactMatch("ID"); found {
-		return r.Watch(makeServiceKey(value), version), nil
+		key, err := makeServiceKey(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		return r.Watch(key, version), nil
+	}
+	if value, found := field.RequiresExactMatch("metadata.name"); found {
+		key, err := makeServiceKey(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		return r.Watch(key, version), nil
 	}
 	if field.Empty() {
-		return r.WatchList("/registry/services/specs", version, tools.Everything)
+		return r.WatchList(makeListKey(ctx, servicePrefix), version, tools.Everything)
 	}
-	return nil, fmt.Errorf("only the 'ID' and default (everything) field selectors are supported")
+	return nil, fmt.Errorf("only the 'ID', 'metadata.name' and default (everything) field selectors are supported")
 }
 
 // ListEndpoints obtains a list of Services.
 func (r *Registry) ListEndpoints(ctx api.Context) (*api.EndpointsList, error) {
 	list := &api.EndpointsList{}
-	err := r.ExtractToList("/registry/services/endpoints", list)
+	err := r.ExtractToList(makeListKey(ctx, serviceEndpointPrefix), list)
 	return list, err
 }
 
 // UpdateEndpoints update Endpoints of a Service.
 func (r *Registry) UpdateEndpoints(ctx api.Context, e *api.Endpoints) error {
+	key, err := makeServiceEndpointsKey(ctx, e.ID)
+	if err != nil {
+		return err
+	}
 	// TODO: this is a really bad misuse of AtomicUpdate, need to compute a diff inside the loop.
-	err := r.AtomicUpdate(makeServiceEndpointsKey(e.ID), &api.Endpoints{},
+	err = r.AtomicUpdate(key, &api.Endpoints{},
 		func(input runtime.Object) (runtime.Object, error) {
 			// TODO: racy - label query is returning different results for two simultaneous updaters
 			return e, nil
@@ -437,7 +639,7 @@ func (r *Registry) UpdateEndpoints(ctx api.Context, e *api.Endpoints) error {
 
 // WatchEndpoints begins watching for new, changed, or deleted endpoint configurations.
 func (r *Registry) WatchEndpoints(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
-	version, err := parseWatchResourceVersion(resourceVersion, "endpoints")
+	version, err := ParseWatchResourceVersion(resourceVersion, "endpoints")
 	if err != nil {
 		return nil, err
 	}
@@ -445,22 +647,50 @@ func (r *Registry) WatchEndpoints(ctx api.Context, label, field labels.Selector,
 		return nil, fmt.Errorf("label selectors are not supported on endpoints")
 	}
 	if value, found := field.RequiresExactMatch("ID"); found {
-		return r.Watch(makeServiceEndpointsKey(value), version), nil
+		key, err := makeServiceEndpointsKey(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		return r.Watch(key, version), nil
 	}
 	if field.Empty() {
-		return r.WatchList("/registry/services/endpoints", version, tools.Everything)
+		return r.WatchList(makeListKey(ctx, serviceEndpointPrefix), version, tools.Everything)
 	}
 	return nil, fmt.Errorf("only the 'ID' and default (everything) field selectors are supported")
 }
 
 func makeMinionKey(minionID string) string {
-	return "/registry/minions/" + minionID
+	return "/minions/" + minionID
+}
+
+func (r *Registry) ListMinions(ctx api.Context, selector labels.Selector) (*api.MinionList, error) {
+	minions := &api.MinionList{}
+	err := r.ExtractToList("/minions", minions)
+	if err != nil || selector.Empty() {
+		return minions, err
+	}
+	filtered := []api.Minion{}
+	for _, minion := range minions.Items {
+		if selector.Matches(labels.Set(minion.Labels)) {
+			filtered = append(filtered, minion)
+		}
+	}
+	minions.Items = filtered
+	return minions, nil
 }
 
-func (r *Registry) ListMinions(ctx api.Context) (*api.MinionList, error) {
+// ListMinionsPage obtains a page of at most limit minions that match filter,
+// ordered by id, starting after the minion called after. Only the minions of
+// the page are decoded. It returns true if more matching minions follow.
+func (r *Registry) ListMinionsPage(ctx api.Context, filter func(*api.Minion) (bool, error), after string, limit int) (*api.MinionList, bool, error) {
 	minions := &api.MinionList{}
-	err := r.ExtractToList("/registry/minions", minions)
-	return minions, err
+	more, err := r.ExtractToListPage("/minions", minions, after, limit, func(obj runtime.Object) (bool, error) {
+		return filter(obj.(*api.Minion))
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return minions, more, nil
 }
 
 func (r *Registry) CreateMinion(ctx api.Context, minion *api.Minion) error {
@@ -479,6 +709,11 @@ func (r *Registry) GetMinion(ctx api.Context, minionID string) (*api.Minion, err
 	return &minion, nil
 }
 
+func (r *Registry) UpdateMinion(ctx api.Context, minion *api.Minion) error {
+	err := r.SetObjWithMatch(makeMinionKey(minion.ID), minion, api.ResourceVersionMatchFrom(ctx))
+	return etcderr.InterpretUpdateError(err, "minion", minion.ID)
+}
+
 func (r *Registry) DeleteMinion(ctx api.Context, minionID string) error {
 	key := makeMinionKey(minionID)
 	err := r.Delete(key, true)
@@ -17,6 +17,7 @@ limitations under the License.
 package etcd
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
 	"testing"
@@ -25,16 +26,18 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/pod"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 
 	"github.com/coreos/go-etcd/etcd"
 )
 
 func NewTestEtcdRegistry(client tools.EtcdClient) *Registry {
-	registry := NewRegistry(tools.EtcdHelper{client, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}},
+	registry := NewRegistry(tools.EtcdHelper{client, latest.Codec, tools.RuntimeVersionAdapter{latest.ResourceVersioner}, "/registry", tools.WatchConfig{}},
 		&pod.BasicManifestFactory{
 			ServiceRegistry: &registrytest.ServiceRegistry{},
 		})
@@ -55,7 +58,7 @@ func TestEtcdParseWatchResourceVersion(t *testing.T) {
 		{Version: "10", ExpectVersion: 11},
 	}
 	for _, testCase := range testCases {
-		version, err := parseWatchResourceVersion(testCase.Version, testCase.Kind)
+		version, err := ParseWatchResourceVersion(testCase.Version, testCase.Kind)
 		switch {
 		case testCase.Err:
 			if err == nil {
@@ -77,9 +80,9 @@ func TestEtcdParseWatchResourceVersion(t *testing.T) {
 }
 
 func TestEtcdGetPod(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Set("/registry/pods/foo", runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	fakeClient.Set("/registry/pods/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
 	pod, err := registry.GetPod(ctx, "foo")
 	if err != nil {
@@ -91,10 +94,43 @@ func TestEtcdGetPod(t *testing.T) {
 	}
 }
 
+func TestEtcdMigrateLegacyKeys(t *testing.T) {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	legacy := runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}})
+	fakeClient.Data["/registry/pods"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{Key: "/registry/pods/foo", Value: legacy},
+					{Key: "/registry/pods/default", Dir: true},
+				},
+			},
+		},
+	}
+	for _, key := range []string{"/registry/controllers", "/registry/services/specs", "/registry/services/endpoints"} {
+		fakeClient.ExpectNotFoundGet(key)
+	}
+	registry := NewTestEtcdRegistry(fakeClient)
+	if err := registry.MigrateLegacyKeys(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pod, err := registry.GetPod(api.NewDefaultContext(), "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pod.ID != "foo" {
+		t.Errorf("Unexpected pod: %#v", pod)
+	}
+	if len(fakeClient.DeletedKeys) != 1 || fakeClient.DeletedKeys[0] != "/registry/pods/foo" {
+		t.Errorf("Unexpected deleted keys: %v", fakeClient.DeletedKeys)
+	}
+}
+
 func TestEtcdGetPodNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/pods/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -108,10 +144,10 @@ func TestEtcdGetPodNotFound(t *testing.T) {
 }
 
 func TestEtcdCreatePod(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/pods/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -143,7 +179,7 @@ func TestEtcdCreatePod(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	resp, err := fakeClient.Get("/registry/pods/foo", false, false)
+	resp, err := fakeClient.Get("/registry/pods/default/foo", false, false)
 	if err != nil {
 		t.Fatalf("Unexpected error %v", err)
 	}
@@ -169,9 +205,9 @@ func TestEtcdCreatePod(t *testing.T) {
 }
 
 func TestEtcdCreatePodAlreadyExisting(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/pods/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: &etcd.Node{
 				Value: runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}),
@@ -191,10 +227,10 @@ func TestEtcdCreatePodAlreadyExisting(t *testing.T) {
 }
 
 func TestEtcdCreatePodWithContainersError(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/pods/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -232,10 +268,10 @@ func TestEtcdCreatePodWithContainersError(t *testing.T) {
 }
 
 func TestEtcdCreatePodWithContainersNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/pods/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -273,7 +309,7 @@ func TestEtcdCreatePodWithContainersNotFound(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	resp, err := fakeClient.Get("/registry/pods/foo", false, false)
+	resp, err := fakeClient.Get("/registry/pods/default/foo", false, false)
 	if err != nil {
 		t.Fatalf("Unexpected error %v", err)
 	}
@@ -299,10 +335,10 @@ func TestEtcdCreatePodWithContainersNotFound(t *testing.T) {
 }
 
 func TestEtcdCreatePodWithExistingContainers(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/pods/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -339,7 +375,7 @@ func TestEtcdCreatePodWithExistingContainers(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	resp, err := fakeClient.Get("/registry/pods/foo", false, false)
+	resp, err := fakeClient.Get("/registry/pods/default/foo", false, false)
 	if err != nil {
 		t.Fatalf("Unexpected error %v", err)
 	}
@@ -365,11 +401,11 @@ func TestEtcdCreatePodWithExistingContainers(t *testing.T) {
 }
 
 func TestEtcdUpdatePodNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	key := "/registry/pods/foo"
+	key := "/registry/pods/default/foo"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{},
 		E: tools.EtcdErrorNotFound,
@@ -389,11 +425,11 @@ func TestEtcdUpdatePodNotFound(t *testing.T) {
 }
 
 func TestEtcdUpdatePodNotScheduled(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	key := "/registry/pods/foo"
+	key := "/registry/pods/default/foo"
 	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &api.Pod{
 		TypeMeta: api.TypeMeta{ID: "foo"},
 	}), 1)
@@ -420,12 +456,79 @@ func TestEtcdUpdatePodNotScheduled(t *testing.T) {
 	}
 }
 
+func TestEtcdUpdatePodConflict(t *testing.T) {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+
+	key := "/registry/pods/default/foo"
+	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+	}), 0)
+	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Labels:   map[string]string{"writer": "first"},
+	}), 0)
+
+	registry := NewTestEtcdRegistry(fakeClient)
+	podIn := api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: "1"},
+		Labels:   map[string]string{"writer": "second"},
+	}
+	if err := registry.UpdatePod(api.NewDefaultContext(), &podIn); !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict, got %v", err)
+	}
+	ctx := api.WithResourceVersionMatch(api.NewDefaultContext(), api.ResourceVersionMatchNotOlderThan)
+	podIn.ResourceVersion = "5"
+	if err := registry.UpdatePod(ctx, &podIn); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestEtcdUpdatePodNoop(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+
+	pod := api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Labels: map[string]string{
+			"foo": "bar",
+		},
+		DesiredState: api.PodState{Host: "machine"},
+	}
+	key := "/registry/pods/default/foo"
+	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &pod), 0)
+	index := fakeClient.ChangeIndex
+
+	registry := NewTestEtcdRegistry(fakeClient)
+	noops := metrics.NoopUpdates.Value("pods")
+	pod.ResourceVersion = strconv.FormatUint(index, 10)
+	if err := registry.UpdatePod(ctx, &pod); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if fakeClient.ChangeIndex != index {
+		t.Errorf("expected no write, change index went from %d to %d", index, fakeClient.ChangeIndex)
+	}
+	if e, a := noops+1, metrics.NoopUpdates.Value("pods"); e != a {
+		t.Errorf("expected %v no-op pod updates, got %v", e, a)
+	}
+
+	// A stale update is still rejected rather than silently ignored.
+	pod.ResourceVersion = strconv.FormatUint(index-1, 10)
+	if err := registry.UpdatePod(ctx, &pod); err == nil {
+		t.Errorf("expected stale update to fail")
+	}
+	if e, a := noops+1, metrics.NoopUpdates.Value("pods"); e != a {
+		t.Errorf("expected a stale update not to count as a no-op, got %v no-op updates", a)
+	}
+}
+
 func TestEtcdUpdatePodScheduled(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	key := "/registry/pods/foo"
+	key := "/registry/pods/default/foo"
 	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &api.Pod{
 		TypeMeta: api.TypeMeta{ID: "foo"},
 		DesiredState: api.PodState{
@@ -507,11 +610,11 @@ func TestEtcdUpdatePodScheduled(t *testing.T) {
 }
 
 func TestEtcdDeletePod(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	key := "/registry/pods/foo"
+	key := "/registry/pods/default/foo"
 	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &api.Pod{
 		TypeMeta:     api.TypeMeta{ID: "foo"},
 		DesiredState: api.PodState{Host: "machine"},
@@ -544,11 +647,11 @@ func TestEtcdDeletePod(t *testing.T) {
 }
 
 func TestEtcdDeletePodMultipleContainers(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	key := "/registry/pods/foo"
+	key := "/registry/pods/default/foo"
 	fakeClient.Set(key, runtime.EncodeOrDie(latest.Codec, &api.Pod{
 		TypeMeta:     api.TypeMeta{ID: "foo"},
 		DesiredState: api.PodState{Host: "machine"},
@@ -587,7 +690,7 @@ func TestEtcdDeletePodMultipleContainers(t *testing.T) {
 
 func TestEtcdEmptyListPods(t *testing.T) {
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/pods"
+	key := "/registry/pods/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: &etcd.Node{
@@ -597,7 +700,7 @@ func TestEtcdEmptyListPods(t *testing.T) {
 		E: nil,
 	}
 	registry := NewTestEtcdRegistry(fakeClient)
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	pods, err := registry.ListPods(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -610,13 +713,13 @@ func TestEtcdEmptyListPods(t *testing.T) {
 
 func TestEtcdListPodsNotFound(t *testing.T) {
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/pods"
+	key := "/registry/pods/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{},
 		E: tools.EtcdErrorNotFound,
 	}
 	registry := NewTestEtcdRegistry(fakeClient)
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	pods, err := registry.ListPods(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -629,7 +732,7 @@ func TestEtcdListPodsNotFound(t *testing.T) {
 
 func TestEtcdListPods(t *testing.T) {
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/pods"
+	key := "/registry/pods/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: &etcd.Node{
@@ -652,7 +755,7 @@ func TestEtcdListPods(t *testing.T) {
 		E: nil,
 	}
 	registry := NewTestEtcdRegistry(fakeClient)
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	pods, err := registry.ListPods(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -667,10 +770,182 @@ func TestEtcdListPods(t *testing.T) {
 	}
 }
 
-func TestEtcdListControllersNotFound(t *testing.T) {
+func TestEtcdListPodsAllNamespaces(t *testing.T) {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.Data["/registry/pods"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{
+						Dir: true,
+						Nodes: []*etcd.Node{
+							{
+								Value: runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: "default"}}),
+							},
+						},
+					},
+					{
+						Dir: true,
+						Nodes: []*etcd.Node{
+							{
+								Value: runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: "other"}}),
+							},
+						},
+					},
+				},
+			},
+		},
+		E: nil,
+	}
+	registry := NewTestEtcdRegistry(fakeClient)
+	pods, err := registry.ListPods(api.NewContext(), labels.Everything())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if len(pods.Items) != 2 || pods.Items[0].Namespace != "default" || pods.Items[1].Namespace != "other" {
+		t.Errorf("Unexpected pod list: %#v", pods)
+	}
+}
+
+func TestEtcdListPodsPage(t *testing.T) {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	podNode := func(namespace, id string) *etcd.Node {
+		return &etcd.Node{
+			Key: "/registry/pods/" + namespace + "/" + id,
+			Value: runtime.EncodeOrDie(latest.Codec, &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: id, Namespace: namespace},
+				DesiredState: api.PodState{Host: "machine"},
+			}),
+		}
+	}
+	fakeClient.Data["/registry/pods"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{Key: "/registry/pods/other", Dir: true, Nodes: []*etcd.Node{podNode("other", "bar")}},
+					{Key: "/registry/pods/default", Dir: true, Nodes: []*etcd.Node{podNode("default", "foo"), podNode("default", "bar")}},
+				},
+			},
+		},
+	}
+	registry := NewTestEtcdRegistry(fakeClient)
+	everything := func(pod *api.Pod) (bool, error) { return true, nil }
+
+	pods, more, err := registry.ListPodsPage(api.NewContext(), everything, "default/bar", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !more || len(pods.Items) != 1 || pods.Items[0].Namespace != "default" || pods.Items[0].ID != "foo" {
+		t.Errorf("Unexpected pod page: %#v, %v", pods, more)
+	}
+	if pods.Items[0].CurrentState.Host != "machine" {
+		t.Errorf("Failed to populate host name.")
+	}
+
+	pods, more, err = registry.ListPodsPage(api.NewContext(), everything, "default/foo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if more || len(pods.Items) != 1 || pods.Items[0].Namespace != "other" {
+		t.Errorf("Unexpected pod page: %#v, %v", pods, more)
+	}
+}
+
+func TestEtcdListMinionsPage(t *testing.T) {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.Data["/registry/minions"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{Key: "/registry/minions/m3", Value: runtime.EncodeOrDie(latest.Codec, &api.Minion{TypeMeta: api.TypeMeta{ID: "m3"}})},
+					{Key: "/registry/minions/m1", Value: runtime.EncodeOrDie(latest.Codec, &api.Minion{TypeMeta: api.TypeMeta{ID: "m1"}})},
+					{Key: "/registry/minions/m2", Value: runtime.EncodeOrDie(latest.Codec, &api.Minion{TypeMeta: api.TypeMeta{ID: "m2"}})},
+				},
+			},
+		},
+	}
+	registry := NewTestEtcdRegistry(fakeClient)
+	notM2 := func(minion *api.Minion) (bool, error) { return minion.ID != "m2", nil }
+	minions, more, err := registry.ListMinionsPage(api.NewContext(), notM2, "m1", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if more || len(minions.Items) != 1 || minions.Items[0].ID != "m3" {
+		t.Errorf("Unexpected minion page: %#v, %v", minions, more)
+	}
+}
+
+func TestEtcdPodRequiresNamespace(t *testing.T) {
+	fakeClient := tools.NewFakeEtcdClient(t)
+	registry := NewTestEtcdRegistry(fakeClient)
 	ctx := api.NewContext()
+	if _, err := registry.GetPod(ctx, "foo"); err == nil {
+		t.Errorf("expected an error getting a pod without a namespace")
+	}
+	if err := registry.CreatePod(ctx, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}); err == nil {
+		t.Errorf("expected an error creating a pod without a namespace")
+	}
+	if err := registry.DeletePod(ctx, "foo"); err == nil {
+		t.Errorf("expected an error deleting a pod without a namespace")
+	}
+	if len(fakeClient.Data) != 0 {
+		t.Errorf("unexpected writes: %#v", fakeClient.Data)
+	}
+}
+
+func TestEtcdListPodsSelector(t *testing.T) {
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/controllers"
+	nodes := []*etcd.Node{}
+	for i := 0; i < 100; i++ {
+		pod := &api.Pod{
+			TypeMeta: api.TypeMeta{ID: fmt.Sprintf("pod%d", i)},
+			Labels: map[string]string{
+				"name": fmt.Sprintf("pod%d", i),
+				"tier": []string{"frontend", "backend", "cache", "db"}[i%4],
+			},
+		}
+		nodes = append(nodes, &etcd.Node{Value: runtime.EncodeOrDie(latest.Codec, pod)})
+	}
+	fakeClient.Data["/registry/pods/default"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Nodes: nodes}},
+	}
+	registry := NewTestEtcdRegistry(fakeClient)
+	ctx := api.NewDefaultContext()
+
+	table := map[string]int{
+		"":                       100,
+		"tier=backend":           25,
+		"tier!=backend":          75,
+		"tier=backend,name=pod5": 1,
+		"tier=backend,name=pod4": 0,
+		"tier=nonexistent":       0,
+		"name=pod42":             1,
+	}
+	for selector, expected := range table {
+		sel, err := labels.ParseSelector(selector)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", selector, err)
+		}
+		pods, err := registry.ListPods(ctx, sel)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", selector, err)
+		}
+		if len(pods.Items) != expected {
+			t.Errorf("%q: expected %d pods, got %d", selector, expected, len(pods.Items))
+		}
+		for _, pod := range pods.Items {
+			if !sel.Matches(labels.Set(pod.Labels)) {
+				t.Errorf("%q: unexpected pod %s with labels %v", selector, pod.ID, pod.Labels)
+			}
+		}
+	}
+}
+
+func TestEtcdListControllersNotFound(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	key := "/registry/controllers/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{},
 		E: tools.EtcdErrorNotFound,
@@ -687,9 +962,9 @@ func TestEtcdListControllersNotFound(t *testing.T) {
 }
 
 func TestEtcdListServicesNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/services/specs"
+	key := "/registry/services/specs/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{},
 		E: tools.EtcdErrorNotFound,
@@ -706,9 +981,9 @@ func TestEtcdListServicesNotFound(t *testing.T) {
 }
 
 func TestEtcdListControllers(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/controllers"
+	key := "/registry/controllers/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: &etcd.Node{
@@ -736,9 +1011,9 @@ func TestEtcdListControllers(t *testing.T) {
 }
 
 func TestEtcdGetController(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Set("/registry/controllers/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	fakeClient.Set("/registry/controllers/default/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
 	ctrl, err := registry.GetController(ctx, "foo")
 	if err != nil {
@@ -751,9 +1026,9 @@ func TestEtcdGetController(t *testing.T) {
 }
 
 func TestEtcdGetControllerNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Data["/registry/controllers/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/controllers/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -770,7 +1045,7 @@ func TestEtcdGetControllerNotFound(t *testing.T) {
 }
 
 func TestEtcdDeleteController(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.DeleteController(ctx, "foo")
@@ -781,14 +1056,14 @@ func TestEtcdDeleteController(t *testing.T) {
 	if len(fakeClient.DeletedKeys) != 1 {
 		t.Errorf("Expected 1 delete, found %#v", fakeClient.DeletedKeys)
 	}
-	key := "/registry/controllers/foo"
+	key := "/registry/controllers/default/foo"
 	if fakeClient.DeletedKeys[0] != key {
 		t.Errorf("Unexpected key: %s, expected %s", fakeClient.DeletedKeys[0], key)
 	}
 }
 
 func TestEtcdCreateController(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.CreateController(ctx, &api.ReplicationController{
@@ -800,7 +1075,7 @@ func TestEtcdCreateController(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	resp, err := fakeClient.Get("/registry/controllers/foo", false, false)
+	resp, err := fakeClient.Get("/registry/controllers/default/foo", false, false)
 	if err != nil {
 		t.Fatalf("Unexpected error %v", err)
 	}
@@ -816,9 +1091,9 @@ func TestEtcdCreateController(t *testing.T) {
 }
 
 func TestEtcdCreateControllerAlreadyExisting(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Set("/registry/controllers/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	fakeClient.Set("/registry/controllers/default/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.CreateController(ctx, &api.ReplicationController{
@@ -832,11 +1107,11 @@ func TestEtcdCreateControllerAlreadyExisting(t *testing.T) {
 }
 
 func TestEtcdUpdateController(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	resp, _ := fakeClient.Set("/registry/controllers/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	resp, _ := fakeClient.Set("/registry/controllers/default/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.UpdateController(ctx, &api.ReplicationController{
 		TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: strconv.FormatUint(resp.Node.ModifiedIndex, 10)},
@@ -854,10 +1129,45 @@ func TestEtcdUpdateController(t *testing.T) {
 	}
 }
 
+func TestEtcdUpdateControllerPreservesUID(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	created := util.Unix(1000, 0)
+	fakeClient.Set("/registry/controllers/default/foo", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{
+		TypeMeta: api.TypeMeta{ID: "foo", UID: "uid1", CreationTimestamp: created},
+	}), 0)
+	registry := NewTestEtcdRegistry(fakeClient)
+
+	// An update which does not send the UID back, as kubecfg update does.
+	resp, _ := fakeClient.Get("/registry/controllers/default/foo", false, false)
+	err := registry.UpdateController(ctx, &api.ReplicationController{
+		TypeMeta:     api.TypeMeta{ID: "foo", ResourceVersion: strconv.FormatUint(resp.Node.ModifiedIndex, 10)},
+		DesiredState: api.ReplicationControllerState{Replicas: 2},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctrl, err := registry.GetController(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctrl.UID != "uid1" || !ctrl.CreationTimestamp.Equal(created.Time) || ctrl.DesiredState.Replicas != 2 {
+		t.Errorf("Unexpected controller: %#v", ctrl)
+	}
+
+	err = registry.UpdateController(ctx, &api.ReplicationController{
+		TypeMeta: api.TypeMeta{ID: "foo", UID: "uid2", ResourceVersion: ctrl.ResourceVersion},
+	})
+	if !errors.IsInvalid(err) {
+		t.Errorf("Expected changing the UID to be invalid, got %#v", err)
+	}
+}
+
 func TestEtcdListServices(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/services/specs"
+	key := "/registry/services/specs/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: &etcd.Node{
@@ -885,7 +1195,7 @@ func TestEtcdListServices(t *testing.T) {
 }
 
 func TestEtcdCreateService(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.CreateService(ctx, &api.Service{
@@ -895,7 +1205,7 @@ func TestEtcdCreateService(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	resp, err := fakeClient.Get("/registry/services/specs/foo", false, false)
+	resp, err := fakeClient.Get("/registry/services/specs/default/foo", false, false)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -912,9 +1222,9 @@ func TestEtcdCreateService(t *testing.T) {
 }
 
 func TestEtcdCreateServiceAlreadyExisting(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Set("/registry/services/specs/foo", runtime.EncodeOrDie(latest.Codec, &api.Service{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	fakeClient.Set("/registry/services/specs/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Service{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.CreateService(ctx, &api.Service{
 		TypeMeta: api.TypeMeta{ID: "foo"},
@@ -925,9 +1235,9 @@ func TestEtcdCreateServiceAlreadyExisting(t *testing.T) {
 }
 
 func TestEtcdGetService(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Set("/registry/services/specs/foo", runtime.EncodeOrDie(latest.Codec, &api.Service{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	fakeClient.Set("/registry/services/specs/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Service{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
 	service, err := registry.GetService(ctx, "foo")
 	if err != nil {
@@ -940,9 +1250,9 @@ func TestEtcdGetService(t *testing.T) {
 }
 
 func TestEtcdGetServiceNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	fakeClient.Data["/registry/services/specs/foo"] = tools.EtcdResponseWithError{
+	fakeClient.Data["/registry/services/specs/default/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: nil,
 		},
@@ -956,7 +1266,7 @@ func TestEtcdGetServiceNotFound(t *testing.T) {
 }
 
 func TestEtcdDeleteService(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.DeleteService(ctx, "foo")
@@ -967,22 +1277,22 @@ func TestEtcdDeleteService(t *testing.T) {
 	if len(fakeClient.DeletedKeys) != 2 {
 		t.Errorf("Expected 2 delete, found %#v", fakeClient.DeletedKeys)
 	}
-	key := "/registry/services/specs/foo"
+	key := "/registry/services/specs/default/foo"
 	if fakeClient.DeletedKeys[0] != key {
 		t.Errorf("Unexpected key: %s, expected %s", fakeClient.DeletedKeys[0], key)
 	}
-	key = "/registry/services/endpoints/foo"
+	key = "/registry/services/endpoints/default/foo"
 	if fakeClient.DeletedKeys[1] != key {
 		t.Errorf("Unexpected key: %s, expected %s", fakeClient.DeletedKeys[1], key)
 	}
 }
 
 func TestEtcdUpdateService(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 
-	resp, _ := fakeClient.Set("/registry/services/specs/foo", runtime.EncodeOrDie(latest.Codec, &api.Service{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	resp, _ := fakeClient.Set("/registry/services/specs/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Service{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
 	testService := api.Service{
 		TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: strconv.FormatUint(resp.Node.ModifiedIndex, 10)},
@@ -1012,9 +1322,9 @@ func TestEtcdUpdateService(t *testing.T) {
 }
 
 func TestEtcdListEndpoints(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
-	key := "/registry/services/endpoints"
+	key := "/registry/services/endpoints/default"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
 			Node: &etcd.Node{
@@ -1042,7 +1352,7 @@ func TestEtcdListEndpoints(t *testing.T) {
 }
 
 func TestEtcdGetEndpoints(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	endpoints := &api.Endpoints{
@@ -1050,7 +1360,7 @@ func TestEtcdGetEndpoints(t *testing.T) {
 		Endpoints: []string{"127.0.0.1:34855"},
 	}
 
-	fakeClient.Set("/registry/services/endpoints/foo", runtime.EncodeOrDie(latest.Codec, endpoints), 0)
+	fakeClient.Set("/registry/services/endpoints/default/foo", runtime.EncodeOrDie(latest.Codec, endpoints), 0)
 
 	got, err := registry.GetEndpoints(ctx, "foo")
 	if err != nil {
@@ -1063,7 +1373,7 @@ func TestEtcdGetEndpoints(t *testing.T) {
 }
 
 func TestEtcdUpdateEndpoints(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
 	registry := NewTestEtcdRegistry(fakeClient)
@@ -1072,14 +1382,14 @@ func TestEtcdUpdateEndpoints(t *testing.T) {
 		Endpoints: []string{"baz", "bar"},
 	}
 
-	fakeClient.Set("/registry/services/endpoints/foo", runtime.EncodeOrDie(latest.Codec, &api.Endpoints{}), 0)
+	fakeClient.Set("/registry/services/endpoints/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Endpoints{}), 0)
 
 	err := registry.UpdateEndpoints(ctx, &endpoints)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	response, err := fakeClient.Get("/registry/services/endpoints/foo", false, false)
+	response, err := fakeClient.Get("/registry/services/endpoints/default/foo", false, false)
 	if err != nil {
 		t.Fatalf("Unexpected error %v", err)
 	}
@@ -1091,7 +1401,7 @@ func TestEtcdUpdateEndpoints(t *testing.T) {
 }
 
 func TestEtcdWatchServices(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	watching, err := registry.WatchServices(ctx,
@@ -1119,7 +1429,7 @@ func TestEtcdWatchServices(t *testing.T) {
 }
 
 func TestEtcdWatchServicesBadSelector(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	_, err := registry.WatchServices(
@@ -1144,7 +1454,7 @@ func TestEtcdWatchServicesBadSelector(t *testing.T) {
 }
 
 func TestEtcdWatchEndpoints(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	watching, err := registry.WatchEndpoints(
@@ -1173,7 +1483,7 @@ func TestEtcdWatchEndpoints(t *testing.T) {
 }
 
 func TestEtcdWatchEndpointsBadSelector(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	_, err := registry.WatchEndpoints(
@@ -1198,7 +1508,7 @@ func TestEtcdWatchEndpointsBadSelector(t *testing.T) {
 }
 
 func TestEtcdListMinions(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	key := "/registry/minions"
 	fakeClient.Data[key] = tools.EtcdResponseWithError{
@@ -1213,6 +1523,7 @@ func TestEtcdListMinions(t *testing.T) {
 					{
 						Value: runtime.EncodeOrDie(latest.Codec, &api.Minion{
 							TypeMeta: api.TypeMeta{ID: "bar"},
+							Labels:   map[string]string{"disk": "ssd"},
 						}),
 					},
 				},
@@ -1221,7 +1532,7 @@ func TestEtcdListMinions(t *testing.T) {
 		E: nil,
 	}
 	registry := NewTestEtcdRegistry(fakeClient)
-	minions, err := registry.ListMinions(ctx)
+	minions, err := registry.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -1229,10 +1540,18 @@ func TestEtcdListMinions(t *testing.T) {
 	if len(minions.Items) != 2 || minions.Items[0].ID != "foo" || minions.Items[1].ID != "bar" {
 		t.Errorf("Unexpected minion list: %#v", minions)
 	}
+
+	minions, err = registry.ListMinions(ctx, labels.SelectorFromSet(labels.Set{"disk": "ssd"}))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(minions.Items) != 1 || minions.Items[0].ID != "bar" {
+		t.Errorf("Unexpected minion list: %#v", minions)
+	}
 }
 
 func TestEtcdCreateMinion(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.CreateMinion(ctx, &api.Minion{
@@ -1258,8 +1577,38 @@ func TestEtcdCreateMinion(t *testing.T) {
 	}
 }
 
+func TestEtcdUpdateMinion(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	resp, _ := fakeClient.Set("/registry/minions/foo", runtime.EncodeOrDie(latest.Codec, &api.Minion{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	registry := NewTestEtcdRegistry(fakeClient)
+	err := registry.UpdateMinion(ctx, &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: strconv.FormatUint(resp.Node.ModifiedIndex, 10)},
+		Status:   api.NodeStatus{Conditions: []api.NodeCondition{{Type: api.NodeReady, Status: api.ConditionTrue}}},
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	minion, err := registry.GetMinion(ctx, "foo")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(minion.Status.Conditions) != 1 || minion.Status.Conditions[0].Type != api.NodeReady {
+		t.Errorf("Unexpected minion: %#v", minion)
+	}
+
+	err = registry.UpdateMinion(ctx, &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: strconv.FormatUint(resp.Node.ModifiedIndex, 10)},
+	})
+	if !errors.IsConflict(err) {
+		t.Errorf("expected a conflict for a stale resource version, got %v", err)
+	}
+}
+
 func TestEtcdGetMinion(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.Set("/registry/minions/foo", runtime.EncodeOrDie(latest.Codec, &api.Minion{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
 	registry := NewTestEtcdRegistry(fakeClient)
@@ -1274,7 +1623,7 @@ func TestEtcdGetMinion(t *testing.T) {
 }
 
 func TestEtcdGetMinionNotFound(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	fakeClient.Data["/registry/minions/foo"] = tools.EtcdResponseWithError{
 		R: &etcd.Response{
@@ -1291,7 +1640,7 @@ func TestEtcdGetMinionNotFound(t *testing.T) {
 }
 
 func TestEtcdDeleteMinion(t *testing.T) {
-	ctx := api.NewContext()
+	ctx := api.NewDefaultContext()
 	fakeClient := tools.NewFakeEtcdClient(t)
 	registry := NewTestEtcdRegistry(fakeClient)
 	err := registry.DeleteMinion(ctx, "foo")
@@ -0,0 +1,219 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	etcderr "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors/etcd"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+
+	"github.com/golang/glog"
+)
+
+// TxRegistry reads and writes pods and replication controllers within a
+// transaction, in the namespace the transaction was started in. Writes are
+// only visible to the transaction until it is committed.
+//
+// As with Registry.UpdatePod and Registry.UpdateController, an update must
+// carry the resource version of the object it replaces, as read through the
+// TxRegistry or outside of the transaction. An update without one fails with a
+// conflict if the object exists, unless the transaction created it.
+type TxRegistry interface {
+	GetPod(podID string) (*api.Pod, error)
+	CreatePod(pod *api.Pod) error
+	UpdatePod(pod *api.Pod) error
+	DeletePod(podID string) error
+
+	GetController(controllerID string) (*api.ReplicationController, error)
+	CreateController(controller *api.ReplicationController) error
+	UpdateController(controller *api.ReplicationController) error
+	DeleteController(controllerID string) error
+}
+
+// Transaction calls fn with a TxRegistry in the namespace of ctx, and commits
+// the writes fn made through it if fn returns nil. If fn returns an error,
+// nothing is written and the error is returned. If an object fn read or wrote
+// was changed by someone else in the meantime, the commit fails with a conflict
+// and nothing is written. See tools.EtcdTransaction for the guarantees of the
+// commit.
+func (r *Registry) Transaction(ctx api.Context, fn func(tx TxRegistry) error) error {
+	tx := &txRegistry{ctx, r.NewTransaction()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	err := tx.Commit()
+	if tools.IsEtcdTestFailed(err) || tools.IsEtcdNodeExist(err) {
+		return errors.NewConflict("transaction", "", fmt.Errorf("an object was changed concurrently"))
+	}
+	return err
+}
+
+type txRegistry struct {
+	ctx api.Context
+	*tools.EtcdTransaction
+}
+
+func (t *txRegistry) GetPod(podID string) (*api.Pod, error) {
+	key, err := makePodKey(t.ctx, podID)
+	if err != nil {
+		return nil, err
+	}
+	var pod api.Pod
+	if err := t.ExtractObj(key, &pod, false); err != nil {
+		return nil, etcderr.InterpretGetError(err, "pod", podID)
+	}
+	pod.CurrentState.Host = pod.DesiredState.Host
+	return &pod, nil
+}
+
+// CreatePod creates an unscheduled pod, like Registry.CreatePod.
+func (t *txRegistry) CreatePod(pod *api.Pod) error {
+	pod.CurrentState.Status = api.PodWaiting
+	pod.CurrentState.Host = ""
+	pod.DesiredState.Status = api.PodRunning
+	pod.DesiredState.Host = ""
+	key, err := makePodKey(t.ctx, pod.ID)
+	if err != nil {
+		return err
+	}
+	return etcderr.InterpretCreateError(t.CreateObj(key, pod), "pod", pod.ID)
+}
+
+// UpdatePod updates a pod, and the manifest of the pod on its host if it is
+// scheduled, like Registry.UpdatePod.
+func (t *txRegistry) UpdatePod(pod *api.Pod) error {
+	key, err := makePodKey(t.ctx, pod.ID)
+	if err != nil {
+		return err
+	}
+	var stored api.Pod
+	if err := t.ExtractObj(key, &stored, false); err != nil {
+		return etcderr.InterpretUpdateError(err, "pod", pod.ID)
+	}
+	scheduled := stored.DesiredState.Host != ""
+	if scheduled {
+		pod.DesiredState.Host = stored.DesiredState.Host
+		if errs := validation.ValidatePodUpdate(pod, &stored); len(errs) != 0 {
+			return errors.NewInvalid("Pod", pod.ID, errs)
+		}
+	}
+	if isNoopPodUpdate(pod, &stored) {
+		metrics.NoopUpdates.Inc("pods")
+		return nil
+	}
+	if err := t.SetObj(key, pod); err != nil {
+		return etcderr.InterpretUpdateError(err, "pod", pod.ID)
+	}
+	if !scheduled {
+		return nil
+	}
+	return t.updateManifests(stored.DesiredState.Host, func(manifests *api.ContainerManifestList) error {
+		for i := range manifests.Items {
+			if manifests.Items[i].ID == pod.ID {
+				manifests.Items[i] = pod.DesiredState.Manifest
+				return nil
+			}
+		}
+		return fmt.Errorf("Failed to update pod, couldn't find %s in %#v", pod.ID, manifests)
+	})
+}
+
+// DeletePod deletes a pod, and removes it from its host if it is scheduled,
+// like Registry.DeletePod.
+func (t *txRegistry) DeletePod(podID string) error {
+	key, err := makePodKey(t.ctx, podID)
+	if err != nil {
+		return err
+	}
+	var pod api.Pod
+	if err := t.ExtractObj(key, &pod, false); err != nil {
+		return etcderr.InterpretDeleteError(err, "pod", podID)
+	}
+	if err := t.Delete(key); err != nil {
+		return etcderr.InterpretDeleteError(err, "pod", podID)
+	}
+	if pod.DesiredState.Host == "" {
+		return nil
+	}
+	return t.updateManifests(pod.DesiredState.Host, func(manifests *api.ContainerManifestList) error {
+		items := make([]api.ContainerManifest, 0, len(manifests.Items))
+		for _, manifest := range manifests.Items {
+			if manifest.ID != podID {
+				items = append(items, manifest)
+			}
+		}
+		if len(items) == len(manifests.Items) {
+			glog.Warningf("Couldn't find: %s in %#v", podID, manifests)
+		}
+		manifests.Items = items
+		return nil
+	})
+}
+
+// updateManifests applies update to the manifests of the pods on machine.
+func (t *txRegistry) updateManifests(machine string, update func(*api.ContainerManifestList) error) error {
+	key := makeContainerKey(machine)
+	manifests := &api.ContainerManifestList{}
+	if err := t.ExtractObj(key, manifests, true); err != nil {
+		return err
+	}
+	if err := update(manifests); err != nil {
+		return err
+	}
+	return t.SetObj(key, manifests)
+}
+
+func (t *txRegistry) GetController(controllerID string) (*api.ReplicationController, error) {
+	key, err := makeControllerKey(t.ctx, controllerID)
+	if err != nil {
+		return nil, err
+	}
+	var controller api.ReplicationController
+	if err := t.ExtractObj(key, &controller, false); err != nil {
+		return nil, etcderr.InterpretGetError(err, "replicationController", controllerID)
+	}
+	return &controller, nil
+}
+
+func (t *txRegistry) CreateController(controller *api.ReplicationController) error {
+	key, err := makeControllerKey(t.ctx, controller.ID)
+	if err != nil {
+		return err
+	}
+	return etcderr.InterpretCreateError(t.CreateObj(key, controller), "replicationController", controller.ID)
+}
+
+func (t *txRegistry) UpdateController(controller *api.ReplicationController) error {
+	key, err := makeControllerKey(t.ctx, controller.ID)
+	if err != nil {
+		return err
+	}
+	return etcderr.InterpretUpdateError(t.SetObj(key, controller), "replicationController", controller.ID)
+}
+
+func (t *txRegistry) DeleteController(controllerID string) error {
+	key, err := makeControllerKey(t.ctx, controllerID)
+	if err != nil {
+		return err
+	}
+	return etcderr.InterpretDeleteError(t.Delete(key), "replicationController", controllerID)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+)
+
+func TestEtcdTransactionCommit(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/registry/pods/default/foo")
+	fakeClient.ExpectNotFoundGet("/registry/controllers/default/bar")
+	fakeClient.Set("/registry/pods/default/baz", runtime.EncodeOrDie(latest.Codec, &api.Pod{
+		TypeMeta:     api.TypeMeta{ID: "baz"},
+		DesiredState: api.PodState{Host: "machine"},
+	}), 0)
+	fakeClient.Set("/registry/hosts/machine/kubelet", runtime.EncodeOrDie(latest.Codec, &api.ContainerManifestList{
+		Items: []api.ContainerManifest{{ID: "baz"}, {ID: "other"}},
+	}), 0)
+	registry := NewTestEtcdRegistry(fakeClient)
+
+	err := registry.Transaction(ctx, func(tx TxRegistry) error {
+		if err := tx.CreatePod(&api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}); err != nil {
+			return err
+		}
+		if err := tx.CreateController(&api.ReplicationController{TypeMeta: api.TypeMeta{ID: "bar"}}); err != nil {
+			return err
+		}
+		if _, err := tx.GetPod("foo"); err != nil {
+			return err
+		}
+		return tx.DeletePod("baz")
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pod, err := registry.GetPod(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pod.DesiredState.Status != api.PodRunning || pod.CurrentState.Status != api.PodWaiting {
+		t.Errorf("Unexpected pod: %#v", pod)
+	}
+	if _, err := registry.GetController(ctx, "bar"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(fakeClient.DeletedKeys) != 1 || fakeClient.DeletedKeys[0] != "/registry/pods/default/baz" {
+		t.Errorf("Unexpected deletes: %#v", fakeClient.DeletedKeys)
+	}
+	var manifests api.ContainerManifestList
+	if err := latest.Codec.DecodeInto([]byte(fakeClient.Data["/registry/hosts/machine/kubelet"].R.Node.Value), &manifests); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(manifests.Items) != 1 || manifests.Items[0].ID != "other" {
+		t.Errorf("Unexpected manifests: %#v", manifests)
+	}
+}
+
+func TestEtcdTransactionAbort(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/registry/pods/default/foo")
+	fakeClient.ExpectNotFoundGet("/registry/controllers/default/bar")
+	registry := NewTestEtcdRegistry(fakeClient)
+
+	expected := fmt.Errorf("aborted")
+	err := registry.Transaction(ctx, func(tx TxRegistry) error {
+		if err := tx.CreatePod(&api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}); err != nil {
+			return err
+		}
+		if err := tx.DeleteController("bar"); !errors.IsNotFound(err) {
+			t.Errorf("Expected a not found error, got %#v", err)
+		}
+		return expected
+	})
+	if err != expected {
+		t.Errorf("Expected %v, got %v", expected, err)
+	}
+	if _, err := registry.GetPod(ctx, "foo"); !errors.IsNotFound(err) {
+		t.Errorf("Expected the pod not to be created, got %#v", err)
+	}
+}
+
+func TestEtcdTransactionConflict(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.ExpectNotFoundGet("/registry/controllers/default/bar")
+	fakeClient.Set("/registry/pods/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	registry := NewTestEtcdRegistry(fakeClient)
+
+	err := registry.Transaction(ctx, func(tx TxRegistry) error {
+		pod, err := tx.GetPod("foo")
+		if err != nil {
+			return err
+		}
+		// The pod changes after the transaction read it.
+		fakeClient.Set("/registry/pods/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+		return tx.CreateController(&api.ReplicationController{
+			TypeMeta:     api.TypeMeta{ID: "bar"},
+			DesiredState: api.ReplicationControllerState{ReplicaSelector: pod.Labels},
+		})
+	})
+	if !errors.IsConflict(err) {
+		t.Fatalf("Expected a conflict, got %#v", err)
+	}
+	if _, err := registry.GetController(ctx, "bar"); !errors.IsNotFound(err) {
+		t.Errorf("Expected the controller not to be created, got %#v", err)
+	}
+
+	// A write based on a stale read conflicts too.
+	err = registry.Transaction(ctx, func(tx TxRegistry) error {
+		return tx.UpdatePod(&api.Pod{TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: "1"}})
+	})
+	if !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict, got %#v", err)
+	}
+}
+
+func TestEtcdTransactionUpdateWithoutResourceVersion(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	fakeClient := tools.NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	fakeClient.Set("/registry/pods/default/foo", runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}), 0)
+	fakeClient.Set("/registry/controllers/default/bar", runtime.EncodeOrDie(latest.Codec, &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "bar"}}), 0)
+	registry := NewTestEtcdRegistry(fakeClient)
+
+	// Updates without a resource version conflict with the stored object, in a
+	// transaction as outside of one.
+	pod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}, Labels: map[string]string{"a": "b"}}
+	if err := registry.UpdatePod(ctx, pod); !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict, got %#v", err)
+	}
+	err := registry.Transaction(ctx, func(tx TxRegistry) error {
+		return tx.UpdatePod(pod)
+	})
+	if !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict, got %#v", err)
+	}
+
+	controller := &api.ReplicationController{TypeMeta: api.TypeMeta{ID: "bar"}, Labels: map[string]string{"a": "b"}}
+	if err := registry.UpdateController(ctx, controller); !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict, got %#v", err)
+	}
+	err = registry.Transaction(ctx, func(tx TxRegistry) error {
+		return tx.UpdateController(controller)
+	})
+	if !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict, got %#v", err)
+	}
+
+	// An object the transaction created has no resource version yet.
+	fakeClient.ExpectNotFoundGet("/registry/controllers/default/baz")
+	err = registry.Transaction(ctx, func(tx TxRegistry) error {
+		if err := tx.CreateController(&api.ReplicationController{TypeMeta: api.TypeMeta{ID: "baz"}}); err != nil {
+			return err
+		}
+		return tx.UpdateController(&api.ReplicationController{TypeMeta: api.TypeMeta{ID: "baz"}, Labels: map[string]string{"a": "b"}})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, err := registry.GetController(ctx, "baz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.Labels["a"] != "b" {
+		t.Errorf("Expected the update to be committed, got %#v", stored)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
+
+// LabelsFunc returns the labels of the object an event refers to.
+type LabelsFunc func(ctx api.Context, ref *api.ObjectReference) (labels.Set, error)
+
+type cachedLabels struct {
+	labels  labels.Set
+	expires time.Time
+}
+
+// labelCache remembers the labels of involved objects for a while, so that
+// filtering a list of events does not look up the same object once per event.
+type labelCache struct {
+	lookup LabelsFunc
+	ttl    time.Duration
+	now    func() time.Time
+
+	lock    sync.Mutex
+	entries map[api.ObjectReference]cachedLabels
+	// lastPrune is when the cache was last rid of its expired entries.
+	lastPrune time.Time
+}
+
+func newLabelCache(lookup LabelsFunc, ttl time.Duration) *labelCache {
+	return &labelCache{
+		lookup:  lookup,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[api.ObjectReference]cachedLabels{},
+	}
+}
+
+// Get returns the labels of the object ref refers to. Objects that cannot be
+// looked up have no labels, and are not looked up again until ttl has passed,
+// so that the events of deleted objects do not cost a lookup each.
+func (c *labelCache) Get(ctx api.Context, ref api.ObjectReference) labels.Set {
+	// Events about the same object differ only in the version and part of it they refer to.
+	key := api.ObjectReference{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}
+	now := c.now()
+
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	c.lock.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.labels
+	}
+	set, err := c.lookup(ctx, &key)
+	if err != nil {
+		set = labels.Set{}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries[key] = cachedLabels{labels: set, expires: now.Add(c.ttl)}
+	c.prune(now)
+	return set
+}
+
+// prune forgets the entries which expired before now, at most once per ttl.
+// The caller must hold lock.
+func (c *labelCache) prune(now time.Time) {
+	if now.Sub(c.lastPrune) < c.ttl {
+		return
+	}
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.lastPrune = now
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
+
+func TestLabelCache(t *testing.T) {
+	lookups := 0
+	cache := newLabelCache(func(ctx api.Context, ref *api.ObjectReference) (labels.Set, error) {
+		lookups++
+		if ref.Name != "foo" {
+			return nil, fmt.Errorf("not found")
+		}
+		return labels.Set{"app": "nginx", "lookup": fmt.Sprintf("%d", lookups)}, nil
+	}, time.Minute)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	ref := api.ObjectReference{Kind: "Pod", Name: "foo", ResourceVersion: "1"}
+	if e, a := (labels.Set{"app": "nginx", "lookup": "1"}), cache.Get(api.NewContext(), ref); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	// Another version of the same object is served from the cache.
+	ref.ResourceVersion = "2"
+	if e, a := (labels.Set{"app": "nginx", "lookup": "1"}), cache.Get(api.NewContext(), ref); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if e, a := (labels.Set{"app": "nginx", "lookup": "2"}), cache.Get(api.NewContext(), ref); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	// Objects which cannot be looked up have no labels until the ttl passes.
+	missing := api.ObjectReference{Kind: "Pod", Name: "bar"}
+	for i := 0; i < 2; i++ {
+		if e, a := (labels.Set{}), cache.Get(api.NewContext(), missing); !reflect.DeepEqual(e, a) {
+			t.Errorf("expected %v, got %v", e, a)
+		}
+	}
+	if lookups != 3 {
+		t.Errorf("expected 3 lookups, got %d", lookups)
+	}
+	now = now.Add(2 * time.Minute)
+	cache.Get(api.NewContext(), missing)
+	if lookups != 4 {
+		t.Errorf("expected the missing object to be looked up again, got %d lookups", lookups)
+	}
+}
+
+func TestLabelCachePrunes(t *testing.T) {
+	cache := newLabelCache(func(ctx api.Context, ref *api.ObjectReference) (labels.Set, error) {
+		return labels.Set{"name": ref.Name}, nil
+	}, time.Minute)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		cache.Get(api.NewContext(), api.ObjectReference{Kind: "Pod", Name: fmt.Sprintf("pod-%d", i)})
+	}
+	now = now.Add(2 * time.Minute)
+	cache.Get(api.NewContext(), api.ObjectReference{Kind: "Pod", Name: "other"})
+	if e, a := 1, len(cache.entries); e != a {
+		t.Errorf("expected the expired entries to be pruned, got %#v", cache.entries)
+	}
+}
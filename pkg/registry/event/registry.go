@@ -17,7 +17,11 @@ limitations under the License.
 package event
 
 import (
+	"errors"
+	"fmt"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	etcderr "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors/etcd"
@@ -25,34 +29,191 @@ import (
 	etcdgeneric "github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic/etcd"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 )
 
 // registry implements custom changes to generic.Etcd.
 type registry struct {
 	*etcdgeneric.Etcd
 	ttl uint64
+	// recent is nil if events are not aggregated.
+	recent *recentEvents
 }
 
+// errEventGone is returned by the update of an aggregated event which has
+// expired in the meantime.
+var errEventGone = errors.New("the event has expired")
+
 // Create stores the object with a ttl, so that events don't stay in the system forever.
+// If the event repeats an event stored within the aggregation window, the stored event
+// is counted once more instead, and the ID of obj is set to the ID of the stored event.
 func (r registry) Create(ctx api.Context, id string, obj runtime.Object) error {
-	err := r.Etcd.Helper.CreateObj(r.Etcd.KeyFunc(id), obj, r.ttl)
+	event, ok := obj.(*api.Event)
+	if !ok {
+		return fmt.Errorf("invalid object type")
+	}
+	if r.recent == nil {
+		return r.create(ctx, id, event)
+	}
+	now := time.Now()
+	key := aggregateKeyOf(event)
+	if recentID, ok := r.recent.get(key, now); ok {
+		err := r.aggregate(ctx, recentID, event)
+		if err == nil {
+			r.recent.set(key, recentID, now)
+			event.ID = recentID
+			return nil
+		}
+		if err != errEventGone {
+			return err
+		}
+	}
+	if err := r.create(ctx, id, event); err != nil {
+		return err
+	}
+	r.recent.set(key, id, now)
+	return nil
+}
+
+func (r registry) create(ctx api.Context, id string, event *api.Event) error {
+	key, err := r.Etcd.KeyFunc(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = r.Etcd.Helper.CreateObj(key, event, r.ttl)
 	return etcderr.InterpretCreateError(err, r.Etcd.EndpointName, id)
 }
 
+// aggregate adds the occurrences of event to the stored event id, and renews its ttl.
+func (r registry) aggregate(ctx api.Context, id string, event *api.Event) error {
+	key, err := r.Etcd.KeyFunc(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = r.Etcd.Helper.AtomicUpdateWithTTL(key, &api.Event{}, r.ttl, func(obj runtime.Object) (runtime.Object, error) {
+		stored := obj.(*api.Event)
+		if stored.ID == "" {
+			return nil, errEventGone
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		if stored.Count == 0 {
+			stored.Count = 1
+		}
+		stored.Count += count
+		stored.LastTimestamp = event.LastTimestamp
+		if stored.LastTimestamp.IsZero() {
+			stored.LastTimestamp = util.Now()
+		}
+		return stored, nil
+	})
+	if err == errEventGone {
+		return err
+	}
+	return etcderr.InterpretUpdateError(err, r.Etcd.EndpointName, id)
+}
+
+// aggregateKey identifies the events which are repeats of each other.
+type aggregateKey struct {
+	namespace string
+	source    string
+	reason    string
+	message   string
+	// The resource version of the involved object is left out, so that events
+	// about an object are aggregated across changes to it.
+	involvedObject api.ObjectReference
+}
+
+func aggregateKeyOf(event *api.Event) aggregateKey {
+	involvedObject := event.InvolvedObject
+	involvedObject.ResourceVersion = ""
+	return aggregateKey{
+		namespace:      event.Namespace,
+		source:         event.Source,
+		reason:         event.Reason,
+		message:        event.Message,
+		involvedObject: involvedObject,
+	}
+}
+
+// recentEvent is the ID of an event and when it last occurred.
+type recentEvent struct {
+	id   string
+	last time.Time
+}
+
+// recentEvents remembers the events which occurred within window, which new
+// events are aggregated into.
+type recentEvents struct {
+	window time.Duration
+
+	lock   sync.Mutex
+	events map[aggregateKey]recentEvent
+	// lastPrune is when events were last rid of the events older than window.
+	lastPrune time.Time
+}
+
+func newRecentEvents(window time.Duration) *recentEvents {
+	return &recentEvents{
+		window: window,
+		events: map[aggregateKey]recentEvent{},
+	}
+}
+
+// get returns the ID of the event identified by key, if it occurred within the
+// window before now.
+func (r *recentEvents) get(key aggregateKey, now time.Time) (string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	event, ok := r.events[key]
+	if !ok || now.Sub(event.last) > r.window {
+		return "", false
+	}
+	return event.id, true
+}
+
+// set records that the event id, identified by key, occurred at now. It also
+// forgets the events older than window, at most once per window.
+func (r *recentEvents) set(key aggregateKey, id string, now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events[key] = recentEvent{id: id, last: now}
+	if now.Sub(r.lastPrune) < r.window {
+		return
+	}
+	for key, event := range r.events {
+		if now.Sub(event.last) > r.window {
+			delete(r.events, key)
+		}
+	}
+	r.lastPrune = now
+}
+
 // NewEtcdRegistry returns a registry which will store Events in the given
 // EtcdHelper. ttl is the time that Events will be retained by the system.
-func NewEtcdRegistry(h tools.EtcdHelper, ttl uint64) generic.Registry {
-	return registry{
+// If aggregationWindow is not zero, an event which repeats an event created
+// within aggregationWindow before it is counted as another occurrence of that
+// event instead of being stored on its own.
+func NewEtcdRegistry(h tools.EtcdHelper, ttl uint64, aggregationWindow time.Duration) generic.Registry {
+	r := registry{
 		Etcd: &etcdgeneric.Etcd{
 			NewFunc:      func() runtime.Object { return &api.Event{} },
 			NewListFunc:  func() runtime.Object { return &api.EventList{} },
 			EndpointName: "events",
-			KeyRoot:      "/registry/events",
-			KeyFunc: func(id string) string {
-				return path.Join("/registry/events", id)
+			KeyRootFunc: func(ctx api.Context) string {
+				return "/events"
+			},
+			KeyFunc: func(ctx api.Context, id string) (string, error) {
+				return path.Join("/events", id), nil
 			},
 			Helper: h,
 		},
 		ttl: ttl,
 	}
+	if aggregationWindow > 0 {
+		r.recent = newRecentEvents(aggregationWindow)
+	}
+	return r
 }
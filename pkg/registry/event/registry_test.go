@@ -17,8 +17,11 @@ limitations under the License.
 package event
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
@@ -36,8 +39,8 @@ var testTTL uint64 = 60
 func NewTestEventEtcdRegistry(t *testing.T) (*tools.FakeEtcdClient, generic.Registry) {
 	f := tools.NewFakeEtcdClient(t)
 	f.TestIndex = true
-	h := tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}}
-	return f, NewEtcdRegistry(h, testTTL)
+	h := tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}, "/registry", tools.WatchConfig{}}
+	return f, NewEtcdRegistry(h, testTTL, 0)
 }
 
 func TestEventCreate(t *testing.T) {
@@ -103,3 +106,87 @@ func TestEventCreate(t *testing.T) {
 		}
 	}
 }
+
+func TestEventCreateAggregated(t *testing.T) {
+	f := tools.NewFakeEtcdClient(t)
+	f.TestIndex = true
+	h := tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}, "/registry", tools.WatchConfig{}}
+	registry := NewEtcdRegistry(h, testTTL, time.Minute)
+
+	newEvent := func(id, message string) *api.Event {
+		return &api.Event{
+			TypeMeta: api.TypeMeta{ID: id},
+			InvolvedObject: api.ObjectReference{
+				Kind:            "Pod",
+				Name:            "foo",
+				ResourceVersion: id,
+			},
+			Reason:  "imagePullBackoff",
+			Message: message,
+			Source:  "kubelet",
+			Count:   1,
+		}
+	}
+	for i := 0; i < 50; i++ {
+		event := newEvent(fmt.Sprintf("pull-%d", i), "Back-off pulling image")
+		if err := registry.Create(api.NewContext(), event.ID, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if event.ID != "pull-0" {
+			t.Errorf("expected the event to be aggregated into pull-0, got %s", event.ID)
+		}
+	}
+	other := newEvent("other", "Back-off pulling another image")
+	if err := registry.Create(api.NewContext(), other.ID, other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := []string{}
+	for key, data := range f.Data {
+		if data.R != nil && data.R.Node != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if e, a := []string{"/registry/events/other", "/registry/events/pull-0"}, keys; !reflect.DeepEqual(e, a) {
+		t.Fatalf("expected keys %v, got %v", e, a)
+	}
+	obj, err := registry.Get(api.NewContext(), "pull-0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := obj.(*api.Event).Count; count != 50 {
+		t.Errorf("expected a count of 50, got %d", count)
+	}
+	if f.LastSetTTL != testTTL {
+		t.Errorf("expected aggregated events to keep a TTL of %d, got %d", testTTL, f.LastSetTTL)
+	}
+
+	// The aggregated event expires.
+	delete(f.Data, "/registry/events/pull-0")
+	f.ExpectNotFoundGet("/registry/events/pull-0")
+	event := newEvent("pull-50", "Back-off pulling image")
+	if err := registry.Create(api.NewContext(), event.ID, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.ID != "pull-50" {
+		t.Errorf("expected a new event once the aggregated one expired, got %s", event.ID)
+	}
+}
+
+func TestRecentEventsWindow(t *testing.T) {
+	recent := newRecentEvents(time.Minute)
+	now := time.Unix(1000, 0)
+	a, b := aggregateKey{reason: "a"}, aggregateKey{reason: "b"}
+	recent.set(a, "a", now)
+	if id, ok := recent.get(a, now.Add(time.Minute)); !ok || id != "a" {
+		t.Errorf("expected the event to be recent within the window, got %q %v", id, ok)
+	}
+	if _, ok := recent.get(a, now.Add(time.Minute+time.Second)); ok {
+		t.Errorf("expected the event not to be recent after the window")
+	}
+	recent.set(b, "b", now.Add(2*time.Minute))
+	if _, ok := recent.events[a]; ok {
+		t.Errorf("expected the old event to be forgotten")
+	}
+}
@@ -18,6 +18,7 @@ package event
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
@@ -28,17 +29,27 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
 )
 
+// involvedObjectLabelsTTL is how long the labels of an involved object are cached.
+const involvedObjectLabelsTTL = 30 * time.Second
+
 // REST adapts an event registry into apiserver's RESTStorage model.
 type REST struct {
 	registry generic.Registry
+	labels   *labelCache
 }
 
 // NewREST returns a new REST. You must use a registry created by
-// NewEtcdRegistry unless you're testing.
-func NewREST(registry generic.Registry) *REST {
-	return &REST{
+// NewEtcdRegistry unless you're testing. If involvedObjectLabels is not nil,
+// events can be selected by the labels of the object they are about;
+// otherwise events have no labels.
+func NewREST(registry generic.Registry, involvedObjectLabels LabelsFunc) *REST {
+	rest := &REST{
 		registry: registry,
 	}
+	if involvedObjectLabels != nil {
+		rest.labels = newLabelCache(involvedObjectLabels, involvedObjectLabelsTTL)
+	}
+	return rest
 }
 
 func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
@@ -47,6 +58,15 @@ func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Obje
 		return nil, fmt.Errorf("invalid object type")
 	}
 	event.CreationTimestamp = util.Now()
+	if event.FirstTimestamp.IsZero() {
+		event.FirstTimestamp = event.CreationTimestamp
+	}
+	if event.LastTimestamp.IsZero() {
+		event.LastTimestamp = event.CreationTimestamp
+	}
+	if event.Count == 0 {
+		event.Count = 1
+	}
 
 	return apiserver.MakeAsync(func() (runtime.Object, error) {
 		err := rs.registry.Create(ctx, event.ID, event)
@@ -83,12 +103,26 @@ func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
 	return event, err
 }
 
-func (rs *REST) getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
-	event, ok := obj.(*api.Event)
-	if !ok {
-		return nil, nil, fmt.Errorf("invalid object type")
+// getAttrs returns the attributes of an event for the given request context.
+// The labels of an event are those of the object it is about; they are only
+// looked up if label selects on them.
+func (rs *REST) getAttrs(ctx api.Context, label labels.Selector) func(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+	return func(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+		event, ok := obj.(*api.Event)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid object type")
+		}
+		objLabels = labels.Set{}
+		if rs.labels != nil && !label.Empty() {
+			objLabels = rs.labels.Get(ctx, event.InvolvedObject)
+		}
+		return objLabels, eventFields(event), nil
 	}
-	return labels.Set{}, labels.Set{
+}
+
+// eventFields returns the fields of an event that can be selected on.
+func eventFields(event *api.Event) labels.Set {
+	return labels.Set{
 		"involvedObject.kind":            event.InvolvedObject.Kind,
 		"involvedObject.name":            event.InvolvedObject.Name,
 		"involvedObject.uid":             event.InvolvedObject.UID,
@@ -97,17 +131,39 @@ func (rs *REST) getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, e
 		"involvedObject.fieldPath":       event.InvolvedObject.FieldPath,
 		"status":                         event.Status,
 		"reason":                         event.Reason,
-	}, nil
+		"metadata.name":                  event.ID,
+		"metadata.namespace":             event.Namespace,
+	}
 }
 
 func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
-	return rs.registry.List(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs})
+	return rs.registry.List(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs(ctx, label)})
+}
+
+// ListPage lists the page of the events of List. If the registry is a
+// generic.Pager, only the events of the page are read.
+func (rs *REST) ListPage(ctx api.Context, label, field labels.Selector, page apiserver.ListPage) (runtime.Object, bool, error) {
+	pager, ok := rs.registry.(generic.Pager)
+	if !ok {
+		list, err := rs.List(ctx, label, field)
+		if err != nil {
+			return nil, false, err
+		}
+		more, err := page.Apply(list)
+		return list, more, err
+	}
+	namespace, _ := api.NamespaceFrom(ctx)
+	after, done := page.Cursor(namespace)
+	if done {
+		return &api.EventList{}, false, nil
+	}
+	return pager.ListPage(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs(ctx, label)}, after, page.Limit)
 }
 
 // Watch returns Events events via a watch.Interface.
 // It implements apiserver.ResourceWatcher.
 func (rs *REST) Watch(ctx api.Context, label, field labels.Selector, resourceVersion uint64) (watch.Interface, error) {
-	return rs.registry.Watch(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs}, resourceVersion)
+	return rs.registry.Watch(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs(ctx, label)}, resourceVersion)
 }
 
 // New returns a new api.Event
@@ -17,6 +17,7 @@ limitations under the License.
 package event
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -34,7 +35,7 @@ type testRegistry struct {
 
 func NewTestREST() (testRegistry, *REST) {
 	reg := testRegistry{registrytest.NewGeneric(nil)}
-	return reg, NewREST(reg)
+	return reg, NewREST(reg, nil)
 }
 
 func TestRESTCreate(t *testing.T) {
@@ -95,6 +96,7 @@ func TestRESTGet(t *testing.T) {
 func TestRESTgetAttrs(t *testing.T) {
 	_, rest := NewTestREST()
 	eventA := &api.Event{
+		TypeMeta: api.TypeMeta{ID: "a", Namespace: "default"},
 		InvolvedObject: api.ObjectReference{
 			Kind:            "Pod",
 			Name:            "foo",
@@ -106,7 +108,7 @@ func TestRESTgetAttrs(t *testing.T) {
 		Status: "tested",
 		Reason: "forTesting",
 	}
-	label, field, err := rest.getAttrs(eventA)
+	label, field, err := rest.getAttrs(api.NewContext(), labels.Everything())(eventA)
 	if err != nil {
 		t.Fatalf("Unexpected error %v", err)
 	}
@@ -122,6 +124,8 @@ func TestRESTgetAttrs(t *testing.T) {
 		"involvedObject.fieldPath":       "",
 		"status":                         "tested",
 		"reason":                         "forTesting",
+		"metadata.name":                  "a",
+		"metadata.namespace":             "default",
 	}
 	if e, a := expect, field; !reflect.DeepEqual(e, a) {
 		t.Errorf("diff: %s", util.ObjectDiff(e, a))
@@ -198,6 +202,59 @@ func TestRESTList(t *testing.T) {
 	}
 }
 
+func TestRESTListByInvolvedObjectLabels(t *testing.T) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	rest := NewREST(reg, func(ctx api.Context, ref *api.ObjectReference) (labels.Set, error) {
+		if ref.Kind == "Pod" && ref.Name == "foo" {
+			return labels.Set{"app": "nginx"}, nil
+		}
+		return nil, fmt.Errorf("not found")
+	})
+	eventA := &api.Event{
+		InvolvedObject: api.ObjectReference{Kind: "Pod", Name: "foo"},
+		Reason:         "forTesting",
+	}
+	eventB := &api.Event{
+		InvolvedObject: api.ObjectReference{Kind: "Pod", Name: "bar"},
+		Reason:         "forTesting",
+	}
+	reg.ObjectList = &api.EventList{
+		Items: []api.Event{*eventA, *eventB},
+	}
+	got, err := rest.List(api.NewContext(), labels.Set{"app": "nginx"}.AsSelector(), labels.Everything())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.EventList{
+		Items: []api.Event{*eventA},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTListWithoutLabelSelector(t *testing.T) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	lookups := 0
+	rest := NewREST(reg, func(ctx api.Context, ref *api.ObjectReference) (labels.Set, error) {
+		lookups++
+		return labels.Set{"app": "nginx"}, nil
+	})
+	reg.ObjectList = &api.EventList{
+		Items: []api.Event{{InvolvedObject: api.ObjectReference{Kind: "Pod", Name: "foo"}}},
+	}
+	got, err := rest.List(api.NewContext(), labels.Everything(), labels.Everything())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := reg.ObjectList, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+	if lookups != 0 {
+		t.Errorf("expected no label lookups without a label selector, got %d", lookups)
+	}
+}
+
 func TestRESTWatch(t *testing.T) {
 	eventA := &api.Event{
 		InvolvedObject: api.ObjectReference{
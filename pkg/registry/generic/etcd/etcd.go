@@ -17,14 +17,41 @@ limitations under the License.
 package etcd
 
 import (
+	"fmt"
+
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	etcderr "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors/etcd"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+
+	"code.google.com/p/go-uuid/uuid"
 )
 
+// NamespaceKeyRootFunc returns the key under which the items in the namespace
+// of ctx are stored, or prefix itself if ctx has no namespace, so that lists
+// and watches span every namespace.
+func NamespaceKeyRootFunc(ctx api.Context, prefix string) string {
+	if ns, ok := api.NamespaceFrom(ctx); ok && len(ns) > 0 {
+		return prefix + "/" + ns
+	}
+	return prefix
+}
+
+// NamespaceKeyFunc returns the key under which the item id in the namespace of
+// ctx is stored. ctx must have a namespace.
+func NamespaceKeyFunc(ctx api.Context, prefix string, id string) (string, error) {
+	ns, ok := api.NamespaceFrom(ctx)
+	if !ok || len(ns) == 0 {
+		return "", fmt.Errorf("a namespace is required to access %s/%s", prefix, id)
+	}
+	return prefix + "/" + ns + "/" + id, nil
+}
+
 // Etcd implements generic.Registry, backing it with etcd storage.
 // It's intended to be embeddable, so that you can implement any
 // non-generic functions if needed.
@@ -40,11 +67,12 @@ type Etcd struct {
 	// Used for error reporting
 	EndpointName string
 
-	// Used for listing/watching; should not include trailing "/"
-	KeyRoot string
+	// Returns the key which a context's items are listed and watched under;
+	// should not include trailing "/"
+	KeyRootFunc func(ctx api.Context) string
 
 	// Called for Create/Update/Get/Delete
-	KeyFunc func(id string) string
+	KeyFunc func(ctx api.Context, id string) (string, error)
 
 	// Used for all etcd access functions
 	Helper tools.EtcdHelper
@@ -53,30 +81,93 @@ type Etcd struct {
 // List returns a list of all the items matching m.
 func (e *Etcd) List(ctx api.Context, m generic.Matcher) (runtime.Object, error) {
 	list := e.NewListFunc()
-	err := e.Helper.ExtractToList(e.KeyRoot, list)
+	err := e.Helper.ExtractToList(e.KeyRootFunc(ctx), list)
 	if err != nil {
 		return nil, err
 	}
 	return generic.FilterList(list, m)
 }
 
-// Create inserts a new item.
+// CountObjects returns the number of items stored in every namespace; see
+// generic.Counter.
+func (e *Etcd) CountObjects() (map[string]int, error) {
+	count, err := e.Helper.CountList(e.KeyRootFunc(api.NewContext()))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{e.EndpointName: count}, nil
+}
+
+// ListPage returns a page of the items matching m; see generic.Pager. Only the
+// items of the page are decoded.
+func (e *Etcd) ListPage(ctx api.Context, m generic.Matcher, after string, limit int) (runtime.Object, bool, error) {
+	list := e.NewListFunc()
+	more, err := e.Helper.ExtractToListPage(e.KeyRootFunc(ctx), list, after, limit, m.Matches)
+	if err != nil {
+		return nil, false, err
+	}
+	return list, more, nil
+}
+
+// Create inserts a new item. Internal API objects must carry valid
+// annotations; they are stamped with their creation time and, unless they
+// already carry one, assigned a UID which stays with the object for its
+// whole lifetime.
 func (e *Etcd) Create(ctx api.Context, id string, obj runtime.Object) error {
-	err := e.Helper.CreateObj(e.KeyFunc(id), obj, 0)
+	if meta, err := api.TypeMetaFor(obj); err == nil {
+		if errs := validation.ValidateAnnotations(meta.Annotations).Prefix("annotations"); len(errs) > 0 {
+			return errors.NewInvalid(e.EndpointName, id, errs)
+		}
+		if len(meta.UID) == 0 {
+			meta.UID = uuid.NewUUID().String()
+		}
+		meta.CreationTimestamp = util.Now()
+	}
+	key, err := e.KeyFunc(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = e.Helper.CreateObj(key, obj, 0)
 	return etcderr.InterpretCreateError(err, e.EndpointName, id)
 }
 
-// Update updates the item.
+// Update updates the item. If obj carries a ResourceVersion, the write only
+// succeeds if the stored object matches that version as the ResourceVersionMatch
+// of ctx tells, by default if it has not been modified since that version.
+// Annotations are validated as in Create. The UID and creation timestamp of
+// a stored object may not be changed; an update that omits them keeps the
+// stored values.
 func (e *Etcd) Update(ctx api.Context, id string, obj runtime.Object) error {
-	// TODO: verify that SetObj checks ResourceVersion before succeeding.
-	err := e.Helper.SetObj(e.KeyFunc(id), obj)
+	key, err := e.KeyFunc(ctx, id)
+	if err != nil {
+		return err
+	}
+	if meta, err := api.TypeMetaFor(obj); err == nil {
+		if errs := validation.ValidateAnnotations(meta.Annotations).Prefix("annotations"); len(errs) > 0 {
+			return errors.NewInvalid(e.EndpointName, id, errs)
+		}
+		existing := e.NewFunc()
+		if err := e.Helper.ExtractObj(key, existing, true); err != nil {
+			return etcderr.InterpretGetError(err, e.EndpointName, id)
+		}
+		if old, err := api.TypeMetaFor(existing); err == nil {
+			if errs := generic.PreserveImmutableFields(meta, old); len(errs) > 0 {
+				return errors.NewInvalid(e.EndpointName, id, errs)
+			}
+		}
+	}
+	err = e.Helper.SetObjWithMatch(key, obj, api.ResourceVersionMatchFrom(ctx))
 	return etcderr.InterpretUpdateError(err, e.EndpointName, id)
 }
 
 // Get retrieves the item from etcd.
 func (e *Etcd) Get(ctx api.Context, id string) (runtime.Object, error) {
 	obj := e.NewFunc()
-	err := e.Helper.ExtractObj(e.KeyFunc(id), obj, false)
+	key, err := e.KeyFunc(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = e.Helper.ExtractObj(key, obj, false)
 	if err != nil {
 		return nil, etcderr.InterpretGetError(err, e.EndpointName, id)
 	}
@@ -85,14 +176,18 @@ func (e *Etcd) Get(ctx api.Context, id string) (runtime.Object, error) {
 
 // Delete removes the item from etcd.
 func (e *Etcd) Delete(ctx api.Context, id string) error {
-	err := e.Helper.Delete(e.KeyFunc(id), false)
+	key, err := e.KeyFunc(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = e.Helper.Delete(key, false)
 	return etcderr.InterpretDeleteError(err, e.EndpointName, id)
 }
 
 // Watch starts a watch for the items that m matches.
 // TODO: Detect if m references a single object instead of a list.
 func (e *Etcd) Watch(ctx api.Context, m generic.Matcher, resourceVersion uint64) (watch.Interface, error) {
-	return e.Helper.WatchList(e.KeyRoot, resourceVersion, func(obj runtime.Object) bool {
+	return e.Helper.WatchList(e.KeyRootFunc(ctx), resourceVersion, func(obj runtime.Object) bool {
 		matches, err := m.Matches(obj)
 		return err == nil && matches
 	})
@@ -21,6 +21,7 @@ import (
 	"path"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
@@ -36,14 +37,16 @@ import (
 func NewTestGenericEtcdRegistry(t *testing.T) (*tools.FakeEtcdClient, *Etcd) {
 	f := tools.NewFakeEtcdClient(t)
 	f.TestIndex = true
-	h := tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}}
+	h := tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}, "", tools.WatchConfig{}}
 	return f, &Etcd{
 		NewFunc:      func() runtime.Object { return &api.Pod{} },
 		NewListFunc:  func() runtime.Object { return &api.PodList{} },
 		EndpointName: "pods",
-		KeyRoot:      "/registry/pods",
-		KeyFunc: func(id string) string {
-			return path.Join("/registry/pods", id)
+		KeyRootFunc: func(ctx api.Context) string {
+			return "/registry/pods"
+		},
+		KeyFunc: func(ctx api.Context, id string) (string, error) {
+			return path.Join("/registry/pods", id), nil
 		},
 		Helper: h,
 	}
@@ -139,7 +142,7 @@ func TestEtcdList(t *testing.T) {
 
 	for name, item := range table {
 		fakeClient, registry := NewTestGenericEtcdRegistry(t)
-		fakeClient.Data[registry.KeyRoot] = item.in
+		fakeClient.Data[registry.KeyRootFunc(api.NewContext())] = item.in
 		list, err := registry.List(api.NewContext(), item.m)
 		if e, a := item.succeed, err == nil; e != a {
 			t.Errorf("%v: expected %v, got %v", name, e, a)
@@ -152,13 +155,76 @@ func TestEtcdList(t *testing.T) {
 	}
 }
 
+func TestEtcdCountObjects(t *testing.T) {
+	fakeClient, registry := NewTestGenericEtcdRegistry(t)
+	fakeClient.Data["/registry/pods"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{Dir: true, Nodes: []*etcd.Node{{Value: "a"}, {Value: "b"}}},
+					{Value: "c"},
+				},
+			},
+		},
+	}
+	counts, err := registry.CountObjects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := map[string]int{"pods": 3}, counts; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
+
+func TestEtcdListPage(t *testing.T) {
+	podA := &api.Pod{TypeMeta: api.TypeMeta{ID: "a"}}
+	podB := &api.Pod{TypeMeta: api.TypeMeta{ID: "b"}}
+	podC := &api.Pod{TypeMeta: api.TypeMeta{ID: "c"}}
+	fakeClient, registry := NewTestGenericEtcdRegistry(t)
+	fakeClient.Data["/registry/pods"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{Key: "/registry/pods/c", Value: runtime.EncodeOrDie(testapi.Codec(), podC)},
+					{Key: "/registry/pods/a", Value: runtime.EncodeOrDie(testapi.Codec(), podA)},
+					{Key: "/registry/pods/b", Value: runtime.EncodeOrDie(testapi.Codec(), podB)},
+				},
+			},
+		},
+	}
+
+	table := map[string]struct {
+		m     generic.Matcher
+		after string
+		limit int
+		out   runtime.Object
+		more  bool
+	}{
+		"first":    {EverythingMatcher{}, "", 1, &api.PodList{Items: []api.Pod{*podA}}, true},
+		"next":     {EverythingMatcher{}, "a", 1, &api.PodList{Items: []api.Pod{*podB}}, true},
+		"last":     {EverythingMatcher{}, "b", 1, &api.PodList{Items: []api.Pod{*podC}}, false},
+		"filtered": {SetMatcher{util.NewStringSet("a", "b")}, "a", 1, &api.PodList{Items: []api.Pod{*podB}}, false},
+		"rest":     {EverythingMatcher{}, "a", 0, &api.PodList{Items: []api.Pod{*podB, *podC}}, false},
+	}
+	for name, item := range table {
+		list, more, err := registry.ListPage(api.NewContext(), item.m, item.after, item.limit)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", name, err)
+			continue
+		}
+		if more != item.more || !reflect.DeepEqual(item.out, list) {
+			t.Errorf("%v: expected %#v, %v, got %#v, %v", name, item.out, item.more, list, more)
+		}
+	}
+}
+
 func TestEtcdCreate(t *testing.T) {
 	podA := &api.Pod{
-		TypeMeta:     api.TypeMeta{ID: "foo"},
+		TypeMeta:     api.TypeMeta{ID: "foo", UID: "a"},
 		DesiredState: api.PodState{Host: "machine"},
 	}
 	podB := &api.Pod{
-		TypeMeta:     api.TypeMeta{ID: "foo"},
+		TypeMeta:     api.TypeMeta{ID: "foo", UID: "a"},
 		DesiredState: api.PodState{Host: "machine2"},
 	}
 
@@ -209,12 +275,63 @@ func TestEtcdCreate(t *testing.T) {
 			t.Errorf("%v: unexpected error: %v", name, err)
 		}
 
-		if e, a := item.expect, fakeClient.Data[path]; !reflect.DeepEqual(e, a) {
+		a := fakeClient.Data[path]
+		if err == nil {
+			// The creation timestamp is set by the registry; check and clear it.
+			var created api.Pod
+			if err := testapi.Codec().DecodeInto([]byte(a.R.Node.Value), &created); err != nil {
+				t.Fatalf("%v: unexpected error: %v", name, err)
+			}
+			if created.CreationTimestamp.IsZero() {
+				t.Errorf("%v: expected creation timestamp to be set", name)
+			}
+			created.CreationTimestamp = util.Time{}
+			a.R.Node.Value = runtime.EncodeOrDie(testapi.Codec(), &created)
+		}
+		if e := item.expect; !reflect.DeepEqual(e, a) {
 			t.Errorf("%v:\n%s", name, util.ObjectDiff(e, a))
 		}
 	}
 }
 
+func TestEtcdCreateAssignsUID(t *testing.T) {
+	fakeClient, registry := NewTestGenericEtcdRegistry(t)
+	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{},
+		E: tools.EtcdErrorNotFound,
+	}
+	pod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
+	if err := registry.Create(api.NewContext(), "foo", pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pod.UID) == 0 {
+		t.Fatalf("expected a UID to be assigned")
+	}
+	obj, err := registry.Get(api.NewContext(), "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := pod.UID, obj.(*api.Pod).UID; e != a {
+		t.Errorf("expected stored UID %q, got %q", e, a)
+	}
+}
+
+func TestEtcdCreateInvalidAnnotations(t *testing.T) {
+	fakeClient, registry := NewTestGenericEtcdRegistry(t)
+	fakeClient.Data["/registry/pods/foo"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{},
+		E: tools.EtcdErrorNotFound,
+	}
+	pod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Annotations: map[string]string{"a b": "c"}}}
+	err := registry.Create(api.NewContext(), "foo", pod)
+	if !errors.IsInvalid(err) {
+		t.Fatalf("expected invalid error, got %v", err)
+	}
+	if fakeClient.Data["/registry/pods/foo"].R.Node != nil {
+		t.Errorf("expected nothing to be stored")
+	}
+}
+
 func TestEtcdUpdate(t *testing.T) {
 	podA := &api.Pod{
 		TypeMeta:     api.TypeMeta{ID: "foo"},
@@ -247,6 +364,46 @@ func TestEtcdUpdate(t *testing.T) {
 		E: nil,
 	}
 
+	nodeWithPodAModified := tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Value:         runtime.EncodeOrDie(testapi.Codec(), podA),
+				ModifiedIndex: 2,
+				CreatedIndex:  1,
+			},
+		},
+		E: nil,
+	}
+
+	created := util.Date(2014, time.November, 1, 0, 0, 0, 0, time.UTC)
+	nodeWithPodUIDA := tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Value: runtime.EncodeOrDie(testapi.Codec(), &api.Pod{
+					TypeMeta:     api.TypeMeta{ID: "foo", UID: "a", CreationTimestamp: created},
+					DesiredState: api.PodState{Host: "machine"},
+				}),
+				ModifiedIndex: 1,
+				CreatedIndex:  1,
+			},
+		},
+		E: nil,
+	}
+
+	nodeWithPodUIDB := tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Value: runtime.EncodeOrDie(testapi.Codec(), &api.Pod{
+					TypeMeta:     api.TypeMeta{ID: "foo", UID: "a", ResourceVersion: "1", CreationTimestamp: created},
+					DesiredState: api.PodState{Host: "machine2"},
+				}),
+				ModifiedIndex: 1,
+				CreatedIndex:  1,
+			},
+		},
+		E: nil,
+	}
+
 	emptyNode := tools.EtcdResponseWithError{
 		R: &etcd.Response{},
 		E: tools.EtcdErrorNotFound,
@@ -274,6 +431,55 @@ func TestEtcdUpdate(t *testing.T) {
 			// TODO: Should updating a non-existing thing fail?
 			errOK: func(err error) bool { return err == nil },
 		},
+		"staleVersion": {
+			existing: nodeWithPodAModified,
+			expect:   nodeWithPodAModified,
+			toUpdate: &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: "foo", ResourceVersion: "1"},
+				DesiredState: api.PodState{Host: "machine2"},
+			},
+			errOK: errors.IsConflict,
+		},
+		"changesCreationTimestamp": {
+			existing: nodeWithPodUIDA,
+			expect:   nodeWithPodUIDA,
+			toUpdate: &api.Pod{
+				TypeMeta: api.TypeMeta{
+					ID:                "foo",
+					ResourceVersion:   "1",
+					CreationTimestamp: util.Date(2014, time.December, 1, 0, 0, 0, 0, time.UTC),
+				},
+				DesiredState: api.PodState{Host: "machine2"},
+			},
+			errOK: errors.IsInvalid,
+		},
+		"invalidAnnotations": {
+			existing: nodeWithPodA,
+			expect:   nodeWithPodA,
+			toUpdate: &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: "foo", ResourceVersion: "1", Annotations: map[string]string{"a/b/c": "d"}},
+				DesiredState: api.PodState{Host: "machine2"},
+			},
+			errOK: errors.IsInvalid,
+		},
+		"keepsUID": {
+			existing: nodeWithPodUIDA,
+			expect:   nodeWithPodUIDB,
+			toUpdate: &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: "foo", ResourceVersion: "1"},
+				DesiredState: api.PodState{Host: "machine2"},
+			},
+			errOK: func(err error) bool { return err == nil },
+		},
+		"changesUID": {
+			existing: nodeWithPodUIDA,
+			expect:   nodeWithPodUIDA,
+			toUpdate: &api.Pod{
+				TypeMeta:     api.TypeMeta{ID: "foo", UID: "b", ResourceVersion: "1"},
+				DesiredState: api.PodState{Host: "machine2"},
+			},
+			errOK: errors.IsInvalid,
+		},
 	}
 
 	for name, item := range table {
@@ -436,3 +642,23 @@ func TestEtcdWatch(t *testing.T) {
 		t.Errorf("difference: %s", util.ObjectDiff(e, a))
 	}
 }
+
+func TestNamespaceKeyFuncs(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	if e, a := "/registry/things/default", NamespaceKeyRootFunc(ctx, "/registry/things"); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+	if e, a := "/registry/things", NamespaceKeyRootFunc(api.NewContext(), "/registry/things"); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+	key, err := NamespaceKeyFunc(ctx, "/registry/things", "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := "/registry/things/default/foo", key; e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+	if _, err := NamespaceKeyFunc(api.NewContext(), "/registry/things", "foo"); err == nil {
+		t.Errorf("expected an error without a namespace")
+	}
+}
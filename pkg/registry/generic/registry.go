@@ -18,6 +18,7 @@ package generic
 
 import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
@@ -79,6 +80,23 @@ type Registry interface {
 	Watch(ctx api.Context, m Matcher, resourceVersion uint64) (watch.Interface, error)
 }
 
+// Pager is implemented by registries which can list a page of their objects
+// without reading every object.
+type Pager interface {
+	// ListPage lists at most limit of the items matching m, in the order of
+	// their keys, starting after the key after, e.g. "default/foo" when
+	// listing every namespace. It returns true if more matching items follow.
+	ListPage(ctx api.Context, m Matcher, after string, limit int) (runtime.Object, bool, error)
+}
+
+// Counter is implemented by registries which can count the objects they store
+// without reading them.
+type Counter interface {
+	// CountObjects returns the number of objects stored in every namespace,
+	// by resource.
+	CountObjects() (map[string]int, error)
+}
+
 // FilterList filters any list object that conforms to the api conventions,
 // provided that 'm' works with the concrete type of list.
 func FilterList(list runtime.Object, m Matcher) (filtered runtime.Object, err error) {
@@ -104,3 +122,26 @@ func FilterList(list runtime.Object, m Matcher) (filtered runtime.Object, err er
 	}
 	return list, nil
 }
+
+// PreserveImmutableFields copies the UID and creation timestamp of old into
+// meta where meta leaves them unset, and reports any attempt to change them.
+// Registries call it on update, so that clients which do not send these fields
+// back do not clear them.
+func PreserveImmutableFields(meta, old *api.TypeMeta) errors.ErrorList {
+	allErrs := errors.ErrorList{}
+	if len(old.UID) != 0 {
+		if len(meta.UID) == 0 {
+			meta.UID = old.UID
+		} else if meta.UID != old.UID {
+			allErrs = append(allErrs, errors.NewFieldInvalid("uid", meta.UID))
+		}
+	}
+	if !old.CreationTimestamp.IsZero() {
+		if meta.CreationTimestamp.IsZero() {
+			meta.CreationTimestamp = old.CreationTimestamp
+		} else if !meta.CreationTimestamp.Rfc3339Copy().Equal(old.CreationTimestamp.Time) {
+			allErrs = append(allErrs, errors.NewFieldInvalid("creationTimestamp", meta.CreationTimestamp))
+		}
+	}
+	return allErrs
+}
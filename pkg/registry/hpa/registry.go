@@ -0,0 +1,47 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	etcdgeneric "github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic/etcd"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+)
+
+// autoscalerPrefix is the key under which horizontal pod autoscalers are
+// stored, by namespace.
+const autoscalerPrefix = "/horizontalpodautoscalers"
+
+// NewEtcdRegistry returns a registry which will store HorizontalPodAutoscalers
+// in the given EtcdHelper. Each autoscaler is stored under the key of its
+// namespace.
+func NewEtcdRegistry(h tools.EtcdHelper) generic.Registry {
+	return &etcdgeneric.Etcd{
+		NewFunc:      func() runtime.Object { return &api.HorizontalPodAutoscaler{} },
+		NewListFunc:  func() runtime.Object { return &api.HorizontalPodAutoscalerList{} },
+		EndpointName: "horizontalPodAutoscalers",
+		KeyRootFunc: func(ctx api.Context) string {
+			return etcdgeneric.NamespaceKeyRootFunc(ctx, autoscalerPrefix)
+		},
+		KeyFunc: func(ctx api.Context, id string) (string, error) {
+			return etcdgeneric.NamespaceKeyFunc(ctx, autoscalerPrefix, id)
+		},
+		Helper: h,
+	}
+}
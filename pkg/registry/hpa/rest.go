@@ -0,0 +1,159 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/controller"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// REST adapts an autoscaler registry into apiserver's RESTStorage model.
+// Autoscalers cannot be watched.
+type REST struct {
+	registry    generic.Registry
+	controllers controller.Registry
+}
+
+// NewREST returns a new REST. You must use a registry created by
+// NewEtcdRegistry unless you're testing. Autoscalers may only refer to
+// replication controllers stored in controllers.
+func NewREST(registry generic.Registry, controllers controller.Registry) *REST {
+	return &REST{
+		registry:    registry,
+		controllers: controllers,
+	}
+}
+
+// validate checks autoscaler and that the replication controller it scales exists.
+func (rs *REST) validate(ctx api.Context, autoscaler *api.HorizontalPodAutoscaler) error {
+	if errs := validation.ValidateHorizontalPodAutoscaler(autoscaler); len(errs) > 0 {
+		return errors.NewInvalid("horizontalPodAutoscaler", autoscaler.ID, errs)
+	}
+	name := autoscaler.Spec.ScaleTargetRef.Name
+	if _, err := rs.controllers.GetController(ctx, name); err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewInvalid("horizontalPodAutoscaler", autoscaler.ID, errors.ErrorList{
+				errors.NewFieldNotFound("spec.scaleTargetRef.name", name),
+			})
+		}
+		return err
+	}
+	return nil
+}
+
+// Create stores a new autoscaler. Any status it carries is discarded.
+func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	autoscaler, ok := obj.(*api.HorizontalPodAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &autoscaler.TypeMeta) {
+		return nil, errors.NewConflict("horizontalPodAutoscaler", autoscaler.Namespace, fmt.Errorf("HorizontalPodAutoscaler.Namespace does not match the provided context"))
+	}
+	if err := rs.validate(ctx, autoscaler); err != nil {
+		return nil, err
+	}
+	autoscaler.Status = api.HorizontalPodAutoscalerStatus{}
+	autoscaler.CreationTimestamp = util.Now()
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Create(ctx, autoscaler.ID, autoscaler)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, autoscaler.ID)
+	}), nil
+}
+
+func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	autoscaler, ok := obj.(*api.HorizontalPodAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &autoscaler.TypeMeta) {
+		return nil, errors.NewConflict("horizontalPodAutoscaler", autoscaler.Namespace, fmt.Errorf("HorizontalPodAutoscaler.Namespace does not match the provided context"))
+	}
+	if err := rs.validate(ctx, autoscaler); err != nil {
+		return nil, err
+	}
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Update(ctx, autoscaler.ID, autoscaler)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, autoscaler.ID)
+	}), nil
+}
+
+func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := obj.(*api.HorizontalPodAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, rs.registry.Delete(ctx, id)
+	}), nil
+}
+
+func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	autoscaler, ok := obj.(*api.HorizontalPodAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return autoscaler, err
+}
+
+// getAttrs returns the labels and fields of an autoscaler. Autoscalers have no
+// labels.
+func getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+	autoscaler, ok := obj.(*api.HorizontalPodAutoscaler)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid object type")
+	}
+	return labels.Set{}, labels.Set{
+		"metadata.name":            autoscaler.ID,
+		"metadata.namespace":       autoscaler.Namespace,
+		"spec.scaleTargetRef.name": autoscaler.Spec.ScaleTargetRef.Name,
+	}, nil
+}
+
+func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return rs.registry.List(ctx, &generic.SelectionPredicate{label, field, getAttrs})
+}
+
+// New returns a new api.HorizontalPodAutoscaler
+func (*REST) New() runtime.Object {
+	return &api.HorizontalPodAutoscaler{}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *registrytest.ControllerRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	controllers := &registrytest.ControllerRegistry{}
+	return reg, controllers, NewREST(reg, controllers)
+}
+
+func testAutoscaler(id string) *api.HorizontalPodAutoscaler {
+	return &api.HorizontalPodAutoscaler{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Spec: api.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef:       api.ObjectReference{Kind: "ReplicationController", Name: "frontend"},
+			MinReplicas:          1,
+			MaxReplicas:          5,
+			TargetCPUUtilization: 80,
+		},
+	}
+}
+
+func TestRESTCreate(t *testing.T) {
+	_, _, rest := NewTestREST()
+	autoscalerA := testAutoscaler("foo")
+	autoscalerA.Status.DesiredReplicas = 3
+	c, err := rest.Create(api.NewDefaultContext(), autoscalerA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	got := (<-c).(*api.HorizontalPodAutoscaler)
+	if e, a := testAutoscaler("foo").Spec, got.Spec; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+	if got.Status.DesiredReplicas != 0 {
+		t.Errorf("expected status to be reset, got %#v", got.Status)
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	_, _, rest := NewTestREST()
+	autoscalerA := testAutoscaler("foo")
+	autoscalerA.Spec.MaxReplicas = 0
+	_, err := rest.Create(api.NewDefaultContext(), autoscalerA)
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTCreateMissingController(t *testing.T) {
+	_, controllers, rest := NewTestREST()
+	controllers.Err = errors.NewNotFound("replicationController", "frontend")
+	_, err := rest.Create(api.NewDefaultContext(), testAutoscaler("foo"))
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTCreateWrongNamespace(t *testing.T) {
+	_, _, rest := NewTestREST()
+	autoscalerA := testAutoscaler("foo")
+	autoscalerA.Namespace = "other"
+	_, err := rest.Create(api.NewDefaultContext(), autoscalerA)
+	if !errors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestRESTUpdate(t *testing.T) {
+	_, _, rest := NewTestREST()
+	autoscalerA := testAutoscaler("foo")
+	c, err := rest.Create(api.NewDefaultContext(), autoscalerA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	autoscalerB := testAutoscaler("foo")
+	autoscalerB.Spec.MaxReplicas = 10
+	c, err = rest.Update(api.NewDefaultContext(), autoscalerB)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	got, err := rest.Get(api.NewDefaultContext(), autoscalerB.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := autoscalerB, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTDelete(t *testing.T) {
+	_, _, rest := NewTestREST()
+	autoscalerA := testAutoscaler("foo")
+	c, err := rest.Create(api.NewDefaultContext(), autoscalerA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	c, err = rest.Delete(api.NewDefaultContext(), autoscalerA.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if stat := (<-c).(*api.Status); stat.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %v", stat)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	reg, _, rest := NewTestREST()
+	other := testAutoscaler("bar")
+	other.Spec.ScaleTargetRef.Name = "backend"
+	reg.ObjectList = &api.HorizontalPodAutoscalerList{
+		Items: []api.HorizontalPodAutoscaler{*testAutoscaler("foo"), *other},
+	}
+	got, err := rest.List(api.NewDefaultContext(), labels.Everything(), labels.Set{"spec.scaleTargetRef.name": "frontend"}.AsSelector())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.HorizontalPodAutoscalerList{
+		Items: []api.HorizontalPodAutoscaler{*testAutoscaler("foo")},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/etcd"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/service"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// REST adapts an ingress registry into apiserver's RESTStorage model.
+type REST struct {
+	registry generic.Registry
+	services service.Registry
+}
+
+// NewREST returns a new REST. You must use a registry created by
+// NewEtcdRegistry unless you're testing. Ingresses may only route requests to
+// services stored in services.
+func NewREST(registry generic.Registry, services service.Registry) *REST {
+	return &REST{
+		registry: registry,
+		services: services,
+	}
+}
+
+// validate checks ingress and that every service its backends refer to exists
+// in its namespace and serves the port the backend names.
+func (rs *REST) validate(ctx api.Context, ingress *api.Ingress) error {
+	if errs := validation.ValidateIngress(ingress); len(errs) > 0 {
+		return errors.NewInvalid("ingress", ingress.ID, errs)
+	}
+	allErrs := errors.ErrorList{}
+	checkBackend := func(field string, backend *api.IngressBackend) error {
+		svc, err := rs.services.GetService(ctx, backend.ServiceName)
+		if errors.IsNotFound(err) {
+			allErrs = append(allErrs, errors.NewFieldNotFound(field+".serviceName", backend.ServiceName))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if svc.Port != backend.ServicePort {
+			allErrs = append(allErrs, errors.NewFieldInvalid(field+".servicePort", backend.ServicePort))
+		}
+		return nil
+	}
+	if ingress.Spec.Backend != nil {
+		if err := checkBackend("spec.backend", ingress.Spec.Backend); err != nil {
+			return err
+		}
+	}
+	for i, rule := range ingress.Spec.Rules {
+		for j := range rule.Paths {
+			field := fmt.Sprintf("spec.rules[%d].paths[%d].backend", i, j)
+			if err := checkBackend(field, &rule.Paths[j].Backend); err != nil {
+				return err
+			}
+		}
+	}
+	if len(allErrs) > 0 {
+		return errors.NewInvalid("ingress", ingress.ID, allErrs)
+	}
+	return nil
+}
+
+// Create stores a new ingress.
+func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	ingress, ok := obj.(*api.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &ingress.TypeMeta) {
+		return nil, errors.NewConflict("ingress", ingress.Namespace, fmt.Errorf("Ingress.Namespace does not match the provided context"))
+	}
+	if err := rs.validate(ctx, ingress); err != nil {
+		return nil, err
+	}
+	ingress.CreationTimestamp = util.Now()
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Create(ctx, ingress.ID, ingress)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, ingress.ID)
+	}), nil
+}
+
+func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	ingress, ok := obj.(*api.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &ingress.TypeMeta) {
+		return nil, errors.NewConflict("ingress", ingress.Namespace, fmt.Errorf("Ingress.Namespace does not match the provided context"))
+	}
+	if err := rs.validate(ctx, ingress); err != nil {
+		return nil, err
+	}
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Update(ctx, ingress.ID, ingress)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, ingress.ID)
+	}), nil
+}
+
+func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := obj.(*api.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, rs.registry.Delete(ctx, id)
+	}), nil
+}
+
+func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ingress, ok := obj.(*api.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return ingress, err
+}
+
+// getAttrs returns the labels and fields of an ingress.
+func getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+	ingress, ok := obj.(*api.Ingress)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid object type")
+	}
+	return labels.Set(ingress.Labels), labels.Set{
+		"metadata.name":      ingress.ID,
+		"metadata.namespace": ingress.Namespace,
+	}, nil
+}
+
+func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return rs.registry.List(ctx, &generic.SelectionPredicate{label, field, getAttrs})
+}
+
+// Watch returns the changes to the ingresses that match label and field,
+// from resourceVersion onwards.
+func (rs *REST) Watch(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
+	version, err := etcd.ParseWatchResourceVersion(resourceVersion, "ingress")
+	if err != nil {
+		return nil, err
+	}
+	return rs.registry.Watch(ctx, &generic.SelectionPredicate{label, field, getAttrs}, version)
+}
+
+// New returns a new api.Ingress
+func (*REST) New() runtime.Object {
+	return &api.Ingress{}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+// fakeServiceRegistry holds services by name.
+type fakeServiceRegistry struct {
+	registrytest.ServiceRegistry
+	services map[string]*api.Service
+}
+
+func (r *fakeServiceRegistry) GetService(ctx api.Context, id string) (*api.Service, error) {
+	svc, ok := r.services[id]
+	if !ok {
+		return nil, errors.NewNotFound("service", id)
+	}
+	return svc, nil
+}
+
+func NewTestREST() (testRegistry, *fakeServiceRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	services := &fakeServiceRegistry{
+		services: map[string]*api.Service{
+			"frontend": {TypeMeta: api.TypeMeta{ID: "frontend", Namespace: api.NamespaceDefault}, Port: 80},
+			"api":      {TypeMeta: api.TypeMeta{ID: "api", Namespace: api.NamespaceDefault}, Port: 8080},
+		},
+	}
+	return reg, services, NewREST(reg, services)
+}
+
+func testIngress(id string) *api.Ingress {
+	return &api.Ingress{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Labels:   map[string]string{"name": id},
+		Spec: api.IngressSpec{
+			Backend: &api.IngressBackend{ServiceName: "frontend", ServicePort: 80},
+			Rules: []api.IngressRule{{
+				Host:  "foo.example.com",
+				Paths: []api.IngressPath{{Path: "/api", Backend: api.IngressBackend{ServiceName: "api", ServicePort: 8080}}},
+			}},
+		},
+	}
+}
+
+func TestREST(t *testing.T) {
+	reg, _, rest := NewTestREST()
+	tester := &registrytest.RESTTester{
+		T:        t,
+		Storage:  rest,
+		Registry: reg.GenericRegistry,
+		New:      func(id string) runtime.Object { return testIngress(id) },
+		NewList:  func() runtime.Object { return &api.IngressList{} },
+	}
+	invalid := testIngress("foo")
+	invalid.Spec.Rules[0].Paths[0].Path = "api"
+	tester.Test(invalid)
+	tester.TestWatch()
+}
+
+func TestRESTCreateUnknownBackend(t *testing.T) {
+	table := map[string]struct {
+		mutate func(*api.Ingress)
+		field  string
+	}{
+		"missing default service": {
+			func(i *api.Ingress) { i.Spec.Backend.ServiceName = "missing" },
+			"spec.backend.serviceName",
+		},
+		"missing path service": {
+			func(i *api.Ingress) { i.Spec.Rules[0].Paths[0].Backend.ServiceName = "missing" },
+			"spec.rules[0].paths[0].backend.serviceName",
+		},
+		"wrong port": {
+			func(i *api.Ingress) { i.Spec.Rules[0].Paths[0].Backend.ServicePort = 80 },
+			"spec.rules[0].paths[0].backend.servicePort",
+		},
+	}
+	for name, item := range table {
+		_, _, rest := NewTestREST()
+		ingressA := testIngress("foo")
+		item.mutate(ingressA)
+		_, err := rest.Create(api.NewDefaultContext(), ingressA)
+		if !errors.IsInvalid(err) {
+			t.Errorf("%s: expected an invalid error, got %v", name, err)
+			continue
+		}
+		causes := err.(interface {
+			Status() api.Status
+		}).Status().Details.Causes
+		if len(causes) != 1 || causes[0].Field != item.field {
+			t.Errorf("%s: expected an error for %s, got %#v", name, item.field, causes)
+		}
+	}
+}
+
+func TestRESTUpdateUnknownBackend(t *testing.T) {
+	_, _, rest := NewTestREST()
+	ingressA := testIngress("foo")
+	ingressA.Spec.Backend.ServiceName = "missing"
+	_, err := rest.Update(api.NewDefaultContext(), ingressA)
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
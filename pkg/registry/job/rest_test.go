@@ -0,0 +1,86 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func testJob(id string) *api.Job {
+	return &api.Job{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Labels:   map[string]string{"name": id},
+		Spec: api.JobSpec{
+			Completions: 1,
+			Parallelism: 1,
+			Template: api.PodTemplate{
+				DesiredState: api.PodState{
+					Manifest: api.ContainerManifest{
+						Version:       "v1beta1",
+						RestartPolicy: api.RestartPolicy{Never: &api.RestartPolicyNever{}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestREST(t *testing.T) {
+	reg, rest := NewTestREST()
+	tester := &registrytest.RESTTester{
+		T:        t,
+		Storage:  rest,
+		Registry: reg.GenericRegistry,
+		New:      func(id string) runtime.Object { return testJob(id) },
+		NewList:  func() runtime.Object { return &api.JobList{} },
+	}
+	invalid := testJob("foo")
+	invalid.Spec.Completions = 0
+	tester.Test(invalid)
+}
+
+func TestRESTCreateResetsStatus(t *testing.T) {
+	_, rest := NewTestREST()
+	jobA := testJob("foo")
+	jobA.Status.Succeeded = 3
+	c, err := rest.Create(api.NewDefaultContext(), jobA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	got := (<-c).(*api.Job)
+	if e, a := testJob("foo").Spec, got.Spec; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+	if got.Status.Succeeded != 0 {
+		t.Errorf("expected status to be reset, got %#v", got.Status)
+	}
+}
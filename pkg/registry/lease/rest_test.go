@@ -0,0 +1,59 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lease
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func testLease(id string) *api.Lease {
+	return &api.Lease{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Spec: api.LeaseSpec{
+			HolderIdentity:       "holder",
+			LeaseDurationSeconds: 15,
+		},
+	}
+}
+
+func TestREST(t *testing.T) {
+	reg, rest := NewTestREST()
+	tester := &registrytest.RESTTester{
+		T:        t,
+		Storage:  rest,
+		Registry: reg.GenericRegistry,
+		New:      func(id string) runtime.Object { return testLease(id) },
+		NewList:  func() runtime.Object { return &api.LeaseList{} },
+	}
+	invalid := testLease("foo")
+	invalid.Spec.LeaseDurationSeconds = -1
+	tester.Test(invalid)
+	tester.TestWatch()
+}
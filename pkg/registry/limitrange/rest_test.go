@@ -0,0 +1,137 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limitrange
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func testLimitRange(id string) *api.LimitRange {
+	return &api.LimitRange{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Limits: []api.LimitRangeItem{{
+			Type: api.LimitTypeContainer,
+			Max:  api.ResourceList{"cpu": util.NewIntOrStringFromInt(1000)},
+		}},
+	}
+}
+
+func TestRESTCreate(t *testing.T) {
+	_, rest := NewTestREST()
+	limitRangeA := testLimitRange("foo")
+	c, err := rest.Create(api.NewDefaultContext(), limitRangeA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := limitRangeA, <-c; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	_, rest := NewTestREST()
+	limitRangeA := testLimitRange("foo")
+	limitRangeA.Limits[0].Type = "Service"
+	_, err := rest.Create(api.NewDefaultContext(), limitRangeA)
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTCreateWrongNamespace(t *testing.T) {
+	_, rest := NewTestREST()
+	limitRangeA := testLimitRange("foo")
+	limitRangeA.Namespace = "other"
+	_, err := rest.Create(api.NewDefaultContext(), limitRangeA)
+	if !errors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestRESTUpdate(t *testing.T) {
+	_, rest := NewTestREST()
+	limitRangeA := testLimitRange("foo")
+	c, err := rest.Create(api.NewDefaultContext(), limitRangeA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	limitRangeB := testLimitRange("foo")
+	limitRangeB.Limits = append(limitRangeB.Limits, api.LimitRangeItem{Type: api.LimitTypePod})
+	c, err = rest.Update(api.NewDefaultContext(), limitRangeB)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	got, err := rest.Get(api.NewDefaultContext(), limitRangeB.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := limitRangeB, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTDelete(t *testing.T) {
+	_, rest := NewTestREST()
+	limitRangeA := testLimitRange("foo")
+	c, err := rest.Create(api.NewDefaultContext(), limitRangeA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	c, err = rest.Delete(api.NewDefaultContext(), limitRangeA.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if stat := (<-c).(*api.Status); stat.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %v", stat)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	reg, rest := NewTestREST()
+	reg.ObjectList = &api.LimitRangeList{
+		Items: []api.LimitRange{*testLimitRange("foo"), *testLimitRange("bar")},
+	}
+	got, err := rest.List(api.NewDefaultContext(), labels.Everything(), labels.Set{"metadata.name": "foo"}.AsSelector())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.LimitRangeList{
+		Items: []api.LimitRange{*testLimitRange("foo")},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 )
 
 type Clock interface {
@@ -34,23 +35,38 @@ func (SystemClock) Now() time.Time {
 	return time.Now()
 }
 
+// CachingConfig holds how long a CachingRegistry may serve cached minions.
+type CachingConfig struct {
+	// GetTTL bounds the age of the cache used to answer GetMinion. Gets back
+	// scheduling decisions, so this should be short. If zero, ListTTL is used.
+	GetTTL time.Duration
+	// ListTTL bounds the age of the cache used to answer ListMinions.
+	ListTTL time.Duration
+}
+
 type CachingRegistry struct {
 	delegate   Registry
-	ttl        time.Duration
+	getTTL     time.Duration
+	listTTL    time.Duration
 	nodes      *api.MinionList
 	lastUpdate int64
 	lock       sync.RWMutex
 	clock      Clock
 }
 
-func NewCachingRegistry(delegate Registry, ttl time.Duration) (Registry, error) {
-	list, err := delegate.ListMinions(nil)
+func NewCachingRegistry(delegate Registry, config CachingConfig) (Registry, error) {
+	list, err := delegate.ListMinions(nil, labels.Everything())
 	if err != nil {
 		return nil, err
 	}
+	getTTL := config.GetTTL
+	if getTTL == 0 {
+		getTTL = config.ListTTL
+	}
 	return &CachingRegistry{
 		delegate:   delegate,
-		ttl:        ttl,
+		getTTL:     getTTL,
+		listTTL:    config.ListTTL,
 		nodes:      list,
 		lastUpdate: time.Now().Unix(),
 		clock:      SystemClock{},
@@ -58,8 +74,8 @@ func NewCachingRegistry(delegate Registry, ttl time.Duration) (Registry, error)
 }
 
 func (r *CachingRegistry) GetMinion(ctx api.Context, nodeID string) (*api.Minion, error) {
-	if r.expired() {
-		if err := r.refresh(ctx, false); err != nil {
+	if r.expired(r.getTTL) {
+		if err := r.refresh(ctx, r.getTTL, false); err != nil {
 			return nil, err
 		}
 	}
@@ -77,39 +93,55 @@ func (r *CachingRegistry) DeleteMinion(ctx api.Context, nodeID string) error {
 	if err := r.delegate.DeleteMinion(ctx, nodeID); err != nil {
 		return err
 	}
-	return r.refresh(ctx, true)
+	return r.refresh(ctx, 0, true)
 }
 
 func (r *CachingRegistry) CreateMinion(ctx api.Context, minion *api.Minion) error {
 	if err := r.delegate.CreateMinion(ctx, minion); err != nil {
 		return err
 	}
-	return r.refresh(ctx, true)
+	return r.refresh(ctx, 0, true)
+}
+
+func (r *CachingRegistry) UpdateMinion(ctx api.Context, minion *api.Minion) error {
+	if err := r.delegate.UpdateMinion(ctx, minion); err != nil {
+		return err
+	}
+	return r.refresh(ctx, 0, true)
 }
 
-func (r *CachingRegistry) ListMinions(ctx api.Context) (*api.MinionList, error) {
-	if r.expired() {
-		if err := r.refresh(ctx, false); err != nil {
+func (r *CachingRegistry) ListMinions(ctx api.Context, selector labels.Selector) (*api.MinionList, error) {
+	if r.expired(r.listTTL) {
+		if err := r.refresh(ctx, r.listTTL, false); err != nil {
 			return r.nodes, err
 		}
 	}
-	return r.nodes, nil
+	if selector.Empty() {
+		return r.nodes, nil
+	}
+	result := &api.MinionList{}
+	for _, minion := range r.nodes.Items {
+		if selector.Matches(labels.Set(minion.Labels)) {
+			result.Items = append(result.Items, minion)
+		}
+	}
+	return result, nil
 }
 
-func (r *CachingRegistry) expired() bool {
+func (r *CachingRegistry) expired(ttl time.Duration) bool {
 	var unix int64
 	atomic.SwapInt64(&unix, r.lastUpdate)
-	return r.clock.Now().Sub(time.Unix(r.lastUpdate, 0)) > r.ttl
+	return r.clock.Now().Sub(time.Unix(r.lastUpdate, 0)) > ttl
 }
 
 // refresh updates the current store.  It double checks expired under lock with the assumption
 // of optimistic concurrency with the other functions.
-func (r *CachingRegistry) refresh(ctx api.Context, force bool) error {
+func (r *CachingRegistry) refresh(ctx api.Context, ttl time.Duration, force bool) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	if force || r.expired() {
+	if force || r.expired(ttl) {
 		var err error
-		r.nodes, err = r.delegate.ListMinions(ctx)
+		r.nodes, err = r.delegate.ListMinions(ctx, labels.Everything())
 		time := r.clock.Now()
 		atomic.SwapInt64(&r.lastUpdate, time.Unix())
 		return err
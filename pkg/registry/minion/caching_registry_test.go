@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
 )
 
@@ -42,12 +43,13 @@ func TestCachingHit(t *testing.T) {
 	expected := registrytest.MakeMinionList([]string{"m1", "m2", "m3"}, api.NodeResources{})
 	cache := CachingRegistry{
 		delegate:   fakeRegistry,
-		ttl:        1 * time.Second,
+		getTTL:     1 * time.Second,
+		listTTL:    1 * time.Second,
 		clock:      &fakeClock,
 		lastUpdate: fakeClock.Now().Unix(),
 		nodes:      expected,
 	}
-	list, err := cache.ListMinions(ctx)
+	list, err := cache.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -65,13 +67,14 @@ func TestCachingMiss(t *testing.T) {
 	expected := registrytest.MakeMinionList([]string{"m1", "m2", "m3"}, api.NodeResources{})
 	cache := CachingRegistry{
 		delegate:   fakeRegistry,
-		ttl:        1 * time.Second,
+		getTTL:     1 * time.Second,
+		listTTL:    1 * time.Second,
 		clock:      &fakeClock,
 		lastUpdate: fakeClock.Now().Unix(),
 		nodes:      expected,
 	}
 	fakeClock.now = time.Unix(3, 0)
-	list, err := cache.ListMinions(ctx)
+	list, err := cache.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -89,7 +92,8 @@ func TestCachingInsert(t *testing.T) {
 	expected := registrytest.MakeMinionList([]string{"m1", "m2", "m3"}, api.NodeResources{})
 	cache := CachingRegistry{
 		delegate:   fakeRegistry,
-		ttl:        1 * time.Second,
+		getTTL:     1 * time.Second,
+		listTTL:    1 * time.Second,
 		clock:      &fakeClock,
 		lastUpdate: fakeClock.Now().Unix(),
 		nodes:      expected,
@@ -100,7 +104,7 @@ func TestCachingInsert(t *testing.T) {
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	list, err := cache.ListMinions(ctx)
+	list, err := cache.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -109,6 +113,36 @@ func TestCachingInsert(t *testing.T) {
 	}
 }
 
+func TestCachingUpdate(t *testing.T) {
+	ctx := api.NewContext()
+	fakeClock := fakeClock{
+		now: time.Unix(0, 0),
+	}
+	fakeRegistry := registrytest.NewMinionRegistry([]string{"m1", "m2"}, api.NodeResources{})
+	cache := CachingRegistry{
+		delegate:   fakeRegistry,
+		getTTL:     1 * time.Second,
+		listTTL:    1 * time.Second,
+		clock:      &fakeClock,
+		lastUpdate: fakeClock.Now().Unix(),
+		nodes:      registrytest.MakeMinionList([]string{"m1", "m2"}, api.NodeResources{}),
+	}
+	err := cache.UpdateMinion(ctx, &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "m2"},
+		Status:   api.NodeStatus{Conditions: []api.NodeCondition{{Type: api.NodeReady, Status: api.ConditionTrue}}},
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	minion, err := cache.GetMinion(ctx, "m2")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(minion, &fakeRegistry.Minions.Items[1]) {
+		t.Errorf("expected: %v, got %v", fakeRegistry.Minions.Items[1], minion)
+	}
+}
+
 func TestCachingDelete(t *testing.T) {
 	ctx := api.NewContext()
 	fakeClock := fakeClock{
@@ -118,7 +152,8 @@ func TestCachingDelete(t *testing.T) {
 	expected := registrytest.MakeMinionList([]string{"m1", "m2", "m3"}, api.NodeResources{})
 	cache := CachingRegistry{
 		delegate:   fakeRegistry,
-		ttl:        1 * time.Second,
+		getTTL:     1 * time.Second,
+		listTTL:    1 * time.Second,
 		clock:      &fakeClock,
 		lastUpdate: fakeClock.Now().Unix(),
 		nodes:      expected,
@@ -127,7 +162,7 @@ func TestCachingDelete(t *testing.T) {
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	list, err := cache.ListMinions(ctx)
+	list, err := cache.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -135,3 +170,33 @@ func TestCachingDelete(t *testing.T) {
 		t.Errorf("expected: %v, got %v", fakeRegistry.Minions, list)
 	}
 }
+
+func TestCachingGetTTL(t *testing.T) {
+	ctx := api.NewContext()
+	fakeClock := fakeClock{
+		now: time.Unix(0, 0),
+	}
+	fakeRegistry := registrytest.NewMinionRegistry([]string{"m1", "m2", "m3"}, api.NodeResources{})
+	cache := CachingRegistry{
+		delegate:   fakeRegistry,
+		getTTL:     1 * time.Second,
+		listTTL:    30 * time.Second,
+		clock:      &fakeClock,
+		lastUpdate: fakeClock.Now().Unix(),
+		nodes:      registrytest.MakeMinionList([]string{"m1", "m2"}, api.NodeResources{}),
+	}
+	if _, err := cache.GetMinion(ctx, "m3"); err != ErrDoesNotExist {
+		t.Errorf("expected a cache hit without m3, got %v", err)
+	}
+	fakeClock.now = time.Unix(3, 0)
+	list, err := cache.ListMinions(ctx, labels.Everything())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("expected the cached list to be within its TTL, got %v", list)
+	}
+	if _, err := cache.GetMinion(ctx, "m3"); err != nil {
+		t.Errorf("expected the get TTL to have expired, got %v", err)
+	}
+}
@@ -21,8 +21,23 @@ import (
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 )
 
+// ZoneLabel and RegionLabel carry the failure zone and region of the instance
+// backing a minion, so that pods can be spread across zones.
+const (
+	ZoneLabel   = "topology.kubernetes.io/zone"
+	RegionLabel = "topology.kubernetes.io/region"
+)
+
+// IsPreemptible returns true if minion is annotated as backed by an instance that
+// its cloud provider may reclaim at any time.
+func IsPreemptible(minion *api.Minion) bool {
+	return minion.Annotations[cloudprovider.GCEPreemptibleAnnotation] == "true" ||
+		minion.Annotations[cloudprovider.AWSCapacityTypeAnnotation] == "SPOT"
+}
+
 type CloudRegistry struct {
 	cloud           cloudprovider.Interface
 	matchRE         string
@@ -38,7 +53,7 @@ func NewCloudRegistry(cloud cloudprovider.Interface, matchRE string, staticResou
 }
 
 func (r *CloudRegistry) GetMinion(ctx api.Context, nodeID string) (*api.Minion, error) {
-	instances, err := r.ListMinions(ctx)
+	instances, err := r.ListMinions(ctx, labels.Everything())
 
 	if err != nil {
 		return nil, err
@@ -59,7 +74,11 @@ func (r CloudRegistry) CreateMinion(ctx api.Context, minion *api.Minion) error {
 	return fmt.Errorf("unsupported")
 }
 
-func (r *CloudRegistry) ListMinions(ctx api.Context) (*api.MinionList, error) {
+func (r CloudRegistry) UpdateMinion(ctx api.Context, minion *api.Minion) error {
+	return fmt.Errorf("unsupported")
+}
+
+func (r *CloudRegistry) ListMinions(ctx api.Context, selector labels.Selector) (*api.MinionList, error) {
 	instances, ok := r.cloud.Instances()
 	if !ok {
 		return nil, fmt.Errorf("cloud doesn't support instances")
@@ -68,6 +87,7 @@ func (r *CloudRegistry) ListMinions(ctx api.Context) (*api.MinionList, error) {
 	if err != nil {
 		return nil, err
 	}
+	annotationKey, annotationValue := instances.PreemptibleAnnotation()
 	result := &api.MinionList{
 		Items: make([]api.Minion, len(matches)),
 	}
@@ -83,6 +103,46 @@ func (r *CloudRegistry) ListMinions(ctx api.Context) (*api.MinionList, error) {
 		if resources != nil {
 			result.Items[ix].NodeResources = *resources
 		}
+		zone, err := instances.InstanceZone(matches[ix])
+		if err != nil {
+			return nil, err
+		}
+		result.Items[ix].Labels = zoneLabels(zone)
+		if len(annotationKey) == 0 {
+			continue
+		}
+		preemptible, err := instances.IsPreemptible(matches[ix])
+		if err != nil {
+			return nil, err
+		}
+		if preemptible {
+			result.Items[ix].Annotations = map[string]string{annotationKey: annotationValue}
+		}
+	}
+	if !selector.Empty() {
+		filtered := []api.Minion{}
+		for _, minion := range result.Items {
+			if selector.Matches(labels.Set(minion.Labels)) {
+				filtered = append(filtered, minion)
+			}
+		}
+		result.Items = filtered
 	}
 	return result, err
 }
+
+// zoneLabels returns the zone and region labels of a minion in zone, leaving
+// out those the cloud reports no value for.
+func zoneLabels(zone cloudprovider.Zone) map[string]string {
+	if len(zone.FailureDomain) == 0 && len(zone.Region) == 0 {
+		return nil
+	}
+	labels := map[string]string{}
+	if len(zone.FailureDomain) > 0 {
+		labels[ZoneLabel] = zone.FailureDomain
+	}
+	if len(zone.Region) > 0 {
+		labels[RegionLabel] = zone.Region
+	}
+	return labels
+}
@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
 	fake_cloud "github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider/fake"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
 )
 
@@ -36,7 +38,7 @@ func TestCloudList(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	list, err := registry.ListMinions(ctx)
+	list, err := registry.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -87,7 +89,7 @@ func TestCloudListRegexp(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	list, err := registry.ListMinions(ctx)
+	list, err := registry.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -97,3 +99,90 @@ func TestCloudListRegexp(t *testing.T) {
 		t.Errorf("Unexpected inequality: %#v, %#v", list, expectedList)
 	}
 }
+
+func TestCloudListPreemptible(t *testing.T) {
+	ctx := api.NewContext()
+	fakeCloud := fake_cloud.FakeCloud{
+		Machines:         []string{"m1", "m2"},
+		Preemptible:      map[string]bool{"m2": true},
+		PreemptibleKey:   cloudprovider.GCEPreemptibleAnnotation,
+		PreemptibleValue: "true",
+	}
+	registry, err := NewCloudRegistry(&fakeCloud, ".*", nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	list, err := registry.ListMinions(ctx, labels.Everything())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expectedList := registrytest.MakeMinionList([]string{"m1", "m2"}, api.NodeResources{})
+	expectedList.Items[1].Annotations = map[string]string{cloudprovider.GCEPreemptibleAnnotation: "true"}
+	if !reflect.DeepEqual(list, expectedList) {
+		t.Errorf("Unexpected inequality: %#v, %#v", list, expectedList)
+	}
+	if !IsPreemptible(&list.Items[1]) || IsPreemptible(&list.Items[0]) {
+		t.Errorf("Unexpected preemptible minions: %#v", list)
+	}
+
+	fakeCloud.PreemptibleKey, fakeCloud.PreemptibleValue = cloudprovider.AWSCapacityTypeAnnotation, "SPOT"
+	list, err = registry.ListMinions(ctx, labels.Everything())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expectedList.Items[1].Annotations = map[string]string{cloudprovider.AWSCapacityTypeAnnotation: "SPOT"}
+	if !reflect.DeepEqual(list, expectedList) {
+		t.Errorf("Unexpected inequality: %#v, %#v", list, expectedList)
+	}
+
+	fakeCloud.PreemptibleKey, fakeCloud.PreemptibleValue = "", ""
+	list, err = registry.ListMinions(ctx, labels.Everything())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if list.Items[1].Annotations != nil {
+		t.Errorf("Unexpected annotations for a provider without preemptible instances: %#v", list.Items[1])
+	}
+}
+
+func TestCloudListZones(t *testing.T) {
+	ctx := api.NewContext()
+	fakeCloud := fake_cloud.FakeCloud{
+		Machines: []string{"m1", "m2", "m3"},
+		Zone: cloudprovider.Zone{
+			FailureDomain: "us-central1-a",
+			Region:        "us-central1",
+		},
+		InstanceZones: map[string]cloudprovider.Zone{
+			"m1": {FailureDomain: "us-central1-b", Region: "us-central1"},
+			"m2": {FailureDomain: "us-central1-c", Region: "us-central1"},
+		},
+	}
+	registry, err := NewCloudRegistry(&fakeCloud, ".*", nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	list, err := registry.ListMinions(ctx, labels.Everything())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Each minion is labelled with the zone of its own instance, not that of the master.
+	expectedList := registrytest.MakeMinionList([]string{"m1", "m2", "m3"}, api.NodeResources{})
+	expectedList.Items[0].Labels = map[string]string{ZoneLabel: "us-central1-b", RegionLabel: "us-central1"}
+	expectedList.Items[1].Labels = map[string]string{ZoneLabel: "us-central1-c", RegionLabel: "us-central1"}
+	if !reflect.DeepEqual(list, expectedList) {
+		t.Errorf("Unexpected inequality: %#v, %#v", list, expectedList)
+	}
+
+	list, err = registry.ListMinions(ctx, labels.SelectorFromSet(labels.Set{ZoneLabel: "us-central1-c"}))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].ID != "m2" {
+		t.Errorf("Unexpected minions in zone us-central1-c: %#v", list)
+	}
+}
@@ -19,24 +19,53 @@ package minion
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/health"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 
 	"github.com/golang/glog"
 )
 
+// HealthyConfig holds how many health checks in a row decide the health of a
+// minion, so that a single check lost to a network blip doesn't take a minion
+// out of the healthy pool. Thresholds below 1 are taken to be 1.
+type HealthyConfig struct {
+	// FailureThreshold is how many checks in a row a healthy minion must fail
+	// to be considered unhealthy.
+	FailureThreshold int
+	// SuccessThreshold is how many checks in a row an unhealthy minion must pass
+	// to be considered healthy again.
+	SuccessThreshold int
+}
+
+// minionHealth is the health of a minion, and how many checks in a row have
+// disagreed with it.
+type minionHealth struct {
+	healthy bool
+	streak  int
+}
+
 type HealthyRegistry struct {
 	delegate Registry
 	client   health.HTTPGetInterface
 	port     int
+	config   HealthyConfig
+
+	// lock guards minions, the health of each minion which has been checked.
+	// Minions are healthy until they fail enough checks.
+	lock    sync.Mutex
+	minions map[string]*minionHealth
 }
 
-func NewHealthyRegistry(delegate Registry, client *http.Client) Registry {
+func NewHealthyRegistry(delegate Registry, client *http.Client, config HealthyConfig) Registry {
 	return &HealthyRegistry{
 		delegate: delegate,
 		client:   client,
 		port:     10250,
+		config:   config,
+		minions:  map[string]*minionHealth{},
 	}
 }
 
@@ -48,17 +77,16 @@ func (r *HealthyRegistry) GetMinion(ctx api.Context, minionID string) (*api.Mini
 	if err != nil {
 		return nil, err
 	}
-	status, err := health.DoHTTPCheck(r.makeMinionURL(minionID), r.client)
-	if err != nil {
-		return nil, err
-	}
-	if status == health.Unhealthy {
+	if !r.check(minionID) {
 		return nil, ErrNotHealty
 	}
 	return minion, nil
 }
 
 func (r *HealthyRegistry) DeleteMinion(ctx api.Context, minionID string) error {
+	r.lock.Lock()
+	delete(r.minions, minionID)
+	r.lock.Unlock()
 	return r.delegate.DeleteMinion(ctx, minionID)
 }
 
@@ -66,27 +94,85 @@ func (r *HealthyRegistry) CreateMinion(ctx api.Context, minion *api.Minion) erro
 	return r.delegate.CreateMinion(ctx, minion)
 }
 
-func (r *HealthyRegistry) ListMinions(ctx api.Context) (currentMinions *api.MinionList, err error) {
+func (r *HealthyRegistry) UpdateMinion(ctx api.Context, minion *api.Minion) error {
+	return r.delegate.UpdateMinion(ctx, minion)
+}
+
+func (r *HealthyRegistry) ListMinions(ctx api.Context, selector labels.Selector) (currentMinions *api.MinionList, err error) {
 	result := &api.MinionList{}
-	list, err := r.delegate.ListMinions(ctx)
+	list, err := r.delegate.ListMinions(ctx, selector)
 	if err != nil {
 		return result, err
 	}
 	for _, minion := range list.Items {
-		status, err := health.DoHTTPCheck(r.makeMinionURL(minion.ID), r.client)
-		if err != nil {
-			glog.Errorf("%#v failed health check with error: %s", minion, err)
-			continue
-		}
-		if status == health.Healthy {
+		if r.check(minion.ID) {
 			result.Items = append(result.Items, minion)
 		} else {
-			glog.Errorf("%s failed a health check, ignoring.", minion)
+			glog.Errorf("%s is unhealthy, ignoring.", minion.ID)
 		}
 	}
 	return result, nil
 }
 
+// ListMinionsPage lists a page of the healthy minions for which filter returns
+// true. Only the minions up to the end of the page are checked.
+func (r *HealthyRegistry) ListMinionsPage(ctx api.Context, filter func(*api.Minion) (bool, error), after string, limit int) (*api.MinionList, bool, error) {
+	return listMinionsPage(ctx, r.delegate, func(minion *api.Minion) (bool, error) {
+		if ok, err := filter(minion); !ok || err != nil {
+			return false, err
+		}
+		if !r.check(minion.ID) {
+			glog.Errorf("%s is unhealthy, ignoring.", minion.ID)
+			return false, nil
+		}
+		return true, nil
+	}, after, limit)
+}
+
+// check runs a health check of the minion, and returns whether the minion is
+// healthy in light of it.
+func (r *HealthyRegistry) check(minionID string) bool {
+	status, err := health.DoHTTPCheck(r.makeMinionURL(minionID), r.client)
+	if err != nil {
+		glog.Errorf("%s failed health check with error: %s", minionID, err)
+	}
+	return r.record(minionID, err == nil && status == health.Healthy)
+}
+
+// record counts a passed or failed check of the minion towards the threshold
+// which changes its health, and returns whether the minion is healthy.
+func (r *HealthyRegistry) record(minionID string, passed bool) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.minions == nil {
+		r.minions = map[string]*minionHealth{}
+	}
+	minion, ok := r.minions[minionID]
+	if !ok {
+		minion = &minionHealth{healthy: true}
+		r.minions[minionID] = minion
+	}
+	if passed == minion.healthy {
+		minion.streak = 0
+		return minion.healthy
+	}
+	minion.streak++
+	threshold := r.config.FailureThreshold
+	if !minion.healthy {
+		threshold = r.config.SuccessThreshold
+	}
+	if minion.streak >= threshold {
+		if passed {
+			glog.Infof("%s passed %d health checks in a row, it is healthy again.", minionID, minion.streak)
+		} else {
+			glog.Errorf("%s failed %d health checks in a row, it is unhealthy.", minionID, minion.streak)
+		}
+		minion.healthy = passed
+		minion.streak = 0
+	}
+	return minion.healthy
+}
+
 func (r *HealthyRegistry) makeMinionURL(minion string) string {
 	return fmt.Sprintf("http://%s:%d/healthz", minion, r.port)
 }
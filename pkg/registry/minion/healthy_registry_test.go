@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
 )
 
@@ -47,7 +48,7 @@ func TestBasicDelegation(t *testing.T) {
 		delegate: mockMinionRegistry,
 		client:   alwaysYes{},
 	}
-	list, err := healthy.ListMinions(ctx)
+	list, err := healthy.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -97,7 +98,7 @@ func TestFiltering(t *testing.T) {
 		port:     10250,
 	}
 	expected := []string{"m2", "m3"}
-	list, err := healthy.ListMinions(ctx)
+	list, err := healthy.ListMinions(ctx, labels.Everything())
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -112,3 +113,89 @@ func TestFiltering(t *testing.T) {
 		t.Errorf("Unexpected presence of 'm1'")
 	}
 }
+
+// toggledMinions fails the health checks of the minions in down.
+type toggledMinions struct {
+	down map[string]bool
+}
+
+func (c *toggledMinions) Get(url string) (*http.Response, error) {
+	for minion := range c.down {
+		if c.down[minion] && url == "http://"+minion+":10250/healthz" {
+			return fakeHTTPResponse(http.StatusInternalServerError), nil
+		}
+	}
+	return fakeHTTPResponse(http.StatusOK), nil
+}
+
+func TestHealthThresholds(t *testing.T) {
+	ctx := api.NewContext()
+	client := &toggledMinions{down: map[string]bool{}}
+	healthy := HealthyRegistry{
+		delegate: registrytest.NewMinionRegistry([]string{"m1", "m2"}, api.NodeResources{}),
+		client:   client,
+		port:     10250,
+		config:   HealthyConfig{FailureThreshold: 3, SuccessThreshold: 2},
+	}
+	listed := func() []string {
+		list, err := healthy.ListMinions(ctx, labels.Everything())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids := []string{}
+		for _, minion := range list.Items {
+			ids = append(ids, minion.ID)
+		}
+		return ids
+	}
+
+	client.down["m1"] = true
+	for i := 1; i < 3; i++ {
+		if ids := listed(); !reflect.DeepEqual(ids, []string{"m1", "m2"}) {
+			t.Errorf("%d failed checks: expected m1 to stay healthy, got %v", i, ids)
+		}
+	}
+	if ids := listed(); !reflect.DeepEqual(ids, []string{"m2"}) {
+		t.Errorf("3 failed checks: expected m1 to be unhealthy, got %v", ids)
+	}
+	if _, err := healthy.GetMinion(ctx, "m1"); err != ErrNotHealty {
+		t.Errorf("expected %v, got %v", ErrNotHealty, err)
+	}
+
+	// A passed check in between failed ones doesn't reinstate the minion.
+	client.down["m1"] = false
+	if ids := listed(); !reflect.DeepEqual(ids, []string{"m2"}) {
+		t.Errorf("1 passed check: expected m1 to stay unhealthy, got %v", ids)
+	}
+	client.down["m1"] = true
+	listed()
+	client.down["m1"] = false
+	if ids := listed(); !reflect.DeepEqual(ids, []string{"m2"}) {
+		t.Errorf("1 passed check after a failure: expected m1 to stay unhealthy, got %v", ids)
+	}
+	if minion, err := healthy.GetMinion(ctx, "m1"); err != nil || minion.ID != "m1" {
+		t.Errorf("2 passed checks: expected m1 to be healthy again, got %v %v", minion, err)
+	}
+
+	// A failed check in between passed ones resets the count of failures.
+	for _, down := range []bool{true, true, false, true, true} {
+		client.down["m1"] = down
+		if ids := listed(); !reflect.DeepEqual(ids, []string{"m1", "m2"}) {
+			t.Errorf("expected m1 to stay healthy while flapping, got %v", ids)
+		}
+	}
+}
+
+func TestHealthThresholdsDefault(t *testing.T) {
+	ctx := api.NewContext()
+	client := &toggledMinions{down: map[string]bool{"m1": true}}
+	healthy := NewHealthyRegistry(registrytest.NewMinionRegistry([]string{"m1"}, api.NodeResources{}), nil, HealthyConfig{}).(*HealthyRegistry)
+	healthy.client = client
+	if _, err := healthy.GetMinion(ctx, "m1"); err != ErrNotHealty {
+		t.Errorf("expected a minion to be unhealthy after a failed check, got %v", err)
+	}
+	client.down["m1"] = false
+	if _, err := healthy.GetMinion(ctx, "m1"); err != nil {
+		t.Errorf("expected a minion to be healthy after a passed check, got %v", err)
+	}
+}
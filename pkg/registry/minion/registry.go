@@ -16,12 +16,67 @@ limitations under the License.
 
 package minion
 
-import "github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+import (
+	"sort"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+)
 
 // MinionRegistry is an interface for things that know how to store minions.
 type Registry interface {
-	ListMinions(ctx api.Context) (*api.MinionList, error)
+	// ListMinions obtains a list of minions having labels which match selector.
+	ListMinions(ctx api.Context, selector labels.Selector) (*api.MinionList, error)
 	CreateMinion(ctx api.Context, minion *api.Minion) error
 	GetMinion(ctx api.Context, minionID string) (*api.Minion, error)
+	UpdateMinion(ctx api.Context, minion *api.Minion) error
 	DeleteMinion(ctx api.Context, minionID string) error
 }
+
+// Pager is implemented by registries which can list a page of minions without
+// reading every minion.
+type Pager interface {
+	// ListMinionsPage obtains at most limit minions for which filter returns
+	// true, ordered by id, starting after the minion called after. It returns
+	// true if more such minions follow.
+	ListMinionsPage(ctx api.Context, filter func(*api.Minion) (bool, error), after string, limit int) (*api.MinionList, bool, error)
+}
+
+// listMinionsPage lists a page of the minions of registry as Pager does,
+// reading every minion if registry is not a Pager.
+func listMinionsPage(ctx api.Context, registry Registry, filter func(*api.Minion) (bool, error), after string, limit int) (*api.MinionList, bool, error) {
+	if pager, ok := registry.(Pager); ok {
+		return pager.ListMinionsPage(ctx, filter, after, limit)
+	}
+	list, err := registry.ListMinions(ctx, labels.Everything())
+	if err != nil {
+		return nil, false, err
+	}
+	minions := make([]api.Minion, len(list.Items))
+	copy(minions, list.Items)
+	sort.Sort(byID(minions))
+	page := &api.MinionList{TypeMeta: list.TypeMeta}
+	for i := range minions {
+		if minions[i].ID <= after {
+			continue
+		}
+		ok, err := filter(&minions[i])
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		if limit > 0 && len(page.Items) == limit {
+			return page, true, nil
+		}
+		page.Items = append(page.Items, minions[i])
+	}
+	return page, false, nil
+}
+
+type byID []api.Minion
+
+func (s byID) Len() int           { return len(s) }
+func (s byID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byID) Less(i, j int) bool { return s[i].ID < s[j].ID }
@@ -20,6 +20,8 @@ import (
 	"fmt"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
@@ -49,6 +51,9 @@ func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Obje
 	if minion.ID == "" {
 		return nil, fmt.Errorf("ID should not be empty: %#v", minion)
 	}
+	if errs := validation.ValidateMinion(minion); len(errs) > 0 {
+		return nil, errors.NewInvalid("minion", minion.ID, errs)
+	}
 
 	minion.CreationTimestamp = util.Now()
 
@@ -90,13 +95,27 @@ func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
 }
 
 func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
-	return rs.registry.ListMinions(ctx)
+	return rs.registry.ListMinions(ctx, label)
+}
+
+// ListPage lists the page of the minions of List. If the registry is a Pager,
+// only the minions of the page are read.
+func (rs *REST) ListPage(ctx api.Context, label, field labels.Selector, page apiserver.ListPage) (runtime.Object, bool, error) {
+	after, _ := page.Cursor(api.NamespaceAll)
+	return listMinionsPage(ctx, rs.registry, func(minion *api.Minion) (bool, error) {
+		return label.Matches(labels.Set(minion.Labels)), nil
+	}, after, page.Limit)
 }
 
 func (rs *REST) New() runtime.Object {
 	return &api.Minion{}
 }
 
+// NamespaceScoped returns false, as minions are not in a namespace.
+func (rs *REST) NamespaceScoped() bool {
+	return false
+}
+
 func (rs *REST) Update(ctx api.Context, minion runtime.Object) (<-chan runtime.Object, error) {
 	return nil, fmt.Errorf("Minions can only be created (inserted) and deleted.")
 }
@@ -104,3 +123,76 @@ func (rs *REST) Update(ctx api.Context, minion runtime.Object) (<-chan runtime.O
 func (rs *REST) toApiMinion(name string) *api.Minion {
 	return &api.Minion{TypeMeta: api.TypeMeta{ID: name}}
 }
+
+// StatusREST implements the RESTStorage interface for the status subresource
+// of minions, through which kubelets report the conditions of their minion.
+// Only the status of a minion can be read and updated through it.
+type StatusREST struct {
+	registry Registry
+}
+
+// NewStatusREST returns a new StatusREST.
+func NewStatusREST(m Registry) *StatusREST {
+	return &StatusREST{
+		registry: m,
+	}
+}
+
+var ErrStatusOnly = fmt.Errorf("The status of a minion can only be read and updated.")
+
+func (rs *StatusREST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrStatusOnly
+}
+
+func (rs *StatusREST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	return nil, ErrStatusOnly
+}
+
+func (rs *StatusREST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	minion, err := rs.registry.GetMinion(ctx, id)
+	if minion == nil {
+		return nil, ErrDoesNotExist
+	}
+	return minion, err
+}
+
+func (rs *StatusREST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return nil, ErrStatusOnly
+}
+
+func (rs *StatusREST) New() runtime.Object {
+	return &api.Minion{}
+}
+
+// NamespaceScoped returns false, as minions are not in a namespace.
+func (rs *StatusREST) NamespaceScoped() bool {
+	return false
+}
+
+// Update replaces the status of the stored minion with the status of obj. The
+// rest of obj is ignored, except that if it carries a resource version, that
+// must be the resource version of the stored minion.
+func (rs *StatusREST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	minion, ok := obj.(*api.Minion)
+	if !ok {
+		return nil, fmt.Errorf("not a minion: %#v", obj)
+	}
+	if errs := validation.ValidateNodeStatus(&minion.Status); len(errs) > 0 {
+		return nil, errors.NewInvalid("minion", minion.ID, errs.Prefix("status"))
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		current, err := rs.registry.GetMinion(ctx, minion.ID)
+		if current == nil {
+			return nil, ErrDoesNotExist
+		}
+		if err != nil {
+			return nil, err
+		}
+		current.ResourceVersion = minion.ResourceVersion
+		current.Status = minion.Status
+		if err := rs.registry.UpdateMinion(ctx, current); err != nil {
+			return nil, err
+		}
+		return rs.registry.GetMinion(ctx, minion.ID)
+	}), nil
+}
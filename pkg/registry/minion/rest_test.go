@@ -17,9 +17,12 @@ limitations under the License.
 package minion
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
 )
@@ -83,6 +86,112 @@ func TestMinionREST(t *testing.T) {
 	}
 }
 
+func TestMinionRESTListPage(t *testing.T) {
+	// The registry is not a Pager, so every minion is read and the page cut
+	// from them.
+	ms := NewREST(registrytest.NewMinionRegistry([]string{"m3", "m1", "m2"}, api.NodeResources{}))
+	table := []struct {
+		page     apiserver.ListPage
+		expected []string
+		more     bool
+	}{
+		{apiserver.ListPage{Limit: 2}, []string{"m1", "m2"}, true},
+		{apiserver.ListPage{Limit: 2, ID: "m2"}, []string{"m3"}, false},
+		{apiserver.ListPage{ID: "m1"}, []string{"m2", "m3"}, false},
+	}
+	for _, item := range table {
+		obj, more, err := ms.ListPage(api.NewContext(), labels.Everything(), labels.Everything(), item.page)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids := []string{}
+		for _, minion := range obj.(*api.MinionList).Items {
+			ids = append(ids, minion.ID)
+		}
+		if !reflect.DeepEqual(item.expected, ids) || more != item.more {
+			t.Errorf("%#v: expected %v, %v, got %v, %v", item.page, item.expected, item.more, ids, more)
+		}
+	}
+}
+
+func TestMinionRESTValidatesAddresses(t *testing.T) {
+	ms := NewREST(registrytest.NewMinionRegistry([]string{}, api.NodeResources{}))
+	minion := &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Addresses: []api.NodeAddress{
+			{Type: api.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: api.NodeInternalIP, Address: "10.0.0.2"},
+		},
+	}
+	if _, err := ms.Create(api.NewContext(), minion); !errors.IsInvalid(err) {
+		t.Errorf("expected invalid error, got %v", err)
+	}
+}
+
+func TestMinionStatusREST(t *testing.T) {
+	registry := registrytest.NewMinionRegistry([]string{"foo"}, api.NodeResources{})
+	registry.Minions.Items[0].Labels = map[string]string{"name": "foo"}
+	ms := NewStatusREST(registry)
+	ctx := api.NewContext()
+
+	status := api.NodeStatus{
+		Conditions: []api.NodeCondition{{Type: api.NodeReady, Status: api.ConditionTrue}},
+	}
+	c, err := ms.Update(ctx, &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Labels:   map[string]string{"name": "bar"},
+		Status:   status,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj := <-c
+	if m, ok := obj.(*api.Minion); !ok || !reflect.DeepEqual(m.Status, status) {
+		t.Errorf("update return value was weird: %#v", obj)
+	}
+	obj, err = ms.Get(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	minion := obj.(*api.Minion)
+	if !reflect.DeepEqual(minion.Status, status) {
+		t.Errorf("expected status %#v, got %#v", status, minion.Status)
+	}
+	if minion.Labels["name"] != "foo" {
+		t.Errorf("expected only the status to be updated, got %#v", minion)
+	}
+
+	c, err = ms.Update(ctx, &api.Minion{TypeMeta: api.TypeMeta{ID: "baz"}, Status: status})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s, ok := (<-c).(*api.Status); !ok || s.Status != api.StatusFailure {
+		t.Errorf("expected the update of a missing minion to fail, got %#v", s)
+	}
+	if _, err := ms.Get(ctx, "baz"); err != ErrDoesNotExist {
+		t.Errorf("expected %v, got %v", ErrDoesNotExist, err)
+	}
+	if _, err := ms.Create(ctx, &api.Minion{TypeMeta: api.TypeMeta{ID: "baz"}}); err != ErrStatusOnly {
+		t.Errorf("expected %v, got %v", ErrStatusOnly, err)
+	}
+	if _, err := ms.Delete(ctx, "foo"); err != ErrStatusOnly {
+		t.Errorf("expected %v, got %v", ErrStatusOnly, err)
+	}
+}
+
+func TestMinionStatusRESTValidatesConditions(t *testing.T) {
+	ms := NewStatusREST(registrytest.NewMinionRegistry([]string{"foo"}, api.NodeResources{}))
+	minion := &api.Minion{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Status: api.NodeStatus{
+			Conditions: []api.NodeCondition{{Type: api.NodeReady, Status: "Full"}},
+		},
+	}
+	if _, err := ms.Update(api.NewContext(), minion); !errors.IsInvalid(err) {
+		t.Errorf("expected invalid error, got %v", err)
+	}
+}
+
 func contains(nodes *api.MinionList, nodeID string) bool {
 	for _, node := range nodes.Items {
 		if node.ID == nodeID {
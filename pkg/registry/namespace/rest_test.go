@@ -0,0 +1,93 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func TestRESTCreate(t *testing.T) {
+	_, rest := NewTestREST()
+	namespaceA := &api.Namespace{TypeMeta: api.TypeMeta{ID: "foo"}}
+	c, err := rest.Create(api.NewDefaultContext(), namespaceA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := namespaceA, <-c; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	_, rest := NewTestREST()
+	_, err := rest.Create(api.NewDefaultContext(), &api.Namespace{TypeMeta: api.TypeMeta{ID: "a b"}})
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTDelete(t *testing.T) {
+	_, rest := NewTestREST()
+	namespaceA := &api.Namespace{TypeMeta: api.TypeMeta{ID: "foo"}}
+	c, err := rest.Create(api.NewDefaultContext(), namespaceA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	c, err = rest.Delete(api.NewDefaultContext(), namespaceA.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if stat := (<-c).(*api.Status); stat.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %v", stat)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	reg, rest := NewTestREST()
+	namespaceA := api.Namespace{TypeMeta: api.TypeMeta{ID: "foo"}, Labels: map[string]string{"team": "a"}}
+	namespaceB := api.Namespace{TypeMeta: api.TypeMeta{ID: "bar"}, Labels: map[string]string{"team": "b"}}
+	reg.ObjectList = &api.NamespaceList{
+		Items: []api.Namespace{namespaceA, namespaceB},
+	}
+	got, err := rest.List(api.NewContext(), labels.Set{"team": "b"}.AsSelector(), labels.Everything())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.NamespaceList{
+		Items: []api.Namespace{namespaceB},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
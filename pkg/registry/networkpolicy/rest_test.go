@@ -0,0 +1,62 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func testNetworkPolicy(id string) *api.NetworkPolicy {
+	return &api.NetworkPolicy{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Spec: api.NetworkPolicySpec{
+			PodSelector: "name=db",
+			Ingress: []api.NetworkPolicyIngressRule{{
+				Ports: []api.NetworkPolicyPort{{Protocol: "TCP", Port: 5432}},
+				From:  []api.NetworkPolicyPeer{{PodSelector: "name=" + id}},
+			}},
+		},
+	}
+}
+
+func TestREST(t *testing.T) {
+	reg, rest := NewTestREST()
+	tester := &registrytest.RESTTester{
+		T:        t,
+		Storage:  rest,
+		Registry: reg.GenericRegistry,
+		New:      func(id string) runtime.Object { return testNetworkPolicy(id) },
+		NewList:  func() runtime.Object { return &api.NetworkPolicyList{} },
+	}
+	invalid := testNetworkPolicy("foo")
+	invalid.Spec.Ingress[0].Ports[0].Port = 0
+	tester.Test(invalid)
+	tester.TestWatch()
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package pod
 
 import (
+	"strings"
+
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/service"
 )
@@ -39,6 +41,20 @@ func (b *BasicManifestFactory) MakeManifest(machine string, pod api.Pod) (api.Co
 	for ix, container := range pod.DesiredState.Manifest.Containers {
 		pod.DesiredState.Manifest.ID = pod.ID
 		pod.DesiredState.Manifest.Containers[ix].Env = append(container.Env, envVars...)
+		if len(container.ImageDigest) != 0 {
+			pod.DesiredState.Manifest.Containers[ix].Image = digestedImage(container.Image, container.ImageDigest)
+		}
 	}
 	return pod.DesiredState.Manifest, nil
 }
+
+// digestedImage replaces the tag of image, if any, with digest, so that the kubelet
+// runs exactly the image that was pinned even if the tag is later moved.
+// For example "registry:5000/foo:v1" becomes "registry:5000/foo@sha256:...".
+func digestedImage(image, digest string) string {
+	repo := image
+	if ix := strings.LastIndex(image, ":"); ix > strings.LastIndex(image, "/") {
+		repo = image[:ix]
+	}
+	return repo + "@" + digest
+}
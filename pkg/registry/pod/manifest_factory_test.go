@@ -18,6 +18,7 @@ package pod
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
@@ -227,3 +228,34 @@ func TestMakeManifestServicesExistingEnvVar(t *testing.T) {
 		}
 	}
 }
+
+func TestMakeManifestImageDigest(t *testing.T) {
+	registry := registrytest.ServiceRegistry{}
+	factory := &BasicManifestFactory{
+		ServiceRegistry: &registry,
+	}
+	digest := "sha256:" + strings.Repeat("ab", 32)
+
+	manifest, err := factory.MakeManifest("machine", api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foobar"},
+		DesiredState: api.PodState{
+			Manifest: api.ContainerManifest{
+				Containers: []api.Container{
+					{Name: "tagged", Image: "registry:5000/foo:v1", ImageDigest: digest},
+					{Name: "untagged", Image: "foo", ImageDigest: digest},
+					{Name: "unpinned", Image: "foo:v1"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := []string{"registry:5000/foo@" + digest, "foo@" + digest, "foo:v1"}
+	for i, container := range manifest.Containers {
+		if container.Image != expected[i] {
+			t.Errorf("%s: expected %q, got %q", container.Name, expected[i], container.Image)
+		}
+	}
+}
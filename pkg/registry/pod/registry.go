@@ -39,3 +39,13 @@ type Registry interface {
 	// Delete an existing pod
 	DeletePod(ctx api.Context, podID string) error
 }
+
+// Pager is implemented by registries which can list a page of pods without
+// reading every pod.
+type Pager interface {
+	// ListPodsPage obtains at most limit pods for which filter returns true,
+	// in the order of their keys, starting after the key after, e.g.
+	// "default/foo" when listing every namespace. It returns true if more
+	// such pods follow.
+	ListPodsPage(ctx api.Context, filter func(*api.Pod) (bool, error), after string, limit int) (*api.PodList, bool, error)
+}
@@ -18,16 +18,25 @@ package pod
 
 import (
 	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/minion"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
@@ -36,6 +45,18 @@ import (
 	"github.com/golang/glog"
 )
 
+// StaleCacheAnnotation is set to "true" on the pods served with container
+// information which the pod cache has not updated for a while.
+const StaleCacheAnnotation = "kubernetes.io/stale-cache"
+
+// PodInfoAger is implemented by pod caches which know how long ago they
+// updated the information of a pod.
+type PodInfoAger interface {
+	// PodInfoAge returns how long ago the information of a pod was updated, or
+	// false if there is none.
+	PodInfoAge(namespace, id string) (time.Duration, bool)
+}
+
 type ipCacheEntry struct {
 	ip         string
 	lastUpdate time.Time
@@ -64,6 +85,12 @@ type REST struct {
 	minions       client.MinionInterface
 	ipCache       ipCache
 	clock         clock
+	// Pods whose cached information is older than this are annotated as stale.
+	// Zero means never.
+	staleCacheThreshold time.Duration
+	configMaps          generic.Registry
+	secrets             generic.Registry
+	events              generic.Registry
 }
 
 type RESTConfig struct {
@@ -72,19 +99,33 @@ type RESTConfig struct {
 	PodInfoGetter client.PodInfoGetter
 	Registry      Registry
 	Minions       client.MinionInterface
+	// If set and PodCache is a PodInfoAger, pods whose cached information is
+	// older than this are served with StaleCacheAnnotation.
+	StaleCacheThreshold time.Duration
+	// If set along with Events, a warning event is recorded for each container
+	// environment variable of a new pod whose config map or secret key does not
+	// exist. The pod is created anyway, so that it may be created before the
+	// objects it refers to.
+	ConfigMaps generic.Registry
+	Secrets    generic.Registry
+	Events     generic.Registry
 }
 
 // NewREST returns a new REST.
 func NewREST(config *RESTConfig) *REST {
 	return &REST{
-		cloudProvider: config.CloudProvider,
-		podCache:      config.PodCache,
-		podInfoGetter: config.PodInfoGetter,
-		podPollPeriod: time.Second * 10,
-		registry:      config.Registry,
-		minions:       config.Minions,
-		ipCache:       ipCache{},
-		clock:         realClock{},
+		cloudProvider:       config.CloudProvider,
+		podCache:            config.PodCache,
+		podInfoGetter:       config.PodInfoGetter,
+		podPollPeriod:       time.Second * 10,
+		registry:            config.Registry,
+		minions:             config.Minions,
+		ipCache:             ipCache{},
+		clock:               realClock{},
+		staleCacheThreshold: config.StaleCacheThreshold,
+		configMaps:          config.ConfigMaps,
+		secrets:             config.Secrets,
+		events:              config.Events,
 	}
 }
 
@@ -107,10 +148,83 @@ func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Obje
 		if err := rs.registry.CreatePod(ctx, pod); err != nil {
 			return nil, err
 		}
+		rs.warnMissingEnvSources(ctx, pod)
 		return rs.registry.GetPod(ctx, pod.ID)
 	}), nil
 }
 
+// warnMissingEnvSources records a warning event for every environment
+// variable of pod whose config map or secret key does not exist. Errors other
+// than a missing object are ignored, since the pod is already stored.
+func (rs *REST) warnMissingEnvSources(ctx api.Context, pod *api.Pod) {
+	if rs.events == nil {
+		return
+	}
+	for _, container := range pod.DesiredState.Manifest.Containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			var reason, message string
+			switch {
+			case env.ValueFrom.ConfigMapKeyRef != nil && rs.configMaps != nil:
+				ref := env.ValueFrom.ConfigMapKeyRef
+				obj, err := rs.configMaps.Get(ctx, ref.Name)
+				if configMap, ok := obj.(*api.ConfigMap); err == nil && ok {
+					if _, ok := configMap.Data[ref.Key]; !ok {
+						reason, message = "ConfigMapKeyNotFound", fmt.Sprintf("config map %q used by container %q has no key %q", ref.Name, container.Name, ref.Key)
+					}
+				} else if errors.IsNotFound(err) {
+					reason, message = "ConfigMapNotFound", fmt.Sprintf("config map %q used by container %q does not exist", ref.Name, container.Name)
+				}
+			case env.ValueFrom.SecretKeyRef != nil && rs.secrets != nil:
+				ref := env.ValueFrom.SecretKeyRef
+				obj, err := rs.secrets.Get(ctx, ref.Name)
+				if secret, ok := obj.(*api.Secret); err == nil && ok {
+					if _, ok := secret.Data[ref.Key]; !ok {
+						reason, message = "SecretKeyNotFound", fmt.Sprintf("secret %q used by container %q has no key %q", ref.Name, container.Name, ref.Key)
+					}
+				} else if errors.IsNotFound(err) {
+					reason, message = "SecretNotFound", fmt.Sprintf("secret %q used by container %q does not exist", ref.Name, container.Name)
+				}
+			}
+			if len(reason) != 0 {
+				rs.recordEvent(ctx, pod, reason, message)
+			}
+		}
+	}
+}
+
+// recordEvent records a warning about pod, which is waiting for reason.
+func (rs *REST) recordEvent(ctx api.Context, pod *api.Pod, reason, message string) {
+	glog.Infof("Pod %s: %s", pod.ID, message)
+	now := util.Now()
+	event := &api.Event{
+		TypeMeta: api.TypeMeta{
+			ID:                uuid.NewUUID().String(),
+			Namespace:         pod.Namespace,
+			CreationTimestamp: now,
+		},
+		InvolvedObject: api.ObjectReference{
+			Kind:       "Pod",
+			Namespace:  pod.Namespace,
+			Name:       pod.ID,
+			UID:        pod.UID,
+			APIVersion: latest.Version,
+		},
+		Status:         string(api.PodWaiting),
+		Reason:         reason,
+		Message:        message,
+		Source:         "apiserver",
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if err := rs.events.Create(ctx, event.ID, event); err != nil {
+		glog.Errorf("Couldn't record an event for pod %s: %v", pod.ID, err)
+	}
+}
+
 func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
 	return apiserver.MakeAsync(func() (runtime.Object, error) {
 		return &api.Status{Status: api.StatusSuccess}, rs.registry.DeletePod(ctx, id)
@@ -141,9 +255,12 @@ func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
 
 func (rs *REST) podToSelectableFields(pod *api.Pod) labels.Set {
 	return labels.Set{
-		"ID": pod.ID,
+		"ID":                  pod.ID,
 		"DesiredState.Status": string(pod.DesiredState.Status),
 		"DesiredState.Host":   pod.DesiredState.Host,
+		"metadata.name":       pod.ID,
+		"metadata.namespace":  pod.Namespace,
+		"status.phase":        string(pod.CurrentState.Status),
 	}
 }
 
@@ -156,23 +273,68 @@ func (rs *REST) filterFunc(label, field labels.Selector) func(*api.Pod) bool {
 	}
 }
 
+// List returns the pods matching label and field. Fields are matched after the
+// current status of each pod has been filled in, so "status.phase" selects on the
+// status that is returned.
 func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
-	pods, err := rs.registry.ListPodsPredicate(ctx, rs.filterFunc(label, field))
-	if err == nil {
-		for i := range pods.Items {
-			pod := &pods.Items[i]
-			rs.fillPodInfo(pod)
-			status, err := getPodStatus(pod, rs.minions)
-			if err != nil {
-				return pod, err
-			}
-			pod.CurrentState.Status = status
-			if pod.CurrentState.Host != "" {
-				pod.CurrentState.HostIP = rs.getInstanceIP(pod.CurrentState.Host)
-			}
+	pods, err := rs.registry.ListPodsPredicate(ctx, rs.filterFunc(label, labels.Everything()))
+	if err != nil {
+		return pods, err
+	}
+	filtered := []api.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		matches, err := rs.completeAndMatch(pod, field)
+		if err != nil {
+			return pod, err
+		}
+		if matches {
+			filtered = append(filtered, *pod)
+		}
+	}
+	pods.Items = filtered
+	return pods, nil
+}
+
+// ListPage lists the page of the pods of List. If the registry is a Pager,
+// only the pods of the page are read, and their info and status looked up.
+func (rs *REST) ListPage(ctx api.Context, label, field labels.Selector, page apiserver.ListPage) (runtime.Object, bool, error) {
+	pager, ok := rs.registry.(Pager)
+	if !ok {
+		list, err := rs.List(ctx, label, field)
+		if err != nil {
+			return nil, false, err
 		}
+		more, err := page.Apply(list)
+		return list, more, err
 	}
-	return pods, err
+	namespace, _ := api.NamespaceFrom(ctx)
+	after, done := page.Cursor(namespace)
+	if done {
+		return &api.PodList{}, false, nil
+	}
+	filter := rs.filterFunc(label, labels.Everything())
+	return pager.ListPodsPage(ctx, func(pod *api.Pod) (bool, error) {
+		if !filter(pod) {
+			return false, nil
+		}
+		return rs.completeAndMatch(pod, field)
+	}, after, page.Limit)
+}
+
+// completeAndMatch fills in the info and status of a listed pod, and returns
+// true if its fields match field.
+func (rs *REST) completeAndMatch(pod *api.Pod, field labels.Selector) (bool, error) {
+	rs.fillPodInfo(pod)
+	status, err := getPodStatus(pod, rs.minions)
+	if err != nil {
+		return false, err
+	}
+	pod.CurrentState.Status = status
+	if pod.CurrentState.Host != "" {
+		pod.CurrentState.HostIP = rs.getInstanceIP(pod.CurrentState.Host)
+	}
+	return field.Matches(rs.podToSelectableFields(pod)), nil
 }
 
 // Watch begins watching for new, changed, or deleted pods.
@@ -222,6 +384,11 @@ func (rs *REST) fillPodInfo(pod *api.Pod) {
 				}
 				return
 			}
+		} else if rs.isStale(pod) {
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[StaleCacheAnnotation] = "true"
 		}
 		pod.CurrentState.Info = info
 		netContainerInfo, ok := info["net"]
@@ -237,6 +404,17 @@ func (rs *REST) fillPodInfo(pod *api.Pod) {
 	}
 }
 
+// isStale returns true if the cached information of pod is older than the
+// stale cache threshold.
+func (rs *REST) isStale(pod *api.Pod) bool {
+	ager, ok := rs.podCache.(PodInfoAger)
+	if !ok || rs.staleCacheThreshold == 0 {
+		return false
+	}
+	age, ok := ager.PodInfoAge(pod.Namespace, pod.ID)
+	return ok && age > rs.staleCacheThreshold
+}
+
 func (rs *REST) getInstanceIP(host string) string {
 	data, ok := rs.ipCache[host]
 	now := rs.clock.Now()
@@ -321,3 +499,572 @@ func getPodStatus(pod *api.Pod, minions client.MinionInterface) (api.PodStatus,
 		return api.PodWaiting, nil
 	}
 }
+
+// LogREST implements the RESTStorage interface for the log subresource of
+// pods. The logs of a pod's container are read from the kubelet of the minion
+// the pod is bound to, and streamed through apiserver.ResourceStreamer.
+type LogREST struct {
+	registry Registry
+	logs     client.PodLogGetter
+}
+
+// NewLogREST returns a new LogREST.
+func NewLogREST(registry Registry, logs client.PodLogGetter) *LogREST {
+	return &LogREST{
+		registry: registry,
+		logs:     logs,
+	}
+}
+
+var ErrLogsOnly = fmt.Errorf("The logs of a pod can only be read.")
+
+func (rs *LogREST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrLogsOnly
+}
+
+func (rs *LogREST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	return nil, ErrLogsOnly
+}
+
+func (rs *LogREST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return nil, ErrLogsOnly
+}
+
+func (rs *LogREST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return nil, ErrLogsOnly
+}
+
+func (rs *LogREST) New() runtime.Object {
+	return &api.Pod{}
+}
+
+func (rs *LogREST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrLogsOnly
+}
+
+// ResourceStream returns the logs of a container of the pod id. The query
+// selects the container, which may be left out if the pod has only one, and
+// accepts follow, tailLines and sinceSeconds. Followed logs are flushed to the
+// client as the container writes them.
+func (rs *LogREST) ResourceStream(ctx api.Context, id string, query url.Values) (io.ReadCloser, string, bool, error) {
+	opts, errs := logOptions(query)
+	if len(errs) > 0 {
+		return nil, "", false, errors.NewInvalid("pod", id, errs)
+	}
+	pod, err := rs.registry.GetPod(ctx, id)
+	if err != nil {
+		return nil, "", false, err
+	}
+	opts.Container, err = podContainer(pod, opts.Container)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(pod.DesiredState.Host) == 0 {
+		return nil, "", false, fmt.Errorf("pod %s is not bound to a minion", id)
+	}
+	stream, err := rs.logs.GetPodLogs(pod.DesiredState.Host, pod.Namespace, pod.ID, opts)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return stream, "text/plain", opts.Follow, nil
+}
+
+// logOptions parses the query of a request for the logs of a pod.
+func logOptions(query url.Values) (client.PodLogOptions, errors.ErrorList) {
+	allErrs := errors.ErrorList{}
+	opts := client.PodLogOptions{Container: query.Get("container")}
+	allErrs = append(allErrs, boolParam(query, "follow", &opts.Follow)...)
+	if value := query.Get("tailLines"); len(value) > 0 {
+		lines, err := strconv.Atoi(value)
+		if err != nil || lines < 0 {
+			allErrs = append(allErrs, errors.NewFieldInvalid("tailLines", value))
+		}
+		opts.TailLines = lines
+	}
+	if value := query.Get("sinceSeconds"); len(value) > 0 {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			allErrs = append(allErrs, errors.NewFieldInvalid("sinceSeconds", value))
+		}
+		opts.SinceSeconds = seconds
+	}
+	return opts, allErrs
+}
+
+// podContainer returns the name of the container of pod a request names, or of
+// its only container if the request names none.
+func podContainer(pod *api.Pod, name string) (string, error) {
+	containers := pod.DesiredState.Manifest.Containers
+	if len(name) == 0 {
+		if len(containers) != 1 {
+			return "", errors.NewInvalid("pod", pod.ID, errors.ErrorList{errors.NewFieldRequired("container", "")})
+		}
+		return containers[0].Name, nil
+	}
+	for _, container := range containers {
+		if container.Name == name {
+			return name, nil
+		}
+	}
+	return "", errors.NewInvalid("pod", pod.ID, errors.ErrorList{errors.NewFieldNotFound("container", name)})
+}
+
+// boolParam parses the query parameter name into value, if it is set.
+func boolParam(query url.Values, name string, value *bool) errors.ErrorList {
+	s := query.Get(name)
+	if len(s) == 0 {
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return errors.ErrorList{errors.NewFieldInvalid(name, s)}
+	}
+	*value = b
+	return nil
+}
+
+// ExecREST implements the RESTStorage interface for the exec subresource of
+// pods. A request to run a command in a container is forwarded, along with its
+// upgraded connection, to the kubelet of the minion the pod runs on through
+// apiserver.ResourceConnector.
+type ExecREST struct {
+	registry    Registry
+	podInfo     client.PodInfoGetter
+	minions     minion.Registry
+	kubeletPort uint
+}
+
+// NewExecREST returns a new ExecREST, which learns whether containers are
+// running from podInfo, and connects to kubelets on kubeletPort of the minions
+// in minions.
+func NewExecREST(registry Registry, podInfo client.PodInfoGetter, minions minion.Registry, kubeletPort uint) *ExecREST {
+	return &ExecREST{
+		registry:    registry,
+		podInfo:     podInfo,
+		minions:     minions,
+		kubeletPort: kubeletPort,
+	}
+}
+
+var ErrExecOnly = fmt.Errorf("Commands can only be run in the containers of a pod.")
+
+func (rs *ExecREST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrExecOnly
+}
+
+func (rs *ExecREST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	return nil, ErrExecOnly
+}
+
+func (rs *ExecREST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return nil, ErrExecOnly
+}
+
+func (rs *ExecREST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return nil, ErrExecOnly
+}
+
+func (rs *ExecREST) New() runtime.Object {
+	return &api.Pod{}
+}
+
+func (rs *ExecREST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrExecOnly
+}
+
+// ConnectLocation returns the location of the kubelet endpoint which runs a
+// command in a running container of the pod id. The query selects the
+// container, which may be left out if the pod has only one, gives the command
+// as one command parameter per argument, and chooses which of stdin, stdout and
+// stderr to attach, and whether to allocate a TTY. Stdout and stderr are
+// attached unless the query says otherwise.
+func (rs *ExecREST) ConnectLocation(ctx api.Context, id string, query url.Values) (*url.URL, error) {
+	allErrs := errors.ErrorList{}
+	command := query["command"]
+	if len(command) == 0 {
+		allErrs = append(allErrs, errors.NewFieldRequired("command", ""))
+	}
+	stdin, stdout, stderr, tty := false, true, true, false
+	allErrs = append(allErrs, boolParam(query, "stdin", &stdin)...)
+	allErrs = append(allErrs, boolParam(query, "stdout", &stdout)...)
+	allErrs = append(allErrs, boolParam(query, "stderr", &stderr)...)
+	allErrs = append(allErrs, boolParam(query, "tty", &tty)...)
+	if len(allErrs) > 0 {
+		return nil, errors.NewInvalid("pod", id, allErrs)
+	}
+
+	pod, err := rs.registry.GetPod(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	container, err := podContainer(pod, query.Get("container"))
+	if err != nil {
+		return nil, err
+	}
+	host := pod.DesiredState.Host
+	if len(host) == 0 {
+		return nil, errors.NewBadRequest(fmt.Sprintf("pod %s is not bound to a minion", id))
+	}
+	info, err := rs.podInfo.GetPodInfo(host, pod.Namespace, pod.ID)
+	if err != nil && err != client.ErrPodInfoNotAvailable {
+		return nil, err
+	}
+	if info[container].State.Running == nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("container %s of pod %s is not running", container, id))
+	}
+	node, err := rs.minions.GetMinion(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, errors.NewNotFound("minion", host)
+	}
+
+	params := url.Values{"command": command}
+	params.Set("stdin", strconv.FormatBool(stdin))
+	params.Set("stdout", strconv.FormatBool(stdout))
+	params.Set("stderr", strconv.FormatBool(stderr))
+	params.Set("tty", strconv.FormatBool(tty))
+	return &url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(minionAddress(node), strconv.FormatUint(uint64(rs.kubeletPort), 10)),
+		Path:     path.Join("/exec", pod.ID, container),
+		RawQuery: params.Encode(),
+	}, nil
+}
+
+// minionAddress returns the address at which the kubelet of node is reached.
+func minionAddress(node *api.Minion) string {
+	for _, address := range node.Addresses {
+		if address.Type == api.NodeInternalIP {
+			return address.Address
+		}
+	}
+	if len(node.HostIP) > 0 {
+		return node.HostIP
+	}
+	return node.ID
+}
+
+// evictionRetryAfterSeconds is how long a client is asked to wait before it
+// retries an eviction which a disruption budget does not allow.
+const evictionRetryAfterSeconds = 10
+
+// EvictionREST implements the RESTStorage interface for the eviction subresource
+// of pods. Creating an eviction deletes its pod, unless that would leave fewer
+// of the pods a disruption budget covers bound to minions than the budget
+// requires. Evictions are created through apiserver.NamedCreater.
+//
+// Budgets are checked against the pods as they are listed, so evictions of
+// pods covered by the same budget which race each other may both be allowed.
+type EvictionREST struct {
+	registry Registry
+	budgets  generic.Registry
+}
+
+// NewEvictionREST returns a new EvictionREST, which honors the disruption
+// budgets in budgets.
+func NewEvictionREST(registry Registry, budgets generic.Registry) *EvictionREST {
+	return &EvictionREST{
+		registry: registry,
+		budgets:  budgets,
+	}
+}
+
+var ErrEvictionOnly = fmt.Errorf("The eviction of a pod can only be created.")
+
+// Create evicts the pod the eviction names, once the disruption budgets
+// covering the pod allow it. Otherwise it returns a TooManyRequests error,
+// suggesting when to retry.
+func (rs *EvictionREST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	eviction, ok := obj.(*api.Eviction)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &eviction.TypeMeta) {
+		return nil, errors.NewConflict("eviction", eviction.Namespace, fmt.Errorf("Eviction.Namespace does not match the provided context"))
+	}
+	if len(eviction.ID) == 0 {
+		return nil, errors.NewInvalid("eviction", eviction.ID, errors.ErrorList{errors.NewFieldRequired("id", eviction.ID)})
+	}
+	pod, err := rs.registry.GetPod(ctx, eviction.ID)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := rs.violatedBudget(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+	if budget != nil {
+		return nil, errors.NewTooManyRequests(fmt.Sprintf("Cannot evict pod %s, as it would violate the pod's disruption budget %s.", pod.ID, budget.ID), evictionRetryAfterSeconds)
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, rs.registry.DeletePod(ctx, pod.ID)
+	}), nil
+}
+
+// CreateNamed evicts the pod id. The eviction may leave out its ID, but may
+// not name another pod.
+func (rs *EvictionREST) CreateNamed(ctx api.Context, id string, obj runtime.Object) (<-chan runtime.Object, error) {
+	eviction, ok := obj.(*api.Eviction)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if len(eviction.ID) != 0 && eviction.ID != id {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the eviction of pod %s cannot evict pod %s", eviction.ID, id))
+	}
+	eviction.ID = id
+	return rs.Create(ctx, eviction)
+}
+
+func (rs *EvictionREST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	return nil, ErrEvictionOnly
+}
+
+func (rs *EvictionREST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return nil, ErrEvictionOnly
+}
+
+func (rs *EvictionREST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return nil, ErrEvictionOnly
+}
+
+func (rs *EvictionREST) New() runtime.Object {
+	return &api.Eviction{}
+}
+
+func (rs *EvictionREST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrEvictionOnly
+}
+
+// violatedBudget returns the first disruption budget covering pod which
+// deleting pod would violate, or nil if there is none. A pod counts as
+// available while it is bound to a minion, so evicting a pod which is not
+// bound violates no budget.
+func (rs *EvictionREST) violatedBudget(ctx api.Context, pod *api.Pod) (*api.PodDisruptionBudget, error) {
+	if len(pod.DesiredState.Host) == 0 {
+		return nil, nil
+	}
+	obj, err := rs.budgets.List(ctx, generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil }))
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*api.PodDisruptionBudgetList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected pod disruption budget list: %#v", obj)
+	}
+	for i := range list.Items {
+		budget := &list.Items[i]
+		selector, err := labels.ParseSelector(budget.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		available, err := rs.registry.ListPodsPredicate(ctx, func(p *api.Pod) bool {
+			return len(p.DesiredState.Host) != 0 && selector.Matches(labels.Set(p.Labels))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(available.Items)-1 < budget.Spec.MinAvailable {
+			return budget, nil
+		}
+	}
+	return nil, nil
+}
+
+// qosClass is the quality of service class of a pod. Containers set a single
+// memory and CPU limit, so a pod is guaranteed when all of its containers set
+// both, best effort when none of them sets either, and burstable otherwise.
+type qosClass int
+
+const (
+	qosBestEffort qosClass = iota
+	qosBurstable
+	qosGuaranteed
+)
+
+func podQOSClass(pod *api.Pod) qosClass {
+	containers := pod.DesiredState.Manifest.Containers
+	limited, unlimited := 0, 0
+	for _, container := range containers {
+		if container.CPU != 0 && container.Memory != 0 {
+			limited++
+		}
+		if container.CPU == 0 && container.Memory == 0 {
+			unlimited++
+		}
+	}
+	switch {
+	case len(containers) > 0 && limited == len(containers):
+		return qosGuaranteed
+	case unlimited == len(containers):
+		return qosBestEffort
+	}
+	return qosBurstable
+}
+
+// byDrainOrder orders pods by their quality of service classes, best effort
+// pods first, and then by their namespaces and ids.
+type byDrainOrder []api.Pod
+
+func (s byDrainOrder) Len() int      { return len(s) }
+func (s byDrainOrder) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byDrainOrder) Less(i, j int) bool {
+	if ci, cj := podQOSClass(&s[i]), podQOSClass(&s[j]); ci != cj {
+		return ci < cj
+	}
+	if s[i].Namespace != s[j].Namespace {
+		return s[i].Namespace < s[j].Namespace
+	}
+	return s[i].ID < s[j].ID
+}
+
+// ownedByDaemonSet returns true if a daemon set owns pod.
+func ownedByDaemonSet(pod *api.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// DrainREST implements the RESTStorage interface for the drain subresource of
+// minions. Creating a drain evicts the pods bound to its minion one at a time,
+// best effort pods first, then burstable and guaranteed pods, honoring the
+// disruption budgets as EvictionREST does. Drains are created through
+// apiserver.NamedCreater.
+type DrainREST struct {
+	evictions *EvictionREST
+	events    generic.Registry
+}
+
+// NewDrainREST returns a new DrainREST, which honors the disruption budgets in
+// budgets and records an event in events for each pod it evicts.
+func NewDrainREST(registry Registry, budgets, events generic.Registry) *DrainREST {
+	return &DrainREST{
+		evictions: NewEvictionREST(registry, budgets),
+		events:    events,
+	}
+}
+
+var ErrDrainOnly = fmt.Errorf("The drain of a minion can only be created.")
+
+// Create evicts the pods bound to the minion the drain names. Pods owned by
+// daemon sets are left alone if the drain ignores daemon sets, and otherwise
+// keep the minion from being drained, since their daemon set would start them
+// again. The drain stops at the first pod which a disruption budget keeps from
+// being evicted, leaving the pods evicted before it deleted.
+func (rs *DrainREST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	drain, ok := obj.(*api.Drain)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if len(drain.ID) == 0 {
+		return nil, errors.NewInvalid("drain", drain.ID, errors.ErrorList{errors.NewFieldRequired("id", drain.ID)})
+	}
+	list, err := rs.evictions.registry.ListPodsPredicate(api.WithNamespace(ctx, api.NamespaceAll), func(pod *api.Pod) bool {
+		return pod.DesiredState.Host == drain.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods := []api.Pod{}
+	daemons := []string{}
+	for _, pod := range list.Items {
+		if !ownedByDaemonSet(&pod) {
+			pods = append(pods, pod)
+		} else if !drain.IgnoreDaemonSets {
+			daemons = append(daemons, pod.Namespace+"/"+pod.ID)
+		}
+	}
+	if len(daemons) > 0 {
+		return nil, errors.NewConflict("drain", drain.ID, fmt.Errorf("minion %s runs the pods %v of daemon sets, which are only evicted by a drain ignoring daemon sets", drain.ID, daemons))
+	}
+	sort.Sort(byDrainOrder(pods))
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		for i := range pods {
+			pod := &pods[i]
+			podCtx := api.WithNamespace(ctx, pod.Namespace)
+			budget, err := rs.evictions.violatedBudget(podCtx, pod)
+			if err != nil {
+				return nil, err
+			}
+			if budget != nil {
+				return nil, errors.NewTooManyRequests(fmt.Sprintf("Cannot drain minion %s, as evicting pod %s would violate the pod's disruption budget %s.", drain.ID, pod.ID, budget.ID), evictionRetryAfterSeconds)
+			}
+			rs.recordEvent(podCtx, pod, drain.ID)
+			if err := rs.evictions.registry.DeletePod(podCtx, pod.ID); err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+		}
+		return &api.Status{Status: api.StatusSuccess}, nil
+	}), nil
+}
+
+// CreateNamed drains the minion id. The drain may leave out its ID, but may
+// not name another minion.
+func (rs *DrainREST) CreateNamed(ctx api.Context, id string, obj runtime.Object) (<-chan runtime.Object, error) {
+	drain, ok := obj.(*api.Drain)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if len(drain.ID) != 0 && drain.ID != id {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the drain of minion %s cannot drain minion %s", drain.ID, id))
+	}
+	drain.ID = id
+	return rs.Create(ctx, drain)
+}
+
+// recordEvent records that pod is evicted to drain minion.
+func (rs *DrainREST) recordEvent(ctx api.Context, pod *api.Pod, minion string) {
+	message := fmt.Sprintf("Evicting pod to drain minion %s", minion)
+	glog.Infof("Pod %s: %s", pod.ID, message)
+	now := util.Now()
+	event := &api.Event{
+		TypeMeta: api.TypeMeta{
+			ID:                uuid.NewUUID().String(),
+			Namespace:         pod.Namespace,
+			CreationTimestamp: now,
+		},
+		InvolvedObject: api.ObjectReference{
+			Kind:       "Pod",
+			Namespace:  pod.Namespace,
+			Name:       pod.ID,
+			UID:        pod.UID,
+			APIVersion: latest.Version,
+		},
+		Reason:         "DrainEvicting",
+		Message:        message,
+		Source:         "apiserver",
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if err := rs.events.Create(ctx, event.ID, event); err != nil {
+		glog.Errorf("Couldn't record an event for pod %s: %v", pod.ID, err)
+	}
+}
+
+func (rs *DrainREST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	return nil, ErrDrainOnly
+}
+
+func (rs *DrainREST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	return nil, ErrDrainOnly
+}
+
+func (rs *DrainREST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return nil, ErrDrainOnly
+}
+
+func (rs *DrainREST) New() runtime.Object {
+	return &api.Drain{}
+}
+
+func (rs *DrainREST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	return nil, ErrDrainOnly
+}
@@ -18,19 +18,30 @@ package pod
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider/fake"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+
+	"code.google.com/p/go.net/websocket"
 )
 
 func expectApiStatusError(t *testing.T, ch <-chan runtime.Object, msg string) {
@@ -224,14 +235,27 @@ func TestListPodListSelection(t *testing.T) {
 				TypeMeta: api.TypeMeta{ID: "qux"},
 				Labels:   map[string]string{"label": "qux"},
 			}, {
-				TypeMeta: api.TypeMeta{ID: "zot"},
+				TypeMeta: api.TypeMeta{ID: "zot", Namespace: "other"},
+			}, {
+				TypeMeta: api.TypeMeta{ID: "quux"},
+				DesiredState: api.PodState{
+					Host: "quuxhost",
+					Manifest: api.ContainerManifest{
+						Containers: []api.Container{{Name: "web"}},
+					},
+				},
 			},
 		},
 	}
 	storage := REST{
 		registry: podRegistry,
-		ipCache:  ipCache{},
-		clock:    &fakeClock{},
+		podCache: &FakePodInfoGetter{
+			info: api.PodInfo{
+				"web": {State: api.ContainerState{Running: &api.ContainerStateRunning{}}},
+			},
+		},
+		ipCache: ipCache{},
+		clock:   &fakeClock{},
 	}
 	ctx := api.NewContext()
 
@@ -240,7 +264,7 @@ func TestListPodListSelection(t *testing.T) {
 		expectedIDs  util.StringSet
 	}{
 		{
-			expectedIDs: util.NewStringSet("foo", "bar", "baz", "qux", "zot"),
+			expectedIDs: util.NewStringSet("foo", "bar", "baz", "qux", "zot", "quux"),
 		}, {
 			field:       "ID=zot",
 			expectedIDs: util.NewStringSet("zot"),
@@ -258,7 +282,19 @@ func TestListPodListSelection(t *testing.T) {
 			expectedIDs: util.NewStringSet("foo", "baz", "qux", "zot"),
 		}, {
 			field:       "DesiredState.Host!=",
-			expectedIDs: util.NewStringSet("bar"),
+			expectedIDs: util.NewStringSet("bar", "quux"),
+		}, {
+			field:       "metadata.name=zot",
+			expectedIDs: util.NewStringSet("zot"),
+		}, {
+			field:       "metadata.namespace=other",
+			expectedIDs: util.NewStringSet("zot"),
+		}, {
+			field:       "status.phase=" + string(api.PodWaiting),
+			expectedIDs: util.NewStringSet("foo", "bar", "baz", "qux", "zot"),
+		}, {
+			field:       "status.phase=" + string(api.PodRunning),
+			expectedIDs: util.NewStringSet("quux"),
 		},
 	}
 
@@ -592,15 +628,90 @@ func TestCreatePod(t *testing.T) {
 	}
 }
 
+// eventRecorder records the events created through it.
+type eventRecorder struct {
+	*registrytest.GenericRegistry
+	events []*api.Event
+}
+
+func (r *eventRecorder) Create(ctx api.Context, id string, obj runtime.Object) error {
+	r.events = append(r.events, obj.(*api.Event))
+	return nil
+}
+
+func TestCreatePodWarnsAboutMissingEnvSources(t *testing.T) {
+	podRegistry := registrytest.NewPodRegistry(nil)
+	configMaps := registrytest.NewGeneric(nil)
+	configMaps.Object = &api.ConfigMap{TypeMeta: api.TypeMeta{ID: "config"}, Data: map[string]string{"present": "1"}}
+	secrets := registrytest.NewGeneric(nil)
+	secrets.Err = errors.NewNotFound("secret", "secret")
+	events := &eventRecorder{GenericRegistry: registrytest.NewGeneric(nil)}
+	storage := NewREST(&RESTConfig{
+		Registry:   podRegistry,
+		ConfigMaps: configMaps,
+		Secrets:    secrets,
+		Events:     events,
+	})
+	pod := &api.Pod{
+		TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+		DesiredState: api.PodState{
+			Manifest: api.ContainerManifest{
+				Version: "v1beta1",
+				Containers: []api.Container{{
+					Name:  "web",
+					Image: "nginx",
+					Env: []api.EnvVar{
+						{Name: "PRESENT", ValueFrom: &api.EnvVarSource{ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config", Key: "present"}}},
+						{Name: "ABSENT", ValueFrom: &api.EnvVarSource{ConfigMapKeyRef: &api.ConfigMapKeySelector{Name: "config", Key: "absent"}}},
+						{Name: "PASSWORD", ValueFrom: &api.EnvVarSource{SecretKeyRef: &api.SecretKeySelector{Name: "secret", Key: "password"}}},
+					},
+				}},
+			},
+		},
+	}
+	channel, err := storage.Create(api.NewDefaultContext(), pod)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obj := <-channel; obj == nil {
+		t.Fatalf("expected the pod to be created")
+	}
+
+	reasons := []string{}
+	for _, event := range events.events {
+		reasons = append(reasons, event.Reason)
+		if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != "foo" || event.Status != string(api.PodWaiting) {
+			t.Errorf("unexpected event %#v", event)
+		}
+	}
+	if e, a := []string{"ConfigMapKeyNotFound", "SecretNotFound"}, reasons; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected events for %v, got %v", e, a)
+	}
+}
+
 type FakePodInfoGetter struct {
 	info api.PodInfo
+	logs string
 	err  error
+
+	// Set when GetPodLogs is called.
+	host    string
+	podID   string
+	logOpts client.PodLogOptions
 }
 
 func (f *FakePodInfoGetter) GetPodInfo(host, podNamespace string, podID string) (api.PodInfo, error) {
 	return f.info, f.err
 }
 
+func (f *FakePodInfoGetter) GetPodLogs(host, podNamespace, podID string, opts client.PodLogOptions) (io.ReadCloser, error) {
+	f.host, f.podID, f.logOpts = host, podID, opts
+	if f.err != nil {
+		return nil, f.err
+	}
+	return ioutil.NopCloser(strings.NewReader(f.logs)), nil
+}
+
 func TestFillPodInfo(t *testing.T) {
 	expectedIP := "1.2.3.4"
 	expectedTime, _ := time.Parse("2013-Feb-03", "2013-Feb-03")
@@ -651,3 +762,499 @@ func TestFillPodInfoNoData(t *testing.T) {
 		t.Errorf("Expected %s, Got %s", expectedIP, pod.CurrentState.PodIP)
 	}
 }
+
+// agedPodInfoGetter is a pod cache whose entries are all as old as age.
+type agedPodInfoGetter struct {
+	FakePodInfoGetter
+	age time.Duration
+}
+
+func (a *agedPodInfoGetter) PodInfoAge(namespace, id string) (time.Duration, bool) {
+	return a.age, true
+}
+
+func TestFillPodInfoStaleCache(t *testing.T) {
+	table := []struct {
+		age, threshold time.Duration
+		stale          bool
+	}{
+		{age: time.Second, threshold: time.Minute, stale: false},
+		{age: time.Hour, threshold: time.Minute, stale: true},
+		{age: time.Hour, threshold: 0, stale: false},
+	}
+	for _, item := range table {
+		storage := REST{
+			podCache: &agedPodInfoGetter{
+				FakePodInfoGetter: FakePodInfoGetter{info: api.PodInfo{"net": {PodIP: "1.2.3.4"}}},
+				age:               item.age,
+			},
+			staleCacheThreshold: item.threshold,
+		}
+		pod := api.Pod{DesiredState: api.PodState{Host: "foo"}}
+		storage.fillPodInfo(&pod)
+		if stale := pod.Annotations[StaleCacheAnnotation] == "true"; stale != item.stale {
+			t.Errorf("age %v, threshold %v: expected stale %v, got annotations %v", item.age, item.threshold, item.stale, pod.Annotations)
+		}
+		if pod.CurrentState.PodIP != "1.2.3.4" {
+			t.Errorf("expected the cached info to be served, got %#v", pod.CurrentState)
+		}
+	}
+}
+
+func newLogTestPod(containers ...string) *api.Pod {
+	pod := &api.Pod{
+		TypeMeta:     api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault},
+		DesiredState: api.PodState{Host: "machine"},
+	}
+	for _, name := range containers {
+		pod.DesiredState.Manifest.Containers = append(pod.DesiredState.Manifest.Containers, api.Container{Name: name})
+	}
+	return pod
+}
+
+func TestLogREST(t *testing.T) {
+	podRegistry := registrytest.NewPodRegistry(nil)
+	podRegistry.Pod = newLogTestPod("web", "sidecar")
+	fakeGetter := &FakePodInfoGetter{logs: "line 1\nline 2\n"}
+	storage := NewLogREST(podRegistry, fakeGetter)
+
+	query := url.Values{"container": {"sidecar"}, "follow": {"true"}, "tailLines": {"10"}, "sinceSeconds": {"60"}}
+	stream, contentType, flush, err := storage.ResourceStream(api.NewDefaultContext(), "foo", query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+	logs, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if string(logs) != fakeGetter.logs {
+		t.Errorf("Expected %q, Got %q", fakeGetter.logs, logs)
+	}
+	if contentType != "text/plain" || !flush {
+		t.Errorf("Expected followed plain text, Got %s %v", contentType, flush)
+	}
+	expectOpts := client.PodLogOptions{Container: "sidecar", Follow: true, TailLines: 10, SinceSeconds: 60}
+	if fakeGetter.host != "machine" || fakeGetter.podID != "foo" || fakeGetter.logOpts != expectOpts {
+		t.Errorf("Unexpected request for logs: %s %s %#v", fakeGetter.host, fakeGetter.podID, fakeGetter.logOpts)
+	}
+}
+
+func TestLogRESTDefaultsToOnlyContainer(t *testing.T) {
+	podRegistry := registrytest.NewPodRegistry(nil)
+	podRegistry.Pod = newLogTestPod("web")
+	fakeGetter := &FakePodInfoGetter{logs: "line 1\n"}
+	storage := NewLogREST(podRegistry, fakeGetter)
+
+	stream, _, flush, err := storage.ResourceStream(api.NewDefaultContext(), "foo", url.Values{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stream.Close()
+	if flush {
+		t.Errorf("Expected logs which are not followed not to be flushed")
+	}
+	if fakeGetter.logOpts != (client.PodLogOptions{Container: "web"}) {
+		t.Errorf("Unexpected request for logs: %#v", fakeGetter.logOpts)
+	}
+}
+
+func TestLogRESTErrors(t *testing.T) {
+	unbound := newLogTestPod("web")
+	unbound.DesiredState.Host = ""
+	tests := []struct {
+		pod     *api.Pod
+		query   url.Values
+		invalid bool
+	}{
+		{newLogTestPod("web", "sidecar"), url.Values{}, true},
+		{newLogTestPod("web"), url.Values{"container": {"missing"}}, true},
+		{newLogTestPod("web"), url.Values{"follow": {"sometimes"}}, true},
+		{newLogTestPod("web"), url.Values{"tailLines": {"-1"}}, true},
+		{newLogTestPod("web"), url.Values{"sinceSeconds": {"a minute"}}, true},
+		{unbound, url.Values{}, false},
+	}
+	for i, test := range tests {
+		podRegistry := registrytest.NewPodRegistry(nil)
+		podRegistry.Pod = test.pod
+		fakeGetter := &FakePodInfoGetter{}
+		storage := NewLogREST(podRegistry, fakeGetter)
+		_, _, _, err := storage.ResourceStream(api.NewDefaultContext(), "foo", test.query)
+		if err == nil {
+			t.Errorf("%d: expected an error", i)
+			continue
+		}
+		if errors.IsInvalid(err) != test.invalid {
+			t.Errorf("%d: unexpected error: %v", i, err)
+		}
+		if fakeGetter.podID != "" {
+			t.Errorf("%d: unexpected request for logs", i)
+		}
+	}
+
+	podRegistry := registrytest.NewPodRegistry(nil)
+	podRegistry.Err = errors.NewNotFound("pod", "foo")
+	storage := NewLogREST(podRegistry, &FakePodInfoGetter{})
+	if _, _, _, err := storage.ResourceStream(api.NewDefaultContext(), "foo", url.Values{}); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found, Got %v", err)
+	}
+}
+
+func newExecTestREST(pod *api.Pod, running ...string) (*ExecREST, *registrytest.MinionRegistry) {
+	podRegistry := registrytest.NewPodRegistry(nil)
+	podRegistry.Pod = pod
+	info := api.PodInfo{}
+	for _, name := range running {
+		info[name] = api.ContainerStatus{State: api.ContainerState{Running: &api.ContainerStateRunning{}}}
+	}
+	minions := registrytest.NewMinionRegistry([]string{"machine"}, api.NodeResources{})
+	return NewExecREST(podRegistry, &FakePodInfoGetter{info: info}, minions, 10250), minions
+}
+
+func TestExecRESTLocation(t *testing.T) {
+	storage, minions := newExecTestREST(newLogTestPod("web", "sidecar"), "web", "sidecar")
+	minions.Minions.Items[0].HostIP = "10.0.0.1"
+
+	query := url.Values{"container": {"sidecar"}, "command": {"ls", "-l"}, "stdin": {"true"}, "stderr": {"false"}}
+	location, err := storage.ConnectLocation(api.NewDefaultContext(), "foo", query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "http://10.0.0.1:10250/exec/foo/sidecar?command=ls&command=-l&stderr=false&stdin=true&stdout=true&tty=false"
+	if location.String() != expected {
+		t.Errorf("Expected %s, Got %s", expected, location)
+	}
+
+	minions.Minions.Items[0].Addresses = []api.NodeAddress{{Type: api.NodeInternalIP, Address: "10.0.0.2"}}
+	location, err = storage.ConnectLocation(api.NewDefaultContext(), "foo", url.Values{"container": {"web"}, "command": {"ls"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.Host != "10.0.0.2:10250" || location.Path != "/exec/foo/web" {
+		t.Errorf("Unexpected location %s", location)
+	}
+}
+
+func TestExecRESTErrors(t *testing.T) {
+	unbound := newLogTestPod("web")
+	unbound.DesiredState.Host = ""
+	tests := []struct {
+		pod     *api.Pod
+		running []string
+		query   url.Values
+		check   func(error) bool
+	}{
+		{newLogTestPod("web"), []string{"web"}, url.Values{}, errors.IsInvalid},
+		{newLogTestPod("web"), []string{"web"}, url.Values{"command": {"ls"}, "tty": {"maybe"}}, errors.IsInvalid},
+		{newLogTestPod("web", "sidecar"), []string{"web"}, url.Values{"command": {"ls"}}, errors.IsInvalid},
+		{newLogTestPod("web"), []string{"web"}, url.Values{"command": {"ls"}, "container": {"missing"}}, errors.IsInvalid},
+		{unbound, []string{"web"}, url.Values{"command": {"ls"}}, errors.IsBadRequest},
+		{newLogTestPod("web"), nil, url.Values{"command": {"ls"}}, errors.IsBadRequest},
+	}
+	for i, test := range tests {
+		storage, _ := newExecTestREST(test.pod, test.running...)
+		_, err := storage.ConnectLocation(api.NewDefaultContext(), "foo", test.query)
+		if !test.check(err) {
+			t.Errorf("%d: unexpected error: %v", i, err)
+		}
+	}
+
+	pod := newLogTestPod("web")
+	pod.DesiredState.Host = "gone"
+	storage, _ := newExecTestREST(pod, "web")
+	if _, err := storage.ConnectLocation(api.NewDefaultContext(), "foo", url.Values{"command": {"ls"}}); !errors.IsNotFound(err) {
+		t.Errorf("Expected not found, Got %v", err)
+	}
+}
+
+func TestExecRESTConnects(t *testing.T) {
+	kubelet := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+		req := ws.Request()
+		if req.URL.Path != "/exec/foo/web" || !reflect.DeepEqual(req.URL.Query()["command"], []string{"echo", "hi"}) {
+			t.Errorf("Unexpected request to the kubelet: %s", req.URL)
+		}
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		websocket.Message.Send(ws, append([]byte{1}, data[1:]...))
+	}))
+	defer kubelet.Close()
+	kubeletURL, err := url.Parse(kubelet.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(kubeletURL.Host)
+	kubeletPort, _ := strconv.Atoi(port)
+
+	pod := newLogTestPod("web")
+	pod.DesiredState.Host = host
+	podRegistry := registrytest.NewPodRegistry(nil)
+	podRegistry.Pod = pod
+	podInfo := &FakePodInfoGetter{info: api.PodInfo{"web": {State: api.ContainerState{Running: &api.ContainerStateRunning{}}}}}
+	minions := registrytest.NewMinionRegistry([]string{host}, api.NodeResources{})
+	storage := map[string]apiserver.RESTStorage{
+		"pods/exec": NewExecREST(podRegistry, podInfo, minions, uint(kubeletPort)),
+	}
+	server := httptest.NewServer(apiserver.Handle(storage, latest.Codec, "/api/v1beta1", latest.SelfLinker))
+	defer server.Close()
+
+	execURL := strings.Replace(server.URL, "http://", "ws://", 1) + "/api/v1beta1/pods/foo/exec?command=echo&command=hi&stdin=true"
+	ws, err := websocket.Dial(execURL, "", "http://127.0.0.1/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ws.Close()
+	if err := websocket.Message.Send(ws, []byte{0, 'h', 'i'}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var data []byte
+	if err := websocket.Message.Receive(ws, &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "\x01hi" {
+		t.Errorf("Unexpected message %q", data)
+	}
+}
+
+// evictionPodRegistry records the pods deleted through it.
+type evictionPodRegistry struct {
+	*registrytest.PodRegistry
+	deleted []string
+}
+
+func (r *evictionPodRegistry) DeletePod(ctx api.Context, podID string) error {
+	r.deleted = append(r.deleted, podID)
+	return r.Err
+}
+
+func newEvictionTestPod(id, host string) api.Pod {
+	return api.Pod{
+		TypeMeta:     api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Labels:       map[string]string{"name": "db"},
+		DesiredState: api.PodState{Host: host},
+	}
+}
+
+func newEvictionTestREST(pod api.Pod, budgets ...api.PodDisruptionBudget) (*evictionPodRegistry, *EvictionREST) {
+	podRegistry := &evictionPodRegistry{PodRegistry: registrytest.NewPodRegistry(&api.PodList{Items: []api.Pod{
+		pod,
+		newEvictionTestPod("db-2", "machine"),
+		newEvictionTestPod("db-3", ""),
+	}})}
+	podRegistry.Pod = &pod
+	return podRegistry, NewEvictionREST(podRegistry, registrytest.NewGeneric(&api.PodDisruptionBudgetList{Items: budgets}))
+}
+
+func newEvictionTestBudget(selector string, minAvailable int) api.PodDisruptionBudget {
+	return api.PodDisruptionBudget{
+		TypeMeta: api.TypeMeta{ID: "db", Namespace: api.NamespaceDefault},
+		Spec:     api.PodDisruptionBudgetSpec{Selector: selector, MinAvailable: minAvailable},
+	}
+}
+
+func TestEvictionRESTAllowed(t *testing.T) {
+	podRegistry, storage := newEvictionTestREST(newEvictionTestPod("db-1", "machine"), newEvictionTestBudget("name=db", 1))
+	c, err := storage.Create(api.NewDefaultContext(), &api.Eviction{TypeMeta: api.TypeMeta{ID: "db-1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := (<-c).(*api.Status); status.Status != api.StatusSuccess {
+		t.Errorf("Unexpected status %#v", status)
+	}
+	if !reflect.DeepEqual(podRegistry.deleted, []string{"db-1"}) {
+		t.Errorf("Expected db-1 to be deleted, Got %v", podRegistry.deleted)
+	}
+}
+
+func TestEvictionRESTBlocked(t *testing.T) {
+	podRegistry, storage := newEvictionTestREST(newEvictionTestPod("db-1", "machine"), newEvictionTestBudget("name=db", 2))
+	_, err := storage.Create(api.NewDefaultContext(), &api.Eviction{TypeMeta: api.TypeMeta{ID: "db-1"}})
+	if !errors.IsTooManyRequests(err) {
+		t.Fatalf("Expected a too many requests error, Got %v", err)
+	}
+	status := err.(interface {
+		Status() api.Status
+	}).Status()
+	if status.Code != 429 || status.Details == nil || status.Details.RetryAfterSeconds != evictionRetryAfterSeconds {
+		t.Errorf("Unexpected status %#v", status)
+	}
+	if len(podRegistry.deleted) != 0 {
+		t.Errorf("Unexpected deletions %v", podRegistry.deleted)
+	}
+
+	// A pod which is not bound does not count towards the budget, so evicting
+	// it is allowed.
+	podRegistry, storage = newEvictionTestREST(newEvictionTestPod("db-1", ""), newEvictionTestBudget("name=db", 2))
+	if _, err := storage.Create(api.NewDefaultContext(), &api.Eviction{TypeMeta: api.TypeMeta{ID: "db-1"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEvictionRESTNoBudget(t *testing.T) {
+	for _, budgets := range [][]api.PodDisruptionBudget{
+		nil,
+		{newEvictionTestBudget("name=web", 2)},
+	} {
+		podRegistry, storage := newEvictionTestREST(newEvictionTestPod("db-1", "machine"), budgets...)
+		c, err := storage.CreateNamed(api.NewDefaultContext(), "db-1", &api.Eviction{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-c
+		if !reflect.DeepEqual(podRegistry.deleted, []string{"db-1"}) {
+			t.Errorf("Expected db-1 to be deleted, Got %v", podRegistry.deleted)
+		}
+	}
+}
+
+func TestEvictionRESTErrors(t *testing.T) {
+	podRegistry, storage := newEvictionTestREST(newEvictionTestPod("db-1", "machine"))
+	ctx := api.NewDefaultContext()
+	if _, err := storage.CreateNamed(ctx, "db-1", &api.Eviction{TypeMeta: api.TypeMeta{ID: "db-2"}}); !errors.IsBadRequest(err) {
+		t.Errorf("Expected a bad request error, Got %v", err)
+	}
+	if _, err := storage.Create(ctx, &api.Eviction{}); !errors.IsInvalid(err) {
+		t.Errorf("Expected an invalid error, Got %v", err)
+	}
+	if _, err := storage.Create(ctx, &api.Eviction{TypeMeta: api.TypeMeta{ID: "db-1", Namespace: "other"}}); !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict error, Got %v", err)
+	}
+	podRegistry.Err = errors.NewNotFound("pod", "db-1")
+	if _, err := storage.Create(ctx, &api.Eviction{TypeMeta: api.TypeMeta{ID: "db-1"}}); !errors.IsNotFound(err) {
+		t.Errorf("Expected a not found error, Got %v", err)
+	}
+	if len(podRegistry.deleted) != 0 {
+		t.Errorf("Unexpected deletions %v", podRegistry.deleted)
+	}
+}
+
+func TestEvictionRESTThroughAPIServer(t *testing.T) {
+	_, storage := newEvictionTestREST(newEvictionTestPod("db-1", "machine"), newEvictionTestBudget("name=db", 2))
+	server := httptest.NewServer(apiserver.Handle(map[string]apiserver.RESTStorage{
+		"pods/eviction": storage,
+	}, latest.Codec, "/api/v1beta1", latest.SelfLinker))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v1beta1/pods/db-1/eviction", "application/json", strings.NewReader(`{"kind": "Eviction", "apiVersion": "v1beta1"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 429 || resp.Header.Get("Retry-After") != strconv.Itoa(evictionRetryAfterSeconds) {
+		t.Errorf("Unexpected response %d with Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
+
+func newDrainTestPod(id, host string, cpu, memory int) api.Pod {
+	pod := newEvictionTestPod(id, host)
+	pod.DesiredState.Manifest.Containers = []api.Container{{Name: "db", CPU: cpu, Memory: memory}}
+	return pod
+}
+
+func newDrainTestREST(pods []api.Pod, budgets ...api.PodDisruptionBudget) (*evictionPodRegistry, *eventRecorder, *DrainREST) {
+	podRegistry := &evictionPodRegistry{PodRegistry: registrytest.NewPodRegistry(&api.PodList{Items: pods})}
+	events := &eventRecorder{GenericRegistry: registrytest.NewGeneric(nil)}
+	return podRegistry, events, NewDrainREST(podRegistry, registrytest.NewGeneric(&api.PodDisruptionBudgetList{Items: budgets}), events)
+}
+
+func TestPodQOSClass(t *testing.T) {
+	for _, test := range []struct {
+		containers []api.Container
+		class      qosClass
+	}{
+		{nil, qosBestEffort},
+		{[]api.Container{{}, {}}, qosBestEffort},
+		{[]api.Container{{CPU: 100}}, qosBurstable},
+		{[]api.Container{{CPU: 100, Memory: 1024}, {}}, qosBurstable},
+		{[]api.Container{{CPU: 100, Memory: 1024}}, qosGuaranteed},
+		{[]api.Container{{CPU: 100, Memory: 1024}, {CPU: 200, Memory: 2048}}, qosGuaranteed},
+	} {
+		pod := &api.Pod{DesiredState: api.PodState{Manifest: api.ContainerManifest{Containers: test.containers}}}
+		if class := podQOSClass(pod); class != test.class {
+			t.Errorf("%v: expected class %d, got %d", test.containers, test.class, class)
+		}
+	}
+}
+
+func TestDrainRESTOrder(t *testing.T) {
+	daemon := newDrainTestPod("daemon", "machine", 0, 0)
+	daemon.OwnerReferences = []api.OwnerReference{{Kind: "DaemonSet", Name: "logs"}}
+	podRegistry, events, storage := newDrainTestREST([]api.Pod{
+		newDrainTestPod("guaranteed", "machine", 100, 1024),
+		newDrainTestPod("burstable", "machine", 100, 0),
+		newDrainTestPod("best-effort-b", "machine", 0, 0),
+		newDrainTestPod("best-effort-a", "machine", 0, 0),
+		newDrainTestPod("elsewhere", "other", 0, 0),
+		daemon,
+	})
+	c, err := storage.CreateNamed(api.NewContext(), "machine", &api.Drain{IgnoreDaemonSets: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := (<-c).(*api.Status); status.Status != api.StatusSuccess {
+		t.Errorf("Unexpected status %#v", status)
+	}
+	expected := []string{"best-effort-a", "best-effort-b", "burstable", "guaranteed"}
+	if !reflect.DeepEqual(podRegistry.deleted, expected) {
+		t.Errorf("Expected %v to be deleted, Got %v", expected, podRegistry.deleted)
+	}
+	evicted := []string{}
+	for _, event := range events.events {
+		if event.Reason != "DrainEvicting" || event.InvolvedObject.Namespace != api.NamespaceDefault {
+			t.Errorf("Unexpected event %#v", event)
+		}
+		evicted = append(evicted, event.InvolvedObject.Name)
+	}
+	if !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("Expected events for %v, Got %v", expected, evicted)
+	}
+}
+
+func TestDrainRESTDaemonSets(t *testing.T) {
+	daemon := newDrainTestPod("daemon", "machine", 0, 0)
+	daemon.OwnerReferences = []api.OwnerReference{{Kind: "DaemonSet", Name: "logs"}}
+	podRegistry, _, storage := newDrainTestREST([]api.Pod{newDrainTestPod("db-1", "machine", 0, 0), daemon})
+	if _, err := storage.CreateNamed(api.NewContext(), "machine", &api.Drain{}); !errors.IsConflict(err) {
+		t.Errorf("Expected a conflict error, Got %v", err)
+	}
+	if len(podRegistry.deleted) != 0 {
+		t.Errorf("Unexpected deletions %v", podRegistry.deleted)
+	}
+}
+
+func TestDrainRESTBlocked(t *testing.T) {
+	podRegistry, _, storage := newDrainTestREST([]api.Pod{
+		newDrainTestPod("db-1", "machine", 100, 1024),
+		newDrainTestPod("db-2", "other", 100, 1024),
+		newDrainTestPod("web", "machine", 0, 0),
+	}, newEvictionTestBudget("name=db", 2))
+	podRegistry.Pods.Items[2].Labels = map[string]string{"name": "web"}
+	c, err := storage.CreateNamed(api.NewContext(), "machine", &api.Drain{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := (<-c).(*api.Status); status.Code != 429 || status.Details == nil || status.Details.RetryAfterSeconds != evictionRetryAfterSeconds {
+		t.Errorf("Unexpected status %#v", status)
+	}
+	// The best effort pod is evicted before the drain stops at the pod the
+	// budget covers.
+	if !reflect.DeepEqual(podRegistry.deleted, []string{"web"}) {
+		t.Errorf("Expected web to be deleted, Got %v", podRegistry.deleted)
+	}
+}
+
+func TestDrainRESTErrors(t *testing.T) {
+	_, _, storage := newDrainTestREST(nil)
+	ctx := api.NewContext()
+	if _, err := storage.CreateNamed(ctx, "machine", &api.Drain{TypeMeta: api.TypeMeta{ID: "other"}}); !errors.IsBadRequest(err) {
+		t.Errorf("Expected a bad request error, Got %v", err)
+	}
+	if _, err := storage.Create(ctx, &api.Drain{}); !errors.IsInvalid(err) {
+		t.Errorf("Expected an invalid error, Got %v", err)
+	}
+	if _, err := storage.Get(ctx, "machine"); err != ErrDrainOnly {
+		t.Errorf("Expected ErrDrainOnly, Got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// REST adapts a pod disruption budget registry into apiserver's RESTStorage
+// model. Pod disruption budgets cannot be watched.
+type REST struct {
+	registry generic.Registry
+}
+
+// NewREST returns a new REST. You must use a registry created by
+// NewEtcdRegistry unless you're testing.
+func NewREST(registry generic.Registry) *REST {
+	return &REST{
+		registry: registry,
+	}
+}
+
+func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	podDisruptionBudget, ok := obj.(*api.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &podDisruptionBudget.TypeMeta) {
+		return nil, errors.NewConflict("podDisruptionBudget", podDisruptionBudget.Namespace, fmt.Errorf("PodDisruptionBudget.Namespace does not match the provided context"))
+	}
+	if errs := validation.ValidatePodDisruptionBudget(podDisruptionBudget); len(errs) > 0 {
+		return nil, errors.NewInvalid("podDisruptionBudget", podDisruptionBudget.ID, errs)
+	}
+	podDisruptionBudget.CreationTimestamp = util.Now()
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Create(ctx, podDisruptionBudget.ID, podDisruptionBudget)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, podDisruptionBudget.ID)
+	}), nil
+}
+
+func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	podDisruptionBudget, ok := obj.(*api.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &podDisruptionBudget.TypeMeta) {
+		return nil, errors.NewConflict("podDisruptionBudget", podDisruptionBudget.Namespace, fmt.Errorf("PodDisruptionBudget.Namespace does not match the provided context"))
+	}
+	if errs := validation.ValidatePodDisruptionBudget(podDisruptionBudget); len(errs) > 0 {
+		return nil, errors.NewInvalid("podDisruptionBudget", podDisruptionBudget.ID, errs)
+	}
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Update(ctx, podDisruptionBudget.ID, podDisruptionBudget)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, podDisruptionBudget.ID)
+	}), nil
+}
+
+func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := obj.(*api.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, rs.registry.Delete(ctx, id)
+	}), nil
+}
+
+func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	podDisruptionBudget, ok := obj.(*api.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return podDisruptionBudget, err
+}
+
+// getAttrs returns the labels and fields of a pod disruption budget.
+func getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+	podDisruptionBudget, ok := obj.(*api.PodDisruptionBudget)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid object type")
+	}
+	return labels.Set(podDisruptionBudget.Labels), labels.Set{
+		"metadata.name":      podDisruptionBudget.ID,
+		"metadata.namespace": podDisruptionBudget.Namespace,
+	}, nil
+}
+
+func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return rs.registry.List(ctx, &generic.SelectionPredicate{label, field, getAttrs})
+}
+
+// New returns a new api.PodDisruptionBudget
+func (*REST) New() runtime.Object {
+	return &api.PodDisruptionBudget{}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// everything matches every object in a registry.
+var everything = generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil })
+
+// Authorizer allows a request if a cluster role binding, or a role binding in
+// the namespace of the request, grants its user a role with a rule that allows
+// the verb on the resource of the request. Requests for resources which are not
+// in a namespace can only be allowed by cluster role bindings. Requests for
+// paths outside the API are denied unless a cluster role binding grants a rule
+// for every resource ("*"). Requests whose user is not known are denied.
+type Authorizer struct {
+	roles               generic.Registry
+	roleBindings        generic.Registry
+	clusterRoles        generic.Registry
+	clusterRoleBindings generic.Registry
+	// superUser is allowed every request, so that the first roles and bindings
+	// can be created.
+	superUser string
+}
+
+// NewAuthorizer returns an Authorizer which reads roles and bindings from the
+// given registries on every request. If superUser is not empty, the user of
+// that name is allowed every request.
+func NewAuthorizer(roles, roleBindings, clusterRoles, clusterRoleBindings generic.Registry, superUser string) *Authorizer {
+	return &Authorizer{
+		roles:               roles,
+		roleBindings:        roleBindings,
+		clusterRoles:        clusterRoles,
+		clusterRoleBindings: clusterRoleBindings,
+		superUser:           superUser,
+	}
+}
+
+// Authorize implements apiserver.Authorizer.
+func (a *Authorizer) Authorize(attrs apiserver.AuthorizationAttributes) error {
+	if attrs.User == nil {
+		return fmt.Errorf("anonymous requests are not allowed")
+	}
+	username := attrs.User.GetName()
+	if len(a.superUser) > 0 && username == a.superUser {
+		return nil
+	}
+
+	ctx := api.NewContext()
+	obj, err := a.clusterRoleBindings.List(ctx, everything)
+	if err != nil {
+		return err
+	}
+	clusterBindings, ok := obj.(*api.ClusterRoleBindingList)
+	if !ok {
+		return fmt.Errorf("unexpected object: %#v", obj)
+	}
+	for _, binding := range clusterBindings.Items {
+		if !hasUser(binding.Subjects, username) {
+			continue
+		}
+		if allowed, err := a.allows(ctx, binding.RoleRef, attrs); allowed || err != nil {
+			return err
+		}
+	}
+
+	if len(attrs.Path) > 0 {
+		return fmt.Errorf("user %q may not %s path %s", username, attrs.Verb, attrs.Path)
+	}
+	if len(attrs.Namespace) == 0 {
+		return fmt.Errorf("user %q may not %s %s", username, attrs.Verb, attrs.Resource)
+	}
+	ctx = api.WithNamespace(ctx, attrs.Namespace)
+	obj, err = a.roleBindings.List(ctx, everything)
+	if err != nil {
+		return err
+	}
+	bindings, ok := obj.(*api.RoleBindingList)
+	if !ok {
+		return fmt.Errorf("unexpected object: %#v", obj)
+	}
+	for _, binding := range bindings.Items {
+		if !hasUser(binding.Subjects, username) {
+			continue
+		}
+		if allowed, err := a.allows(ctx, binding.RoleRef, attrs); allowed || err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("user %q may not %s %s in namespace %q", username, attrs.Verb, attrs.Resource, attrs.Namespace)
+}
+
+// allows returns true if the role ref refers to allows the request. A role
+// which does not exist allows nothing. Roles are looked up in the namespace of
+// ctx.
+func (a *Authorizer) allows(ctx api.Context, ref api.RoleRef, attrs apiserver.AuthorizationAttributes) (bool, error) {
+	var rules []api.PolicyRule
+	switch ref.Kind {
+	case api.RoleRefKindRole:
+		obj, err := a.roles.Get(ctx, ref.Name)
+		if errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		role, ok := obj.(*api.Role)
+		if !ok {
+			return false, fmt.Errorf("unexpected object: %#v", obj)
+		}
+		rules = role.Rules
+	case api.RoleRefKindClusterRole:
+		obj, err := a.clusterRoles.Get(ctx, ref.Name)
+		if errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		role, ok := obj.(*api.ClusterRole)
+		if !ok {
+			return false, fmt.Errorf("unexpected object: %#v", obj)
+		}
+		rules = role.Rules
+	}
+	for _, rule := range rules {
+		if matches(rule.Verbs, attrs.Verb) && matches(rule.Resources, attrs.Resource) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasUser returns true if subjects include the user called username.
+func hasUser(subjects []api.Subject, username string) bool {
+	for _, subject := range subjects {
+		if subject.Kind == api.SubjectKindUser && subject.Name == username {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if values include value or the wildcard "*".
+func matches(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
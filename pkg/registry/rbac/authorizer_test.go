@@ -0,0 +1,153 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/auth/user"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+)
+
+// fakeRoleRegistry holds roles by namespace and id, or only by id if they are
+// not namespaced.
+type fakeRoleRegistry struct {
+	*registrytest.GenericRegistry
+	namespaced bool
+	roles      map[string]runtime.Object
+}
+
+func (r *fakeRoleRegistry) Get(ctx api.Context, id string) (runtime.Object, error) {
+	key := id
+	if r.namespaced {
+		namespace, _ := api.NamespaceFrom(ctx)
+		key = namespace + "/" + id
+	}
+	obj, ok := r.roles[key]
+	if !ok {
+		return nil, errors.NewNotFound("role", id)
+	}
+	return obj, nil
+}
+
+func newTestAuthorizer() *Authorizer {
+	roles := &fakeRoleRegistry{
+		GenericRegistry: registrytest.NewGeneric(nil),
+		namespaced:      true,
+		roles: map[string]runtime.Object{
+			"default/pod-reader": &api.Role{Rules: []api.PolicyRule{{Verbs: []string{"get", "list"}, Resources: []string{"pods"}}}},
+		},
+	}
+	clusterRoles := &fakeRoleRegistry{
+		GenericRegistry: registrytest.NewGeneric(nil),
+		roles: map[string]runtime.Object{
+			"admin":         &api.ClusterRole{Rules: []api.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"*"}}}},
+			"minion-reader": &api.ClusterRole{Rules: []api.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"minions"}}}},
+			"editor":        &api.ClusterRole{Rules: []api.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"services"}}}},
+		},
+	}
+	roleBindings := registrytest.NewGeneric(&api.RoleBindingList{Items: []api.RoleBinding{
+		{
+			Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "alice"}, {Kind: api.SubjectKindUser, Name: "bob"}},
+			RoleRef:  api.RoleRef{Kind: api.RoleRefKindRole, Name: "pod-reader"},
+		},
+		{
+			Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "carol"}},
+			RoleRef:  api.RoleRef{Kind: api.RoleRefKindClusterRole, Name: "editor"},
+		},
+		{
+			Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "dave"}},
+			RoleRef:  api.RoleRef{Kind: api.RoleRefKindRole, Name: "missing"},
+		},
+	}})
+	clusterRoleBindings := registrytest.NewGeneric(&api.ClusterRoleBindingList{Items: []api.ClusterRoleBinding{
+		{
+			Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "root"}},
+			RoleRef:  api.RoleRef{Kind: api.RoleRefKindClusterRole, Name: "admin"},
+		},
+		{
+			Subjects: []api.Subject{{Kind: api.SubjectKindUser, Name: "bob"}},
+			RoleRef:  api.RoleRef{Kind: api.RoleRefKindClusterRole, Name: "minion-reader"},
+		},
+	}})
+	return NewAuthorizer(roles, roleBindings, clusterRoles, clusterRoleBindings, "bootstrap")
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := newTestAuthorizer()
+	table := []struct {
+		user, verb, resource, namespace string
+		allowed                         bool
+	}{
+		{"alice", "get", "pods", api.NamespaceDefault, true},
+		{"alice", "list", "pods", api.NamespaceDefault, true},
+		{"alice", "delete", "pods", api.NamespaceDefault, false},
+		{"alice", "get", "pods/log", api.NamespaceDefault, false},
+		{"alice", "get", "services", api.NamespaceDefault, false},
+		{"alice", "get", "pods", "other", false},
+		{"alice", "get", "minions", "", false},
+		{"bob", "get", "pods", api.NamespaceDefault, true},
+		{"bob", "get", "minions", "", true},
+		{"bob", "delete", "minions", "", false},
+		{"carol", "delete", "services", api.NamespaceDefault, true},
+		{"carol", "create", "services", api.NamespaceDefault, true},
+		{"carol", "create", "pods", api.NamespaceDefault, false},
+		{"dave", "get", "pods", api.NamespaceDefault, false},
+		{"root", "delete", "minions", "", true},
+		{"root", "proxy", "pods", "other", true},
+		{"bootstrap", "create", "clusterRoleBindings", "", true},
+		{"", "get", "pods", api.NamespaceDefault, false},
+	}
+	for _, item := range table {
+		attrs := apiserver.AuthorizationAttributes{Verb: item.verb, Resource: item.resource, Namespace: item.namespace}
+		if item.user != "" {
+			attrs.User = &user.DefaultInfo{Name: item.user}
+		}
+		err := authorizer.Authorize(attrs)
+		if allowed := err == nil; allowed != item.allowed {
+			t.Errorf("%q %s %s in %q: expected allowed %v, got %v", item.user, item.verb, item.resource, item.namespace, item.allowed, err)
+		}
+	}
+}
+
+func TestAuthorizerPaths(t *testing.T) {
+	authorizer := newTestAuthorizer()
+	table := []struct {
+		user, path string
+		allowed    bool
+	}{
+		{"root", "/logs/", true},
+		{"bootstrap", "/logs/", true},
+		{"bob", "/logs/", false},
+		{"carol", "/metrics", false},
+		{"", "/metrics", false},
+	}
+	for _, item := range table {
+		attrs := apiserver.AuthorizationAttributes{Verb: "get", Path: item.path}
+		if item.user != "" {
+			attrs.User = &user.DefaultInfo{Name: item.user}
+		}
+		err := authorizer.Authorize(attrs)
+		if allowed := err == nil; allowed != item.allowed {
+			t.Errorf("%q get %s: expected allowed %v, got %v", item.user, item.path, item.allowed, err)
+		}
+	}
+}
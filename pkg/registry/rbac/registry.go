@@ -0,0 +1,104 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"path"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	etcdgeneric "github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic/etcd"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+)
+
+const (
+	// rolePrefix is the key under which roles are stored, by namespace.
+	rolePrefix = "/roles"
+	// roleBindingPrefix is the key under which role bindings are stored, by namespace.
+	roleBindingPrefix = "/rolebindings"
+	// clusterRolePrefix is the key under which cluster roles are stored.
+	clusterRolePrefix = "/clusterroles"
+	// clusterRoleBindingPrefix is the key under which cluster role bindings are stored.
+	clusterRoleBindingPrefix = "/clusterrolebindings"
+)
+
+// newNamespacedRegistry returns a registry which stores objects under the key
+// of their namespace in prefix.
+func newNamespacedRegistry(h tools.EtcdHelper, prefix, endpointName string, newFunc, newListFunc func() runtime.Object) generic.Registry {
+	return &etcdgeneric.Etcd{
+		NewFunc:      newFunc,
+		NewListFunc:  newListFunc,
+		EndpointName: endpointName,
+		KeyRootFunc: func(ctx api.Context) string {
+			return etcdgeneric.NamespaceKeyRootFunc(ctx, prefix)
+		},
+		KeyFunc: func(ctx api.Context, id string) (string, error) {
+			return etcdgeneric.NamespaceKeyFunc(ctx, prefix, id)
+		},
+		Helper: h,
+	}
+}
+
+// newClusterRegistry returns a registry which stores objects directly under
+// prefix, whatever the namespace of the request.
+func newClusterRegistry(h tools.EtcdHelper, prefix, endpointName string, newFunc, newListFunc func() runtime.Object) generic.Registry {
+	return &etcdgeneric.Etcd{
+		NewFunc:      newFunc,
+		NewListFunc:  newListFunc,
+		EndpointName: endpointName,
+		KeyRootFunc: func(ctx api.Context) string {
+			return prefix
+		},
+		KeyFunc: func(ctx api.Context, id string) (string, error) {
+			return path.Join(prefix, id), nil
+		},
+		Helper: h,
+	}
+}
+
+// NewRoleEtcdRegistry returns a registry which will store Roles in the given
+// EtcdHelper. Each role is stored under the key of its namespace.
+func NewRoleEtcdRegistry(h tools.EtcdHelper) generic.Registry {
+	return newNamespacedRegistry(h, rolePrefix, "roles",
+		func() runtime.Object { return &api.Role{} },
+		func() runtime.Object { return &api.RoleList{} })
+}
+
+// NewRoleBindingEtcdRegistry returns a registry which will store RoleBindings
+// in the given EtcdHelper. Each binding is stored under the key of its namespace.
+func NewRoleBindingEtcdRegistry(h tools.EtcdHelper) generic.Registry {
+	return newNamespacedRegistry(h, roleBindingPrefix, "roleBindings",
+		func() runtime.Object { return &api.RoleBinding{} },
+		func() runtime.Object { return &api.RoleBindingList{} })
+}
+
+// NewClusterRoleEtcdRegistry returns a registry which will store ClusterRoles
+// in the given EtcdHelper.
+func NewClusterRoleEtcdRegistry(h tools.EtcdHelper) generic.Registry {
+	return newClusterRegistry(h, clusterRolePrefix, "clusterRoles",
+		func() runtime.Object { return &api.ClusterRole{} },
+		func() runtime.Object { return &api.ClusterRoleList{} })
+}
+
+// NewClusterRoleBindingEtcdRegistry returns a registry which will store
+// ClusterRoleBindings in the given EtcdHelper.
+func NewClusterRoleBindingEtcdRegistry(h tools.EtcdHelper) generic.Registry {
+	return newClusterRegistry(h, clusterRoleBindingPrefix, "clusterRoleBindings",
+		func() runtime.Object { return &api.ClusterRoleBinding{} },
+		func() runtime.Object { return &api.ClusterRoleBindingList{} })
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/testapi"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+)
+
+func newTestHelper(t *testing.T) (*tools.FakeEtcdClient, tools.EtcdHelper) {
+	f := tools.NewFakeEtcdClient(t)
+	f.TestIndex = true
+	return f, tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}, "/registry", tools.WatchConfig{}}
+}
+
+func TestEtcdRegistryKeys(t *testing.T) {
+	table := map[string]struct {
+		newRegistry func(tools.EtcdHelper) generic.Registry
+		obj         runtime.Object
+		key         string
+	}{
+		"role": {
+			NewRoleEtcdRegistry,
+			&api.Role{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}},
+			"/registry/roles/default/foo",
+		},
+		"roleBinding": {
+			NewRoleBindingEtcdRegistry,
+			&api.RoleBinding{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}},
+			"/registry/rolebindings/default/foo",
+		},
+		"clusterRole": {
+			NewClusterRoleEtcdRegistry,
+			&api.ClusterRole{TypeMeta: api.TypeMeta{ID: "foo"}},
+			"/registry/clusterroles/foo",
+		},
+		"clusterRoleBinding": {
+			NewClusterRoleBindingEtcdRegistry,
+			&api.ClusterRoleBinding{TypeMeta: api.TypeMeta{ID: "foo"}},
+			"/registry/clusterrolebindings/foo",
+		},
+	}
+	for name, item := range table {
+		fakeClient, helper := newTestHelper(t)
+		registry := item.newRegistry(helper)
+		if err := registry.Create(api.NewDefaultContext(), "foo", item.obj); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if _, ok := fakeClient.Data[item.key]; !ok {
+			t.Errorf("%s: expected the object to be stored at %s, got %v", name, item.key, fakeClient.Data)
+		}
+		obj, err := registry.Get(api.NewDefaultContext(), "foo")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if meta, _ := api.TypeMetaFor(obj); meta.ID != "foo" {
+			t.Errorf("%s: unexpected object %#v", name, obj)
+		}
+	}
+}
+
+func TestRoleRequiresNamespace(t *testing.T) {
+	_, helper := newTestHelper(t)
+	err := NewRoleEtcdRegistry(helper).Create(api.NewContext(), "foo", &api.Role{TypeMeta: api.TypeMeta{ID: "foo"}})
+	if err == nil {
+		t.Errorf("expected an error without a namespace")
+	}
+}
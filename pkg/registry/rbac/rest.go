@@ -0,0 +1,226 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/etcd"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+)
+
+// kind describes the objects served by a REST.
+type kind struct {
+	// name is the name of the kind in errors, e.g. "role".
+	name string
+	// namespaced is true if the objects are in a namespace.
+	namespaced bool
+	newFunc    func() runtime.Object
+	// validate returns the validation errors of an object of the kind.
+	validate func(obj runtime.Object) errors.ErrorList
+}
+
+var (
+	roleKind = kind{
+		name:       "role",
+		namespaced: true,
+		newFunc:    func() runtime.Object { return &api.Role{} },
+		validate: func(obj runtime.Object) errors.ErrorList {
+			return validation.ValidateRole(obj.(*api.Role))
+		},
+	}
+	roleBindingKind = kind{
+		name:       "roleBinding",
+		namespaced: true,
+		newFunc:    func() runtime.Object { return &api.RoleBinding{} },
+		validate: func(obj runtime.Object) errors.ErrorList {
+			return validation.ValidateRoleBinding(obj.(*api.RoleBinding))
+		},
+	}
+	clusterRoleKind = kind{
+		name:    "clusterRole",
+		newFunc: func() runtime.Object { return &api.ClusterRole{} },
+		validate: func(obj runtime.Object) errors.ErrorList {
+			return validation.ValidateClusterRole(obj.(*api.ClusterRole))
+		},
+	}
+	clusterRoleBindingKind = kind{
+		name:    "clusterRoleBinding",
+		newFunc: func() runtime.Object { return &api.ClusterRoleBinding{} },
+		validate: func(obj runtime.Object) errors.ErrorList {
+			return validation.ValidateClusterRoleBinding(obj.(*api.ClusterRoleBinding))
+		},
+	}
+)
+
+// REST adapts a registry of roles, cluster roles or their bindings into
+// apiserver's RESTStorage model. The four kinds only differ in their type,
+// their validation and whether they are in a namespace.
+type REST struct {
+	registry generic.Registry
+	kind     kind
+}
+
+// NewRoleREST returns a REST for roles. You must use a registry created by
+// NewRoleEtcdRegistry unless you're testing.
+func NewRoleREST(registry generic.Registry) *REST {
+	return &REST{registry, roleKind}
+}
+
+// NewRoleBindingREST returns a REST for role bindings. You must use a registry
+// created by NewRoleBindingEtcdRegistry unless you're testing.
+func NewRoleBindingREST(registry generic.Registry) *REST {
+	return &REST{registry, roleBindingKind}
+}
+
+// NewClusterRoleREST returns a REST for cluster roles. You must use a registry
+// created by NewClusterRoleEtcdRegistry unless you're testing.
+func NewClusterRoleREST(registry generic.Registry) *REST {
+	return &REST{registry, clusterRoleKind}
+}
+
+// NewClusterRoleBindingREST returns a REST for cluster role bindings. You must
+// use a registry created by NewClusterRoleBindingEtcdRegistry unless you're testing.
+func NewClusterRoleBindingREST(registry generic.Registry) *REST {
+	return &REST{registry, clusterRoleBindingKind}
+}
+
+// typeMeta returns the TypeMeta of obj, or an error if obj is not of the kind
+// of rs.
+func (rs *REST) typeMeta(obj runtime.Object) (*api.TypeMeta, error) {
+	if reflect.TypeOf(obj) != reflect.TypeOf(rs.kind.newFunc()) {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return api.TypeMetaFor(obj)
+}
+
+// check returns an error if obj may not be stored in the namespace of ctx.
+func (rs *REST) check(ctx api.Context, obj runtime.Object) (*api.TypeMeta, error) {
+	meta, err := rs.typeMeta(obj)
+	if err != nil {
+		return nil, err
+	}
+	if rs.kind.namespaced && !api.ValidNamespace(ctx, meta) {
+		return nil, errors.NewConflict(rs.kind.name, meta.Namespace, fmt.Errorf("Namespace does not match the provided context"))
+	}
+	if errs := rs.kind.validate(obj); len(errs) > 0 {
+		return nil, errors.NewInvalid(rs.kind.name, meta.ID, errs)
+	}
+	return meta, nil
+}
+
+func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	meta, err := rs.check(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	meta.CreationTimestamp = util.Now()
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Create(ctx, meta.ID, obj)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, meta.ID)
+	}), nil
+}
+
+func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	meta, err := rs.check(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Update(ctx, meta.ID, obj)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, meta.ID)
+	}), nil
+}
+
+func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rs.typeMeta(obj); err != nil {
+		return nil, err
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, rs.registry.Delete(ctx, id)
+	}), nil
+}
+
+func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rs.typeMeta(obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// getAttrs returns the labels and fields of an object of the kind of rs. None
+// of the kinds have labels.
+func (rs *REST) getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+	meta, err := rs.typeMeta(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return labels.Set{}, labels.Set{
+		"metadata.name":      meta.ID,
+		"metadata.namespace": meta.Namespace,
+	}, nil
+}
+
+func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	return rs.registry.List(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs})
+}
+
+// Watch returns the changes to the objects that match label and field, from
+// resourceVersion onwards.
+func (rs *REST) Watch(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
+	version, err := etcd.ParseWatchResourceVersion(resourceVersion, rs.kind.name)
+	if err != nil {
+		return nil, err
+	}
+	return rs.registry.Watch(ctx, &generic.SelectionPredicate{label, field, rs.getAttrs}, version)
+}
+
+// New returns a new object of the kind of rs.
+func (rs *REST) New() runtime.Object {
+	return rs.kind.newFunc()
+}
+
+// NamespaceScoped returns true for roles and role bindings, and false for
+// cluster roles and cluster role bindings.
+func (rs *REST) NamespaceScoped() bool {
+	return rs.kind.namespaced
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+func testRole(id string) *api.Role {
+	return &api.Role{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Rules:    []api.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}},
+	}
+}
+
+func TestRESTCreate(t *testing.T) {
+	rest := NewRoleREST(registrytest.NewGeneric(nil))
+	role := testRole("foo")
+	c, err := rest.Create(api.NewDefaultContext(), role)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := role, <-c; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	rest := NewRoleREST(registrytest.NewGeneric(nil))
+	role := testRole("foo")
+	role.Rules[0].Verbs = []string{"escalate"}
+	if _, err := rest.Create(api.NewDefaultContext(), role); !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTCreateWrongNamespace(t *testing.T) {
+	rest := NewRoleREST(registrytest.NewGeneric(nil))
+	role := testRole("foo")
+	role.Namespace = "other"
+	if _, err := rest.Create(api.NewDefaultContext(), role); !errors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestRESTCreateClusterRole(t *testing.T) {
+	rest := NewClusterRoleREST(registrytest.NewGeneric(nil))
+	role := &api.ClusterRole{
+		TypeMeta: api.TypeMeta{ID: "admin"},
+		Rules:    []api.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"*"}}},
+	}
+	// Cluster roles are not in the namespace of the request.
+	c, err := rest.Create(api.NewDefaultContext(), role)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e, a := role, <-c; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+	if rest.NamespaceScoped() {
+		t.Errorf("expected cluster roles not to be in a namespace")
+	}
+}
+
+func TestRESTWrongType(t *testing.T) {
+	registry := registrytest.NewGeneric(nil)
+	rest := NewRoleBindingREST(registry)
+	if _, err := rest.Create(api.NewDefaultContext(), testRole("foo")); err == nil {
+		t.Errorf("expected a role to be rejected by the role binding storage")
+	}
+	registry.Object = testRole("foo")
+	if _, err := rest.Get(api.NewDefaultContext(), "foo"); err == nil {
+		t.Errorf("expected an error getting a role from the role binding storage")
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	registry := registrytest.NewGeneric(&api.RoleList{Items: []api.Role{*testRole("foo"), *testRole("bar")}})
+	rest := NewRoleREST(registry)
+	obj, err := rest.List(api.NewDefaultContext(), labels.Everything(), labels.Set{"metadata.name": "bar"}.AsSelector())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list := obj.(*api.RoleList); len(list.Items) != 1 || list.Items[0].ID != "bar" {
+		t.Errorf("unexpected list %#v", list)
+	}
+}
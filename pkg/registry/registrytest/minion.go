@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
 )
 
 type MinionRegistry struct {
@@ -46,10 +47,19 @@ func NewMinionRegistry(minions []string, nodeResources api.NodeResources) *Minio
 	}
 }
 
-func (r *MinionRegistry) ListMinions(ctx api.Context) (*api.MinionList, error) {
+func (r *MinionRegistry) ListMinions(ctx api.Context, selector labels.Selector) (*api.MinionList, error) {
 	r.Lock()
 	defer r.Unlock()
-	return &r.Minions, r.Err
+	if selector.Empty() {
+		return &r.Minions, r.Err
+	}
+	result := &api.MinionList{}
+	for _, minion := range r.Minions.Items {
+		if selector.Matches(labels.Set(minion.Labels)) {
+			result.Items = append(result.Items, minion)
+		}
+	}
+	return result, r.Err
 }
 
 func (r *MinionRegistry) CreateMinion(ctx api.Context, minion *api.Minion) error {
@@ -71,6 +81,18 @@ func (r *MinionRegistry) GetMinion(ctx api.Context, minionID string) (*api.Minio
 	return nil, r.Err
 }
 
+func (r *MinionRegistry) UpdateMinion(ctx api.Context, minion *api.Minion) error {
+	r.Lock()
+	defer r.Unlock()
+	for i := range r.Minions.Items {
+		if r.Minions.Items[i].ID == minion.ID {
+			r.Minions.Items[i] = *minion
+			return r.Err
+		}
+	}
+	return r.Err
+}
+
 func (r *MinionRegistry) DeleteMinion(ctx api.Context, minionID string) error {
 	r.Lock()
 	defer r.Unlock()
@@ -0,0 +1,252 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrytest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/testapi"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+// EtcdTester checks the behavior shared by the registries which store the
+// objects of a kind in etcd under the key of their namespace.
+type EtcdTester struct {
+	T *testing.T
+	// NewRegistry returns the registry under test, which stores its objects
+	// with helper.
+	NewRegistry func(helper tools.EtcdHelper) generic.Registry
+	// Prefix is the key the objects are stored under, e.g. "/configmaps".
+	Prefix string
+	// New returns a valid object named id in namespace.
+	New func(id, namespace string) runtime.Object
+}
+
+func (tt *EtcdTester) newRegistry() (*tools.FakeEtcdClient, generic.Registry) {
+	fakeClient := tools.NewFakeEtcdClient(tt.T)
+	fakeClient.TestIndex = true
+	helper := tools.EtcdHelper{fakeClient, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}, "/registry", tools.WatchConfig{}}
+	return fakeClient, tt.NewRegistry(helper)
+}
+
+func (tt *EtcdTester) key(namespace, id string) string {
+	return "/registry" + tt.Prefix + "/" + namespace + "/" + id
+}
+
+// Test checks that objects are created, read and listed by namespace, and
+// that objects of the same name in different namespaces are kept apart.
+func (tt *EtcdTester) Test() {
+	t := tt.T
+	fakeClient, registry := tt.newRegistry()
+	ctxA := api.WithNamespace(api.NewContext(), "a")
+	ctxB := api.WithNamespace(api.NewContext(), "b")
+	fakeClient.ExpectNotFoundGet(tt.key("a", "foo"))
+	fakeClient.ExpectNotFoundGet(tt.key("b", "foo"))
+
+	if err := registry.Create(ctxA, "foo", tt.New("foo", "a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := fakeClient.Data[tt.key("a", "foo")]; !ok {
+		t.Errorf("expected the object to be stored under %s: %#v", tt.key("a", "foo"), fakeClient.Data)
+	}
+	if err := registry.Create(ctxA, "foo", tt.New("foo", "a")); !errors.IsAlreadyExists(err) {
+		t.Errorf("expected an already exists error, got %v", err)
+	}
+	obj, err := registry.Get(ctxA, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta, err := api.TypeMetaFor(obj); err != nil || meta.ID != "foo" || meta.Namespace != "a" {
+		t.Errorf("unexpected object: %#v", obj)
+	}
+	if _, err := registry.Get(ctxB, "foo"); !errors.IsNotFound(err) {
+		t.Errorf("expected the object of namespace a not to be found in namespace b, got %v", err)
+	}
+	if err := registry.Create(ctxB, "foo", tt.New("foo", "b")); err != nil {
+		t.Errorf("expected an object of the same name to be created in namespace b, got %v", err)
+	}
+	if err := registry.Create(api.NewContext(), "foo", tt.New("foo", "")); err == nil {
+		t.Errorf("expected an error without a namespace")
+	}
+
+	node := func(id, namespace string) *etcd.Node {
+		return &etcd.Node{Value: runtime.EncodeOrDie(testapi.Codec(), tt.New(id, namespace))}
+	}
+	fakeClient.Data["/registry"+tt.Prefix+"/a"] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Nodes: []*etcd.Node{node("foo", "a")}}},
+	}
+	fakeClient.Data["/registry"+tt.Prefix] = tools.EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Nodes: []*etcd.Node{
+			{Dir: true, Nodes: []*etcd.Node{node("foo", "a")}},
+			{Dir: true, Nodes: []*etcd.Node{node("foo", "b"), node("bar", "b")}},
+		}}},
+	}
+	everything := generic.MatcherFunc(func(runtime.Object) (bool, error) { return true, nil })
+	for ctx, expected := range map[api.Context][]string{
+		ctxA:             {"a/foo"},
+		api.NewContext(): {"a/foo", "b/foo", "b/bar"},
+	} {
+		list, err := registry.List(ctx, everything)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		items, err := runtime.ExtractList(list)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		listed := []string{}
+		for _, item := range items {
+			meta, err := api.TypeMetaFor(item)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			listed = append(listed, meta.Namespace+"/"+meta.ID)
+		}
+		if !reflect.DeepEqual(expected, listed) {
+			t.Errorf("expected %v to be listed, got %v", expected, listed)
+		}
+	}
+}
+
+// RESTStorage is the storage RESTTester checks.
+type RESTStorage interface {
+	Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error)
+	Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error)
+	Get(ctx api.Context, id string) (runtime.Object, error)
+	Delete(ctx api.Context, id string) (<-chan runtime.Object, error)
+	List(ctx api.Context, label, field labels.Selector) (runtime.Object, error)
+}
+
+// RESTTester checks the behavior shared by the REST storage of the kinds
+// which are kept in a generic.Registry, served from Registry.
+type RESTTester struct {
+	T        *testing.T
+	Storage  RESTStorage
+	Registry *GenericRegistry
+	// New returns a valid object named id in the default namespace.
+	New func(id string) runtime.Object
+	// NewList returns an empty list of the objects.
+	NewList func() runtime.Object
+}
+
+func (tt *RESTTester) meta(obj runtime.Object) *api.TypeMeta {
+	meta, err := api.TypeMetaFor(obj)
+	if err != nil {
+		tt.T.Fatalf("unexpected error: %v", err)
+	}
+	return meta
+}
+
+// Test checks that valid objects are created, updated, deleted and listed,
+// and that invalid and objects of another namespace than the request are
+// rejected.
+func (tt *RESTTester) Test(invalid runtime.Object) {
+	t := tt.T
+	ctx := api.NewDefaultContext()
+
+	c, err := tt.Storage.Create(ctx, tt.New("foo"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created := <-c; tt.meta(created).ID != "foo" {
+		t.Errorf("unexpected object: %#v", created)
+	}
+	if _, err := tt.Storage.Create(ctx, invalid); !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+	other := tt.New("foo")
+	tt.meta(other).Namespace = "other"
+	if _, err := tt.Storage.Create(ctx, other); !errors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+
+	updated := tt.New("foo")
+	tt.meta(updated).Annotations = map[string]string{"updated": "true"}
+	c, err = tt.Storage.Update(ctx, updated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-c
+	got, err := tt.Storage.Get(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(updated, got) {
+		t.Errorf("diff: %s", util.ObjectDiff(updated, got))
+	}
+
+	c, err = tt.Storage.Delete(ctx, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status, ok := (<-c).(*api.Status); !ok || status.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %#v", status)
+	}
+
+	list := tt.NewList()
+	if err := runtime.SetList(list, []runtime.Object{tt.New("foo"), tt.New("bar")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tt.Registry.ObjectList = list
+	got, err = tt.Storage.List(ctx, labels.Everything(), labels.Set{"metadata.name": "foo"}.AsSelector())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := tt.NewList()
+	if err := runtime.SetList(expected, []runtime.Object{tt.New("foo")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("diff: %s", util.ObjectDiff(expected, got))
+	}
+}
+
+// WatchingRESTStorage is the storage RESTTester.TestWatch checks.
+type WatchingRESTStorage interface {
+	Watch(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error)
+}
+
+// TestWatch checks that the changes made to Registry are watched, and that an
+// invalid resource version is rejected. Storage must be a WatchingRESTStorage.
+func (tt *RESTTester) TestWatch() {
+	t := tt.T
+	storage := tt.Storage.(WatchingRESTStorage)
+	ctx := api.NewDefaultContext()
+	w, err := storage.Watch(ctx, labels.Everything(), labels.Everything(), "0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Stop()
+	obj := tt.New("foo")
+	go tt.Registry.Mux.Action(watch.Added, obj)
+	if e, a := (watch.Event{watch.Added, obj}), <-w.ResultChan(); !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+	if _, err := storage.Watch(ctx, labels.Everything(), labels.Everything(), "abc"); err == nil {
+		t.Errorf("expected an error for an invalid resource version")
+	}
+}
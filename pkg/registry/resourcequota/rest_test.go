@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func testQuota(id string) *api.ResourceQuota {
+	return &api.ResourceQuota{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Hard:     api.ResourceList{"pods": util.NewIntOrStringFromInt(10)},
+	}
+}
+
+func TestRESTCreate(t *testing.T) {
+	_, rest := NewTestREST()
+	quota := testQuota("foo")
+	quota.Status.Used = api.ResourceList{"pods": util.NewIntOrStringFromInt(3)}
+	c, err := rest.Create(api.NewDefaultContext(), quota)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	got := (<-c).(*api.ResourceQuota)
+	if !reflect.DeepEqual(got.Hard, testQuota("foo").Hard) {
+		t.Errorf("unexpected limits: %#v", got.Hard)
+	}
+	if got.Status.Used != nil {
+		t.Errorf("expected the usage set by the client to be dropped: %#v", got.Status)
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	_, rest := NewTestREST()
+	quota := testQuota("foo")
+	quota.Hard["disk"] = util.NewIntOrStringFromInt(1)
+	_, err := rest.Create(api.NewDefaultContext(), quota)
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTDelete(t *testing.T) {
+	_, rest := NewTestREST()
+	quota := testQuota("foo")
+	c, err := rest.Create(api.NewDefaultContext(), quota)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	c, err = rest.Delete(api.NewDefaultContext(), quota.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if stat := (<-c).(*api.Status); stat.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %v", stat)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	reg, rest := NewTestREST()
+	other := testQuota("bar")
+	other.Namespace = "other"
+	reg.ObjectList = &api.ResourceQuotaList{
+		Items: []api.ResourceQuota{*testQuota("foo"), *other},
+	}
+	got, err := rest.List(api.NewDefaultContext(), labels.Everything(), labels.Set{"metadata.namespace": "other"}.AsSelector())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.ResourceQuotaList{
+		Items: []api.ResourceQuota{*other},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/apiserver"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+// REST adapts a secret registry into apiserver's RESTStorage model. Secrets
+// cannot be watched, and lists leave out their data, so that the contents of
+// a secret are only returned when it is asked for by name.
+type REST struct {
+	registry generic.Registry
+	maxSize  int
+}
+
+// NewREST returns a new REST. You must use a registry created by
+// NewEtcdRegistry unless you're testing. The values of a secret may take at
+// most maxSize bytes in total, or api.MaxSecretSize if maxSize is zero.
+func NewREST(registry generic.Registry, maxSize int) *REST {
+	if maxSize == 0 {
+		maxSize = api.MaxSecretSize
+	}
+	return &REST{
+		registry: registry,
+		maxSize:  maxSize,
+	}
+}
+
+func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	secret, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &secret.TypeMeta) {
+		return nil, errors.NewConflict("secret", secret.Namespace, fmt.Errorf("Secret.Namespace does not match the provided context"))
+	}
+	if errs := validation.ValidateSecret(secret, rs.maxSize); len(errs) > 0 {
+		return nil, errors.NewInvalid("secret", secret.ID, errs)
+	}
+	secret.CreationTimestamp = util.Now()
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Create(ctx, secret.ID, secret)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, secret.ID)
+	}), nil
+}
+
+func (rs *REST) Update(ctx api.Context, obj runtime.Object) (<-chan runtime.Object, error) {
+	secret, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	if !api.ValidNamespace(ctx, &secret.TypeMeta) {
+		return nil, errors.NewConflict("secret", secret.Namespace, fmt.Errorf("Secret.Namespace does not match the provided context"))
+	}
+	if errs := validation.ValidateSecret(secret, rs.maxSize); len(errs) > 0 {
+		return nil, errors.NewInvalid("secret", secret.ID, errs)
+	}
+
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		err := rs.registry.Update(ctx, secret.ID, secret)
+		if err != nil {
+			return nil, err
+		}
+		return rs.registry.Get(ctx, secret.ID)
+	}), nil
+}
+
+func (rs *REST) Delete(ctx api.Context, id string) (<-chan runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return apiserver.MakeAsync(func() (runtime.Object, error) {
+		return &api.Status{Status: api.StatusSuccess}, rs.registry.Delete(ctx, id)
+	}), nil
+}
+
+func (rs *REST) Get(ctx api.Context, id string) (runtime.Object, error) {
+	obj, err := rs.registry.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	return secret, err
+}
+
+// getAttrs returns the labels and fields of a secret. Secrets have no labels.
+func getAttrs(obj runtime.Object) (objLabels, objFields labels.Set, err error) {
+	secret, ok := obj.(*api.Secret)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid object type")
+	}
+	return labels.Set{}, labels.Set{
+		"metadata.name":      secret.ID,
+		"metadata.namespace": secret.Namespace,
+	}, nil
+}
+
+// List returns the matching secrets without their data.
+func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Object, error) {
+	obj, err := rs.registry.List(ctx, &generic.SelectionPredicate{label, field, getAttrs})
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*api.SecretList)
+	if !ok {
+		return nil, fmt.Errorf("invalid object type")
+	}
+	for i := range list.Items {
+		list.Items[i].Data = nil
+	}
+	return list, nil
+}
+
+// New returns a new api.Secret
+func (*REST) New() runtime.Object {
+	return &api.Secret{}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg, 0)
+}
+
+func testSecret(id string) *api.Secret {
+	return &api.Secret{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Data:     map[string]string{"data-1": "YmFy"},
+	}
+}
+
+func TestRESTCreate(t *testing.T) {
+	_, rest := NewTestREST()
+	secretA := testSecret("foo")
+	c, err := rest.Create(api.NewDefaultContext(), secretA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := secretA, <-c; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	_, rest := NewTestREST()
+	secretA := testSecret("foo")
+	secretA.Data["a b"] = "d2hvb3Bz"
+	_, err := rest.Create(api.NewDefaultContext(), secretA)
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTCreateTooLarge(t *testing.T) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	rest := NewREST(reg, 4)
+	secretA := testSecret("foo")
+	secretA.Data["data-2"] = "YmF6"
+	_, err := rest.Create(api.NewDefaultContext(), secretA)
+	if !errors.IsInvalid(err) {
+		t.Fatalf("expected an invalid error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "6 bytes, more than the limit of 4 bytes") {
+		t.Errorf("expected the sizes in the error, got %v", err)
+	}
+}
+
+func TestRESTCreateWrongNamespace(t *testing.T) {
+	_, rest := NewTestREST()
+	secretA := testSecret("foo")
+	secretA.Namespace = "other"
+	_, err := rest.Create(api.NewDefaultContext(), secretA)
+	if !errors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestRESTUpdate(t *testing.T) {
+	_, rest := NewTestREST()
+	secretA := testSecret("foo")
+	c, err := rest.Create(api.NewDefaultContext(), secretA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	secretB := testSecret("foo")
+	secretB.Data["data-2"] = "YmF6"
+	c, err = rest.Update(api.NewDefaultContext(), secretB)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	got, err := rest.Get(api.NewDefaultContext(), secretB.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := secretB, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTDelete(t *testing.T) {
+	_, rest := NewTestREST()
+	secretA := testSecret("foo")
+	c, err := rest.Create(api.NewDefaultContext(), secretA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	c, err = rest.Delete(api.NewDefaultContext(), secretA.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if stat := (<-c).(*api.Status); stat.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %v", stat)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	reg, rest := NewTestREST()
+	reg.ObjectList = &api.SecretList{
+		Items: []api.Secret{*testSecret("foo"), *testSecret("bar")},
+	}
+	got, err := rest.List(api.NewDefaultContext(), labels.Everything(), labels.Set{"metadata.name": "foo"}.AsSelector())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.SecretList{
+		Items: []api.Secret{{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}}},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
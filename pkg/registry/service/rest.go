@@ -76,7 +76,7 @@ func (rs *REST) Create(ctx api.Context, obj runtime.Object) (<-chan runtime.Obje
 			if !ok {
 				return nil, fmt.Errorf("The cloud provider does not support zone enumeration.")
 			}
-			hosts, err := rs.machines.ListMinions(ctx)
+			hosts, err := rs.machines.ListMinions(ctx, labels.Everything())
 			if err != nil {
 				return nil, err
 			}
@@ -132,7 +132,7 @@ func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Obj
 	}
 	var filtered []api.Service
 	for _, service := range list.Items {
-		if label.Matches(labels.Set(service.Labels)) {
+		if label.Matches(labels.Set(service.Labels)) && field.Matches(serviceToSelectableFields(&service)) {
 			filtered = append(filtered, service)
 		}
 	}
@@ -140,6 +140,15 @@ func (rs *REST) List(ctx api.Context, label, field labels.Selector) (runtime.Obj
 	return list, err
 }
 
+// serviceToSelectableFields returns the fields of a service that can be selected on.
+func serviceToSelectableFields(service *api.Service) labels.Set {
+	return labels.Set{
+		"ID":                 service.ID,
+		"metadata.name":      service.ID,
+		"metadata.namespace": service.Namespace,
+	}
+}
+
 // Watch returns Services events via a watch.Interface.
 // It implements apiserver.ResourceWatcher.
 func (rs *REST) Watch(ctx api.Context, label, field labels.Selector, resourceVersion string) (watch.Interface, error) {
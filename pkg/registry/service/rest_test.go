@@ -357,6 +357,39 @@ func TestServiceRegistryResourceLocation(t *testing.T) {
 	}
 }
 
+func TestServiceRegistryListFieldSelection(t *testing.T) {
+	ctx := api.NewDefaultContext()
+	table := map[string][]string{
+		"":                          {"foo", "bar"},
+		"ID=foo":                    {"foo"},
+		"metadata.name=bar":         {"bar"},
+		"metadata.namespace=other":  {"bar"},
+		"metadata.namespace!=other": {"foo"},
+		"metadata.name=nonexistent": {},
+	}
+	for selector, expected := range table {
+		field, err := labels.ParseSelector(selector)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", selector, err)
+		}
+		registry := registrytest.NewServiceRegistry()
+		registry.CreateService(ctx, &api.Service{TypeMeta: api.TypeMeta{ID: "foo", Namespace: api.NamespaceDefault}})
+		registry.CreateService(ctx, &api.Service{TypeMeta: api.TypeMeta{ID: "bar", Namespace: "other"}})
+		storage := NewREST(registry, &cloud.FakeCloud{}, registrytest.NewMinionRegistry([]string{"foo"}, api.NodeResources{}))
+		obj, err := storage.List(ctx, labels.Everything(), field)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", selector, err)
+		}
+		ids := []string{}
+		for _, service := range obj.(*api.ServiceList).Items {
+			ids = append(ids, service.ID)
+		}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("%q: expected %v, got %v", selector, expected, ids)
+		}
+	}
+}
+
 func TestServiceRegistryList(t *testing.T) {
 	ctx := api.NewDefaultContext()
 	registry := registrytest.NewServiceRegistry()
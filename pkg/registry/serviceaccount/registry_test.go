@@ -0,0 +1,124 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/testapi"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/generic"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+
+	"github.com/coreos/go-etcd/etcd"
+)
+
+func NewTestServiceAccountEtcdRegistry(t *testing.T) (*tools.FakeEtcdClient, generic.Registry) {
+	f := tools.NewFakeEtcdClient(t)
+	f.TestIndex = true
+	h := tools.EtcdHelper{f, testapi.Codec(), tools.RuntimeVersionAdapter{testapi.ResourceVersioner()}, "/registry", tools.WatchConfig{}}
+	return f, NewEtcdRegistry(h)
+}
+
+func TestServiceAccountCreate(t *testing.T) {
+	accountA := &api.ServiceAccount{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Secrets:  []api.ObjectReference{{Name: "foo-token"}},
+	}
+	accountB := &api.ServiceAccount{
+		TypeMeta: api.TypeMeta{ID: "foo"},
+		Secrets:  []api.ObjectReference{{Name: "bar-token"}},
+	}
+
+	nodeWithAccountA := tools.EtcdResponseWithError{
+		R: &etcd.Response{
+			Node: &etcd.Node{
+				Value:         runtime.EncodeOrDie(testapi.Codec(), accountA),
+				ModifiedIndex: 1,
+				CreatedIndex:  1,
+			},
+		},
+		E: nil,
+	}
+
+	emptyNode := tools.EtcdResponseWithError{
+		R: &etcd.Response{},
+		E: tools.EtcdErrorNotFound,
+	}
+
+	path := "/registry/serviceaccounts/default/foo"
+	key := "foo"
+
+	table := map[string]struct {
+		existing tools.EtcdResponseWithError
+		toCreate runtime.Object
+		errOK    func(error) bool
+	}{
+		"normal": {
+			existing: emptyNode,
+			toCreate: accountA,
+			errOK:    func(err error) bool { return err == nil },
+		},
+		"preExisting": {
+			existing: nodeWithAccountA,
+			toCreate: accountB,
+			errOK:    errors.IsAlreadyExists,
+		},
+	}
+
+	for name, item := range table {
+		fakeClient, registry := NewTestServiceAccountEtcdRegistry(t)
+		fakeClient.Data[path] = item.existing
+		err := registry.Create(api.NewDefaultContext(), key, item.toCreate)
+		if !item.errOK(err) {
+			t.Errorf("%v: unexpected error: %v", name, err)
+		}
+
+		obj, err := registry.Get(api.NewDefaultContext(), key)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", name, err)
+			continue
+		}
+		if e, a := accountA.Secrets, obj.(*api.ServiceAccount).Secrets; !reflect.DeepEqual(e, a) {
+			t.Errorf("%v:\n%s", name, util.ObjectDiff(e, a))
+		}
+	}
+}
+
+func TestServiceAccountNamespaces(t *testing.T) {
+	fakeClient, registry := NewTestServiceAccountEtcdRegistry(t)
+	fakeClient.ExpectNotFoundGet("/registry/serviceaccounts/a/foo")
+	fakeClient.ExpectNotFoundGet("/registry/serviceaccounts/b/foo")
+	ctxA := api.WithNamespace(api.NewContext(), "a")
+	ctxB := api.WithNamespace(api.NewContext(), "b")
+	if err := registry.Create(ctxA, "foo", &api.ServiceAccount{TypeMeta: api.TypeMeta{ID: "foo", Namespace: "a"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := registry.Get(ctxB, "foo"); !errors.IsNotFound(err) {
+		t.Errorf("expected the account of namespace a not to be found in namespace b, got %v", err)
+	}
+	if err := registry.Create(ctxB, "foo", &api.ServiceAccount{TypeMeta: api.TypeMeta{ID: "foo", Namespace: "b"}}); err != nil {
+		t.Errorf("expected an account of the same name to be created in namespace b, got %v", err)
+	}
+	if err := registry.Create(api.NewContext(), "foo", &api.ServiceAccount{TypeMeta: api.TypeMeta{ID: "foo"}}); err == nil {
+		t.Errorf("expected an error without a namespace")
+	}
+}
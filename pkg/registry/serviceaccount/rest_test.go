@@ -0,0 +1,134 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/registry/registrytest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
+)
+
+type testRegistry struct {
+	*registrytest.GenericRegistry
+}
+
+func NewTestREST() (testRegistry, *REST) {
+	reg := testRegistry{registrytest.NewGeneric(nil)}
+	return reg, NewREST(reg)
+}
+
+func testServiceAccount(id string) *api.ServiceAccount {
+	return &api.ServiceAccount{
+		TypeMeta: api.TypeMeta{ID: id, Namespace: api.NamespaceDefault},
+		Secrets:  []api.ObjectReference{{Name: id + "-token"}},
+	}
+}
+
+func TestRESTCreate(t *testing.T) {
+	_, rest := NewTestREST()
+	accountA := testServiceAccount("foo")
+	c, err := rest.Create(api.NewDefaultContext(), accountA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := accountA, <-c; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTCreateInvalid(t *testing.T) {
+	_, rest := NewTestREST()
+	accountA := testServiceAccount("foo")
+	accountA.Secrets = append(accountA.Secrets, api.ObjectReference{})
+	_, err := rest.Create(api.NewDefaultContext(), accountA)
+	if !errors.IsInvalid(err) {
+		t.Errorf("expected an invalid error, got %v", err)
+	}
+}
+
+func TestRESTCreateWrongNamespace(t *testing.T) {
+	_, rest := NewTestREST()
+	accountA := testServiceAccount("foo")
+	accountA.Namespace = "other"
+	_, err := rest.Create(api.NewDefaultContext(), accountA)
+	if !errors.IsConflict(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestRESTUpdate(t *testing.T) {
+	_, rest := NewTestREST()
+	accountA := testServiceAccount("foo")
+	c, err := rest.Create(api.NewDefaultContext(), accountA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	accountB := testServiceAccount("foo")
+	accountB.Secrets = append(accountB.Secrets, api.ObjectReference{Name: "other-token"})
+	c, err = rest.Update(api.NewDefaultContext(), accountB)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	got, err := rest.Get(api.NewDefaultContext(), accountB.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if e, a := accountB, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
+
+func TestRESTDelete(t *testing.T) {
+	_, rest := NewTestREST()
+	accountA := testServiceAccount("foo")
+	c, err := rest.Create(api.NewDefaultContext(), accountA)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	<-c
+	c, err = rest.Delete(api.NewDefaultContext(), accountA.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if stat := (<-c).(*api.Status); stat.Status != api.StatusSuccess {
+		t.Errorf("unexpected status: %v", stat)
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	reg, rest := NewTestREST()
+	reg.ObjectList = &api.ServiceAccountList{
+		Items: []api.ServiceAccount{*testServiceAccount("foo"), *testServiceAccount("bar")},
+	}
+	got, err := rest.List(api.NewDefaultContext(), labels.Everything(), labels.Set{"metadata.name": "foo"}.AsSelector())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := &api.ServiceAccountList{
+		Items: []api.ServiceAccount{*testServiceAccount("foo")},
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("diff: %s", util.ObjectDiff(e, a))
+	}
+}
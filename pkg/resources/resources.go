@@ -27,6 +27,8 @@ import (
 const (
 	CPU    api.ResourceName = "cpu"
 	Memory api.ResourceName = "memory"
+	// Pods is the number of pods, for use in a ResourceQuota.
+	Pods api.ResourceName = "pods"
 )
 
 // TODO: None of these currently handle SI units
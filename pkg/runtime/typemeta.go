@@ -107,6 +107,7 @@ func NewTypeMetaSelfLinker() SelfLinker {
 type TypeMetaInterface interface {
 	ID() string
 	SetID(ID string)
+	Namespace() string
 	APIVersion() string
 	SetAPIVersion(version string)
 	Kind() string
@@ -119,6 +120,7 @@ type TypeMetaInterface interface {
 
 type genericTypeMeta struct {
 	id              *string
+	namespace       *string
 	apiVersion      *string
 	kind            *string
 	resourceVersion *string
@@ -133,6 +135,14 @@ func (g genericTypeMeta) SetID(id string) {
 	*g.id = id
 }
 
+// Namespace returns the namespace of the object, or "" if its TypeMeta has none.
+func (g genericTypeMeta) Namespace() string {
+	if g.namespace == nil {
+		return ""
+	}
+	return *g.namespace
+}
+
 func (g genericTypeMeta) APIVersion() string {
 	return *g.apiVersion
 }
@@ -198,6 +208,11 @@ func newGenericTypeMeta(v reflect.Value) (genericTypeMeta, error) {
 	if err := fieldPtr(v, "ID", &g.id); err != nil {
 		return g, err
 	}
+	if v.FieldByName("Namespace").IsValid() {
+		if err := fieldPtr(v, "Namespace", &g.namespace); err != nil {
+			return g, err
+		}
+	}
 	if err := fieldPtr(v, "APIVersion", &g.apiVersion); err != nil {
 		return g, err
 	}
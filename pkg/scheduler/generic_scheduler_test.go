@@ -137,3 +137,28 @@ func TestGenericScheduler(t *testing.T) {
 		}
 	}
 }
+
+func TestGenericSchedulerNodeSelector(t *testing.T) {
+	minions := makeMinionList([]string{"machine1", "machine2", "machine3"})
+	minions.Items[1].Labels = map[string]string{"disk": "ssd"}
+	predicate := NewSelectorMatchPredicate(StaticNodeInfo{&minions})
+	pod := api.Pod{DesiredState: api.PodState{NodeSelector: map[string]string{"disk": "ssd"}}}
+
+	for i := int64(0); i < 10; i++ {
+		random := rand.New(rand.NewSource(i))
+		scheduler := NewGenericScheduler([]FitPredicate{predicate}, EqualPriority, FakePodLister([]api.Pod{}), random)
+		machine, err := scheduler.Schedule(pod, FakeMinionLister(minions))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if machine != "machine2" {
+			t.Errorf("Expected the pod on the only matching minion, saw: %s", machine)
+		}
+	}
+
+	pod.DesiredState.NodeSelector["disk"] = "hdd"
+	scheduler := NewGenericScheduler([]FitPredicate{predicate}, EqualPriority, FakePodLister([]api.Pod{}), rand.New(rand.NewSource(0)))
+	if _, err := scheduler.Schedule(pod, FakeMinionLister(minions)); err == nil {
+		t.Errorf("Expected an error when no minion matches")
+	}
+}
@@ -106,6 +106,31 @@ func NewResourceFitPredicate(info NodeInfo) FitPredicate {
 	return fit.PodFitsResources
 }
 
+type NodeSelector struct {
+	info NodeInfo
+}
+
+// PodSelectorMatches fits a pod on a minion only if the labels of the minion
+// match the node selector of the pod.
+func (n *NodeSelector) PodSelectorMatches(pod api.Pod, existingPods []api.Pod, node string) (bool, error) {
+	if len(pod.DesiredState.NodeSelector) == 0 {
+		return true, nil
+	}
+	minion, err := n.info.GetNodeInfo(node)
+	if err != nil {
+		return false, err
+	}
+	selector := labels.SelectorFromSet(pod.DesiredState.NodeSelector)
+	return selector.Matches(labels.Set(minion.Labels)), nil
+}
+
+func NewSelectorMatchPredicate(info NodeInfo) FitPredicate {
+	selector := &NodeSelector{
+		info: info,
+	}
+	return selector.PodSelectorMatches
+}
+
 func PodFitsPorts(pod api.Pod, existingPods []api.Pod, node string) (bool, error) {
 	for _, scheduledPod := range existingPods {
 		for _, container := range pod.DesiredState.Manifest.Containers {
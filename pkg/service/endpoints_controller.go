@@ -44,7 +44,7 @@ func NewEndpointController(client *client.Client) *EndpointController {
 
 // SyncServiceEndpoints syncs service endpoints.
 func (e *EndpointController) SyncServiceEndpoints() error {
-	ctx := api.NewContext()
+	ctx := api.WithNamespace(api.NewContext(), api.NamespaceAll)
 	services, err := e.client.ListServices(ctx, labels.Everything())
 	if err != nil {
 		glog.Errorf("Failed to list services: %v", err)
@@ -52,7 +52,7 @@ func (e *EndpointController) SyncServiceEndpoints() error {
 	}
 	var resultErr error
 	for _, service := range services.Items {
-		nsCtx := api.WithNamespace(ctx, service.Namespace)
+		nsCtx := api.WithNamespace(api.NewContext(), service.Namespace)
 		pods, err := e.client.ListPods(nsCtx, labels.Set(service.Selector).AsSelector())
 		if err != nil {
 			glog.Errorf("Error syncing service: %#v, skipping.", service)
@@ -78,7 +78,8 @@ func (e *EndpointController) SyncServiceEndpoints() error {
 			if errors.IsNotFound(err) {
 				currentEndpoints = &api.Endpoints{
 					TypeMeta: api.TypeMeta{
-						ID: service.ID,
+						ID:        service.ID,
+						Namespace: service.Namespace,
 					},
 				}
 			} else {
@@ -181,7 +181,7 @@ func TestSyncEndpointsItemsPreexisting(t *testing.T) {
 	serviceList := api.ServiceList{
 		Items: []api.Service{
 			{
-				TypeMeta: api.TypeMeta{ID: "foo"},
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: "other"},
 				Selector: map[string]string{
 					"foo": "bar",
 				},
@@ -210,14 +210,14 @@ func TestSyncEndpointsItemsPreexisting(t *testing.T) {
 		},
 		Endpoints: []string{"1.2.3.4:8080"},
 	})
-	endpointsHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/endpoints/foo", "PUT", &data)
+	endpointsHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/endpoints/foo?namespace=other", "PUT", &data)
 }
 
 func TestSyncEndpointsItemsPreexistingIdentical(t *testing.T) {
 	serviceList := api.ServiceList{
 		Items: []api.Service{
 			{
-				TypeMeta: api.TypeMeta{ID: "foo"},
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: "other"},
 				Selector: map[string]string{
 					"foo": "bar",
 				},
@@ -238,14 +238,14 @@ func TestSyncEndpointsItemsPreexistingIdentical(t *testing.T) {
 	if err := endpoints.SyncServiceEndpoints(); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	endpointsHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/endpoints/foo", "GET", nil)
+	endpointsHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/endpoints/foo?namespace=other", "GET", nil)
 }
 
 func TestSyncEndpointsItems(t *testing.T) {
 	serviceList := api.ServiceList{
 		Items: []api.Service{
 			{
-				TypeMeta: api.TypeMeta{ID: "foo"},
+				TypeMeta: api.TypeMeta{ID: "foo", Namespace: "other"},
 				Selector: map[string]string{
 					"foo": "bar",
 				},
@@ -267,7 +267,7 @@ func TestSyncEndpointsItems(t *testing.T) {
 		},
 		Endpoints: []string{"1.2.3.4:8080"},
 	})
-	endpointsHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/endpoints", "POST", &data)
+	endpointsHandler.ValidateRequest(t, "/api/"+testapi.Version()+"/endpoints?namespace=other", "POST", &data)
 }
 
 func TestSyncEndpointsPodError(t *testing.T) {
@@ -20,8 +20,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/coreos/go-etcd/etcd"
 )
@@ -100,6 +103,16 @@ type EtcdHelper struct {
 	Codec  runtime.Codec
 	// optional, no atomic operations can be performed without this interface
 	ResourceVersioner EtcdResourceVersioner
+	// PathPrefix is prepended to every key the helper is given, so that
+	// several users of one etcd can keep their keys apart. Optional.
+	PathPrefix string
+	// WatchConfig configures the watches the helper starts. Optional.
+	WatchConfig WatchConfig
+}
+
+// prefixEtcdKey returns the etcd key of key.
+func (h *EtcdHelper) prefixEtcdKey(key string) string {
+	return h.PathPrefix + key
 }
 
 // IsEtcdNotFound returns true iff err is an etcd not found error.
@@ -151,13 +164,27 @@ func (h *EtcdHelper) listEtcdNode(key string) ([]*etcd.Node, uint64, error) {
 			return nodes, index, err
 		}
 	}
-	return result.Node.Nodes, result.EtcdIndex, nil
+	return flattenEtcdNodes(result.Node.Nodes), result.EtcdIndex, nil
+}
+
+// flattenEtcdNodes returns the leaves beneath nodes, descending into directories,
+// so that listing a key also lists the keys nested in its subdirectories.
+func flattenEtcdNodes(nodes []*etcd.Node) []*etcd.Node {
+	leaves := make([]*etcd.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Dir {
+			leaves = append(leaves, flattenEtcdNodes(node.Nodes)...)
+			continue
+		}
+		leaves = append(leaves, node)
+	}
+	return leaves
 }
 
 // ExtractList extracts a go object per etcd node into a slice with the resource version.
 // DEPRECATED: Use ExtractToList instead, it's more convenient.
 func (h *EtcdHelper) ExtractList(key string, slicePtr interface{}, resourceVersion *uint64) error {
-	nodes, index, err := h.listEtcdNode(key)
+	nodes, index, err := h.listEtcdNode(h.prefixEtcdKey(key))
 	if resourceVersion != nil {
 		*resourceVersion = index
 	}
@@ -206,11 +233,109 @@ func (h *EtcdHelper) ExtractToList(key string, listObj runtime.Object) error {
 	return nil
 }
 
+// CountList returns the number of objects stored under key, including those in
+// its subdirectories, without decoding them.
+func (h *EtcdHelper) CountList(key string) (int, error) {
+	nodes, _, err := h.listEtcdNode(h.prefixEtcdKey(key))
+	return len(nodes), err
+}
+
+// ExtractToListPage is like ExtractToList, but only extracts the objects whose
+// keys follow after, in the order of their keys, and only until accept has
+// returned true for limit of them; objects accept returns false for are left
+// out. after is relative to key, e.g. "default/foo" for the key "/pods", and
+// keys are compared segment by segment, so that the objects of a subdirectory
+// all come before those of the next one. Only the objects which are extracted
+// are decoded. It returns true if more objects which accept returns true for
+// follow. A limit of 0 extracts every object after the cursor.
+func (h *EtcdHelper) ExtractToListPage(key string, listObj runtime.Object, after string, limit int, accept func(runtime.Object) (bool, error)) (bool, error) {
+	listPtr, err := runtime.GetItemsPtr(listObj)
+	if err != nil {
+		return false, err
+	}
+	prefix := h.prefixEtcdKey(key)
+	nodes, index, err := h.listEtcdNode(prefix)
+	if err != nil {
+		return false, err
+	}
+	if h.ResourceVersioner != nil {
+		if err := h.ResourceVersioner.SetResourceVersion(listObj, index); err != nil {
+			return false, err
+		}
+	}
+	keys := make([][]string, len(nodes))
+	for i, node := range nodes {
+		keys[i] = splitEtcdKey(strings.TrimPrefix(node.Key, prefix))
+	}
+	sort.Sort(nodesByKey{nodes, keys})
+	cursor := splitEtcdKey(after)
+	start := sort.Search(len(nodes), func(i int) bool { return compareEtcdKeys(keys[i], cursor) > 0 })
+
+	v := reflect.ValueOf(listPtr).Elem()
+	for _, node := range nodes[start:] {
+		obj := reflect.New(v.Type().Elem())
+		if err := h.Codec.DecodeInto([]byte(node.Value), obj.Interface().(runtime.Object)); err != nil {
+			return false, err
+		}
+		if h.ResourceVersioner != nil {
+			_ = h.ResourceVersioner.SetResourceVersion(obj.Interface().(runtime.Object), node.ModifiedIndex)
+		}
+		ok, err := accept(obj.Interface().(runtime.Object))
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+		if limit > 0 && v.Len() == limit {
+			return true, nil
+		}
+		v.Set(reflect.Append(v, obj.Elem()))
+	}
+	return false, nil
+}
+
+// splitEtcdKey returns the segments of a key.
+func splitEtcdKey(key string) []string {
+	key = strings.Trim(key, "/")
+	if len(key) == 0 {
+		return []string{}
+	}
+	return strings.Split(key, "/")
+}
+
+// compareEtcdKeys compares the segments of two keys, returning a negative
+// number if a comes first, a positive one if b does, and 0 if they are equal.
+func compareEtcdKeys(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// nodesByKey sorts etcd nodes by the segments of their keys.
+type nodesByKey struct {
+	nodes []*etcd.Node
+	keys  [][]string
+}
+
+func (s nodesByKey) Len() int { return len(s.nodes) }
+func (s nodesByKey) Swap(i, j int) {
+	s.nodes[i], s.nodes[j] = s.nodes[j], s.nodes[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+func (s nodesByKey) Less(i, j int) bool { return compareEtcdKeys(s.keys[i], s.keys[j]) < 0 }
+
 // ExtractObj unmarshals json found at key into objPtr. On a not found error, will either return
 // a zero object of the requested type, or an error, depending on ignoreNotFound. Treats
 // empty responses and nil response nodes exactly like a not found error.
 func (h *EtcdHelper) ExtractObj(key string, objPtr runtime.Object, ignoreNotFound bool) error {
-	_, _, err := h.bodyAndExtractObj(key, objPtr, ignoreNotFound)
+	_, _, err := h.bodyAndExtractObj(h.prefixEtcdKey(key), objPtr, ignoreNotFound)
 	return err
 }
 
@@ -252,25 +377,47 @@ func (h *EtcdHelper) CreateObj(key string, obj runtime.Object, ttl uint64) error
 		}
 	}
 
-	_, err = h.Client.Create(key, string(data), ttl)
+	_, err = h.Client.Create(h.prefixEtcdKey(key), string(data), ttl)
 	return err
 }
 
 // Delete removes the specified key.
 func (h *EtcdHelper) Delete(key string, recursive bool) error {
-	_, err := h.Client.Delete(key, recursive)
+	_, err := h.Client.Delete(h.prefixEtcdKey(key), recursive)
 	return err
 }
 
 // SetObj marshals obj via json, and stores under key. Will do an
 // atomic update if obj's ResourceVersion field is set.
 func (h *EtcdHelper) SetObj(key string, obj runtime.Object) error {
+	return h.SetObjWithMatch(key, obj, api.ResourceVersionMatchExact)
+}
+
+// SetObjWithMatch is SetObj, but the atomic update done if obj's
+// ResourceVersion field is set fails unless the stored object's version
+// matches it as match tells. The error is then an etcd test failed error.
+func (h *EtcdHelper) SetObjWithMatch(key string, obj runtime.Object, match api.ResourceVersionMatch) error {
+	key = h.prefixEtcdKey(key)
 	data, err := h.Codec.Encode(obj)
 	if err != nil {
 		return err
 	}
 	if h.ResourceVersioner != nil {
 		if version, err := h.ResourceVersioner.ResourceVersion(obj); err == nil && version != 0 {
+			switch match {
+			case api.ResourceVersionMatchExact:
+			case api.ResourceVersionMatchNotOlderThan:
+				response, err := h.Client.Get(key, false, false)
+				if err != nil {
+					return err
+				}
+				if response.Node.ModifiedIndex > version {
+					return EtcdErrorTestFailed
+				}
+				version = response.Node.ModifiedIndex
+			default:
+				return fmt.Errorf("unsupported resource version match %q", match)
+			}
 			_, err = h.Client.CompareAndSwap(key, string(data), 0, "", version)
 			return err // err is shadowed!
 		}
@@ -305,11 +452,18 @@ type EtcdUpdateFunc func(input runtime.Object) (output runtime.Object, err error
 // })
 //
 func (h *EtcdHelper) AtomicUpdate(key string, ptrToType runtime.Object, tryUpdate EtcdUpdateFunc) error {
+	return h.AtomicUpdateWithTTL(key, ptrToType, 0, tryUpdate)
+}
+
+// AtomicUpdateWithTTL is AtomicUpdate, except that the updated object expires
+// after ttl seconds. A ttl of 0 means the object does not expire.
+func (h *EtcdHelper) AtomicUpdateWithTTL(key string, ptrToType runtime.Object, ttl uint64, tryUpdate EtcdUpdateFunc) error {
 	pt := reflect.TypeOf(ptrToType)
 	if pt.Kind() != reflect.Ptr {
 		// Panic is appropriate, because this is a programming error.
 		panic("need ptr to type")
 	}
+	key = h.prefixEtcdKey(key)
 	for {
 		obj := reflect.New(pt.Elem()).Interface().(runtime.Object)
 		origBody, index, err := h.bodyAndExtractObj(key, obj, true)
@@ -329,7 +483,7 @@ func (h *EtcdHelper) AtomicUpdate(key string, ptrToType runtime.Object, tryUpdat
 
 		// First time this key has been used, try creating new value.
 		if index == 0 {
-			_, err = h.Client.Create(key, string(data), 0)
+			_, err = h.Client.Create(key, string(data), ttl)
 			if IsEtcdNodeExist(err) {
 				continue
 			}
@@ -340,7 +494,7 @@ func (h *EtcdHelper) AtomicUpdate(key string, ptrToType runtime.Object, tryUpdat
 			return nil
 		}
 
-		_, err = h.Client.CompareAndSwap(key, string(data), 0, origBody, index)
+		_, err = h.Client.CompareAndSwap(key, string(data), ttl, origBody, index)
 		if IsEtcdTestFailed(err) {
 			continue
 		}
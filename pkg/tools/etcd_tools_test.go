@@ -98,7 +98,7 @@ func TestExtractToList(t *testing.T) {
 	}
 
 	var got api.PodList
-	helper := EtcdHelper{fakeClient, latest.Codec, versioner}
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
 	err := helper.ExtractToList("/some/key", &got)
 	if err != nil {
 		t.Errorf("Unexpected error %v", err)
@@ -108,11 +108,137 @@ func TestExtractToList(t *testing.T) {
 	}
 }
 
+func TestExtractToListNested(t *testing.T) {
+	fakeClient := NewFakeEtcdClient(t)
+	fakeClient.Data["/some/key"] = EtcdResponseWithError{
+		R: &etcd.Response{
+			EtcdIndex: 10,
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{
+						Dir: true,
+						Nodes: []*etcd.Node{
+							{
+								Value:         `{"id":"foo"}`,
+								ModifiedIndex: 1,
+							},
+							{
+								Value:         `{"id":"bar"}`,
+								ModifiedIndex: 2,
+							},
+						},
+					},
+					{
+						Value:         `{"id":"baz"}`,
+						ModifiedIndex: 3,
+					},
+				},
+			},
+		},
+	}
+	expect := api.PodList{
+		TypeMeta: api.TypeMeta{ResourceVersion: "10"},
+		Items: []api.Pod{
+			{TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: "1"}},
+			{TypeMeta: api.TypeMeta{ID: "bar", ResourceVersion: "2"}},
+			{TypeMeta: api.TypeMeta{ID: "baz", ResourceVersion: "3"}},
+		},
+	}
+
+	var got api.PodList
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
+	err := helper.ExtractToList("/some/key", &got)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if e, a := expect, got; !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %#v, got %#v", e, a)
+	}
+
+	count, err := helper.CountList("/some/key")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 objects, got %d", count)
+	}
+	fakeClient.ExpectNotFoundGet("/other/key")
+	if count, err := helper.CountList("/other/key"); err != nil || count != 0 {
+		t.Errorf("Expected no objects, got %d, %v", count, err)
+	}
+}
+
+func TestExtractToListPage(t *testing.T) {
+	fakeClient := NewFakeEtcdClient(t)
+	fakeClient.Data["/some/key"] = EtcdResponseWithError{
+		R: &etcd.Response{
+			EtcdIndex: 10,
+			Node: &etcd.Node{
+				Nodes: []*etcd.Node{
+					{
+						Key: "/some/key/b",
+						Dir: true,
+						Nodes: []*etcd.Node{
+							{Key: "/some/key/b/x", Value: `{"id":"x"}`, ModifiedIndex: 1},
+						},
+					},
+					{
+						Key: "/some/key/a-b",
+						Dir: true,
+						Nodes: []*etcd.Node{
+							{Key: "/some/key/a-b/y", Value: `{"id":"y"}`, ModifiedIndex: 2},
+						},
+					},
+					{
+						Key: "/some/key/a",
+						Dir: true,
+						Nodes: []*etcd.Node{
+							{Key: "/some/key/a/z", Value: `{"id":"z"}`, ModifiedIndex: 3},
+							// Objects before the cursor are not decoded.
+							{Key: "/some/key/a/w", Value: `garbage`, ModifiedIndex: 4},
+						},
+					},
+				},
+			},
+		},
+	}
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
+	notY := func(obj runtime.Object) (bool, error) {
+		return obj.(*api.Pod).ID != "y", nil
+	}
+
+	var got api.PodList
+	more, err := helper.ExtractToListPage("/some/key", &got, "a/w", 1, notY)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect := api.PodList{
+		TypeMeta: api.TypeMeta{ResourceVersion: "10"},
+		Items:    []api.Pod{{TypeMeta: api.TypeMeta{ID: "z", ResourceVersion: "3"}}},
+	}
+	if !more || !reflect.DeepEqual(expect, got) {
+		t.Errorf("Expected %#v and more, got %#v, %v", expect, got, more)
+	}
+
+	got = api.PodList{}
+	more, err = helper.ExtractToListPage("/some/key", &got, "a/z", 0, notY)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expect = api.PodList{
+		TypeMeta: api.TypeMeta{ResourceVersion: "10"},
+		Items:    []api.Pod{{TypeMeta: api.TypeMeta{ID: "x", ResourceVersion: "1"}}},
+	}
+	if more || !reflect.DeepEqual(expect, got) {
+		t.Errorf("Expected %#v and no more, got %#v, %v", expect, got, more)
+	}
+}
+
 func TestExtractObj(t *testing.T) {
 	fakeClient := NewFakeEtcdClient(t)
 	expect := api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
 	fakeClient.Set("/some/key", util.EncodeJSON(expect), 0)
-	helper := EtcdHelper{fakeClient, latest.Codec, versioner}
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
 	var got api.Pod
 	err := helper.ExtractObj("/some/key", &got, false)
 	if err != nil {
@@ -145,7 +271,7 @@ func TestExtractObjNotFoundErr(t *testing.T) {
 			},
 		},
 	}
-	helper := EtcdHelper{fakeClient, codec, versioner}
+	helper := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 	try := func(key string) {
 		var got api.Pod
 		err := helper.ExtractObj(key, &got, false)
@@ -166,7 +292,7 @@ func TestExtractObjNotFoundErr(t *testing.T) {
 func TestCreateObj(t *testing.T) {
 	obj := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
 	fakeClient := NewFakeEtcdClient(t)
-	helper := EtcdHelper{fakeClient, latest.Codec, versioner}
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
 	err := helper.CreateObj("/some/key", obj, 5)
 	if err != nil {
 		t.Errorf("Unexpected error %#v", err)
@@ -187,7 +313,7 @@ func TestCreateObj(t *testing.T) {
 func TestSetObj(t *testing.T) {
 	obj := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
 	fakeClient := NewFakeEtcdClient(t)
-	helper := EtcdHelper{fakeClient, latest.Codec, versioner}
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
 	err := helper.SetObj("/some/key", obj)
 	if err != nil {
 		t.Errorf("Unexpected error %#v", err)
@@ -216,7 +342,7 @@ func TestSetObjWithVersion(t *testing.T) {
 		},
 	}
 
-	helper := EtcdHelper{fakeClient, latest.Codec, versioner}
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
 	err := helper.SetObj("/some/key", obj)
 	if err != nil {
 		t.Fatalf("Unexpected error %#v", err)
@@ -232,10 +358,86 @@ func TestSetObjWithVersion(t *testing.T) {
 	}
 }
 
+func TestSetObjConcurrentWriters(t *testing.T) {
+	fakeClient := NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
+	if err := helper.CreateObj("/some/key", &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}, 0); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+
+	// Both writers read the pod before either of them writes it.
+	first, second := &api.Pod{}, &api.Pod{}
+	for _, pod := range []*api.Pod{first, second} {
+		if err := helper.ExtractObj("/some/key", pod, false); err != nil {
+			t.Fatalf("Unexpected error %#v", err)
+		}
+	}
+	first.Labels = map[string]string{"writer": "first"}
+	second.Labels = map[string]string{"writer": "second"}
+	if err := helper.SetObj("/some/key", first); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if err := helper.SetObj("/some/key", second); !IsEtcdTestFailed(err) {
+		t.Errorf("Expected the second writer to fail, got %#v", err)
+	}
+
+	var stored api.Pod
+	if err := helper.ExtractObj("/some/key", &stored, false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if stored.Labels["writer"] != "first" {
+		t.Errorf("Expected the write of the first writer to be kept, got %#v", stored)
+	}
+}
+
+func TestSetObjWithMatch(t *testing.T) {
+	table := map[string]struct {
+		version  string
+		match    api.ResourceVersionMatch
+		succeeds bool
+	}{
+		"exact":                {"2", api.ResourceVersionMatchExact, true},
+		"exact older":          {"1", api.ResourceVersionMatchExact, false},
+		"exact newer":          {"3", api.ResourceVersionMatchExact, false},
+		"not older than":       {"2", api.ResourceVersionMatchNotOlderThan, true},
+		"not older than older": {"1", api.ResourceVersionMatchNotOlderThan, false},
+		"not older than newer": {"3", api.ResourceVersionMatchNotOlderThan, true},
+	}
+	for name, item := range table {
+		fakeClient := NewFakeEtcdClient(t)
+		fakeClient.TestIndex = true
+		fakeClient.Data["/some/key"] = EtcdResponseWithError{
+			R: &etcd.Response{
+				Node: &etcd.Node{
+					Value:         runtime.EncodeOrDie(latest.Codec, &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}),
+					ModifiedIndex: 2,
+				},
+			},
+		}
+		helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
+		obj := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: item.version}, Labels: map[string]string{"updated": "true"}}
+		err := helper.SetObjWithMatch("/some/key", obj, item.match)
+		if item.succeeds && err != nil {
+			t.Errorf("%s: unexpected error %#v", name, err)
+		}
+		if !item.succeeds && !IsEtcdTestFailed(err) {
+			t.Errorf("%s: expected a test failed error, got %#v", name, err)
+		}
+	}
+
+	fakeClient := NewFakeEtcdClient(t)
+	helper := EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
+	obj := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", ResourceVersion: "1"}}
+	if err := helper.SetObjWithMatch("/some/key", obj, "Newer"); err == nil {
+		t.Errorf("expected an unsupported match to fail")
+	}
+}
+
 func TestSetObjWithoutResourceVersioner(t *testing.T) {
 	obj := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
 	fakeClient := NewFakeEtcdClient(t)
-	helper := EtcdHelper{fakeClient, latest.Codec, nil}
+	helper := EtcdHelper{fakeClient, latest.Codec, nil, "", WatchConfig{}}
 	err := helper.SetObj("/some/key", obj)
 	if err != nil {
 		t.Errorf("Unexpected error %#v", err)
@@ -251,10 +453,44 @@ func TestSetObjWithoutResourceVersioner(t *testing.T) {
 	}
 }
 
+func TestPathPrefix(t *testing.T) {
+	fakeClient := NewFakeEtcdClient(t)
+	a := EtcdHelper{fakeClient, latest.Codec, versioner, "/cluster-a", WatchConfig{}}
+	b := EtcdHelper{fakeClient, latest.Codec, versioner, "/cluster-b", WatchConfig{}}
+	if err := a.CreateObj("/pods/foo", &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}, Labels: map[string]string{"cluster": "a"}}, 0); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if err := b.CreateObj("/pods/foo", &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}, Labels: map[string]string{"cluster": "b"}}, 0); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	for _, key := range []string{"/cluster-a/pods/foo", "/cluster-b/pods/foo"} {
+		if !fakeClient.nodeExists(key) {
+			t.Errorf("Expected %s to be stored", key)
+		}
+	}
+
+	for cluster, helper := range map[string]EtcdHelper{"a": a, "b": b} {
+		var pod api.Pod
+		if err := helper.ExtractObj("/pods/foo", &pod, false); err != nil {
+			t.Fatalf("Unexpected error %#v", err)
+		}
+		if pod.Labels["cluster"] != cluster {
+			t.Errorf("Expected the pod of cluster %s, got %#v", cluster, pod)
+		}
+	}
+
+	if err := a.Delete("/pods/foo", false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if fakeClient.nodeExists("/cluster-a/pods/foo") || !fakeClient.nodeExists("/cluster-b/pods/foo") {
+		t.Errorf("Expected only the pod of cluster a to be deleted, deleted %v", fakeClient.DeletedKeys)
+	}
+}
+
 func TestAtomicUpdate(t *testing.T) {
 	fakeClient := NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	helper := EtcdHelper{fakeClient, codec, versioner}
+	helper := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	// Create a new node.
 	fakeClient.ExpectNotFoundGet("/some/key")
@@ -305,10 +541,29 @@ func TestAtomicUpdate(t *testing.T) {
 	}
 }
 
+func TestAtomicUpdateWithTTL(t *testing.T) {
+	fakeClient := NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	helper := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
+
+	fakeClient.ExpectNotFoundGet("/some/key")
+	for i := 1; i <= 2; i++ {
+		err := helper.AtomicUpdateWithTTL("/some/key", &TestResource{}, 10, func(in runtime.Object) (runtime.Object, error) {
+			return &TestResource{TypeMeta: api.TypeMeta{ID: "foo"}, Value: i}, nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected error %#v", err)
+		}
+		if fakeClient.LastSetTTL != 10 {
+			t.Errorf("%d: expected a TTL of 10, got %d", i, fakeClient.LastSetTTL)
+		}
+	}
+}
+
 func TestAtomicUpdateNoChange(t *testing.T) {
 	fakeClient := NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	helper := EtcdHelper{fakeClient, codec, versioner}
+	helper := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	// Create a new node.
 	fakeClient.ExpectNotFoundGet("/some/key")
@@ -339,7 +594,7 @@ func TestAtomicUpdateNoChange(t *testing.T) {
 func TestAtomicUpdate_CreateCollision(t *testing.T) {
 	fakeClient := NewFakeEtcdClient(t)
 	fakeClient.TestIndex = true
-	helper := EtcdHelper{fakeClient, codec, versioner}
+	helper := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	fakeClient.ExpectNotFoundGet("/some/key")
 
@@ -17,10 +17,12 @@ limitations under the License.
 package tools
 
 import (
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
@@ -42,8 +44,8 @@ func Everything(runtime.Object) bool {
 // watch.Interface. resourceVersion may be used to specify what version to begin
 // watching (e.g., for reconnecting without missing any updates).
 func (h *EtcdHelper) WatchList(key string, resourceVersion uint64, filter FilterFunc) (watch.Interface, error) {
-	w := newEtcdWatcher(true, filter, h.Codec, h.ResourceVersioner, nil)
-	go w.etcdWatch(h.Client, key, resourceVersion)
+	w := newEtcdWatcher(true, filter, h.Codec, h.ResourceVersioner, nil, h.WatchConfig)
+	go w.etcdWatch(h.Client, h.prefixEtcdKey(key), resourceVersion)
 	return w, nil
 }
 
@@ -71,8 +73,8 @@ func (h *EtcdHelper) Watch(key string, resourceVersion uint64) watch.Interface {
 //
 // Errors will be sent down the channel.
 func (h *EtcdHelper) WatchAndTransform(key string, resourceVersion uint64, transform TransformFunc) watch.Interface {
-	w := newEtcdWatcher(false, Everything, h.Codec, h.ResourceVersioner, transform)
-	go w.etcdWatch(h.Client, key, resourceVersion)
+	w := newEtcdWatcher(false, Everything, h.Codec, h.ResourceVersioner, transform, h.WatchConfig)
+	go w.etcdWatch(h.Client, h.prefixEtcdKey(key), resourceVersion)
 	return w
 }
 
@@ -100,14 +102,39 @@ type etcdWatcher struct {
 
 	// Injectable for testing. Send the event down the outgoing channel.
 	emit func(watch.Event)
+
+	// How long an event may wait for the consumer before it is reported as slow.
+	slowConsumerThreshold time.Duration
+	// The number of events the consumer was slow to receive.
+	slowEvents int
 }
 
 // watchWaitDuration is the amount of time to wait for an error from watch.
 const watchWaitDuration = 100 * time.Millisecond
 
+// defaultSlowConsumerThreshold is used when WatchConfig.SlowConsumerThreshold
+// is not set.
+const defaultSlowConsumerThreshold = 5 * time.Second
+
+// WatchConfig configures the watches of an EtcdHelper.
+type WatchConfig struct {
+	// SlowConsumerThreshold is how long a watch event may wait to be received
+	// before the consumer of the watch is reported as falling behind, in the
+	// log and in metrics.WatchEventsDropped. Defaults to 5 seconds.
+	SlowConsumerThreshold time.Duration
+}
+
+// slowConsumerThreshold returns the SlowConsumerThreshold of c, or its default.
+func (c WatchConfig) slowConsumerThreshold() time.Duration {
+	if c.SlowConsumerThreshold > 0 {
+		return c.SlowConsumerThreshold
+	}
+	return defaultSlowConsumerThreshold
+}
+
 // newEtcdWatcher returns a new etcdWatcher; if list is true, watch sub-nodes.  If you provide a transform
 // and a versioner, the versioner must be able to handle the objects that transform creates.
-func newEtcdWatcher(list bool, filter FilterFunc, encoding runtime.Codec, versioner EtcdResourceVersioner, transform TransformFunc) *etcdWatcher {
+func newEtcdWatcher(list bool, filter FilterFunc, encoding runtime.Codec, versioner EtcdResourceVersioner, transform TransformFunc, config WatchConfig) *etcdWatcher {
 	w := &etcdWatcher{
 		encoding:     encoding,
 		versioner:    versioner,
@@ -119,12 +146,31 @@ func newEtcdWatcher(list bool, filter FilterFunc, encoding runtime.Codec, versio
 		etcdStop:     make(chan bool),
 		outgoing:     make(chan watch.Event),
 		userStop:     make(chan struct{}),
+
+		slowConsumerThreshold: config.slowConsumerThreshold(),
 	}
-	w.emit = func(e watch.Event) { w.outgoing <- e }
+	w.emit = w.send
 	go w.translate()
 	return w
 }
 
+// send sends e down the outgoing channel, logging when the consumer takes longer
+// than slowConsumerThreshold to receive it.
+func (w *etcdWatcher) send(e watch.Event) {
+	timer := time.NewTimer(w.slowConsumerThreshold)
+	defer timer.Stop()
+	select {
+	case w.outgoing <- e:
+		return
+	case <-timer.C:
+	}
+	w.slowEvents++
+	resource := reflect.Indirect(reflect.ValueOf(e.Object)).Type().Name()
+	metrics.WatchEventsDropped.Inc(resource)
+	glog.Warningf("WatchEventDropped: watch consumer has not received a %s event for %s within %v (%d slow events on this watch)", e.Type, resource, w.slowConsumerThreshold, w.slowEvents)
+	w.outgoing <- e
+}
+
 // etcdWatch calls etcd's Watch function, and handles any errors. Meant to be called
 // as a goroutine.
 func (w *etcdWatcher) etcdWatch(client EtcdGetSet, key string, resourceVersion uint64) {
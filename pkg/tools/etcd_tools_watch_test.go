@@ -24,6 +24,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/metrics"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/watch"
 	"github.com/coreos/go-etcd/etcd"
@@ -112,7 +113,7 @@ func TestWatchInterpretations(t *testing.T) {
 
 	for name, item := range table {
 		for _, action := range item.actions {
-			w := newEtcdWatcher(true, firstLetterIsB, codec, versioner, nil)
+			w := newEtcdWatcher(true, firstLetterIsB, codec, versioner, nil, WatchConfig{})
 			emitCalled := false
 			w.emit = func(event watch.Event) {
 				emitCalled = true
@@ -150,7 +151,7 @@ func TestWatchInterpretations(t *testing.T) {
 }
 
 func TestWatchInterpretation_ResponseNotSet(t *testing.T) {
-	w := newEtcdWatcher(false, Everything, codec, versioner, nil)
+	w := newEtcdWatcher(false, Everything, codec, versioner, nil, WatchConfig{})
 	w.emit = func(e watch.Event) {
 		t.Errorf("Unexpected emit: %v", e)
 	}
@@ -164,7 +165,7 @@ func TestWatchInterpretation_ResponseNotSet(t *testing.T) {
 func TestWatchInterpretation_ResponseNoNode(t *testing.T) {
 	actions := []string{"create", "set", "compareAndSwap", "delete"}
 	for _, action := range actions {
-		w := newEtcdWatcher(false, Everything, codec, versioner, nil)
+		w := newEtcdWatcher(false, Everything, codec, versioner, nil, WatchConfig{})
 		w.emit = func(e watch.Event) {
 			t.Errorf("Unexpected emit: %v", e)
 		}
@@ -178,7 +179,7 @@ func TestWatchInterpretation_ResponseNoNode(t *testing.T) {
 func TestWatchInterpretation_ResponseBadData(t *testing.T) {
 	actions := []string{"create", "set", "compareAndSwap", "delete"}
 	for _, action := range actions {
-		w := newEtcdWatcher(false, Everything, codec, versioner, nil)
+		w := newEtcdWatcher(false, Everything, codec, versioner, nil, WatchConfig{})
 		w.emit = func(e watch.Event) {
 			t.Errorf("Unexpected emit: %v", e)
 		}
@@ -198,12 +199,41 @@ func TestWatchInterpretation_ResponseBadData(t *testing.T) {
 	}
 }
 
+func TestWatchSlowConsumer(t *testing.T) {
+	if e, a := defaultSlowConsumerThreshold, (WatchConfig{}).slowConsumerThreshold(); e != a {
+		t.Errorf("Expected the default threshold %v, got %v", e, a)
+	}
+	w := newEtcdWatcher(false, Everything, codec, versioner, nil, WatchConfig{SlowConsumerThreshold: time.Millisecond})
+	pod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
+	dropped := metrics.WatchEventsDropped.Value("Pod")
+
+	go w.emit(watch.Event{Type: watch.Added, Object: pod})
+	time.Sleep(20 * time.Millisecond)
+	if e, a := pod, (<-w.ResultChan()).Object; !reflect.DeepEqual(e, a) {
+		t.Errorf("Expected %#v, got %#v", e, a)
+	}
+	if w.slowEvents != 1 {
+		t.Errorf("Expected 1 slow event, got %d", w.slowEvents)
+	}
+	if e, a := dropped+1, metrics.WatchEventsDropped.Value("Pod"); e != a {
+		t.Errorf("Expected %v dropped pod events, got %v", e, a)
+	}
+
+	w.slowConsumerThreshold = time.Minute
+	go w.emit(watch.Event{Type: watch.Modified, Object: pod})
+	<-w.ResultChan()
+	if w.slowEvents != 1 {
+		t.Errorf("Expected 1 slow event, got %d", w.slowEvents)
+	}
+	w.Stop()
+}
+
 func TestWatchEtcdError(t *testing.T) {
 	codec := latest.Codec
 	fakeClient := NewFakeEtcdClient(t)
 	fakeClient.expectNotFoundGetSet["/some/key"] = struct{}{}
 	fakeClient.WatchImmediateError = fmt.Errorf("immediate error")
-	h := EtcdHelper{fakeClient, codec, versioner}
+	h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	got := <-h.Watch("/some/key", 4).ResultChan()
 	if got.Type != watch.Error {
@@ -225,7 +255,7 @@ func TestWatch(t *testing.T) {
 	codec := latest.Codec
 	fakeClient := NewFakeEtcdClient(t)
 	fakeClient.expectNotFoundGetSet["/some/key"] = struct{}{}
-	h := EtcdHelper{fakeClient, codec, versioner}
+	h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	watching := h.Watch("/some/key", 0)
 
@@ -366,7 +396,7 @@ func TestWatchEtcdState(t *testing.T) {
 		for key, value := range testCase.Initial {
 			fakeClient.Data[key] = value
 		}
-		h := EtcdHelper{fakeClient, codec, versioner}
+		h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 		watching := h.Watch("/somekey/foo", testCase.From)
 		fakeClient.WaitForWatchCompletion()
 
@@ -433,7 +463,7 @@ func TestWatchFromZeroIndex(t *testing.T) {
 	for k, testCase := range testCases {
 		fakeClient := NewFakeEtcdClient(t)
 		fakeClient.Data["/some/key"] = testCase.Response
-		h := EtcdHelper{fakeClient, codec, versioner}
+		h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 		watching := h.Watch("/some/key", 0)
 
@@ -490,7 +520,7 @@ func TestWatchListFromZeroIndex(t *testing.T) {
 			EtcdIndex: 3,
 		},
 	}
-	h := EtcdHelper{fakeClient, codec, versioner}
+	h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	watching, err := h.WatchList("/some/key", 0, Everything)
 	if err != nil {
@@ -534,7 +564,7 @@ func TestWatchFromNotFound(t *testing.T) {
 			ErrorCode: 100,
 		},
 	}
-	h := EtcdHelper{fakeClient, codec, versioner}
+	h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	watching := h.Watch("/some/key", 0)
 
@@ -557,7 +587,7 @@ func TestWatchFromOtherError(t *testing.T) {
 			ErrorCode: 101,
 		},
 	}
-	h := EtcdHelper{fakeClient, codec, versioner}
+	h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 
 	watching := h.Watch("/some/key", 0)
 
@@ -585,7 +615,7 @@ func TestWatchFromOtherError(t *testing.T) {
 
 func TestWatchPurposefulShutdown(t *testing.T) {
 	fakeClient := NewFakeEtcdClient(t)
-	h := EtcdHelper{fakeClient, codec, versioner}
+	h := EtcdHelper{fakeClient, codec, versioner, "", WatchConfig{}}
 	fakeClient.expectNotFoundGetSet["/some/key"] = struct{}{}
 
 	// Test purposeful shutdown
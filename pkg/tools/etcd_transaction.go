@@ -0,0 +1,302 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"reflect"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+
+	"github.com/coreos/go-etcd/etcd"
+	"github.com/golang/glog"
+)
+
+// EtcdTransaction buffers the writes made through it to the keys of an
+// EtcdHelper, and applies them together when it is committed. Every key it
+// reads or writes is read from etcd once, and the commit fails with an etcd
+// test failed error, writing nothing, if any of them changed since.
+//
+// etcd has no transactions spanning several keys, so the writes are applied
+// one by one, each conditioned on the key being unchanged. If one of them
+// fails, the writes applied before it are undone. A transaction whose commit
+// is interrupted, for example because the process exits, may be partially
+// applied.
+type EtcdTransaction struct {
+	helper *EtcdHelper
+	// read holds the state of every key the transaction used as it was read
+	// from etcd, by etcd key.
+	read map[string]txKeyState
+	// pending holds the state of every key written in the transaction, by etcd
+	// key. written holds the same keys in the order they were first written.
+	pending map[string]txKeyState
+	written []string
+}
+
+// txKeyState is the value of a key, or the absence of one.
+type txKeyState struct {
+	exists bool
+	value  string
+	// index is the index the key was last modified at, if it was read from etcd.
+	index uint64
+}
+
+// NewTransaction returns an empty EtcdTransaction on the keys of h.
+func (h *EtcdHelper) NewTransaction() *EtcdTransaction {
+	return &EtcdTransaction{
+		helper:  h,
+		read:    map[string]txKeyState{},
+		pending: map[string]txKeyState{},
+	}
+}
+
+// state returns the state of key as the transaction sees it, reading it from
+// etcd the first time.
+func (t *EtcdTransaction) state(key string) (txKeyState, error) {
+	if state, ok := t.pending[key]; ok {
+		return state, nil
+	}
+	if state, ok := t.read[key]; ok {
+		return state, nil
+	}
+	response, err := t.helper.Client.Get(key, false, false)
+	if err != nil && !IsEtcdNotFound(err) {
+		return txKeyState{}, err
+	}
+	state := txKeyState{}
+	if err == nil && response.Node != nil {
+		state = txKeyState{exists: true, value: response.Node.Value, index: response.Node.ModifiedIndex}
+	}
+	t.read[key] = state
+	return state, nil
+}
+
+// write records that key has state once the transaction is committed.
+func (t *EtcdTransaction) write(key string, state txKeyState) {
+	if _, ok := t.pending[key]; !ok {
+		t.written = append(t.written, key)
+	}
+	t.pending[key] = state
+}
+
+// ExtractObj unmarshals the object stored at key into objPtr, as it is in the
+// transaction, like EtcdHelper.ExtractObj. The object carries the resource
+// version key was read from etcd at, if it was in use then.
+func (t *EtcdTransaction) ExtractObj(key string, objPtr runtime.Object, ignoreNotFound bool) error {
+	key = t.helper.prefixEtcdKey(key)
+	state, err := t.state(key)
+	if err != nil {
+		return err
+	}
+	if !state.exists {
+		if ignoreNotFound {
+			pv := reflect.ValueOf(objPtr)
+			pv.Elem().Set(reflect.Zero(pv.Type().Elem()))
+			return nil
+		}
+		return EtcdErrorNotFound
+	}
+	if err := t.helper.Codec.DecodeInto([]byte(state.value), objPtr); err != nil {
+		return err
+	}
+	if index := t.read[key].index; t.helper.ResourceVersioner != nil && index != 0 {
+		_ = t.helper.ResourceVersioner.SetResourceVersion(objPtr, index)
+	}
+	return nil
+}
+
+// CreateObj stores obj at key when the transaction is committed. It fails
+// with an etcd node exist error if key is already in use.
+func (t *EtcdTransaction) CreateObj(key string, obj runtime.Object) error {
+	key = t.helper.prefixEtcdKey(key)
+	state, err := t.state(key)
+	if err != nil {
+		return err
+	}
+	if state.exists {
+		return EtcdErrorNodeExist
+	}
+	data, err := t.helper.Codec.Encode(obj)
+	if err != nil {
+		return err
+	}
+	t.write(key, txKeyState{exists: true, value: string(data)})
+	return nil
+}
+
+// SetObj stores obj at key when the transaction is committed, like
+// EtcdHelper.SetObj. If obj carries a resource version, it fails with an etcd
+// test failed error unless key was read at that version and is still in use.
+// Otherwise it fails with an etcd node exist error if key is in use, unless the
+// transaction created it, since such a key has no resource version yet.
+func (t *EtcdTransaction) SetObj(key string, obj runtime.Object) error {
+	key = t.helper.prefixEtcdKey(key)
+	state, err := t.state(key)
+	if err != nil {
+		return err
+	}
+	data, err := t.helper.Codec.Encode(obj)
+	if err != nil {
+		return err
+	}
+	var version uint64
+	if t.helper.ResourceVersioner != nil {
+		if v, err := t.helper.ResourceVersioner.ResourceVersion(obj); err == nil {
+			version = v
+		}
+	}
+	switch {
+	case version != 0 && (version != t.read[key].index || !state.exists):
+		return EtcdErrorTestFailed
+	case version == 0 && state.exists && t.read[key].exists:
+		return EtcdErrorNodeExist
+	}
+	t.write(key, txKeyState{exists: true, value: string(data)})
+	return nil
+}
+
+// Delete removes key when the transaction is committed. It fails with an etcd
+// not found error if key is not in use.
+func (t *EtcdTransaction) Delete(key string) error {
+	key = t.helper.prefixEtcdKey(key)
+	state, err := t.state(key)
+	if err != nil {
+		return err
+	}
+	if !state.exists {
+		return EtcdErrorNotFound
+	}
+	t.write(key, txKeyState{})
+	return nil
+}
+
+// Commit applies the writes of the transaction. It fails with an etcd test
+// failed error, after undoing any writes it applied, if a key the transaction
+// used changed since it was read.
+func (t *EtcdTransaction) Commit() error {
+	for key, state := range t.read {
+		if _, ok := t.pending[key]; ok {
+			// Written keys are checked as they are written.
+			continue
+		}
+		current, err := t.current(key)
+		if err != nil {
+			return err
+		}
+		if current != state.index {
+			return EtcdErrorTestFailed
+		}
+	}
+	// applied holds the keys written so far, and the index each was written at.
+	applied := []string{}
+	indexes := map[string]uint64{}
+	for _, key := range t.written {
+		index, err := t.apply(key, t.read[key], t.pending[key])
+		if err != nil {
+			t.undo(applied, indexes)
+			return err
+		}
+		applied = append(applied, key)
+		indexes[key] = index
+	}
+	return nil
+}
+
+// current returns the index key was last modified at, or 0 if it is not in use.
+func (t *EtcdTransaction) current(key string) (uint64, error) {
+	response, err := t.helper.Client.Get(key, false, false)
+	if IsEtcdNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if response.Node == nil {
+		return 0, nil
+	}
+	return response.Node.ModifiedIndex, nil
+}
+
+// apply changes key from the state it was read in to the state it was
+// written to, and returns the index it was modified at.
+func (t *EtcdTransaction) apply(key string, from, to txKeyState) (uint64, error) {
+	switch {
+	case !from.exists && !to.exists:
+		return 0, nil
+	case !from.exists:
+		response, err := t.helper.Client.Create(key, to.value, 0)
+		if err != nil {
+			return 0, err
+		}
+		return modifiedIndex(response), nil
+	case to.exists:
+		response, err := t.helper.Client.CompareAndSwap(key, to.value, 0, "", from.index)
+		if err != nil {
+			return 0, err
+		}
+		return modifiedIndex(response), nil
+	default:
+		// EtcdGetSet has no compare and delete, so the key is checked just
+		// before it is deleted.
+		current, err := t.current(key)
+		if err != nil {
+			return 0, err
+		}
+		if current != from.index {
+			return 0, EtcdErrorTestFailed
+		}
+		_, err = t.helper.Client.Delete(key, false)
+		return 0, err
+	}
+}
+
+// undo restores the keys of a failed commit, which were written at indexes,
+// to the state they were read in, in reverse order. A key which was changed by
+// someone else since the commit wrote it is left alone.
+func (t *EtcdTransaction) undo(keys []string, indexes map[string]uint64) {
+	for i := len(keys) - 1; i >= 0; i-- {
+		key := keys[i]
+		from, to := t.read[key], t.pending[key]
+		var err error
+		switch {
+		case !from.exists && !to.exists:
+		case !from.exists:
+			// As in apply, the key is checked just before it is deleted.
+			var current uint64
+			if current, err = t.current(key); err == nil {
+				if current != indexes[key] {
+					err = EtcdErrorTestFailed
+				} else {
+					_, err = t.helper.Client.Delete(key, false)
+				}
+			}
+		case to.exists:
+			_, err = t.helper.Client.CompareAndSwap(key, from.value, 0, "", indexes[key])
+		default:
+			_, err = t.helper.Client.Create(key, from.value, 0)
+		}
+		if err != nil {
+			glog.Errorf("Unable to undo the write of %s by a failed transaction: %v", key, err)
+		}
+	}
+}
+
+func modifiedIndex(response *etcd.Response) uint64 {
+	if response == nil || response.Node == nil {
+		return 0
+	}
+	return response.Node.ModifiedIndex
+}
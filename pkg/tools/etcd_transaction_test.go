@@ -0,0 +1,276 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/latest"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
+	"github.com/coreos/go-etcd/etcd"
+)
+
+func newTransactionHelper(t *testing.T, pods ...*api.Pod) (*FakeEtcdClient, *EtcdHelper) {
+	fakeClient := NewFakeEtcdClient(t)
+	fakeClient.TestIndex = true
+	helper := &EtcdHelper{fakeClient, latest.Codec, versioner, "", WatchConfig{}}
+	for _, pod := range pods {
+		if err := helper.CreateObj("/pods/"+pod.ID, pod, 0); err != nil {
+			t.Fatalf("Unexpected error %#v", err)
+		}
+	}
+	return fakeClient, helper
+}
+
+func storedPod(t *testing.T, fakeClient *FakeEtcdClient, key string) *api.Pod {
+	result := fakeClient.Data[key]
+	if result.R == nil || result.R.Node == nil {
+		return nil
+	}
+	var pod api.Pod
+	if err := latest.Codec.DecodeInto([]byte(result.R.Node.Value), &pod); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	return &pod
+}
+
+// changePod sets the host of the pod stored at key, as another writer would.
+func changePod(t *testing.T, helper *EtcdHelper, key, host string) {
+	var pod api.Pod
+	if err := helper.ExtractObj(key, &pod, false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	pod.DesiredState.Host = host
+	if err := helper.SetObj(key, &pod); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+}
+
+func TestEtcdTransactionCommit(t *testing.T) {
+	fakeClient, helper := newTransactionHelper(t,
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "update"}},
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "delete"}},
+	)
+	fakeClient.ExpectNotFoundGet("/pods/create")
+	tx := helper.NewTransaction()
+
+	if err := tx.CreateObj("/pods/create", &api.Pod{TypeMeta: api.TypeMeta{ID: "create"}}); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	var pod api.Pod
+	if err := tx.ExtractObj("/pods/update", &pod, false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if pod.ResourceVersion != "1" {
+		t.Errorf("Expected the resource version to be set, got %#v", pod)
+	}
+	pod.Labels = map[string]string{"updated": "true"}
+	if err := tx.SetObj("/pods/update", &pod); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if err := tx.Delete("/pods/delete"); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if err := tx.ExtractObj("/pods/delete", &pod, false); !IsEtcdNotFound(err) {
+		t.Errorf("Expected the transaction to see its own delete, got %#v", err)
+	}
+
+	if storedPod(t, fakeClient, "/pods/create") != nil || len(fakeClient.DeletedKeys) != 0 {
+		t.Fatalf("Expected nothing to be written before the commit")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if storedPod(t, fakeClient, "/pods/create") == nil {
+		t.Errorf("Expected the pod to be created")
+	}
+	if updated := storedPod(t, fakeClient, "/pods/update"); updated == nil || updated.Labels["updated"] != "true" {
+		t.Errorf("Expected the pod to be updated, got %#v", updated)
+	}
+	if storedPod(t, fakeClient, "/pods/delete") != nil {
+		t.Errorf("Expected the pod to be deleted")
+	}
+}
+
+func TestEtcdTransactionConflict(t *testing.T) {
+	fakeClient, helper := newTransactionHelper(t,
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "read"}},
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "write"}},
+	)
+	tx := helper.NewTransaction()
+
+	var pod api.Pod
+	if err := tx.ExtractObj("/pods/read", &pod, false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if err := tx.ExtractObj("/pods/write", &pod, false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	pod.Labels = map[string]string{"tx": "true"}
+	if err := tx.SetObj("/pods/write", &pod); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	// An object carrying a stale resource version is rejected at once.
+	stale := &api.Pod{TypeMeta: api.TypeMeta{ID: "read", ResourceVersion: "100"}}
+	if err := tx.SetObj("/pods/read", stale); !IsEtcdTestFailed(err) {
+		t.Errorf("Expected a test failed error, got %#v", err)
+	}
+
+	// Someone else changes a pod the transaction only read.
+	changePod(t, helper, "/pods/read", "other")
+	if err := tx.Commit(); !IsEtcdTestFailed(err) {
+		t.Fatalf("Expected a test failed error, got %#v", err)
+	}
+	if written := storedPod(t, fakeClient, "/pods/write"); written.Labels["tx"] == "true" {
+		t.Errorf("Expected nothing to be written, got %#v", written)
+	}
+}
+
+func TestEtcdTransactionUndo(t *testing.T) {
+	fakeClient, helper := newTransactionHelper(t,
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "update"}},
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "conflict"}},
+		&api.Pod{TypeMeta: api.TypeMeta{ID: "delete"}},
+	)
+	fakeClient.ExpectNotFoundGet("/pods/create")
+	original := fakeClient.Data["/pods/update"].R.Node.Value
+	tx := helper.NewTransaction()
+
+	if err := tx.CreateObj("/pods/create", &api.Pod{TypeMeta: api.TypeMeta{ID: "create"}}); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	for _, key := range []string{"/pods/update", "/pods/conflict"} {
+		var pod api.Pod
+		if err := tx.ExtractObj(key, &pod, false); err != nil {
+			t.Fatalf("Unexpected error %#v", err)
+		}
+		pod.DesiredState.Host = "machine"
+		if err := tx.SetObj(key, &pod); err != nil {
+			t.Fatalf("Unexpected error %#v", err)
+		}
+		if key == "/pods/update" {
+			if err := tx.Delete("/pods/delete"); err != nil {
+				t.Fatalf("Unexpected error %#v", err)
+			}
+		}
+	}
+
+	// Someone else changes the last pod the transaction writes, so the writes
+	// before it are undone.
+	changePod(t, helper, "/pods/conflict", "other")
+	if err := tx.Commit(); !IsEtcdTestFailed(err) {
+		t.Fatalf("Expected a test failed error, got %#v", err)
+	}
+	if storedPod(t, fakeClient, "/pods/create") != nil {
+		t.Errorf("Expected the created pod to be removed")
+	}
+	if e, a := original, fakeClient.Data["/pods/update"].R.Node.Value; e != a {
+		t.Errorf("Expected the updated pod to be restored to %s, got %s", e, a)
+	}
+	if storedPod(t, fakeClient, "/pods/delete") == nil {
+		t.Errorf("Expected the deleted pod to be restored")
+	}
+	if conflict := storedPod(t, fakeClient, "/pods/conflict"); conflict.DesiredState.Host != "other" {
+		t.Errorf("Expected the concurrent write to be kept, got %#v", conflict)
+	}
+}
+
+// casHookClient calls before ahead of every compare and swap.
+type casHookClient struct {
+	*FakeEtcdClient
+	before func(key string)
+}
+
+func (c *casHookClient) CompareAndSwap(key, value string, ttl uint64, prevValue string, prevIndex uint64) (*etcd.Response, error) {
+	c.before(key)
+	return c.FakeEtcdClient.CompareAndSwap(key, value, ttl, prevValue, prevIndex)
+}
+
+func TestEtcdTransactionUndoKeepsConcurrentCreate(t *testing.T) {
+	fakeClient, helper := newTransactionHelper(t, &api.Pod{TypeMeta: api.TypeMeta{ID: "conflict"}})
+	fakeClient.ExpectNotFoundGet("/pods/create")
+	tx := helper.NewTransaction()
+
+	if err := tx.CreateObj("/pods/create", &api.Pod{TypeMeta: api.TypeMeta{ID: "create"}}); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	var pod api.Pod
+	if err := tx.ExtractObj("/pods/conflict", &pod, false); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	pod.DesiredState.Host = "machine"
+	if err := tx.SetObj("/pods/conflict", &pod); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+
+	// Once the created pod is written, someone else changes it and the pod the
+	// transaction writes next, so the commit fails and undoes the create.
+	changed := false
+	helper.Client = &casHookClient{fakeClient, func(key string) {
+		if key == "/pods/conflict" && !changed {
+			changed = true
+			changePod(t, helper, "/pods/create", "other")
+			changePod(t, helper, "/pods/conflict", "other")
+		}
+	}}
+	if err := tx.Commit(); !IsEtcdTestFailed(err) {
+		t.Fatalf("Expected a test failed error, got %#v", err)
+	}
+	if created := storedPod(t, fakeClient, "/pods/create"); created == nil || created.DesiredState.Host != "other" {
+		t.Errorf("Expected the concurrent write to the created pod to be kept, got %#v", created)
+	}
+}
+
+func TestEtcdTransactionExtractObjNotFound(t *testing.T) {
+	fakeClient, helper := newTransactionHelper(t)
+	fakeClient.ExpectNotFoundGet("/some/key")
+	fakeClient.Data["/other/key"] = EtcdResponseWithError{
+		R: &etcd.Response{Node: &etcd.Node{Value: runtime.EncodeOrDie(latest.Codec, &api.Pod{}), ModifiedIndex: 1}},
+	}
+	tx := helper.NewTransaction()
+
+	pod := api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
+	if err := tx.ExtractObj("/some/key", &pod, true); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if pod.ID != "" {
+		t.Errorf("Expected a zero pod, got %#v", pod)
+	}
+	if err := tx.ExtractObj("/some/key", &pod, false); !IsEtcdNotFound(err) {
+		t.Errorf("Expected a not found error, got %#v", err)
+	}
+	if err := tx.Delete("/some/key"); !IsEtcdNotFound(err) {
+		t.Errorf("Expected a not found error, got %#v", err)
+	}
+	if err := tx.CreateObj("/other/key", &pod); !IsEtcdNodeExist(err) {
+		t.Errorf("Expected a node exist error, got %#v", err)
+	}
+	if err := tx.SetObj("/other/key", &pod); !IsEtcdNodeExist(err) {
+		t.Errorf("Expected a node exist error, got %#v", err)
+	}
+	// A key the transaction created has no resource version to update it at.
+	if err := tx.CreateObj("/some/key", &pod); err != nil {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+	if err := tx.SetObj("/some/key", &pod); err != nil {
+		t.Errorf("Unexpected error %#v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Unexpected error %#v", err)
+	}
+}
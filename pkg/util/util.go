@@ -51,12 +51,27 @@ func HandleCrash() {
 
 // Forever loops forever running f every d.  Catches any panics, and keeps going.
 func Forever(f func(), period time.Duration) {
+	Until(f, period, nil)
+}
+
+// Until loops until stopCh is closed, running f every d.  Catches any panics, and
+// keeps going.  A nil stopCh never closes, so Until behaves like Forever.
+func Until(f func(), period time.Duration, stopCh <-chan struct{}) {
 	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
 		func() {
 			defer HandleCrash()
 			f()
 		}()
-		time.Sleep(period)
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(period):
+		}
 	}
 }
 
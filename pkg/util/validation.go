@@ -18,6 +18,7 @@ package util
 
 import (
 	"regexp"
+	"strings"
 )
 
 const dnsLabelFmt string = "[a-z0-9]([-a-z0-9]*[a-z0-9])?"
@@ -68,3 +69,39 @@ const dns952MaxLength = 24
 func IsDNS952Label(value string) bool {
 	return len(value) <= dns952MaxLength && dns952Regexp.MatchString(value)
 }
+
+const imageDigestFmt string = "sha256:[a-f0-9]{64}"
+
+var imageDigestRegexp = regexp.MustCompile("^" + imageDigestFmt + "$")
+
+// IsImageDigest tests for a string that is a sha256 content digest of a container image.
+func IsImageDigest(value string) bool {
+	return imageDigestRegexp.MatchString(value)
+}
+
+const labelKeyNameFmt string = "[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?"
+
+var labelKeyNameRegexp = regexp.MustCompile("^" + labelKeyNameFmt + "$")
+
+const labelKeyNameMaxLength int = 63
+
+// IsLabelKey tests for a string that is a valid label key: a name of at most 63
+// alphanumeric characters, dashes, underscores and dots, optionally prefixed by a
+// DNS subdomain and a slash (e.g. "example.com/name").
+func IsLabelKey(value string) bool {
+	name := value
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		if !IsDNSSubdomain(value[:i]) {
+			return false
+		}
+		name = value[i+1:]
+	}
+	return len(name) <= labelKeyNameMaxLength && labelKeyNameRegexp.MatchString(name)
+}
+
+// IsLabelValue tests for a string that is a valid label value: empty, or at most
+// 63 alphanumeric characters, dashes, underscores and dots, starting and ending
+// with an alphanumeric character.
+func IsLabelValue(value string) bool {
+	return len(value) == 0 || (len(value) <= labelKeyNameMaxLength && labelKeyNameRegexp.MatchString(value))
+}
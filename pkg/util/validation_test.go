@@ -147,3 +147,76 @@ func TestIsDNS952(t *testing.T) {
 		}
 	}
 }
+
+func TestIsImageDigest(t *testing.T) {
+	goodValues := []string{
+		"sha256:" + strings.Repeat("0", 64),
+		"sha256:" + strings.Repeat("a1", 32),
+	}
+	for _, val := range goodValues {
+		if !IsImageDigest(val) {
+			t.Errorf("expected true for '%s'", val)
+		}
+	}
+
+	badValues := []string{
+		"", "sha256:", "sha256:abc",
+		"sha256:" + strings.Repeat("A", 64),
+		"sha256:" + strings.Repeat("0", 65),
+		"md5:" + strings.Repeat("0", 64),
+		strings.Repeat("0", 64),
+	}
+	for _, val := range badValues {
+		if IsImageDigest(val) {
+			t.Errorf("expected false for '%s'", val)
+		}
+	}
+}
+
+func TestIsLabelKey(t *testing.T) {
+	goodValues := []string{
+		"a", "A", "1", "a-b", "a_b", "a.b", "Abc123",
+		"example.com/a", "a.b.c/Name_1",
+		strings.Repeat("a", 63),
+		"example.com/" + strings.Repeat("a", 63),
+	}
+	for _, val := range goodValues {
+		if !IsLabelKey(val) {
+			t.Errorf("expected true for '%s'", val)
+		}
+	}
+
+	badValues := []string{
+		"", "-a", "a-", "_a", "a b", "a/b/c", "/a", "a/",
+		"Example.com/a", "example..com/a",
+		strings.Repeat("a", 64),
+		"example.com/" + strings.Repeat("a", 64),
+	}
+	for _, val := range badValues {
+		if IsLabelKey(val) {
+			t.Errorf("expected false for '%s'", val)
+		}
+	}
+}
+
+func TestIsLabelValue(t *testing.T) {
+	goodValues := []string{
+		"", "a", "A", "1", "a-b", "a_b", "a.b", "Abc123",
+		strings.Repeat("a", 63),
+	}
+	for _, val := range goodValues {
+		if !IsLabelValue(val) {
+			t.Errorf("expected true for '%s'", val)
+		}
+	}
+
+	badValues := []string{
+		"-a", "a-", "_a", "a b", "a/b", "a=b", "a,b",
+		strings.Repeat("a", 64),
+	}
+	for _, val := range badValues {
+		if IsLabelValue(val) {
+			t.Errorf("expected false for '%s'", val)
+		}
+	}
+}
@@ -72,6 +72,8 @@ func (factory *ConfigFactory) Create() *scheduler.Config {
 			algorithm.PodFitsPorts,
 			// Fit is determined by resource availability
 			algorithm.NewResourceFitPredicate(minionLister),
+			// Fit is determined by the node selector of the pod.
+			algorithm.NewSelectorMatchPredicate(minionLister),
 		},
 		// Prioritize nodes by least requested utilization.
 		algorithm.LeastRequestedPriority,
@@ -107,6 +109,7 @@ type listWatch struct {
 func (lw *listWatch) List() (runtime.Object, error) {
 	return lw.client.
 		Get().
+		Namespace(api.NamespaceAllParam).
 		Path(lw.resource).
 		SelectorParam("fields", lw.fieldSelector).
 		Do().
@@ -116,6 +119,7 @@ func (lw *listWatch) List() (runtime.Object, error) {
 func (lw *listWatch) Watch(resourceVersion string) (watch.Interface, error) {
 	return lw.client.
 		Get().
+		Namespace(api.NamespaceAllParam).
 		Path("watch").
 		Path(lw.resource).
 		SelectorParam("fields", lw.fieldSelector).
@@ -178,17 +182,22 @@ func (factory *ConfigFactory) makeDefaultErrorFunc(backoff *podBackoff, podQueue
 		// Note that this is extremely rudimentary and we need a more real error handling path.
 		go func() {
 			defer util.HandleCrash()
-			podID := pod.ID
-			backoff.wait(podID)
+			podID, namespace := pod.ID, pod.Namespace
+			backoff.wait(namespace + "/" + podID)
 			// Get the pod again; it may have changed/been scheduled already.
 			pod = &api.Pod{}
-			err := factory.Client.Get().Path("pods").Path(podID).Do().Into(pod)
+			err := factory.Client.Get().Namespace(namespace).Path("pods").Path(podID).Do().Into(pod)
 			if err != nil {
 				glog.Errorf("Error getting pod %v for retry: %v; abandoning", podID, err)
 				return
 			}
 			if pod.DesiredState.Host == "" {
-				podQueue.Add(pod.ID, pod)
+				meta, err := runtime.FindTypeMeta(pod)
+				if err != nil {
+					glog.Errorf("Error queueing pod %v for retry: %v; abandoning", podID, err)
+					return
+				}
+				podQueue.Add(cache.MetaKey(meta), pod)
 			}
 		}()
 	}
@@ -254,7 +263,7 @@ type binder struct {
 // Bind just does a POST binding RPC.
 func (b *binder) Bind(binding *api.Binding) error {
 	glog.V(2).Infof("Attempting to bind %v to %v", binding.PodID, binding.Host)
-	return b.Post().Path("bindings").Body(binding).Do().Error()
+	return b.Post().Namespace(binding.Namespace).Path("bindings").Body(binding).Do().Error()
 }
 
 type clock interface {
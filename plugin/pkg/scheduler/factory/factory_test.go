@@ -53,17 +53,17 @@ func TestCreateLists(t *testing.T) {
 	}{
 		// Minion
 		{
-			location: "/api/" + testapi.Version() + "/minions?fields=",
+			location: "/api/" + testapi.Version() + "/minions?namespace=*&fields=",
 			factory:  factory.createMinionLW,
 		},
 		// Assigned pod
 		{
-			location: "/api/" + testapi.Version() + "/pods?fields=DesiredState.Host!%3D",
+			location: "/api/" + testapi.Version() + "/pods?namespace=*&fields=DesiredState.Host!%3D",
 			factory:  factory.createAssignedPodLW,
 		},
 		// Unassigned pod
 		{
-			location: "/api/" + testapi.Version() + "/pods?fields=DesiredState.Host%3D",
+			location: "/api/" + testapi.Version() + "/pods?namespace=*&fields=DesiredState.Host%3D",
 			factory:  factory.createUnassignedPodLW,
 		},
 	}
@@ -92,35 +92,35 @@ func TestCreateWatches(t *testing.T) {
 		// Minion watch
 		{
 			rv:       "",
-			location: "/api/" + testapi.Version() + "/watch/minions?fields=&resourceVersion=",
+			location: "/api/" + testapi.Version() + "/watch/minions?namespace=*&fields=&resourceVersion=",
 			factory:  factory.createMinionLW,
 		}, {
 			rv:       "0",
-			location: "/api/" + testapi.Version() + "/watch/minions?fields=&resourceVersion=0",
+			location: "/api/" + testapi.Version() + "/watch/minions?namespace=*&fields=&resourceVersion=0",
 			factory:  factory.createMinionLW,
 		}, {
 			rv:       "42",
-			location: "/api/" + testapi.Version() + "/watch/minions?fields=&resourceVersion=42",
+			location: "/api/" + testapi.Version() + "/watch/minions?namespace=*&fields=&resourceVersion=42",
 			factory:  factory.createMinionLW,
 		},
 		// Assigned pod watches
 		{
 			rv:       "",
-			location: "/api/" + testapi.Version() + "/watch/pods?fields=DesiredState.Host!%3D&resourceVersion=",
+			location: "/api/" + testapi.Version() + "/watch/pods?namespace=*&fields=DesiredState.Host!%3D&resourceVersion=",
 			factory:  factory.createAssignedPodLW,
 		}, {
 			rv:       "42",
-			location: "/api/" + testapi.Version() + "/watch/pods?fields=DesiredState.Host!%3D&resourceVersion=42",
+			location: "/api/" + testapi.Version() + "/watch/pods?namespace=*&fields=DesiredState.Host!%3D&resourceVersion=42",
 			factory:  factory.createAssignedPodLW,
 		},
 		// Unassigned pod watches
 		{
 			rv:       "",
-			location: "/api/" + testapi.Version() + "/watch/pods?fields=DesiredState.Host%3D&resourceVersion=",
+			location: "/api/" + testapi.Version() + "/watch/pods?namespace=*&fields=DesiredState.Host%3D&resourceVersion=",
 			factory:  factory.createUnassignedPodLW,
 		}, {
 			rv:       "42",
-			location: "/api/" + testapi.Version() + "/watch/pods?fields=DesiredState.Host%3D&resourceVersion=42",
+			location: "/api/" + testapi.Version() + "/watch/pods?namespace=*&fields=DesiredState.Host%3D&resourceVersion=42",
 			factory:  factory.createUnassignedPodLW,
 		},
 	}
@@ -179,7 +179,7 @@ func TestPollMinions(t *testing.T) {
 }
 
 func TestDefaultErrorFunc(t *testing.T) {
-	testPod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo"}}
+	testPod := &api.Pod{TypeMeta: api.TypeMeta{ID: "foo", Namespace: "bar"}}
 	handler := util.FakeHandler{
 		StatusCode:   200,
 		ResponseBody: runtime.EncodeOrDie(latest.Codec, testPod),
@@ -203,11 +203,11 @@ func TestDefaultErrorFunc(t *testing.T) {
 		// whole error handling system in the future. The test will time
 		// out if something doesn't work.
 		time.Sleep(10 * time.Millisecond)
-		got, exists := queue.Get("foo")
+		got, exists := queue.Get("bar/foo")
 		if !exists {
 			continue
 		}
-		handler.ValidateRequest(t, "/api/"+testapi.Version()+"/pods/foo", "GET", nil)
+		handler.ValidateRequest(t, "/api/"+testapi.Version()+"/pods/foo?namespace=bar", "GET", nil)
 		if e, a := testPod, got; !reflect.DeepEqual(e, a) {
 			t.Errorf("Expected %v, got %v", e, a)
 		}
@@ -300,7 +300,7 @@ func TestBind(t *testing.T) {
 	table := []struct {
 		binding *api.Binding
 	}{
-		{binding: &api.Binding{PodID: "foo", Host: "foohost.kubernetes.mydomain.com"}},
+		{binding: &api.Binding{TypeMeta: api.TypeMeta{Namespace: "bar"}, PodID: "foo", Host: "foohost.kubernetes.mydomain.com"}},
 	}
 
 	for _, item := range table {
@@ -318,7 +318,7 @@ func TestBind(t *testing.T) {
 			continue
 		}
 		expectedBody := runtime.EncodeOrDie(testapi.Codec(), item.binding)
-		handler.ValidateRequest(t, "/api/"+testapi.Version()+"/bindings", "POST", &expectedBody)
+		handler.ValidateRequest(t, "/api/"+testapi.Version()+"/bindings?namespace=bar", "POST", &expectedBody)
 	}
 }
 